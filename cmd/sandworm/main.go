@@ -9,7 +9,8 @@ import (
 
 func main() {
 	opts := &cli.Options{}
-	if err := cli.NewRootCmd(opts).Execute(); err != nil {
-		os.Exit(1)
+	if err := cli.Execute(opts, os.Args[1:]); err != nil {
+		opts.PrintError(err)
+		os.Exit(cli.ExitCode(err))
 	}
 }
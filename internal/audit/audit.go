@@ -0,0 +1,158 @@
+// Package audit keeps an append-only local log of what was shared with Claude
+// (pushes, purges and deploys), for reviewing which code left the machine.
+package audit
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"path/filepath"
+	"time"
+)
+
+// Actions recorded in the log
+const (
+	ActionPush    = "push"
+	ActionPurge   = "purge"
+	ActionPrune   = "prune"
+	ActionRestore = "restore"
+	ActionDeploy  = "deploy"
+)
+
+// Entry is a single audit log record.
+type Entry struct {
+	Time         time.Time `json:"time"`
+	User         string    `json:"user"`              // OS user running sandworm
+	Account      string    `json:"account,omitempty"` // Sandworm account label, if any
+	Action       string    `json:"action"`
+	Directory    string    `json:"directory"`
+	Organization string    `json:"organization"` // Organization ID
+	Project      string    `json:"project"`      // Project ID
+	ProjectName  string    `json:"project_name,omitempty"`
+	Documents    []string  `json:"documents,omitempty"` // Uploaded or deleted documents
+	SHA256       string    `json:"sha256,omitempty"`    // Hash of the uploaded content
+	Size         int64     `json:"size,omitempty"`      // Size of the uploaded content
+	Deploy       *Deploy   `json:"deploy,omitempty"`    // What a deploy shipped, for ActionDeploy
+}
+
+// Deploy is the marker of a deploy: the version of the project whose bundle
+// and instructions were published together.
+type Deploy struct {
+	Version      string `json:"version"`                // Release label, e.g. from git describe
+	Commit       string `json:"commit,omitempty"`       // Git commit of the project
+	Branch       string `json:"branch,omitempty"`       // Git branch of the project
+	Instructions string `json:"instructions,omitempty"` // SHA-256 of the instructions, if deployed
+}
+
+// Path returns the audit log path in a (global config) directory.
+func Path(dir string) string {
+	return filepath.Join(dir, "audit.log")
+}
+
+// Append adds an entry to the log at path, filling in the time and user if
+// unset. The log is only ever appended to, one JSON object per line.
+func Append(path string, e Entry) error {
+	data, err := Marshal(e)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	if _, err := f.Write(data); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return f.Close()
+}
+
+// Read returns the entries of the log at path, oldest first. A missing log
+// has no entries.
+func Read(path string) ([]Entry, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	return Parse(f)
+}
+
+// Marshal encodes an entry as a line of the log, filling in the time and user
+// if unset.
+func Marshal(e Entry) ([]byte, error) {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	if e.User == "" {
+		e.User = currentUser()
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// Parse returns the entries of a log (e.g. the deploy markers of a project),
+// oldest first.
+func Parse(r io.Reader) ([]Entry, error) {
+	var entries []Entry
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("failed to parse audit log line %d: %w", line, err)
+		}
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return entries, nil
+}
+
+// HashFile returns the SHA-256 (hex) and size of a file.
+func HashFile(path string) (string, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to hash file: %w", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), size, nil
+}
+
+// MARK: Helpers
+
+// currentUser returns the name of the OS user, falling back to $USER.
+func currentUser() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+	return os.Getenv("USERNAME")
+}
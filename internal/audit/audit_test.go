@@ -0,0 +1,66 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAppendRead(t *testing.T) {
+	path := Path(filepath.Join(t.TempDir(), "sandworm"))
+
+	entries, err := Read(path)
+	if err != nil || len(entries) != 0 {
+		t.Fatalf("Expected no entries for a missing log, got %v (%v)", entries, err)
+	}
+
+	if err := Append(path, Entry{Action: ActionPush, Project: "p1", SHA256: "abc", Size: 42}); err != nil {
+		t.Fatalf("Failed to append: %v", err)
+	}
+	if err := Append(path, Entry{Action: ActionPurge, Project: "p1", Documents: []string{"project.txt"}}); err != nil {
+		t.Fatalf("Failed to append: %v", err)
+	}
+	if err := Append(path, Entry{Action: ActionDeploy, Project: "p1", Deploy: &Deploy{Version: "v1.2.0", Commit: "abc123"}}); err != nil {
+		t.Fatalf("Failed to append: %v", err)
+	}
+
+	entries, err = Read(path)
+	if err != nil {
+		t.Fatalf("Failed to read: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 entries, got %d", len(entries))
+	}
+	if entries[0].Action != ActionPush || entries[0].Size != 42 || entries[0].Time.IsZero() {
+		t.Errorf("Unexpected first entry: %+v", entries[0])
+	}
+	if entries[1].Action != ActionPurge || len(entries[1].Documents) != 1 {
+		t.Errorf("Unexpected second entry: %+v", entries[1])
+	}
+	if entries[2].Deploy == nil || entries[2].Deploy.Version != "v1.2.0" || entries[0].Deploy != nil {
+		t.Errorf("Expected only the deploy to have a marker, got %+v", entries)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Failed to stat log: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("Expected log to be private (0600), got %o", perm)
+	}
+}
+
+func TestHashFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file.txt")
+	if err := os.WriteFile(path, []byte("hello"), 0o644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	hash, size, err := HashFile(path)
+	if err != nil {
+		t.Fatalf("Failed to hash file: %v", err)
+	}
+	if hash != "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824" || size != 5 {
+		t.Errorf("Unexpected hash/size: %s, %d", hash, size)
+	}
+}
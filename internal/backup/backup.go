@@ -0,0 +1,138 @@
+// Package backup keeps local copies of a Claude project's documents, taken
+// before a push replaces or deletes them, so that they can be restored.
+// Backups are kept per project in the global config directory, one directory
+// per backup holding a file per document.
+package backup
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// timeFormat names backup directories, so that they sort chronologically.
+const timeFormat = "20060102-150405"
+
+// Document is a remote document's file name and content.
+type Document struct {
+	Name    string
+	Content string
+}
+
+// Dir returns the directory holding the backups of a project in a (global
+// config) directory.
+func Dir(dir, projectID string) string {
+	return filepath.Join(dir, "backups", projectID)
+}
+
+// Save writes documents to a new backup in dir, named after t, and returns
+// its path.
+func Save(dir string, docs []Document, t time.Time) (string, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	// Several pushes can happen within a second (e.g. watch)
+	name := t.Format(timeFormat)
+	path := filepath.Join(dir, name)
+	for i := 2; ; i++ {
+		err := os.Mkdir(path, 0o700)
+		if err == nil {
+			break
+		}
+		if !os.IsExist(err) {
+			return "", fmt.Errorf("failed to create backup directory: %w", err)
+		}
+		path = filepath.Join(dir, fmt.Sprintf("%s-%d", name, i))
+	}
+
+	for _, doc := range docs {
+		if err := os.WriteFile(filepath.Join(path, fileName(doc.Name)), []byte(doc.Content), 0o600); err != nil {
+			return "", fmt.Errorf("failed to write backup: %w", err)
+		}
+	}
+	return path, nil
+}
+
+// List returns the names of the backups in dir, oldest first. A missing
+// directory has no backups.
+func List(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backups: %w", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
+// Names returns the file names of the documents in the backup at path.
+func Names(path string) ([]string, error) {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup: %w", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	return names, nil
+}
+
+// Load returns the documents of the backup at path.
+func Load(path string) ([]Document, error) {
+	names, err := Names(path)
+	if err != nil {
+		return nil, err
+	}
+
+	docs := make([]Document, len(names))
+	for i, name := range names {
+		data, err := os.ReadFile(filepath.Join(path, name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read backup: %w", err)
+		}
+		docs[i] = Document{Name: name, Content: string(data)}
+	}
+	return docs, nil
+}
+
+// Prune removes the oldest backups in dir, keeping the last keep ones.
+func Prune(dir string, keep int) error {
+	names, err := List(dir)
+	if err != nil {
+		return err
+	}
+	for len(names) > keep {
+		if err := os.RemoveAll(filepath.Join(dir, names[0])); err != nil {
+			return fmt.Errorf("failed to remove old backup: %w", err)
+		}
+		names = names[1:]
+	}
+	return nil
+}
+
+// fileName returns a safe file name for a document name, which could contain
+// path separators.
+func fileName(name string) string {
+	name = strings.NewReplacer("/", "_", `\`, "_").Replace(name)
+	if name == "" || name == "." || name == ".." {
+		return "_" + name
+	}
+	return name
+}
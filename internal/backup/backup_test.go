@@ -0,0 +1,66 @@
+package backup
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSaveLoad(t *testing.T) {
+	dir := Dir(t.TempDir(), "p-1")
+	at := time.Date(2026, 10, 17, 9, 30, 0, 0, time.UTC)
+	docs := []Document{
+		{Name: "project.txt", Content: "bundle"},
+		{Name: "docs/notes.md", Content: "notes"},
+	}
+
+	first, err := Save(dir, docs, at)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	second, err := Save(dir, docs[:1], at)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if filepath.Base(first) != "20261017-093000" || filepath.Base(second) != "20261017-093000-2" {
+		t.Errorf("Unexpected backup names: %s, %s", first, second)
+	}
+
+	loaded, err := Load(first)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var names []string
+	for _, doc := range loaded {
+		names = append(names, doc.Name+"="+doc.Content)
+	}
+	if got := strings.Join(names, ","); got != "docs_notes.md=notes,project.txt=bundle" {
+		t.Errorf("Unexpected documents: %s", got)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	for i := range 4 {
+		if _, err := Save(dir, nil, start.Add(time.Duration(i)*time.Hour)); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	if err := Prune(dir, 2); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	names, err := List(dir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := strings.Join(names, ","); got != "20261017-110000,20261017-120000" {
+		t.Errorf("Expected the 2 newest backups, got %s", got)
+	}
+
+	if names, err := List(filepath.Join(dir, "missing")); err != nil || names != nil {
+		t.Errorf("Expected no backups for a missing directory, got %v, %v", names, err)
+	}
+}
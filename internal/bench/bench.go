@@ -0,0 +1,199 @@
+// Package bench generates synthetic projects and measures how fast sandworm
+// walks, processes and uploads them, to establish performance baselines (see
+// the hidden 'sandworm bench' command). Generated projects are deterministic
+// for a given shape, so that measurements are comparable across versions.
+package bench
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Shape describes a synthetic project.
+type Shape struct {
+	Files    int   // Number of source files
+	Depth    int   // Levels of nested directories
+	FanOut   int   // Subdirectories per directory
+	FileSize int   // Average file size, in bytes
+	Ignored  int   // Files to skip (node_modules, build output, gitignored logs)
+	Seed     int64 // Seed of the contents, for reproducible projects
+}
+
+// DefaultShape is a mid-sized project.
+var DefaultShape = Shape{Files: 1000, Depth: 3, FanOut: 4, FileSize: 4096, Ignored: 200, Seed: 1}
+
+// Validate checks that a shape describes a project that can be generated.
+func (s Shape) Validate() error {
+	switch {
+	case s.Files < 1:
+		return fmt.Errorf("invalid file count %d (must be at least 1)", s.Files)
+	case s.Depth < 0:
+		return fmt.Errorf("invalid depth %d (must not be negative)", s.Depth)
+	case s.FanOut < 1:
+		return fmt.Errorf("invalid fan-out %d (must be at least 1)", s.FanOut)
+	case s.FileSize < 1:
+		return fmt.Errorf("invalid file size %d (must be at least 1)", s.FileSize)
+	case s.Ignored < 0:
+		return fmt.Errorf("invalid ignored file count %d (must not be negative)", s.Ignored)
+	}
+	return nil
+}
+
+// Project is a generated project.
+type Project struct {
+	Dir   string
+	Files int   // Files sandworm includes
+	Bytes int64 // Total size of the included files
+}
+
+// languages are the kinds of generated files, with how they start and how
+// they repeat to reach the requested size.
+var languages = []struct {
+	ext    string
+	header func(name string) string
+	line   func(r *rand.Rand, i int) string
+}{
+	{".go", func(name string) string { return "package " + name + "\n\nimport \"fmt\"\n\n" }, func(r *rand.Rand, i int) string {
+		return fmt.Sprintf("func helper%d(n int) int { fmt.Println(%q); return n * %d }\n", i, word(r), r.Intn(100))
+	}},
+	{".ts", func(string) string { return "import { format } from './format';\n\n" }, func(r *rand.Rand, i int) string {
+		return fmt.Sprintf("export const value%d = format('%s', %d);\n", i, word(r), r.Intn(100))
+	}},
+	{".py", func(string) string { return "import os\n\n" }, func(r *rand.Rand, i int) string {
+		return fmt.Sprintf("def helper_%d(n):\n    return os.path.join(%q, str(n * %d))\n\n", i, word(r), r.Intn(100))
+	}},
+	{".md", func(name string) string { return "# " + name + "\n\n" }, func(r *rand.Rand, _ int) string {
+		words := make([]string, 8+r.Intn(8))
+		for i := range words {
+			words[i] = word(r)
+		}
+		return strings.Join(words, " ") + ".\n\n"
+	}},
+}
+
+// Generate writes a synthetic project of the given shape in dir, which is
+// created if needed: source files of several languages spread across nested
+// directories, a .gitignore, and ignored directories sandworm must skip.
+func Generate(dir string, shape Shape) (*Project, error) {
+	if err := shape.Validate(); err != nil {
+		return nil, err
+	}
+	r := rand.New(rand.NewSource(shape.Seed))
+	dirs := directories(shape.Depth, shape.FanOut)
+	project := &Project{Dir: dir}
+
+	for i := range shape.Files {
+		lang := languages[i%len(languages)]
+		name := fmt.Sprintf("file%d", i)
+		var b strings.Builder
+		b.WriteString(lang.header(name))
+		// Sizes vary between half and one and a half times the average
+		size := shape.FileSize/2 + r.Intn(shape.FileSize+1)
+		for line := 0; b.Len() < size; line++ {
+			b.WriteString(lang.line(r, line))
+		}
+		path := filepath.Join(dir, dirs[i%len(dirs)], name+lang.ext)
+		if err := writeFile(path, b.String()); err != nil {
+			return nil, err
+		}
+		project.Files++
+		project.Bytes += int64(b.Len())
+	}
+
+	const gitignore = "node_modules/\nbuild/\n*.log\n"
+	if err := writeFile(filepath.Join(dir, ".gitignore"), gitignore); err != nil {
+		return nil, err
+	}
+	project.Files++
+	project.Bytes += int64(len(gitignore))
+	for i := range shape.Ignored {
+		var path string
+		switch i % 3 {
+		case 0:
+			path = filepath.Join(dir, "node_modules", fmt.Sprintf("pkg%d", i%10), fmt.Sprintf("index%d.js", i))
+		case 1:
+			path = filepath.Join(dir, "build", fmt.Sprintf("out%d.o", i))
+		default:
+			path = filepath.Join(dir, dirs[i%len(dirs)], fmt.Sprintf("debug%d.log", i))
+		}
+		if err := writeFile(path, strings.Repeat(word(r)+"\n", shape.FileSize/8+1)); err != nil {
+			return nil, err
+		}
+	}
+	return project, nil
+}
+
+// directories returns the relative directories of a tree of the given depth
+// and fan-out, root ("") included, in a stable order.
+func directories(depth, fanOut int) []string {
+	dirs := []string{""}
+	level := []string{""}
+	for d := range depth {
+		var next []string
+		for _, parent := range level {
+			for i := range fanOut {
+				next = append(next, filepath.Join(parent, fmt.Sprintf("dir%d_%d", d, i)))
+			}
+		}
+		dirs = append(dirs, next...)
+		level = next
+	}
+	sort.Strings(dirs)
+	return dirs
+}
+
+var words = []string{"alpha", "bravo", "charlie", "delta", "echo", "foxtrot", "golf", "hotel", "india", "juliet", "kilo", "lima"}
+
+func word(r *rand.Rand) string {
+	return words[r.Intn(len(words))]
+}
+
+func writeFile(path, content string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	return nil
+}
+
+// MARK: Results
+
+// Result is the measurements of a stage over several iterations.
+type Result struct {
+	Stage     string
+	Files     int
+	Bytes     int64
+	Durations []time.Duration
+}
+
+// Median returns the median duration of the iterations.
+func (r Result) Median() time.Duration {
+	if len(r.Durations) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration(nil), r.Durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted[len(sorted)/2]
+}
+
+// String renders the median duration with the throughput it amounts to.
+func (r Result) String() string {
+	median := r.Median()
+	line := fmt.Sprintf("  %-8s %10s", r.Stage, median.Round(time.Microsecond))
+	if seconds := median.Seconds(); seconds > 0 {
+		if r.Files > 0 {
+			line += fmt.Sprintf(" %12.0f files/s", float64(r.Files)/seconds)
+		}
+		if r.Bytes > 0 {
+			line += fmt.Sprintf(" %10.1f MB/s", float64(r.Bytes)/seconds/(1<<20))
+		}
+	}
+	return line
+}
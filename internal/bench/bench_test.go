@@ -0,0 +1,82 @@
+package bench
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGenerate(t *testing.T) {
+	shape := Shape{Files: 20, Depth: 2, FanOut: 2, FileSize: 256, Ignored: 6, Seed: 7}
+	first, err := Generate(filepath.Join(t.TempDir(), "a"), shape)
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	second, err := Generate(filepath.Join(t.TempDir(), "b"), shape)
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	if first.Files != 21 {
+		t.Errorf("Expected 20 source files and a .gitignore, got %d", first.Files)
+	}
+	if first.Bytes != second.Bytes {
+		t.Errorf("Expected the same seed to generate the same project, got %d and %d bytes", first.Bytes, second.Bytes)
+	}
+	for _, path := range []string{"dir0_1/dir1_0", "node_modules", "build"} {
+		if info, err := os.Stat(filepath.Join(first.Dir, path)); err != nil || !info.IsDir() {
+			t.Errorf("Expected directory %s, got %v", path, err)
+		}
+	}
+	content, err := os.ReadFile(filepath.Join(first.Dir, "file0.go"))
+	if err != nil || !strings.HasPrefix(string(content), "package file0\n") {
+		t.Errorf("Expected a Go file at the root, got %q (%v)", content, err)
+	}
+
+	if _, err := Generate(t.TempDir(), Shape{Files: 1, FanOut: 0, FileSize: 1}); err == nil {
+		t.Error("Expected an error for an invalid shape")
+	}
+}
+
+func TestResult(t *testing.T) {
+	result := Result{Stage: "read", Files: 100, Bytes: 1 << 20, Durations: []time.Duration{
+		3 * time.Second, time.Second, 2 * time.Second,
+	}}
+	if median := result.Median(); median != 2*time.Second {
+		t.Errorf("Expected a median of 2s, got %s", median)
+	}
+	if got := result.String(); !strings.Contains(got, "50 files/s") || !strings.Contains(got, "0.5 MB/s") {
+		t.Errorf("Expected throughput from the median, got %q", got)
+	}
+}
+
+func TestServer(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	docs := server.URL + "/organizations/o/projects/p/docs"
+
+	resp, err := http.Post(docs, "application/json", strings.NewReader(`{"file_name":"project.txt","content":"bundle"}`))
+	if err != nil {
+		t.Fatalf("Upload failed: %v", err)
+	}
+	var doc document
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil || doc.ID == "" {
+		t.Fatalf("Expected the uploaded document, got %+v (%v)", doc, err)
+	}
+	_ = resp.Body.Close()
+	if server.Received() != int64(len("bundle")) {
+		t.Errorf("Expected 6 bytes received, got %d", server.Received())
+	}
+
+	req, _ := http.NewRequest(http.MethodDelete, docs+"/"+doc.ID, nil)
+	if resp, err := http.DefaultClient.Do(req); err != nil || resp.StatusCode != http.StatusNoContent {
+		t.Errorf("Expected the document to be deleted, got %v", err)
+	}
+	if resp, err := http.DefaultClient.Do(req); err != nil || resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected a deleted document to be gone, got %v", err)
+	}
+}
@@ -0,0 +1,92 @@
+package bench
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+)
+
+// Server is an in-process stand-in for the project documents API, so that
+// uploads can be measured without network latency or touching real projects.
+// It serves the routes under any organization and project.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	docs     map[string]string // ID -> file name
+	next     int
+	received int64
+}
+
+// NewServer starts a documents API server. Close it when done.
+func NewServer() *Server {
+	s := &Server{docs: map[string]string{}}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// Received returns the number of bytes of uploaded documents.
+func (s *Server) Received() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.received
+}
+
+type document struct {
+	ID       string `json:"uuid"`
+	FileName string `json:"file_name"`
+	Content  string `json:"content,omitempty"`
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// .../projects/{project}/docs[/{doc}]
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) < 2 || (parts[len(parts)-1] != "docs" && parts[len(parts)-2] != "docs") {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && parts[len(parts)-1] == "docs":
+		docs := []document{}
+		for id, name := range s.docs {
+			docs = append(docs, document{ID: id, FileName: name})
+		}
+		writeJSON(w, docs)
+	case r.Method == http.MethodPost && parts[len(parts)-1] == "docs":
+		var doc document
+		if err := json.NewDecoder(r.Body).Decode(&doc); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.next++
+		doc.ID = fmt.Sprintf("doc-%d", s.next)
+		s.docs[doc.ID] = doc.FileName
+		s.received += int64(len(doc.Content))
+		doc.Content = ""
+		writeJSON(w, doc)
+	case r.Method == http.MethodDelete && parts[len(parts)-2] == "docs":
+		id := parts[len(parts)-1]
+		if _, ok := s.docs[id]; !ok {
+			http.NotFound(w, r)
+			return
+		}
+		delete(s.docs, id)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		_, _ = io.Copy(io.Discard, r.Body)
+		http.Error(w, "unsupported request", http.StatusMethodNotAllowed)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
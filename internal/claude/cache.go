@@ -0,0 +1,128 @@
+package claude
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// cacheFile is the name of the metadata cache, stored in the global config
+// directory.
+const cacheFile = "cache.json"
+
+// metadataCache holds organization and project listings so that names can be
+// displayed and resolved without hitting the API on every invocation.
+type metadataCache struct {
+	Organizations []organization       `json:"organizations,omitempty"`
+	Projects      map[string][]project `json:"projects,omitempty"`  // keyed by organization ID
+	Endpoints     map[string]string    `json:"endpoints,omitempty"` // Path variants that worked, see endpoints.go
+	UpdatedAt     time.Time            `json:"updated_at"`
+}
+
+// cachePath returns the location of the metadata cache file.
+func (c *Client) cachePath() string {
+	return filepath.Join(c.config.Dir(), cacheFile)
+}
+
+// loadCache reads the metadata cache from disk. A missing or unreadable cache
+// is treated as empty.
+func (c *Client) loadCache() *metadataCache {
+	if c.cache != nil {
+		return c.cache
+	}
+
+	c.cache = &metadataCache{Projects: make(map[string][]project)}
+	if c.refresh {
+		// Start from scratch; listings will be re-fetched and saved.
+		return c.cache
+	}
+
+	data, err := os.ReadFile(c.cachePath())
+	if err != nil {
+		return c.cache
+	}
+	if err := json.Unmarshal(data, c.cache); err != nil || c.cache.Projects == nil {
+		c.cache = &metadataCache{Projects: make(map[string][]project)}
+	}
+	return c.cache
+}
+
+// saveCache persists the metadata cache to disk.
+func (c *Client) saveCache() error {
+	cache := c.loadCache()
+	cache.UpdatedAt = time.Now()
+
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(c.cachePath()), 0o755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	if err := os.WriteFile(c.cachePath(), data, 0o644); err != nil {
+		return fmt.Errorf("failed to write cache: %w", err)
+	}
+	return nil
+}
+
+// organizations returns the organization listing, from cache when possible.
+func (c *Client) organizations(fresh bool) ([]organization, error) {
+	cache := c.loadCache()
+	if !fresh && len(cache.Organizations) > 0 {
+		return cache.Organizations, nil
+	}
+
+	orgs, err := c.listOrganizations()
+	if err != nil {
+		return nil, err
+	}
+	cache.Organizations = orgs
+	if err := c.saveCache(); err != nil {
+		return nil, err
+	}
+	return orgs, nil
+}
+
+// projects returns the project listing for the current organization, from
+// cache when possible.
+func (c *Client) projects(fresh bool) ([]project, error) {
+	cache := c.loadCache()
+	if cached, ok := cache.Projects[c.orgID()]; ok && !fresh {
+		return cached, nil
+	}
+
+	projects, err := c.listProjects()
+	if err != nil {
+		return nil, err
+	}
+	cache.Projects[c.orgID()] = projects
+	if err := c.saveCache(); err != nil {
+		return nil, err
+	}
+	return projects, nil
+}
+
+// resolveCached finds an item by ID or name in a cached listing, re-fetching
+// the listing once if it isn't found (it may have been created since).
+func resolveCached[T interface{ GetName() string }](
+	list func(fresh bool) ([]T, error),
+	query string,
+	id func(T) string,
+) (T, error) {
+	var zero T
+	items, err := list(false)
+	if err != nil {
+		return zero, err
+	}
+	if match, err := findByIDOrName(items, query, id); err == nil {
+		return match, nil
+	}
+
+	items, err = list(true)
+	if err != nil {
+		return zero, err
+	}
+	return findByIDOrName(items, query, id)
+}
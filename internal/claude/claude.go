@@ -2,20 +2,28 @@
 package claude
 
 import (
+	"bufio"
 	"bytes"
+	"cmp"
 	"compress/gzip"
 	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
 	"os"
+	"path/filepath"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/holonoms/sandworm/internal/backup"
 	"github.com/holonoms/sandworm/internal/config"
+	"github.com/holonoms/sandworm/internal/util"
 )
 
 const (
@@ -23,17 +31,119 @@ const (
 
 	// Configuration keys
 	sessionKey     = "claude.session_key" // Global, used across sandworm projects
+	accountKey     = "claude.account"     // Project binding to a named account
+	defaultAccount = "claude.default_account"
 	organizationID = "claude.organization_id"
 	projectID      = "claude.project_id"
 	documentID     = "claude.document_id"
+	// What the tracked document looked like when pushed, to notice edits
+	// made to it since (e.g. in the web interface)
+	documentSize    = "claude.document_size"
+	documentUpdated = "claude.document_updated"
+
+	// managedSection lists the IDs of the documents sandworm uploaded to
+	// each project (documents.<project ID>), so that push and purge only
+	// ever touch those, never knowledge files uploaded by hand or by other
+	// tools.
+	managedSection = "documents"
 )
 
 var sessionKeyRegex = regexp.MustCompile(`^sessionKey=([^;]+)`)
 
+// ErrMissingConfig is returned when required configuration (session key,
+// organization, project) hasn't been set up yet.
+var ErrMissingConfig = errors.New("missing required config keys")
+
+// ReadOnlyEnv turns on read-only mode when set to a true value (see
+// ReadOnly). It's set by --read-only, so that child processes inherit it.
+const ReadOnlyEnv = "SANDWORM_READ_ONLY"
+
+// ErrReadOnly is returned instead of sending requests that would change
+// anything (uploads, deletions, project, conversation and instruction
+// updates) in read-only mode.
+var ErrReadOnly = errors.New("read-only mode")
+
+// ErrNotFound is returned when no organization or project has the given ID or
+// name.
+var ErrNotFound = errors.New("not found")
+
+// ErrPreviousKept is wrapped by the errors of uploads that failed replacing
+// documents, whose previous versions are left in the project. The upload may
+// have failed for lack of room: until they're deleted, both versions count
+// towards the project's knowledge (see SetDeleteFirst).
+var ErrPreviousKept = errors.New("the previous version is left in the project")
+
+// ReadOnly reports whether read-only mode is on, for exploring a production
+// project without risk: only listing and reading requests are sent.
+func ReadOnly() bool {
+	readOnly, err := strconv.ParseBool(os.Getenv(ReadOnlyEnv))
+	return err == nil && readOnly
+}
+
+// APIError is returned when the Claude API responds with a non-2xx status.
+type APIError struct {
+	StatusCode int
+	Body       string
+	ResetsAt   time.Time // When a rate limit resets, if known
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API request failed: %d - %s", e.StatusCode, e.Body)
+}
+
+// IsAuthError reports whether the API rejected the request's credentials
+// (typically an expired or invalid session key).
+func (e *APIError) IsAuthError() bool {
+	return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+}
+
+// IsRateLimited reports whether the API rejected the request because a usage
+// or rate limit was reached.
+func (e *APIError) IsRateLimited() bool {
+	return e.StatusCode == http.StatusTooManyRequests
+}
+
+// IsServiceError reports whether the request failed on the service's side
+// (server errors, including 529 when overloaded), rather than because of it.
+func (e *APIError) IsServiceError() bool {
+	return e.StatusCode >= 500
+}
+
+// IsStatus reports whether err is an APIError with the given status code.
+func IsStatus(err error, statusCode int) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == statusCode
+}
+
 // Client manages interactions with the Claude API
 type Client struct {
 	config     *config.Config
 	httpClient *http.Client
+
+	// Per-invocation overrides of the configured organization/project. The
+	// queries (ID or name) are resolved to IDs during Setup. When overridden,
+	// the configured document ID is ignored since it belongs to the configured
+	// project.
+	orgQuery        string
+	projectQuery    string
+	orgOverride     string
+	projectOverride string
+
+	// Organization/project metadata cache (see cache.go). When refresh is set,
+	// the on-disk cache is ignored and rebuilt.
+	cache   *metadataCache
+	refresh bool
+
+	// Disables the response cache of listings (see httpcache.go).
+	noCache bool
+
+	// Deletes the previous versions of pushed documents before uploading
+	// them (see SetDeleteFirst).
+	deleteFirst bool
+
+	// Session key sent instead of the configured one, while checking it
+	// (see CheckSessionKey).
+	sessionKeyOverride string
 }
 
 // New creates a new Claude API client using the provided configuration
@@ -57,28 +167,116 @@ func New(conf *config.Config) *Client {
 
 // MARK: Interface
 
+// SetTarget overrides the configured organization and/or project for this
+// client. Both accept either an ID or a (case-insensitive) name, which is
+// resolved via the API during Setup. Empty values keep the configured ones.
+func (c *Client) SetTarget(org, proj string) {
+	c.orgQuery = org
+	c.projectQuery = proj
+}
+
+// SetRefresh forces cached organization/project metadata to be re-fetched.
+func (c *Client) SetRefresh(refresh bool) {
+	c.refresh = refresh
+}
+
+// SetDeleteFirst makes pushes delete the previous versions of documents before
+// uploading the new ones, rather than after. Documents are uploaded under the
+// same name either way (never renamed), but deleting them first frees room in
+// projects whose knowledge is full, at the cost of leaving the project
+// without them should an upload fail.
+func (c *Client) SetDeleteFirst(deleteFirst bool) {
+	c.deleteFirst = deleteFirst
+}
+
+// TargetNames returns the human-readable names of the organization and project
+// the client operates on. Names come from the local cache when possible; IDs
+// are returned as a fallback if they can't be resolved.
+func (c *Client) TargetNames() (orgName, projectName string) {
+	orgName, projectName = c.orgID(), c.projectID()
+	if org, err := resolveCached(c.organizations, c.orgID(), func(o organization) string { return o.ID }); err == nil {
+		orgName = org.Name
+	}
+	if proj, err := resolveCached(c.projects, c.projectID(), func(p project) string { return p.ID }); err == nil {
+		projectName = proj.Name
+	}
+	return orgName, projectName
+}
+
+// TargetIDs returns the IDs of the organization and project the client
+// operates on.
+func (c *Client) TargetIDs() (orgID, projectID string) {
+	return c.orgID(), c.projectID()
+}
+
+// Account returns the label of the account in use, or "" when using the
+// single (unlabeled) session key.
+func (c *Client) Account() string {
+	if account := c.config.Get(accountKey); account != "" {
+		return account
+	}
+	return c.config.Get(defaultAccount)
+}
+
+// ProjectURL returns the claude.ai URL of the target project.
+func (c *Client) ProjectURL() string {
+	return ProjectURL(c.projectID())
+}
+
+// ProjectURL returns the claude.ai URL of a project. Project knowledge
+// (including the uploaded document) is listed on that page.
+func ProjectURL(projectID string) string {
+	return baseURL + "/project/" + projectID
+}
+
+// LookupOrganization returns the ID and name of an organization the account
+// can access, given its ID or name.
+func (c *Client) LookupOrganization(query string) (id, name string, err error) {
+	if !c.config.Has(c.sessionKeyName()) {
+		return "", "", fmt.Errorf("%w: %s", ErrMissingConfig, c.sessionKeyName())
+	}
+	org, err := resolveCached(c.organizations, query, func(o organization) string { return o.ID })
+	if err != nil {
+		return "", "", fmt.Errorf("organization %w", err)
+	}
+	return org.ID, org.Name, nil
+}
+
+// LookupProject returns the ID and name of a project of the target
+// organization, given its ID or name.
+func (c *Client) LookupProject(query string) (id, name string, err error) {
+	if !c.config.Has(c.sessionKeyName()) {
+		return "", "", fmt.Errorf("%w: %s", ErrMissingConfig, c.sessionKeyName())
+	}
+	if c.orgID() == "" {
+		return "", "", fmt.Errorf("%w: %s", ErrMissingConfig, organizationID)
+	}
+	proj, err := resolveCached(c.projects, query, func(p project) string { return p.ID })
+	if err != nil {
+		return "", "", fmt.Errorf("project %w", err)
+	}
+	return proj.ID, proj.Name, nil
+}
+
 // Setup initializes the client configuration, prompting for required values
 // if they're not already set. It validates organization access and project
 // selection.
 func (c *Client) Setup(force bool) (bool, error) {
 	// Handle session key setup
-	if force || !c.config.Has(sessionKey) {
-		fmt.Println("\nPlease go to https://claude.ai in your browser and copy your session key from the Cookie header.")
-		fmt.Println("You can find this in your browser's developer tools under Network tab.")
-		fmt.Println()
-		fmt.Print("Enter your session key: ")
-		var key string
-		if _, err := fmt.Scanln(&key); err != nil {
-			return false, fmt.Errorf("failed to read session key: %w", err)
-		}
-		if err := c.config.Set(sessionKey, key); err != nil {
+	if force || !c.config.Has(c.sessionKeyName()) {
+		if err := c.promptSessionKey(); err != nil {
 			return false, err
 		}
 	}
 
+	// Resolve per-invocation overrides (these skip the interactive selection)
+	if err := c.resolveTarget(); err != nil {
+		return false, err
+	}
+
 	// Handle organization selection
-	if force || !c.config.Has(organizationID) {
-		orgs, err := c.listOrganizations()
+	if c.orgOverride == "" && (force || !c.config.Has(organizationID)) {
+		orgs, err := c.organizations(true)
 		if err != nil {
 			return false, err
 		}
@@ -96,8 +294,8 @@ func (c *Client) Setup(force bool) (bool, error) {
 	}
 
 	// Handle project selection
-	if force || !c.config.Has(projectID) {
-		projects, err := c.listProjects()
+	if c.projectOverride == "" && (force || !c.config.Has(projectID)) {
+		projects, err := c.projects(true)
 		if err != nil {
 			return false, err
 		}
@@ -109,14 +307,29 @@ func (c *Client) Setup(force bool) (bool, error) {
 			}
 		}
 
+		// Offer creating a new project alongside the existing ones (a project
+		// without ID acts as the sentinel for that choice).
+		selected := project{Name: "Create a new project"}
 		if len(activeProjects) == 0 {
-			fmt.Println("\nNo active projects found. Please create one at https://claude.ai")
-			return false, nil
+			fmt.Println("\nNo active projects found.")
+		} else {
+			fmt.Println("\nSelect a project:")
+			selected = selectFromList(append(activeProjects, selected))
 		}
 
-		fmt.Println("\nSelect a project:")
-		project := selectFromList(activeProjects)
-		if err := c.config.Set(projectID, project.ID); err != nil {
+		if selected.ID == "" {
+			created, err := c.promptCreateProject()
+			if err != nil {
+				return false, err
+			}
+			if created == nil {
+				fmt.Println("\nNo project selected. Please create one at https://claude.ai")
+				return false, nil
+			}
+			selected = *created
+		}
+
+		if err := c.config.Set(projectID, selected.ID); err != nil {
 			return false, err
 		}
 	}
@@ -124,106 +337,822 @@ func (c *Client) Setup(force bool) (bool, error) {
 	return true, nil
 }
 
-// Push uploads a file to the selected Claude project. If a file with the same
-// name exists, it's replaced.
+// Push uploads a file to the selected Claude project. If a document sandworm
+// uploaded with the same name exists, it's replaced: the new version is
+// uploaded first under the same name, and the previous one only deleted once
+// that succeeded, so a failed upload never leaves the project without the
+// document (see ErrPreviousKept, and SetDeleteFirst for the reverse order).
 func (c *Client) Push(filePath, fileName string) error {
 	if err := c.validateConfig(); err != nil {
 		return err
 	}
 
-	// If no document ID is set, try to find existing document
-	if !c.config.Has(documentID) {
-		docs, err := c.listDocuments()
-		if err != nil {
+	// The tracked document, and those left over by an interrupted or failed
+	// replacement
+	var previous []string
+	if docID := c.documentID(); docID != "" {
+		previous = append(previous, docID)
+	}
+	docs, err := c.listDocuments()
+	if err != nil {
+		return err
+	}
+	for _, doc := range c.managed(docs) {
+		if doc.FileName == fileName && !slices.Contains(previous, doc.ID) {
+			previous = append(previous, doc.ID)
+		}
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+
+	if c.deleteFirst {
+		if err := c.deleteDocuments(previous); err != nil {
 			return err
 		}
-		for _, doc := range docs {
-			if doc.FileName == fileName {
-				if err := c.config.Set(documentID, doc.ID); err != nil {
-					return err
-				}
-				break
+		previous = nil
+	}
+	doc, err := c.uploadDocument(fileName, string(content))
+	if err != nil {
+		return keptError(err, len(previous) > 0)
+	}
+	if err := c.setManaged(doc.ID, true); err != nil {
+		return err
+	}
+	if err := c.recordDocument(doc, string(content)); err != nil {
+		return err
+	}
+	return c.deletePrevious(fileName, doc.ID, previous)
+}
+
+// Upload is a document to push.
+type Upload struct {
+	FileName string
+	Content  string
+}
+
+// PushDocuments uploads documents (e.g. the parts of a split bundle) as a
+// whole, replacing the documents sandworm uploaded with the same file names.
+// Like Push, the previous versions are only deleted once every document is
+// uploaded; should an upload fail, those already uploaded are deleted again,
+// so that the project isn't left with a mix of old and new documents. It only
+// updates the tracked document ID if it replaces that document.
+func (c *Client) PushDocuments(uploads []Upload, progressFn func(fileName string, current, total int)) error {
+	if err := c.validateConfig(); err != nil {
+		return err
+	}
+
+	docs, err := c.listDocuments()
+	if err != nil {
+		return err
+	}
+	var previous []string
+	tracked := ""
+	for _, doc := range c.managed(docs) {
+		if slices.ContainsFunc(uploads, func(u Upload) bool { return u.FileName == doc.FileName }) {
+			previous = append(previous, doc.ID)
+			if doc.ID == c.documentID() {
+				tracked = doc.FileName
 			}
 		}
 	}
+	if c.deleteFirst {
+		if err := c.deleteDocuments(previous); err != nil {
+			return err
+		}
+		previous = nil
+	}
 
-	// Delete existing document if we have one
-	if c.config.Has(documentID) {
-		if err := c.deleteDocument(c.config.Get(documentID)); err != nil {
-			// Only return error if it's not a 404
-			if !strings.Contains(err.Error(), "404") {
+	var uploaded []string
+	for i, upload := range uploads {
+		if progressFn != nil {
+			progressFn(upload.FileName, i+1, len(uploads))
+		}
+		doc, err := c.uploadDocument(upload.FileName, upload.Content)
+		if err != nil {
+			if rollbackErr := c.deleteDocuments(uploaded); rollbackErr != nil {
+				return fmt.Errorf("%w (and the documents uploaded before it couldn't be removed: %w)", err, rollbackErr)
+			}
+			return keptError(err, len(previous) > 0)
+		}
+		uploaded = append(uploaded, doc.ID)
+		if err := c.setManaged(doc.ID, true); err != nil {
+			return err
+		}
+		if upload.FileName == tracked {
+			if err := c.recordDocument(doc, upload.Content); err != nil {
 				return err
 			}
 		}
-		if err := c.config.Delete(documentID); err != nil {
+	}
+
+	for _, prev := range previous {
+		if err := c.deleteDocument(prev); err != nil && !IsStatus(err, http.StatusNotFound) {
+			return fmt.Errorf("uploaded the documents, but a previous version is still in the project: %w", err)
+		}
+		if err := c.setManaged(prev, false); err != nil {
 			return err
 		}
 	}
+	return nil
+}
 
-	// Read and upload new file
-	content, err := os.ReadFile(filePath)
+// ExistingDocument returns the name of the remote document a Push with the
+// given file name would replace, or an empty string if there is none.
+func (c *Client) ExistingDocument(fileName string) (string, error) {
+	if err := c.validateConfig(); err != nil {
+		return "", err
+	}
+
+	docs, err := c.listDocuments()
 	if err != nil {
-		return fmt.Errorf("failed to read file: %w", err)
+		return "", err
+	}
+	docID := c.documentID()
+	for _, doc := range c.managed(docs) {
+		if (docID != "" && doc.ID == docID) || (docID == "" && doc.FileName == fileName) {
+			return doc.FileName, nil
+		}
 	}
+	return "", nil
+}
 
-	doc, err := c.uploadDocument(fileName, string(content))
+// DocumentChanges describes how the tracked document changed since sandworm
+// pushed it, e.g. "size 1.2 KB → 1.5 KB, updated 2026-01-02 10:00", in which
+// case it was likely edited in the web interface and replacing it loses those
+// edits. It returns an empty string if it's unchanged, gone, or wasn't
+// recorded when pushed.
+func (c *Client) DocumentChanges() (string, error) {
+	if err := c.validateConfig(); err != nil {
+		return "", err
+	}
+	docID := c.documentID()
+	if docID == "" || !c.config.Has(documentSize) {
+		return "", nil
+	}
+
+	docs, err := c.listDocuments()
 	if err != nil {
-		return err
+		return "", err
+	}
+	for _, doc := range docs {
+		if doc.ID != docID {
+			continue
+		}
+		var changes []string
+		size, err := strconv.Atoi(c.config.Get(documentSize))
+		if err == nil && doc.Content != "" && len(doc.Content) != size {
+			changes = append(changes, fmt.Sprintf("size %s → %s",
+				util.FormatSize(int64(size)), util.FormatSize(int64(len(doc.Content)))))
+		}
+		if recorded := c.config.Get(documentUpdated); recorded != "" && doc.UpdatedAt != "" && doc.UpdatedAt != recorded {
+			changes = append(changes, "updated "+formatTimestamp(doc.UpdatedAt))
+		}
+		return strings.Join(changes, ", "), nil
 	}
+	return "", nil
+}
 
-	return c.config.Set(documentID, doc.ID)
+// formatTimestamp renders an RFC 3339 time of the API in local time, or as is
+// if it doesn't parse.
+func formatTimestamp(value string) string {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return value
+	}
+	return t.Local().Format("2006-01-02 15:04")
 }
 
-// PurgeProjectFiles removes all files from the current project.
-func (c *Client) PurgeProjectFiles(progressFn func(fileName string, current, total int)) (int, error) {
+// ListDocumentNames returns the file names of all documents in the project.
+func (c *Client) ListDocumentNames() ([]string, error) {
 	if err := c.validateConfig(); err != nil {
-		return 0, err
+		return nil, err
 	}
 
 	docs, err := c.listDocuments()
 	if err != nil {
-		return 0, err
+		return nil, err
+	}
+	names := make([]string, len(docs))
+	for i, doc := range docs {
+		names[i] = doc.FileName
+	}
+	return names, nil
+}
+
+// ManagedDocumentNames returns the file names of the documents sandworm
+// uploaded to the project.
+func (c *Client) ManagedDocumentNames() ([]string, error) {
+	if err := c.validateConfig(); err != nil {
+		return nil, err
+	}
+
+	docs, err := c.listDocuments()
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, doc := range c.managed(docs) {
+		names = append(names, doc.FileName)
+	}
+	return names, nil
+}
+
+// DocumentContent returns the content of the project's document with the
+// given file name, whoever uploaded it, or an empty string if there is none.
+func (c *Client) DocumentContent(fileName string) (string, error) {
+	if err := c.validateConfig(); err != nil {
+		return "", err
+	}
+
+	docs, err := c.listDocuments()
+	if err != nil {
+		return "", err
+	}
+	for _, doc := range docs {
+		if doc.FileName == fileName {
+			return doc.Content, nil
+		}
+	}
+	return "", nil
+}
+
+// GetInstructions returns the project's custom instructions.
+func (c *Client) GetInstructions() (string, error) {
+	if err := c.validateConfig(); err != nil {
+		return "", err
+	}
+
+	proj, err := c.getProject()
+	if err != nil {
+		return "", err
 	}
+	return proj.PromptTemplate, nil
+}
+
+// SetInstructions replaces the project's custom instructions.
+func (c *Client) SetInstructions(instructions string) error {
+	if err := c.validateConfig(); err != nil {
+		return err
+	}
+	return c.updateProject(map[string]string{"prompt_template": instructions})
+}
+
+// PurgeFilter selects the documents PurgeCandidates returns. The zero value
+// selects all the documents sandworm uploaded.
+type PurgeFilter struct {
+	OlderThan time.Duration // Only documents created longer ago than this (0 for any age); those of unknown age are kept
+	Prefix    string        // Only documents whose file name starts with this
+	All       bool          // Also documents sandworm didn't upload (by hand, or by other tools)
+}
+
+// matches reports whether the filter selects a document at time now.
+func (f PurgeFilter) matches(doc document, now time.Time) bool {
+	if !strings.HasPrefix(doc.FileName, f.Prefix) {
+		return false
+	}
+	if f.OlderThan <= 0 {
+		return true
+	}
+	created, err := time.Parse(time.RFC3339, doc.CreatedAt)
+	return err == nil && now.Sub(created) > f.OlderThan
+}
+
+// ProjectDocument identifies a document of the project, as listed for
+// confirmation before removing it.
+type ProjectDocument struct {
+	ID       string
+	FileName string
+}
 
+// PurgeCandidates returns the documents selected by filter, to confirm before
+// removing them with RemoveDocuments.
+func (c *Client) PurgeCandidates(filter PurgeFilter) ([]ProjectDocument, error) {
+	docs, err := c.purgeCandidates(filter)
+	if err != nil {
+		return nil, err
+	}
+	return projectDocuments(docs), nil
+}
+
+// RemoveDocuments removes the given documents from the current project, as
+// confirmed: the project isn't listed again, so documents uploaded meanwhile
+// are left alone. It returns the file names of the documents removed; those
+// already gone aren't included.
+func (c *Client) RemoveDocuments(docs []ProjectDocument, progressFn func(fileName string, current, total int)) ([]string, error) {
+	if err := c.validateConfig(); err != nil {
+		return nil, err
+	}
+
+	docID := c.documentID()
+	var removed []string
 	for i, doc := range docs {
 		if progressFn != nil {
 			progressFn(doc.FileName, i+1, len(docs))
 		}
 
-		if err := c.deleteDocument(doc.ID); err != nil {
+		if err := c.deleteDocument(doc.ID); err == nil {
+			removed = append(removed, doc.FileName)
+		} else if !IsStatus(err, http.StatusNotFound) {
 			// Only return error if it's not a 404
-			if !strings.Contains(err.Error(), "404") {
-				return i, err
+			return removed, err
+		}
+		if doc.ID == docID {
+			if err := c.setDocumentID(""); err != nil {
+				return removed, err
 			}
 		}
+		if err := c.setManaged(doc.ID, false); err != nil {
+			return removed, err
+		}
+	}
+
+	return removed, nil
+}
+
+// DownloadDocuments returns the file names and contents of all documents in
+// the project.
+func (c *Client) DownloadDocuments() ([]backup.Document, error) {
+	if err := c.validateConfig(); err != nil {
+		return nil, err
+	}
+
+	data, err := c.request(http.MethodGet, "docs", nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("downloadDocuments: %w", err)
+	}
+	var docs []document
+	if err := decodeResponse(data, "documents", &docs, "uuid", "file_name", "content"); err != nil {
+		return nil, err
+	}
+
+	result := make([]backup.Document, len(docs))
+	for i, doc := range docs {
+		result[i] = backup.Document{Name: doc.FileName, Content: doc.Content}
+	}
+	return result, nil
+}
+
+// ReplaceDocument uploads a document, replacing any document with the same
+// file name (e.g. to restore a backup). Unlike Push, it doesn't rely on the
+// tracked document ID, which it only updates if it replaces that document.
+// The new document is managed by sandworm if a replaced one was.
+func (c *Client) ReplaceDocument(fileName, content string) error {
+	if err := c.validateConfig(); err != nil {
+		return err
+	}
+
+	docs, err := c.listDocuments()
+	if err != nil {
+		return err
+	}
+	managedIDs := c.managedIDs()
+	var previous []string
+	var tracked, managed bool
+	for _, doc := range docs {
+		if doc.FileName != fileName {
+			continue
+		}
+		previous = append(previous, doc.ID)
+		tracked = tracked || doc.ID == c.documentID()
+		managed = managed || slices.Contains(managedIDs, doc.ID)
+	}
+
+	// Uploaded before the previous versions are deleted, like Push
+	doc, err := c.uploadDocument(fileName, content)
+	if err != nil {
+		return keptError(err, len(previous) > 0)
+	}
+	if managed {
+		if err := c.setManaged(doc.ID, true); err != nil {
+			return err
+		}
+	}
+	if tracked {
+		if err := c.recordDocument(doc, content); err != nil {
+			return err
+		}
 	}
+	return c.deletePrevious(fileName, doc.ID, previous)
+}
+
+// StaleDocuments returns the documents sandworm uploaded whose file name
+// isn't in keep, to confirm before removing them with RemoveDocuments so that
+// the project mirrors the pushed files. Other documents (knowledge files
+// uploaded by hand or by other tools) are left alone.
+func (c *Client) StaleDocuments(keep []string) ([]ProjectDocument, error) {
+	docs, err := c.staleDocuments(keep)
+	if err != nil {
+		return nil, err
+	}
+	return projectDocuments(docs), nil
+}
 
-	if err := c.config.Delete(documentID); err != nil {
-		return len(docs), err
+// DeleteDocuments removes the documents sandworm uploaded with the given file
+// names (e.g. the leftover parts of a previously split bundle). It returns the
+// number of documents removed.
+func (c *Client) DeleteDocuments(fileNames []string) (int, error) {
+	if err := c.validateConfig(); err != nil {
+		return 0, err
 	}
 
-	return len(docs), nil
+	docs, err := c.listDocuments()
+	if err != nil {
+		return 0, err
+	}
+	deleted := 0
+	for _, doc := range c.managed(docs) {
+		if !slices.Contains(fileNames, doc.FileName) {
+			continue
+		}
+		if err := c.deleteDocument(doc.ID); err != nil && !IsStatus(err, http.StatusNotFound) {
+			return deleted, err
+		}
+		if err := c.setManaged(doc.ID, false); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
 }
 
 // MARK: Internal helper functions
 
+// deleteDocuments deletes documents sandworm uploaded, e.g. the previous
+// versions of documents before uploading them.
+func (c *Client) deleteDocuments(ids []string) error {
+	for _, id := range ids {
+		if err := c.deleteDocument(id); err != nil && !IsStatus(err, http.StatusNotFound) {
+			return err
+		}
+		if err := c.setManaged(id, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// keptError is the error of a failed upload, wrapping ErrPreviousKept if it
+// was replacing documents.
+func keptError(err error, replacing bool) error {
+	if !replacing {
+		return err
+	}
+	return fmt.Errorf("%w (%w)", err, ErrPreviousKept)
+}
+
+// deletePrevious deletes the previous versions of a document once its new
+// version (id) is uploaded. Versions that can't be deleted stay managed, so
+// that the next push (or purge) removes them.
+func (c *Client) deletePrevious(fileName, id string, previous []string) error {
+	for _, prev := range previous {
+		if prev == id {
+			continue
+		}
+		if err := c.deleteDocument(prev); err != nil && !IsStatus(err, http.StatusNotFound) {
+			return fmt.Errorf("uploaded %s, but the previous version is still in the project: %w", fileName, err)
+		}
+		if err := c.setManaged(prev, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// managed returns the documents sandworm uploaded, out of docs.
+func (c *Client) managed(docs []document) []document {
+	ids := c.managedIDs()
+	var managed []document
+	for _, doc := range docs {
+		if slices.Contains(ids, doc.ID) {
+			managed = append(managed, doc)
+		}
+	}
+	return managed
+}
+
+// managedIDs returns the IDs of the documents sandworm uploaded to the target
+// project, including the tracked document (the only one known to versions
+// that didn't record them).
+func (c *Client) managedIDs() []string {
+	var ids []string
+	for _, id := range strings.Split(c.config.Get(managedSection+"."+c.projectID()), ",") {
+		if id != "" {
+			ids = append(ids, id)
+		}
+	}
+	if id := c.documentID(); id != "" && !slices.Contains(ids, id) {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// setManaged records that sandworm uploaded a document to the target project,
+// or forgets it once deleted.
+func (c *Client) setManaged(id string, managed bool) error {
+	key := managedSection + "." + c.projectID()
+	var ids []string
+	for _, existing := range strings.Split(c.config.Get(key), ",") {
+		if existing != "" && existing != id {
+			ids = append(ids, existing)
+		}
+	}
+	if managed {
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		if !c.config.Has(key) {
+			return nil
+		}
+		return c.config.Delete(key)
+	}
+	return c.config.Set(key, strings.Join(ids, ","))
+}
+
+// projectDocuments returns the identifiers of docs.
+func projectDocuments(docs []document) []ProjectDocument {
+	refs := make([]ProjectDocument, len(docs))
+	for i, doc := range docs {
+		refs[i] = ProjectDocument{ID: doc.ID, FileName: doc.FileName}
+	}
+	return refs
+}
+
+// purgeCandidates returns the project's documents selected by filter.
+func (c *Client) purgeCandidates(filter PurgeFilter) ([]document, error) {
+	if err := c.validateConfig(); err != nil {
+		return nil, err
+	}
+
+	docs, err := c.listDocuments()
+	if err != nil {
+		return nil, err
+	}
+	if !filter.All {
+		docs = c.managed(docs)
+	}
+	now := time.Now()
+	var selected []document
+	for _, doc := range docs {
+		if filter.matches(doc, now) {
+			selected = append(selected, doc)
+		}
+	}
+	return selected, nil
+}
+
+// staleDocuments returns the documents sandworm uploaded whose file name
+// isn't in keep.
+func (c *Client) staleDocuments(keep []string) ([]document, error) {
+	if err := c.validateConfig(); err != nil {
+		return nil, err
+	}
+
+	docs, err := c.listDocuments()
+	if err != nil {
+		return nil, err
+	}
+	var stale []document
+	for _, doc := range c.managed(docs) {
+		if !slices.Contains(keep, doc.FileName) {
+			stale = append(stale, doc)
+		}
+	}
+	return stale, nil
+}
+
+// promptCreateProject asks for a project name (defaulting to the project
+// directory name) and creates it. Returns nil if the user declines.
+func (c *Client) promptCreateProject() (*project, error) {
+	defaultName := "sandworm"
+	if dir, err := filepath.Abs(filepath.Dir(c.config.Source(projectID))); err == nil {
+		defaultName = filepath.Base(dir)
+	}
+
+	fmt.Printf("\nCreate a new project? Enter a name [%s] (or '-' to cancel): ", defaultName)
+	name, err := readLine()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read project name: %w", err)
+	}
+	switch name {
+	case "-":
+		return nil, nil
+	case "":
+		name = defaultName
+	}
+
+	created, err := c.createProject(name)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep the metadata cache in sync with the new project
+	cache := c.loadCache()
+	cache.Projects[c.orgID()] = append(cache.Projects[c.orgID()], *created)
+	if err := c.saveCache(); err != nil {
+		return nil, err
+	}
+
+	fmt.Printf("Created project '%s'\n", created.Name)
+	return created, nil
+}
+
+// sessionKeyName returns the config key holding the active session key: the
+// account bound to the project, else the default account, else the legacy
+// single session key.
+func (c *Client) sessionKeyName() string {
+	if account := c.Account(); account != "" {
+		return AccountKey(account)
+	}
+	return sessionKey
+}
+
+// AccountKey returns the config key storing the session key for an account.
+func AccountKey(label string) string {
+	return "accounts." + label
+}
+
+// resolveTarget resolves the organization/project queries set via SetTarget.
+func (c *Client) resolveTarget() error {
+	if c.orgQuery != "" {
+		match, err := resolveCached(c.organizations, c.orgQuery, func(o organization) string { return o.ID })
+		if err != nil {
+			return fmt.Errorf("organization %w", err)
+		}
+		c.orgOverride = match.ID
+	}
+
+	if c.projectQuery != "" {
+		if c.orgID() == "" {
+			return fmt.Errorf("no organization configured; run 'sandworm setup' or pass --org")
+		}
+		match, err := resolveCached(c.projects, c.projectQuery, func(p project) string { return p.ID })
+		if err != nil {
+			return fmt.Errorf("project %w", err)
+		}
+		c.projectOverride = match.ID
+	} else if c.orgOverride != "" && c.orgOverride != c.config.Get(organizationID) {
+		// The configured project belongs to the configured organization
+		return fmt.Errorf("--project is required when targeting a different organization")
+	}
+
+	return nil
+}
+
+// orgID returns the organization to operate on (override or configured).
+func (c *Client) orgID() string {
+	if c.orgOverride != "" {
+		return c.orgOverride
+	}
+	return c.config.Get(organizationID)
+}
+
+// projectID returns the project to operate on (override or configured).
+func (c *Client) projectID() string {
+	if c.projectOverride != "" {
+		return c.projectOverride
+	}
+	return c.config.Get(projectID)
+}
+
+// isOverridden reports whether the client targets something other than the
+// configured organization/project.
+func (c *Client) isOverridden() bool {
+	return (c.orgOverride != "" && c.orgOverride != c.config.Get(organizationID)) ||
+		(c.projectOverride != "" && c.projectOverride != c.config.Get(projectID))
+}
+
+// documentID returns the tracked document ID. The configured one is only
+// meaningful for the configured project.
+func (c *Client) documentID() string {
+	if c.isOverridden() {
+		return ""
+	}
+	return c.config.Get(documentID)
+}
+
+// setDocumentID persists the tracked document ID (or deletes it if empty).
+// Nothing is persisted when targeting an overridden project.
+func (c *Client) setDocumentID(id string) error {
+	if c.isOverridden() {
+		return nil
+	}
+	if id == "" {
+		for _, key := range []string{documentSize, documentUpdated} {
+			if c.config.Has(key) {
+				if err := c.config.Delete(key); err != nil {
+					return err
+				}
+			}
+		}
+		return c.config.Delete(documentID)
+	}
+	return c.config.Set(documentID, id)
+}
+
+// recordDocument tracks an uploaded document, with its size and update time
+// as reported by the API, for DocumentChanges.
+func (c *Client) recordDocument(doc *document, content string) error {
+	if err := c.setDocumentID(doc.ID); err != nil || c.isOverridden() {
+		return err
+	}
+	if doc.Content != "" { // The API's copy, which may be normalized
+		content = doc.Content
+	}
+	if err := c.config.Set(documentSize, strconv.Itoa(len(content))); err != nil {
+		return err
+	}
+	updated := cmp.Or(doc.UpdatedAt, doc.CreatedAt)
+	if updated == "" {
+		if !c.config.Has(documentUpdated) {
+			return nil
+		}
+		return c.config.Delete(documentUpdated)
+	}
+	return c.config.Set(documentUpdated, updated)
+}
+
 // validateConfig ensures all required configuration values are present
 func (c *Client) validateConfig() error {
-	required := []string{sessionKey, organizationID, projectID}
 	var missing []string
-	for _, key := range required {
-		if !c.config.Has(key) {
-			missing = append(missing, key)
-		}
+	if !c.config.Has(c.sessionKeyName()) {
+		missing = append(missing, c.sessionKeyName())
+	}
+	if c.orgID() == "" {
+		missing = append(missing, organizationID)
+	}
+	if c.projectID() == "" {
+		missing = append(missing, projectID)
 	}
 	if len(missing) > 0 {
-		return fmt.Errorf("missing required config keys: %s", strings.Join(missing, ", "))
+		return fmt.Errorf("%w: %s", ErrMissingConfig, strings.Join(missing, ", "))
 	}
 	return nil
 }
 
 // makeRequest performs an HTTP request to the Claude API
 func (c *Client) makeRequest(method, path string, body any) ([]byte, error) {
+	url := c.apiURL() + path
+	req, err := c.newRequest(method, url, body)
+	if err != nil {
+		return nil, err
+	}
+
+	// Revalidate cached listings rather than downloading them again
+	var cached *cachedResponse
+	if method == http.MethodGet {
+		if cached = c.cachedResponse(url); cached != nil {
+			if cached.ETag != "" {
+				req.Header.Set("If-None-Match", cached.ETag)
+			}
+			if cached.LastModified != "" {
+				req.Header.Set("If-Modified-Since", cached.LastModified)
+			}
+		}
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	reader, err := responseReader(resp)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	// Check for error status codes
+	notModified := resp.StatusCode == http.StatusNotModified && cached != nil
+	if (resp.StatusCode < 200 || resp.StatusCode >= 300) && !notModified {
+		return nil, newAPIError(resp, respBody)
+	}
+
+	if err := c.updateSessionKey(resp); err != nil {
+		return nil, err
+	}
+
+	if notModified {
+		return cached.Body, nil
+	}
+	if method == http.MethodGet {
+		c.storeResponse(url, resp.Header, respBody)
+	}
+	return respBody, nil
+}
+
+// newRequest creates an API request with a JSON body (if not nil) and the
+// headers claude.ai expects.
+func (c *Client) newRequest(method, url string, body any) (*http.Request, error) {
+	if method != http.MethodGet && ReadOnly() {
+		return nil, fmt.Errorf("%w: not sending %s %s", ErrReadOnly, method, url)
+	}
 	var bodyReader io.Reader
 	if body != nil {
 		data, err := json.Marshal(body)
@@ -233,16 +1162,22 @@ func (c *Client) makeRequest(method, path string, body any) ([]byte, error) {
 		bodyReader = bytes.NewReader(data)
 	}
 
-	req, err := http.NewRequest(method, baseURL+"/api"+path, bodyReader)
+	req, err := http.NewRequest(method, url, bodyReader)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
+	// Unlock an encrypted config (asking for its passphrase) rather than
+	// sending an empty session key
+	if err := c.config.Unlock(); err != nil {
+		return nil, err
+	}
+
 	// Set headers
 	headers := map[string]string{
 		"Content-Type": "application/json",
 		"User-Agent":   "Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:129.0) Gecko/20100101 Firefox/129.0",
-		"Cookie":       fmt.Sprintf("sessionKey=%s", c.config.Get(sessionKey)),
+		"Cookie":       fmt.Sprintf("sessionKey=%s", cmp.Or(c.sessionKeyOverride, c.config.Get(c.sessionKeyName()))),
 		// NB: Setting this particular Accept-Encoding because Claude will 403 when
 		// under heavy load (funny http code choice...) when the client doesn't
 		// explicitly state it accepts compressed payloads. Golang's HTTP client
@@ -256,119 +1191,123 @@ func (c *Client) makeRequest(method, path string, body any) ([]byte, error) {
 	for k, v := range headers {
 		req.Header.Set(k, v)
 	}
+	return req, nil
+}
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
-	}
-	defer func() { _ = resp.Body.Close() }()
-
-	// Read response body w/ manual decoding (necessary since we're using a custom
-	// Accept-Encoding header above).
-	var respBody []byte
+// responseReader returns the decoded body of a response (manual decoding is
+// necessary since we're using a custom Accept-Encoding header).
+func responseReader(resp *http.Response) (io.Reader, error) {
 	switch resp.Header.Get("Content-Encoding") {
 	case "gzip":
 		gz, err := gzip.NewReader(resp.Body)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create gzip reader: %w", err)
 		}
-		defer func() { _ = gz.Close() }()
-		respBody, err = io.ReadAll(gz)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read gzip response: %w", err)
-		}
+		return gz, nil
 	default:
 		// identity or no encoding
-		respBody, err = io.ReadAll(resp.Body)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read response body: %w", err)
-		}
-	}
-
-	// Check for error status codes
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("API request failed: %d - %s", resp.StatusCode, string(respBody))
+		return resp.Body, nil
 	}
+}
 
-	// Update session key if it changed
+// updateSessionKey persists the session key of a response if it changed.
+func (c *Client) updateSessionKey(resp *http.Response) error {
 	if cookie := resp.Header.Get("Set-Cookie"); cookie != "" {
 		if matches := sessionKeyRegex.FindStringSubmatch(cookie); matches != nil {
 			newKey := matches[1]
-			if newKey != c.config.Get(sessionKey) {
-				if err := c.config.Set(sessionKey, newKey); err != nil {
-					return nil, err
+			if newKey != c.config.Get(c.sessionKeyName()) {
+				if err := c.config.Set(c.sessionKeyName(), newKey); err != nil {
+					return err
 				}
 			}
 		}
 	}
-
-	return respBody, nil
+	return nil
 }
 
 // MARK: Anthropic API requests
 
 func (c *Client) listOrganizations() ([]organization, error) {
-	data, err := c.makeRequest(http.MethodGet, "/organizations", nil)
+	data, err := c.request(http.MethodGet, "organizations", nil, nil)
 	if err != nil {
 		return nil, fmt.Errorf("listOrganizations: %w", err)
 	}
 
 	var orgs []organization
-	if err := json.Unmarshal(data, &orgs); err != nil {
-		return nil, fmt.Errorf("failed to parse organizations: %w", err)
+	if err := decodeResponse(data, "organizations", &orgs, "uuid", "name"); err != nil {
+		return nil, err
 	}
 	return orgs, nil
 }
 
 func (c *Client) listProjects() ([]project, error) {
-	data, err := c.makeRequest(
-		http.MethodGet,
-		fmt.Sprintf("/organizations/%s/projects", c.config.Get(organizationID)),
-		nil,
-	)
+	data, err := c.request(http.MethodGet, "projects", nil, nil)
 	if err != nil {
 		return nil, fmt.Errorf("listProjects: %w", err)
 	}
 
 	var projects []project
-	if err := json.Unmarshal(data, &projects); err != nil {
-		return nil, fmt.Errorf("failed to parse projects: %w", err)
+	if err := decodeResponse(data, "projects", &projects, "uuid", "name"); err != nil {
+		return nil, err
 	}
 	return projects, nil
 }
 
+func (c *Client) createProject(name string) (*project, error) {
+	body := map[string]any{
+		"name":        name,
+		"description": "",
+		"is_private":  true,
+	}
+
+	data, err := c.request(http.MethodPost, "projects", nil, body)
+	if err != nil {
+		return nil, fmt.Errorf("createProject: %w", err)
+	}
+
+	var proj project
+	if err := decodeResponse(data, "project", &proj, "uuid", "name"); err != nil {
+		return nil, err
+	}
+	return &proj, nil
+}
+
+func (c *Client) getProject() (*project, error) {
+	data, err := c.request(http.MethodGet, "project", nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("getProject: %w", err)
+	}
+
+	var proj project
+	if err := decodeResponse(data, "project", &proj, "uuid", "name"); err != nil {
+		return nil, err
+	}
+	return &proj, nil
+}
+
+func (c *Client) updateProject(fields map[string]string) error {
+	_, err := c.request(http.MethodPut, "project", nil, fields)
+	if err != nil {
+		return fmt.Errorf("updateProject: %w", err)
+	}
+	return nil
+}
+
 func (c *Client) listDocuments() ([]document, error) {
-	data, err := c.makeRequest(
-		http.MethodGet,
-		fmt.Sprintf(
-			"/organizations/%s/projects/%s/docs",
-			c.config.Get(organizationID),
-			c.config.Get(projectID),
-		),
-		nil,
-	)
+	data, err := c.request(http.MethodGet, "docs", nil, nil)
 	if err != nil {
 		return nil, fmt.Errorf("listDocuments: %w", err)
 	}
 
 	var docs []document
-	if err := json.Unmarshal(data, &docs); err != nil {
-		return nil, fmt.Errorf("failed to parse documents: %w", err)
+	if err := decodeResponse(data, "documents", &docs, "uuid", "file_name"); err != nil {
+		return nil, err
 	}
 	return docs, nil
 }
 
 func (c *Client) deleteDocument(id string) error {
-	_, err := c.makeRequest(
-		http.MethodDelete,
-		fmt.Sprintf(
-			"/organizations/%s/projects/%s/docs/%s",
-			c.config.Get(organizationID),
-			c.config.Get(projectID),
-			id,
-		),
-		nil,
-	)
+	_, err := c.request(http.MethodDelete, "doc", map[string]string{"doc": id}, nil)
 	if err != nil {
 		return fmt.Errorf("deleteDocument: %w", err)
 	}
@@ -381,22 +1320,14 @@ func (c *Client) uploadDocument(fileName, content string) (*document, error) {
 		"content":   content,
 	}
 
-	data, err := c.makeRequest(
-		http.MethodPost,
-		fmt.Sprintf(
-			"/organizations/%s/projects/%s/docs",
-			c.config.Get(organizationID),
-			c.config.Get(projectID),
-		),
-		body,
-	)
+	data, err := c.request(http.MethodPost, "docs", nil, body)
 	if err != nil {
 		return nil, fmt.Errorf("uploadDocument: %w", err)
 	}
 
 	var doc document
-	if err := json.Unmarshal(data, &doc); err != nil {
-		return nil, fmt.Errorf("failed to parse document: %w", err)
+	if err := decodeResponse(data, "document", &doc, "uuid", "file_name"); err != nil {
+		return nil, err
 	}
 	return &doc, nil
 }
@@ -409,14 +1340,18 @@ type organization struct {
 }
 
 type project struct {
-	ID         string    `json:"uuid"`
-	Name       string    `json:"name"`
-	ArchivedAt time.Time `json:"archived_at,omitempty"`
+	ID             string    `json:"uuid"`
+	Name           string    `json:"name"`
+	ArchivedAt     time.Time `json:"archived_at,omitempty"`
+	PromptTemplate string    `json:"prompt_template,omitempty"`
 }
 
 type document struct {
-	ID       string `json:"uuid"`
-	FileName string `json:"file_name"`
+	ID        string `json:"uuid"`
+	FileName  string `json:"file_name"`
+	Content   string `json:"content,omitempty"`
+	CreatedAt string `json:"created_at,omitempty"` // RFC 3339, if known
+	UpdatedAt string `json:"updated_at,omitempty"` // RFC 3339, if known
 }
 
 // MARK: User interaction (for setup)
@@ -445,6 +1380,39 @@ func selectFromList[T interface{ GetName() string }](items []T) T {
 	}
 }
 
+// findByIDOrName returns the single item whose ID matches exactly or whose name
+// matches case-insensitively.
+func findByIDOrName[T interface{ GetName() string }](items []T, query string, id func(T) string) (T, error) {
+	var matches []T
+	for _, item := range items {
+		if id(item) == query {
+			return item, nil
+		}
+		if strings.EqualFold(item.GetName(), query) {
+			matches = append(matches, item)
+		}
+	}
+
+	var zero T
+	switch len(matches) {
+	case 0:
+		return zero, fmt.Errorf("'%s' %w", query, ErrNotFound)
+	case 1:
+		return matches[0], nil
+	default:
+		return zero, fmt.Errorf("'%s' is ambiguous (%d matches); use its ID instead", query, len(matches))
+	}
+}
+
+// readLine reads a full line (which may contain spaces) from stdin, trimmed.
+func readLine() (string, error) {
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
 // GetName implementations for our types to satisfy the generic constraint
 func (o organization) GetName() string { return o.Name }
 func (p project) GetName() string      { return p.Name }
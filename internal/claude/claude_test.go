@@ -0,0 +1,542 @@
+package claude
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFindByIDOrName(t *testing.T) {
+	projects := []project{
+		{ID: "p-1", Name: "Acme Backend"},
+		{ID: "p-2", Name: "Acme Frontend"},
+		{ID: "p-3", Name: "acme backend"},
+		{ID: "p-4", Name: "Staging"},
+	}
+	id := func(p project) string { return p.ID }
+
+	tests := []struct {
+		name     string
+		query    string
+		expected string
+		wantErr  bool
+	}{
+		{name: "by id", query: "p-2", expected: "p-2"},
+		{name: "by name", query: "staging", expected: "p-4"},
+		{name: "ambiguous name", query: "Acme Backend", wantErr: true},
+		{name: "not found", query: "Production", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, err := findByIDOrName(projects, tt.query, id)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error for %q, got match %v", tt.query, match)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if match.ID != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, match.ID)
+			}
+		})
+	}
+}
+
+func TestResolveCached(t *testing.T) {
+	stale := []project{{ID: "p-1", Name: "Old"}}
+	fresh := []project{{ID: "p-1", Name: "Old"}, {ID: "p-2", Name: "New"}}
+	fetches := 0
+	list := func(refresh bool) ([]project, error) {
+		if refresh {
+			fetches++
+			return fresh, nil
+		}
+		return stale, nil
+	}
+	id := func(p project) string { return p.ID }
+
+	if _, err := resolveCached(list, "old", id); err != nil || fetches != 0 {
+		t.Errorf("Expected cached hit without fetching, got err=%v fetches=%d", err, fetches)
+	}
+
+	match, err := resolveCached(list, "New", id)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if match.ID != "p-2" || fetches != 1 {
+		t.Errorf("Expected p-2 after one refresh, got %s (fetches=%d)", match.ID, fetches)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/projects") {
+			_, _ = w.Write([]byte(`[{"uuid":"p-1","name":"Backend"},{"uuid":"p-2","name":"Frontend"}]`))
+			return
+		}
+		_, _ = w.Write([]byte(`[{"uuid":"o-1","name":"Acme"}]`))
+	}))
+	defer server.Close()
+	c := newTestClient(t, server.URL)
+
+	if id, name, err := c.LookupOrganization("acme"); err != nil || id != "o-1" || name != "Acme" {
+		t.Errorf("Expected o-1 (Acme), got %s (%s), %v", id, name, err)
+	}
+	if id, name, err := c.LookupProject("p-2"); err != nil || id != "p-2" || name != "Frontend" {
+		t.Errorf("Expected p-2 (Frontend), got %s (%s), %v", id, name, err)
+	}
+	if _, _, err := c.LookupProject("p-3"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for an unknown project, got %v", err)
+	}
+}
+
+func TestPruneDocuments(t *testing.T) {
+	var deleted []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			deleted = append(deleted, path.Base(r.URL.Path))
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		_, _ = w.Write([]byte(`[{"uuid":"d-1","file_name":"project.txt"},{"uuid":"d-2","file_name":"old.txt"},{"uuid":"d-3","file_name":"notes.md"}]`))
+	}))
+	defer server.Close()
+
+	// notes.md was uploaded by hand
+	c := newTestClient(t, server.URL)
+	if err := c.config.Set(managedSection+".p-1", "d-1,d-2"); err != nil {
+		t.Fatal(err)
+	}
+	keep := []string{"project.txt"}
+	stale, err := c.StaleDocuments(keep)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(stale) != 1 || stale[0] != (ProjectDocument{ID: "d-2", FileName: "old.txt"}) {
+		t.Errorf("Expected only old.txt to be stale, got %v", stale)
+	}
+
+	removed, err := c.RemoveDocuments(stale, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if strings.Join(removed, ",") != "old.txt" || strings.Join(deleted, ",") != "d-2" {
+		t.Errorf("Expected d-2 to be deleted, got %v: %v", removed, deleted)
+	}
+	if ids := c.managedIDs(); strings.Join(ids, ",") != "d-1" {
+		t.Errorf("Expected d-2 to be forgotten, got %v", ids)
+	}
+}
+
+func TestPushManagedDocuments(t *testing.T) {
+	var deleted []string
+	uploads := 1
+	docs := `[{"uuid":"d-1","file_name":"project.txt"}]`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodDelete:
+			deleted = append(deleted, path.Base(r.URL.Path))
+			w.WriteHeader(http.StatusNoContent)
+		case http.MethodPost:
+			uploads++
+			_, _ = fmt.Fprintf(w, `{"uuid":"d-%d","file_name":"project.txt"}`, uploads)
+		default:
+			_, _ = w.Write([]byte(docs))
+		}
+	}))
+	defer server.Close()
+
+	bundle := filepath.Join(t.TempDir(), "bundle.txt")
+	if err := os.WriteFile(bundle, []byte("bundle"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// A document with the same name that sandworm didn't upload is kept
+	c := newTestClient(t, server.URL)
+	if existing, err := c.ExistingDocument("project.txt"); err != nil || existing != "" {
+		t.Errorf("Expected no document to replace, got %q, %v", existing, err)
+	}
+	if err := c.Push(bundle, "project.txt"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(deleted) != 0 {
+		t.Errorf("Expected the unmanaged document to be kept, got %v deleted", deleted)
+	}
+	if ids := c.managedIDs(); strings.Join(ids, ",") != "d-2" {
+		t.Errorf("Expected the pushed document to be managed, got %v", ids)
+	}
+
+	// Its own are replaced
+	docs = `[{"uuid":"d-1","file_name":"project.txt"},{"uuid":"d-2","file_name":"project.txt"}]`
+	if err := c.PushDocuments([]Upload{{FileName: "project.txt", Content: "bundle"}}, nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if strings.Join(deleted, ",") != "d-2" {
+		t.Errorf("Expected only d-2 to be replaced, got %v deleted", deleted)
+	}
+}
+
+func TestPushUploadsBeforeDeleting(t *testing.T) {
+	var requests []string
+	failUpload := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method)
+		switch r.Method {
+		case http.MethodDelete:
+			w.WriteHeader(http.StatusNoContent)
+		case http.MethodPost:
+			if failUpload {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			_, _ = w.Write([]byte(`{"uuid":"d-2","file_name":"project.txt"}`))
+		default:
+			_, _ = w.Write([]byte(`[{"uuid":"d-1","file_name":"project.txt"}]`))
+		}
+	}))
+	defer server.Close()
+
+	bundle := filepath.Join(t.TempDir(), "bundle.txt")
+	if err := os.WriteFile(bundle, []byte("bundle"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	c := newTestClient(t, server.URL)
+	if err := c.config.Set(documentID, "d-1"); err != nil {
+		t.Fatal(err)
+	}
+
+	// A failed upload leaves the previous version in place
+	failUpload = true
+	if err := c.Push(bundle, "project.txt"); !errors.Is(err, ErrPreviousKept) {
+		t.Fatalf("Expected the failed upload to be reported, got %v", err)
+	}
+	if slices.Contains(requests, http.MethodDelete) || c.documentID() != "d-1" {
+		t.Errorf("Expected the previous version to be kept, got requests %v and document %s", requests, c.documentID())
+	}
+
+	requests = nil
+	failUpload = false
+	if err := c.Push(bundle, "project.txt"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if post, del := slices.Index(requests, http.MethodPost), slices.Index(requests, http.MethodDelete); post < 0 || del < post {
+		t.Errorf("Expected the upload before the deletion, got %v", requests)
+	}
+	if ids := c.managedIDs(); c.documentID() != "d-2" || strings.Join(ids, ",") != "d-2" {
+		t.Errorf("Expected d-2 to be tracked and managed only, got %s and %v", c.documentID(), ids)
+	}
+}
+
+func TestPushDeleteFirst(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method)
+		switch r.Method {
+		case http.MethodDelete:
+			w.WriteHeader(http.StatusNoContent)
+		case http.MethodPost:
+			_, _ = w.Write([]byte(`{"uuid":"d-2","file_name":"project.txt"}`))
+		default:
+			_, _ = w.Write([]byte(`[{"uuid":"d-1","file_name":"project.txt"}]`))
+		}
+	}))
+	defer server.Close()
+
+	bundle := filepath.Join(t.TempDir(), "bundle.txt")
+	if err := os.WriteFile(bundle, []byte("bundle"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	c := newTestClient(t, server.URL)
+	if err := c.config.Set(documentID, "d-1"); err != nil {
+		t.Fatal(err)
+	}
+	c.SetDeleteFirst(true)
+	if err := c.Push(bundle, "project.txt"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if post, del := slices.Index(requests, http.MethodPost), slices.Index(requests, http.MethodDelete); del < 0 || post < del {
+		t.Errorf("Expected the deletion before the upload, got %v", requests)
+	}
+	if ids := c.managedIDs(); c.documentID() != "d-2" || strings.Join(ids, ",") != "d-2" {
+		t.Errorf("Expected d-2 to be tracked and managed only, got %s and %v", c.documentID(), ids)
+	}
+}
+
+func TestPushDocuments(t *testing.T) {
+	var deleted []string
+	uploads, failAt := 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodDelete:
+			deleted = append(deleted, path.Base(r.URL.Path))
+			w.WriteHeader(http.StatusNoContent)
+		case http.MethodPost:
+			uploads++
+			if uploads == failAt {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			_, _ = fmt.Fprintf(w, `{"uuid":"n-%d","file_name":"part"}`, uploads)
+		default:
+			_, _ = w.Write([]byte(`[{"uuid":"d-1","file_name":"project-1.txt"},{"uuid":"d-2","file_name":"project-2.txt"}]`))
+		}
+	}))
+	defer server.Close()
+
+	c := newTestClient(t, server.URL)
+	if err := c.config.Set(managedSection+".p-1", "d-1,d-2"); err != nil {
+		t.Fatal(err)
+	}
+	parts := []Upload{{FileName: "project-1.txt", Content: "a"}, {FileName: "project-2.txt", Content: "b"}}
+
+	// A failed upload removes the parts uploaded before it, leaving the
+	// previous ones as they were
+	failAt = 2
+	if err := c.PushDocuments(parts, nil); !errors.Is(err, ErrPreviousKept) {
+		t.Fatalf("Expected the failed upload to be reported, got %v", err)
+	}
+	if strings.Join(deleted, ",") != "n-1" {
+		t.Errorf("Expected only the new first part to be deleted, got %v", deleted)
+	}
+	if ids := c.managedIDs(); strings.Join(ids, ",") != "d-1,d-2" {
+		t.Errorf("Expected the previous parts to stay managed, got %v", ids)
+	}
+
+	// Previous parts are only deleted once all the new ones are uploaded
+	deleted, uploads, failAt = nil, 0, 0
+	var progress []string
+	err := c.PushDocuments(parts, func(name string, current, total int) {
+		progress = append(progress, fmt.Sprintf("%d/%d %s", current, total, name))
+		if len(deleted) > 0 {
+			t.Errorf("Expected no deletion before uploading %s, got %v", name, deleted)
+		}
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if strings.Join(progress, ",") != "1/2 project-1.txt,2/2 project-2.txt" {
+		t.Errorf("Unexpected progress: %v", progress)
+	}
+	if strings.Join(deleted, ",") != "d-1,d-2" {
+		t.Errorf("Expected the previous parts to be deleted, got %v", deleted)
+	}
+	if ids := c.managedIDs(); strings.Join(ids, ",") != "n-1,n-2" {
+		t.Errorf("Expected the new parts to be managed, got %v", ids)
+	}
+}
+
+func TestReadOnly(t *testing.T) {
+	var methods []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method)
+		_, _ = w.Write([]byte(`[{"uuid":"d-1","file_name":"project.txt"}]`))
+	}))
+	defer server.Close()
+	t.Setenv(ReadOnlyEnv, "true")
+
+	c := newTestClient(t, server.URL)
+	if names, err := c.ListDocumentNames(); err != nil || len(names) != 1 {
+		t.Errorf("Expected listing to work, got %v, %v", names, err)
+	}
+	if err := c.PushDocuments([]Upload{{FileName: "project.txt", Content: "bundle"}}, nil); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Expected ErrReadOnly, got %v", err)
+	}
+	if err := c.SetInstructions("Be brief"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Expected ErrReadOnly, got %v", err)
+	}
+	if strings.Join(methods, ",") != "GET,GET" {
+		t.Errorf("Expected only GET requests to be sent, got %v", methods)
+	}
+}
+
+func TestDocumentChanges(t *testing.T) {
+	remote := `{"uuid":"d-1","file_name":"project.txt","content":"bundle","updated_at":"2026-01-02T10:00:00Z"}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			_, _ = w.Write([]byte(`{"uuid":"d-1","file_name":"project.txt","updated_at":"2026-01-02T10:00:00Z"}`))
+		default:
+			_, _ = w.Write([]byte("[" + remote + "]"))
+		}
+	}))
+	defer server.Close()
+
+	bundle := filepath.Join(t.TempDir(), "bundle.txt")
+	if err := os.WriteFile(bundle, []byte("bundle"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	c := newTestClient(t, server.URL)
+	if changes, err := c.DocumentChanges(); err != nil || changes != "" {
+		t.Errorf("Expected no changes before pushing, got %q, %v", changes, err)
+	}
+	if err := c.Push(bundle, "project.txt"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if changes, err := c.DocumentChanges(); err != nil || changes != "" {
+		t.Errorf("Expected no changes right after pushing, got %q, %v", changes, err)
+	}
+
+	remote = `{"uuid":"d-1","file_name":"project.txt","content":"bundle, edited","updated_at":"2026-01-03T10:00:00Z"}`
+	changes, err := c.DocumentChanges()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(changes, "size 6.0 B → 14.0 B") || !strings.Contains(changes, "updated 2026-01-0") {
+		t.Errorf("Expected size and update time changes, got %q", changes)
+	}
+}
+
+func TestPurgeDocuments(t *testing.T) {
+	old := time.Now().Add(-10 * 24 * time.Hour).UTC().Format(time.RFC3339Nano)
+	recent := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	var deleted []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			deleted = append(deleted, path.Base(r.URL.Path))
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		_, _ = fmt.Fprintf(w, `[{"uuid":"d-1","file_name":"sandworm-a.txt","created_at":%q},`+
+			`{"uuid":"d-2","file_name":"sandworm-b.txt","created_at":%q},`+
+			`{"uuid":"d-3","file_name":"notes.md","created_at":%q},`+
+			`{"uuid":"d-4","file_name":"sandworm-c.txt"}]`, old, recent, old)
+	}))
+	defer server.Close()
+
+	// notes.md was uploaded by hand
+	c := newTestClient(t, server.URL)
+	if err := c.config.Set(managedSection+".p-1", "d-1,d-2,d-4"); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		filter   PurgeFilter
+		expected string
+	}{
+		{name: "managed", expected: "sandworm-a.txt,sandworm-b.txt,sandworm-c.txt"},
+		{name: "all", filter: PurgeFilter{All: true}, expected: "sandworm-a.txt,sandworm-b.txt,notes.md,sandworm-c.txt"},
+		{name: "older than", filter: PurgeFilter{OlderThan: 7 * 24 * time.Hour, All: true}, expected: "sandworm-a.txt,notes.md"},
+		{name: "prefix", filter: PurgeFilter{Prefix: "sandworm-", All: true}, expected: "sandworm-a.txt,sandworm-b.txt,sandworm-c.txt"},
+		{name: "both", filter: PurgeFilter{OlderThan: 7 * 24 * time.Hour, Prefix: "sandworm-"}, expected: "sandworm-a.txt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			docs, err := c.PurgeCandidates(tt.filter)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			var names []string
+			for _, doc := range docs {
+				names = append(names, doc.FileName)
+			}
+			if got := strings.Join(names, ","); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+
+	// Only the confirmed documents are deleted, without listing the project
+	// again, and the pushed document is only forgotten if it's one of them
+	if err := c.config.Set(documentID, "d-2"); err != nil {
+		t.Fatal(err)
+	}
+	confirmed := []ProjectDocument{{ID: "d-1", FileName: "sandworm-a.txt"}, {ID: "d-3", FileName: "notes.md"}}
+	removed, err := c.RemoveDocuments(confirmed, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if strings.Join(removed, ",") != "sandworm-a.txt,notes.md" || strings.Join(deleted, ",") != "d-1,d-3" {
+		t.Errorf("Expected d-1 and d-3 to be deleted, got %v: %v", removed, deleted)
+	}
+	if c.documentID() != "d-2" {
+		t.Errorf("Expected the pushed document to be kept, got %q", c.documentID())
+	}
+	if _, err := c.RemoveDocuments([]ProjectDocument{{ID: "d-2", FileName: "sandworm-b.txt"}}, nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if c.documentID() != "" {
+		t.Errorf("Expected the purged document to be forgotten, got %q", c.documentID())
+	}
+	if ids := c.managedIDs(); strings.Join(ids, ",") != "d-4" {
+		t.Errorf("Expected the purged documents to be forgotten, got %v", ids)
+	}
+}
+
+func TestRemoveDocumentsGone(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if path.Base(r.URL.Path) == "d-2" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	// Documents deleted meanwhile are forgotten, but not reported as removed
+	c := newTestClient(t, server.URL)
+	if err := c.config.Set(managedSection+".p-1", "d-1,d-2"); err != nil {
+		t.Fatal(err)
+	}
+	removed, err := c.RemoveDocuments([]ProjectDocument{{ID: "d-1", FileName: "a.txt"}, {ID: "d-2", FileName: "b.txt"}}, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if strings.Join(removed, ",") != "a.txt" {
+		t.Errorf("Expected only a.txt to be removed, got %v", removed)
+	}
+	if ids := c.managedIDs(); len(ids) != 0 {
+		t.Errorf("Expected both documents to be forgotten, got %v", ids)
+	}
+}
+
+func TestDocumentContent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`[{"uuid":"d-1","file_name":"project.txt","content":"bundle"},{"uuid":"d-2","file_name":"log.jsonl","content":"{}\n"}]`))
+	}))
+	defer server.Close()
+
+	// Documents are found whoever uploaded them
+	c := newTestClient(t, server.URL)
+	if content, err := c.DocumentContent("log.jsonl"); err != nil || content != "{}\n" {
+		t.Errorf("Unexpected content: %q, %v", content, err)
+	}
+	if content, err := c.DocumentContent("missing.txt"); err != nil || content != "" {
+		t.Errorf("Expected no content for a missing document, got %q, %v", content, err)
+	}
+}
+
+func TestDownloadDocuments(t *testing.T) {
+	body := `[{"uuid":"d-1","file_name":"project.txt","content":"bundle"}]`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	c := newTestClient(t, server.URL)
+	docs, err := c.DownloadDocuments()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(docs) != 1 || docs[0].Name != "project.txt" || docs[0].Content != "bundle" {
+		t.Errorf("Unexpected documents: %+v", docs)
+	}
+
+	// Without contents, there's nothing to back up
+	body = `[{"uuid":"d-1","file_name":"project.txt"}]`
+	var schemaErr *SchemaError
+	if _, err := c.DownloadDocuments(); !errors.As(err, &schemaErr) {
+		t.Errorf("Expected a schema error, got %v", err)
+	}
+}
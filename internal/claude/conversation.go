@@ -0,0 +1,296 @@
+package claude
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Conversation is a chat in the project, which has the project's knowledge
+// (including the pushed documents) attached.
+type Conversation struct {
+	ID        string    `json:"uuid"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at,omitempty"`
+	UpdatedAt time.Time `json:"updated_at,omitempty"`
+}
+
+// Message is a message of a conversation.
+type Message struct {
+	Sender    string    // SenderHuman or SenderAssistant
+	Text      string    // Text content, without attachments
+	CreatedAt time.Time // Zero if unknown
+}
+
+// chatMessage is a conversation message, as returned by the API.
+type chatMessage struct {
+	Index     int       `json:"index"`
+	Sender    string    `json:"sender"`
+	Text      string    `json:"text"` // Legacy rendering
+	CreatedAt time.Time `json:"created_at"`
+	Content   []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"` // Messages rendering
+}
+
+// Senders of conversation messages
+const (
+	SenderHuman     = "human"
+	SenderAssistant = "assistant"
+)
+
+// ConversationURL returns the URL of a conversation in the browser.
+func ConversationURL(conversationID string) string {
+	return baseURL + "/chat/" + conversationID
+}
+
+// CreateConversation starts a new conversation in the project.
+func (c *Client) CreateConversation(name string) (*Conversation, error) {
+	if err := c.validateConfig(); err != nil {
+		return nil, err
+	}
+
+	id, err := newUUID()
+	if err != nil {
+		return nil, err
+	}
+	body := map[string]any{
+		"uuid":         id,
+		"name":         name,
+		"project_uuid": c.projectID(),
+	}
+
+	data, err := c.request(http.MethodPost, "conversations", nil, body)
+	if err != nil {
+		return nil, fmt.Errorf("createConversation: %w", err)
+	}
+
+	var conv Conversation
+	if err := decodeResponse(data, "conversation", &conv, "uuid"); err != nil {
+		return nil, err
+	}
+	return &conv, nil
+}
+
+// Ask sends a message to a conversation, calling textFn with each chunk of
+// the reply as it streams in. It returns the whole reply.
+func (c *Client) Ask(conversationID, prompt string, textFn func(string)) (string, error) {
+	body := map[string]any{
+		"prompt":         prompt,
+		"timezone":       timezone(),
+		"attachments":    []any{},
+		"files":          []any{},
+		"rendering_mode": "messages",
+	}
+
+	var reply strings.Builder
+	vars := map[string]string{"conversation": conversationID}
+	err := c.probe("completion", vars, func(path string) error {
+		return c.streamRequest(http.MethodPost, path, body, func(data []byte) error {
+			text, err := completionText(data)
+			if err != nil || text == "" {
+				return err
+			}
+			reply.WriteString(text)
+			if textFn != nil {
+				textFn(text)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return reply.String(), fmt.Errorf("completion: %w", err)
+	}
+	return reply.String(), nil
+}
+
+// ListConversations returns the project's conversations, most recently
+// updated first.
+func (c *Client) ListConversations() ([]Conversation, error) {
+	if err := c.validateConfig(); err != nil {
+		return nil, err
+	}
+
+	data, err := c.request(http.MethodGet, "project_conversations", nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("listConversations: %w", err)
+	}
+
+	var convs []Conversation
+	if err := decodeResponse(data, "conversations", &convs, "uuid"); err != nil {
+		return nil, err
+	}
+	slices.SortStableFunc(convs, func(a, b Conversation) int {
+		return b.UpdatedAt.Compare(a.UpdatedAt)
+	})
+	return convs, nil
+}
+
+// ConversationMessages returns the messages of a conversation, in order.
+func (c *Client) ConversationMessages(conversationID string) ([]Message, error) {
+	if err := c.validateConfig(); err != nil {
+		return nil, err
+	}
+
+	vars := map[string]string{"conversation": conversationID}
+	data, err := c.request(http.MethodGet, "conversation", vars, nil)
+	if err != nil {
+		return nil, fmt.Errorf("getConversation: %w", err)
+	}
+
+	var conv struct {
+		Messages []chatMessage `json:"chat_messages"`
+	}
+	if err := decodeResponse(data, "conversation", &conv, "chat_messages"); err != nil {
+		return nil, err
+	}
+
+	slices.SortStableFunc(conv.Messages, func(a, b chatMessage) int {
+		return a.Index - b.Index
+	})
+	messages := make([]Message, 0, len(conv.Messages))
+	for _, m := range conv.Messages {
+		var text []string
+		for _, block := range m.Content {
+			if block.Type == "text" && block.Text != "" {
+				text = append(text, block.Text)
+			}
+		}
+		if len(text) == 0 && m.Text != "" {
+			text = append(text, m.Text)
+		}
+		messages = append(messages, Message{Sender: m.Sender, Text: strings.Join(text, "\n\n"), CreatedAt: m.CreatedAt})
+	}
+	return messages, nil
+}
+
+// MARK: Helpers
+
+// streamRequest performs a request answered with server-sent events, calling
+// eventFn with the data of each event.
+func (c *Client) streamRequest(method, path string, body any, eventFn func([]byte) error) error {
+	req, err := c.newRequest(method, c.apiURL()+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	reader, err := responseReader(resp)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(reader)
+		return newAPIError(resp, respBody)
+	}
+	if err := c.updateSessionKey(resp); err != nil {
+		return err
+	}
+	return readEvents(reader, eventFn)
+}
+
+// readEvents reads server-sent events, calling eventFn with the (joined)
+// data lines of each.
+func readEvents(r io.Reader, eventFn func([]byte) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+
+	var data []string
+	dispatch := func() error {
+		if len(data) == 0 {
+			return nil
+		}
+		event := strings.Join(data, "\n")
+		data = data[:0]
+		return eventFn([]byte(event))
+	}
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		switch {
+		case line == "":
+			if err := dispatch(); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read event stream: %w", err)
+	}
+	return dispatch()
+}
+
+// completionText returns the text of a completion event. Both the legacy
+// format ({"completion": "..."}) and the messages one (content block deltas)
+// are understood; other events have no text.
+func completionText(data []byte) (string, error) {
+	var event struct {
+		Type       string `json:"type"`
+		Completion string `json:"completion"`
+		Delta      struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"delta"`
+		Error struct {
+			Type    string `json:"type"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(data, &event); err != nil {
+		return "", &SchemaError{Resource: "completion", Problem: "invalid JSON", Payload: data}
+	}
+
+	switch event.Type {
+	case "completion":
+		return event.Completion, nil
+	case "content_block_delta":
+		if event.Delta.Type == "text_delta" {
+			return event.Delta.Text, nil
+		}
+	case "error":
+		if event.Error.Message == "" {
+			return "", errors.New("completion failed")
+		}
+		return "", fmt.Errorf("completion failed: %s", event.Error.Message)
+	}
+	return "", nil
+}
+
+// timezone returns the IANA name of the local time zone, as claude.ai
+// expects it, falling back to UTC.
+func timezone() string {
+	if tz := os.Getenv("TZ"); tz != "" {
+		return tz
+	}
+	if name := time.Local.String(); name != "Local" {
+		return name
+	}
+	return "UTC"
+}
+
+// newUUID returns a random (version 4) UUID.
+func newUUID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("failed to generate UUID: %w", err)
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
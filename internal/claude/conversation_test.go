@@ -0,0 +1,120 @@
+package claude
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAskConversation(t *testing.T) {
+	var created map[string]any
+	var prompt string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/organizations/o-1/chat_conversations":
+			_ = json.NewDecoder(r.Body).Decode(&created)
+			_, _ = w.Write([]byte(`{"uuid":"c-1","name":"Question"}`))
+		case "/organizations/o-1/chat_conversations/c-1/completion":
+			var body struct {
+				Prompt string `json:"prompt"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			prompt = body.Prompt
+			w.Header().Set("Content-Type", "text/event-stream")
+			_, _ = w.Write([]byte(strings.Join([]string{
+				"event: message_start",
+				`data: {"type":"message_start"}`,
+				"",
+				"event: content_block_delta",
+				`data: {"type":"content_block_delta","delta":{"type":"text_delta","text":"Hello"}}`,
+				"",
+				`data: {"type":"completion","completion":", world"}`,
+				"",
+				"event: message_stop",
+				`data: {"type":"message_stop"}`,
+				"",
+			}, "\n")))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	c := newTestClient(t, server.URL)
+	conv, err := c.CreateConversation("Question")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if conv.ID != "c-1" || created["project_uuid"] != "p-1" {
+		t.Errorf("Unexpected conversation %+v (created with %v)", conv, created)
+	}
+
+	var chunks []string
+	reply, err := c.Ask(conv.ID, "Hi?", func(text string) { chunks = append(chunks, text) })
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if prompt != "Hi?" {
+		t.Errorf("Expected prompt %q, got %q", "Hi?", prompt)
+	}
+	if reply != "Hello, world" || len(chunks) != 2 {
+		t.Errorf("Unexpected reply %q (chunks %q)", reply, chunks)
+	}
+}
+
+func TestAskStreamError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`data: {"type":"completion","completion":"Partial"}` + "\n\n" +
+			`data: {"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}` + "\n\n"))
+	}))
+	defer server.Close()
+
+	c := newTestClient(t, server.URL)
+	reply, err := c.Ask("c-1", "Hi?", nil)
+	if err == nil || !strings.Contains(err.Error(), "Overloaded") {
+		t.Errorf("Expected an overloaded error, got %v", err)
+	}
+	if reply != "Partial" {
+		t.Errorf("Expected the partial reply, got %q", reply)
+	}
+}
+
+func TestConversationTranscripts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/organizations/o-1/projects/p-1/conversations":
+			_, _ = w.Write([]byte(`[
+				{"uuid":"c-1","name":"Old","updated_at":"2025-07-01T10:00:00Z"},
+				{"uuid":"c-2","name":"New","updated_at":"2025-07-02T10:00:00Z"}
+			]`))
+		case "/organizations/o-1/chat_conversations/c-1":
+			_, _ = w.Write([]byte(`{"uuid":"c-1","chat_messages":[
+				{"index":1,"sender":"assistant","content":[{"type":"text","text":"Hello"},{"type":"tool_use"},{"type":"text","text":"there"}]},
+				{"index":0,"sender":"human","text":"Hi"}
+			]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	c := newTestClient(t, server.URL)
+	convs, err := c.ListConversations()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(convs) != 2 || convs[0].ID != "c-2" || convs[1].ID != "c-1" {
+		t.Errorf("Expected conversations most recent first, got %+v", convs)
+	}
+
+	messages, err := c.ConversationMessages("c-1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(messages) != 2 || messages[0].Sender != SenderHuman || messages[0].Text != "Hi" ||
+		messages[1].Sender != SenderAssistant || messages[1].Text != "Hello\n\nthere" {
+		t.Errorf("Unexpected messages: %+v", messages)
+	}
+}
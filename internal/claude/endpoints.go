@@ -0,0 +1,171 @@
+package claude
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// Configuration keys overriding the API location, for when claude.ai moves
+// routes before sandworm is updated.
+const (
+	apiURLKey    = "claude.api_url"   // Base URL of the API
+	endpointsKey = "claude.endpoints" // name=path overrides of endpoint paths
+)
+
+// defaultAPIURL is the base URL of the API.
+const defaultAPIURL = baseURL + "/api"
+
+// endpoints lists the known path variants of each API endpoint, current one
+// first. Paths hold {org}, {project}, {doc} and {conversation} placeholders.
+var endpoints = map[string][]string{
+	"account":       {"/account"},
+	"organizations": {"/organizations"},
+	"projects":      {"/organizations/{org}/projects"},
+	"project":       {"/organizations/{org}/projects/{project}"},
+	"docs": {
+		"/organizations/{org}/projects/{project}/docs",
+		"/organizations/{org}/projects/{project}/files",
+	},
+	"doc": {
+		"/organizations/{org}/projects/{project}/docs/{doc}",
+		"/organizations/{org}/projects/{project}/files/{doc}",
+	},
+	"conversations":         {"/organizations/{org}/chat_conversations"},
+	"conversation":          {"/organizations/{org}/chat_conversations/{conversation}?tree=True&rendering_mode=messages"},
+	"project_conversations": {"/organizations/{org}/projects/{project}/conversations"},
+	"usage":                 {"/organizations/{org}/usage"},
+	"completion":            {"/organizations/{org}/chat_conversations/{conversation}/completion"},
+}
+
+// parseEndpoints parses comma-separated name=path endpoint overrides, e.g.
+// "docs=/organizations/{org}/projects/{project}/files".
+func parseEndpoints(value string) (map[string]string, error) {
+	overrides := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		name, path, ok := strings.Cut(pair, "=")
+		name, path = strings.TrimSpace(name), strings.TrimSpace(path)
+		if !ok || !strings.HasPrefix(path, "/") {
+			return nil, fmt.Errorf("invalid endpoint %q (expected name=/path)", pair)
+		}
+		if _, ok := endpoints[name]; !ok {
+			return nil, fmt.Errorf("unknown endpoint %q (expected one of: %s)", name, strings.Join(endpointNames(), ", "))
+		}
+		overrides[name] = path
+	}
+	return overrides, nil
+}
+
+// ValidateEndpoints checks endpoint overrides (see parseEndpoints).
+func ValidateEndpoints(value string) error {
+	_, err := parseEndpoints(value)
+	return err
+}
+
+// ValidateAPIURL checks an API base URL.
+func ValidateAPIURL(value string) error {
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid API URL %q (expected e.g. %s)", value, defaultAPIURL)
+	}
+	return nil
+}
+
+// MARK: Helpers
+
+// request performs a request to a named endpoint. Unless its path is
+// overridden in config, the endpoint's variants are probed in turn while the
+// API answers 404/405, and the one that works is remembered in the metadata
+// cache for the next runs (until --refresh). When all fail, the error of the
+// first variant is returned, so a missing resource is still reported as such.
+func (c *Client) request(method, endpoint string, vars map[string]string, body any) ([]byte, error) {
+	var data []byte
+	err := c.probe(endpoint, vars, func(path string) error {
+		var err error
+		data, err = c.makeRequest(method, path, body)
+		return err
+	})
+	return data, err
+}
+
+// probe calls do with the paths of an endpoint's variants (see request),
+// placeholders replaced, until one doesn't fail with a 404/405.
+func (c *Client) probe(endpoint string, vars map[string]string, do func(path string) error) error {
+	replacements := []string{"{org}", c.orgID(), "{project}", c.projectID()}
+	for k, v := range vars {
+		replacements = append(replacements, "{"+k+"}", v)
+	}
+	replacer := strings.NewReplacer(replacements...)
+
+	var firstErr error
+	variants := c.endpointVariants(endpoint)
+	for i, path := range variants {
+		err := do(replacer.Replace(path))
+		if err == nil {
+			if i > 0 {
+				c.rememberEndpoint(endpoint, path)
+			}
+			return nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+		if !IsStatus(err, http.StatusNotFound) && !IsStatus(err, http.StatusMethodNotAllowed) {
+			return err
+		}
+	}
+	return firstErr
+}
+
+// endpointVariants returns the paths to try for an endpoint: the configured
+// override alone, or the known variants starting with the last one that
+// worked.
+func (c *Client) endpointVariants(endpoint string) []string {
+	if overrides, err := parseEndpoints(c.config.Get(endpointsKey)); err == nil {
+		if path, ok := overrides[endpoint]; ok {
+			return []string{path}
+		}
+	}
+
+	variants := endpoints[endpoint]
+	if known := c.loadCache().Endpoints[endpoint]; known != "" && slices.Contains(variants, known) {
+		variants = append([]string{known}, slices.DeleteFunc(slices.Clone(variants), func(v string) bool {
+			return v == known
+		})...)
+	}
+	return variants
+}
+
+// rememberEndpoint records the path variant that worked for an endpoint.
+func (c *Client) rememberEndpoint(endpoint, path string) {
+	cache := c.loadCache()
+	if cache.Endpoints == nil {
+		cache.Endpoints = make(map[string]string)
+	}
+	cache.Endpoints[endpoint] = path
+	// Best effort: the request succeeded, and probing again is harmless.
+	_ = c.saveCache()
+}
+
+// apiURL returns the base URL of the API.
+func (c *Client) apiURL() string {
+	if u := c.config.Get(apiURLKey); u != "" {
+		return strings.TrimRight(u, "/")
+	}
+	return defaultAPIURL
+}
+
+// endpointNames returns the sorted endpoint names.
+func endpointNames() []string {
+	names := make([]string, 0, len(endpoints))
+	for name := range endpoints {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
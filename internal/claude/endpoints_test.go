@@ -0,0 +1,122 @@
+package claude
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/holonoms/sandworm/internal/config"
+)
+
+func TestRequestProbesEndpointVariants(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if strings.HasSuffix(r.URL.Path, "/docs") {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`[{"uuid":"d-1","file_name":"a.txt"}]`))
+	}))
+	defer server.Close()
+
+	c := newTestClient(t, server.URL)
+	for range 2 {
+		docs, err := c.listDocuments()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(docs) != 1 || docs[0].ID != "d-1" {
+			t.Fatalf("Unexpected documents: %+v", docs)
+		}
+	}
+
+	expected := []string{
+		"/organizations/o-1/projects/p-1/docs",
+		"/organizations/o-1/projects/p-1/files",
+		"/organizations/o-1/projects/p-1/files", // Remembered
+	}
+	if strings.Join(paths, " ") != strings.Join(expected, " ") {
+		t.Errorf("Expected requests to %v, got %v", expected, paths)
+	}
+}
+
+func TestRequestReturnsFirstError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "/docs/") {
+			http.Error(w, "no such document", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "unknown route", http.StatusMethodNotAllowed)
+	}))
+	defer server.Close()
+
+	c := newTestClient(t, server.URL)
+	err := c.deleteDocument("d-1")
+	if !IsStatus(err, http.StatusNotFound) {
+		t.Errorf("Expected the 404 of the first variant, got %v", err)
+	}
+}
+
+func TestRequestEndpointOverride(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	c := newTestClient(t, server.URL)
+	if err := c.config.Set(endpointsKey, "docs=/v2/projects/{project}/documents"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.listDocuments(); !IsStatus(err, http.StatusNotFound) {
+		t.Errorf("Expected a 404, got %v", err)
+	}
+	if len(paths) != 1 || paths[0] != "/v2/projects/p-1/documents" {
+		t.Errorf("Expected only the overridden path, got %v", paths)
+	}
+}
+
+func TestValidateEndpoints(t *testing.T) {
+	tests := []struct {
+		value   string
+		wantErr bool
+	}{
+		{value: ""},
+		{value: "docs=/a/{project}/files, doc=/a/{project}/files/{doc}"},
+		{value: "docs", wantErr: true},
+		{value: "docs=files", wantErr: true},
+		{value: "documents=/files", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		if err := ValidateEndpoints(tt.value); (err != nil) != tt.wantErr {
+			t.Errorf("ValidateEndpoints(%q) = %v, wantErr %v", tt.value, err, tt.wantErr)
+		}
+	}
+}
+
+// newTestClient returns a client of a test server, with an isolated config.
+func newTestClient(t *testing.T, apiURL string) *Client {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	conf, err := config.New(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, value := range map[string]string{
+		apiURLKey:      apiURL,
+		sessionKey:     "sk-test",
+		organizationID: "o-1",
+		projectID:      "p-1",
+	} {
+		if err := conf.Set(key, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return New(conf)
+}
@@ -0,0 +1,121 @@
+package claude
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// responseCacheDir is the directory of cached API responses, in the global
+// config directory.
+const responseCacheDir = "http-cache"
+
+// Bounds of the response cache: responses are dropped once older than
+// responseMaxAge, and the oldest ones first once the cache exceeds
+// responseCacheSize.
+const (
+	responseMaxAge    = 7 * 24 * time.Hour
+	responseCacheSize = 32 * 1024 * 1024
+)
+
+// cachedResponse is a listing response kept for conditional requests: it's
+// revalidated with If-None-Match/If-Modified-Since, and reused when the API
+// answers 304 Not Modified.
+type cachedResponse struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+	Body         []byte `json:"body"`
+}
+
+// SetNoCache disables the response cache: requests are never conditional,
+// and responses aren't stored.
+func (c *Client) SetNoCache(noCache bool) {
+	c.noCache = noCache
+}
+
+// responsePath returns the cache file of a URL, per session key since
+// responses depend on the account.
+func (c *Client) responsePath(url string) string {
+	sum := sha256.Sum256([]byte(c.sessionKeyName() + " " + url))
+	return filepath.Join(c.config.Dir(), responseCacheDir, hex.EncodeToString(sum[:])+".json")
+}
+
+// cachedResponse returns the cached response of a GET request, or nil.
+func (c *Client) cachedResponse(url string) *cachedResponse {
+	if c.noCache {
+		return nil
+	}
+	path := c.responsePath(url)
+	if info, err := os.Stat(path); err != nil || time.Since(info.ModTime()) > responseMaxAge {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var cached cachedResponse
+	if err := json.Unmarshal(data, &cached); err != nil || (cached.ETag == "" && cached.LastModified == "") {
+		return nil
+	}
+	return &cached
+}
+
+// storeResponse caches the response of a GET request, if it can be
+// revalidated. Caching is best effort: failures are ignored.
+func (c *Client) storeResponse(url string, header http.Header, body []byte) {
+	cached := cachedResponse{
+		ETag:         header.Get("ETag"),
+		LastModified: header.Get("Last-Modified"),
+		Body:         body,
+	}
+	if c.noCache || (cached.ETag == "" && cached.LastModified == "") {
+		return
+	}
+	data, err := json.Marshal(cached)
+	if err != nil {
+		return
+	}
+	path := c.responsePath(url)
+	// Responses hold project contents: keep them private
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return
+	}
+	if os.WriteFile(path, data, 0o600) == nil {
+		pruneResponses(filepath.Dir(path), time.Now())
+	}
+}
+
+// pruneResponses removes the expired responses cached in dir, then the oldest
+// ones until the cache fits in responseCacheSize.
+func pruneResponses(dir string, now time.Time) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	var infos []os.FileInfo
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		if now.Sub(info.ModTime()) > responseMaxAge {
+			_ = os.Remove(filepath.Join(dir, info.Name()))
+			continue
+		}
+		infos = append(infos, info)
+	}
+
+	// Newest first, keeping them while they fit
+	sort.Slice(infos, func(i, j int) bool { return infos[i].ModTime().After(infos[j].ModTime()) })
+	var size int64
+	for _, info := range infos {
+		if size += info.Size(); size > responseCacheSize {
+			_ = os.Remove(filepath.Join(dir, info.Name()))
+		}
+	}
+}
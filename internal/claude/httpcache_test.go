@@ -0,0 +1,82 @@
+package claude
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConditionalRequests(t *testing.T) {
+	var conditions []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conditions = append(conditions, r.Header.Get("If-None-Match"))
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte(`[{"uuid":"d-1","file_name":"a.txt"}]`))
+	}))
+	defer server.Close()
+
+	c := newTestClient(t, server.URL)
+	for range 2 {
+		docs, err := c.listDocuments()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(docs) != 1 || docs[0].ID != "d-1" {
+			t.Fatalf("Unexpected documents: %+v", docs)
+		}
+	}
+	if len(conditions) != 2 || conditions[0] != "" || conditions[1] != `"v1"` {
+		t.Errorf("Expected the second request to be conditional, got %q", conditions)
+	}
+
+	// --no-cache never sends conditional requests
+	conditions = nil
+	c.SetNoCache(true)
+	if _, err := c.listDocuments(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(conditions) != 1 || conditions[0] != "" {
+		t.Errorf("Expected an unconditional request, got %q", conditions)
+	}
+}
+
+func TestPruneResponses(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	write := func(name string, size int, age time.Duration) {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(strings.Repeat("x", size)), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, now.Add(-age), now.Add(-age)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("expired.json", 10, responseMaxAge+time.Hour)
+	write("old.json", responseCacheSize/2, 2*time.Hour)
+	write("recent.json", responseCacheSize/2, time.Hour)
+	write("new.json", 10, 0)
+
+	pruneResponses(dir, now)
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	if strings.Join(names, " ") != "new.json recent.json" {
+		t.Errorf("Expected the expired and oldest responses to be removed, got %v", names)
+	}
+}
@@ -0,0 +1,69 @@
+package claude
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// SchemaError is returned when a Claude API response doesn't have the
+// expected shape, which usually means that the (unofficial) API changed.
+type SchemaError struct {
+	Resource string // What was requested, e.g. "projects"
+	Problem  string // What didn't match, e.g. `missing field "uuid"`
+	Payload  []byte // The raw response
+}
+
+func (e *SchemaError) Error() string {
+	return fmt.Sprintf("unexpected %s response (%s): the Claude API may have changed, please update sandworm", e.Resource, e.Problem)
+}
+
+// decodeResponse parses a response into v, checking that the required fields
+// are set in the object (or in each object of the array) it holds. Unknown
+// fields are fine: they're usually additions that don't affect sandworm.
+func decodeResponse(data []byte, resource string, v any, required ...string) error {
+	var raw any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return &SchemaError{Resource: resource, Problem: "invalid JSON", Payload: data}
+	}
+
+	items, ok := raw.([]any)
+	if !ok {
+		items = []any{raw}
+	}
+	for _, item := range items {
+		object, ok := item.(map[string]any)
+		if !ok {
+			return &SchemaError{Resource: resource, Problem: fmt.Sprintf("expected an object, got %s", jsonType(item)), Payload: data}
+		}
+		for _, field := range required {
+			if value, ok := object[field]; !ok || value == nil {
+				return &SchemaError{Resource: resource, Problem: fmt.Sprintf("missing field %q", field), Payload: data}
+			}
+		}
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return &SchemaError{Resource: resource, Problem: err.Error(), Payload: data}
+	}
+	return nil
+}
+
+// MARK: Helpers
+
+// jsonType names the JSON type of a decoded value.
+func jsonType(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "a boolean"
+	case float64:
+		return "a number"
+	case string:
+		return "a string"
+	case []any:
+		return "an array"
+	default:
+		return "an object"
+	}
+}
@@ -0,0 +1,51 @@
+package claude
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDecodeResponse(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		problem string
+	}{
+		{name: "valid array", payload: `[{"uuid":"d-1","file_name":"a.txt","extra":true}]`},
+		{name: "valid empty array", payload: `[]`},
+		{name: "renamed key", payload: `[{"id":"d-1","file_name":"a.txt"}]`, problem: `missing field "uuid"`},
+		{name: "null field", payload: `[{"uuid":null,"file_name":"a.txt"}]`, problem: `missing field "uuid"`},
+		{name: "wrapped array", payload: `{"documents":[]}`, problem: `missing field "uuid"`},
+		{name: "not an object", payload: `["d-1"]`, problem: "expected an object, got a string"},
+		{name: "wrong type", payload: `[{"uuid":1,"file_name":"a.txt"}]`, problem: "cannot unmarshal number"},
+		{name: "invalid JSON", payload: `<html>`, problem: "invalid JSON"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var docs []document
+			err := decodeResponse([]byte(tt.payload), "documents", &docs, "uuid", "file_name")
+			if tt.problem == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			var schemaErr *SchemaError
+			if !errors.As(err, &schemaErr) {
+				t.Fatalf("expected a SchemaError, got %v", err)
+			}
+			if !strings.Contains(schemaErr.Problem, tt.problem) {
+				t.Errorf("expected problem %q, got %q", tt.problem, schemaErr.Problem)
+			}
+			if string(schemaErr.Payload) != tt.payload {
+				t.Errorf("expected the raw payload, got %q", schemaErr.Payload)
+			}
+			if !strings.Contains(err.Error(), "please update sandworm") {
+				t.Errorf("unexpected message: %v", err)
+			}
+		})
+	}
+}
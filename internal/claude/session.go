@@ -0,0 +1,180 @@
+package claude
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/holonoms/sandworm/internal/config"
+)
+
+// SessionLifetime is roughly how long claude.ai session keys last. Their
+// expiry isn't exposed, so it's estimated from when they were entered.
+const SessionLifetime = 30 * 24 * time.Hour
+
+// sessionsSection records when each session key was entered, keyed by the
+// config key of the session key (e.g. sessions.accounts.work).
+const sessionsSection = "sessions"
+
+// sessionKeyRE matches session keys as claude.ai issues them, e.g.
+// sk-ant-sid01-<base64url>.
+var sessionKeyRE = regexp.MustCompile(`^sk-ant-[A-Za-z0-9_-]{20,}$`)
+
+// ErrInvalidSessionKey is returned for input that isn't a session key.
+var ErrInvalidSessionKey = errors.New("invalid session key")
+
+// Session describes the account a session key signs in to.
+type Session struct {
+	Email string
+	Name  string
+	Added time.Time // When the key was entered; zero if unknown
+}
+
+// Expires returns approximately when the session expires (see
+// SessionLifetime), or the zero time if unknown.
+func (s *Session) Expires() time.Time {
+	if s.Added.IsZero() {
+		return time.Time{}
+	}
+	return s.Added.Add(SessionLifetime)
+}
+
+// String describes a session, e.g. "alice@example.com (Alice), expires
+// around Feb 1".
+func (s *Session) String() string {
+	description := s.Email
+	if s.Name != "" && s.Name != s.Email {
+		description += " (" + s.Name + ")"
+	}
+	if expires := s.Expires(); !expires.IsZero() {
+		description += ", expires around " + expires.Local().Format("Jan 2")
+	}
+	return description
+}
+
+// ParseSessionKey extracts a session key from pasted input: the key itself,
+// or the sessionKey cookie as copied from a Cookie header (e.g.
+// "sessionKey=sk-ant-...;"). It returns ErrInvalidSessionKey for anything
+// else, such as a truncated key.
+func ParseSessionKey(input string) (string, error) {
+	key := strings.TrimSpace(input)
+	for _, cookie := range strings.Split(key, ";") {
+		if value, ok := strings.CutPrefix(strings.TrimSpace(cookie), "sessionKey="); ok {
+			key = value
+			break
+		}
+	}
+	key = strings.Trim(strings.TrimSpace(key), `"'`)
+
+	switch {
+	case key == "":
+		return "", fmt.Errorf("%w: empty", ErrInvalidSessionKey)
+	case !strings.HasPrefix(key, "sk-ant-"):
+		return "", fmt.Errorf("%w: expected it to start with sk-ant-", ErrInvalidSessionKey)
+	case !sessionKeyRE.MatchString(key):
+		return "", fmt.Errorf("%w: it looks truncated or contains unexpected characters", ErrInvalidSessionKey)
+	}
+	return key, nil
+}
+
+// CheckSessionKey confirms that a session key works, with a lightweight
+// request for the account it signs in to, before it's saved.
+func (c *Client) CheckSessionKey(key string) (*Session, error) {
+	c.sessionKeyOverride = key
+	defer func() { c.sessionKeyOverride = "" }()
+	session, err := c.account()
+	if err != nil {
+		return nil, err
+	}
+	session.Added = time.Now()
+	return session, nil
+}
+
+// Session returns the account the configured session key signs in to, and
+// when the key was entered.
+func (c *Client) Session() (*Session, error) {
+	if !c.config.Has(c.sessionKeyName()) {
+		return nil, fmt.Errorf("%w: %s", ErrMissingConfig, c.sessionKeyName())
+	}
+	session, err := c.account()
+	if err != nil {
+		return nil, err
+	}
+	session.Added, _ = time.Parse(time.RFC3339, c.config.Get(SessionAddedKey(c.sessionKeyName())))
+	return session, nil
+}
+
+// SetSessionKey stores the session key of an account ("" for the single,
+// unlabeled key), recording when it was entered.
+func SetSessionKey(cfg *config.Config, account, key string) error {
+	name := sessionKey
+	if account != "" {
+		name = AccountKey(account)
+	}
+	if err := cfg.Set(name, key); err != nil {
+		return err
+	}
+	return cfg.Set(SessionAddedKey(name), time.Now().UTC().Format(time.RFC3339))
+}
+
+// SessionAddedKey returns the config key recording when the session key
+// stored under a config key was entered.
+func SessionAddedKey(name string) string {
+	return sessionsSection + "." + name
+}
+
+// MARK: Helpers
+
+// promptSessionKey asks for a session key until one is valid and works, and
+// stores it for the account in use.
+func (c *Client) promptSessionKey() error {
+	fmt.Println("\nPlease go to https://claude.ai in your browser and copy your session key from the Cookie header.")
+	fmt.Println("You can find this in your browser's developer tools under Network tab.")
+	for {
+		fmt.Println()
+		fmt.Print("Enter your session key: ")
+		input, err := readLine()
+		if err != nil {
+			return fmt.Errorf("failed to read session key: %w", err)
+		}
+		key, err := ParseSessionKey(input)
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		session, err := c.CheckSessionKey(key)
+		if err != nil {
+			var apiErr *APIError
+			if errors.As(err, &apiErr) && apiErr.IsAuthError() {
+				fmt.Println("The session key was rejected; copy it again (it may have expired).")
+				continue
+			}
+			return fmt.Errorf("failed to check session key: %w", err)
+		}
+		if err := SetSessionKey(c.config, c.Account(), key); err != nil {
+			return err
+		}
+		fmt.Printf("Signed in as %s\n", session)
+		return nil
+	}
+}
+
+// account requests the account the session key signs in to.
+func (c *Client) account() (*Session, error) {
+	data, err := c.request(http.MethodGet, "account", nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("getAccount: %w", err)
+	}
+	var account struct {
+		Email string `json:"email_address"`
+		Name  string `json:"full_name"`
+	}
+	if err := json.Unmarshal(data, &account); err != nil || account.Email == "" {
+		return nil, &SchemaError{Resource: "account", Problem: "expected an object with email_address", Payload: data}
+	}
+	return &Session{Email: account.Email, Name: account.Name}, nil
+}
@@ -0,0 +1,77 @@
+package claude
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseSessionKey(t *testing.T) {
+	const key = "sk-ant-REDACTED"
+
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+	}{
+		{name: "key", input: key},
+		{name: "surrounding whitespace and quotes", input: "  \"" + key + "\"\n"},
+		{name: "cookie", input: "sessionKey=" + key + ";"},
+		{name: "cookie header", input: "intercom-device-id=1; sessionKey=" + key + "; lastActiveOrg=o-1"},
+		{name: "empty", input: "  ", wantErr: true},
+		{name: "other cookie", input: "lastActiveOrg=o-1", wantErr: true},
+		{name: "truncated", input: "sk-ant-sid01-AbC", wantErr: true},
+		{name: "unexpected characters", input: key + "…", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSessionKey(tt.input)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidSessionKey) {
+					t.Errorf("Expected ErrInvalidSessionKey, got %q, %v", got, err)
+				}
+				return
+			}
+			if err != nil || got != key {
+				t.Errorf("Expected the key, got %q, %v", got, err)
+			}
+		})
+	}
+}
+
+func TestCheckSessionKey(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if cookie, err := r.Cookie("sessionKey"); err != nil || cookie.Value != "sk-new" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte(`{"uuid":"u-1","email_address":"alice@example.com","full_name":"Alice"}`))
+	}))
+	defer server.Close()
+	c := newTestClient(t, server.URL)
+
+	// The configured key (sk-test) isn't the one checked
+	session, err := c.CheckSessionKey("sk-new")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if session.Email != "alice@example.com" || session.Expires().Before(time.Now().Add(SessionLifetime-time.Minute)) {
+		t.Errorf("Unexpected session: %+v", session)
+	}
+	if _, err := c.Session(); err == nil {
+		t.Error("Expected the configured key to be rejected")
+	}
+
+	if err := SetSessionKey(c.config, "", "sk-new"); err != nil {
+		t.Fatal(err)
+	}
+	session, err = c.Session()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if session.Added.IsZero() || session.String() != "alice@example.com (Alice), expires around "+session.Expires().Local().Format("Jan 2") {
+		t.Errorf("Unexpected session: %s", session)
+	}
+}
@@ -0,0 +1,128 @@
+package claude
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// statusURL is the status page summary of the Claude service (a Statuspage
+// API, replaceable in tests).
+var statusURL = "https://status.claude.com/api/v2/status.json"
+
+// UsageLimit is the state of one of the account's usage limits (e.g. the
+// five-hour or weekly message allowance).
+type UsageLimit struct {
+	Name        string    // As named by the API, e.g. "five_hour"
+	Utilization float64   // Percentage of the limit used
+	ResetsAt    time.Time // Zero if unknown
+}
+
+// ServiceStatus is the overall state of the Claude service.
+type ServiceStatus struct {
+	Indicator   string // none, minor, major or critical
+	Description string // e.g. "All Systems Operational"
+}
+
+// Usage returns the usage limits of the organization, as far as the API
+// exposes them.
+func (c *Client) Usage() ([]UsageLimit, error) {
+	if err := c.validateConfig(); err != nil {
+		return nil, err
+	}
+
+	data, err := c.request(http.MethodGet, "usage", nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("getUsage: %w", err)
+	}
+
+	// Limits are keyed by name, e.g. {"five_hour": {"utilization": 12.0,
+	// "resets_at": "..."}, "seven_day_opus": null}; unknown shapes are skipped.
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, &SchemaError{Resource: "usage", Problem: "expected an object", Payload: data}
+	}
+	var limits []UsageLimit
+	for name, value := range raw {
+		var limit struct {
+			Utilization *float64  `json:"utilization"`
+			ResetsAt    time.Time `json:"resets_at"`
+		}
+		if json.Unmarshal(value, &limit) != nil || limit.Utilization == nil {
+			continue
+		}
+		limits = append(limits, UsageLimit{Name: name, Utilization: *limit.Utilization, ResetsAt: limit.ResetsAt})
+	}
+	slices.SortFunc(limits, func(a, b UsageLimit) int { return strings.Compare(a.Name, b.Name) })
+	return limits, nil
+}
+
+// ServiceStatus returns the state of the Claude service from its status page.
+func (c *Client) ServiceStatus() (*ServiceStatus, error) {
+	resp, err := c.httpClient.Get(statusURL)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, &APIError{StatusCode: resp.StatusCode, Body: string(data)}
+	}
+
+	var summary struct {
+		Status ServiceStatus `json:"status"`
+	}
+	if err := decodeResponse(data, "status", &summary, "status"); err != nil {
+		return nil, err
+	}
+	return &summary.Status, nil
+}
+
+// MARK: Helpers
+
+// newAPIError creates the error of a failed response, with the time a rate
+// limit resets when the API tells.
+func newAPIError(resp *http.Response, body []byte) *APIError {
+	err := &APIError{StatusCode: resp.StatusCode, Body: string(body)}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		err.ResetsAt = rateLimitReset(resp.Header.Get("Retry-After"), body, time.Now())
+	}
+	return err
+}
+
+// rateLimitReset returns when a rate limit resets, from a Retry-After header
+// (seconds or HTTP date) or the "resetsAt" (Unix time) claude.ai embeds in
+// its error message. It returns the zero time if unknown.
+func rateLimitReset(retryAfter string, body []byte, now time.Time) time.Time {
+	if seconds, err := strconv.Atoi(strings.TrimSpace(retryAfter)); err == nil {
+		return now.Add(time.Duration(seconds) * time.Second)
+	}
+	if t, err := http.ParseTime(retryAfter); err == nil {
+		return t
+	}
+
+	var payload struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(body, &payload) != nil {
+		return time.Time{}
+	}
+	var message struct {
+		ResetsAt int64 `json:"resetsAt"`
+	}
+	if json.Unmarshal([]byte(payload.Error.Message), &message) != nil || message.ResetsAt == 0 {
+		return time.Time{}
+	}
+	return time.Unix(message.ResetsAt, 0)
+}
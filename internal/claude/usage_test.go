@@ -0,0 +1,76 @@
+package claude
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestUsage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/organizations/o-1/usage":
+			_, _ = w.Write([]byte(`{
+				"seven_day": {"utilization": 35.5, "resets_at": "2025-07-26T10:00:00Z"},
+				"five_hour": {"utilization": 100, "resets_at": null},
+				"seven_day_opus": null,
+				"tier": "pro"
+			}`))
+		case "/status":
+			_, _ = w.Write([]byte(`{"status":{"indicator":"minor","description":"Partially Degraded Service"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	c := newTestClient(t, server.URL)
+	limits, err := c.Usage()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	resets := time.Date(2025, 7, 26, 10, 0, 0, 0, time.UTC)
+	if len(limits) != 2 ||
+		limits[0].Name != "five_hour" || limits[0].Utilization != 100 || !limits[0].ResetsAt.IsZero() ||
+		limits[1].Name != "seven_day" || limits[1].Utilization != 35.5 || !limits[1].ResetsAt.Equal(resets) {
+		t.Errorf("Unexpected limits: %+v", limits)
+	}
+
+	defer func(url string) { statusURL = url }(statusURL)
+	statusURL = server.URL + "/status"
+	status, err := c.ServiceStatus()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if status.Indicator != "minor" || status.Description != "Partially Degraded Service" {
+		t.Errorf("Unexpected status: %+v", status)
+	}
+}
+
+func TestRateLimitReset(t *testing.T) {
+	now := time.Date(2025, 7, 19, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		retryAfter string
+		body       string
+		expected   time.Time
+	}{
+		{name: "retry after seconds", retryAfter: "120", expected: now.Add(2 * time.Minute)},
+		{name: "retry after date", retryAfter: "Sat, 19 Jul 2025 13:00:00 GMT", expected: now.Add(time.Hour)},
+		{
+			name:     "error message",
+			body:     `{"type":"error","error":{"type":"rate_limit_error","message":"{\"type\":\"exceeded_limit\",\"resetsAt\":1752930000}"}}`,
+			expected: time.Unix(1752930000, 0),
+		},
+		{name: "unknown", body: `{"error":{"message":"Slow down"}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rateLimitReset(tt.retryAfter, []byte(tt.body), now); !got.Equal(tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/holonoms/sandworm/internal/backup"
+	"github.com/holonoms/sandworm/internal/claude"
+	"github.com/holonoms/sandworm/internal/config"
+	"github.com/holonoms/sandworm/internal/style"
+)
+
+// backupKeep is the number of backups kept per project; older ones are
+// removed.
+const backupKeep = 20
+
+// backupRemote saves the project's current documents locally before a push
+// replaces or deletes them, when claude.backup (or --backup) is on. A failed
+// backup stops the push.
+func backupRemote(client *claude.Client, opts *Options, enabled *bool) error {
+	cfg, err := config.New(opts.Directory)
+	if err != nil {
+		return fmt.Errorf("unable to load config: %w", err)
+	}
+	if !resolveBool(enabled, cfg, "claude.backup", false) {
+		return nil
+	}
+
+	docs, err := client.DownloadDocuments()
+	if err != nil {
+		return fmt.Errorf("unable to back up documents: %w", err)
+	}
+	if len(docs) == 0 {
+		return nil
+	}
+	_, projectID := client.TargetIDs()
+	dir := backup.Dir(cfg.Dir(), projectID)
+	path, err := backup.Save(dir, docs, time.Now())
+	if err != nil {
+		return fmt.Errorf("unable to back up documents: %w", err)
+	}
+	if err := backup.Prune(dir, backupKeep); err != nil {
+		return fmt.Errorf("unable to remove old backups: %w", err)
+	}
+	fmt.Println(style.Dim(fmt.Sprintf("Backed up %d document(s) to %s", len(docs), path)))
+	return nil
+}
@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/holonoms/sandworm/internal/config"
+	"github.com/holonoms/sandworm/internal/stats"
+	"github.com/holonoms/sandworm/internal/style"
+	"github.com/holonoms/sandworm/internal/tokens"
+	"github.com/holonoms/sandworm/internal/util"
+)
+
+// budget holds the bundle size thresholds (see the budget.* config options).
+type budget struct {
+	sizeLimit     int64 // 0 for none
+	tokenLimit    int   // 0 for none
+	warnPercent   int
+	growthPercent int // 0 to disable
+}
+
+// checkBudget warns when a bundle about to be pushed nears or exceeds the
+// size or token budget, or grew too much since the last push. With --strict,
+// any warning fails the push.
+func checkBudget(opts *Options, gen generation, strict bool) error {
+	cfg, err := config.New(opts.Directory)
+	if err != nil {
+		return fmt.Errorf("unable to load config: %w", err)
+	}
+	b := budget{
+		tokenLimit:    resolveInt(nil, cfg, "budget.token_limit", 0),
+		warnPercent:   resolveInt(nil, cfg, "budget.warn_percent", 80),
+		growthPercent: resolveInt(nil, cfg, "budget.growth_percent", 20),
+	}
+	if limit := cfg.Get("budget.size_limit"); limit != "" {
+		if b.sizeLimit, err = util.ParseSize(limit); err != nil {
+			return validationError(fmt.Errorf("invalid budget.size_limit: %w", err))
+		}
+	}
+
+	warnings := budgetWarnings(b, gen.Size, gen.Tokens, lastPush(opts.Directory).Size)
+	for _, warning := range warnings {
+		fmt.Println(style.Warning(style.Symbol("⚠ ", "! ") + "Budget: " + warning))
+	}
+	if len(warnings) > 0 && strict {
+		return validationError(errors.New("bundle budget exceeded (--strict)"))
+	}
+	return nil
+}
+
+// budgetWarnings returns the budget thresholds a bundle of a given size and
+// token count crosses. lastPush is the size of the previous push, 0 if
+// unknown.
+func budgetWarnings(b budget, size int64, tokenCount int, lastPush int64) []string {
+	var warnings []string
+	if b.sizeLimit > 0 {
+		percent := size * 100 / b.sizeLimit
+		switch {
+		case size > b.sizeLimit:
+			warnings = append(warnings, fmt.Sprintf("bundle is %s, over the %s limit", util.FormatSize(size), util.FormatSize(b.sizeLimit)))
+		case percent >= int64(b.warnPercent):
+			warnings = append(warnings, fmt.Sprintf("bundle is %s, %d%% of the %s limit", util.FormatSize(size), percent, util.FormatSize(b.sizeLimit)))
+		}
+	}
+	if b.tokenLimit > 0 {
+		percent := tokenCount * 100 / b.tokenLimit
+		switch {
+		case tokenCount > b.tokenLimit:
+			warnings = append(warnings, fmt.Sprintf("bundle is %s, over the %d token limit", tokens.Format(tokenCount), b.tokenLimit))
+		case percent >= b.warnPercent:
+			warnings = append(warnings, fmt.Sprintf("bundle is %s, %d%% of the %d token limit", tokens.Format(tokenCount), percent, b.tokenLimit))
+		}
+	}
+	if b.growthPercent > 0 && lastPush > 0 {
+		if growth := (size - lastPush) * 100 / lastPush; growth > int64(b.growthPercent) {
+			warnings = append(warnings, fmt.Sprintf("bundle grew by %d%% since the last push (%s to %s)", growth, util.FormatSize(lastPush), util.FormatSize(size)))
+		}
+	}
+	return warnings
+}
+
+// lastPush returns the last recorded push of a project, a zero entry if
+// there's none.
+func lastPush(directory string) stats.Entry {
+	cfg, err := config.New("")
+	if err != nil {
+		return stats.Entry{}
+	}
+	dir, _ := filepath.Abs(directory)
+	entries, err := stats.Read(stats.Path(cfg.Dir()), dir)
+	if err != nil {
+		return stats.Entry{}
+	}
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].Action == stats.ActionPush {
+			return entries[i]
+		}
+	}
+	return stats.Entry{}
+}
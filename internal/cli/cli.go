@@ -2,6 +2,17 @@
 package cli
 
 import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/holonoms/sandworm/internal/claude"
+	"github.com/holonoms/sandworm/internal/config"
+	"github.com/holonoms/sandworm/internal/processor"
+	"github.com/holonoms/sandworm/internal/profile"
+	"github.com/holonoms/sandworm/internal/sanitize"
+	"github.com/holonoms/sandworm/internal/source"
+	"github.com/holonoms/sandworm/internal/style"
 	"github.com/spf13/cobra"
 )
 
@@ -12,16 +23,52 @@ var (
 	version = "dev"
 )
 
+// Execute runs the command line args (without the program name), after
+// expanding aliases and the configured default command (see expandArgs).
+func Execute(opts *Options, args []string) error {
+	rootCmd := NewRootCmd(opts)
+	// Aliases may be in the global config of --config-dir, which is only
+	// applied once flags are parsed
+	for i, arg := range args {
+		dir, ok := strings.CutPrefix(arg, "--config-dir=")
+		if arg == "--config-dir" && i+1 < len(args) {
+			dir, ok = args[i+1], true
+		}
+		if ok {
+			if err := os.Setenv(config.DirEnv, dir); err != nil {
+				return fmt.Errorf("unable to set config directory: %w", err)
+			}
+		}
+	}
+	cfg, err := config.New(".")
+	if err != nil {
+		cfg = nil // Reported by the commands that need the config
+	}
+	if args, err = expandArgs(rootCmd, cfg, args); err != nil {
+		return err
+	}
+	rootCmd.SetArgs(args)
+	restore := withInterrupts()
+	defer restore()
+	cmd, err := rootCmd.ExecuteContextC(interrupt)
+	recordCommand(cfg, cmd, err)
+	return err
+}
+
 // NewRootCmd creates the root command with all subcommands
 func NewRootCmd(opts *Options) *cobra.Command {
 	if opts == nil {
 		opts = &Options{}
 	}
+	config.Passphrase = readPassphrase
 	rootCmd := &cobra.Command{
 		Use:          "sandworm [directory]",
 		Short:        "Project file concatenator",
 		Version:      version,
 		SilenceUsage: true,
+		// Errors are reported by the caller (see PrintError), which allows
+		// skipping non-failures such as ErrNothingToDo.
+		SilenceErrors: true,
 		// NB: ArbitraryArgs is required to avoid interpreting the first argument
 		// as a subcommand. This is necessary for the use case `sandworm [folder]`,
 		// where folder would otherwise be interpreted as a subcommand and fail.
@@ -32,14 +79,91 @@ func NewRootCmd(opts *Options) *cobra.Command {
 
 	rootCmd.PersistentFlags().StringVarP(&opts.OutputFile, "output", "o", "", "Output file")
 	rootCmd.PersistentFlags().StringVarP(&opts.IgnoreFile, "ignore", "i", "", "Ignore file (default: .gitignore)")
+	rootCmd.PersistentFlags().StringVar(&opts.DefaultIgnores, "default-ignores", "", "Built-in ignore categories to apply: "+strings.Join(processor.IgnoreCategories, ", ")+", all or none; -name to disable one (default: all)")
+	rootCmd.PersistentFlags().StringArrayVar(&opts.Exclude, "exclude", nil, "Exclude files matching a gitignore-style pattern, after the ignore file's (repeatable)")
+	rootCmd.PersistentFlags().StringArrayVar(&opts.Include, "include", nil, "Include files matching a gitignore-style pattern, even if ignored (repeatable)")
 	rootCmd.PersistentFlags().BoolVarP(&opts.KeepFile, "keep", "k", false, "Keep the generated file after pushing")
+	rootCmd.PersistentFlags().StringVar(&opts.Organization, "org", "", "Claude organization ID or name (overrides config)")
+	rootCmd.PersistentFlags().StringVar(&opts.Project, "project", "", "Claude project ID or name (overrides config)")
+	rootCmd.PersistentFlags().BoolVar(&opts.Refresh, "refresh", false, "Refresh cached organization/project metadata")
+	rootCmd.PersistentFlags().BoolVar(&opts.NoCache, "no-cache", false, "Don't cache or revalidate Claude API responses (implies --refresh)")
+	rootCmd.PersistentFlags().BoolVar(&opts.RefreshSources, "refresh-sources", false, "Fetch the URLs listed in "+source.SourcesFile+" again, regardless of their cached copies' age")
+	rootCmd.PersistentFlags().BoolVarP(&opts.AssumeYes, "yes", "y", false, "Skip confirmation prompts")
+	rootCmd.PersistentFlags().BoolVar(&opts.ReadOnly, "read-only", false, "Only list and read from Claude: push, purge and other changes fail (or set "+claude.ReadOnlyEnv+"=1)")
+	rootCmd.PersistentFlags().StringVar(&opts.ConfigDir, "config-dir", "", "Global config directory (default: the user config directory, or "+config.PortableDir+"/ next to the binary if it exists)")
+
+	var noColor bool
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "Disable colored output (also honors NO_COLOR)")
+	rootCmd.PersistentFlags().BoolVar(&opts.Plain, "plain", false, "ASCII-only output: no colors, unicode or emoji (also in generated files)")
+	rootCmd.PersistentFlags().BoolVar(&opts.Verbose, "verbose", false, "Show error details, such as unexpected Claude API responses")
+	rootCmd.PersistentFlags().StringVar(&opts.Profile, "profile", "", "Profile generate/push: cpu, mem or trace, written to the current directory, with a timing breakdown")
+
+	rootCmd.PersistentFlags().StringVar(&opts.Submodules, "submodules", "", "How to handle git submodules: full, tree (structure only) or skip (default: full)")
+	rootCmd.PersistentFlags().StringVar(&opts.Order, "order", "", "Order of the file contents: path or related (clustered by directory and imports, tests next to sources) (default: path)")
+	rootCmd.PersistentFlags().StringVar(&opts.Workspace, "workspace", "", "Only include a monorepo workspace package (name or path) and its local dependencies")
+	rootCmd.PersistentFlags().StringVar(&opts.Package, "package", "", "Only include a Go package (e.g. ./cmd/foo)")
+	rootCmd.PersistentFlags().BoolVar(&opts.Deps, "deps", false, "With --package, also include the local packages it imports")
+	rootCmd.PersistentFlags().StringVar(&opts.LicenseExclude, "license-exclude", "", "Exclude files whose license/copyright header matches a regular expression (overrides config setting)")
+	rootCmd.PersistentFlags().StringVar(&opts.LicenseReport, "license-report", "", "Write a license compliance report (excluded and included files) to a file")
+	rootCmd.PersistentFlags().StringVar(&opts.From, "from", "", "Read files from an archive (zip, tar, tar.gz, tar.bz2) instead of a directory")
+	rootCmd.PersistentFlags().StringVar(&opts.FromImage, "from-image", "", "Read files from a container image (uses docker or podman)")
+	rootCmd.PersistentFlags().StringSliceVar(&opts.ImagePaths, "image-path", nil, "Directory of the --from-image image to bundle (repeatable, default: the image's working directory)")
+	rootCmd.PersistentFlags().StringVar(&opts.Target, "target", "", "Only include the files of a selection saved with 'sandworm pick --save'")
+	rootCmd.PersistentFlags().StringVar(&opts.HeaderStyle, "header-style", "", "File header style: full, short or minimal (default: full)")
 
 	var followSymlinks bool
 	rootCmd.PersistentFlags().BoolVarP(&followSymlinks, "follow-symlinks", "L", false, "Follow symbolic links when traversing directories")
 
 	var showLineNumbers bool
 	rootCmd.PersistentFlags().BoolVarP(&showLineNumbers, "line-numbers", "n", false, "Show line numbers in output (overrides config setting)")
+
+	var dependencyGraph bool
+	rootCmd.PersistentFlags().BoolVar(&dependencyGraph, "dependency-graph", false, "Add a section summarizing imports between project files (overrides config setting)")
+
+	var symbolIndex bool
+	rootCmd.PersistentFlags().BoolVar(&symbolIndex, "symbol-index", false, "Add a section listing the exported symbols of each file (overrides config setting)")
+
+	var fileMetadata bool
+	rootCmd.PersistentFlags().BoolVar(&fileMetadata, "file-metadata", false, "Add size, line count, modification time and last commit to file headers (overrides config setting)")
+
+	var preset string
+	rootCmd.PersistentFlags().StringVar(&preset, "preset", "", "Apply the flags of a preset saved with 'sandworm preset save'")
+
+	var checksumManifest bool
+	rootCmd.PersistentFlags().BoolVar(&checksumManifest, "manifest", false, "Add a section with the SHA-256 of each file, see 'sandworm manifest' (overrides config setting)")
+
+	var normalize bool
+	rootCmd.PersistentFlags().BoolVar(&normalize, "normalize", false, "Convert CRLF line endings to LF and trim trailing whitespace in file contents (overrides config settings)")
+
+	rootCmd.PersistentFlags().StringVar(&opts.Sanitize, "sanitize", "", "Handle ANSI sequences, control and invisible characters: off, strip or escape (default: off)")
+
+	var scanInjection bool
+	rootCmd.PersistentFlags().BoolVar(&scanInjection, "scan-injection", false, "Flag instruction-like content that could hijack the model, asking before push (overrides config setting)")
+
+	var scrubPII bool
+	rootCmd.PersistentFlags().BoolVar(&scrubPII, "scrub-pii", false, "Mask emails, phone numbers and processor.scrub_pattern matches in file contents (overrides config setting)")
+	var scrubSecrets bool
+	rootCmd.PersistentFlags().BoolVar(&scrubSecrets, "scrub-secrets", false, "Mask credentials such as private keys and API tokens in file contents (overrides config setting)")
+	var chunkTokens int
+	rootCmd.PersistentFlags().IntVar(&chunkTokens, "chunk-tokens", 0, "Split the bundle into balanced parts of at most N tokens, at file boundaries (overrides config setting)")
 	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, _ []string) error {
+		// Set through the environment, so that child processes (e.g. the
+		// daemon) use the same config.
+		if opts.ConfigDir != "" {
+			if err := os.Setenv(config.DirEnv, opts.ConfigDir); err != nil {
+				return fmt.Errorf("unable to set config directory: %w", err)
+			}
+		}
+		if opts.ReadOnly {
+			if err := os.Setenv(claude.ReadOnlyEnv, "1"); err != nil {
+				return fmt.Errorf("unable to set read-only mode: %w", err)
+			}
+		}
+		if preset != "" {
+			if err := applyPreset(cmd, preset); err != nil {
+				return err
+			}
+		}
 		// Set the pointer only if the flag was explicitly provided; otherwise
 		// leave it as nil to use the project settings.
 		if cmd.Flags().Changed("line-numbers") {
@@ -48,17 +172,87 @@ func NewRootCmd(opts *Options) *cobra.Command {
 		if cmd.Flags().Changed("follow-symlinks") {
 			opts.FollowSymlinks = &followSymlinks
 		}
+		if cmd.Flags().Changed("dependency-graph") {
+			opts.DependencyGraph = &dependencyGraph
+		}
+		if cmd.Flags().Changed("symbol-index") {
+			opts.SymbolIndex = &symbolIndex
+		}
+		if cmd.Flags().Changed("file-metadata") {
+			opts.FileMetadata = &fileMetadata
+		}
+		if cmd.Flags().Changed("manifest") {
+			opts.ChecksumManifest = &checksumManifest
+		}
+		if cmd.Flags().Changed("normalize") {
+			opts.Normalize = &normalize
+		}
+		if cmd.Flags().Changed("scan-injection") {
+			opts.ScanInjection = &scanInjection
+		}
+		if cmd.Flags().Changed("scrub-pii") {
+			opts.ScrubPII = &scrubPII
+		}
+		if cmd.Flags().Changed("scrub-secrets") {
+			opts.ScrubSecrets = &scrubSecrets
+		}
+		if cmd.Flags().Changed("chunk-tokens") {
+			opts.ChunkTokens = &chunkTokens
+		}
+		if noColor {
+			style.SetEnabled(false)
+		}
+		if opts.Plain {
+			style.SetPlain(true)
+		}
+		if path, insecure := config.InsecureFile(); insecure {
+			fmt.Fprintln(os.Stderr, style.Warning(fmt.Sprintf("%s is readable by other users and may expose your session keys; run 'chmod 600 %s'", path, path)))
+		}
 		return nil
 	}
 
+	_ = rootCmd.RegisterFlagCompletionFunc("submodules", cobra.FixedCompletions(processor.SubmoduleModes, cobra.ShellCompDirectiveNoFileComp))
+	_ = rootCmd.RegisterFlagCompletionFunc("profile", cobra.FixedCompletions(profile.Modes, cobra.ShellCompDirectiveNoFileComp))
+	_ = rootCmd.RegisterFlagCompletionFunc("order", cobra.FixedCompletions(processor.OrderModes, cobra.ShellCompDirectiveNoFileComp))
+	_ = rootCmd.RegisterFlagCompletionFunc("sanitize", cobra.FixedCompletions(sanitize.Modes, cobra.ShellCompDirectiveNoFileComp))
+	_ = rootCmd.RegisterFlagCompletionFunc("default-ignores", cobra.FixedCompletions(append([]string{"all", "none"}, processor.IgnoreCategories...), cobra.ShellCompDirectiveNoFileComp))
+	_ = rootCmd.RegisterFlagCompletionFunc("header-style", cobra.FixedCompletions(processor.HeaderStyles, cobra.ShellCompDirectiveNoFileComp))
+
 	// Add commands
 	rootCmd.AddCommand(
 		newGenerateCmd(opts),
 		newPushCmd(opts),
-		newPurgeCmd(),
+		newPurgeCmd(opts),
+		newRestoreCmd(opts),
+		newDeployCmd(opts),
+		newDeploysCmd(opts),
+		newAskCmd(opts),
+		newConversationsCmd(opts),
 		newSetupCmd(),
 		newConfigCmd(),
+		newInstructionsCmd(opts),
+		newAccountsCmd(),
+		newWorkspacesCmd(opts),
+		newOpenCmd(opts),
+		newStatusCmd(opts),
+		newWatchCmd(opts),
+		newDaemonCmd(opts),
+		newIgnoreCmd(opts),
+		newPickCmd(opts),
+		newPresetCmd(),
+		newAliasCmd(),
+		newRunCmd(opts),
+		newAuditCmd(),
+		newStatsCmd(),
+		newManifestCmd(),
+		newComposeCmd(opts),
+		newDecryptCmd(opts),
+		newConvertersCmd(),
+		newBenchCmd(opts),
+		newReportCmd(opts),
+		newManCmd(),
 	)
+	rootCmd.AddCommand(newHelpTopicCmds()...)
 
 	return rootCmd
 }
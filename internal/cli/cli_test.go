@@ -1,9 +1,28 @@
 package cli
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
 	"testing"
+	"time"
+
+	"github.com/holonoms/sandworm/internal/audit"
+	"github.com/holonoms/sandworm/internal/claude"
+	"github.com/holonoms/sandworm/internal/config"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 func TestGenerateCmd_Flags(t *testing.T) {
@@ -94,3 +113,772 @@ func TestGenerateCmd_OutputIgnoreKeepFlags(t *testing.T) {
 		t.Errorf("Expected KeepFile to be true, got %v", opts.KeepFile)
 	}
 }
+
+func TestCommandOptionsDontBleed(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Chdir(t.TempDir())
+
+	opts := &Options{}
+	rootCmd := NewRootCmd(opts)
+	rootCmd.SetArgs([]string{"generate", tmpDir})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Command failed: %v", err)
+	}
+	if _, err := os.Stat("sandworm.txt"); err != nil {
+		t.Errorf("Expected sandworm.txt to be generated: %v", err)
+	}
+	// generate's defaults apply to its own options (see generateOptions) only
+	if opts.OutputFile != "" || opts.KeepFile {
+		t.Errorf("Expected parsed options to be unchanged, got OutputFile %q and KeepFile %v", opts.OutputFile, opts.KeepFile)
+	}
+}
+
+func TestMatchConfigOptions(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		prefix   string   // every match must start with this
+		expected []string // matches must include these
+	}{
+		{"claude.project_id", "claude.project_id", []string{"claude.project_id"}},
+		{"claude.*", "claude.", []string{"claude.organization_id", "claude.project_id", "claude.document_id"}},
+		{"processor", "processor.", []string{"processor.print_line_numbers", "processor.follow_symlinks"}},
+		{"processor.", "processor.", []string{"processor.print_line_numbers", "processor.follow_symlinks"}},
+		{"*.project_id", "claude.project_id", []string{"claude.project_id"}},
+		{"unknown", "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			matches := matchConfigOptions(tt.pattern)
+			keys := make(map[string]bool)
+			for _, option := range matches {
+				keys[option.Key] = true
+				if !strings.HasPrefix(option.Key, tt.prefix) {
+					t.Errorf("matchConfigOptions(%q) returned unexpected key %s", tt.pattern, option.Key)
+				}
+			}
+			for _, key := range tt.expected {
+				if !keys[key] {
+					t.Errorf("matchConfigOptions(%q) is missing %s", tt.pattern, key)
+				}
+			}
+			if tt.expected == nil && len(matches) > 0 {
+				t.Errorf("matchConfigOptions(%q) expected no matches, got %d", tt.pattern, len(matches))
+			}
+		})
+	}
+}
+
+func TestConfigEncodeDecode(t *testing.T) {
+	values := map[string]map[string]string{
+		"claude":    {"project_id": "abc"},
+		"processor": {"follow_symlinks": "true"},
+	}
+
+	for _, format := range []string{"json", "toml"} {
+		t.Run(format, func(t *testing.T) {
+			data, err := encodeConfig(values, format)
+			if err != nil {
+				t.Fatalf("encodeConfig failed: %v", err)
+			}
+			decoded, err := decodeConfig(data, format)
+			if err != nil {
+				t.Fatalf("decodeConfig failed: %v", err)
+			}
+			if decoded["claude"]["project_id"] != "abc" || decoded["processor"]["follow_symlinks"] != "true" {
+				t.Errorf("Round trip mismatch, got %v", decoded)
+			}
+		})
+	}
+}
+
+func TestConfirm(t *testing.T) {
+	defer func(r io.Reader) { promptInput = r }(promptInput)
+
+	tests := []struct {
+		input    string
+		expected bool
+		wantErr  bool
+	}{
+		{input: "y\n", expected: true},
+		{input: "YES\n", expected: true},
+		{input: "n\n", expected: false},
+		{input: "\n", expected: false},
+		{input: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		promptInput = strings.NewReader(tt.input)
+		ok, err := confirm("Continue?", false)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("confirm(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+		}
+		if ok != tt.expected {
+			t.Errorf("confirm(%q) = %v, want %v", tt.input, ok, tt.expected)
+		}
+	}
+
+	// --yes skips reading input entirely
+	promptInput = strings.NewReader("")
+	if ok, err := confirm("Continue?", true); !ok || err != nil {
+		t.Errorf("Expected confirm to be skipped, got %v, %v", ok, err)
+	}
+}
+
+func TestChoose(t *testing.T) {
+	defer func(r io.Reader) { promptInput = r }(promptInput)
+
+	tests := []struct {
+		input    string
+		expected int
+		wantErr  bool
+	}{
+		{input: "2\n", expected: 1},
+		{input: "0\nfoo\n3\n", expected: 2}, // Asked again until valid
+		{input: "4\n", wantErr: true},
+		{input: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		promptInput = strings.NewReader(tt.input)
+		i, err := choose("Which one?", []string{"a", "b", "c"})
+		if (err != nil) != tt.wantErr {
+			t.Errorf("choose(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+		}
+		if err == nil && i != tt.expected {
+			t.Errorf("choose(%q) = %d, want %d", tt.input, i, tt.expected)
+		}
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{"no error", nil, ExitOK},
+		{"generic error", errors.New("boom"), ExitError},
+		{"nothing to do", fmt.Errorf("purge: %w", ErrNothingToDo), ExitNothingToDo},
+		{"auth error", fmt.Errorf("push: %w", &claude.APIError{StatusCode: 403}), ExitAuth},
+		{"other API error", &claude.APIError{StatusCode: 500}, ExitError},
+		{"validation error", validationError(errors.New("bad value")), ExitValidation},
+		{"missing config", fmt.Errorf("%w: claude.project_id", claude.ErrMissingConfig), ExitValidation},
+		{"malformed config file", fmt.Errorf("unable to load config: %w", &config.FileError{Path: ".sandworm", Err: errors.New("bad")}), ExitValidation},
+		{"network error", fmt.Errorf("request failed: %w", &net.OpError{Op: "dial", Err: errors.New("refused")}), ExitNetwork},
+		{"file error", fmt.Errorf("unable to read: %w", &os.PathError{Op: "open", Path: "x", Err: syscall.ENOENT}), ExitError},
+		{"interrupted", fmt.Errorf("unable to process files: %w", ErrInterrupted), ExitInterrupted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExitCode(tt.err); got != tt.expected {
+				t.Errorf("ExitCode(%v) = %d, want %d", tt.err, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDescribeChanges(t *testing.T) {
+	if got := describeChanges([]string{"a.go", "b.go"}); got != "Changed: a.go, b.go" {
+		t.Errorf("Unexpected description: %s", got)
+	}
+	if got := describeChanges([]string{"a.go", "b.go", "c.go", "d.go", "e.go"}); got != "Changed: a.go, b.go, c.go and 2 more" {
+		t.Errorf("Unexpected description: %s", got)
+	}
+}
+
+func TestAppendIgnoreRules(t *testing.T) {
+	tmpDir := t.TempDir()
+	target := filepath.Join(tmpDir, ".sandwormignore")
+	gitignore := filepath.Join(tmpDir, ".gitignore")
+	if err := os.WriteFile(gitignore, []byte("*.tmp"), 0o644); err != nil {
+		t.Fatalf("Failed to write .gitignore: %v", err)
+	}
+
+	t.Run("new file is seeded from .gitignore", func(t *testing.T) {
+		if err := appendIgnoreRules(target, gitignore, []string{"/vendor/"}); err != nil {
+			t.Fatalf("appendIgnoreRules failed: %v", err)
+		}
+		data, err := os.ReadFile(target)
+		if err != nil {
+			t.Fatalf("Failed to read ignore file: %v", err)
+		}
+		want := "# Rules from .gitignore\n*.tmp\n\n# Added by 'sandworm ignore suggest'\n/vendor/\n"
+		if string(data) != want {
+			t.Errorf("Expected:\n%q\nGot:\n%q", want, data)
+		}
+	})
+
+	t.Run("existing file is appended to", func(t *testing.T) {
+		if err := appendIgnoreRules(target, gitignore, []string{"*.pb.go"}); err != nil {
+			t.Fatalf("appendIgnoreRules failed: %v", err)
+		}
+		data, err := os.ReadFile(target)
+		if err != nil {
+			t.Fatalf("Failed to read ignore file: %v", err)
+		}
+		if !strings.HasSuffix(string(data), "/vendor/\n\n# Added by 'sandworm ignore suggest'\n*.pb.go\n") {
+			t.Errorf("Unexpected content:\n%s", data)
+		}
+	})
+}
+
+func TestPresetArgs(t *testing.T) {
+	flags := []string{"--header-style=short", "--symbol-index=true", "--output=my context.txt"}
+	formatted := formatPresetArgs(flags)
+	if want := `--header-style=short --symbol-index=true "--output=my context.txt"`; formatted != want {
+		t.Errorf("Expected %q, got %q", want, formatted)
+	}
+
+	parsed, err := parsePresetArgs(formatted)
+	if err != nil {
+		t.Fatalf("Failed to parse preset: %v", err)
+	}
+	if strings.Join(parsed, "|") != strings.Join(flags, "|") {
+		t.Errorf("Expected %q, got %q", flags, parsed)
+	}
+
+	if _, err := parsePresetArgs("--plain src"); err == nil {
+		t.Error("Expected an error for a non-flag argument")
+	}
+
+	// Repeatable flags are saved once per value
+	set := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	set.StringArray("exclude", nil, "")
+	set.String("preset", "", "")
+	if err := set.Parse([]string{"--exclude", "*.log", "--exclude", "a,b", "--preset", "docs"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(flagArgs(set, "preset"), " "); got != "--exclude=*.log --exclude=a,b" {
+		t.Errorf("Unexpected flags: %s", got)
+	}
+}
+
+func TestBundleParts(t *testing.T) {
+	if got := partName("project.txt", 2); got != "project-2.txt" {
+		t.Errorf("Unexpected part name: %s", got)
+	}
+	for doc, want := range map[string]bool{
+		"project-1.txt":  true,
+		"project-12.txt": true,
+		"project.txt":    false,
+		"project-.txt":   false,
+		"project-a.txt":  false,
+		"project-1.md":   false,
+		"notes-1.txt":    false,
+	} {
+		if got := isPartOf(doc, "project.txt"); got != want {
+			t.Errorf("isPartOf(%q) = %v, want %v", doc, got, want)
+		}
+	}
+}
+
+func TestBudgetWarnings(t *testing.T) {
+	b := budget{sizeLimit: 1000, tokenLimit: 200, warnPercent: 80, growthPercent: 20}
+	tests := []struct {
+		name     string
+		size     int64
+		tokens   int
+		lastPush int64
+		expected []string
+	}{
+		{name: "within budget", size: 500, lastPush: 450},
+		{name: "near limit", size: 850, expected: []string{"85% of the"}},
+		{name: "over limit", size: 1200, lastPush: 1100, expected: []string{"over the"}},
+		{name: "growth", size: 600, lastPush: 400, expected: []string{"grew by 50%"}},
+		{name: "both", size: 900, lastPush: 500, expected: []string{"90% of the", "grew by 80%"}},
+		{name: "near token limit", size: 500, tokens: 170, expected: []string{"85% of the 200 token limit"}},
+		{name: "over token limit", size: 500, tokens: 250, expected: []string{"over the 200 token limit"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warnings := budgetWarnings(b, tt.size, tt.tokens, tt.lastPush)
+			if len(warnings) != len(tt.expected) {
+				t.Fatalf("Expected %d warnings, got %q", len(tt.expected), warnings)
+			}
+			for i, expected := range tt.expected {
+				if !strings.Contains(warnings[i], expected) {
+					t.Errorf("Expected warning %q to contain %q", warnings[i], expected)
+				}
+			}
+		})
+	}
+
+	if warnings := budgetWarnings(budget{}, 1<<30, 1<<20, 1); len(warnings) != 0 {
+		t.Errorf("Expected no warnings without thresholds, got %q", warnings)
+	}
+}
+
+func TestSuspiciousRoot(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	loose := t.TempDir()
+	if err := os.WriteFile(filepath.Join(loose, "notes.txt"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	unmarked := t.TempDir()
+	if err := os.Mkdir(filepath.Join(unmarked, "photos"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	project := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(project, ".git"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(project, "internal", "cli"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	// A dotfiles repository doesn't make the home directory a project
+	if err := os.MkdirAll(filepath.Join(home, ".git"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(home, "Downloads", "photos"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		dir      string
+		expected string
+	}{
+		{name: "home", dir: home, expected: "your home directory"},
+		{name: "root", dir: string(filepath.Separator), expected: "the filesystem root"},
+		{name: "no markers", dir: unmarked, expected: "not a recognizable project"},
+		{name: "loose files", dir: loose},
+		{name: "project", dir: project},
+		{name: "project subdirectory", dir: filepath.Join(project, "internal")},
+		{name: "home subdirectory", dir: filepath.Join(home, "Downloads"), expected: "not a recognizable project"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason := suspiciousRoot(tt.dir)
+			if (tt.expected == "") != (reason == "") || !strings.HasPrefix(reason, tt.expected) {
+				t.Errorf("Expected %q, got %q", tt.expected, reason)
+			}
+		})
+	}
+}
+
+func TestWindowsRoot(t *testing.T) {
+	tests := []struct {
+		dir      string
+		expected string
+	}{
+		{dir: "/mnt/c", expected: "the root of drive C:"},
+		{dir: "/mnt/c/Users/me", expected: "a Windows home directory"},
+		{dir: "/mnt/c/Users/me/project"},
+		{dir: "/mnt/d/src"},
+		{dir: "/home/me"},
+	}
+	for _, tt := range tests {
+		if reason := windowsRoot(tt.dir, "/mnt/"); reason != tt.expected {
+			t.Errorf("Expected %q for %s, got %q", tt.expected, tt.dir, reason)
+		}
+	}
+}
+
+func TestConversationName(t *testing.T) {
+	tests := []struct {
+		question string
+		expected string
+	}{
+		{question: "Why?", expected: "Why?"},
+		{question: "  How does\nthe  watcher work?\n", expected: "How does the watcher work?"},
+		{question: strings.Repeat("a", 70), expected: strings.Repeat("a", 60) + "..."},
+	}
+
+	for _, tt := range tests {
+		if got := conversationName(tt.question); got != tt.expected {
+			t.Errorf("conversationName(%q) = %q, expected %q", tt.question, got, tt.expected)
+		}
+	}
+}
+
+func TestTranscript(t *testing.T) {
+	created := time.Date(2025, 7, 19, 12, 0, 0, 0, time.UTC)
+	conv := claude.Conversation{ID: "3f2a9c1e-0000-4000-8000-000000000000", Name: "API design: errors?", CreatedAt: created}
+
+	if got, expected := transcriptFileName(conv), "2025-07-19-api-design-errors-3f2a9c1e.md"; got != expected {
+		t.Errorf("Expected file name %q, got %q", expected, got)
+	}
+	if got, expected := transcriptFileName(claude.Conversation{ID: "c-1"}), "c.md"; got != expected {
+		t.Errorf("Expected file name %q, got %q", expected, got)
+	}
+
+	markdown := transcriptMarkdown(conv, []claude.Message{
+		{Sender: claude.SenderHuman, Text: "How should errors look?"},
+		{Sender: claude.SenderAssistant, Text: "Wrap them.\n"},
+	}, created)
+	for _, expected := range []string{
+		"# API design: errors?\n",
+		"- Conversation: https://claude.ai/chat/" + conv.ID + "\n",
+		"\n## Human\n\nHow should errors look?\n\n## Claude\n\nWrap them.\n",
+	} {
+		if !strings.Contains(markdown, expected) {
+			t.Errorf("Expected transcript to contain %q, got:\n%s", expected, markdown)
+		}
+	}
+}
+
+func TestDescribeFailure(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{name: "missing config", err: fmt.Errorf("push: %w", claude.ErrMissingConfig), expected: "not configured, run 'sandworm setup'"},
+		{name: "auth", err: &claude.APIError{StatusCode: 401}, expected: "rejected (401), the session key may have expired: run 'sandworm setup'"},
+		{name: "rate limited", err: &claude.APIError{StatusCode: 429}, expected: "usage limit reached"},
+		{name: "overloaded", err: &claude.APIError{StatusCode: 529}, expected: "service error (529)"},
+		{name: "other", err: &claude.APIError{StatusCode: 400}, expected: "request failed (400)"},
+		{name: "network", err: errors.New("request failed: timeout"), expected: "request failed: timeout"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := describeFailure(tt.err); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestFormatReset(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		in       time.Duration
+		expected string
+	}{
+		{in: -time.Minute, expected: "resets now"},
+		{in: 2*time.Hour + 5*time.Minute, expected: "(in 2h5m)"},
+		{in: 3*24*time.Hour + 4*time.Hour + 20*time.Minute, expected: "(in 3d4h)"},
+	}
+
+	for _, tt := range tests {
+		if got := formatReset(now.Add(tt.in), now); !strings.HasSuffix(got, tt.expected) {
+			t.Errorf("formatReset(%v) = %q, expected it to end with %q", tt.in, got, tt.expected)
+		}
+	}
+}
+
+func TestFormatExpiry(t *testing.T) {
+	now := time.Date(2026, 1, 10, 12, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		expires time.Time
+		want    string
+	}{
+		{now.Add(12 * 24 * time.Hour), "around Jan 22 (in 12 days)"},
+		{now.Add(5 * time.Hour), "around Jan 10 (within a day)"},
+		{now.Add(-time.Hour), "any time now (estimated around Jan 10)"},
+	}
+	for _, tt := range tests {
+		// Soon to expire sessions are highlighted
+		if got := formatExpiry(tt.expires, now); !strings.Contains(got, tt.want) {
+			t.Errorf("formatExpiry(%v) = %q, expected it to contain %q", tt.expires, got, tt.want)
+		}
+	}
+}
+
+func TestCheckConfig(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	dir := t.TempDir()
+	content := `{
+  "processor": {
+    "symbol_index": "true",
+    "print_line_number": "true",
+    "max_files": "lots"
+  },
+  "presets": {"review": "--header-style=short"},
+  "preset": {"docs": "--plain"},
+  "converters": {"pdf": ""}
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, ".sandworm"), []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	cfg, err := config.New(dir)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	var got []string
+	for _, problem := range checkConfig(cfg) {
+		got = append(got, fmt.Sprintf("%t %s", problem.Invalid, strings.TrimPrefix(problem.String(), dir+string(filepath.Separator))))
+	}
+	want := []string{
+		"false .sandworm:4: unknown key 'processor.print_line_number' (did you mean 'processor.print_line_numbers'?)",
+		"true .sandworm:5: invalid value for processor.max_files: value must be a non-negative integer, got: lots",
+		"false .sandworm:8: unknown key 'preset.docs' (did you mean 'presets.docs'?)",
+		"false .sandworm:9: 'converters.pdf' is ignored in this file, it's only read from " + cfg.Source("converters.pdf"),
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Unexpected problems:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestFilterDeploys(t *testing.T) {
+	entries := []audit.Entry{
+		{Action: audit.ActionPush, Project: "p1"},
+		{Action: audit.ActionDeploy, Project: "p1", ProjectName: "Backend", Deploy: &audit.Deploy{Version: "v1.0.0"}},
+		{Action: audit.ActionDeploy, Project: "p2", Deploy: &audit.Deploy{Version: "v2.0.0"}},
+	}
+
+	if deploys := filterDeploys(entries); len(deploys) != 2 || deploys[0].Deploy.Version != "v1.0.0" {
+		t.Errorf("Expected every deploy, got %+v", deploys)
+	}
+}
+
+func TestExpandArgs(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	dir := t.TempDir()
+	t.Chdir(dir)
+	// The default command is only read from the global config
+	content := `{"aliases": {"p": "push --prune", "g": "--keep -o out.txt", "docs": "generate"}, "cli": {"default_command": "watch"}}`
+	if err := os.WriteFile(filepath.Join(dir, ".sandworm"), []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "docs"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.New(dir)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if got, _ := expandArgs(NewRootCmd(nil), cfg, []string{"src"}); strings.Join(got, " ") != "src" {
+		t.Errorf("Expected the project's default command to be ignored, got %q", got)
+	}
+	if err := cfg.Set("cli.default_command", "generate"); err != nil {
+		t.Fatalf("Failed to set default command: %v", err)
+	}
+	root := NewRootCmd(nil)
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"push", "src"}, "push src"},
+		{[]string{"-y", "p", "src"}, "-y push --prune src"},
+		{[]string{"--project", "p", "status"}, "--project p status"},
+		{[]string{"-o", "p", "src"}, "generate -o p src"},
+		{[]string{"g", "src"}, "generate --keep -o out.txt src"},
+		{[]string{"src"}, "generate src"},
+		// Existing paths aren't expanded as aliases
+		{[]string{"docs"}, "generate docs"},
+		{nil, "generate"},
+		{[]string{"--version"}, "--version"},
+		{[]string{"help"}, "help"},
+	}
+	for _, tt := range tests {
+		got, err := expandArgs(root, cfg, tt.args)
+		if err != nil || strings.Join(got, " ") != tt.want {
+			t.Errorf("expandArgs(%q) = %q, %v, want %q", tt.args, got, err, tt.want)
+		}
+	}
+
+	if got, _ := expandArgs(root, nil, []string{"p"}); strings.Join(got, " ") != "p" {
+		t.Errorf("Expected args to be left as is without config, got %q", got)
+	}
+}
+
+func TestAliasSetRejectsPaths(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	dir := t.TempDir()
+	t.Chdir(dir)
+	if err := os.Mkdir(filepath.Join(dir, "docs"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	root := NewRootCmd(nil)
+	if err := runAliasSet(root, "docs", "generate"); ExitCode(err) != ExitValidation {
+		t.Errorf("Expected an alias named after a directory to be rejected, got %v", err)
+	}
+	if err := runAliasSet(root, "push", "generate"); ExitCode(err) != ExitValidation {
+		t.Errorf("Expected an alias named after a command to be rejected, got %v", err)
+	}
+	if err := runAliasSet(root, "d", "generate"); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestHelpTopics(t *testing.T) {
+	root := NewRootCmd(nil)
+	var out bytes.Buffer
+	root.SetOut(&out)
+	root.SetArgs([]string{"help", "config-options"})
+	if err := root.Execute(); err != nil {
+		t.Fatalf("Command failed: %v", err)
+	}
+	// Generated from the registry of options
+	if !strings.Contains(out.String(), "processor.header_style") || strings.Contains(out.String(), "Usage:") {
+		t.Errorf("Expected the config options topic alone, got:\n%s", out.String())
+	}
+
+	for _, topic := range helpTopics {
+		cmd, _, err := root.Find([]string{topic.name})
+		if err != nil || !cmd.IsAdditionalHelpTopicCommand() {
+			t.Errorf("Expected '%s' to be a help topic, got %v", topic.name, err)
+		}
+	}
+	if got := wrapWords([]string{"*.a", "*.bb", "*.c"}, 8); strings.Join(got, "|") != "*.a *.bb|*.c" {
+		t.Errorf("wrapWords = %q", got)
+	}
+}
+
+func TestRecordedArgs(t *testing.T) {
+	root := &cobra.Command{Use: "sandworm"}
+	ask := &cobra.Command{Use: "ask", RunE: func(*cobra.Command, []string) error { return nil }}
+	ask.Flags().String("project", "", "")
+	ask.Flags().Int("max-tokens", 0, "")
+	ask.Flags().Bool("yes", false, "")
+	root.AddCommand(ask)
+	root.SetArgs([]string{"ask", "--project", "Secret plans", "--max-tokens", "100", "--yes", "what does main.go do?"})
+	cmd, err := root.ExecuteC()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "ask --max-tokens=100 --project=[SET] --yes=true [ARG]"
+	if got := strings.Join(recordedArgs(cmd), " "); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	for key, want := range map[string]bool{
+		"processor.header_style": true, // A valid value
+		"processor.max_files":    true, // A number
+		"watch.debounce":         true, // A duration
+		"claude.project_id":      false,
+		"unknown.key":            false,
+	} {
+		value := map[string]string{"processor.header_style": "short", "processor.max_files": "10", "watch.debounce": "2s"}[key]
+		if value == "" {
+			value = "Secret plans"
+		}
+		if got := reportableValue(key, value); got != want {
+			t.Errorf("reportableValue(%s, %s) = %t, expected %t", key, value, got, want)
+		}
+	}
+}
+
+func TestInstructions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("The test editor is a shell script")
+	}
+	instructions := "Be brief"
+	var puts int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			var fields map[string]string
+			if err := json.NewDecoder(r.Body).Decode(&fields); err != nil {
+				t.Errorf("Unexpected request body: %v", err)
+			}
+			instructions = fields["prompt_template"]
+			puts++
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"uuid": "p-1", "name": "Project", "prompt_template": instructions})
+	}))
+	defer server.Close()
+	dir := setupTestProject(t, server.URL)
+	opts := &Options{}
+
+	if err := runInstructionsGet(opts, "saved.md"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if saved, _ := os.ReadFile(filepath.Join(dir, "saved.md")); string(saved) != "Be brief" {
+		t.Errorf("Expected the instructions to be saved, got %q", saved)
+	}
+
+	if err := runInstructionsSet(opts, instructionsFile); err == nil {
+		t.Error("Expected an error without " + instructionsFile)
+	}
+	if err := os.WriteFile(filepath.Join(dir, instructionsFile), []byte("Be thorough"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := runInstructionsSet(opts, instructionsFile); err != nil || instructions != "Be thorough" {
+		t.Errorf("Expected the file to be uploaded, got %q, %v", instructions, err)
+	}
+
+	// Editing the file in place uploads it, even unchanged
+	editor := filepath.Join(t.TempDir(), "editor")
+	if err := os.WriteFile(editor, []byte("#!/bin/sh\nprintf ', cite sources' >> \"$1\"\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("VISUAL", editor)
+	if err := runInstructionsEdit(opts); err != nil || instructions != "Be thorough, cite sources" {
+		t.Errorf("Expected the edited file to be uploaded, got %q, %v", instructions, err)
+	}
+	if content, _ := os.ReadFile(filepath.Join(dir, instructionsFile)); string(content) != instructions {
+		t.Errorf("Expected %s to be edited in place, got %q", instructionsFile, content)
+	}
+
+	// Without the file, a temporary copy of the remote instructions is edited
+	if err := os.Remove(filepath.Join(dir, instructionsFile)); err != nil {
+		t.Fatal(err)
+	}
+	if err := runInstructionsEdit(opts); err != nil || instructions != "Be thorough, cite sources, cite sources" {
+		t.Errorf("Expected the edited copy to be uploaded, got %q, %v", instructions, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, instructionsFile)); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected %s not to be created, got %v", instructionsFile, err)
+	}
+	t.Setenv("VISUAL", "true")
+	before := puts
+	if err := runInstructionsEdit(opts); err != nil || puts != before {
+		t.Errorf("Expected unchanged instructions not to be uploaded, got %d uploads, %v", puts-before, err)
+	}
+}
+
+// setupTestProject configures a Claude project served by apiURL in a
+// temporary directory, which becomes the working directory.
+func setupTestProject(t *testing.T, apiURL string) string {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv(claude.ReadOnlyEnv, "")
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	cfg, err := config.New(dir)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	for key, value := range map[string]string{
+		"claude.api_url":         apiURL,
+		"claude.session_key":     "sk-test",
+		"claude.organization_id": "o-1",
+		"claude.project_id":      "p-1",
+	} {
+		if err := cfg.Set(key, value); err != nil {
+			t.Fatalf("Failed to set %s: %v", key, err)
+		}
+	}
+	return dir
+}
+
+func TestProjectURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`[{"uuid":"p-1","name":"Backend"},{"uuid":"p-2","name":"Docs"}]`))
+	}))
+	defer server.Close()
+	dir := setupTestProject(t, server.URL)
+
+	if url, err := projectURL(&Options{}); err != nil || url != "https://claude.ai/project/p-1" {
+		t.Errorf("Expected the configured project's URL, got %q, %v", url, err)
+	}
+	if url, err := projectURL(&Options{Project: "Docs"}); err != nil || url != "https://claude.ai/project/p-2" {
+		t.Errorf("Expected --project to be resolved by name, got %q, %v", url, err)
+	}
+	if _, err := projectURL(&Options{Project: "Frontend"}); err == nil {
+		t.Error("Expected an error for an unknown project")
+	}
+
+	if err := os.Remove(filepath.Join(dir, ".sandworm")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := projectURL(&Options{}); ExitCode(err) != ExitValidation {
+		t.Errorf("Expected a validation error without a configured project, got %v", err)
+	}
+}
@@ -0,0 +1,243 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/holonoms/sandworm/internal/claude"
+	"github.com/holonoms/sandworm/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// newAccountsCmd creates the accounts command and its subcommands
+func newAccountsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "accounts",
+		Short: "Manage Claude accounts (session keys)",
+	}
+
+	cmd.AddCommand(
+		newAccountsListCmd(),
+		newAccountsAddCmd(),
+		newAccountsSwitchCmd(),
+		newAccountsRemoveCmd(),
+	)
+
+	return cmd
+}
+
+func newAccountsListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List configured accounts",
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return runAccountsList()
+		},
+	}
+
+	return cmd
+}
+
+func runAccountsList() error {
+	cfg, err := config.New(".")
+	if err != nil {
+		return fmt.Errorf("unable to load config: %w", err)
+	}
+
+	labels := cfg.Keys("accounts")
+	if len(labels) == 0 {
+		fmt.Println("No accounts configured. Run 'sandworm accounts add <label>' to add one.")
+		return nil
+	}
+
+	bound := cfg.Get("claude.account")
+	defaultLabel := cfg.Get("claude.default_account")
+	for _, label := range labels {
+		marker := "  "
+		if label == bound || (bound == "" && label == defaultLabel) {
+			marker = "* "
+		}
+		var notes []string
+		if label == bound {
+			notes = append(notes, "this project")
+		}
+		if label == defaultLabel {
+			notes = append(notes, "default")
+		}
+		if len(notes) > 0 {
+			fmt.Printf("%s%s (%s)\n", marker, label, strings.Join(notes, ", "))
+		} else {
+			fmt.Printf("%s%s\n", marker, label)
+		}
+	}
+
+	return nil
+}
+
+func newAccountsAddCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add <label>",
+		Short: "Add an account (prompts for its session key)",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			return runAccountsAdd(args[0])
+		},
+	}
+
+	return cmd
+}
+
+func runAccountsAdd(label string) error {
+	if err := validateAccountLabel(label); err != nil {
+		return err
+	}
+
+	cfg, err := config.New(".")
+	if err != nil {
+		return fmt.Errorf("unable to load config: %w", err)
+	}
+
+	fmt.Println("Please go to https://claude.ai in your browser and copy your session key from the Cookie header.")
+	fmt.Print("Enter the session key for '" + label + "': ")
+	input, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to read session key: %w", err)
+	}
+	if strings.TrimSpace(input) == "" {
+		return validationError(errors.New("no session key entered"))
+	}
+	key, err := claude.ParseSessionKey(input)
+	if err != nil {
+		return validationError(err)
+	}
+
+	// Checked before saving, rather than failing with a 403 on first use
+	session, err := claude.New(cfg).CheckSessionKey(key)
+	if err != nil {
+		return fmt.Errorf("unable to check session key: %w", err)
+	}
+	if err := claude.SetSessionKey(cfg, label, key); err != nil {
+		return fmt.Errorf("unable to save account: %w", err)
+	}
+	fmt.Printf("Signed in as %s\n", session)
+
+	// The first account becomes the default
+	if !cfg.Has("claude.default_account") {
+		if err := cfg.Set("claude.default_account", label); err != nil {
+			return fmt.Errorf("unable to set default account: %w", err)
+		}
+	}
+
+	fmt.Printf("Added account '%s'\n", label)
+	return nil
+}
+
+func newAccountsSwitchCmd() *cobra.Command {
+	var global bool
+	cmd := &cobra.Command{
+		Use:   "switch <label>",
+		Short: "Use an account for this project (or by default, with --global)",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			return runAccountsSwitch(args[0], global)
+		},
+		ValidArgsFunction: accountLabelCompletion,
+	}
+
+	cmd.Flags().BoolVarP(&global, "global", "g", false, "Set the default account for all projects")
+
+	return cmd
+}
+
+func runAccountsSwitch(label string, global bool) error {
+	cfg, err := config.New(".")
+	if err != nil {
+		return fmt.Errorf("unable to load config: %w", err)
+	}
+
+	if !cfg.Has(claude.AccountKey(label)) {
+		return validationError(fmt.Errorf("unknown account: %s\n\nRun 'sandworm accounts list' to see available accounts", label))
+	}
+
+	key := "claude.account"
+	if global {
+		key = "claude.default_account"
+	}
+	if err := cfg.Set(key, label); err != nil {
+		return fmt.Errorf("unable to switch account: %w", err)
+	}
+
+	if global {
+		fmt.Printf("Default account is now '%s'\n", label)
+	} else {
+		fmt.Printf("This project now uses account '%s'\n", label)
+	}
+	return nil
+}
+
+func newAccountsRemoveCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "remove <label>",
+		Short:             "Remove an account",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: accountLabelCompletion,
+		RunE: func(_ *cobra.Command, args []string) error {
+			return runAccountsRemove(args[0])
+		},
+	}
+
+	return cmd
+}
+
+func runAccountsRemove(label string) error {
+	cfg, err := config.New(".")
+	if err != nil {
+		return fmt.Errorf("unable to load config: %w", err)
+	}
+
+	if !cfg.Has(claude.AccountKey(label)) {
+		return validationError(fmt.Errorf("unknown account: %s", label))
+	}
+	for _, key := range []string{claude.AccountKey(label), claude.SessionAddedKey(claude.AccountKey(label))} {
+		if err := cfg.Delete(key); err != nil {
+			return fmt.Errorf("unable to remove account: %w", err)
+		}
+	}
+
+	// Drop bindings to the removed account
+	for _, key := range []string{"claude.account", "claude.default_account"} {
+		if cfg.Get(key) == label {
+			if err := cfg.Delete(key); err != nil {
+				return fmt.Errorf("unable to remove account: %w", err)
+			}
+		}
+	}
+
+	fmt.Printf("Removed account '%s'\n", label)
+	return nil
+}
+
+// MARK: Helpers
+
+func validateAccountLabel(label string) error {
+	if label == "" || strings.ContainsAny(label, " \t\n") {
+		return validationError(fmt.Errorf("invalid account label: %q (must be non-empty, without spaces)", label))
+	}
+	return nil
+}
+
+func accountLabelCompletion(_ *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	cfg, err := config.New(".")
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return cfg.Keys("accounts"), cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,251 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/holonoms/sandworm/internal/config"
+	"github.com/holonoms/sandworm/internal/style"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// defaultCommands are the commands cli.default_command can run when no
+// subcommand is given.
+var defaultCommands = []string{"push", "generate", "watch"}
+
+// aliasNameRE matches valid alias names (config keys can't contain dots).
+var aliasNameRE = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// newAliasCmd creates the alias command and its subcommands
+func newAliasCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "alias",
+		Short: "Manage command aliases, e.g. 'sandworm p' for 'sandworm push --prune'",
+	}
+
+	cmd.AddCommand(
+		newAliasSetCmd(),
+		newAliasListCmd(),
+		newAliasRemoveCmd(),
+	)
+
+	return cmd
+}
+
+func newAliasSetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set <name> <command>",
+		Short: "Define an alias for a command and its flags",
+		Long: `Define an alias for a command and its flags, in the project config. Running
+'sandworm <name> [args]' then runs the command, with args appended. Quote the
+command, or pass it after '--'.`,
+		Example: `  sandworm alias set p "push --prune --backup"
+  sandworm alias set docs -- generate --include 'docs/**' --output docs.txt`,
+		Args: cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			command := args[1]
+			if len(args) > 2 {
+				command = formatPresetArgs(args[1:])
+			}
+			return runAliasSet(cmd.Root(), args[0], command)
+		},
+	}
+
+	return cmd
+}
+
+func runAliasSet(root *cobra.Command, name, command string) error {
+	if !aliasNameRE.MatchString(name) {
+		return validationError(fmt.Errorf("invalid alias name '%s' (letters, digits, '-' and '_' only)", name))
+	}
+	if isCommand(root, name) {
+		return validationError(fmt.Errorf("'%s' is a sandworm command", name))
+	}
+	if isPath(name) {
+		return validationError(fmt.Errorf("'%s' is a path in this directory, which 'sandworm %s' bundles", name, name))
+	}
+	args, err := splitArgs(command)
+	if err != nil {
+		return validationError(fmt.Errorf("invalid command: %w", err))
+	}
+	if len(args) == 0 {
+		return validationError(errors.New("empty command"))
+	}
+
+	cfg, err := config.New(".")
+	if err != nil {
+		return fmt.Errorf("unable to load config: %w", err)
+	}
+	if err := cfg.Set("aliases."+name, formatPresetArgs(args)); err != nil {
+		return fmt.Errorf("unable to save alias: %w", err)
+	}
+
+	fmt.Println(style.Success(fmt.Sprintf("Saved alias '%s': sandworm %s", name, formatPresetArgs(args))))
+	return nil
+}
+
+func newAliasListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List aliases",
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return runAliasList()
+		},
+	}
+
+	return cmd
+}
+
+func runAliasList() error {
+	cfg, err := config.New(".")
+	if err != nil {
+		return fmt.Errorf("unable to load config: %w", err)
+	}
+
+	names := cfg.Keys("aliases")
+	if len(names) == 0 {
+		fmt.Println("No aliases defined. Run 'sandworm alias set <name> <command>' to add one.")
+		return nil
+	}
+	for _, name := range names {
+		fmt.Printf("%s %s\n", name, style.Dim("sandworm "+cfg.Get("aliases."+name)))
+	}
+
+	return nil
+}
+
+func newAliasRemoveCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "remove <name>",
+		Short: "Remove an alias",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			return runAliasRemove(args[0])
+		},
+	}
+
+	return cmd
+}
+
+func runAliasRemove(name string) error {
+	cfg, err := config.New(".")
+	if err != nil {
+		return fmt.Errorf("unable to load config: %w", err)
+	}
+	if !cfg.Has("aliases." + name) {
+		return validationError(fmt.Errorf("unknown alias '%s'", name))
+	}
+	if err := cfg.Delete("aliases." + name); err != nil {
+		return fmt.Errorf("unable to remove alias: %w", err)
+	}
+
+	fmt.Println(style.Success(fmt.Sprintf("Removed alias '%s'", name)))
+	return nil
+}
+
+// MARK: Helpers
+
+// expandArgs rewrites command line arguments before they're parsed: an alias
+// in place of the subcommand is replaced with its command, unless it's also
+// an existing path (the directory argument), and when there is no
+// subcommand, cli.default_command is inserted (push being the root command's
+// own). cfg may be nil if the config can't be loaded, in which case args are
+// left as is.
+func expandArgs(root *cobra.Command, cfg *config.Config, args []string) ([]string, error) {
+	if cfg == nil {
+		return args, nil
+	}
+	i := commandIndex(root, args)
+	if i >= 0 && isCommand(root, args[i]) {
+		return args, nil
+	}
+
+	if i >= 0 && cfg.Has("aliases."+args[i]) && isPath(args[i]) {
+		fmt.Fprintln(os.Stderr, style.Warning(fmt.Sprintf("'%s' is both an alias and a path, bundling the path (rename the alias to use it)", args[i])))
+	} else if i >= 0 && cfg.Has("aliases."+args[i]) {
+		expansion, err := splitArgs(cfg.Get("aliases." + args[i]))
+		if err != nil {
+			return nil, validationError(fmt.Errorf("invalid alias '%s': %w", args[i], err))
+		}
+		args = slices.Concat(args[:i], expansion, args[i+1:])
+		if i = commandIndex(root, args); i >= 0 && isCommand(root, args[i]) {
+			return args, nil
+		}
+	}
+
+	command := cfg.Get("cli.default_command")
+	if command == "" || command == "push" || slices.ContainsFunc(args, func(arg string) bool {
+		return arg == "-h" || arg == "--help" || arg == "--version"
+	}) {
+		return args, nil
+	}
+	return append([]string{command}, args...), nil
+}
+
+// commandIndex returns the index of the first positional argument (a
+// subcommand, an alias or the directory), skipping the root command's flags
+// and their values, or -1 if there is none.
+func commandIndex(root *cobra.Command, args []string) int {
+	lookup := func(name string, short bool) *pflag.Flag {
+		for _, flags := range []*pflag.FlagSet{root.PersistentFlags(), root.Flags()} {
+			if short {
+				if f := flags.ShorthandLookup(name); f != nil {
+					return f
+				}
+			} else if f := flags.Lookup(name); f != nil {
+				return f
+			}
+		}
+		return nil
+	}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			return -1
+		case strings.HasPrefix(arg, "--"):
+			name, _, hasValue := strings.Cut(arg[2:], "=")
+			if f := lookup(name, false); f != nil && f.NoOptDefVal == "" && !hasValue {
+				i++ // The value is the next argument
+			}
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			// Shorthands, possibly combined (-yk) or with their value (-oout.txt)
+			for j, c := range arg[1:] {
+				if f := lookup(string(c), true); f == nil || f.NoOptDefVal == "" {
+					if f != nil && j == len(arg)-2 {
+						i++
+					}
+					break
+				}
+			}
+		default:
+			return i
+		}
+	}
+	return -1
+}
+
+// isPath reports whether an argument names an existing file or directory.
+func isPath(arg string) bool {
+	_, err := os.Stat(arg)
+	return err == nil
+}
+
+// isCommand reports whether name is a subcommand of root, including those
+// cobra only adds when executing (help, completion).
+func isCommand(root *cobra.Command, name string) bool {
+	switch name {
+	case "help", "completion", cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd:
+		return true
+	}
+	return slices.ContainsFunc(root.Commands(), func(c *cobra.Command) bool {
+		return c.Name() == name || c.HasAlias(name)
+	})
+}
@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/holonoms/sandworm/internal/claude"
+	"github.com/holonoms/sandworm/internal/style"
+	"github.com/spf13/cobra"
+)
+
+// conversationNameLength is the length past which questions are truncated to
+// name their conversation.
+const conversationNameLength = 60
+
+// newAskCmd creates the ask command
+func newAskCmd(opts *Options) *cobra.Command {
+	var push, chat bool
+
+	cmd := &cobra.Command{
+		Use:   "ask [question]",
+		Short: "Ask Claude a question about the project",
+		Long: `Start a new conversation in the Claude project, which has the pushed
+documents attached, and print the reply as it streams in. With --chat, keep
+asking follow-up questions in the same conversation (an empty line or EOF
+ends it); without a question, it is asked for and --chat is implied.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			question := ""
+			if len(args) > 0 {
+				question = args[0]
+			} else {
+				chat = true
+			}
+			opts.SetDefaults()
+			return runAsk(opts, question, push, chat)
+		},
+	}
+
+	cmd.Flags().BoolVar(&push, "push", false, "Push the project before asking")
+	cmd.Flags().BoolVar(&chat, "chat", false, "Keep asking follow-up questions in the same conversation")
+
+	return cmd
+}
+
+func runAsk(opts *Options, question string, push, chat bool) error {
+	if err := checkWritable("ask"); err != nil {
+		return err
+	}
+	if push {
+		if err := runPush(opts, pushOptions{}); err != nil {
+			return err
+		}
+		fmt.Println()
+	}
+
+	if question == "" {
+		var err error
+		if question, err = readQuestion(); err != nil || question == "" {
+			return err
+		}
+	}
+
+	client, err := setupClaudeClient(false, opts)
+	if err != nil {
+		return err
+	}
+	conv, err := client.CreateConversation(conversationName(question))
+	if err != nil {
+		return fmt.Errorf("unable to start conversation: %w", err)
+	}
+
+	for {
+		if _, err := client.Ask(conv.ID, question, func(text string) { fmt.Print(text) }); err != nil {
+			fmt.Println()
+			return fmt.Errorf("unable to get a reply: %w", err)
+		}
+		fmt.Println()
+
+		if !chat {
+			break
+		}
+		fmt.Println()
+		if question, err = readQuestion(); err != nil || question == "" {
+			if err != nil {
+				return err
+			}
+			break
+		}
+	}
+
+	fmt.Println(style.Dim("Continue in the browser: " + claude.ConversationURL(conv.ID)))
+	return nil
+}
+
+// MARK: Helpers
+
+// readQuestion prompts for a question, returning an empty one at the end of
+// input or for a blank line.
+func readQuestion() (string, error) {
+	fmt.Print(style.Header("> "))
+	answer, err := readAnswer()
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("failed to read question: %w", err)
+	}
+	if err != nil && answer == "" {
+		fmt.Println()
+	}
+	return strings.TrimSpace(answer), nil
+}
+
+// conversationName names a conversation after its first question.
+func conversationName(question string) string {
+	name := strings.Join(strings.Fields(question), " ")
+	if runes := []rune(name); len(runes) > conversationNameLength {
+		name = strings.TrimSpace(string(runes[:conversationNameLength])) + "..."
+	}
+	return name
+}
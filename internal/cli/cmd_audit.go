@@ -0,0 +1,132 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/holonoms/sandworm/internal/audit"
+	"github.com/holonoms/sandworm/internal/claude"
+	"github.com/holonoms/sandworm/internal/config"
+	"github.com/holonoms/sandworm/internal/style"
+	"github.com/holonoms/sandworm/internal/util"
+	"github.com/spf13/cobra"
+)
+
+// newAuditCmd creates the audit command
+func newAuditCmd() *cobra.Command {
+	var limit int
+	var asJSON bool
+
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Show the log of pushes and purges",
+		Long: `Show the local audit log of every push, purge and deploy: when, by whom,
+which Claude project and the SHA-256 and size of the uploaded content. The log
+is append-only and kept next to the global config.`,
+		Args: cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return runAudit(limit, asJSON)
+		},
+	}
+
+	cmd.Flags().IntVarP(&limit, "limit", "l", 20, "Show the last N entries (0 for all)")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print entries as JSON lines")
+
+	return cmd
+}
+
+func runAudit(limit int, asJSON bool) error {
+	cfg, err := config.New("")
+	if err != nil {
+		return fmt.Errorf("unable to load config: %w", err)
+	}
+
+	entries, err := audit.Read(audit.Path(cfg.Dir()))
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		fmt.Println("No pushes or purges recorded yet.")
+		return ErrNothingToDo
+	}
+	if limit > 0 && len(entries) > limit {
+		entries = entries[len(entries)-limit:]
+	}
+
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		for _, e := range entries {
+			if err := enc.Encode(e); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for _, e := range entries {
+		project := e.Project
+		if e.ProjectName != "" {
+			project = e.ProjectName
+		}
+		user := e.User
+		if e.Account != "" {
+			user += " (" + e.Account + ")"
+		}
+		fmt.Printf("%s %-5s %s -> %s\n", style.Dim(e.Time.Local().Format("2006-01-02 15:04:05")), e.Action, user, style.Info(project))
+
+		var details []string
+		if e.Deploy != nil {
+			details = append(details, "version "+e.Deploy.Version)
+		}
+		if e.SHA256 != "" {
+			details = append(details, fmt.Sprintf("sha256 %s, %s", e.SHA256[:12], util.FormatSize(e.Size)))
+		}
+		if len(e.Documents) > 0 {
+			details = append(details, strings.Join(e.Documents, ", "))
+		}
+		details = append(details, e.Directory)
+		fmt.Println(style.Dim("    " + strings.Join(details, " | ")))
+	}
+
+	return nil
+}
+
+// MARK: Helpers
+
+// recordAudit appends a push or purge to the audit log. Failures are only
+// reported: the operation itself already happened.
+func recordAudit(client *claude.Client, action, directory string, documents []string, file string) {
+	appendAudit(client, audit.Entry{Action: action, Directory: directory, Documents: documents}, file)
+}
+
+// appendAudit completes an entry with the account and target of the client
+// (and the hash of file, if set) and appends it to the audit log, only
+// reporting failures.
+func appendAudit(client *claude.Client, entry audit.Entry, file string) {
+	orgID, projectID := client.TargetIDs()
+	_, projectName := client.TargetNames()
+	entry.Directory, _ = filepath.Abs(entry.Directory)
+	entry.Account = client.Account()
+	entry.Organization, entry.Project, entry.ProjectName = orgID, projectID, projectName
+
+	err := func() error {
+		if file != "" {
+			hash, size, err := audit.HashFile(file)
+			if err != nil {
+				return err
+			}
+			entry.SHA256, entry.Size = hash, size
+		}
+		cfg, err := config.New("")
+		if err != nil {
+			return err
+		}
+		return audit.Append(audit.Path(cfg.Dir()), entry)
+	}()
+	if err != nil {
+		fmt.Println(style.Warning(fmt.Sprintf("Unable to record %s in the audit log: %v", entry.Action, err)))
+	}
+}
@@ -0,0 +1,221 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/holonoms/sandworm/internal/bench"
+	"github.com/holonoms/sandworm/internal/claude"
+	"github.com/holonoms/sandworm/internal/config"
+	"github.com/holonoms/sandworm/internal/processor"
+	"github.com/holonoms/sandworm/internal/profile"
+	"github.com/holonoms/sandworm/internal/style"
+	"github.com/holonoms/sandworm/internal/util"
+	"github.com/spf13/cobra"
+)
+
+// newBenchCmd creates the (hidden) bench command
+func newBenchCmd(opts *Options) *cobra.Command {
+	shape := bench.DefaultShape
+	var iterations int
+	var dir string
+	tuning := processor.SandwormOptions{
+		WalkConcurrency: processor.DefaultWalkConcurrency,
+		StatBatch:       processor.DefaultStatBatch,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "bench",
+		Short: "Measure walk, process and upload throughput on a synthetic project",
+		Long: `Generate a synthetic project of the given size and shape, then measure how
+fast sandworm walks it, processes it (from scratch and incrementally) and
+uploads the bundle to an in-process stand-in for the API. Medians of several
+iterations are reported, as baselines to compare performance changes against.
+
+Projects are generated from a seed, so the same flags always measure the same
+project. Combine with --profile to see where the time goes, and compare
+--walk-concurrency and --stat-batch values to tune them for a filesystem
+(e.g. with --dir on a network share).`,
+		Hidden: true,
+		Args:   cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			opts.SetDefaults()
+			if err := shape.Validate(); err != nil {
+				return validationError(err)
+			}
+			if iterations < 1 {
+				return validationError(fmt.Errorf("invalid iteration count %d (must be at least 1)", iterations))
+			}
+			timings, stop, err := startProfiling(opts)
+			if err != nil {
+				return err
+			}
+			defer stop()
+			return runBench(timings, shape, iterations, dir, tuning)
+		},
+	}
+
+	cmd.Flags().IntVar(&shape.Files, "files", shape.Files, "Number of source files")
+	cmd.Flags().IntVar(&shape.Depth, "depth", shape.Depth, "Levels of nested directories")
+	cmd.Flags().IntVar(&shape.FanOut, "fan-out", shape.FanOut, "Subdirectories per directory")
+	cmd.Flags().IntVar(&shape.FileSize, "file-size", shape.FileSize, "Average file size, in bytes")
+	cmd.Flags().IntVar(&shape.Ignored, "ignored", shape.Ignored, "Number of files sandworm must skip")
+	cmd.Flags().Int64Var(&shape.Seed, "seed", shape.Seed, "Seed of the generated contents")
+	cmd.Flags().IntVar(&iterations, "iterations", 5, "Number of measured runs of each stage")
+	cmd.Flags().IntVar(&tuning.WalkConcurrency, "walk-concurrency", tuning.WalkConcurrency, "Directories read at once while walking")
+	cmd.Flags().IntVar(&tuning.StatBatch, "stat-batch", tuning.StatBatch, "Files stat'ed at once")
+	cmd.Flags().StringVar(&dir, "dir", "", "Generate the project in this directory and keep it (default: a temporary directory)")
+
+	return cmd
+}
+
+func runBench(timings *profile.Timings, shape bench.Shape, iterations int, dir string, tuning processor.SandwormOptions) error {
+	if dir == "" {
+		tmp, err := os.MkdirTemp("", "sandworm-bench-*")
+		if err != nil {
+			return fmt.Errorf("unable to create temporary directory: %w", err)
+		}
+		defer func() { _ = os.RemoveAll(tmp) }()
+		dir = tmp
+	}
+	work, err := os.MkdirTemp("", "sandworm-bench-out-*")
+	if err != nil {
+		return fmt.Errorf("unable to create temporary directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(work) }()
+
+	start := time.Now()
+	project, err := bench.Generate(filepath.Join(dir, "project"), shape)
+	if err != nil {
+		return fmt.Errorf("unable to generate project: %w", err)
+	}
+	addTiming(timings, "generate", start)
+	fmt.Printf("%s %d files (%s) in %s, %d directory levels\n", style.Header("Project:"),
+		project.Files, util.FormatSize(project.Bytes), project.Dir, shape.Depth)
+
+	walk := bench.Result{Stage: "walk", Files: project.Files}
+	read := bench.Result{Stage: "read", Files: project.Files, Bytes: project.Bytes}
+	write := bench.Result{Stage: "write", Files: project.Files}
+	process := bench.Result{Stage: "process", Files: project.Files, Bytes: project.Bytes}
+	incremental := bench.Result{Stage: "regen", Files: project.Files, Bytes: project.Bytes}
+	upload := bench.Result{Stage: "upload"}
+
+	outputFile := filepath.Join(work, "bundle.txt")
+	for range iterations {
+		p, err := benchProcessor(project.Dir, outputFile, "", tuning)
+		if err != nil {
+			return err
+		}
+		start := time.Now()
+		if _, err := p.Process(); err != nil {
+			return fmt.Errorf("unable to process files: %w", err)
+		}
+		process.Durations = append(process.Durations, time.Since(start))
+		phases := p.Timings()
+		walk.Durations = append(walk.Durations, phases.Walk)
+		read.Durations = append(read.Durations, phases.Read)
+		write.Durations = append(write.Durations, phases.Write)
+		addTiming(timings, "process", start)
+	}
+
+	// Regenerating an unchanged project, from the incremental cache
+	cacheDir := filepath.Join(work, "incremental")
+	for i := range iterations + 1 {
+		p, err := benchProcessor(project.Dir, outputFile, cacheDir, tuning)
+		if err != nil {
+			return err
+		}
+		start := time.Now()
+		if _, err := p.Process(); err != nil {
+			return fmt.Errorf("unable to process files: %w", err)
+		}
+		if i > 0 { // The first run fills the cache
+			incremental.Durations = append(incremental.Durations, time.Since(start))
+		}
+		addTiming(timings, "regen", start)
+	}
+
+	info, err := os.Stat(outputFile)
+	if err != nil {
+		return fmt.Errorf("unable to read bundle: %w", err)
+	}
+	upload.Bytes = info.Size()
+	start = time.Now()
+	if upload.Durations, err = benchUpload(outputFile, work, iterations); err != nil {
+		return err
+	}
+	addTiming(timings, "upload", start)
+
+	fmt.Printf("%s median of %d runs\n", style.Header("Results:"), iterations)
+	for _, result := range []bench.Result{walk, read, write, process, incremental, upload} {
+		fmt.Println(result.String())
+	}
+	return nil
+}
+
+// benchProcessor creates a processor with the default options and the walk
+// tuning being measured, caching its output in cacheDir if set.
+func benchProcessor(root, outputFile, cacheDir string, tuning processor.SandwormOptions) (*processor.Processor, error) {
+	p, err := processor.NewWithOptions(root, outputFile, "", processor.SandwormOptions{
+		Linguist:        true,
+		Submodules:      processor.SubmodulesFull,
+		Order:           processor.OrderPath,
+		DefaultIgnores:  "all",
+		MaxDepth:        processor.DefaultMaxDepth,
+		MaxFiles:        processor.DefaultMaxFiles,
+		IncrementalDir:  cacheDir,
+		WalkConcurrency: tuning.WalkConcurrency,
+		StatBatch:       tuning.StatBatch,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to create processor: %w", err)
+	}
+	return p, nil
+}
+
+// benchUpload times pushes of a bundle to an in-process API server, with a
+// throwaway config so that the user's is never touched.
+func benchUpload(bundle, work string, iterations int) ([]time.Duration, error) {
+	server := bench.NewServer()
+	defer server.Close()
+
+	previous, set := os.LookupEnv(config.DirEnv)
+	if err := os.Setenv(config.DirEnv, filepath.Join(work, "config")); err != nil {
+		return nil, fmt.Errorf("unable to isolate config: %w", err)
+	}
+	defer func() {
+		if set {
+			_ = os.Setenv(config.DirEnv, previous)
+		} else {
+			_ = os.Unsetenv(config.DirEnv)
+		}
+	}()
+
+	cfg, err := config.New(work)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load config: %w", err)
+	}
+	for key, value := range map[string]string{
+		"claude.api_url":         server.URL,
+		"claude.session_key":     "sk-bench",
+		"claude.organization_id": "bench-org",
+		"claude.project_id":      "bench-project",
+	} {
+		if err := cfg.Set(key, value); err != nil {
+			return nil, fmt.Errorf("unable to configure client: %w", err)
+		}
+	}
+
+	client := claude.New(cfg)
+	var durations []time.Duration
+	for range iterations {
+		start := time.Now()
+		if err := client.Push(bundle, "project.txt"); err != nil {
+			return nil, fmt.Errorf("unable to upload bundle: %w", err)
+		}
+		durations = append(durations, time.Since(start))
+	}
+	return durations, nil
+}
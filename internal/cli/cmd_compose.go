@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+
+	"github.com/holonoms/sandworm/internal/config"
+	"github.com/holonoms/sandworm/internal/processor"
+	"github.com/holonoms/sandworm/internal/style"
+	"github.com/holonoms/sandworm/internal/tokens"
+	"github.com/holonoms/sandworm/internal/util"
+	"github.com/spf13/cobra"
+)
+
+// newComposeCmd creates the compose command
+func newComposeCmd(opts *Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "compose <file>...",
+		Short: "Compose a bundle from previously generated files",
+		Long: `Compose a bundle from files generated by sandworm, as if generated together:
+a single project structure listing the files of all of them, merged symbol
+indexes, dependency graphs and checksum manifests, then the contents of each
+file in order. Files found in several of them are kept from the first.
+
+This lets expensive parts (e.g. vendored docs) be generated once and reused:
+
+  sandworm generate vendor/docs -o docs.txt
+  sandworm compose project.txt docs.txt -o all.txt
+
+'sandworm generate --append docs.txt' does the same for a fresh bundle.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			opts.SetDefaults()
+			outputFile := cmp.Or(opts.OutputFile, defaultOutputFile)
+			composition, err := composeBundles(opts, outputFile, args)
+			if err != nil {
+				return err
+			}
+			tokenCount := estimateTokens(opts.Directory, outputFile)
+			fmt.Println(style.Success(fmt.Sprintf("Composed '%s' from %d files (%d files, %s, %s)",
+				outputFile, len(args), composition.Files, util.FormatSize(int64(len(composition.Content))), tokens.Format(tokenCount))))
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// composeBundles composes outputFile from generated files (see
+// processor.Compose), reporting the files found in several of them.
+func composeBundles(opts *Options, outputFile string, inputs []string) (*processor.Composition, error) {
+	cfg, err := config.New(opts.Directory)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load config: %w", err)
+	}
+	fileHeader, err := resolveFileHeader(opts.HeaderStyle, cfg)
+	if err != nil {
+		return nil, validationError(err)
+	}
+
+	bundles := make([][]byte, len(inputs))
+	for i, input := range inputs {
+		if bundles[i], err = os.ReadFile(input); err != nil {
+			return nil, fmt.Errorf("unable to read %s: %w", input, err)
+		}
+	}
+	composition, err := processor.Compose(bundles, fileHeader)
+	if err != nil {
+		return nil, validationError(fmt.Errorf("unable to compose bundles: %w", err))
+	}
+	if err := os.WriteFile(outputFile, composition.Content, 0o644); err != nil {
+		return nil, fmt.Errorf("unable to write %s: %w", outputFile, err)
+	}
+
+	if len(composition.Duplicates) > 0 {
+		fmt.Println(style.Warning(fmt.Sprintf("Skipped %d files found in several bundles (kept from the first):", len(composition.Duplicates))))
+		for _, path := range composition.Duplicates {
+			fmt.Printf("  %s\n", path)
+		}
+	}
+	return composition, nil
+}
@@ -1,9 +1,32 @@
 package cli
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/BurntSushi/toml"
+	"github.com/holonoms/sandworm/internal/claude"
 	"github.com/holonoms/sandworm/internal/config"
+	"github.com/holonoms/sandworm/internal/convert"
+	"github.com/holonoms/sandworm/internal/cron"
+	"github.com/holonoms/sandworm/internal/processor"
+	"github.com/holonoms/sandworm/internal/sanitize"
+	"github.com/holonoms/sandworm/internal/scrub"
+	"github.com/holonoms/sandworm/internal/source"
+	"github.com/holonoms/sandworm/internal/style"
+	"github.com/holonoms/sandworm/internal/tokens"
+	"github.com/holonoms/sandworm/internal/util"
 	"github.com/spf13/cobra"
 )
 
@@ -33,6 +56,37 @@ var configOptions = []ConfigOption{
 		Description: "The document ID to use for the Claude API",
 		Default:     "",
 	},
+	{
+		Key:         "claude.account",
+		Description: "The account (see 'sandworm accounts') to use for this project",
+		Default:     "",
+	},
+	{
+		Key:         "claude.confirm",
+		Description: "Ask for confirmation before replacing or deleting remote documents",
+		Default:     "true",
+		ValidValues: []string{"true", "false"},
+		Validator:   validateBoolOption,
+	},
+	{
+		Key:         "claude.backup",
+		Description: "Back up the project's documents locally before a push replaces or deletes them",
+		Default:     "false",
+		ValidValues: []string{"true", "false"},
+		Validator:   validateBoolOption,
+	},
+	{
+		Key:         "claude.api_url",
+		Description: "Base URL of the Claude API (global; for when it moves)",
+		Default:     "https://claude.ai/api",
+		Validator:   claude.ValidateAPIURL,
+	},
+	{
+		Key:         "claude.endpoints",
+		Description: "Comma-separated name=path API endpoint overrides, e.g. docs=/organizations/{org}/projects/{project}/files (global)",
+		Default:     "",
+		Validator:   claude.ValidateEndpoints,
+	},
 	{
 		Key:         "processor.print_line_numbers",
 		Description: "Print line numbers in the output",
@@ -40,6 +94,332 @@ var configOptions = []ConfigOption{
 		ValidValues: []string{"true", "false"},
 		Validator:   validateBoolOption,
 	},
+	{
+		Key:         "processor.follow_symlinks",
+		Description: "Follow symbolic links when traversing directories",
+		Default:     "false",
+		ValidValues: []string{"true", "false"},
+		Validator:   validateBoolOption,
+	},
+	{
+		Key:         "processor.dependency_graph",
+		Description: "Add a DEPENDENCY GRAPH section summarizing imports between project files (Go, TS/JS)",
+		Default:     "false",
+		ValidValues: []string{"true", "false"},
+		Validator:   validateBoolOption,
+	},
+	{
+		Key:         "processor.symbol_index",
+		Description: "Add a SYMBOL INDEX section listing the exported functions/types of each file (Go, TS/JS, Python)",
+		Default:     "false",
+		ValidValues: []string{"true", "false"},
+		Validator:   validateBoolOption,
+	},
+	{
+		Key:         "processor.file_metadata",
+		Description: "Add size, line count, modification time and last git commit to file headers",
+		Default:     "false",
+		ValidValues: []string{"true", "false"},
+		Validator:   validateBoolOption,
+	},
+	{
+		Key:         "processor.checksum_manifest",
+		Description: "Add a section with the SHA-256 of each file, to verify bundles and diff them",
+		Default:     "false",
+		ValidValues: []string{"true", "false"},
+		Validator:   validateBoolOption,
+	},
+	{
+		Key:         "processor.converters",
+		Description: "Render matching files as text with the built-in and external converters (see 'sandworm converters')",
+		Default:     "true",
+		ValidValues: []string{"true", "false"},
+		Validator:   validateBoolOption,
+	},
+	{
+		Key:         "processor.csv_sample_rows",
+		Description: "Data rows kept from CSV/TSV files (with a note of the total), 0 to keep them all",
+		Default:     "20",
+		Validator:   validateCountOption,
+	},
+	{
+		Key:         "processor.incremental",
+		Description: "Reuse the sections of unchanged files from the last bundle (cached in plaintext in the global config directory, except for encrypted bundles) rather than processing them again",
+		Default:     "false",
+		ValidValues: []string{"true", "false"},
+		Validator:   validateBoolOption,
+	},
+	{
+		Key:         "processor.fixture_max_lines",
+		Description: "Test fixtures/golden files (testdata/, __snapshots__/, *.golden...) longer than this keep only their first lines, 0 to include them whole",
+		Default:     "2000",
+		Validator:   validateCountOption,
+	},
+	{
+		Key:         "processor.image_placeholders",
+		Description: "Describe images in one line (e.g. '[logo.png: 512x512 PNG, 14.2 KB]') instead of skipping them",
+		Default:     "false",
+		ValidValues: []string{"true", "false"},
+		Validator:   validateBoolOption,
+	},
+	{
+		Key:         "processor.minified",
+		Description: "How to handle minified JS/CSS: keep, sources (the original sources from their source map, if any) or summary (like sources, with a placeholder when there's no map)",
+		Default:     convert.MinifiedSources,
+		ValidValues: convert.MinifiedModes,
+		Validator:   validateEnumOption(convert.MinifiedModes),
+	},
+	{
+		Key:         "processor.api_specs",
+		Description: "How to handle OpenAPI/Swagger specs and GraphQL schemas: summary (a compact endpoint/type summary) or full",
+		Default:     convert.APISpecSummary,
+		ValidValues: convert.APISpecModes,
+		Validator:   validateEnumOption(convert.APISpecModes),
+	},
+	{
+		Key:         "processor.infra",
+		Description: "How to handle Terraform state/plan JSON and Kubernetes manifests: full or summary (resources, names and key fields)",
+		Default:     convert.InfraFull,
+		ValidValues: convert.InfraModes,
+		Validator:   validateEnumOption(convert.InfraModes),
+	},
+	{
+		Key:         "processor.databases",
+		Description: "Comma-separated name=DSN pairs of databases whose schema is added (sqlite:, postgres://, mysql://), e.g. 'app=$DATABASE_URL'. Global: DSNs of unset variables are skipped",
+		Default:     "",
+		Validator:   convert.ValidateDatabases,
+	},
+	{
+		Key:         "sources.max_age",
+		Description: "How long the URLs listed in " + source.SourcesFile + " are used before being fetched again (e.g. 12h, 7d; 0 to always fetch them)",
+		Default:     "24h",
+		Validator:   source.ValidateMaxAge,
+	},
+	{
+		Key:         "sources.allow_private",
+		Description: "Fetch the URLs listed in " + source.SourcesFile + " from private, loopback and link-local addresses too (global: projects can't enable it)",
+		Default:     "false",
+		ValidValues: []string{"true", "false"},
+		Validator:   validateBoolOption,
+	},
+	{
+		Key:         "processor.max_depth",
+		Description: "Fail when a non-ignored directory is nested deeper than this (0 for no limit)",
+		Default:     "25",
+		Validator:   validateCountOption,
+	},
+	{
+		Key:         "processor.max_files",
+		Description: "Fail when more files than this would be included (0 for no limit)",
+		Default:     "10000",
+		Validator:   validateCountOption,
+	},
+	{
+		Key:         "processor.read_timeout",
+		Description: "Skip files whose read stalls longer than this, e.g. on hung network mounts (e.g. 10s, 1m; 0 for no limit)",
+		Default:     "10s",
+		Validator:   validateDurationOption,
+	},
+	{
+		Key:         "processor.walk_concurrency",
+		Description: "Directories read at once while walking; raise it on network shares or WSL /mnt paths (1 for a serial walk)",
+		Default:     "8",
+		Validator:   validateCountOption,
+	},
+	{
+		Key:         "processor.stat_batch",
+		Description: "Files stat'ed at once (symbolic links, unchanged file checks, metadata); raise it on high-latency filesystems (1 for one at a time)",
+		Default:     "32",
+		Validator:   validateCountOption,
+	},
+	{
+		Key:         "processor.default_ignores",
+		Description: "Built-in ignore categories to apply, e.g. 'binaries,locks' or '-locks' (" + strings.Join(processor.IgnoreCategories, ", ") + ", all or none)",
+		Default:     "all",
+		Validator:   processor.ValidateIgnoreCategories,
+	},
+	{
+		Key:         "processor.linguist",
+		Description: "Exclude files marked linguist-generated or linguist-vendored in .gitattributes",
+		Default:     "true",
+		ValidValues: []string{"true", "false"},
+		Validator:   validateBoolOption,
+	},
+	{
+		Key:         "processor.submodules",
+		Description: "How to handle git submodules: full, tree (structure only) or skip",
+		Default:     processor.SubmodulesFull,
+		ValidValues: processor.SubmoduleModes,
+		Validator:   validateEnumOption(processor.SubmoduleModes),
+	},
+	{
+		Key:         "processor.order",
+		Description: "Order of the file contents: path, or related (clustered by directory and imports, tests and headers next to their sources)",
+		Default:     processor.OrderPath,
+		ValidValues: processor.OrderModes,
+		Validator:   validateEnumOption(processor.OrderModes),
+	},
+	{
+		Key:         "processor.header_style",
+		Description: "File header style: full (80-char separators), short or minimal (fewer tokens)",
+		Default:     processor.HeaderFull,
+		ValidValues: processor.HeaderStyles,
+		Validator:   validateEnumOption(processor.HeaderStyles),
+	},
+	{
+		Key:         "processor.file_header",
+		Description: "Custom file header template, e.g. '### {path}' ({meta} for metadata, \\n for newlines; overrides header_style)",
+		Default:     "",
+		Validator:   processor.ValidateHeaderTemplate,
+	},
+	{
+		Key:         "processor.license_exclude",
+		Description: "Exclude files whose first 30 lines match this case-insensitive regex, e.g. 'GNU (Affero )?General Public License'",
+		Default:     "",
+		Validator:   processor.ValidateLicensePattern,
+	},
+	{
+		Key:         "processor.normalize_eol",
+		Description: "Convert CRLF line endings to LF in file contents, so bundles hash the same on every OS",
+		Default:     "false",
+		ValidValues: []string{"true", "false"},
+		Validator:   validateBoolOption,
+	},
+	{
+		Key:         "processor.trim_whitespace",
+		Description: "Trim trailing whitespace from each line of file contents",
+		Default:     "false",
+		ValidValues: []string{"true", "false"},
+		Validator:   validateBoolOption,
+	},
+	{
+		Key:         "processor.sanitize",
+		Description: "Handle ANSI escape sequences, control, zero-width and bidi characters in file contents: off, strip or escape",
+		Default:     sanitize.Off,
+		ValidValues: sanitize.Modes,
+		Validator:   validateEnumOption(sanitize.Modes),
+	},
+	{
+		Key:         "processor.injection_scan",
+		Description: "Flag instruction-like content that could hijack the model (prompt injection), asking before push",
+		Default:     "false",
+		ValidValues: []string{"true", "false"},
+		Validator:   validateBoolOption,
+	},
+	{
+		Key:         "processor.injection_scan_paths",
+		Description: "Comma-separated gitignore-style patterns limiting the prompt-injection scan to some files, e.g. 'vendor/,third_party/' (default: all files)",
+		Default:     "",
+		Validator:   validatePatternsOption,
+	},
+	{
+		Key:         "processor.scrub_pii",
+		Description: "Mask emails, phone numbers and scrub_pattern matches in file contents",
+		Default:     "false",
+		ValidValues: []string{"true", "false"},
+		Validator:   validateBoolOption,
+	},
+	{
+		Key:         "processor.scrub_secrets",
+		Description: "Mask credentials (private keys, AWS, GitHub, Slack, Google, Stripe and sk- API keys) in all file contents",
+		Default:     "false",
+		ValidValues: []string{"true", "false"},
+		Validator:   validateBoolOption,
+	},
+	{
+		Key:         "processor.scrub_pattern",
+		Description: "Regular expression of extra values to mask when scrubbing PII, e.g. 'Jane Doe|John Smith'",
+		Default:     "",
+		Validator:   scrub.ValidatePattern,
+	},
+	{
+		Key:         "processor.scrub_paths",
+		Description: "Comma-separated gitignore-style patterns limiting PII scrubbing to some files, e.g. 'testdata/,*.csv' (default: all files)",
+		Default:     "",
+		Validator:   validatePatternsOption,
+	},
+	{
+		Key:         "watch.schedule",
+		Description: "Cron expression (e.g. '0 * * * *') to push on a schedule instead of on every change",
+		Default:     "",
+		Validator:   validateCronOption,
+	},
+	{
+		Key:         "watch.generate",
+		Description: "Regenerate the output file (sandworm.txt) on change in watch mode instead of pushing, for using sandworm as a context generator for other tools",
+		Default:     "false",
+		ValidValues: []string{"true", "false"},
+		Validator:   validateBoolOption,
+	},
+	{
+		Key:         "watch.debounce",
+		Description: "Wait until files stopped changing for this long before pushing in watch mode, e.g. 30s (default: the polling interval)",
+		Default:     "",
+		Validator:   validateDurationOption,
+	},
+	{
+		Key:         "watch.ignore_changes",
+		Description: "Comma-separated gitignore-style patterns of files whose changes don't trigger pushes in watch mode, e.g. 'dist/,*.generated.go' (they're still bundled)",
+		Default:     "",
+		Validator:   validatePatternsOption,
+	},
+	{
+		Key:         "watch.max_pushes_per_hour",
+		Description: "Push at most this many times per hour in watch mode, holding back later changes (0 for no limit)",
+		Default:     "0",
+		Validator:   validateCountOption,
+	},
+	{
+		Key:         "watch.notify",
+		Description: "Show a desktop notification after each push in watch mode",
+		Default:     "true",
+		ValidValues: []string{"true", "false"},
+		Validator:   validateBoolOption,
+	},
+	{
+		Key:         "budget.size_limit",
+		Description: "Bundle size budget, e.g. 10MB: push warns when nearing or exceeding it (default: none)",
+		Default:     "",
+		Validator:   validateSizeOption,
+	},
+	{
+		Key:         "budget.token_limit",
+		Description: "Bundle token budget (see tokens.estimator): push warns when nearing or exceeding it (0 for none)",
+		Default:     "0",
+		Validator:   validateCountOption,
+	},
+	{
+		Key:         "budget.warn_percent",
+		Description: "Warn when the bundle exceeds this percentage of budget.size_limit or budget.token_limit",
+		Default:     "80",
+		Validator:   validateCountOption,
+	},
+	{
+		Key:         "budget.growth_percent",
+		Description: "Warn when the bundle grew by more than this percentage since the last push (0 to disable)",
+		Default:     "20",
+		Validator:   validateCountOption,
+	},
+	{
+		Key:         "tokens.chunk_size",
+		Description: "Split bundles into balanced parts of at most this many tokens, at file boundaries, pushed as project-1.txt, project-2.txt... (0 for a single document)",
+		Default:     "0",
+		Validator:   validateCountOption,
+	},
+	{
+		Key:         "tokens.estimator",
+		Description: "How bundle tokens are estimated: fast (characters / 4), words (a local heuristic weighing words, numbers and punctuation) or api (Anthropic's token counting endpoint, requires " + tokens.APIKeyEnv + ")",
+		Default:     tokens.Fast,
+		ValidValues: tokens.Estimators,
+		Validator:   validateEnumOption(tokens.Estimators),
+	},
+	{
+		Key:         "cli.default_command",
+		Description: "Command run by 'sandworm [directory]', without a subcommand: push, generate or watch (global: projects can't change it)",
+		Default:     "push",
+		ValidValues: defaultCommands,
+		Validator:   validateEnumOption(defaultCommands),
+	},
 }
 
 // MARK: Sub-commands
@@ -57,41 +437,59 @@ func newConfigCmd() *cobra.Command {
 		newConfigGetCmd(),
 		newConfigSetCmd(),
 		newConfigUnsetCmd(),
+		newConfigUndoCmd(),
+		newConfigExportCmd(),
+		newConfigImportCmd(),
+		newConfigCheckCmd(),
+		newConfigEncryptCmd(),
+		newConfigDecryptCmd(),
 	)
 
 	return cmd
 }
 
 func newConfigListCmd() *cobra.Command {
+	var section string
 	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List all configuration values",
 		Args:  cobra.NoArgs,
 		RunE: func(_ *cobra.Command, _ []string) error {
-			return runConfigList()
+			return runConfigList(section)
 		},
 	}
 
+	cmd.Flags().StringVarP(&section, "section", "s", "", "Only list options in this section (e.g. claude, processor)")
+
 	return cmd
 }
 
-func runConfigList() error {
+func runConfigList(section string) error {
 	cfg, err := config.New(".")
 	if err != nil {
 		return fmt.Errorf("unable to load config: %w", err)
 	}
 
+	options := configOptions
+	if section != "" {
+		options = matchConfigOptions(section)
+		if len(options) == 0 {
+			return validationError(fmt.Errorf("unknown configuration section: %s", section))
+		}
+	}
+
 	fmt.Println("Available configuration options:")
 	fmt.Println()
 
-	for _, option := range configOptions {
-		fmt.Printf("  %s\n", option.Key)
+	for _, option := range options {
+		fmt.Printf("  %s\n", style.Header(option.Key))
 		fmt.Printf("    Description: %s\n", option.Description)
 		fmt.Printf("    Default: %s\n", option.Default)
 
 		if cfg.Has(option.Key) {
 			value := cfg.Get(option.Key)
 			fmt.Printf("    Current: %s\n", value)
+			fmt.Printf("    Source: %s\n", cfg.Source(option.Key))
 		} else {
 			fmt.Printf("    Current: %s (default)\n", option.Default)
 		}
@@ -102,12 +500,15 @@ func runConfigList() error {
 }
 
 func newConfigSetCmd() *cobra.Command {
+	var validate bool
 	cmd := &cobra.Command{
 		Use:   "set <key> <value>",
 		Short: "Set a configuration value",
-		Args:  cobra.ExactArgs(2),
+		Example: `  sandworm config set processor.follow_symlinks true
+  sandworm config set --validate claude.project_id "Backend"`,
+		Args: cobra.ExactArgs(2),
 		RunE: func(_ *cobra.Command, args []string) error {
-			return runConfigSet(args[0], args[1])
+			return runConfigSet(args[0], args[1], validate)
 		},
 		ValidArgsFunction: func(
 			_ *cobra.Command,
@@ -128,20 +529,25 @@ func newConfigSetCmd() *cobra.Command {
 		},
 	}
 
+	cmd.Flags().BoolVar(&validate, "validate", false, "For claude.organization_id and claude.project_id, check that the account can access it and show its name first (names are saved as IDs)")
+
 	return cmd
 }
 
-func runConfigSet(key, value string) error {
+func runConfigSet(key, value string, validate bool) error {
 	// Find the configuration option
 	option := findConfigOption(key)
 	if option == nil {
-		return fmt.Errorf("unknown configuration option: %s\n\nRun 'sandworm config list' to see available options", key)
+		if closest := util.Closest(key, configOptionsKeys()); closest != "" {
+			return validationError(fmt.Errorf("unknown configuration option: %s (did you mean %s?)\n\nRun 'sandworm config list' to see available options", key, closest))
+		}
+		return validationError(fmt.Errorf("unknown configuration option: %s\n\nRun 'sandworm config list' to see available options", key))
 	}
 
 	// Validate the value
 	if option.Validator != nil {
 		if err := option.Validator(value); err != nil {
-			return fmt.Errorf("invalid value for %s: %w", key, err)
+			return validationError(fmt.Errorf("invalid value for %s: %w", key, err))
 		}
 	}
 
@@ -150,7 +556,13 @@ func runConfigSet(key, value string) error {
 		return fmt.Errorf("unable to load config: %w", err)
 	}
 
-	if err := cfg.Set(key, value); err != nil {
+	if validate {
+		if value, err = resolveTargetOption(cfg, key, value); err != nil {
+			return err
+		}
+	}
+
+	if err := cfg.SetUndoable(key, value); err != nil {
 		return fmt.Errorf("unable to set config: %w", err)
 	}
 
@@ -158,10 +570,39 @@ func runConfigSet(key, value string) error {
 	return nil
 }
 
+// resolveTargetOption checks, against the API, a value of
+// claude.organization_id or claude.project_id (a project of the configured
+// organization): it must be the ID or name of an organization or project the
+// account can access. It prints its name, and returns its ID. Other options
+// are returned as is.
+func resolveTargetOption(cfg *config.Config, key, value string) (string, error) {
+	client := claude.New(cfg)
+	client.SetRefresh(true) // Access may have changed since listings were cached
+	var id, name string
+	var err error
+	switch key {
+	case "claude.organization_id":
+		id, name, err = client.LookupOrganization(value)
+	case "claude.project_id":
+		id, name, err = client.LookupProject(value)
+	default:
+		return value, nil
+	}
+	if errors.Is(err, claude.ErrNotFound) {
+		return "", validationError(fmt.Errorf("%w (or this account can't access it)", err))
+	}
+	if err != nil {
+		return "", fmt.Errorf("unable to validate %s: %w", key, err)
+	}
+
+	fmt.Printf("%s %s %s\n", style.Success("Found"), style.Info(name), style.Dim("("+id+")"))
+	return id, nil
+}
+
 func newConfigGetCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "get <key>",
-		Short: "Get a configuration value",
+		Short: "Get a configuration value (supports wildcards, e.g. 'claude.*')",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(_ *cobra.Command, args []string) error {
 			return runConfigGet(args[0])
@@ -181,11 +622,11 @@ func newConfigGetCmd() *cobra.Command {
 	return cmd
 }
 
-func runConfigGet(key string) error {
-	// Validate that the key is a known option
-	option := findConfigOption(key)
-	if option == nil {
-		return fmt.Errorf("unknown configuration option: %s\n\nRun 'sandworm config list' to see available options", key)
+func runConfigGet(pattern string) error {
+	// Validate that the pattern matches at least one known option
+	options := matchConfigOptions(pattern)
+	if len(options) == 0 {
+		return validationError(fmt.Errorf("unknown configuration option: %s\n\nRun 'sandworm config list' to see available options", pattern))
 	}
 
 	cfg, err := config.New(".")
@@ -193,13 +634,13 @@ func runConfigGet(key string) error {
 		return fmt.Errorf("unable to load config: %w", err)
 	}
 
-	if !cfg.Has(key) {
-		fmt.Printf("%s = %s (default)\n", key, option.Default)
-		return nil
+	for _, option := range options {
+		if !cfg.Has(option.Key) {
+			fmt.Printf("%s = %s (default)\n", option.Key, option.Default)
+			continue
+		}
+		fmt.Printf("%s = %s (from %s)\n", option.Key, cfg.Get(option.Key), cfg.Source(option.Key))
 	}
-
-	value := cfg.Get(key)
-	fmt.Printf("%s = %s\n", key, value)
 	return nil
 }
 
@@ -232,7 +673,7 @@ func runConfigUnset(key string) error {
 		return fmt.Errorf("unable to load config: %w", err)
 	}
 
-	if err := cfg.Delete(key); err != nil {
+	if err := cfg.DeleteUndoable(key); err != nil {
 		return fmt.Errorf("unable to unset config: %w", err)
 	}
 
@@ -240,8 +681,448 @@ func runConfigUnset(key string) error {
 	return nil
 }
 
+func newConfigUndoCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "undo",
+		Short: "Undo the last 'config set' or 'config unset' (repeat to go further back)",
+		Long: `Undo the last 'config set' or 'config unset' of a project or global option,
+restoring its previous value. The last 20 changes are kept, across projects;
+changes to session keys aren't, so they can't be undone.`,
+		Args: cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return runConfigUndo()
+		},
+	}
+
+	return cmd
+}
+
+func runConfigUndo() error {
+	cfg, err := config.New(".")
+	if err != nil {
+		return fmt.Errorf("unable to load config: %w", err)
+	}
+
+	change, err := cfg.Undo()
+	if err != nil {
+		return fmt.Errorf("unable to undo config change: %w", err)
+	}
+	if change == nil {
+		fmt.Println("No config change to undo.")
+		return ErrNothingToDo
+	}
+
+	undone := "unset"
+	if change.Value != nil {
+		undone = "set to " + *change.Value
+	}
+	if change.Previous == nil {
+		fmt.Printf("Unset %s %s\n", change.Key, style.Dim(fmt.Sprintf("(undoing %s on %s)", undone, change.Time.Format(time.DateTime))))
+	} else {
+		fmt.Printf("Set %s = %s %s\n", change.Key, *change.Previous, style.Dim(fmt.Sprintf("(undoing %s on %s)", undone, change.Time.Format(time.DateTime))))
+	}
+	return nil
+}
+
+func newConfigExportCmd() *cobra.Command {
+	var (
+		format         string
+		outputFile     string
+		includeSecrets bool
+	)
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export configuration values (JSON or TOML)",
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return runConfigExport(format, outputFile, includeSecrets)
+		},
+	}
+
+	cmd.Flags().StringVarP(&format, "format", "f", "", "Export format: json or toml (default: from output extension, or json)")
+	cmd.Flags().StringVarP(&outputFile, "output", "o", "", "Write to file instead of stdout")
+	cmd.Flags().BoolVar(&includeSecrets, "secrets", false, "Include secret values (e.g. session key)")
+
+	return cmd
+}
+
+func runConfigExport(format, outputFile string, includeSecrets bool) error {
+	cfg, err := config.New(".")
+	if err != nil {
+		return fmt.Errorf("unable to load config: %w", err)
+	}
+
+	format, err = resolveConfigFormat(format, outputFile)
+	if err != nil {
+		return err
+	}
+
+	if includeSecrets {
+		if err := cfg.Unlock(); err != nil {
+			return fmt.Errorf("unable to unlock config: %w", err)
+		}
+	}
+
+	data, err := encodeConfig(cfg.Export(includeSecrets), format)
+	if err != nil {
+		return fmt.Errorf("unable to encode config: %w", err)
+	}
+
+	if outputFile == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+
+	// Exports may contain secrets, so keep them private to the current user.
+	if err := os.WriteFile(outputFile, data, 0o600); err != nil {
+		return fmt.Errorf("unable to write export: %w", err)
+	}
+	fmt.Printf("Exported config to '%s'\n", outputFile)
+	return nil
+}
+
+func newConfigImportCmd() *cobra.Command {
+	var (
+		format         string
+		includeSecrets bool
+	)
+	cmd := &cobra.Command{
+		Use:   "import <file>",
+		Short: "Import configuration values (JSON or TOML)",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			return runConfigImport(args[0], format, includeSecrets)
+		},
+	}
+
+	cmd.Flags().StringVarP(&format, "format", "f", "", "Import format: json or toml (default: from file extension, or json)")
+	cmd.Flags().BoolVar(&includeSecrets, "secrets", false, "Also import secret values (e.g. session key)")
+
+	return cmd
+}
+
+func runConfigImport(inputFile, format string, includeSecrets bool) error {
+	format, err := resolveConfigFormat(format, inputFile)
+	if err != nil {
+		return err
+	}
+
+	content, err := os.ReadFile(inputFile)
+	if err != nil {
+		return fmt.Errorf("unable to read import file: %w", err)
+	}
+
+	values, err := decodeConfig(content, format)
+	if err != nil {
+		return validationError(fmt.Errorf("unable to parse import file: %w", err))
+	}
+
+	cfg, err := config.New(".")
+	if err != nil {
+		return fmt.Errorf("unable to load config: %w", err)
+	}
+
+	// Sort keys so that output (and failures) are deterministic
+	var keys []string
+	for section, sectionData := range values {
+		for subKey := range sectionData {
+			keys = append(keys, section+"."+subKey)
+		}
+	}
+	sort.Strings(keys)
+
+	imported := 0
+	for _, key := range keys {
+		section, subKey, _ := strings.Cut(key, ".")
+		value := values[section][subKey]
+
+		if cfg.IsSecretKey(key) {
+			if !includeSecrets {
+				fmt.Printf("Skipping secret %s (use --secrets to import it)\n", key)
+				continue
+			}
+		} else {
+			option := findConfigOption(key)
+			if option == nil {
+				fmt.Printf("Skipping unknown configuration option %s\n", key)
+				continue
+			}
+			if option.Validator != nil {
+				if err := option.Validator(value); err != nil {
+					return validationError(fmt.Errorf("invalid value for %s: %w", key, err))
+				}
+			}
+		}
+
+		if err := cfg.Set(key, value); err != nil {
+			return fmt.Errorf("unable to set config: %w", err)
+		}
+		imported++
+	}
+
+	fmt.Printf("Imported %d value(s) from '%s'\n", imported, inputFile)
+	return nil
+}
+
+func newConfigCheckCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "check",
+		Short: "Check the config files for unknown keys and invalid values",
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return runConfigCheck()
+		},
+	}
+
+	return cmd
+}
+
+func runConfigCheck() error {
+	cfg, err := config.New(".")
+	if err != nil {
+		return validationError(fmt.Errorf("unable to load config: %w", err))
+	}
+
+	problems := checkConfig(cfg)
+	if len(problems) == 0 {
+		fmt.Println(style.Success("No problems found"))
+		return nil
+	}
+	for _, problem := range problems {
+		if problem.Invalid {
+			fmt.Println(style.Error(problem.String()))
+		} else {
+			fmt.Println(style.Warning(problem.String()))
+		}
+	}
+	return validationError(fmt.Errorf("found %d problem(s) in the config files", len(problems)))
+}
+
+func newConfigEncryptCmd() *cobra.Command {
+	var useKeychain bool
+	cmd := &cobra.Command{
+		Use:   "encrypt",
+		Short: "Encrypt the secret values of the global config with a passphrase",
+		Long: `Encrypt the secret values of the global config (session keys) at rest, with a
+key derived from a passphrase, or with a random key stored in the OS keychain
+(--keychain, using security on macOS or secret-tool on Linux).
+
+The passphrase is asked when a command first needs a session key, or read from
+` + config.PassphraseEnv + ` (e.g. for the daemon). Use 'sandworm config decrypt'
+to store them in the clear again.`,
+		Args: cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			method := config.EncryptPassphrase
+			if useKeychain {
+				method = config.EncryptKeychain
+			}
+			return runConfigEncrypt(method)
+		},
+	}
+
+	cmd.Flags().BoolVar(&useKeychain, "keychain", false, "Store a random key in the OS keychain instead of using a passphrase")
+
+	return cmd
+}
+
+func runConfigEncrypt(method string) error {
+	cfg, err := config.New("")
+	if err != nil {
+		return fmt.Errorf("unable to load config: %w", err)
+	}
+	if cfg.Encryption() != "" {
+		return validationError(fmt.Errorf("the config is already encrypted (with a %s); run 'sandworm config decrypt' first", cfg.Encryption()))
+	}
+
+	if err := cfg.Encrypt(method); err != nil {
+		return fmt.Errorf("unable to encrypt config: %w", err)
+	}
+
+	fmt.Println(style.Success(fmt.Sprintf("Encrypted the secret values of %s (with a %s)", cfg.Source("claude.session_key"), method)))
+	return nil
+}
+
+func newConfigDecryptCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "decrypt",
+		Short: "Store the secret values of an encrypted global config in the clear",
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return runConfigDecrypt()
+		},
+	}
+
+	return cmd
+}
+
+func runConfigDecrypt() error {
+	cfg, err := config.New("")
+	if err != nil {
+		return fmt.Errorf("unable to load config: %w", err)
+	}
+	if cfg.Encryption() == "" {
+		return validationError(errors.New("the config isn't encrypted"))
+	}
+
+	if err := cfg.Decrypt(); err != nil {
+		return fmt.Errorf("unable to decrypt config: %w", err)
+	}
+
+	fmt.Println(style.Success(fmt.Sprintf("Decrypted the secret values of %s", cfg.Source("claude.session_key"))))
+	return nil
+}
+
+// MARK: Checks
+
+// namedConfigSections hold named entries (e.g. presets.review) rather than
+// options, with the check of their values, if any.
+var namedConfigSections = map[string]func(name, value string) error{
+	"accounts":   nil,
+	"encryption": nil,
+	"sessions":   nil, // Set with session keys (see claude.SetSessionKey)
+	"targets":    nil,
+	"documents":  nil, // Set by push (see claude.Client.Push)
+	"aliases": func(_, value string) error {
+		_, err := splitArgs(value)
+		return err
+	},
+	"presets": func(_, value string) error {
+		_, err := parsePresetArgs(value)
+		return err
+	},
+	"converters": func(name, value string) error {
+		_, err := convert.ParseExec(name, value)
+		return err
+	},
+}
+
+// managedConfigKeys are set by commands (setup, accounts, push) rather than
+// 'config set', so they aren't listed as options.
+var managedConfigKeys = []string{"claude.session_key", "claude.default_account", "claude.document_size", "claude.document_updated"}
+
+// configProblem is an unknown key or invalid value in a config file.
+type configProblem struct {
+	Entry   config.Entry
+	Message string
+	Invalid bool // The value is invalid; otherwise the key is unknown (and ignored)
+}
+
+func (p configProblem) String() string {
+	pos := p.Entry.Path
+	if p.Entry.Line > 0 {
+		pos += fmt.Sprintf(":%d", p.Entry.Line)
+	}
+	return pos + ": " + p.Message
+}
+
+// checkConfig checks the keys set in the config files against the known
+// options, suggesting the closest option for unknown ones, and validates
+// their values.
+func checkConfig(cfg *config.Config) []configProblem {
+	var problems []configProblem
+	for _, entry := range cfg.Entries() {
+		section, name, _ := strings.Cut(entry.Key, ".")
+		if source := cfg.Source(entry.Key); entry.Path != source {
+			problems = append(problems, configProblem{Entry: entry, Message: fmt.Sprintf("'%s' is ignored in this file, it's only read from %s", entry.Key, source)})
+			continue
+		}
+		if check, ok := namedConfigSections[section]; ok {
+			if check != nil {
+				if err := check(name, entry.Value); err != nil {
+					problems = append(problems, configProblem{Entry: entry, Message: fmt.Sprintf("invalid value for %s: %v", entry.Key, err), Invalid: true})
+				}
+			}
+			continue
+		}
+		if slices.Contains(managedConfigKeys, entry.Key) {
+			continue
+		}
+
+		option := findConfigOption(entry.Key)
+		if option == nil {
+			message := fmt.Sprintf("unknown key '%s'", entry.Key)
+			if closest := util.Closest(entry.Key, append(configOptionsKeys(), managedConfigKeys...)); closest != "" {
+				message += fmt.Sprintf(" (did you mean '%s'?)", closest)
+			} else if closest := util.Closest(section, slices.Collect(maps.Keys(namedConfigSections))); closest != "" {
+				message += fmt.Sprintf(" (did you mean '%s.%s'?)", closest, name)
+			}
+			problems = append(problems, configProblem{Entry: entry, Message: message})
+			continue
+		}
+		if option.Validator != nil {
+			if err := option.Validator(entry.Value); err != nil {
+				problems = append(problems, configProblem{Entry: entry, Message: fmt.Sprintf("invalid value for %s: %v", entry.Key, err), Invalid: true})
+			}
+		}
+	}
+	return problems
+}
+
+// validateConfig reports the problems of the config files before they cause
+// failures deep in generation: unknown keys are warned about, and invalid
+// values are a validation error listing them all.
+func validateConfig(cfg *config.Config) error {
+	var invalid []string
+	for _, problem := range checkConfig(cfg) {
+		if problem.Invalid {
+			invalid = append(invalid, "  "+problem.String())
+		} else {
+			fmt.Println(style.Warning(problem.String()))
+		}
+	}
+	if len(invalid) > 0 {
+		return validationError(fmt.Errorf("invalid configuration:\n%s", strings.Join(invalid, "\n")))
+	}
+	return nil
+}
+
 // MARK: Helpers
 
+// resolveConfigFormat validates an explicit format, or infers it from a file
+// extension, defaulting to JSON.
+func resolveConfigFormat(format, file string) (string, error) {
+	if format == "" {
+		if strings.EqualFold(filepath.Ext(file), ".toml") {
+			return "toml", nil
+		}
+		return "json", nil
+	}
+	format = strings.ToLower(format)
+	if format != "json" && format != "toml" {
+		return "", validationError(fmt.Errorf("unsupported format: %s (expected json or toml)", format))
+	}
+	return format, nil
+}
+
+func encodeConfig(values map[string]map[string]string, format string) ([]byte, error) {
+	if format == "toml" {
+		var buf bytes.Buffer
+		if err := toml.NewEncoder(&buf).Encode(values); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+	data, err := json.MarshalIndent(values, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+func decodeConfig(content []byte, format string) (map[string]map[string]string, error) {
+	values := make(map[string]map[string]string)
+	if format == "toml" {
+		if _, err := toml.Decode(string(content), &values); err != nil {
+			return nil, err
+		}
+		return values, nil
+	}
+	if err := json.Unmarshal(content, &values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
 // findConfigOption finds a config option by key
 func findConfigOption(key string) *ConfigOption {
 	for i := range configOptions {
@@ -252,6 +1133,26 @@ func findConfigOption(key string) *ConfigOption {
 	return nil
 }
 
+// matchConfigOptions returns the config options matching a pattern. The pattern
+// may be an exact key, a glob (e.g. "claude.*") or a section prefix (e.g.
+// "processor" or "processor.").
+func matchConfigOptions(pattern string) []ConfigOption {
+	var matches []ConfigOption
+	isGlob := strings.ContainsAny(pattern, "*?[")
+	section := strings.TrimSuffix(pattern, ".")
+	for _, option := range configOptions {
+		switch {
+		case isGlob:
+			if ok, _ := path.Match(pattern, option.Key); ok {
+				matches = append(matches, option)
+			}
+		case option.Key == pattern, strings.HasPrefix(option.Key, section+"."):
+			matches = append(matches, option)
+		}
+	}
+	return matches
+}
+
 func configOptionsKeys() []string {
 	keys := make([]string, len(configOptions))
 	for i, option := range configOptions {
@@ -262,6 +1163,27 @@ func configOptionsKeys() []string {
 
 // MARK: Validators
 
+// validateEnumOption returns a validator accepting only the given values
+func validateEnumOption(values []string) func(string) error {
+	return func(value string) error {
+		for _, v := range values {
+			if value == v {
+				return nil
+			}
+		}
+		return fmt.Errorf("value must be one of %s, got: %s", strings.Join(values, ", "), value)
+	}
+}
+
+// validateCronOption validates a cron expression (empty to disable)
+func validateCronOption(value string) error {
+	if value == "" {
+		return nil
+	}
+	_, err := cron.Parse(value)
+	return err
+}
+
 // validateBoolOption validates that a value is either "true" or "false"
 func validateBoolOption(value string) error {
 	if value != "true" && value != "false" {
@@ -269,3 +1191,34 @@ func validateBoolOption(value string) error {
 	}
 	return nil
 }
+
+// validateSizeOption validates a size such as 500KB or 10MB (empty for none)
+func validateSizeOption(value string) error {
+	if value == "" {
+		return nil
+	}
+	_, err := util.ParseSize(value)
+	return err
+}
+
+// validatePatternsOption validates comma-separated gitignore-style patterns
+// (empty for none)
+func validatePatternsOption(value string) error {
+	return processor.ValidatePatterns(splitList(value))
+}
+
+// validateDurationOption validates a non-negative duration such as 10s or 1m
+func validateDurationOption(value string) error {
+	if d, err := time.ParseDuration(value); err != nil || d < 0 {
+		return fmt.Errorf("value must be a non-negative duration such as 10s or 1m, got: %s", value)
+	}
+	return nil
+}
+
+// validateCountOption validates that a value is a non-negative integer
+func validateCountOption(value string) error {
+	if n, err := strconv.Atoi(value); err != nil || n < 0 {
+		return fmt.Errorf("value must be a non-negative integer, got: %s", value)
+	}
+	return nil
+}
@@ -0,0 +1,249 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/holonoms/sandworm/internal/claude"
+	"github.com/holonoms/sandworm/internal/style"
+	"github.com/spf13/cobra"
+)
+
+// conversationsDir is where transcripts are exported by default.
+const conversationsDir = "conversations"
+
+// newConversationsCmd creates the conversations command and its subcommands
+func newConversationsCmd(opts *Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "conversations",
+		Short: "List and export the Claude project's conversations",
+	}
+
+	cmd.AddCommand(
+		newConversationsListCmd(opts),
+		newConversationsExportCmd(opts),
+	)
+
+	return cmd
+}
+
+func newConversationsListCmd(opts *Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the project's conversations, most recent first",
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			opts.SetDefaults()
+			return runConversationsList(opts)
+		},
+	}
+
+	return cmd
+}
+
+func runConversationsList(opts *Options) error {
+	client, err := setupClaudeClient(false, opts)
+	if err != nil {
+		return err
+	}
+
+	convs, err := client.ListConversations()
+	if err != nil {
+		return fmt.Errorf("unable to list conversations: %w", err)
+	}
+	if len(convs) == 0 {
+		fmt.Println("No conversations in this project.")
+		return ErrNothingToDo
+	}
+	for _, conv := range convs {
+		fmt.Printf("%s %s %s\n", style.Dim(formatConversationTime(conv.UpdatedAt)), conversationTitle(conv), style.Dim(conv.ID))
+	}
+	return nil
+}
+
+func newConversationsExportCmd(opts *Options) *cobra.Command {
+	var all bool
+	var dir string
+
+	cmd := &cobra.Command{
+		Use:   "export [conversation...]",
+		Short: "Save conversation transcripts as markdown",
+		Long: `Save the transcripts of conversations (by ID or name) as markdown files, to
+archive design discussions held in Claude next to the code. Without
+arguments, the project's conversations are listed to pick one; use --all to
+export them all.`,
+		RunE: func(_ *cobra.Command, args []string) error {
+			if all && len(args) > 0 {
+				return validationError(errors.New("--all can't be combined with conversation arguments"))
+			}
+			opts.SetDefaults()
+			return runConversationsExport(opts, args, all, dir)
+		},
+	}
+
+	cmd.Flags().BoolVar(&all, "all", false, "Export all of the project's conversations")
+	cmd.Flags().StringVarP(&dir, "dir", "d", conversationsDir, "Directory to save transcripts to")
+
+	return cmd
+}
+
+func runConversationsExport(opts *Options, refs []string, all bool, dir string) error {
+	client, err := setupClaudeClient(false, opts)
+	if err != nil {
+		return err
+	}
+
+	convs, err := client.ListConversations()
+	if err != nil {
+		return fmt.Errorf("unable to list conversations: %w", err)
+	}
+	if len(convs) == 0 {
+		fmt.Println("No conversations in this project.")
+		return ErrNothingToDo
+	}
+
+	var selected []claude.Conversation
+	switch {
+	case all:
+		selected = convs
+	case len(refs) > 0:
+		for _, ref := range refs {
+			conv, err := findConversation(convs, ref)
+			if err != nil {
+				return err
+			}
+			selected = append(selected, conv)
+		}
+	default:
+		titles := make([]string, len(convs))
+		for i, conv := range convs {
+			titles[i] = fmt.Sprintf("%s %s", formatConversationTime(conv.UpdatedAt), conversationTitle(conv))
+		}
+		i, err := choose("Conversation to export", titles)
+		if err != nil {
+			return err
+		}
+		selected = []claude.Conversation{convs[i]}
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("unable to create directory: %w", err)
+	}
+	for i, conv := range selected {
+		fmt.Println(style.Dim(fmt.Sprintf("%d/%d: Exporting '%s'...", i+1, len(selected), conversationTitle(conv))))
+		messages, err := client.ConversationMessages(conv.ID)
+		if err != nil {
+			return fmt.Errorf("unable to get conversation '%s': %w", conversationTitle(conv), err)
+		}
+		path := filepath.Join(dir, transcriptFileName(conv))
+		if err := os.WriteFile(path, []byte(transcriptMarkdown(conv, messages, time.Now())), 0o644); err != nil {
+			return fmt.Errorf("unable to write transcript: %w", err)
+		}
+	}
+
+	suffix := ""
+	if len(selected) > 1 {
+		suffix = "s"
+	}
+	fmt.Println(style.Success(fmt.Sprintf("Done! Saved %d transcript%s to '%s'", len(selected), suffix, dir)))
+	return nil
+}
+
+// MARK: Helpers
+
+// findConversation finds a conversation by ID or (case-insensitive) name.
+func findConversation(convs []claude.Conversation, ref string) (claude.Conversation, error) {
+	for _, conv := range convs {
+		if conv.ID == ref {
+			return conv, nil
+		}
+	}
+	var matches []claude.Conversation
+	for _, conv := range convs {
+		if strings.EqualFold(conv.Name, ref) {
+			matches = append(matches, conv)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return claude.Conversation{}, validationError(fmt.Errorf("no conversation '%s' in this project", ref))
+	case 1:
+		return matches[0], nil
+	default:
+		return claude.Conversation{}, validationError(fmt.Errorf("several conversations are named '%s', use its ID instead", ref))
+	}
+}
+
+// conversationTitle returns the name of a conversation, or a placeholder.
+func conversationTitle(conv claude.Conversation) string {
+	if conv.Name == "" {
+		return "Untitled"
+	}
+	return conv.Name
+}
+
+// formatConversationTime formats a conversation time for listing.
+func formatConversationTime(t time.Time) string {
+	if t.IsZero() {
+		return strings.Repeat(" ", len("2006-01-02 15:04"))
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
+
+// transcriptFileName names the transcript of a conversation after its date
+// and name, e.g. "2025-07-19-api-design.md", ending with a bit of its ID to
+// keep conversations of the same name apart.
+func transcriptFileName(conv claude.Conversation) string {
+	var slug strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(conv.Name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if dash && slug.Len() > 0 {
+				slug.WriteByte('-')
+			}
+			slug.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+
+	var parts []string
+	if !conv.CreatedAt.IsZero() {
+		parts = append(parts, conv.CreatedAt.Local().Format("2006-01-02"))
+	}
+	if slug.Len() > 0 {
+		parts = append(parts, slug.String())
+	}
+	id, _, _ := strings.Cut(conv.ID, "-")
+	parts = append(parts, id)
+	return strings.Join(parts, "-") + ".md"
+}
+
+// transcriptMarkdown renders a conversation as markdown.
+func transcriptMarkdown(conv claude.Conversation, messages []claude.Message, exported time.Time) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", conversationTitle(conv))
+	fmt.Fprintf(&b, "- Conversation: %s\n", claude.ConversationURL(conv.ID))
+	if !conv.CreatedAt.IsZero() {
+		fmt.Fprintf(&b, "- Created: %s\n", conv.CreatedAt.Local().Format(time.RFC3339))
+	}
+	fmt.Fprintf(&b, "- Exported: %s\n", exported.Local().Format(time.RFC3339))
+
+	for _, m := range messages {
+		sender := "Human"
+		if m.Sender == claude.SenderAssistant {
+			sender = "Claude"
+		}
+		fmt.Fprintf(&b, "\n## %s", sender)
+		if !m.CreatedAt.IsZero() {
+			fmt.Fprintf(&b, " (%s)", m.CreatedAt.Local().Format("2006-01-02 15:04"))
+		}
+		fmt.Fprintf(&b, "\n\n%s\n", strings.TrimSpace(m.Text))
+	}
+	return b.String()
+}
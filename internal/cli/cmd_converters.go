@@ -0,0 +1,164 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/holonoms/sandworm/internal/config"
+	"github.com/holonoms/sandworm/internal/convert"
+	"github.com/holonoms/sandworm/internal/style"
+	"github.com/spf13/cobra"
+)
+
+// newConvertersCmd creates the converters command and its subcommands
+func newConvertersCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "converters",
+		Short: "Manage the converters rendering files (e.g. data formats) as text",
+		Long: `Manage the converters rendering files as text in the generated file.
+
+External converters are shell commands printing the text representation of a
+file. They're defined as '<patterns>: <command>', where patterns is a
+comma-separated list of file name globs or MIME types. The file path replaces
+{} in the command; without it, the content is passed on stdin. Files with a
+converter are bundled even if they'd be skipped as binary, unless your ignore
+rules exclude them.
+
+External converters are stored in the global config, for all projects: they
+run commands, so they're never read from a project's .sandworm file.`,
+	}
+
+	cmd.AddCommand(
+		newConvertersAddCmd(),
+		newConvertersListCmd(),
+		newConvertersRemoveCmd(),
+	)
+
+	return cmd
+}
+
+func newConvertersAddCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add <name> <definition>",
+		Short: "Add an external converter",
+		Example: `  sandworm converters add parquet '*.parquet: parquet-tools schema {}'
+  sandworm converters add proto '*.pb: protoc --decode_raw'`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(_ *cobra.Command, args []string) error {
+			return runConvertersAdd(args[0], args[1])
+		},
+	}
+
+	return cmd
+}
+
+func runConvertersAdd(name, definition string) error {
+	if _, err := convert.ParseExec(name, definition); err != nil {
+		return validationError(err)
+	}
+
+	cfg, err := config.New(".")
+	if err != nil {
+		return fmt.Errorf("unable to load config: %w", err)
+	}
+	if err := cfg.Set("converters."+name, definition); err != nil {
+		return fmt.Errorf("unable to save converter: %w", err)
+	}
+
+	fmt.Println(style.Success(fmt.Sprintf("Added converter '%s': %s", name, definition)))
+	return nil
+}
+
+func newConvertersListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the built-in and external converters",
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return runConvertersList()
+		},
+	}
+
+	return cmd
+}
+
+func runConvertersList() error {
+	cfg, err := config.New(".")
+	if err != nil {
+		return fmt.Errorf("unable to load config: %w", err)
+	}
+
+	names := cfg.Keys("converters")
+	builtins := convert.Builtins(convert.Options{})
+	if len(names) == 0 && len(builtins) == 0 {
+		fmt.Println("No converters. Run 'sandworm converters add <name> <definition>' to add one.")
+		return nil
+	}
+	for _, name := range names {
+		fmt.Printf("%s %s\n", name, style.Dim(cfg.Get("converters."+name)))
+	}
+	for _, c := range builtins {
+		fmt.Printf("%s %s\n", c.Name(), style.Dim("(built-in)"))
+	}
+
+	return nil
+}
+
+func newConvertersRemoveCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "remove <name>",
+		Short: "Remove an external converter",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			return runConvertersRemove(args[0])
+		},
+	}
+
+	return cmd
+}
+
+func runConvertersRemove(name string) error {
+	cfg, err := config.New(".")
+	if err != nil {
+		return fmt.Errorf("unable to load config: %w", err)
+	}
+	if !cfg.Has("converters." + name) {
+		return validationError(fmt.Errorf("unknown converter '%s'", name))
+	}
+	if err := cfg.Delete("converters." + name); err != nil {
+		return fmt.Errorf("unable to remove converter: %w", err)
+	}
+
+	fmt.Println(style.Success(fmt.Sprintf("Removed converter '%s'", name)))
+	return nil
+}
+
+// MARK: Helpers
+
+// newConverterRegistry returns the converters to use: the external ones from
+// config, which take precedence, then the built-in ones. It returns nil when
+// converters are disabled.
+func newConverterRegistry(cfg *config.Config) (*convert.Registry, error) {
+	if !resolveBool(nil, cfg, "processor.converters", true) {
+		return nil, nil
+	}
+
+	registry := convert.NewRegistry()
+	for _, name := range cfg.Keys("converters") {
+		c, err := convert.ParseExec(name, cfg.Get("converters."+name))
+		if err != nil {
+			return nil, validationError(err)
+		}
+		registry.Register(c)
+	}
+	opts := convert.Options{
+		CSVSampleRows:     resolveInt(nil, cfg, "processor.csv_sample_rows", convert.DefaultCSVSampleRows),
+		ImagePlaceholders: resolveBool(nil, cfg, "processor.image_placeholders", false),
+		Minified:          resolveString("", cfg, "processor.minified", convert.MinifiedSources),
+		APISpecs:          resolveString("", cfg, "processor.api_specs", convert.APISpecSummary),
+		Infra:             resolveString("", cfg, "processor.infra", convert.InfraFull),
+	}
+	for _, c := range convert.Builtins(opts) {
+		registry.Register(c)
+	}
+	return registry, nil
+}
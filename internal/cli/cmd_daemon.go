@@ -0,0 +1,204 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/holonoms/sandworm/internal/config"
+	"github.com/holonoms/sandworm/internal/daemon"
+	"github.com/holonoms/sandworm/internal/style"
+	"github.com/spf13/cobra"
+)
+
+// daemonStartTimeout is how long to wait for the daemon's first push.
+const daemonStartTimeout = 60 * time.Second
+
+// newDaemonCmd creates the daemon command and its subcommands
+func newDaemonCmd(opts *Options) *cobra.Command {
+	var watchOpts watchOptions
+
+	cmd := &cobra.Command{
+		Use:   "daemon [directory]",
+		Short: "Run the watcher in the background",
+		Long: `Run 'sandworm watch' in the background for a project, so it stays in sync
+without leaving a terminal open. Use 'sandworm daemon status' and
+'sandworm daemon stop' to manage it.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				opts.Directory = args[0]
+			}
+			opts.SetDefaults()
+			return runDaemonStart(cmd, opts, watchOpts)
+		},
+	}
+
+	addWatchFlags(cmd, &watchOpts)
+
+	cmd.AddCommand(
+		newDaemonStatusCmd(opts),
+		newDaemonStopCmd(opts),
+	)
+
+	return cmd
+}
+
+func newDaemonStatusCmd(opts *Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status [directory]",
+		Short: "Show the status of the project's daemon",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				opts.Directory = args[0]
+			}
+			opts.SetDefaults()
+			return runDaemonStatus(opts)
+		},
+	}
+
+	return cmd
+}
+
+func newDaemonStopCmd(opts *Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "stop [directory]",
+		Short: "Stop the project's daemon",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				opts.Directory = args[0]
+			}
+			opts.SetDefaults()
+			return runDaemonStop(opts)
+		},
+	}
+
+	return cmd
+}
+
+func runDaemonStart(cmd *cobra.Command, opts *Options, watchOpts watchOptions) error {
+	cfg, err := config.New(opts.Directory)
+	if err != nil {
+		return fmt.Errorf("unable to load config: %w", err)
+	}
+	if !resolveBool(watchOpts.Generate, cfg, "watch.generate", false) {
+		if err := checkWritable("the daemon"); err != nil {
+			return err
+		}
+	}
+	paths, err := daemonPaths(opts)
+	if err != nil {
+		return err
+	}
+	if status, err := daemon.Send(paths.Socket, daemon.CommandStatus); err == nil {
+		return validationError(fmt.Errorf("a daemon is already running for this project (pid %d)", status.PID))
+	}
+
+	dir, err := filepath.Abs(opts.Directory)
+	if err != nil {
+		return fmt.Errorf("unable to resolve directory: %w", err)
+	}
+
+	// Run the watch command with the same flags
+	args := append([]string{"watch", dir, "--daemon"}, flagArgs(cmd.Flags())...)
+
+	pid, err := daemon.Start(paths, args)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Started daemon (pid %d), waiting for the first push...\n", pid)
+
+	// Wait for the first push, which fails fast on configuration errors
+	deadline := time.Now().Add(daemonStartTimeout)
+	for time.Now().Before(deadline) {
+		time.Sleep(250 * time.Millisecond)
+		status, err := daemon.Send(paths.Socket, daemon.CommandStatus)
+		if errors.Is(err, daemon.ErrNotRunning) {
+			if daemon.Alive(pid) {
+				continue
+			}
+			daemon.Cleanup(paths)
+			return fmt.Errorf("daemon exited, see %s", paths.Log)
+		}
+		if err == nil && status.Pushes > 0 {
+			fmt.Println(style.Success(fmt.Sprintf("Daemon running for %s", dir)))
+			fmt.Println(style.Dim("Logs: " + paths.Log))
+			return nil
+		}
+	}
+
+	fmt.Println(style.Warning(fmt.Sprintf("Daemon started but hasn't pushed yet, see %s", paths.Log)))
+	return nil
+}
+
+func runDaemonStatus(opts *Options) error {
+	paths, err := daemonPaths(opts)
+	if err != nil {
+		return err
+	}
+
+	status, err := daemon.Send(paths.Socket, daemon.CommandStatus)
+	if errors.Is(err, daemon.ErrNotRunning) {
+		daemon.Cleanup(paths)
+		fmt.Println("No daemon running for this project.")
+		return ErrNothingToDo
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s %s (pid %d)\n", style.Header("Daemon running for"), status.Directory, status.PID)
+	fmt.Printf("  Started:    %s\n", status.StartedAt.Format(time.DateTime))
+	if status.Pushes > 0 {
+		fmt.Printf("  Last push:  %s (%d pushes)\n", status.LastPush.Format(time.DateTime), status.Pushes)
+	}
+	if status.LastError != "" {
+		fmt.Printf("  Last error: %s\n", style.Error(status.LastError))
+	}
+	fmt.Printf("  Logs:       %s\n", paths.Log)
+
+	return nil
+}
+
+func runDaemonStop(opts *Options) error {
+	paths, err := daemonPaths(opts)
+	if err != nil {
+		return err
+	}
+
+	status, err := daemon.Send(paths.Socket, daemon.CommandStop)
+	if errors.Is(err, daemon.ErrNotRunning) {
+		daemon.Cleanup(paths)
+		fmt.Println("No daemon running for this project.")
+		return ErrNothingToDo
+	}
+	if err != nil {
+		return err
+	}
+
+	// The daemon removes its socket on exit
+	for i := 0; i < 40; i++ {
+		if !daemon.Alive(status.PID) {
+			break
+		}
+		time.Sleep(250 * time.Millisecond)
+	}
+	daemon.Cleanup(paths)
+
+	fmt.Println(style.Success(fmt.Sprintf("Stopped daemon (pid %d)", status.PID)))
+	return nil
+}
+
+// MARK: Helpers
+
+// daemonPaths returns the daemon runtime files for the project directory.
+func daemonPaths(opts *Options) (daemon.Paths, error) {
+	cfg, err := config.New(opts.Directory)
+	if err != nil {
+		return daemon.Paths{}, fmt.Errorf("unable to load config: %w", err)
+	}
+	return daemon.PathsFor(cfg.Dir(), opts.Directory)
+}
@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/holonoms/sandworm/internal/encrypt"
+	"github.com/holonoms/sandworm/internal/style"
+	"github.com/spf13/cobra"
+)
+
+// newDecryptCmd creates the decrypt command
+func newDecryptCmd(opts *Options) *cobra.Command {
+	var identity string
+
+	cmd := &cobra.Command{
+		Use:   "decrypt <file>",
+		Short: "Decrypt a file generated with 'generate --encrypt'",
+		Long: `Decrypt a file generated with 'generate --encrypt', using age (with an
+identity file, --identity) or GPG (with the keys of your keyring). The output defaults
+to the file name without its .age/.gpg extension.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			return runDecrypt(args[0], identity, opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&identity, "identity", "", "age identity file (private key)")
+
+	return cmd
+}
+
+func runDecrypt(path, identity string, opts *Options) error {
+	output := opts.OutputFile
+	if output == "" {
+		ext := filepath.Ext(path)
+		if ext != ".age" && ext != ".gpg" {
+			return validationError(errors.New("unable to derive the output name (no .age/.gpg extension), use -o"))
+		}
+		output = strings.TrimSuffix(path, ext)
+	}
+	if _, err := os.Stat(output); err == nil {
+		if err := confirmOrCancel(fmt.Sprintf("Overwrite '%s'?", output), "decrypt", opts); err != nil {
+			return err
+		}
+	}
+
+	if err := encrypt.DecryptFile(path, output, identity); err != nil {
+		return fmt.Errorf("unable to decrypt: %w", err)
+	}
+
+	fmt.Println(style.Success(fmt.Sprintf("Decrypted '%s'", output)))
+	return nil
+}
@@ -0,0 +1,308 @@
+package cli
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/holonoms/sandworm/internal/audit"
+	"github.com/holonoms/sandworm/internal/claude"
+	"github.com/holonoms/sandworm/internal/config"
+	"github.com/holonoms/sandworm/internal/style"
+	"github.com/spf13/cobra"
+)
+
+// deployLog is the project document holding the deploy markers, so that
+// everyone working on the project sees its releases. It isn't uploaded as a
+// managed document, so pruning and purging leave it alone.
+const deployLog = "sandworm-deploys.jsonl"
+
+// deployLogLimit is the number of deploys kept in deployLog.
+const deployLogLimit = 100
+
+// deployOptions holds the options of the deploy command.
+type deployOptions struct {
+	Push         pushOptions
+	Version      string // Release label; git describe of the project if empty
+	Instructions string // Instructions file; PROJECT_INSTRUCTIONS.md of the project if empty
+}
+
+// newDeployCmd creates the deploy command
+func newDeployCmd(opts *Options) *cobra.Command {
+	var deployOpts deployOptions
+
+	cmd := &cobra.Command{
+		Use:   "deploy [directory]",
+		Short: "Publish the bundle and project instructions together, as a release",
+		Long: `Publish a release of the project to Claude: generate and push the bundle, set
+the project instructions from ` + instructionsFile + ` (if present) and record
+a deploy marker with the version in the project (as the ` + deployLog + `
+document) and the audit log, listed by 'sandworm deploys list'.
+
+Everything that can fail locally (reading the instructions, generating the
+bundle, budget checks) happens before the project is touched, and the
+instructions are only updated once the bundle is uploaded. The API has no
+transactions though: if updating the instructions fails, the new bundle is
+already live, and deploying again completes the release.`,
+		Example: `  sandworm deploy
+  sandworm deploy --version v1.4.0 --prune
+  sandworm deploy --instructions docs/claude.md`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				opts.Directory = args[0]
+			}
+			if cmd.Flags().Changed("backup") {
+				backup, _ := cmd.Flags().GetBool("backup")
+				deployOpts.Push.Backup = &backup
+			}
+			opts.SetDefaults()
+			return runDeploy(opts, deployOpts, cmd.Flags().Changed("instructions"))
+		},
+	}
+
+	cmd.Flags().StringVar(&deployOpts.Version, "version", "", "Version label of the release (default: git describe of the project)")
+	cmd.Flags().StringVar(&deployOpts.Instructions, "instructions", "", "Instructions file (default: "+instructionsFile+" in the project, skipped if missing)")
+	cmd.Flags().BoolVar(&deployOpts.Push.Strict, "strict", false, "Fail instead of warning when the bundle exceeds the budget.* thresholds")
+	cmd.Flags().BoolVar(&deployOpts.Push.Prune, "prune", false, "Delete project documents other than the pushed ones, mirroring the local project")
+	cmd.Flags().Bool("backup", false, "Back up the project's documents locally before replacing them, see 'sandworm restore' (overrides config setting)")
+	addDeleteFirstFlag(cmd, &deployOpts.Push)
+
+	return cmd
+}
+
+// runDeploy pushes the bundle, then updates the instructions and records the
+// deploy. explicit reports whether the instructions file was given, in which
+// case it must exist.
+func runDeploy(opts *Options, deployOpts deployOptions, explicit bool) error {
+	if err := checkWritable("deploy"); err != nil {
+		return err
+	}
+	marker := audit.Deploy{
+		Version: deployOpts.Version,
+		Commit:  gitOutput(opts.Directory, "rev-parse", "--short", "HEAD"),
+		Branch:  gitOutput(opts.Directory, "rev-parse", "--abbrev-ref", "HEAD"),
+	}
+	if marker.Version == "" {
+		marker.Version = gitOutput(opts.Directory, "describe", "--tags", "--always", "--dirty")
+	}
+	if marker.Version == "" {
+		return validationError(fmt.Errorf("'%s' isn't a git repository, set the version of the release with --version", opts.Directory))
+	}
+
+	file := deployOpts.Instructions
+	if file == "" {
+		file = filepath.Join(opts.Directory, instructionsFile)
+	}
+	instructions, err := os.ReadFile(file)
+	switch {
+	case errors.Is(err, fs.ErrNotExist) && !explicit:
+		file, instructions = "", nil
+	case err != nil:
+		return fmt.Errorf("unable to read instructions: %w", err)
+	}
+
+	client, err := setupClaudeClient(false, opts)
+	if err != nil {
+		return err
+	}
+	var current string
+	if file != "" {
+		if current, err = client.GetInstructions(); err != nil {
+			return fmt.Errorf("unable to get instructions: %w", err)
+		}
+	}
+
+	fmt.Printf("%s %s", style.Header("Deploying:"), style.Info(marker.Version))
+	if marker.Commit != "" {
+		fmt.Print(style.Dim(fmt.Sprintf(" (%s, %s)", marker.Commit, marker.Branch)))
+	}
+	fmt.Println()
+	if err := runPush(opts, deployOpts.Push); err != nil {
+		return err
+	}
+
+	switch {
+	case file == "":
+		fmt.Println(style.Dim(fmt.Sprintf("No %s, project instructions left as is", instructionsFile)))
+	case string(instructions) == current:
+		fmt.Println("Project instructions are up to date")
+	default:
+		if err := client.SetInstructions(string(instructions)); err != nil {
+			return fmt.Errorf("bundle deployed, but unable to set instructions (deploy again to complete the release): %w", err)
+		}
+		fmt.Println(style.Success("Updated project instructions"))
+	}
+
+	if file != "" {
+		marker.Instructions, _, _ = audit.HashFile(file)
+	}
+	appendAudit(client, audit.Entry{Action: audit.ActionDeploy, Directory: opts.Directory, Deploy: &marker}, "")
+	if err := recordDeploy(client, marker); err != nil {
+		return fmt.Errorf("deployed, but unable to record the deploy in the project (deploy again to record it): %w", err)
+	}
+	fmt.Println(style.Success(fmt.Sprintf("Deployed %s", marker.Version)))
+	return nil
+}
+
+// recordDeploy appends a deploy marker to the project's deploy log, keeping
+// the last deployLogLimit deploys. Local details (directory, account) stay in
+// the audit log.
+func recordDeploy(client *claude.Client, marker audit.Deploy) error {
+	content, err := client.DocumentContent(deployLog)
+	if err != nil {
+		return err
+	}
+	entries, err := audit.Parse(strings.NewReader(content))
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", deployLog, err)
+	}
+	orgID, projectID := client.TargetIDs()
+	_, projectName := client.TargetNames()
+	entries = append(entries, audit.Entry{
+		Action:       audit.ActionDeploy,
+		Organization: orgID,
+		Project:      projectID,
+		ProjectName:  projectName,
+		Deploy:       &marker,
+	})
+
+	var b strings.Builder
+	for _, e := range entries[max(0, len(entries)-deployLogLimit):] {
+		line, err := audit.Marshal(e)
+		if err != nil {
+			return err
+		}
+		b.Write(line)
+	}
+	return client.ReplaceDocument(deployLog, b.String())
+}
+
+// newDeploysCmd creates the deploys command and its subcommands
+func newDeploysCmd(opts *Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "deploys",
+		Short: "Show past deploys",
+	}
+
+	cmd.AddCommand(newDeploysListCmd(opts))
+
+	return cmd
+}
+
+func newDeploysListCmd(opts *Options) *cobra.Command {
+	var limit int
+	var all, asJSON bool
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the deploys to the project, most recent last",
+		Long: `List the deploys to the project, most recent last, as recorded in its
+` + deployLog + ` document: those of everyone working on the project. With
+--all, list the deploys to every project made from this machine, as recorded
+in the audit log.`,
+		Args: cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return runDeploysList(opts, limit, all, asJSON)
+		},
+	}
+
+	cmd.Flags().IntVarP(&limit, "limit", "l", 20, "Show the last N deploys (0 for all)")
+	cmd.Flags().BoolVar(&all, "all", false, "List the deploys to every project made from this machine")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print deploys as JSON lines")
+
+	return cmd
+}
+
+func runDeploysList(opts *Options, limit int, all, asJSON bool) error {
+	entries, err := readDeploys(opts, all)
+	if err != nil {
+		return err
+	}
+	deploys := filterDeploys(entries)
+	if len(deploys) == 0 {
+		fmt.Println("No deploys recorded yet.")
+		return ErrNothingToDo
+	}
+	if limit > 0 && len(deploys) > limit {
+		deploys = deploys[len(deploys)-limit:]
+	}
+
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		for _, e := range deploys {
+			if err := enc.Encode(e); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for _, e := range deploys {
+		line := fmt.Sprintf("%s %s", style.Dim(e.Time.Local().Format("2006-01-02 15:04:05")), style.Info(e.Deploy.Version))
+		if e.Deploy.Commit != "" {
+			line += style.Dim(fmt.Sprintf(" (%s, %s)", e.Deploy.Commit, e.Deploy.Branch))
+		}
+		line += " by " + e.User
+		if all {
+			line += " -> " + cmp.Or(e.ProjectName, e.Project)
+		}
+		fmt.Println(line)
+	}
+	return nil
+}
+
+// MARK: Helpers
+
+// readDeploys returns the entries of the project's deploy log, or of the
+// local audit log if all is set.
+func readDeploys(opts *Options, all bool) ([]audit.Entry, error) {
+	if all {
+		cfg, err := config.New(".")
+		if err != nil {
+			return nil, fmt.Errorf("unable to load config: %w", err)
+		}
+		return audit.Read(audit.Path(cfg.Dir()))
+	}
+
+	client, err := setupClaudeClient(false, opts)
+	if err != nil {
+		return nil, err
+	}
+	content, err := client.DocumentContent(deployLog)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := audit.Parse(strings.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", deployLog, err)
+	}
+	return entries, nil
+}
+
+// filterDeploys returns the deploys out of audit log entries.
+func filterDeploys(entries []audit.Entry) []audit.Entry {
+	var deploys []audit.Entry
+	for _, e := range entries {
+		if e.Action == audit.ActionDeploy && e.Deploy != nil {
+			deploys = append(deploys, e)
+		}
+	}
+	return deploys
+}
+
+// gitOutput returns the trimmed output of a git command run in dir, or an
+// empty string if it fails (e.g. outside a repository).
+func gitOutput(dir string, args ...string) string {
+	out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
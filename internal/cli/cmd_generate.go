@@ -1,16 +1,65 @@
 package cli
 
 import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/holonoms/sandworm/internal/config"
+	"github.com/holonoms/sandworm/internal/convert"
+	"github.com/holonoms/sandworm/internal/deps"
+	"github.com/holonoms/sandworm/internal/encrypt"
 	"github.com/holonoms/sandworm/internal/processor"
+	"github.com/holonoms/sandworm/internal/profile"
+	"github.com/holonoms/sandworm/internal/sanitize"
+	"github.com/holonoms/sandworm/internal/scrub"
+	"github.com/holonoms/sandworm/internal/source"
+	"github.com/holonoms/sandworm/internal/stats"
+	"github.com/holonoms/sandworm/internal/style"
+	"github.com/holonoms/sandworm/internal/tokens"
 	"github.com/holonoms/sandworm/internal/util"
+	"github.com/holonoms/sandworm/internal/wsl"
 	"github.com/spf13/cobra"
 )
 
+// defaultOutputFile is the file generate writes, unless --output is given.
+const defaultOutputFile = "sandworm.txt"
+
+// generateOptions holds the options of generating a bundle, for the generate
+// command and the others generating one (e.g. push, watch --generate).
+type generateOptions struct {
+	OutputFile   string           // Resolved from --output or the command's default
+	Encrypt      *encrypt.Spec    // Encrypt the bundle once generated; nil for plaintext
+	Append       []string         // Previously generated bundles to append to it
+	ScrubSecrets bool             // Mask credentials regardless of the options, as a policy requires (see requiresSecretScan)
+	Timings      *profile.Timings // Records the phases of a profiled run (see startProfiling); nil when not profiling
+}
+
+// generation holds the results of generating a bundle (see runGenerate).
+type generation struct {
+	Path     string        // Of the bundle
+	Size     int64         // Of the bundle, in bytes
+	Files    int           // With contents in the bundle
+	Tokens   int           // Estimated with tokens.estimator
+	Duration time.Duration // Of the processing
+	Offsets  []int64       // Of the file sections, to split the bundle (see splitBundle)
+	Flagged  int           // Files flagged by the prompt-injection scan
+}
+
 // newGenerateCmd creates the generate command
 func newGenerateCmd(opts *Options) *cobra.Command {
+	var genOpts generateOptions
+	var encryption string
+
 	cmd := &cobra.Command{
 		Use:   "generate [directory]",
 		Short: "Generate concatenated file only",
@@ -18,73 +67,603 @@ func newGenerateCmd(opts *Options) *cobra.Command {
 			if len(args) > 0 {
 				opts.Directory = args[0]
 			}
-			// Default output for generate
-			if opts.OutputFile == "" {
-				opts.OutputFile = "sandworm.txt"
+			opts.SetDefaults()
+			genOpts.OutputFile = cmp.Or(opts.OutputFile, defaultOutputFile)
+			timings, stopProfiling, err := startProfiling(opts)
+			if err != nil {
+				return err
 			}
-			opts.KeepFile = true
+			defer stopProfiling()
+			genOpts.Timings = timings
 
-			fmt.Printf("Generating project '%s'...\n", opts.OutputFile)
-			size, err := runGenerate(opts)
-			fmt.Printf("Generated '%s' (%s)\n", opts.OutputFile, util.FormatSize(size))
-			return err
+			if encryption != "" {
+				parsed, err := encrypt.Parse(encryption)
+				if err != nil {
+					return validationError(err)
+				}
+				genOpts.Encrypt = &parsed
+			}
+
+			fmt.Printf("Generating project '%s'...\n", genOpts.OutputFile)
+			if genOpts.Encrypt != nil {
+				// The plaintext is only readable by the user until encrypted,
+				// and removed in any case
+				if err := createPrivate(genOpts.OutputFile); err != nil {
+					return err
+				}
+				defer func() { _ = os.Remove(genOpts.OutputFile) }()
+			}
+			return writeBundle(opts, genOpts)
 		},
 	}
 
+	cmd.Flags().StringArrayVar(&genOpts.Append, "append", nil, "Append a previously generated file (e.g. vendored docs), merging its structure (see 'sandworm compose'); repeatable")
+	cmd.Flags().StringVar(&encryption, "encrypt", "", "Encrypt the generated file for a recipient: age:<recipient> or gpg:<key id/email> (see 'sandworm decrypt')")
+
 	return cmd
 }
 
-func runGenerate(opts *Options) (int64, error) {
-	if opts.Directory == "" {
-		opts.Directory = "."
+// writeBundle generates the output file, then appends the bundles of
+// genOpts.Append to it, splits it in parts of tokens.chunk_size tokens and
+// encrypts it (or its parts), as configured.
+func writeBundle(opts *Options, genOpts generateOptions) error {
+	gen, err := runGenerate(opts, genOpts)
+	if err != nil {
+		return err
+	}
+	if len(genOpts.Append) > 0 {
+		composition, err := composeBundles(opts, gen.Path, append([]string{gen.Path}, genOpts.Append...))
+		if err != nil {
+			return err
+		}
+		gen.Size, gen.Files = int64(len(composition.Content)), composition.Files
+		gen.Tokens = estimateTokens(opts.Directory, gen.Path)
+		gen.Offsets = composition.Offsets
+	}
+	recordStats(opts, stats.ActionGenerate, gen)
+
+	parts, err := splitBundle(opts, gen, filepath.Base(gen.Path))
+	if err != nil {
+		return err
+	}
+	if parts != nil {
+		return writeParts(gen.Path, parts, genOpts.Encrypt)
+	}
+
+	if genOpts.Encrypt != nil {
+		return encryptOutput(*genOpts.Encrypt, gen.Path, gen.Path)
+	}
+	fmt.Println(style.Success(fmt.Sprintf("Generated '%s' (%s, %s)", gen.Path, util.FormatSize(gen.Size), tokens.Format(gen.Tokens))))
+	if win := windowsPath(gen.Path); win != "" {
+		fmt.Println(style.Dim("From Windows: " + win))
+	}
+	return nil
+}
+
+// encryptOutput encrypts a plaintext file into outputFile (plus the
+// extension of the encryption tool), removing the plaintext file in any case.
+func encryptOutput(spec encrypt.Spec, plaintext, outputFile string) error {
+	defer func() { _ = os.Remove(plaintext) }()
+	encrypted := outputFile + spec.Extension()
+	if err := encrypt.EncryptFile(spec, plaintext, encrypted); err != nil {
+		return fmt.Errorf("unable to encrypt output: %w", err)
+	}
+
+	info, err := os.Stat(encrypted)
+	if err != nil {
+		return fmt.Errorf("unable to read encrypted output: %w", err)
+	}
+	fmt.Println(style.Success(fmt.Sprintf("Generated '%s' (%s, encrypted with %s)", encrypted, util.FormatSize(info.Size()), spec.Tool)))
+	return nil
+}
+
+// createPrivate creates a file only readable by the user, or restricts an
+// existing one, for the output to keep these permissions.
+func createPrivate(path string) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("unable to create output file: %w", err)
+	}
+	_ = f.Close()
+	if err := os.Chmod(path, 0o600); err != nil {
+		return fmt.Errorf("unable to restrict output file: %w", err)
+	}
+	return nil
+}
+
+// createPlaintext creates a temporary file only readable by the user in dir,
+// holding content until it's encrypted, and returns its path. Its name
+// matches the generated files, so it's never bundled should it be left over.
+func createPlaintext(dir string, content []byte) (string, error) {
+	f, err := os.CreateTemp(dir, ".sandworm-*.txt")
+	if err != nil {
+		return "", fmt.Errorf("unable to create output file: %w", err)
+	}
+	_, err = f.Write(content)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+		return "", fmt.Errorf("unable to write output file: %w", err)
+	}
+	return f.Name(), nil
+}
+
+// writeParts writes the parts of a split bundle next to the output file, in
+// place of it, encrypting them if spec is set.
+func writeParts(outputFile string, parts []bundlePart, spec *encrypt.Spec) error {
+	dir := filepath.Dir(outputFile)
+	for _, part := range parts {
+		path := filepath.Join(dir, part.Name)
+		if spec != nil {
+			plaintext, err := createPlaintext(dir, part.Content)
+			if err != nil {
+				return err
+			}
+			if err := encryptOutput(*spec, plaintext, path); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := os.WriteFile(path, part.Content, 0o644); err != nil {
+			return fmt.Errorf("unable to write %s: %w", part.Name, err)
+		}
+		fmt.Println(style.Success(fmt.Sprintf("Generated '%s' (%s, %s)", path, util.FormatSize(int64(len(part.Content))), tokens.Format(part.Tokens))))
+	}
+	if err := os.Remove(outputFile); err != nil {
+		return fmt.Errorf("unable to remove unsplit output: %w", err)
 	}
+	return nil
+}
 
-	// Resolve all processor options from flags/config/defaults
-	cfg, err := config.New(opts.Directory)
+// runGenerate writes the bundle to genOpts.OutputFile, reporting what was
+// skipped, sanitized or flagged along the way.
+func runGenerate(opts *Options, genOpts generateOptions) (generation, error) {
+	in, closeInput, err := withInput(opts)
 	if err != nil {
-		return 0, fmt.Errorf("unable to load config: %w", err)
+		return generation{}, err
 	}
+	defer closeInput()
 
-	if opts.ShowLineNumbers == nil {
-		if cfg.Has("processor.print_line_numbers") {
-			value := cfg.Get("processor.print_line_numbers")
-			b := value == "true"
-			opts.ShowLineNumbers = &b
+	// Archives, images and remote directories are meant to be bundled whole
+	if in.dir == "" && in.fsys == nil {
+		if err := checkRoot(opts); err != nil {
+			return generation{}, err
 		}
 	}
 
-	if opts.FollowSymlinks == nil {
-		if cfg.Has("processor.follow_symlinks") {
-			value := cfg.Get("processor.follow_symlinks")
-			b := value == "true"
-			opts.FollowSymlinks = &b
+	p, err := newProcessor(opts, genOpts, in)
+	if err != nil {
+		return generation{}, err
+	}
+
+	start := time.Now()
+	size, err := p.ProcessContext(interrupt)
+	var partial *processor.PartialError
+	if errors.As(err, &partial) {
+		err = nil // A complete bundle without the skipped files, reported below
+	}
+	if errors.Is(err, processor.ErrLimitExceeded) {
+		return generation{}, validationError(fmt.Errorf("%w (is %s the right directory? Raise processor.max_depth/max_files, or set them to 0 for no limit)", err, opts.Directory))
+	}
+	if err != nil {
+		return generation{}, fmt.Errorf("unable to process files: %w", err)
+	}
+	gen := generation{
+		Path:     genOpts.OutputFile,
+		Size:     size,
+		Files:    p.IncludedCount(),
+		Duration: time.Since(start),
+		Offsets:  p.FileOffsets(),
+	}
+	if genOpts.Timings != nil {
+		timings := p.Timings()
+		genOpts.Timings.Add("walk", timings.Walk)
+		genOpts.Timings.Add("read", timings.Read)
+		genOpts.Timings.Add("write", timings.Write)
+	}
+	tokensStart := time.Now()
+	gen.Tokens = estimateTokens(cmp.Or(in.settings, opts.Directory), gen.Path)
+	addTiming(genOpts.Timings, "tokens", tokensStart)
+
+	if spliced := p.Spliced(); spliced > 0 {
+		fmt.Println(style.Dim(fmt.Sprintf("Reused %d unchanged files from the last bundle", spliced)))
+	}
+	if partial != nil {
+		fmt.Println(style.Warning(fmt.Sprintf("Skipped %d files that couldn't be read (listed in the bundle):", len(partial.Skipped))))
+		for _, file := range partial.Skipped {
+			fmt.Printf("  %s %s\n", file.Path, style.Dim("("+file.Reason+")"))
+		}
+	}
+	if stale := p.StaleURLs(); len(stale) > 0 {
+		fmt.Println(style.Warning(fmt.Sprintf("Included cached copies of %d remote sources that couldn't be fetched:", len(stale))))
+		for _, u := range stale {
+			fmt.Printf("  %s\n", u)
+		}
+	}
+	if excluded := p.LicenseExclusions(); len(excluded) > 0 {
+		fmt.Println(style.Warning(fmt.Sprintf("Excluded %d files by license policy", len(excluded))))
+	}
+	if sanitized := p.SanitizeReport(); len(sanitized) > 0 {
+		fmt.Println(style.Header(fmt.Sprintf("Sanitized control/invisible characters in %d files:", len(sanitized))))
+		for _, result := range sanitized {
+			fmt.Printf("  %s %s\n", result.Path, style.Dim(fmt.Sprintf("(%d characters)", result.Count)))
+		}
+	}
+	gen.Flagged = len(p.InjectionReport())
+	if gen.Flagged > 0 {
+		printInjectionReport(p.InjectionReport())
+	}
+	if scrubbed := p.ScrubReport(); len(scrubbed) > 0 {
+		fmt.Println(style.Header(fmt.Sprintf("Scrubbed PII or secrets in %d files:", len(scrubbed))))
+		for _, result := range scrubbed {
+			fmt.Printf("  %s %s\n", result.Path, style.Dim("("+result.Counts.String()+")"))
 		}
 	}
+	if opts.LicenseReport != "" {
+		if err := writeLicenseReport(p, opts.LicenseReport); err != nil {
+			return generation{}, err
+		}
+		fmt.Println(style.Dim("License report written to " + opts.LicenseReport))
+	}
+
+	return gen, nil
+}
 
-	printLineNumbers := false
-	if opts.ShowLineNumbers != nil {
-		printLineNumbers = *opts.ShowLineNumbers
+// newProcessor creates a processor writing to genOpts.OutputFile and reading
+// files from in, resolving all its options from flags/config/defaults.
+func newProcessor(opts *Options, genOpts generateOptions, in input) (*processor.Processor, error) {
+	directory := cmp.Or(in.settings, opts.Directory, ".")
+	cfg, err := config.New(directory)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load config: %w", err)
 	}
-	followSymlinks := false
-	if opts.FollowSymlinks != nil {
-		followSymlinks = *opts.FollowSymlinks
+	if err := validateConfig(cfg); err != nil {
+		return nil, err
+	}
+	for _, patterns := range [][]string{opts.Exclude, opts.Include} {
+		if err := processor.ValidatePatterns(patterns); err != nil {
+			return nil, validationError(err)
+		}
 	}
 
 	// Resolve processor options from CLI options
 	procOpts := processor.SandwormOptions{
-		PrintLineNumbers: printLineNumbers,
-		FollowSymlinks:   followSymlinks,
+		PrintLineNumbers: resolveBool(opts.ShowLineNumbers, cfg, "processor.print_line_numbers", false),
+		FollowSymlinks:   resolveBool(opts.FollowSymlinks, cfg, "processor.follow_symlinks", false),
+		ASCIITree:        opts.Plain,
+		Linguist:         resolveBool(nil, cfg, "processor.linguist", true),
+		Submodules:       resolveString(opts.Submodules, cfg, "processor.submodules", processor.SubmodulesFull),
+		Order:            resolveString(opts.Order, cfg, "processor.order", processor.OrderPath),
+		DefaultIgnores:   resolveString(opts.DefaultIgnores, cfg, "processor.default_ignores", "all"),
+		GlobalIgnoreFile: globalIgnoreFile(cfg),
+		ExcludePatterns:  opts.Exclude,
+		IncludePatterns:  opts.Include,
+		MaxDepth:         resolveInt(nil, cfg, "processor.max_depth", processor.DefaultMaxDepth),
+		MaxFiles:         resolveInt(nil, cfg, "processor.max_files", processor.DefaultMaxFiles),
+		ReadTimeout:      resolveDuration(cfg, "processor.read_timeout", processor.DefaultReadTimeout),
+		WalkConcurrency:  resolveInt(nil, cfg, "processor.walk_concurrency", processor.DefaultWalkConcurrency),
+		StatBatch:        resolveInt(nil, cfg, "processor.stat_batch", processor.DefaultStatBatch),
+		FixtureMaxLines:  resolveInt(nil, cfg, "processor.fixture_max_lines", processor.DefaultFixtureMaxLines),
+		DependencyGraph:  resolveBool(opts.DependencyGraph, cfg, "processor.dependency_graph", false),
+		SymbolIndex:      resolveBool(opts.SymbolIndex, cfg, "processor.symbol_index", false),
+		FileMetadata:     resolveBool(opts.FileMetadata, cfg, "processor.file_metadata", false),
+		ChecksumManifest: resolveBool(opts.ChecksumManifest, cfg, "processor.checksum_manifest", false),
+		LicenseExclude:   resolveString(opts.LicenseExclude, cfg, "processor.license_exclude", ""),
+		ReportFiles:      []string{opts.LicenseReport}, // Empty if none
+		NormalizeEOL:     resolveBool(opts.Normalize, cfg, "processor.normalize_eol", false),
+		TrimWhitespace:   resolveBool(opts.Normalize, cfg, "processor.trim_whitespace", false),
+		Sanitize:         resolveString(opts.Sanitize, cfg, "processor.sanitize", sanitize.Off),
+		InjectionScan:    resolveBool(opts.ScanInjection, cfg, "processor.injection_scan", false),
+		InjectionPaths:   splitList(resolveString("", cfg, "processor.injection_scan_paths", "")),
+		ScrubPII:         resolveBool(opts.ScrubPII, cfg, "processor.scrub_pii", false),
+		ScrubPattern:     resolveString("", cfg, "processor.scrub_pattern", ""),
+		ScrubPaths:       splitList(resolveString("", cfg, "processor.scrub_paths", "")),
+		ScrubSecrets:     genOpts.ScrubSecrets || resolveBool(opts.ScrubSecrets, cfg, "processor.scrub_secrets", false),
 	}
 
-	p, err := processor.NewWithOptions(opts.Directory, opts.OutputFile, opts.IgnoreFile, procOpts)
+	fileHeader, err := resolveFileHeader(opts.HeaderStyle, cfg)
 	if err != nil {
-		return 0, fmt.Errorf("unable to create processor: %w", err)
+		return nil, validationError(err)
+	}
+	procOpts.FileHeader = fileHeader
+
+	if opts.Deps && opts.Package == "" {
+		return nil, validationError(errors.New("--deps requires --package"))
+	}
+	if opts.Workspace != "" && opts.Package != "" {
+		return nil, validationError(errors.New("--workspace and --package can't be used together"))
+	}
+	if opts.Target != "" && (opts.Workspace != "" || opts.Package != "") {
+		return nil, validationError(errors.New("--target can't be used with --workspace or --package"))
 	}
+	if in.fsys != nil && (opts.Workspace != "" || opts.Package != "") {
+		return nil, validationError(errors.New("--from can't be used with --workspace or --package"))
+	}
+
+	// Files are read from the input (e.g. an extracted image) if any, while
+	// config comes from the project directory.
+	root := cmp.Or(in.dir, directory)
 
-	size, err := p.Process()
+	if opts.Package != "" {
+		files, err := deps.GoPackageFiles(root, opts.Package, opts.Deps)
+		if err != nil {
+			return nil, validationError(fmt.Errorf("unable to resolve go package: %w", err))
+		}
+		procOpts.IncludeFiles = files
+	}
+	if opts.Workspace != "" {
+		dirs, err := workspaceDirs(root, opts.Workspace)
+		if err != nil {
+			return nil, err
+		}
+		procOpts.IncludeDirs = dirs
+	}
+
+	procOpts.SelectedFiles = opts.Files
+	if opts.Target != "" && len(opts.Files) == 0 {
+		if procOpts.SelectedFiles, err = targetFiles(cfg, opts.Target); err != nil {
+			return nil, err
+		}
+	}
+
+	if procOpts.Converters, err = newConverterRegistry(cfg); err != nil {
+		return nil, err
+	}
+	databases, err := convert.ParseDatabases(resolveString("", cfg, "processor.databases", ""))
+	if err != nil {
+		return nil, validationError(err)
+	}
+	// Databases are configured for all projects: those given by variables
+	// only some projects set are skipped elsewhere, as are all of them for
+	// archives, whose SQLite files aren't on disk
+	if in.fsys == nil {
+		procOpts.Databases = slices.DeleteFunc(databases, convert.Database.Unset)
+	}
+
+	if procOpts.URLSources, err = source.ReadURLs(directory); err != nil {
+		return nil, validationError(err)
+	}
+	if len(procOpts.URLSources) > 0 {
+		maxAge, err := source.ParseMaxAge(resolveString("", cfg, "sources.max_age", "24h"))
+		if err != nil {
+			return nil, validationError(err)
+		}
+		procOpts.URLFetcher = &source.URLFetcher{
+			Dir:          filepath.Join(cfg.Dir(), "sources"),
+			MaxAge:       maxAge,
+			Refresh:      opts.RefreshSources,
+			AllowPrivate: resolveBool(nil, cfg, "sources.allow_private", false),
+		}
+	}
+
+	// Inputs (e.g. archives) are extracted anew each time, no use caching them.
+	// The cache holds the bundle in plaintext, so it's opt-in, and never kept
+	// for encrypted bundles.
+	if in.dir == "" && in.fsys == nil && genOpts.Encrypt == nil && resolveBool(nil, cfg, "processor.incremental", false) {
+		abs, err := filepath.Abs(root)
+		if err != nil {
+			return nil, fmt.Errorf("unable to resolve project directory: %w", err)
+		}
+		sum := sha256.Sum256([]byte(abs))
+		procOpts.IncrementalDir = filepath.Join(cfg.Dir(), "incremental", hex.EncodeToString(sum[:8]))
+	}
+
+	if err := processor.ValidateIgnoreCategories(procOpts.DefaultIgnores); err != nil {
+		return nil, validationError(err)
+	}
+	if err := processor.ValidateLicensePattern(procOpts.LicenseExclude); err != nil {
+		return nil, validationError(err)
+	}
+	if err := sanitize.Validate(procOpts.Sanitize); err != nil {
+		return nil, validationError(err)
+	}
+	if err := scrub.ValidatePattern(procOpts.ScrubPattern); err != nil {
+		return nil, validationError(err)
+	}
+
+	var p *processor.Processor
+	if in.fsys != nil {
+		p, err = processor.NewFromFS(in.fsys, genOpts.OutputFile, opts.IgnoreFile, procOpts)
+	} else {
+		p, err = processor.NewWithOptions(root, genOpts.OutputFile, opts.IgnoreFile, procOpts)
+	}
 	if err != nil {
-		return 0, fmt.Errorf("unable to process files: %w", err)
+		return nil, fmt.Errorf("unable to create processor: %w", err)
 	}
 
-	return size, nil
+	return p, nil
+}
+
+// MARK: Helpers
+
+// input holds the files to bundle when they aren't read from the project
+// directory (see withInput). The zero value reads the project directory.
+type input struct {
+	dir      string // Temporary directory holding the files of --from-image or a remote directory
+	fsys     fs.FS  // Files of the --from archive
+	settings string // Directory of the project settings, if not the project directory
+}
+
+// withInput opens the --from archive, or materializes the --from-image or
+// remote ([user@]host:/path) input as a temporary directory, for the
+// processor. The returned function closes or removes it.
+func withInput(opts *Options) (input, func(), error) {
+	if len(opts.ImagePaths) > 0 && opts.FromImage == "" {
+		return input{}, nil, validationError(errors.New("--image-path requires --from-image"))
+	}
+
+	var extracted *source.Extracted
+	var err error
+	var settings string
+	switch {
+	case opts.From != "" && opts.FromImage != "":
+		return input{}, nil, validationError(errors.New("--from and --from-image can't be used together"))
+	case opts.From != "":
+		fmt.Println(style.Dim(fmt.Sprintf("Reading '%s'...", opts.From)))
+		archive, err := source.Archive(opts.From)
+		if err != nil {
+			return input{}, nil, validationError(fmt.Errorf("unable to read '%s': %w", opts.From, err))
+		}
+		return input{fsys: archive}, func() { _ = archive.Close() }, nil
+	case opts.FromImage != "":
+		fmt.Println(style.Dim(fmt.Sprintf("Exporting image '%s'...", opts.FromImage)))
+		if extracted, err = source.Image(opts.FromImage, opts.ImagePaths); err != nil {
+			return input{}, nil, fmt.Errorf("unable to read image '%s': %w", opts.FromImage, err)
+		}
+	case source.IsRemote(opts.Directory):
+		fmt.Println(style.Dim(fmt.Sprintf("Copying '%s'...", opts.Directory)))
+		if extracted, err = source.Remote(opts.Directory, remoteFilter(opts)); err != nil {
+			return input{}, nil, fmt.Errorf("unable to read '%s': %w", opts.Directory, err)
+		}
+		// Project settings come from the current directory
+		settings = "."
+	default:
+		return input{}, func() {}, nil
+	}
+	return input{dir: extracted.Dir, settings: settings}, func() { _ = extracted.Close() }, nil
+}
+
+// remoteFilter selects the remote files to transfer using the ignore rules,
+// fetched into dir beforehand.
+func remoteFilter(opts *Options) func(dir string, files []string) []string {
+	return func(dir string, files []string) []string {
+		cfg, err := config.New("")
+		if err != nil {
+			return files
+		}
+		p, err := processor.NewWithOptions(dir, opts.OutputFile, opts.IgnoreFile, processor.SandwormOptions{
+			DefaultIgnores:   resolveString(opts.DefaultIgnores, cfg, "processor.default_ignores", "all"),
+			GlobalIgnoreFile: globalIgnoreFile(cfg),
+			ExcludePatterns:  opts.Exclude,
+			IncludePatterns:  opts.Include,
+		})
+		if err != nil {
+			return files
+		}
+		var kept []string
+		for _, file := range files {
+			if !p.Ignored(file) {
+				kept = append(kept, file)
+			}
+		}
+		return kept
+	}
+}
+
+// windowsPath returns the Windows path of a file on a Windows drive mounted
+// in WSL (e.g. to upload it from a Windows browser), or "" for other files.
+func windowsPath(name string) string {
+	if !wsl.Detected() {
+		return ""
+	}
+	abs, err := filepath.Abs(name)
+	if err != nil {
+		return ""
+	}
+	win, _ := wsl.ToWindows(abs, wsl.MountRoot())
+	return win
+}
+
+// globalIgnoreFile returns the path of the user's ignore file, merged into
+// every project's ignore patterns.
+func globalIgnoreFile(cfg *config.Config) string {
+	return filepath.Join(cfg.Dir(), "ignore")
+}
+
+// maxInjectionFindings is the number of suspicious lines shown per file.
+const maxInjectionFindings = 3
+
+// printInjectionReport lists the files flagged by the prompt-injection scan.
+func printInjectionReport(results []processor.InjectionResult) {
+	fmt.Println(style.Warning(fmt.Sprintf("Possible prompt injection in %d files, review before sharing:", len(results))))
+	for _, result := range results {
+		for i, finding := range result.Findings {
+			if i == maxInjectionFindings {
+				fmt.Println(style.Dim(fmt.Sprintf("  ... and %d more in %s", len(result.Findings)-i, result.Path)))
+				break
+			}
+			fmt.Printf("  %s:%d %s %s\n", result.Path, finding.Line, style.Dim("["+finding.Rule+"]"), finding.Snippet)
+		}
+	}
+}
+
+// writeLicenseReport writes the license compliance report of the last run.
+func writeLicenseReport(p *processor.Processor, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("unable to create license report: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	if err := p.WriteLicenseReport(f); err != nil {
+		return fmt.Errorf("unable to write license report: %w", err)
+	}
+	return f.Close()
+}
+
+// resolveBool resolves a boolean option: the CLI flag wins if it was set, then
+// the project config, then the default.
+func resolveBool(flag *bool, cfg *config.Config, key string, def bool) bool {
+	if flag != nil {
+		return *flag
+	}
+	if cfg.Has(key) {
+		return cfg.Get(key) == "true"
+	}
+	return def
+}
+
+// resolveInt resolves an integer option: the CLI flag wins if it was set, then
+// the project config (if valid), then the default.
+func resolveInt(flag *int, cfg *config.Config, key string, def int) int {
+	if flag != nil {
+		return *flag
+	}
+	if n, err := strconv.Atoi(cfg.Get(key)); err == nil && cfg.Has(key) {
+		return n
+	}
+	return def
+}
+
+// resolveDuration resolves a duration from config, falling back to the default
+func resolveDuration(cfg *config.Config, key string, def time.Duration) time.Duration {
+	if d, err := time.ParseDuration(cfg.Get(key)); err == nil && cfg.Has(key) {
+		return d
+	}
+	return def
+}
+
+// resolveFileHeader resolves the file header template: the --header-style
+// flag wins, then a custom template from the project config, then the
+// configured header style.
+func resolveFileHeader(flag string, cfg *config.Config) (string, error) {
+	if flag == "" && cfg.Has("processor.file_header") {
+		return cfg.Get("processor.file_header"), nil
+	}
+	return processor.HeaderTemplate(resolveString(flag, cfg, "processor.header_style", processor.HeaderFull))
+}
+
+// splitList splits a comma-separated config value, dropping empty items.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// resolveString resolves a string option: the CLI flag wins if it was set
+// (non-empty), then the project config, then the default.
+func resolveString(flag string, cfg *config.Config, key, def string) string {
+	if flag != "" {
+		return flag
+	}
+	if cfg.Has(key) {
+		return cfg.Get(key)
+	}
+	return def
 }
@@ -0,0 +1,159 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/holonoms/sandworm/internal/style"
+	"github.com/holonoms/sandworm/internal/suggest"
+	"github.com/holonoms/sandworm/internal/util"
+	"github.com/spf13/cobra"
+)
+
+// newIgnoreCmd creates the ignore command and its subcommands
+func newIgnoreCmd(opts *Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ignore",
+		Short: "Manage ignore rules",
+	}
+
+	cmd.AddCommand(
+		newIgnoreSuggestCmd(opts),
+	)
+
+	return cmd
+}
+
+func newIgnoreSuggestCmd(opts *Options) *cobra.Command {
+	var largeKB int64
+
+	cmd := &cobra.Command{
+		Use:   "suggest [directory]",
+		Short: "Suggest ignore rules for vendored, generated, binary and large files",
+		Long: `Analyze the files that would currently be bundled and propose ignore rules
+for vendored directories, generated code, binary files and the largest files.
+Accepted rules are appended to the ignore file (.sandwormignore by default).`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				opts.Directory = args[0]
+			}
+			opts.SetDefaults()
+			return runIgnoreSuggest(opts, largeKB*1024)
+		},
+	}
+
+	cmd.Flags().Int64Var(&largeKB, "large", 100, "Suggest files at least this big (in KB)")
+
+	return cmd
+}
+
+func runIgnoreSuggest(opts *Options, largeSize int64) error {
+	p, err := newProcessor(opts, generateOptions{OutputFile: opts.OutputFile}, input{})
+	if err != nil {
+		return err
+	}
+	infos, err := p.Files(interrupt)
+	if err != nil {
+		return fmt.Errorf("unable to collect files: %w", err)
+	}
+
+	files := make([]suggest.File, 0, len(infos))
+	for _, info := range infos {
+		if info.TreeOnly {
+			continue
+		}
+		stat, err := os.Stat(info.AbsolutePath)
+		if err != nil {
+			continue
+		}
+		files = append(files, suggest.File{Path: info.RelativePath, AbsPath: info.AbsolutePath, Size: stat.Size()})
+	}
+
+	suggestions := suggest.Analyze(files, suggest.Options{LargeFileSize: largeSize, MaxLargeFiles: 10})
+	if len(suggestions) == 0 {
+		fmt.Println("No suggestions, the bundle looks lean.")
+		return ErrNothingToDo
+	}
+
+	var accepted []string
+	for _, s := range suggestions {
+		noun := "files"
+		if s.Files == 1 {
+			noun = "file"
+		}
+		question := fmt.Sprintf("Ignore %s (%s, %d %s, %s)?", s.Pattern, s.Reason, s.Files, noun, util.FormatSize(s.Size))
+		ok, err := confirm(question, opts.AssumeYes)
+		if err != nil {
+			return err
+		}
+		if ok {
+			accepted = append(accepted, s.Pattern)
+		}
+	}
+	if len(accepted) == 0 {
+		fmt.Println("No rules added.")
+		return nil
+	}
+
+	target := opts.IgnoreFile
+	if target == "" {
+		target = filepath.Join(opts.Directory, ".sandwormignore")
+	}
+	if err := appendIgnoreRules(target, filepath.Join(opts.Directory, ".gitignore"), accepted); err != nil {
+		return err
+	}
+
+	noun := "rules"
+	if len(accepted) == 1 {
+		noun = "rule"
+	}
+	fmt.Println(style.Success(fmt.Sprintf("Added %d %s to %s", len(accepted), noun, target)))
+	return nil
+}
+
+// MARK: Helpers
+
+// appendIgnoreRules appends rules to an ignore file. A new file is seeded
+// with the rules of fallback (the .gitignore), which it replaces.
+func appendIgnoreRules(target, fallback string, rules []string) error {
+	var b strings.Builder
+
+	existing, err := os.ReadFile(target)
+	switch {
+	case err == nil:
+		if len(existing) > 0 && !strings.HasSuffix(string(existing), "\n") {
+			b.WriteString("\n")
+		}
+	case os.IsNotExist(err):
+		// .sandwormignore takes precedence over .gitignore, so keep its rules
+		if data, err := os.ReadFile(fallback); err == nil {
+			fmt.Fprintf(&b, "# Rules from %s\n%s", filepath.Base(fallback), data)
+			if !strings.HasSuffix(string(data), "\n") {
+				b.WriteString("\n")
+			}
+		}
+	default:
+		return fmt.Errorf("unable to read %s: %w", target, err)
+	}
+
+	if len(existing) > 0 || b.Len() > 0 {
+		b.WriteString("\n")
+	}
+	b.WriteString("# Added by 'sandworm ignore suggest'\n")
+	for _, rule := range rules {
+		b.WriteString(rule + "\n")
+	}
+
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("unable to open %s: %w", target, err)
+	}
+	defer func() { _ = f.Close() }()
+	if _, err := f.WriteString(b.String()); err != nil {
+		return fmt.Errorf("unable to write %s: %w", target, err)
+	}
+	return nil
+}
@@ -0,0 +1,216 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// instructionsFile is the conventional location for version-controlled
+// project instructions.
+const instructionsFile = "PROJECT_INSTRUCTIONS.md"
+
+// newInstructionsCmd creates the instructions command and its subcommands
+func newInstructionsCmd(opts *Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "instructions",
+		Short: "Manage the Claude project's custom instructions",
+	}
+
+	cmd.AddCommand(
+		newInstructionsGetCmd(opts),
+		newInstructionsSetCmd(opts),
+		newInstructionsEditCmd(opts),
+	)
+
+	return cmd
+}
+
+func newInstructionsGetCmd(opts *Options) *cobra.Command {
+	var outputFile string
+	cmd := &cobra.Command{
+		Use:   "get",
+		Short: "Print the project instructions",
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return runInstructionsGet(opts, outputFile)
+		},
+	}
+
+	cmd.Flags().StringVarP(&outputFile, "output", "o", "", "Write instructions to a file (e.g. "+instructionsFile+")")
+
+	return cmd
+}
+
+func runInstructionsGet(opts *Options, outputFile string) error {
+	client, err := setupClaudeClient(false, opts)
+	if err != nil {
+		return err
+	}
+
+	instructions, err := client.GetInstructions()
+	if err != nil {
+		return fmt.Errorf("unable to get instructions: %w", err)
+	}
+
+	if outputFile == "" {
+		fmt.Println(instructions)
+		return nil
+	}
+
+	if err := os.WriteFile(outputFile, []byte(instructions), 0o644); err != nil {
+		return fmt.Errorf("unable to write instructions: %w", err)
+	}
+	fmt.Printf("Saved instructions to '%s'\n", outputFile)
+	return nil
+}
+
+func newInstructionsSetCmd(opts *Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set [file]",
+		Short: "Set the project instructions from a file (default: " + instructionsFile + ", '-' for stdin)",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			file := instructionsFile
+			if len(args) > 0 {
+				file = args[0]
+			}
+			return runInstructionsSet(opts, file)
+		},
+	}
+
+	return cmd
+}
+
+func runInstructionsSet(opts *Options, file string) error {
+	if err := checkWritable("instructions set"); err != nil {
+		return err
+	}
+	var (
+		content []byte
+		err     error
+	)
+	if file == "-" {
+		content, err = io.ReadAll(os.Stdin)
+	} else {
+		content, err = os.ReadFile(file)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to read instructions: %w", err)
+	}
+
+	client, err := setupClaudeClient(false, opts)
+	if err != nil {
+		return err
+	}
+
+	if err := client.SetInstructions(string(content)); err != nil {
+		return fmt.Errorf("unable to set instructions: %w", err)
+	}
+
+	fmt.Println("Updated project instructions")
+	return nil
+}
+
+func newInstructionsEditCmd(opts *Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "edit",
+		Short: "Edit the project instructions in $EDITOR",
+		Long: "Edit the project instructions in $EDITOR. If " + instructionsFile + " exists, it is edited\n" +
+			"in place (keeping it the source of truth) and then uploaded.",
+		Args: cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return runInstructionsEdit(opts)
+		},
+	}
+
+	return cmd
+}
+
+func runInstructionsEdit(opts *Options) error {
+	if err := checkWritable("instructions edit"); err != nil {
+		return err
+	}
+	client, err := setupClaudeClient(false, opts)
+	if err != nil {
+		return err
+	}
+
+	// Prefer the version-controlled file; otherwise edit a temporary copy of
+	// the remote instructions.
+	file := instructionsFile
+	if _, err := os.Stat(file); errors.Is(err, fs.ErrNotExist) {
+		current, err := client.GetInstructions()
+		if err != nil {
+			return fmt.Errorf("unable to get instructions: %w", err)
+		}
+		tmp, err := os.CreateTemp("", "sandworm-instructions-*.md")
+		if err != nil {
+			return fmt.Errorf("unable to create temp file: %w", err)
+		}
+		defer func() { _ = os.Remove(tmp.Name()) }()
+		if _, err := tmp.WriteString(current); err != nil {
+			_ = tmp.Close()
+			return fmt.Errorf("unable to write temp file: %w", err)
+		}
+		if err := tmp.Close(); err != nil {
+			return fmt.Errorf("unable to write temp file: %w", err)
+		}
+		file = tmp.Name()
+	}
+
+	before, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("unable to read instructions: %w", err)
+	}
+	if err := openEditor(file); err != nil {
+		return err
+	}
+	after, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("unable to read instructions: %w", err)
+	}
+
+	if string(before) == string(after) && file != instructionsFile {
+		fmt.Println("No changes.")
+		return nil
+	}
+
+	if err := client.SetInstructions(string(after)); err != nil {
+		return fmt.Errorf("unable to set instructions: %w", err)
+	}
+	fmt.Println("Updated project instructions")
+	return nil
+}
+
+// openEditor opens a file in the user's editor and waits for it to exit.
+func openEditor(file string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+		if runtime.GOOS == "windows" {
+			editor = "notepad"
+		}
+	}
+
+	// Editors are often configured with arguments (e.g. "code --wait")
+	parts := strings.Fields(editor)
+	cmd := exec.Command(parts[0], append(parts[1:], file)...) //nolint:gosec // user-configured editor
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("editor failed: %w", err)
+	}
+	return nil
+}
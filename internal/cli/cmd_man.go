@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/holonoms/sandworm/internal/manpage"
+	"github.com/holonoms/sandworm/internal/style"
+	"github.com/spf13/cobra"
+)
+
+// newManCmd creates the (hidden) man command, run at build time to generate
+// the man pages shipped with releases.
+func newManCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "man <directory>",
+		Short: "Generate the man pages of sandworm's commands and help topics",
+		Long: `Generate the man pages of sandworm's commands (section 1) and help topics
+(section 7) in a directory. Pages are dated from SOURCE_DATE_EPOCH if set, for
+reproducible builds.`,
+		Example: `  sandworm man man/
+  MANPATH=man: man sandworm-push`,
+		Hidden: true,
+		Args:   cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runMan(cmd.Root(), args[0])
+		},
+	}
+
+	return cmd
+}
+
+func runMan(root *cobra.Command, dir string) error {
+	date := time.Now()
+	if epoch := os.Getenv("SOURCE_DATE_EPOCH"); epoch != "" {
+		seconds, err := strconv.ParseInt(epoch, 10, 64)
+		if err != nil {
+			return validationError(fmt.Errorf("invalid SOURCE_DATE_EPOCH: %s", epoch))
+		}
+		date = time.Unix(seconds, 0).UTC()
+	}
+
+	if err := manpage.Generate(root, dir, date); err != nil {
+		return fmt.Errorf("unable to generate man pages: %w", err)
+	}
+	fmt.Println(style.Success(fmt.Sprintf("Generated man pages in %s", dir)))
+	return nil
+}
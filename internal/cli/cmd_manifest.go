@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/holonoms/sandworm/internal/manifest"
+	"github.com/holonoms/sandworm/internal/style"
+	"github.com/spf13/cobra"
+)
+
+// newManifestCmd creates the manifest command and its subcommands
+func newManifestCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "manifest",
+		Short: "Compare and verify generated files using their checksum manifest",
+		Long: `Work with the checksum manifest embedded in files generated with --manifest
+(or processor.checksum_manifest): the SHA-256 of each file's content, in the
+format of sha256sum.`,
+	}
+
+	cmd.AddCommand(
+		newManifestDiffCmd(),
+		newManifestVerifyCmd(),
+	)
+
+	return cmd
+}
+
+func newManifestDiffCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "diff <old> <new>",
+		Short: "List the files added, removed and modified between two generated files",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(_ *cobra.Command, args []string) error {
+			return runManifestDiff(args[0], args[1])
+		},
+	}
+
+	return cmd
+}
+
+func runManifestDiff(oldPath, newPath string) error {
+	old, err := readManifest(oldPath)
+	if err != nil {
+		return err
+	}
+	updated, err := readManifest(newPath)
+	if err != nil {
+		return err
+	}
+
+	changes := manifest.Diff(old, updated)
+	if changes.Empty() {
+		fmt.Println("No changes.")
+		return nil
+	}
+	for _, path := range changes.Added {
+		fmt.Println(style.Success("+ " + path))
+	}
+	for _, path := range changes.Removed {
+		fmt.Println(style.Error("- " + path))
+	}
+	for _, path := range changes.Modified {
+		fmt.Println(style.Warning("~ " + path))
+	}
+
+	return nil
+}
+
+func newManifestVerifyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "verify <file> [directory]",
+		Short: "Check that the files of a project still match a generated file",
+		Args:  cobra.RangeArgs(1, 2),
+		RunE: func(_ *cobra.Command, args []string) error {
+			dir := "."
+			if len(args) > 1 {
+				dir = args[1]
+			}
+			return runManifestVerify(args[0], dir)
+		},
+	}
+
+	return cmd
+}
+
+func runManifestVerify(path, dir string) error {
+	m, err := readManifest(path)
+	if err != nil {
+		return err
+	}
+
+	modified, missing := m.Verify(dir)
+	for _, file := range modified {
+		fmt.Println(style.Warning("modified: " + file))
+	}
+	for _, file := range missing {
+		fmt.Println(style.Error("missing:  " + file))
+	}
+	if len(modified)+len(missing) > 0 {
+		return fmt.Errorf("%d of %d files don't match '%s'", len(modified)+len(missing), len(m), path)
+	}
+
+	fmt.Println(style.Success(fmt.Sprintf("All %d files match '%s'", len(m), path)))
+	return nil
+}
+
+// MARK: Helpers
+
+// readManifest reads the checksum manifest of a generated file.
+func readManifest(path string) (manifest.Manifest, error) {
+	m, err := manifest.ReadFile(path)
+	if err != nil {
+		return nil, validationError(fmt.Errorf("unable to read manifest of '%s': %w", path, err))
+	}
+	return m, nil
+}
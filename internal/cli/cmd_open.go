@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+
+	"github.com/holonoms/sandworm/internal/claude"
+	"github.com/holonoms/sandworm/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// newOpenCmd creates the open command
+func newOpenCmd(opts *Options) *cobra.Command {
+	var printOnly bool
+
+	cmd := &cobra.Command{
+		Use:   "open",
+		Short: "Open the Claude project in the browser",
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return runOpen(opts, printOnly)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&printOnly, "print", "p", false, "Print the project URL instead of opening it")
+
+	return cmd
+}
+
+func runOpen(opts *Options, printOnly bool) error {
+	url, err := projectURL(opts)
+	if err != nil {
+		return err
+	}
+
+	if printOnly {
+		fmt.Println(url)
+		return nil
+	}
+
+	fmt.Printf("Opening %s\n", url)
+	return openBrowser(url)
+}
+
+// MARK: Helpers
+
+// projectURL resolves the URL of the target project. The configured project
+// doesn't need any API call; --org/--project overrides are resolved through
+// the client, as they may be names.
+func projectURL(opts *Options) (string, error) {
+	if opts.Organization != "" || opts.Project != "" {
+		client, err := setupClaudeClient(false, opts)
+		if err != nil {
+			return "", err
+		}
+		return client.ProjectURL(), nil
+	}
+
+	cfg, err := config.New(".")
+	if err != nil {
+		return "", fmt.Errorf("unable to load config: %w", err)
+	}
+	id := cfg.Get("claude.project_id")
+	if id == "" {
+		return "", validationError(errors.New("no Claude project configured, run 'sandworm setup' first"))
+	}
+	return claude.ProjectURL(id), nil
+}
+
+// openBrowser opens a URL with the platform's default handler.
+func openBrowser(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("unable to open browser (visit %s): %w", url, err)
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package cli
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"github.com/holonoms/sandworm/internal/config"
+	"github.com/holonoms/sandworm/internal/picker"
+	"github.com/holonoms/sandworm/internal/source"
+	"github.com/holonoms/sandworm/internal/stats"
+	"github.com/holonoms/sandworm/internal/style"
+	"github.com/holonoms/sandworm/internal/util"
+	"github.com/spf13/cobra"
+)
+
+// newPickCmd creates the pick command
+func newPickCmd(opts *Options) *cobra.Command {
+	var save string
+	var push bool
+
+	cmd := &cobra.Command{
+		Use:   "pick [directory]",
+		Short: "Hand-pick the files to bundle in a terminal UI",
+		Long: `Hand-pick the files to bundle from a tree with checkboxes, pre-populated
+from the ignore rules (or the --save target, if it exists).
+
+Keys: arrows/hjkl to move and expand, space to toggle, a to toggle all,
+enter to confirm, q to cancel.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				opts.Directory = args[0]
+			}
+			opts.SetDefaults()
+			return runPick(opts, save, push)
+		},
+	}
+
+	cmd.Flags().StringVar(&save, "save", "", "Save the selection as a named target (use with --target)")
+	cmd.Flags().BoolVar(&push, "push", false, "Push the selection to Claude instead of generating a file")
+
+	return cmd
+}
+
+func runPick(opts *Options, save string, push bool) error {
+	if opts.From != "" || opts.FromImage != "" {
+		return validationError(errors.New("--from/--from-image can't be used with pick"))
+	}
+	if source.IsRemote(opts.Directory) {
+		return validationError(errors.New("remote directories can't be used with pick"))
+	}
+	cfg, err := config.New(opts.Directory)
+	if err != nil {
+		return fmt.Errorf("unable to load config: %w", err)
+	}
+
+	paths, err := listFiles(opts.Directory)
+	if err != nil {
+		return fmt.Errorf("unable to list files: %w", err)
+	}
+
+	// Pre-populate from the saved target when updating it, otherwise from the
+	// files the ignore rules would bundle.
+	if save != "" && cfg.Has("targets."+save) {
+		opts.Target = save
+	}
+	p, err := newProcessor(opts, generateOptions{OutputFile: opts.OutputFile}, input{})
+	if err != nil {
+		return err
+	}
+	files, err := p.Files(interrupt)
+	if err != nil {
+		return fmt.Errorf("unable to collect files: %w", err)
+	}
+	preselected := make(map[string]bool, len(files))
+	for _, file := range files {
+		if !file.TreeOnly {
+			preselected[file.RelativePath] = true
+		}
+	}
+
+	selection, err := picker.Run(paths, preselected)
+	if errors.Is(err, picker.ErrCancelled) {
+		fmt.Println(style.Dim("Selection cancelled."))
+		return ErrNothingToDo
+	}
+	if err != nil {
+		return err
+	}
+	if len(selection) == 0 {
+		fmt.Println(style.Dim("No files selected."))
+		return ErrNothingToDo
+	}
+	opts.Files = selection
+
+	if save != "" {
+		if err := cfg.Set("targets."+save, strings.Join(selection, ",")); err != nil {
+			return fmt.Errorf("unable to save target: %w", err)
+		}
+		fmt.Println(style.Success(fmt.Sprintf("Saved %d files as target '%s' (use --target %s)", len(selection), save, save)))
+	}
+
+	if push {
+		return runPush(opts, pushOptions{})
+	}
+
+	genOpts := generateOptions{OutputFile: cmp.Or(opts.OutputFile, defaultOutputFile)}
+	fmt.Printf("Generating project '%s'...\n", genOpts.OutputFile)
+	gen, err := runGenerate(opts, genOpts)
+	if err == nil {
+		recordStats(opts, stats.ActionGenerate, gen)
+		fmt.Println(style.Success(fmt.Sprintf("Generated '%s' (%s)", gen.Path, util.FormatSize(gen.Size))))
+	}
+	return err
+}
+
+// MARK: Helpers
+
+// listFiles returns every file under dir (slash-separated, relative paths),
+// ignored or not, skipping git's internal directory.
+func listFiles(dir string) ([]string, error) {
+	var paths []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		paths = append(paths, filepath.ToSlash(rel))
+		return nil
+	})
+	return paths, err
+}
+
+// targetFiles returns the files of a target saved with 'sandworm pick'.
+func targetFiles(cfg *config.Config, name string) ([]string, error) {
+	key := "targets." + name
+	if !cfg.Has(key) || cfg.Get(key) == "" {
+		return nil, validationError(fmt.Errorf("unknown target '%s' (save one with 'sandworm pick --save %s')", name, name))
+	}
+	return strings.Split(cfg.Get(key), ","), nil
+}
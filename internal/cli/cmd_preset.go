@@ -0,0 +1,224 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/holonoms/sandworm/internal/config"
+	"github.com/holonoms/sandworm/internal/style"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// newPresetCmd creates the preset command and its subcommands
+func newPresetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "preset",
+		Short: "Manage named presets of flags (for use with --preset)",
+	}
+
+	cmd.AddCommand(
+		newPresetSaveCmd(),
+		newPresetListCmd(),
+		newPresetRemoveCmd(),
+	)
+
+	return cmd
+}
+
+func newPresetSaveCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "save <name> [flags]",
+		Short: "Save the given flags as a preset",
+		Example: `  sandworm preset save review --header-style short --symbol-index
+  sandworm generate --preset review`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPresetSave(args[0], flagArgs(cmd.Flags(), "preset"))
+		},
+	}
+
+	return cmd
+}
+
+func runPresetSave(name string, flags []string) error {
+	if len(flags) == 0 {
+		return validationError(errors.New("no flags given, e.g. 'sandworm preset save review --header-style short'"))
+	}
+
+	cfg, err := config.New(".")
+	if err != nil {
+		return fmt.Errorf("unable to load config: %w", err)
+	}
+	if err := cfg.Set("presets."+name, formatPresetArgs(flags)); err != nil {
+		return fmt.Errorf("unable to save preset: %w", err)
+	}
+
+	fmt.Println(style.Success(fmt.Sprintf("Saved preset '%s': %s", name, formatPresetArgs(flags))))
+	return nil
+}
+
+func newPresetListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List saved presets",
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return runPresetList()
+		},
+	}
+
+	return cmd
+}
+
+func runPresetList() error {
+	cfg, err := config.New(".")
+	if err != nil {
+		return fmt.Errorf("unable to load config: %w", err)
+	}
+
+	names := cfg.Keys("presets")
+	if len(names) == 0 {
+		fmt.Println("No presets saved. Run 'sandworm preset save <name> [flags]' to add one.")
+		return nil
+	}
+	for _, name := range names {
+		fmt.Printf("%s %s\n", name, style.Dim(cfg.Get("presets."+name)))
+	}
+
+	return nil
+}
+
+func newPresetRemoveCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "remove <name>",
+		Short: "Remove a preset",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			return runPresetRemove(args[0])
+		},
+	}
+
+	return cmd
+}
+
+func runPresetRemove(name string) error {
+	cfg, err := config.New(".")
+	if err != nil {
+		return fmt.Errorf("unable to load config: %w", err)
+	}
+	if !cfg.Has("presets." + name) {
+		return validationError(fmt.Errorf("unknown preset '%s'", name))
+	}
+	if err := cfg.Delete("presets." + name); err != nil {
+		return fmt.Errorf("unable to remove preset: %w", err)
+	}
+
+	fmt.Println(style.Success(fmt.Sprintf("Removed preset '%s'", name)))
+	return nil
+}
+
+// applyPreset sets the flags saved in a preset on cmd. Flags given on the
+// command line take precedence.
+func applyPreset(cmd *cobra.Command, name string) error {
+	cfg, err := config.New(".")
+	if err != nil {
+		return fmt.Errorf("unable to load config: %w", err)
+	}
+	if !cfg.Has("presets." + name) {
+		return validationError(fmt.Errorf("unknown preset '%s' (see 'sandworm preset list')", name))
+	}
+
+	flags, err := parsePresetArgs(cfg.Get("presets." + name))
+	if err != nil {
+		return validationError(fmt.Errorf("invalid preset '%s': %w", name, err))
+	}
+	for _, flag := range flags {
+		flagName, value, _ := strings.Cut(strings.TrimPrefix(flag, "--"), "=")
+		if cmd.Flags().Changed(flagName) {
+			continue
+		}
+		if err := cmd.Flags().Set(flagName, value); err != nil {
+			return validationError(fmt.Errorf("invalid preset '%s': --%s: %w", name, flagName, err))
+		}
+	}
+
+	return nil
+}
+
+// MARK: Helpers
+
+// flagArgs returns the flags set on the command line as --name=value
+// arguments, except for the skipped ones. Repeatable flags are given once per
+// value.
+func flagArgs(flags *pflag.FlagSet, skip ...string) []string {
+	var args []string
+	flags.Visit(func(f *pflag.Flag) {
+		if slices.Contains(skip, f.Name) {
+			return
+		}
+		if values, ok := f.Value.(pflag.SliceValue); ok {
+			for _, value := range values.GetSlice() {
+				args = append(args, fmt.Sprintf("--%s=%s", f.Name, value))
+			}
+			return
+		}
+		args = append(args, fmt.Sprintf("--%s=%s", f.Name, f.Value.String()))
+	})
+	return args
+}
+
+// formatPresetArgs joins flags with spaces, quoting the ones containing
+// whitespace or quotes.
+func formatPresetArgs(flags []string) string {
+	quoted := make([]string, len(flags))
+	for i, flag := range flags {
+		if strings.ContainsAny(flag, " \t\"") {
+			flag = strconv.Quote(flag)
+		}
+		quoted[i] = flag
+	}
+	return strings.Join(quoted, " ")
+}
+
+// parsePresetArgs splits flags formatted with formatPresetArgs.
+func parsePresetArgs(s string) ([]string, error) {
+	flags, err := splitArgs(s)
+	if err != nil {
+		return nil, err
+	}
+	for _, flag := range flags {
+		if !strings.HasPrefix(flag, "--") {
+			return nil, fmt.Errorf("expected a flag, got: %s", flag)
+		}
+	}
+	return flags, nil
+}
+
+// splitArgs splits arguments formatted with formatPresetArgs: separated by
+// whitespace, and double-quoted (Go syntax) if they contain some.
+func splitArgs(s string) ([]string, error) {
+	var args []string
+	for s = strings.TrimSpace(s); s != ""; s = strings.TrimSpace(s) {
+		if s[0] == '"' {
+			quoted, err := strconv.QuotedPrefix(s)
+			if err != nil {
+				return nil, err
+			}
+			arg, _ := strconv.Unquote(quoted)
+			args = append(args, arg)
+			s = s[len(quoted):]
+			continue
+		}
+		end := strings.IndexAny(s, " \t")
+		if end < 0 {
+			end = len(s)
+		}
+		args = append(args, s[:end])
+		s = s[end:]
+	}
+	return args, nil
+}
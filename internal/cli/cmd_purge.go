@@ -3,43 +3,99 @@ package cli
 import (
 	"fmt"
 
+	"github.com/holonoms/sandworm/internal/audit"
+	"github.com/holonoms/sandworm/internal/claude"
+	"github.com/holonoms/sandworm/internal/source"
+	"github.com/holonoms/sandworm/internal/style"
 	"github.com/spf13/cobra"
 )
 
 // newPurgeCmd creates the purge command
-func newPurgeCmd() *cobra.Command {
+func newPurgeCmd(opts *Options) *cobra.Command {
+	var olderThan string
+	var filter claude.PurgeFilter
+
 	cmd := &cobra.Command{
 		Use:   "purge",
-		Short: "Remove all files from Claude project",
+		Short: "Remove the files sandworm uploaded from Claude project",
+		Long: `Remove the files sandworm uploaded from the Claude project, or only some of
+them: those uploaded longer ago than --older-than (e.g. 7d, 12h), and/or
+whose name starts with --prefix. Knowledge files uploaded by hand or by
+other tools are left alone, unless --all is given.
+
+Documents whose upload time is unknown are kept when filtering by age.`,
+		Example: `  sandworm purge
+  sandworm purge --older-than 30d
+  sandworm purge --prefix sandworm- --older-than 7d
+  sandworm purge --all`,
 		RunE: func(_ *cobra.Command, _ []string) error {
-			return runPurge()
+			if olderThan != "" {
+				age, err := source.ParseMaxAge(olderThan)
+				if err != nil {
+					return validationError(fmt.Errorf("invalid --older-than: %w", err))
+				}
+				filter.OlderThan = age
+			}
+			return runPurge(opts, filter)
 		},
 	}
 
+	cmd.Flags().StringVar(&olderThan, "older-than", "", "Only remove files uploaded longer ago than this (e.g. 7d, 12h)")
+	cmd.Flags().StringVar(&filter.Prefix, "prefix", "", "Only remove files whose name starts with this")
+	cmd.Flags().BoolVar(&filter.All, "all", false, "Also remove files sandworm didn't upload (by hand, or by other tools)")
+
 	return cmd
 }
 
-func runPurge() error {
-	client, err := setupClaudeClient(false)
+func runPurge(opts *Options, filter claude.PurgeFilter) error {
+	if err := checkWritable("purge"); err != nil {
+		return err
+	}
+	client, err := setupClaudeClient(false, opts)
+	if err != nil {
+		return err
+	}
+
+	docs, err := client.PurgeCandidates(filter)
 	if err != nil {
 		return err
 	}
+	if len(docs) == 0 {
+		if filter != (claude.PurgeFilter{}) {
+			fmt.Println("No files match the filters.")
+		} else {
+			fmt.Println("No files to delete.")
+		}
+		return ErrNothingToDo
+	}
+
+	orgName, projectName := client.TargetNames()
+	fmt.Printf("%s project '%s' in org '%s'\n", style.Header("Target:"), style.Info(projectName), style.Info(orgName))
+	for _, doc := range docs {
+		fmt.Printf("  - %s\n", doc.FileName)
+	}
+	if err := confirmOrCancel(fmt.Sprintf("Delete %d file(s)?", len(docs)), "purge", opts); err != nil {
+		return err
+	}
 
-	count, err := client.PurgeProjectFiles(func(filename string, current, total int) {
-		fmt.Printf("%d/%d: Deleting '%s'...\n", current, total, filename)
+	removed, err := client.RemoveDocuments(docs, func(filename string, current, total int) {
+		fmt.Println(style.Dim(fmt.Sprintf("%d/%d: Deleting '%s'...", current, total, filename)))
 	})
+	if len(removed) > 0 {
+		recordAudit(client, audit.ActionPurge, opts.Directory, removed, "")
+	}
 	if err != nil {
 		return err
 	}
 
-	if count == 0 {
+	if count := len(removed); count == 0 {
 		fmt.Println("No files to delete.")
 	} else {
 		suffix := ""
 		if count > 1 {
 			suffix = "s"
 		}
-		fmt.Printf("Done! Removed %d file%s\n", count, suffix)
+		fmt.Println(style.Success(fmt.Sprintf("Done! Removed %d file%s", count, suffix)))
 	}
 
 	return nil
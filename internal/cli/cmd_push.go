@@ -1,71 +1,321 @@
 package cli
 
 import (
+	"cmp"
+	"errors"
 	"fmt"
 	"os"
+	"slices"
+	"strings"
 	"time"
 
+	"github.com/holonoms/sandworm/internal/audit"
 	"github.com/holonoms/sandworm/internal/claude"
 	"github.com/holonoms/sandworm/internal/config"
+	"github.com/holonoms/sandworm/internal/profile"
+	"github.com/holonoms/sandworm/internal/stats"
+	"github.com/holonoms/sandworm/internal/style"
+	"github.com/holonoms/sandworm/internal/tokens"
 	"github.com/holonoms/sandworm/internal/util"
 	"github.com/spf13/cobra"
 )
 
+// pushOptions holds the options of the push command.
+type pushOptions struct {
+	Strict      bool             // Fail instead of warning when the bundle exceeds the budget.* thresholds
+	Prune       bool             // Delete remote documents that weren't pushed
+	Backup      *bool            // Back up remote documents before replacing them; if nil, the value from config will be used
+	DeleteFirst bool             // Delete the previous bundle before uploading the new one (see claude.Client.SetDeleteFirst)
+	Timings     *profile.Timings // Records the phases of a profiled run (see startProfiling); nil when not profiling
+}
+
 // newPushCmd creates the push command
 func newPushCmd(opts *Options) *cobra.Command {
+	var pushOpts pushOptions
+	var backup bool
+
 	cmd := &cobra.Command{
 		Use:   "push [directory]",
 		Short: "Generate and push to Claude",
-		RunE: func(_ *cobra.Command, args []string) error {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) > 0 {
 				opts.Directory = args[0]
 			}
-			// Default output for push
-			if opts.OutputFile == "" {
-				opts.OutputFile = fmt.Sprintf(".sandworm-%d.txt", time.Now().Unix())
+			if cmd.Flags().Changed("backup") {
+				pushOpts.Backup = &backup
 			}
-			return runPush(opts)
+			opts.SetDefaults()
+			timings, stopProfiling, err := startProfiling(opts)
+			if err != nil {
+				return err
+			}
+			defer stopProfiling()
+			pushOpts.Timings = timings
+			return runPush(opts, pushOpts)
 		},
 	}
 
+	cmd.Flags().BoolVar(&pushOpts.Strict, "strict", false, "Fail instead of warning when the bundle exceeds the budget.* thresholds")
+	cmd.Flags().BoolVar(&pushOpts.Prune, "prune", false, "Delete project documents other than the pushed ones, mirroring the local project")
+	cmd.Flags().BoolVar(&backup, "backup", false, "Back up the project's documents locally before replacing them, see 'sandworm restore' (overrides config setting)")
+	addDeleteFirstFlag(cmd, &pushOpts)
+
 	return cmd
 }
 
-func runPush(opts *Options) error {
-	client, err := setupClaudeClient(false)
+// addDeleteFirstFlag adds the flag reversing the replacement order of pushed
+// documents, shared by the push and deploy commands.
+func addDeleteFirstFlag(cmd *cobra.Command, pushOpts *pushOptions) {
+	cmd.Flags().BoolVar(&pushOpts.DeleteFirst, "delete-first", false, "Delete the previous bundle before uploading the new one, e.g. when the project's knowledge is full (the project is left without it if the upload fails)")
+}
+
+// runPush generates and pushes the project file, to a temporary file unless
+// --output is given, removed afterwards unless kept.
+func runPush(opts *Options, pushOpts pushOptions) error {
+	if err := checkWritable("push"); err != nil {
+		return err
+	}
+	client, err := setupClaudeClient(false, opts)
+	if err != nil {
+		return err
+	}
+	client.SetDeleteFirst(pushOpts.DeleteFirst)
+	policies, err := applyPolicies(opts, client)
 	if err != nil {
 		return err
 	}
 
+	orgName, projectName := client.TargetNames()
+	existing, err := client.ExistingDocument("project.txt")
+	if err != nil {
+		return err
+	}
+	// Parts of a bundle previously split with tokens.chunk_size
+	existingParts, err := existingBundleParts(client, "project.txt")
+	if err != nil {
+		return err
+	}
+	if replaced := slices.DeleteFunc(append([]string{existing}, existingParts...), func(name string) bool { return name == "" }); len(replaced) > 0 {
+		fmt.Printf("%s project '%s' in org '%s'\n", style.Header("Target:"), style.Info(projectName), style.Info(orgName))
+		question := fmt.Sprintf("Replace document '%s'?", replaced[0])
+		if len(replaced) > 1 {
+			question = fmt.Sprintf("Replace documents '%s'?", strings.Join(replaced, "', '"))
+		}
+		if err := confirmOrCancel(question, "push", opts); err != nil {
+			return err
+		}
+	}
+	if existing != "" {
+		changes, err := client.DocumentChanges()
+		if err != nil {
+			return err
+		}
+		if changes != "" {
+			fmt.Println(style.Warning(fmt.Sprintf("'%s' changed since sandworm last pushed it (%s); it may have been edited in the web interface.", existing, changes)))
+			// Asked even if claude.confirm is off: replacing it loses those edits
+			ok, err := confirm("Replace it anyway?", opts.AssumeYes)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return errors.New("push cancelled")
+			}
+		}
+	}
+
+	// Each push gets its own file, so that those of watch don't overwrite
+	// each other
+	outputFile := cmp.Or(opts.OutputFile, fmt.Sprintf(".sandworm-%d.txt", time.Now().Unix()))
 	defer func() {
 		// Clean up unless keepFile is true
 		if !opts.KeepFile {
-			_ = os.Remove(opts.OutputFile)
+			_ = os.Remove(outputFile)
 		}
 	}()
 
 	fmt.Println("Generating project file...")
-	var size int64
-	if size, err = runGenerate(opts); err != nil {
+	gen, err := runGenerate(opts, generateOptions{
+		OutputFile:   outputFile,
+		ScrubSecrets: requiresSecretScan(policies),
+		Timings:      pushOpts.Timings,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := checkPolicySize(policies, gen.Size); err != nil {
+		return err
+	}
+	if err := checkBudget(opts, gen, pushOpts.Strict); err != nil {
+		return err
+	}
+
+	parts, err := splitBundle(opts, gen, "project.txt")
+	if err != nil {
 		return err
 	}
 
-	if err := client.Push(opts.OutputFile, "project.txt"); err != nil {
+	if gen.Flagged > 0 {
+		// Asked even if claude.confirm is off: it's about content, not the target
+		ok, err := confirm(fmt.Sprintf("Push %d files flagged as possible prompt injection?", gen.Flagged), opts.AssumeYes)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errors.New("push cancelled")
+		}
+	}
+
+	if existing != "" || pushOpts.Prune {
+		if err := backupRemote(client, opts, pushOpts.Backup); err != nil {
+			return err
+		}
+	}
+
+	// Last point to stop at: once the upload starts, it's completed even if
+	// interrupted, along with the removal of the previous bundle. Parts are
+	// pushed as a whole, so a failed upload leaves the previous bundle as is
+	// rather than a mix of their parts (unless deleting it first).
+	if err := interrupted(); err != nil {
+		return err
+	}
+	fmt.Printf("Pushing to project '%s' in org '%s'...\n", style.Info(projectName), style.Info(orgName))
+	uploadStart := time.Now()
+	pushed := []string{"project.txt"}
+	if parts != nil {
+		pushed = pushed[:0]
+		for _, part := range parts {
+			pushed = append(pushed, part.Name)
+		}
+	}
+	// What's left of a bundle split differently (or not at all)
+	leftovers := slices.DeleteFunc(append([]string{existing}, existingParts...), func(name string) bool {
+		return name == "" || slices.Contains(pushed, name)
+	})
+	if pushOpts.DeleteFirst {
+		if err := deleteLeftovers(client, leftovers); err != nil {
+			return err
+		}
+		leftovers = nil
+	}
+	if parts == nil {
+		err = client.Push(gen.Path, "project.txt")
+	} else {
+		uploads := make([]claude.Upload, len(parts))
+		for i, part := range parts {
+			uploads[i] = claude.Upload{FileName: part.Name, Content: string(part.Content)}
+		}
+		err = client.PushDocuments(uploads, func(name string, current, total int) {
+			fmt.Printf("  %s %s\n", style.Dim(fmt.Sprintf("[%d/%d]", current, total)), name)
+		})
+	}
+	if err != nil && (errors.Is(err, claude.ErrPreviousKept) || len(leftovers) > 0) {
+		// The previous bundle counts towards the project's knowledge until
+		// the new one is uploaded
+		return fmt.Errorf("unable to push: %w; if the project's knowledge is full, the previous and new bundles don't fit at once: push again with --delete-first", err)
+	}
+	if err != nil {
 		return fmt.Errorf("unable to push: %w", err)
 	}
+	if err := deleteLeftovers(client, leftovers); err != nil {
+		return err
+	}
+	addTiming(pushOpts.Timings, "upload", uploadStart)
+	recordAudit(client, audit.ActionPush, opts.Directory, pushed, gen.Path)
+	recordStats(opts, stats.ActionPush, gen)
+
+	if parts == nil {
+		fmt.Println(style.Success(fmt.Sprintf("Updated project file (%s, %s)", util.FormatSize(gen.Size), tokens.Format(gen.Tokens))))
+	} else {
+		fmt.Println(style.Success(fmt.Sprintf("Updated %d project files (%s, %s)", len(parts), util.FormatSize(gen.Size), tokens.Format(gen.Tokens))))
+	}
+
+	if pushOpts.Prune {
+		if err := interrupted(); err != nil {
+			return err
+		}
+		return prune(client, opts, pushed)
+	}
+	return nil
+}
+
+// deleteLeftovers deletes the documents of a previous bundle that the pushed
+// one doesn't replace.
+func deleteLeftovers(client *claude.Client, leftovers []string) error {
+	if len(leftovers) == 0 {
+		return nil
+	}
+	if _, err := client.DeleteDocuments(leftovers); err != nil {
+		return fmt.Errorf("unable to remove previous bundle parts: %w", err)
+	}
+	return nil
+}
+
+// existingBundleParts returns the documents sandworm uploaded that are parts
+// of a bundle name, e.g. project-1.txt and project-2.txt for project.txt.
+func existingBundleParts(client *claude.Client, name string) ([]string, error) {
+	names, err := client.ManagedDocumentNames()
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(names, func(doc string) bool { return !isPartOf(doc, name) }), nil
+}
+
+// prune deletes the project's documents other than the pushed ones, after
+// confirmation.
+func prune(client *claude.Client, opts *Options, pushed []string) error {
+	stale, err := client.StaleDocuments(pushed)
+	if err != nil {
+		return err
+	}
+	if len(stale) == 0 {
+		return nil
+	}
 
-	fmt.Printf("Updated project file (%s)\n", util.FormatSize(size))
+	fmt.Printf("%s\n", style.Header("Stale documents:"))
+	for _, doc := range stale {
+		fmt.Printf("  - %s\n", doc.FileName)
+	}
+	if err := confirmOrCancel(fmt.Sprintf("Delete %d stale file(s)?", len(stale)), "prune", opts); err != nil {
+		return err
+	}
 
+	removed, err := client.RemoveDocuments(stale, func(filename string, current, total int) {
+		fmt.Println(style.Dim(fmt.Sprintf("%d/%d: Deleting '%s'...", current, total, filename)))
+	})
+	if len(removed) > 0 {
+		recordAudit(client, audit.ActionPrune, opts.Directory, removed, "")
+	}
+	if err != nil {
+		return fmt.Errorf("unable to prune: %w", err)
+	}
+	fmt.Println(style.Success(fmt.Sprintf("Pruned %d stale file(s)", len(removed))))
 	return nil
 }
 
-func setupClaudeClient(force bool) (*claude.Client, error) {
+// checkWritable fails in read-only mode (see --read-only), before a command
+// that changes the project does any work.
+func checkWritable(command string) error {
+	if claude.ReadOnly() {
+		return fmt.Errorf("%w: %s is disabled", claude.ErrReadOnly, command)
+	}
+	return nil
+}
+
+// setupClaudeClient creates a Claude client, prompting for any missing
+// configuration. Organization/project overrides are taken from opts, if given.
+func setupClaudeClient(force bool, opts *Options) (*claude.Client, error) {
 	conf, err := config.New(".")
 	if err != nil {
 		return nil, fmt.Errorf("unable to load config: %w", err)
 	}
 
 	client := claude.New(conf)
+	if opts != nil {
+		client.SetTarget(opts.Organization, opts.Project)
+		client.SetRefresh(opts.Refresh || opts.NoCache)
+		client.SetNoCache(opts.NoCache)
+	}
 	ok, err := client.Setup(force)
 	if err != nil {
 		return nil, err
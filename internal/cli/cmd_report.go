@@ -0,0 +1,162 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/holonoms/sandworm/internal/config"
+	"github.com/holonoms/sandworm/internal/daemon"
+	"github.com/holonoms/sandworm/internal/report"
+	"github.com/holonoms/sandworm/internal/style"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// reportLogLines is the number of command log lines included in reports.
+const reportLogLines = 20
+
+// unrecordedCommands aren't recorded in the command log: reports are about
+// the commands that ran before them.
+var unrecordedCommands = []string{"report", "help", "completion", cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd}
+
+// newReportCmd creates the report command
+func newReportCmd(opts *Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "report",
+		Short: "Write an anonymized report to attach to bug reports",
+		Long: `Write the sandworm version, platform, config, the last command and recent
+log lines to a single file to attach to bug reports. Only config values that
+can't identify you (modes, booleans, numbers) are shown, others are [SET].
+Commands are logged without their arguments, nor the values of their text
+flags. Credentials, emails, Claude IDs and your home directory are masked;
+review the file before sharing it.
+
+The report is written to sandworm-report-<time>.txt, or to the file given with
+-o ('-' for the standard output).`,
+		Args: cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			opts.SetDefaults()
+			return runReport(opts)
+		},
+	}
+
+	return cmd
+}
+
+func runReport(opts *Options) error {
+	cfg, err := config.New(opts.Directory)
+	if err != nil {
+		return fmt.Errorf("unable to load config: %w", err)
+	}
+
+	r := report.Report{Version: version}
+	for _, entry := range cfg.Entries() {
+		if strings.HasPrefix(entry.Key, "encryption.") {
+			continue
+		}
+		value := "[SET]"
+		if reportableValue(entry.Key, entry.Value) {
+			value = entry.Value
+		}
+		scope := "project"
+		if cfg.IsGlobalKey(entry.Key) {
+			scope = "global"
+		}
+		r.Config = append(r.Config, fmt.Sprintf("%s = %s (%s)", entry.Key, value, scope))
+	}
+	for _, env := range os.Environ() {
+		if name, _, _ := strings.Cut(env, "="); strings.HasPrefix(name, "SANDWORM_") {
+			r.Environment = append(r.Environment, name)
+		}
+	}
+	slices.Sort(r.Environment)
+	if r.Log, err = report.Tail(report.LogPath(cfg.Dir()), reportLogLines); err != nil {
+		return err
+	}
+	if paths, err := daemon.PathsFor(cfg.Dir(), opts.Directory); err == nil {
+		r.DaemonLog, _ = report.Tail(paths.Log, reportLogLines)
+	}
+
+	output := opts.OutputFile
+	if output == "-" {
+		return r.Write(os.Stdout)
+	}
+	if output == "" {
+		output = "sandworm-report-" + time.Now().Format("20060102-150405") + ".txt"
+	} else if _, err := os.Stat(output); err == nil {
+		if err := confirmOrCancel(fmt.Sprintf("Overwrite '%s'?", output), "report", opts); err != nil {
+			return err
+		}
+	}
+
+	f, err := os.OpenFile(output, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("unable to write report: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	if err := r.Write(f); err != nil {
+		return fmt.Errorf("unable to write report: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("unable to write report: %w", err)
+	}
+
+	fmt.Println(style.Success(fmt.Sprintf("Wrote '%s'", output)))
+	fmt.Println(style.Dim("Review it before attaching it to a bug report."))
+	return nil
+}
+
+// MARK: Helpers
+
+// reportableFlagTypes are the types of the flags whose values are recorded
+// in the command log: they can't hold free text.
+var reportableFlagTypes = []string{"bool", "count", "duration", "float64", "int", "int64", "uint"}
+
+// reportableValue reports whether the value of a config option can be shown in
+// reports: one of its valid values (e.g. a mode), a number or a duration.
+// Free text (names, paths, patterns, URLs) may identify the user.
+func reportableValue(key, value string) bool {
+	option := findConfigOption(key)
+	if option == nil {
+		return false
+	}
+	if slices.Contains(option.ValidValues, value) {
+		return true
+	}
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return true
+	}
+	_, err := time.ParseDuration(value)
+	return err == nil
+}
+
+// recordCommand appends a command and its outcome to the command log, for
+// reports. Failures are ignored: the log is only a diagnostic aid.
+func recordCommand(cfg *config.Config, cmd *cobra.Command, err error) {
+	if cfg == nil || cmd == nil || slices.Contains(unrecordedCommands, cmd.Name()) {
+		return
+	}
+	_ = report.Record(report.LogPath(cfg.Dir()), recordedArgs(cmd), ExitCode(err), err)
+}
+
+// recordedArgs returns the command line of a command to record: its name and
+// the flags set, without the values of text flags nor the arguments (e.g. the
+// question of ask), which are [SET] and [ARG].
+func recordedArgs(cmd *cobra.Command) []string {
+	args := strings.Fields(cmd.CommandPath())[1:]
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		if slices.Contains(reportableFlagTypes, f.Value.Type()) {
+			args = append(args, fmt.Sprintf("--%s=%s", f.Name, f.Value.String()))
+		} else {
+			args = append(args, "--"+f.Name+"=[SET]")
+		}
+	})
+	for range cmd.Flags().Args() {
+		args = append(args, "[ARG]")
+	}
+	return args
+}
@@ -0,0 +1,151 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/holonoms/sandworm/internal/audit"
+	"github.com/holonoms/sandworm/internal/backup"
+	"github.com/holonoms/sandworm/internal/config"
+	"github.com/holonoms/sandworm/internal/scrub"
+	"github.com/holonoms/sandworm/internal/style"
+	"github.com/spf13/cobra"
+)
+
+// newRestoreCmd creates the restore command
+func newRestoreCmd(opts *Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "restore [backup]",
+		Short: "Re-upload a backup of the project's documents",
+		Long: `Re-upload the documents of a backup taken before a push replaced them (see
+'sandworm push --backup' and the claude.backup setting), replacing the
+project's documents of the same name. Without an argument, the project's
+backups are listed to pick one; use "latest" for the most recent.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			name := ""
+			if len(args) > 0 {
+				name = args[0]
+			}
+			opts.SetDefaults()
+			return runRestore(opts, name)
+		},
+	}
+
+	return cmd
+}
+
+func runRestore(opts *Options, name string) error {
+	if err := checkWritable("restore"); err != nil {
+		return err
+	}
+	client, err := setupClaudeClient(false, opts)
+	if err != nil {
+		return err
+	}
+	cfg, err := config.New(opts.Directory)
+	if err != nil {
+		return fmt.Errorf("unable to load config: %w", err)
+	}
+
+	// Restores upload documents, so the policies of pushes apply
+	policies, err := applyPolicies(opts, client)
+	if err != nil {
+		return err
+	}
+
+	_, projectID := client.TargetIDs()
+	dir := backup.Dir(cfg.Dir(), projectID)
+	names, err := backup.List(dir)
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		fmt.Println("No backups of this project.")
+		return ErrNothingToDo
+	}
+
+	switch {
+	case name == "latest":
+		name = names[len(names)-1]
+	case name == "":
+		if name, err = chooseBackup(dir, names); err != nil {
+			return err
+		}
+	case !slices.Contains(names, name):
+		return validationError(fmt.Errorf("backup '%s' not found (run 'sandworm restore' to list them)", name))
+	}
+
+	docs, err := backup.Load(filepath.Join(dir, name))
+	if err != nil {
+		return err
+	}
+	if len(docs) == 0 {
+		fmt.Println("The backup has no documents.")
+		return ErrNothingToDo
+	}
+	scrubSecrets := requiresSecretScan(policies)
+	for i, doc := range docs {
+		if scrubSecrets {
+			content, _ := scrub.NewSecrets().Scrub([]byte(doc.Content))
+			docs[i].Content = string(content)
+		}
+		if err := checkPolicySize(policies, int64(len(docs[i].Content))); err != nil {
+			return err
+		}
+	}
+
+	orgName, projectName := client.TargetNames()
+	fmt.Printf("%s project '%s' in org '%s'\n", style.Header("Target:"), style.Info(projectName), style.Info(orgName))
+	restored := make([]string, len(docs))
+	for i, doc := range docs {
+		restored[i] = doc.Name
+		fmt.Printf("  - %s\n", doc.Name)
+	}
+	if err := confirmOrCancel(fmt.Sprintf("Restore %d file(s) from backup %s, replacing those of the same name?", len(docs), name), "restore", opts); err != nil {
+		return err
+	}
+
+	// Restoring replaces documents too, which can then be restored in turn
+	if err := backupRemote(client, opts, nil); err != nil {
+		return err
+	}
+
+	for i, doc := range docs {
+		fmt.Println(style.Dim(fmt.Sprintf("%d/%d: Uploading '%s'...", i+1, len(docs), doc.Name)))
+		if err := client.ReplaceDocument(doc.Name, doc.Content); err != nil {
+			if i > 0 {
+				recordAudit(client, audit.ActionRestore, opts.Directory, restored[:i], "")
+			}
+			return fmt.Errorf("unable to restore '%s': %w", doc.Name, err)
+		}
+	}
+	recordAudit(client, audit.ActionRestore, opts.Directory, restored, "")
+
+	fmt.Println(style.Success(fmt.Sprintf("Restored %d file(s) from backup %s", len(docs), name)))
+	return nil
+}
+
+// chooseBackup lists the backups of a project, newest first, and asks which
+// one to restore.
+func chooseBackup(dir string, names []string) (string, error) {
+	names = slices.Clone(names)
+	slices.Reverse(names)
+
+	options := make([]string, len(names))
+	for i, name := range names {
+		options[i] = name
+		if files, err := backup.Names(filepath.Join(dir, name)); err == nil {
+			options[i] += " " + style.Dim("("+strings.Join(files, ", ")+")")
+		}
+	}
+
+	fmt.Println(style.Header("Backups:"))
+	i, err := choose("Restore which backup?", options)
+	if err != nil {
+		return "", fmt.Errorf("%w; pass the backup name (or 'latest') as an argument", err)
+	}
+	return names[i], nil
+}
@@ -0,0 +1,155 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/holonoms/sandworm/internal/pipeline"
+	"github.com/holonoms/sandworm/internal/style"
+	"github.com/holonoms/sandworm/internal/util"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// runOptions holds the options of the run command.
+type runOptions struct {
+	File string // Pipelines file
+	List bool   // List the pipelines (and their command lines) instead of running them
+}
+
+// newRunCmd creates the run command
+func newRunCmd(opts *Options) *cobra.Command {
+	var runOpts runOptions
+
+	cmd := &cobra.Command{
+		Use:   "run [pipeline...]",
+		Short: "Run the pipelines declared in " + pipeline.File,
+		Long: `Run the pipelines declared in ` + pipeline.File + ` (all of them, in order, unless
+some are named). Each pipeline describes a bundle: its input files, transforms
+(any generation flag), output file and, for pushes, the backend project:
+
+  pipelines:
+    - name: api
+      description: Backend code for the API project
+      command: push
+      input:
+        directory: services/api
+        exclude: ["*_test.go"]
+      transforms:
+        symbol-index: true
+        header-style: short
+      output:
+        file: bundles/api.txt
+      backend:
+        project: API
+
+Paths are relative to the pipelines file. Use --list to review the command
+line each pipeline runs.`,
+		RunE: func(_ *cobra.Command, args []string) error {
+			opts.SetDefaults()
+			return runRun(opts, runOpts, args)
+		},
+	}
+
+	cmd.Flags().StringVarP(&runOpts.File, "file", "f", pipeline.File, "Pipelines file")
+	cmd.Flags().BoolVar(&runOpts.List, "list", false, "List the pipelines and the command line each runs, without running them")
+
+	return cmd
+}
+
+func runRun(opts *Options, runOpts runOptions, names []string) error {
+	all, err := pipeline.Read(runOpts.File)
+	if errors.Is(err, os.ErrNotExist) {
+		return validationError(fmt.Errorf("no %s found (see 'sandworm run --help' for its format)", runOpts.File))
+	}
+	if err != nil {
+		return validationError(err)
+	}
+	pipelines, err := pipeline.Find(all, names)
+	if err != nil {
+		return validationError(err)
+	}
+	if err := checkTransforms(runOpts.File, pipelines); err != nil {
+		return validationError(err)
+	}
+	dir := filepath.Dir(runOpts.File)
+
+	if runOpts.List {
+		for _, p := range pipelines {
+			line := style.Info(p.Name)
+			if p.Description != "" {
+				line += " " + style.Dim(p.Description)
+			}
+			fmt.Println(line)
+			fmt.Printf("  sandworm %s\n", formatPresetArgs(p.Args(dir)))
+		}
+		return nil
+	}
+
+	for i, p := range pipelines {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("%s %s\n", style.Header("Pipeline:"), style.Info(p.Name))
+
+		if output := p.OutputPath(dir); output != "" {
+			if err := os.MkdirAll(filepath.Dir(output), 0o755); err != nil {
+				return fmt.Errorf("unable to create output directory: %w", err)
+			}
+		}
+
+		// Each pipeline runs with a fresh set of options, exactly as its command
+		// line would, but skipping prompts when run with --yes.
+		args := p.Args(dir)
+		if opts.AssumeYes {
+			args = append(args, "--yes")
+		}
+		if opts.Plain {
+			args = append(args, "--plain")
+		}
+		cmd := NewRootCmd(&Options{})
+		cmd.SetArgs(args)
+		if err := cmd.Execute(); err != nil && !errors.Is(err, ErrNothingToDo) {
+			return fmt.Errorf("pipeline '%s' failed: %w", p.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// MARK: Helpers
+
+// checkTransforms checks that the transforms of pipelines are flags of their
+// command, suggesting the closest flag for unknown ones.
+func checkTransforms(file string, pipelines []pipeline.Pipeline) error {
+	root := NewRootCmd(&Options{})
+	for _, p := range pipelines {
+		command := p.Command
+		if command == "" {
+			command = pipeline.Generate
+		}
+		cmd, _, err := root.Find([]string{command})
+		if err != nil {
+			return err
+		}
+
+		var names []string
+		for _, flags := range []*pflag.FlagSet{cmd.Flags(), cmd.InheritedFlags()} {
+			flags.VisitAll(func(f *pflag.Flag) { names = append(names, f.Name) })
+		}
+		for flag := range p.Transforms {
+			if slices.Contains(names, flag) {
+				continue
+			}
+			problem := fmt.Sprintf("%s:%d: pipeline '%s': unknown transform '%s'", file, p.Line, p.Name, flag)
+			if closest := util.Closest(flag, names); closest != "" {
+				problem += fmt.Sprintf(" (did you mean '%s'?)", closest)
+			}
+			return errors.New(problem)
+		}
+	}
+	return nil
+}
@@ -12,7 +12,7 @@ func newSetupCmd() *cobra.Command {
 		Use:   "setup",
 		Short: "Configure Claude project",
 		RunE: func(_ *cobra.Command, _ []string) error {
-			_, err := setupClaudeClient(true)
+			_, err := setupClaudeClient(true, nil)
 			if err != nil {
 				return err
 			}
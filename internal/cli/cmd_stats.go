@@ -0,0 +1,149 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/holonoms/sandworm/internal/config"
+	"github.com/holonoms/sandworm/internal/stats"
+	"github.com/holonoms/sandworm/internal/style"
+	"github.com/holonoms/sandworm/internal/tokens"
+	"github.com/holonoms/sandworm/internal/util"
+	"github.com/spf13/cobra"
+)
+
+// newStatsCmd creates the stats command
+func newStatsCmd() *cobra.Command {
+	var limit int
+	var asJSON bool
+
+	cmd := &cobra.Command{
+		Use:   "stats [directory]",
+		Short: "Show how the project's bundle grew over time",
+		Long: `Show the local usage stats of a project: pushes, bundle sizes over time and
+generation durations. Stats are recorded on each generate and push, kept next
+to the global config and never sent anywhere.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			dir := "."
+			if len(args) > 0 {
+				dir = args[0]
+			}
+			return runStats(dir, limit, asJSON)
+		},
+	}
+
+	cmd.Flags().IntVarP(&limit, "limit", "l", 20, "Show the last N entries (0 for all)")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print entries as JSON lines")
+
+	return cmd
+}
+
+func runStats(dir string, limit int, asJSON bool) error {
+	cfg, err := config.New("")
+	if err != nil {
+		return fmt.Errorf("unable to load config: %w", err)
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return fmt.Errorf("unable to resolve directory: %w", err)
+	}
+
+	entries, err := stats.Read(stats.Path(cfg.Dir()), abs)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		fmt.Printf("No stats recorded yet for %s.\n", abs)
+		return ErrNothingToDo
+	}
+
+	runs, pushes := len(entries), 0
+	var totalDuration int64
+	for _, e := range entries {
+		if e.Action == stats.ActionPush {
+			pushes++
+		}
+		totalDuration += e.Duration
+	}
+	if limit > 0 && len(entries) > limit {
+		entries = entries[len(entries)-limit:]
+	}
+
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		for _, e := range entries {
+			if err := enc.Encode(e); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	sizes := make([]int64, len(entries))
+	for i, e := range entries {
+		sizes[i] = e.Size
+	}
+	first, last := entries[0], entries[len(entries)-1]
+
+	fmt.Printf("%s %s\n", style.Header("Project:"), abs)
+	fmt.Printf("%s %d pushes in %d runs, last on %s\n", style.Header("Runs:   "), pushes, runs, last.Time.Local().Format("2006-01-02 15:04"))
+	fmt.Printf("%s %s %s %s\n", style.Header("Size:   "), util.FormatSize(last.Size), style.Info(stats.Sparkline(sizes, style.Plain())), style.Dim(growth(first.Size, last.Size, len(entries))))
+	if last.Tokens > 0 {
+		fmt.Printf("%s %s\n", style.Header("Tokens: "), tokens.Format(last.Tokens))
+	}
+	fmt.Printf("%s %d\n", style.Header("Files:  "), last.Files)
+	fmt.Printf("%s %s average\n\n", style.Header("Time:   "), formatDuration(totalDuration/int64(runs)))
+
+	fmt.Println(style.Dim(fmt.Sprintf("%-16s  %-8s  %10s  %6s  %8s", "DATE", "ACTION", "SIZE", "FILES", "TIME")))
+	for _, e := range entries {
+		fmt.Printf("%-16s  %-8s  %10s  %6d  %8s\n", e.Time.Local().Format("2006-01-02 15:04"), e.Action, util.FormatSize(e.Size), e.Files, formatDuration(e.Duration))
+	}
+	return nil
+}
+
+// MARK: Helpers
+
+// recordStats appends the last generation to the usage stats. Failures are
+// only reported: stats are informational.
+func recordStats(opts *Options, action string, gen generation) {
+	entry := stats.Entry{
+		Action:   action,
+		Size:     gen.Size,
+		Files:    gen.Files,
+		Tokens:   gen.Tokens,
+		Duration: gen.Duration.Milliseconds(),
+	}
+	entry.Directory, _ = filepath.Abs(opts.Directory)
+
+	err := func() error {
+		cfg, err := config.New("")
+		if err != nil {
+			return err
+		}
+		return stats.Append(stats.Path(cfg.Dir()), entry)
+	}()
+	if err != nil {
+		fmt.Println(style.Warning(fmt.Sprintf("Unable to record usage stats: %v", err)))
+	}
+}
+
+// growth describes the size change between two entries, e.g. "+12% over the
+// last 8 runs".
+func growth(from, to int64, runs int) string {
+	if runs < 2 || from == 0 {
+		return ""
+	}
+	return fmt.Sprintf("(%+.0f%% over the last %d runs)", float64(to-from)*100/float64(from), runs)
+}
+
+// formatDuration formats a duration in milliseconds, e.g. "350ms" or "1.2s".
+func formatDuration(ms int64) string {
+	if ms < 1000 {
+		return fmt.Sprintf("%dms", ms)
+	}
+	return (time.Duration(ms) * time.Millisecond).Round(100 * time.Millisecond).String()
+}
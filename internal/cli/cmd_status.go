@@ -0,0 +1,176 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/holonoms/sandworm/internal/claude"
+	"github.com/holonoms/sandworm/internal/style"
+	"github.com/holonoms/sandworm/internal/tokens"
+	"github.com/holonoms/sandworm/internal/util"
+	"github.com/spf13/cobra"
+)
+
+// newStatusCmd creates the status command
+func newStatusCmd(opts *Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show the account's session, usage limits and the Claude service status",
+		Long: `Check the session key against the target project, show the account's usage
+limits (where the API exposes them) and the state of the Claude service, to
+tell whether a failure comes from the setup, the account or the service.`,
+		Args: cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			opts.SetDefaults()
+			return runStatus(opts)
+		},
+	}
+
+	return cmd
+}
+
+func runStatus(opts *Options) error {
+	client, err := setupClaudeClient(false, opts)
+	if err != nil {
+		return err
+	}
+
+	account := client.Account()
+	if account == "" {
+		account = "default"
+	}
+	orgName, projectName := client.TargetNames()
+	orgID, projectID := client.TargetIDs()
+	fmt.Printf("%s %s\n", style.Header("Account:     "), account)
+	fmt.Printf("%s %s %s\n", style.Header("Organization:"), orgName, style.Dim("("+orgID+")"))
+	fmt.Printf("%s %s %s\n", style.Header("Project:     "), projectName, style.Dim("("+projectID+")"))
+	if push := lastPush(opts.Directory); !push.Time.IsZero() {
+		bundle := fmt.Sprintf("%s, %s", util.FormatSize(push.Size), tokens.Format(push.Tokens))
+		if push.Tokens == 0 {
+			bundle = util.FormatSize(push.Size) // Recorded before token estimates
+		}
+		fmt.Printf("%s %s %s\n", style.Header("Last push:   "), bundle, style.Dim("("+push.Time.Local().Format("2006-01-02 15:04")+")"))
+	}
+
+	// The session is checked against the project, which is what pushes need
+	names, sessionErr := client.ListDocumentNames()
+	if sessionErr != nil {
+		fmt.Printf("%s %s\n", style.Header("Session:     "), style.Error(describeFailure(sessionErr)))
+	} else {
+		fmt.Printf("%s %s\n", style.Header("Session:     "), style.Success(fmt.Sprintf("valid (%d document(s) in project)", len(names))))
+		if session, err := client.Session(); err == nil {
+			fmt.Printf("%s %s\n", style.Header("Signed in as:"), session.Email)
+			if expires := session.Expires(); !expires.IsZero() {
+				fmt.Printf("%s %s\n", style.Header("Expires:     "), formatExpiry(expires, time.Now()))
+			}
+		}
+		if managed, err := client.ManagedDocumentNames(); err == nil {
+			fmt.Printf("%s %d uploaded by sandworm, %d by hand or other tools\n", style.Header("Documents:   "), len(managed), len(names)-len(managed))
+		}
+	}
+
+	limits, err := client.Usage()
+	switch {
+	case claude.IsStatus(err, http.StatusNotFound):
+		fmt.Printf("%s %s\n", style.Header("Usage:       "), style.Dim("not exposed by the API"))
+	case err != nil:
+		fmt.Printf("%s %s\n", style.Header("Usage:       "), style.Error(describeFailure(err)))
+	case len(limits) == 0:
+		fmt.Printf("%s %s\n", style.Header("Usage:       "), style.Dim("no limits reported"))
+	default:
+		fmt.Println(style.Header("Usage:"))
+		now := time.Now()
+		for _, limit := range limits {
+			usage := fmt.Sprintf("%3.0f%%", limit.Utilization)
+			switch {
+			case limit.Utilization >= 100:
+				usage = style.Error(usage)
+			case limit.Utilization >= 80:
+				usage = style.Warning(usage)
+			}
+			line := fmt.Sprintf("  %-16s %s", limit.Name, usage)
+			if !limit.ResetsAt.IsZero() {
+				line += " " + style.Dim(formatReset(limit.ResetsAt, now))
+			}
+			fmt.Println(line)
+		}
+	}
+
+	status, err := client.ServiceStatus()
+	switch {
+	case err != nil:
+		fmt.Printf("%s %s\n", style.Header("Service:     "), style.Error(describeFailure(err)))
+	case status.Indicator == "none":
+		fmt.Printf("%s %s\n", style.Header("Service:     "), style.Success(status.Description))
+	default:
+		fmt.Printf("%s %s\n", style.Header("Service:     "), style.Warning(status.Description))
+	}
+
+	return sessionErr
+}
+
+// MARK: Helpers
+
+// describeFailure summarizes why a Claude API request failed, telling apart
+// problems of the setup, the account and the service.
+func describeFailure(err error) string {
+	var apiErr *claude.APIError
+	switch {
+	case errors.Is(err, claude.ErrMissingConfig):
+		return "not configured, run 'sandworm setup'"
+	case !errors.As(err, &apiErr):
+		return err.Error()
+	case apiErr.IsAuthError():
+		return fmt.Sprintf("rejected (%d), the session key may have expired: run 'sandworm setup'", apiErr.StatusCode)
+	case apiErr.IsRateLimited():
+		if apiErr.ResetsAt.IsZero() {
+			return "usage limit reached"
+		}
+		return "usage limit reached, " + formatReset(apiErr.ResetsAt, time.Now())
+	case apiErr.IsServiceError():
+		return fmt.Sprintf("service error (%d)", apiErr.StatusCode)
+	default:
+		return fmt.Sprintf("request failed (%d)", apiErr.StatusCode)
+	}
+}
+
+// formatExpiry describes when a session key is estimated to expire, e.g.
+// "around Feb 1 (in 12 days)". Soon to expire sessions are highlighted.
+func formatExpiry(t, now time.Time) string {
+	date := t.Local().Format("Jan 2")
+	switch days := int(t.Sub(now).Hours() / 24); {
+	case !t.After(now):
+		return style.Warning("any time now (estimated around " + date + ")")
+	case days == 0:
+		return style.Warning("around " + date + " (within a day)")
+	case days < 3:
+		return style.Warning(fmt.Sprintf("around %s (in %d days)", date, days))
+	default:
+		return fmt.Sprintf("around %s (in %d days)", date, days)
+	}
+}
+
+// formatReset describes when a limit resets, e.g. "resets 15:04 (in 2h5m)".
+func formatReset(t, now time.Time) string {
+	in := t.Sub(now).Round(time.Minute)
+	if in <= 0 {
+		return "resets now"
+	}
+	layout := "15:04"
+	if in >= 24*time.Hour {
+		layout = "Jan 2 15:04"
+	}
+	return fmt.Sprintf("resets %s (in %s)", t.Local().Format(layout), formatMinutes(in))
+}
+
+// formatMinutes formats a duration rounded to the minute, e.g. "2h5m" or
+// "3d4h".
+func formatMinutes(d time.Duration) string {
+	if d >= 24*time.Hour {
+		days := d / (24 * time.Hour)
+		return fmt.Sprintf("%dd%dh", days, (d-days*24*time.Hour)/time.Hour)
+	}
+	return fmt.Sprintf("%dh%dm", d/time.Hour, (d%time.Hour)/time.Minute)
+}
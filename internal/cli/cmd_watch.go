@@ -0,0 +1,354 @@
+package cli
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/holonoms/sandworm/internal/config"
+	"github.com/holonoms/sandworm/internal/cron"
+	"github.com/holonoms/sandworm/internal/daemon"
+	"github.com/holonoms/sandworm/internal/notify"
+	"github.com/holonoms/sandworm/internal/processor"
+	"github.com/holonoms/sandworm/internal/source"
+	"github.com/holonoms/sandworm/internal/style"
+	"github.com/holonoms/sandworm/internal/watch"
+	"github.com/spf13/cobra"
+)
+
+// watchOptions holds the options of the watch command (and the daemon
+// running it).
+type watchOptions struct {
+	Interval      time.Duration
+	Debounce      *time.Duration // If nil, the value from config will be used
+	IgnoreChanges []string       // Gitignore-style patterns of files whose changes don't trigger pushes
+	MaxPushes     *int           // Pushes per hour; if nil, the value from config will be used
+	Schedule      string         // Cron expression; if set, push on schedule instead of on change
+	Notify        *bool          // If nil, the value from config will be used
+	Generate      *bool          // Regenerate the output file instead of pushing; if nil, the value from config will be used
+	Daemon        bool           // Running as a daemon: serve the status socket
+}
+
+// newWatchCmd creates the watch command
+func newWatchCmd(opts *Options) *cobra.Command {
+	var watchOpts watchOptions
+
+	cmd := &cobra.Command{
+		Use:   "watch [directory]",
+		Short: "Push to Claude whenever project files change",
+		Long: `Push to Claude once, then again whenever the files that would be bundled
+change. With --generate (or watch.generate), regenerate the output file
+instead, as generate does (split in parts with tokens.chunk_size), keeping it
+up to date for other tools without using Claude.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				opts.Directory = args[0]
+			}
+			opts.SetDefaults()
+			return runWatch(opts, watchOpts)
+		},
+	}
+
+	addWatchFlags(cmd, &watchOpts)
+	cmd.Flags().BoolVar(&watchOpts.Daemon, "daemon", false, "Run as a daemon (see 'sandworm daemon')")
+	_ = cmd.Flags().MarkHidden("daemon")
+
+	return cmd
+}
+
+// addWatchFlags adds the flags shared by the watch and daemon commands.
+func addWatchFlags(cmd *cobra.Command, watchOpts *watchOptions) {
+	var notifications bool
+	var generate bool
+	var debounce time.Duration
+	var maxPushes int
+	cmd.Flags().DurationVar(&watchOpts.Interval, "interval", 2*time.Second, "How often to check for changes")
+	cmd.Flags().DurationVar(&debounce, "debounce", 0, "Wait until files stopped changing for this long before pushing (overrides config setting; default: --interval)")
+	cmd.Flags().StringArrayVar(&watchOpts.IgnoreChanges, "ignore-changes", nil, "Don't push on changes to files matching a gitignore-style pattern, e.g. build output (repeatable; they're still bundled)")
+	cmd.Flags().IntVar(&maxPushes, "max-pushes", 0, "Push at most N times per hour, holding back later changes (overrides config setting; 0 for no limit)")
+	cmd.Flags().StringVar(&watchOpts.Schedule, "schedule", "", `Push on a cron schedule (e.g. "0 * * * *") if files changed, instead of on every change`)
+	cmd.Flags().BoolVar(&notifications, "notify", true, "Show a desktop notification after each push (overrides config setting)")
+	cmd.Flags().BoolVar(&generate, "generate", false, "Regenerate the output file (-o, default: sandworm.txt) on change instead of pushing (overrides config setting)")
+
+	cmd.PreRun = func(cmd *cobra.Command, _ []string) {
+		if cmd.Flags().Changed("notify") {
+			watchOpts.Notify = &notifications
+		}
+		if cmd.Flags().Changed("generate") {
+			watchOpts.Generate = &generate
+		}
+		if cmd.Flags().Changed("debounce") {
+			watchOpts.Debounce = &debounce
+		}
+		if cmd.Flags().Changed("max-pushes") {
+			watchOpts.MaxPushes = &maxPushes
+		}
+	}
+}
+
+func runWatch(opts *Options, watchOpts watchOptions) error {
+	if opts.From != "" || opts.FromImage != "" {
+		return validationError(errors.New("--from/--from-image can't be used with watch"))
+	}
+	if source.IsRemote(opts.Directory) {
+		return validationError(errors.New("remote directories can't be used with watch"))
+	}
+	if watchOpts.Interval <= 0 {
+		return validationError(fmt.Errorf("--interval must be positive, got: %s", watchOpts.Interval))
+	}
+
+	cfg, err := config.New(opts.Directory)
+	if err != nil {
+		return fmt.Errorf("unable to load config: %w", err)
+	}
+	// Generating only writes a local file, so it's allowed in read-only mode
+	generate := resolveBool(watchOpts.Generate, cfg, "watch.generate", false)
+	if !generate {
+		if err := checkWritable("watch"); err != nil {
+			return err
+		}
+	}
+	notifier := &pushNotifier{enabled: resolveBool(watchOpts.Notify, cfg, "watch.notify", true)}
+	genOpts := generateOptions{OutputFile: cmp.Or(opts.OutputFile, defaultOutputFile)}
+	if generate {
+		notifier.generated = genOpts.OutputFile
+	}
+	debounce := resolveDuration(cfg, "watch.debounce", watchOpts.Interval)
+	if watchOpts.Debounce != nil {
+		debounce = *watchOpts.Debounce
+	}
+	if debounce < 0 {
+		return validationError(fmt.Errorf("--debounce must not be negative, got: %s", debounce))
+	}
+	limit := &watch.Limit{Max: resolveInt(watchOpts.MaxPushes, cfg, "watch.max_pushes_per_hour", 0), Window: time.Hour}
+	if limit.Max < 0 {
+		return validationError(fmt.Errorf("--max-pushes must not be negative, got: %d", limit.Max))
+	}
+	ignoreChanges := append(splitList(cfg.Get("watch.ignore_changes")), watchOpts.IgnoreChanges...)
+	if err := processor.ValidatePatterns(ignoreChanges); err != nil {
+		return validationError(err)
+	}
+
+	var schedule *cron.Schedule
+	if expr := resolveString(watchOpts.Schedule, cfg, "watch.schedule", ""); expr != "" {
+		if schedule, err = cron.Parse(expr); err != nil {
+			return validationError(err)
+		}
+	}
+
+	// Stopped by Ctrl+C, SIGTERM or 'daemon stop', once a push in progress
+	// reaches a safe point (see runPush)
+	ctx, stop := context.WithCancel(interrupt)
+	defer stop()
+
+	state := newWatchState(opts.Directory)
+	if watchOpts.Daemon {
+		paths, err := daemon.PathsFor(cfg.Dir(), opts.Directory)
+		if err != nil {
+			return err
+		}
+		defer daemon.Cleanup(paths)
+		go func() {
+			if err := daemon.Serve(ctx, paths.Socket, state.status, stop); err != nil {
+				opts.PrintError(err)
+				stop()
+			}
+		}()
+	}
+
+	// Pushes happen unattended, so there's nobody to confirm replacing the
+	// document.
+	opts.AssumeYes = true
+	push := func() error {
+		var err error
+		if generate {
+			err = writeBundle(opts, genOpts)
+		} else {
+			err = runPush(opts, pushOptions{})
+		}
+		state.pushed(err)
+		notifier.pushed(err)
+		return err
+	}
+
+	// When generating, the scan skips the output file, which would otherwise
+	// trigger the next generation.
+	scanOpts := generateOptions{OutputFile: opts.OutputFile}
+	if generate {
+		scanOpts = genOpts
+	}
+	scan, err := watchScanner(opts, scanOpts, ignoreChanges)
+	if err != nil {
+		return err
+	}
+	last, err := scan()
+	if err != nil {
+		return fmt.Errorf("unable to scan files: %w", err)
+	}
+
+	// The first push fails fast, as errors at this point (missing setup,
+	// invalid session key) won't fix themselves.
+	limit.Allow(time.Now())
+	if err := push(); err != nil {
+		return err
+	}
+
+	if schedule != nil {
+		fmt.Println(style.Dim(fmt.Sprintf("Next push check at %s (press Ctrl+C to stop)...", schedule.Next(time.Now()).Format(time.DateTime))))
+		watch.Schedule(ctx, schedule.Next, func() {
+			snapshot, err := scan()
+			if err != nil {
+				opts.PrintError(fmt.Errorf("unable to scan files: %w", err))
+				return
+			}
+			now := time.Now()
+			changed := watch.Diff(last, snapshot)
+			if len(changed) == 0 {
+				fmt.Printf("%s %s\n", style.Dim(now.Format("15:04:05")), style.Dim("No changes since the last push"))
+				return
+			}
+			if !limit.Allow(now) {
+				fmt.Printf("%s %s\n", style.Dim(now.Format("15:04:05")), style.Warning(describeLimit(limit, now)))
+				return
+			}
+			fmt.Printf("\n%s %s\n", style.Dim(now.Format("15:04:05")), describeChanges(changed))
+			if err := push(); err != nil {
+				opts.PrintError(err)
+				return
+			}
+			last = snapshot
+		})
+		return nil
+	}
+
+	w := &watch.Watcher{
+		Scan:     scan,
+		Interval: watchOpts.Interval,
+		Debounce: debounce,
+		Limit:    limit,
+		OnLimit: func(time.Time) {
+			now := time.Now()
+			fmt.Printf("%s %s\n", style.Dim(now.Format("15:04:05")), style.Warning(describeLimit(limit, now)))
+		},
+	}
+
+	fmt.Println(style.Dim("Watching for changes (press Ctrl+C to stop)..."))
+	return w.Run(ctx, func(changed []string) {
+		fmt.Printf("\n%s %s\n", style.Dim(time.Now().Format("15:04:05")), describeChanges(changed))
+		if err := push(); err != nil {
+			opts.PrintError(err)
+		}
+	})
+}
+
+// MARK: Helpers
+
+// watchScanner returns a scan function fingerprinting the files that would be
+// bundled, so changes to ignored files (including sandworm's own outputs,
+// such as the parts of a split output file or a license report) don't trigger
+// pushes. Neither do changes to the files matching the ignoreChanges patterns.
+func watchScanner(opts *Options, genOpts generateOptions, ignoreChanges []string) (func() (watch.Snapshot, error), error) {
+	p, err := newProcessor(opts, genOpts, input{})
+	if err != nil {
+		return nil, err
+	}
+	ignored := processor.PathMatcher(ignoreChanges)
+
+	return func() (watch.Snapshot, error) {
+		files, err := p.Files(interrupt)
+		if err != nil {
+			return nil, err
+		}
+
+		snapshot := make(watch.Snapshot, len(files))
+		for _, file := range files {
+			if ignored != nil && ignored(file.RelativePath) {
+				continue
+			}
+			info, err := os.Stat(file.AbsolutePath)
+			if err != nil {
+				continue
+			}
+			snapshot[file.RelativePath] = fmt.Sprintf("%d:%d", info.ModTime().UnixNano(), info.Size())
+		}
+		return snapshot, nil
+	}, nil
+}
+
+// describeChanges summarizes changed paths for display.
+func describeChanges(changed []string) string {
+	const maxListed = 3
+	if len(changed) <= maxListed {
+		return "Changed: " + strings.Join(changed, ", ")
+	}
+	return fmt.Sprintf("Changed: %s and %d more", strings.Join(changed[:maxListed], ", "), len(changed)-maxListed)
+}
+
+// describeLimit explains that changes are held back by the push limit.
+func describeLimit(limit *watch.Limit, now time.Time) string {
+	return fmt.Sprintf("Reached %d push(es) in the last hour, holding changes back until %s", limit.Max, limit.Next(now).Format("15:04"))
+}
+
+// watchState tracks pushes for the daemon status.
+type watchState struct {
+	mu sync.Mutex
+	s  daemon.Status
+}
+
+func newWatchState(dir string) *watchState {
+	abs, _ := filepath.Abs(dir)
+	return &watchState{s: daemon.Status{PID: os.Getpid(), Directory: abs, StartedAt: time.Now()}}
+}
+
+func (w *watchState) pushed(err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err != nil {
+		w.s.LastError = err.Error()
+		return
+	}
+	w.s.LastPush = time.Now()
+	w.s.LastError = ""
+	w.s.Pushes++
+}
+
+func (w *watchState) status() daemon.Status {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.s
+}
+
+// pushNotifier reports push results as desktop notifications, so background
+// syncs don't fail silently.
+type pushNotifier struct {
+	enabled   bool
+	generated string // Output file, if generating instead of pushing
+}
+
+func (n *pushNotifier) pushed(err error) {
+	if !n.enabled {
+		return
+	}
+
+	message := "Pushed project to Claude"
+	switch {
+	case err != nil && n.generated != "":
+		message = "Generation failed: " + err.Error()
+	case err != nil:
+		message = "Push failed: " + err.Error()
+	case n.generated != "":
+		message = "Generated " + n.generated
+	}
+	if err := notify.Send("sandworm", message); err != nil {
+		// Don't retry (and warn) on every push
+		n.enabled = false
+		fmt.Println(style.Warning(fmt.Sprintf("Desktop notifications disabled: %v", err)))
+	}
+}
@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/holonoms/sandworm/internal/style"
+	"github.com/holonoms/sandworm/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+// newWorkspacesCmd creates the workspaces command
+func newWorkspacesCmd(opts *Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "workspaces [directory]",
+		Short: "List the packages of a monorepo workspace (for use with --workspace)",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				opts.Directory = args[0]
+			}
+			opts.SetDefaults()
+			return runWorkspaces(opts.Directory)
+		},
+	}
+
+	return cmd
+}
+
+func runWorkspaces(dir string) error {
+	ws, err := detectWorkspace(dir)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(style.Header(fmt.Sprintf("Workspace (%s):", ws.Kind)))
+	for _, pkg := range ws.Packages {
+		line := fmt.Sprintf("  %s %s", pkg.Name, style.Dim("("+pkg.Path+")"))
+		if len(pkg.Deps) > 0 {
+			line += style.Dim(" -> " + strings.Join(pkg.Deps, ", "))
+		}
+		fmt.Println(line)
+	}
+
+	return nil
+}
+
+// MARK: Helpers
+
+// detectWorkspace detects the monorepo workspace in dir, failing if there is
+// none.
+func detectWorkspace(dir string) (*workspace.Workspace, error) {
+	ws, err := workspace.Detect(dir)
+	if err != nil {
+		return nil, fmt.Errorf("unable to detect workspace: %w", err)
+	}
+	if ws == nil {
+		return nil, validationError(fmt.Errorf("no workspace found in '%s' (looked for go.work, pnpm-workspace.yaml, package.json workspaces and Cargo.toml)", dir))
+	}
+	return ws, nil
+}
+
+// workspaceDirs resolves the directories to include for a workspace package:
+// the package itself and its local dependencies.
+func workspaceDirs(dir, name string) ([]string, error) {
+	ws, err := detectWorkspace(dir)
+	if err != nil {
+		return nil, err
+	}
+	dirs, err := ws.Closure(name)
+	if err != nil {
+		return nil, validationError(err)
+	}
+	return dirs, nil
+}
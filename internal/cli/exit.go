@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+
+	"github.com/holonoms/sandworm/internal/claude"
+	"github.com/holonoms/sandworm/internal/config"
+	"github.com/holonoms/sandworm/internal/style"
+)
+
+// Exit codes returned by sandworm. These are part of the CLI's contract so
+// that scripts can branch on the type of failure.
+const (
+	ExitOK          = 0 // Success
+	ExitError       = 1 // Any error not covered below
+	ExitNothingToDo = 2 // The command had nothing to do (e.g. no files to purge)
+	ExitAuth        = 3 // Authentication failed (invalid/expired session key)
+	ExitValidation  = 4 // Invalid input, configuration or size limits exceeded
+	ExitNetwork     = 5 // The Claude API couldn't be reached
+
+	ExitInterrupted = 130 // Stopped with Ctrl+C or SIGTERM (like shells report SIGINT)
+)
+
+// ErrNothingToDo signals that a command completed without doing anything. It
+// isn't reported as an error, but results in ExitNothingToDo.
+var ErrNothingToDo = errors.New("nothing to do")
+
+// exitError attaches an exit code to an error.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+func (e *exitError) Unwrap() error { return e.err }
+
+// validationError marks an error as a validation failure (ExitValidation).
+func validationError(err error) error {
+	return &exitError{code: ExitValidation, err: err}
+}
+
+// ExitCode maps an error returned by a command to the process exit code.
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+
+	var exitErr *exitError
+	if errors.As(err, &exitErr) {
+		return exitErr.code
+	}
+	if errors.Is(err, ErrNothingToDo) {
+		return ExitNothingToDo
+	}
+	if errors.Is(err, ErrInterrupted) {
+		return ExitInterrupted
+	}
+
+	var apiErr *claude.APIError
+	if errors.As(err, &apiErr) && apiErr.IsAuthError() {
+		return ExitAuth
+	}
+	var fileErr *config.FileError
+	if errors.Is(err, claude.ErrMissingConfig) || errors.Is(err, claude.ErrReadOnly) || errors.As(err, &fileErr) {
+		return ExitValidation
+	}
+
+	// Match network operations specifically: syscall errors (e.g. from file
+	// operations) also implement net.Error.
+	var urlErr *url.Error
+	var opErr *net.OpError
+	var dnsErr *net.DNSError
+	if errors.As(err, &urlErr) || errors.As(err, &opErr) || errors.As(err, &dnsErr) {
+		return ExitNetwork
+	}
+
+	return ExitError
+}
+
+// PrintError reports an error returned by a command on stderr. Errors that
+// aren't failures (ErrNothingToDo) and interruptions, already reported when
+// they happen, are not printed.
+func (o *Options) PrintError(err error) {
+	if err == nil || errors.Is(err, ErrNothingToDo) || errors.Is(err, ErrInterrupted) {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "%s %v\n", style.Error("Error:"), err)
+
+	var apiErr *claude.APIError
+	if errors.As(err, &apiErr) && (apiErr.IsRateLimited() || apiErr.IsServiceError()) {
+		fmt.Fprintln(os.Stderr, style.Dim(fmt.Sprintf("Claude %s. Run 'sandworm status' to check the account's usage and the service.", describeFailure(apiErr))))
+	}
+
+	var schemaErr *claude.SchemaError
+	if errors.As(err, &schemaErr) {
+		if o.Verbose {
+			fmt.Fprintf(os.Stderr, "Response:\n%s\n", schemaErr.Payload)
+		} else {
+			fmt.Fprintln(os.Stderr, style.Dim("Run with --verbose to see the response."))
+		}
+	}
+}
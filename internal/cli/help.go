@@ -0,0 +1,232 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/holonoms/sandworm/internal/convert"
+	"github.com/holonoms/sandworm/internal/processor"
+	"github.com/holonoms/sandworm/internal/source"
+	"github.com/spf13/cobra"
+)
+
+// helpTopic is an extended help page about something spanning commands,
+// shown by 'sandworm help <name>' and generated as a man page (see
+// 'sandworm man'). Lists in its text are built from the code they describe,
+// so that they can't go stale.
+type helpTopic struct {
+	name  string
+	short string
+	text  func() string
+}
+
+// helpTopics are the help topics, in the order they're listed.
+var helpTopics = []helpTopic{
+	{"ignore-syntax", "Which files are bundled: ignore files, patterns and built-in categories", ignoreSyntaxTopic},
+	{"formats", "Layout of generated files, and the file formats converted to text", formatsTopic},
+	{"backends", "Where files are read from, and where bundles go", backendsTopic},
+	{"config-options", "Configuration options, with their defaults and valid values", configOptionsTopic},
+	{"exit-codes", "Exit codes, for scripts to branch on the type of failure", exitCodesTopic},
+}
+
+// newHelpTopicCmds creates the help topics, plus 'topics' listing them. They
+// are commands without a Run function, which cobra only shows help for (and
+// lists as additional help topics).
+func newHelpTopicCmds() []*cobra.Command {
+	cmds := make([]*cobra.Command, 0, len(helpTopics)+1)
+	var list strings.Builder
+	list.WriteString("Help topics, shown with 'sandworm help <topic>':\n\n")
+	for _, topic := range helpTopics {
+		cmds = append(cmds, &cobra.Command{
+			Use:   topic.name,
+			Short: topic.short,
+			Long:  strings.TrimSpace(topic.text()),
+		})
+		fmt.Fprintf(&list, "  %-16s %s\n", topic.name, topic.short)
+	}
+	return append(cmds, &cobra.Command{
+		Use:   "topics",
+		Short: "List the help topics",
+		Long:  strings.TrimSpace(list.String()),
+	})
+}
+
+func ignoreSyntaxTopic() string {
+	var b strings.Builder
+	b.WriteString(`Sandworm bundles the files of a directory that no ignore rule matches. Rules
+come from, by increasing precedence:
+
+  1. The built-in categories selected with --default-ignores (or
+     processor.default_ignores)
+  2. The global ignore file, 'ignore' in the global config directory (like
+     git's core.excludesFile)
+  3. The project's ignore file: .sandwormignore, else .gitignore, or the file
+     given with --ignore. Like git, ignore files of the same name in
+     subdirectories apply to their directory, taking precedence over those of
+     parent directories.
+  4. --exclude patterns, then --include patterns, which take precedence over
+     every ignore file, including those of subdirectories. Like any negated
+     pattern, --include can't bring back a file whose directory is excluded:
+     include the directory itself (e.g. --include 'dist/').
+
+Sandworm's own files (the output file, leftovers of interrupted runs) are
+always excluded.
+
+Patterns follow the .gitignore format, one per line:
+
+  # comment    Blank lines and lines starting with # are skipped (\# matches
+               a literal #)
+  name         Matches files and directories of that name, at any depth
+  dir/name     Patterns with a slash (other than a trailing one) are relative
+               to the ignore file's directory; so is a leading slash (/name)
+  name/        A trailing slash only matches directories
+  *  ?         Match any characters, or any single character, except slashes
+  [a-z]        Matches a character of a set or range
+  **           As a whole path component, matches any number of directories
+               (**/logs, logs/**, a/**/b)
+  !pattern     Includes files again that a previous pattern ignored
+
+Built-in categories, selected with a comma-separated list of names, 'all'
+(the default), 'none', or -name to disable one:
+
+`)
+	for _, name := range processor.IgnoreCategories {
+		for i, line := range wrapWords(processor.IgnoreCategoryPatterns(name), 60) {
+			if i == 0 {
+				fmt.Fprintf(&b, "  %-12s %s\n", name, line)
+			} else {
+				fmt.Fprintf(&b, "  %-12s %s\n", "", line)
+			}
+		}
+	}
+	b.WriteString(`
+'sandworm ignore suggest' proposes rules for vendored directories, generated
+code and large files; 'sandworm pick' selects files interactively.`)
+	return b.String()
+}
+
+func formatsTopic() string {
+	builtins := convert.Builtins(convert.Options{})
+	names := make([]string, len(builtins))
+	for i, c := range builtins {
+		names[i] = c.Name()
+	}
+
+	return fmt.Sprintf(`Generated files are plain text: a tree of the bundled files, followed by the
+contents of each file under a header.
+
+  PROJECT STRUCTURE:
+  ==================
+
+  /
+  +-- components
+  |   +-- Button.tsx
+  +-- pages
+      +-- index.tsx
+
+  FILE CONTENTS:
+  ==============
+
+  ================================================================================
+  FILE: components/Button.tsx
+  ================================================================================
+  [file contents here]
+
+File headers are set with --header-style (or processor.header_style): %s.
+Other options add sections: SYMBOL INDEX (--symbol-index) and DEPENDENCY
+GRAPH (--dependency-graph) before the contents; REMOTE SOURCES (the URLs of
+%s), DATABASE SCHEMAS and SKIPPED FILES (files that couldn't be read, with
+the reason) after them. --plain replaces the unicode of the tree with ASCII,
+and --line-numbers, --file-metadata and --normalize change file contents and
+headers.
+
+Binary files are skipped, unless a converter renders them as text. Built-in
+converters: %s (see the processor.* options). External converters are
+commands added with 'sandworm converters add'.`,
+		strings.Join(processor.HeaderStyles, ", "), source.SourcesFile, strings.Join(names, ", "))
+}
+
+func backendsTopic() string {
+	return fmt.Sprintf(`Sources, where the files to bundle are read from:
+
+  <directory>             A local directory (default: the current one)
+  [user@]host:/path       A directory on another machine, read over ssh
+  --from <archive>        A zip or tar archive (optionally gzip or bzip2
+                          compressed)
+  --from-image <image>    A container image, exported with docker or podman
+  %-23s URLs whose contents are fetched and cached, listed in the
+                          directory
+
+Destinations, where bundles go:
+
+  Claude project          push, deploy, watch and the daemon upload the bundle
+                          as a document of a project. The project is the one
+                          given with --org and --project, else the pipeline's
+                          backend (see 'sandworm run'), else the configured
+                          one (claude.organization_id, claude.project_id),
+                          asked for the first time.
+  File                    generate writes it to --output (default:
+                          sandworm.txt). With --encrypt age:<recipient> or
+                          gpg:<recipient>, it's encrypted for sharing, see
+                          'sandworm decrypt'. watch --generate (or
+                          watch.generate) keeps it up to date.
+
+Pipelines in sandworm.yaml declare both for bundles shared by a team; safety
+policies can restrict the projects pushes may target.`, source.SourcesFile)
+}
+
+func configOptionsTopic() string {
+	var b strings.Builder
+	b.WriteString(`Options are set with 'sandworm config set <key> <value>', in the project
+config (.sandworm) or, for user-wide ones such as session keys, the global
+config. 'sandworm config list' shows their current values and where they're
+set.
+
+`)
+	for _, option := range configOptions {
+		fmt.Fprintf(&b, "  %s\n      %s\n", option.Key, option.Description)
+		if option.Default != "" {
+			fmt.Fprintf(&b, "      Default: %s\n", option.Default)
+		}
+		if len(option.ValidValues) > 0 {
+			fmt.Fprintf(&b, "      Values: %s\n", strings.Join(option.ValidValues, ", "))
+		}
+	}
+	return b.String()
+}
+
+func exitCodesTopic() string {
+	return fmt.Sprintf(`Sandworm exits with distinct codes, so that scripts and CI can branch on the
+type of failure:
+
+  %d    Success
+  %d    Any other error
+  %d    Nothing to do (e.g. no files to purge)
+  %d    Authentication error (invalid or expired session key)
+  %d    Validation error (bad input or config, read-only mode, size limits or
+       policies)
+  %d    Network error (Claude unreachable)
+  %d  Interrupted (Ctrl+C or SIGTERM), after stopping at a safe point`,
+		ExitOK, ExitError, ExitNothingToDo, ExitAuth, ExitValidation, ExitNetwork, ExitInterrupted)
+}
+
+// wrapWords joins words with spaces into lines of at most width characters
+// (unless a word is longer).
+func wrapWords(words []string, width int) []string {
+	var lines []string
+	var line string
+	for _, word := range words {
+		if line != "" && len(line)+1+len(word) > width {
+			lines = append(lines, line)
+			line = ""
+		}
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/holonoms/sandworm/internal/style"
+)
+
+// ErrInterrupted is the cause of the cancellation of the interrupt context,
+// returned by commands stopped with Ctrl+C (ExitInterrupted).
+var ErrInterrupted = errors.New("interrupted")
+
+// interrupt is cancelled (with ErrInterrupted) on the first Ctrl+C or
+// SIGTERM, see withInterrupts. Long-running work (generating, pushing,
+// watching, prompts) checks it to stop at a safe point and clean up.
+var interrupt = context.Background()
+
+// withInterrupts makes the first Ctrl+C (or SIGTERM) cancel the interrupt
+// context instead of killing the process, so that commands stop tidily. A
+// second one quits immediately. The returned function restores the default
+// behavior.
+func withInterrupts() func() {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-signals:
+			signal.Stop(signals) // The next signal gets the default behavior
+			fmt.Fprintln(os.Stderr, style.Dim("\nStopping... (press Ctrl+C again to quit immediately)"))
+			cancel(ErrInterrupted)
+		case <-done:
+		}
+	}()
+
+	interrupt = ctx
+	return func() {
+		signal.Stop(signals)
+		close(done)
+		cancel(nil)
+		interrupt = context.Background()
+	}
+}
+
+// interrupted returns ErrInterrupted once the interrupt context is
+// cancelled, for commands to check between steps.
+func interrupted() error {
+	if interrupt.Err() != nil {
+		return context.Cause(interrupt)
+	}
+	return nil
+}
@@ -1,12 +1,12 @@
 // Package cli provides the command-line interface for sandworm
 package cli
 
-import (
-	"fmt"
-	"time"
-)
+import "github.com/holonoms/sandworm/internal/wsl"
 
-// Options holds the command-line options shared across commands
+// Options holds the command-line options shared across commands, as parsed
+// from the persistent flags and the directory argument. Options of a single
+// command are held by its own struct (e.g. generateOptions, pushOptions), and
+// results of a run are returned by it (e.g. generation), never stored here.
 // If a value is nil, it will be resolved from config; otherwise, the CLI value overrides config.
 type Options struct {
 	// OutputFile specifies the path where the concatenated project file will be written.
@@ -17,13 +17,28 @@ type Options struct {
 	// If empty, sandworm will:
 	// - look for .sandwormignore first, then
 	// - fall back to .gitignore if present
-	// - use a sane internal list of ignore patterns (see internal/processor.go)
+	// - use a sane internal list of ignore patterns (see processor.IgnoreCategories)
 	IgnoreFile string
 
+	// DefaultIgnores selects the built-in ignore categories to apply (e.g.
+	// "binaries,locks" or "-locks"). If empty, the value from config will be used.
+	DefaultIgnores string
+
+	// Exclude are ad-hoc gitignore-style patterns excluding files, applied
+	// after those of the ignore files.
+	Exclude []string
+
+	// Include are ad-hoc gitignore-style patterns re-including files excluded
+	// by any other pattern.
+	Include []string
+
 	// KeepFile determines whether to retain the generated file after pushing to Claude.
-	// For generate command, this is always true. For push command, this is controlled by the --keep flag.
+	// The generate command always keeps it.
 	KeepFile bool
 
+	// ConfigDir overrides the global config directory (see config.DirEnv).
+	ConfigDir string
+
 	// Directory specifies the root directory to process.
 	// If empty, defaults to the current directory (".").
 	Directory string
@@ -35,23 +50,151 @@ type Options struct {
 	// FollowSymlinks determines whether to follow symbolic links when traversing directories.
 	// If nil, the value from config will be used. If set, it overrides the config.
 	FollowSymlinks *bool
+
+	// DependencyGraph determines whether to add a dependency graph section.
+	// If nil, the value from config will be used. If set, it overrides the config.
+	DependencyGraph *bool
+
+	// SymbolIndex determines whether to add a symbol index section.
+	// If nil, the value from config will be used. If set, it overrides the config.
+	SymbolIndex *bool
+
+	// FileMetadata determines whether to add file metadata to file headers.
+	// If nil, the value from config will be used. If set, it overrides the config.
+	FileMetadata *bool
+
+	// HeaderStyle selects the file header style: full, short or minimal.
+	// If empty, the value from config will be used.
+	HeaderStyle string
+
+	// Organization overrides the configured Claude organization (ID or name).
+	// If empty, the configured organization is used.
+	Organization string
+
+	// Project overrides the configured Claude project (ID or name).
+	// If empty, the configured project is used.
+	Project string
+
+	// Refresh invalidates the cached organization/project metadata.
+	Refresh bool
+
+	// NoCache disables the Claude API response cache (implying Refresh).
+	NoCache bool
+
+	// RefreshSources fetches the remote sources (see source.SourcesFile)
+	// again, regardless of the age of their cached copies.
+	RefreshSources bool
+
+	// AssumeYes skips confirmation prompts before destructive operations.
+	AssumeYes bool
+
+	// ReadOnly disables the API calls that change anything (see
+	// claude.ReadOnlyEnv).
+	ReadOnly bool
+
+	// Submodules controls how git submodules are handled: full, tree or skip.
+	// If empty, the value from config will be used.
+	Submodules string
+
+	// Order controls the order of the file contents: path or related.
+	// If empty, the value from config will be used.
+	Order string
+
+	// Workspace scopes the output to a single monorepo workspace package (name
+	// or path) plus its local dependencies. If empty, the whole project is used.
+	Workspace string
+
+	// Package scopes the output to a single Go package (e.g. ./cmd/foo).
+	// If empty, the whole project is used.
+	Package string
+
+	// Deps includes the local packages transitively imported by Package.
+	Deps bool
+
+	// ChecksumManifest determines whether to add a checksum manifest section.
+	// If nil, the value from config will be used. If set, it overrides the config.
+	ChecksumManifest *bool
+
+	// Normalize determines whether to convert CRLF line endings to LF and trim
+	// trailing whitespace in file contents.
+	// If nil, the values from config will be used. If set, it overrides the config.
+	Normalize *bool
+
+	// ScanInjection determines whether to flag instruction-like content that
+	// could hijack the model (prompt injection).
+	// If nil, the value from config will be used. If set, it overrides the config.
+	ScanInjection *bool
+
+	// Profile writes a profile of generate/push: cpu, mem or trace.
+	// If empty, no profile is written.
+	Profile string
+
+	// ChunkTokens splits the bundle into parts of at most this many tokens, at
+	// file boundaries (0 for a single part).
+	// If nil, the value from config will be used. If set, it overrides the config.
+	ChunkTokens *int
+
+	// Sanitize controls how control and invisible characters are handled: off,
+	// strip or escape. If empty, the value from config will be used.
+	Sanitize string
+
+	// ScrubPII determines whether to mask emails, phone numbers and custom
+	// patterns in file contents.
+	// If nil, the value from config will be used. If set, it overrides the config.
+	ScrubPII *bool
+
+	// ScrubSecrets determines whether to mask credentials in file contents.
+	// If nil, the value from config will be used. If set, it overrides the config.
+	ScrubSecrets *bool
+
+	// LicenseExclude excludes files whose header matches this regular
+	// expression. If empty, the value from config will be used.
+	LicenseExclude string
+
+	// LicenseReport is the path to write a license compliance report to.
+	// If empty, no report is written.
+	LicenseReport string
+
+	// Target selects a named file selection saved with 'sandworm pick'.
+	// If empty, files are selected by the ignore rules.
+	Target string
+
+	// Files are hand-picked files to bundle, regardless of ignore rules. Set by
+	// the pick command, or resolved from Target.
+	Files []string
+
+	// From is an archive (zip, tar, tar.gz, tar.bz2) to read files from instead
+	// of Directory. If empty, Directory is used.
+	From string
+
+	// FromImage is a container image to read files from instead of Directory.
+	// If empty, Directory (or From) is used.
+	FromImage string
+
+	// ImagePaths are the directories of FromImage to bundle. If empty, the
+	// image's working directory is used.
+	ImagePaths []string
+
+	// Plain disables colors and unicode output (including the tree drawing in
+	// generated files).
+	Plain bool
+
+	// Verbose shows error details, such as unexpected Claude API responses
+	// (see PrintError).
+	Verbose bool
 }
 
-// SetDefaults sets default values for options based on the command context
-func (o *Options) SetDefaults(command string) {
+// SetDefaults sets the default values of the options, once the directory
+// argument is applied. Command-specific defaults, such as the output file of
+// generate and push, belong to the command (see generateOptions).
+func (o *Options) SetDefaults() {
 	if o.Directory == "" {
 		o.Directory = "."
 	}
-
-	switch command {
-	case "generate":
-		if o.OutputFile == "" {
-			o.OutputFile = "sandworm.txt"
-		}
-		o.KeepFile = true
-	case "push":
-		if o.OutputFile == "" {
-			o.OutputFile = fmt.Sprintf(".sandworm-%d.txt", time.Now().Unix())
+	// Paths may be given in the form of the other side of WSL
+	for _, p := range []*string{&o.Directory, &o.OutputFile, &o.IgnoreFile, &o.LicenseReport, &o.From} {
+		if *p != "" {
+			*p = wsl.Normalize(*p)
 		}
 	}
 }
@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"slices"
+
+	"github.com/holonoms/sandworm/internal/claude"
+	"github.com/holonoms/sandworm/internal/policy"
+)
+
+// applyPolicies reads the safety policies of the project in opts (see the
+// policy package) and checks that they allow pushing to the client's target.
+// It returns them, for checkPolicySize and the scans they require (see
+// requiresSecretScan).
+func applyPolicies(opts *Options, client *claude.Client) ([]*policy.Policy, error) {
+	policies, err := policy.Load(opts.Directory)
+	if err != nil {
+		return nil, validationError(err)
+	}
+
+	orgID, projectID := client.TargetIDs()
+	orgName, projectName := client.TargetNames()
+	for _, p := range policies {
+		if err := p.CheckTarget(orgID, orgName, projectID, projectName); err != nil {
+			return nil, validationError(err)
+		}
+	}
+	return policies, nil
+}
+
+// requiresSecretScan reports whether any of the policies requires masking
+// credentials.
+func requiresSecretScan(policies []*policy.Policy) bool {
+	return slices.ContainsFunc(policies, func(p *policy.Policy) bool { return p.RequireSecretScan })
+}
+
+// checkPolicySize fails if a bundle is larger than a policy allows.
+func checkPolicySize(policies []*policy.Policy, size int64) error {
+	for _, p := range policies {
+		if err := p.CheckSize(size); err != nil {
+			return validationError(err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/holonoms/sandworm/internal/profile"
+	"github.com/holonoms/sandworm/internal/style"
+)
+
+// startProfiling starts the profile requested with --profile, if any, and
+// returns the timings to record the phases of the run in (nil when not
+// profiling). The returned function stops it, writing the profile and
+// printing the timing breakdown.
+func startProfiling(opts *Options) (*profile.Timings, func(), error) {
+	if opts.Profile == "" {
+		return nil, func() {}, nil
+	}
+	path := profile.FileName(opts.Profile)
+	stop, err := profile.Start(opts.Profile, path)
+	if err != nil {
+		return nil, nil, validationError(err)
+	}
+	timings := profile.NewTimings()
+
+	return timings, func() {
+		if err := stop(); err != nil {
+			fmt.Println(style.Warning(fmt.Sprintf("Unable to write the %s profile: %v", opts.Profile, err)))
+			return
+		}
+		fmt.Println(style.Header("Timings:"))
+		fmt.Println(timings.String())
+		tool := "pprof"
+		if opts.Profile == profile.Trace {
+			tool = "trace"
+		}
+		fmt.Println(style.Dim(fmt.Sprintf("Profile written to %s (inspect with 'go tool %s %s')", path, tool, path)))
+	}, nil
+}
+
+// addTiming records the duration of a phase of a profiled run since start,
+// if profiling.
+func addTiming(timings *profile.Timings, name string, start time.Time) {
+	if timings != nil {
+		timings.Add(name, time.Since(start))
+	}
+}
@@ -0,0 +1,177 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/holonoms/sandworm/internal/config"
+	"github.com/holonoms/sandworm/internal/style"
+	"golang.org/x/term"
+)
+
+// promptInput is where interactive answers are read from (replaceable in tests).
+var promptInput io.Reader = os.Stdin
+
+// promptReader buffers promptInput. It is shared across prompts so that
+// answers piped in for several questions aren't lost.
+var (
+	promptReader       *bufio.Reader
+	promptReaderSource io.Reader
+)
+
+// readAnswer reads a line of input, or returns ErrInterrupted on Ctrl+C.
+func readAnswer() (string, error) {
+	if promptReader == nil || promptReaderSource != promptInput {
+		promptReader = bufio.NewReader(promptInput)
+		promptReaderSource = promptInput
+	}
+	return whileInterruptible(func() (string, error) {
+		return promptReader.ReadString('\n')
+	})
+}
+
+// whileInterruptible runs a blocking read, returning early with
+// ErrInterrupted on Ctrl+C. The read is then abandoned, as the command stops.
+func whileInterruptible(read func() (string, error)) (string, error) {
+	type result struct {
+		answer string
+		err    error
+	}
+	results := make(chan result, 1)
+	go func() {
+		answer, err := read()
+		results <- result{answer, err}
+	}()
+	select {
+	case r := <-results:
+		return r.answer, r.err
+	case <-interrupt.Done():
+		return "", interrupted()
+	}
+}
+
+// confirm asks a yes/no question and returns true only for an explicit yes.
+// When assumeYes is set, the question is skipped entirely.
+func confirm(question string, assumeYes bool) (bool, error) {
+	if assumeYes {
+		return true, nil
+	}
+
+	fmt.Printf("%s [y/N]: ", style.Warning(question))
+	answer, err := readAnswer()
+	if err != nil && answer == "" {
+		if err == io.EOF {
+			fmt.Println()
+			return false, fmt.Errorf("confirmation required; re-run with --yes to skip it")
+		}
+		return false, fmt.Errorf("failed to read answer: %w", err)
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// readPassphrase asks for the passphrase of an encrypted config (see
+// config.Passphrase), without echoing it when reading from a terminal. The
+// prompt goes to stderr, so that it doesn't mix with exported output.
+func readPassphrase(confirm bool) (string, error) {
+	read := func(prompt string) (string, error) {
+		fmt.Fprint(os.Stderr, style.Warning(prompt))
+		if f, ok := promptInput.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+			// Echo is off while reading: it's restored if interrupted, as
+			// the abandoned read won't
+			fd := int(f.Fd())
+			state, err := term.GetState(fd)
+			if err != nil {
+				return "", err
+			}
+			passphrase, err := whileInterruptible(func() (string, error) {
+				passphrase, err := term.ReadPassword(fd)
+				return string(passphrase), err
+			})
+			if errors.Is(err, ErrInterrupted) {
+				_ = term.Restore(fd, state)
+			}
+			fmt.Fprintln(os.Stderr)
+			return passphrase, err
+		}
+		answer, err := readAnswer()
+		if err != nil && answer == "" {
+			if err == io.EOF {
+				fmt.Fprintln(os.Stderr)
+				return "", fmt.Errorf("passphrase required; set %s to provide it", config.PassphraseEnv)
+			}
+			return "", err
+		}
+		return strings.TrimRight(answer, "\r\n"), nil
+	}
+
+	passphrase, err := read("Config passphrase: ")
+	if err != nil || !confirm {
+		return passphrase, err
+	}
+	again, err := read("Repeat the passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if again != passphrase {
+		return "", errors.New("the passphrases don't match")
+	}
+	return passphrase, nil
+}
+
+// choose lists options and asks to pick one by number, returning its index.
+func choose(question string, options []string) (int, error) {
+	for i, option := range options {
+		fmt.Printf("  %d. %s\n", i+1, option)
+	}
+	for {
+		fmt.Printf("%s [1-%d]: ", style.Warning(question), len(options))
+		answer, err := readAnswer()
+		if err != nil && answer == "" {
+			if err == io.EOF {
+				fmt.Println()
+				return 0, fmt.Errorf("selection required")
+			}
+			return 0, fmt.Errorf("failed to read answer: %w", err)
+		}
+		if n, err := strconv.Atoi(strings.TrimSpace(answer)); err == nil && n >= 1 && n <= len(options) {
+			return n - 1, nil
+		}
+		fmt.Printf("Please enter a number between 1 and %d\n", len(options))
+	}
+}
+
+// confirmOrCancel asks for confirmation unless skipped via --yes or the
+// claude.confirm project setting, returning an error if the user declines.
+func confirmOrCancel(question, action string, opts *Options) error {
+	ok, err := confirm(question, skipConfirmation(opts))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("%s cancelled", action)
+	}
+	return nil
+}
+
+// skipConfirmation reports whether confirmation prompts should be skipped.
+func skipConfirmation(opts *Options) bool {
+	if opts != nil && opts.AssumeYes {
+		return true
+	}
+	conf, err := config.New(".")
+	if err != nil {
+		return false
+	}
+	return conf.Get("claude.confirm") == "false"
+}
@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/holonoms/sandworm/internal/wsl"
+)
+
+// projectMarkers are files or directories that identify a project root.
+var projectMarkers = []string{
+	".git", ".hg", ".svn", ".sandworm", ".sandwormignore",
+	"go.mod", "package.json", "deno.json", "Cargo.toml", "pyproject.toml", "setup.py", "requirements.txt",
+	"Gemfile", "composer.json", "pom.xml", "build.gradle", "build.gradle.kts", "mix.exs", "pubspec.yaml",
+	"CMakeLists.txt", "Makefile", "Dockerfile", "README.md",
+}
+
+// checkRoot asks for confirmation before walking a directory that doesn't
+// look like a project (see suspiciousRoot), unless --yes is given.
+func checkRoot(opts *Options) error {
+	dir := opts.Directory
+	if dir == "" {
+		dir = "."
+	}
+	reason := suspiciousRoot(dir)
+	if reason == "" {
+		return nil
+	}
+	ok, err := confirm(fmt.Sprintf("'%s' is %s. Bundle it anyway?", dir, reason), opts.AssumeYes)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("generation cancelled")
+	}
+	return nil
+}
+
+// suspiciousRoot returns why a directory is unlikely to be meant as a
+// project root: the home directory, the filesystem root, or a directory
+// with subdirectories but no project marker in it or its parents up to the
+// home directory (loose files are cheap to walk, and common). Markers in the
+// home directory itself (e.g. a dotfiles repository) don't count. It returns
+// "" if it looks fine.
+func suspiciousRoot(dir string) string {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	home, err := os.UserHomeDir()
+	if err == nil {
+		home = filepath.Clean(home)
+	}
+	if abs == home {
+		return "your home directory"
+	}
+	if filepath.Dir(abs) == abs {
+		return "the filesystem root"
+	}
+	if wsl.Detected() {
+		if reason := windowsRoot(abs, wsl.MountRoot()); reason != "" {
+			return reason
+		}
+	}
+
+	for d := abs; d != home; d = filepath.Dir(d) {
+		for _, marker := range projectMarkers {
+			if _, err := os.Lstat(filepath.Join(d, marker)); err == nil {
+				return ""
+			}
+		}
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+
+	entries, err := os.ReadDir(abs)
+	if err != nil {
+		return ""
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			return "not a recognizable project (no .git, go.mod, package.json, etc.)"
+		}
+	}
+	return ""
+}
+
+// windowsRoot returns why a directory of a Windows drive mounted in WSL is
+// unlikely to be meant as a project root: the drive's root, or a Windows home
+// directory. It returns "" for other directories.
+func windowsRoot(abs, mountRoot string) string {
+	win, ok := wsl.ToWindows(abs, mountRoot)
+	if !ok {
+		return ""
+	}
+	parts := strings.Split(strings.TrimSuffix(win, `\`), `\`)
+	switch {
+	case len(parts) == 1:
+		return "the root of drive " + parts[0]
+	case len(parts) == 3 && strings.EqualFold(parts[1], "Users"):
+		return "a Windows home directory"
+	}
+	return ""
+}
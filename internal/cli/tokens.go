@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/holonoms/sandworm/internal/config"
+	"github.com/holonoms/sandworm/internal/processor"
+	"github.com/holonoms/sandworm/internal/style"
+	"github.com/holonoms/sandworm/internal/tokens"
+)
+
+// estimateTokens estimates the tokens of the bundle at path with the
+// tokens.estimator option of the project in directory. When the estimator
+// fails (e.g. without an API key), it warns and falls back to the fast
+// estimator, rather than failing the generation.
+func estimateTokens(directory, path string) int {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+	estimator := tokens.Fast
+	if cfg, err := config.New(directory); err == nil {
+		estimator = resolveString("", cfg, "tokens.estimator", tokens.Fast)
+	}
+
+	counter, err := tokens.New(estimator)
+	if err == nil {
+		var count int
+		if count, err = counter.Count(content); err == nil {
+			return count
+		}
+	}
+	fmt.Println(style.Warning(fmt.Sprintf("Unable to estimate tokens with the %s estimator, using %s: %v", estimator, tokens.Fast, err)))
+	fast, _ := tokens.New(tokens.Fast)
+	count, _ := fast.Count(content)
+	return count
+}
+
+// bundlePart is a part of a bundle split by tokens.chunk_size.
+type bundlePart struct {
+	Name    string
+	Content []byte
+	Tokens  int
+}
+
+// splitBundle splits the generated bundle into balanced parts of at most
+// tokens.chunk_size tokens, at file boundaries (see processor.Split), named
+// after name (e.g. project-1.txt). It returns nil when chunking is off or the
+// bundle fits in a single part.
+func splitBundle(opts *Options, gen generation, name string) ([]bundlePart, error) {
+	cfg, err := config.New(opts.Directory)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load config: %w", err)
+	}
+	budget := resolveInt(opts.ChunkTokens, cfg, "tokens.chunk_size", 0)
+	if budget <= 0 || gen.Tokens <= budget {
+		return nil, nil
+	}
+	content, err := os.ReadFile(gen.Path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read bundle: %w", err)
+	}
+
+	// Files are sized with the fast estimator, scaled to the bundle's estimate,
+	// so that parts agree with tokens.estimator without estimating each file
+	// with it (e.g. one API request per file)
+	fast, _ := tokens.New(tokens.Fast)
+	total, _ := fast.Count(content)
+	count := func(piece []byte) int {
+		n, _ := fast.Count(piece)
+		if total > 0 {
+			n = int(int64(n) * int64(gen.Tokens) / int64(total))
+		}
+		return n
+	}
+
+	chunks := processor.Split(content, gen.Offsets, budget, count)
+	if len(chunks) < 2 {
+		return nil, nil
+	}
+	names := make([]string, len(chunks))
+	for i := range chunks {
+		names[i] = partName(name, i+1)
+	}
+	parts := make([]bundlePart, len(chunks))
+	for i, chunk := range chunks {
+		// Each part lists the others, so that Claude knows the bundle continues
+		note := fmt.Sprintf("[Part %d of %d of the project bundle: %s]\n\n", i+1, len(chunks), strings.Join(names, ", "))
+		parts[i] = bundlePart{Name: names[i], Content: append([]byte(note), chunk...), Tokens: count(chunk)}
+	}
+	return parts, nil
+}
+
+// partName returns the name of a bundle part, e.g. project-2.txt for the
+// second part of project.txt.
+func partName(name string, n int) string {
+	ext := filepath.Ext(name)
+	return fmt.Sprintf("%s-%d%s", strings.TrimSuffix(name, ext), n, ext)
+}
+
+// isPartOf reports whether a document name is a part of a bundle name, e.g.
+// project-2.txt of project.txt.
+func isPartOf(doc, name string) bool {
+	ext := filepath.Ext(name)
+	n, ok := strings.CutPrefix(doc, strings.TrimSuffix(name, ext)+"-")
+	if !ok {
+		return false
+	}
+	n, ok = strings.CutSuffix(n, ext)
+	return ok && n != "" && strings.Trim(n, "0123456789") == ""
+}
@@ -4,12 +4,17 @@
 package config
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strings"
+
+	"github.com/holonoms/sandworm/internal/wsl"
 )
 
 // Config manages application configuration, automatically storing values in either
@@ -19,17 +24,73 @@ type Config struct {
 	projectPath string
 	global      map[string]map[string]string
 	project     map[string]map[string]string
+	lines       map[string]map[string]int // Line of each key, by file path
+}
+
+// Entry is a key set in a config file.
+type Entry struct {
+	Key   string
+	Value string
+	Path  string // File the key is set in
+	Line  int    // 0 if unknown
+}
+
+// FileError is a malformed config file, with the position of the problem.
+type FileError struct {
+	Path string
+	Line int    // 0 if unknown
+	Key  string // The offending key, if known
+	Err  error
 }
 
+func (e *FileError) Error() string {
+	pos := e.Path
+	if e.Line > 0 {
+		pos += fmt.Sprintf(":%d", e.Line)
+	}
+	if e.Key != "" {
+		return fmt.Sprintf("%s: %s: %s", pos, e.Key, e.Err)
+	}
+	return fmt.Sprintf("%s: %s", pos, e.Err)
+}
+
+func (e *FileError) Unwrap() error { return e.Err }
+
 // Specify shared keys. These are stored in the global configuration file and are accessible
 // to all sandworm projects.
 var globalKeys = map[string]bool{
-	"claude.session_key": true,
+	"claude.session_key":     true,
+	"claude.default_account": true,
+	"claude.api_url":         true, // API location overrides apply to every project
+	"claude.endpoints":       true,
+	"processor.databases":    true, // Run dump tools with expanded environment variables
+	"sources.allow_private":  true, // Lets sources files reach internal services
+	"cli.default_command":    true, // Changes what a bare 'sandworm' does, so cloned projects can't
+}
+
+// Specify shared sections. All keys in these sections are stored globally.
+var globalSections = map[string]bool{
+	"accounts":   true, // Session keys, keyed by account label
+	"converters": true, // Shell commands, never run from a (possibly cloned) project file
+	"encryption": true, // How secret values are encrypted, see Encrypt
+	"sessions":   true, // When session keys were entered, to estimate their expiry
 }
 
+// Specify secret keys and sections. These are excluded from exports unless
+// explicitly requested.
+var (
+	secretKeys = map[string]bool{
+		"claude.session_key": true,
+	}
+	secretSections = map[string]bool{
+		"accounts": true,
+	}
+)
+
 // New creates a new Config instance. If projectPath is empty, only global config
-// is used. Global config is stored in ~/.config/sandworm/config.json, while
-// project config is stored in .sandworm in the project directory.
+// is used. Global config is stored in ~/.config/sandworm/config.json (see
+// getGlobalConfigPath for overrides and portable mode), while project config
+// is stored in .sandworm in the project directory.
 func New(projectPath string) (*Config, error) {
 	globalPath, err := getGlobalConfigPath()
 	if err != nil {
@@ -41,16 +102,22 @@ func New(projectPath string) (*Config, error) {
 		projectPath: filepath.Join(projectPath, ".sandworm"),
 		global:      make(map[string]map[string]string),
 		project:     make(map[string]map[string]string),
+		lines:       make(map[string]map[string]int),
 	}
 
 	// Load global config
-	if err := config.loadGlobal(); err != nil && !os.IsNotExist(err) {
+	var fileErr *FileError
+	if err := config.loadGlobal(); errors.As(err, &fileErr) {
+		return nil, err
+	} else if err != nil && !os.IsNotExist(err) {
 		return nil, fmt.Errorf("failed to load global config: %w", err)
 	}
 
 	// Load project config if path provided
 	if projectPath != "" {
-		if err := config.loadProject(); err != nil && !os.IsNotExist(err) {
+		if err := config.loadProject(); errors.As(err, &fileErr) {
+			return nil, err
+		} else if err != nil && !os.IsNotExist(err) {
 			return nil, fmt.Errorf("failed to load project config: %w", err)
 		}
 	}
@@ -61,7 +128,7 @@ func New(projectPath string) (*Config, error) {
 // Has checks if a configuration key exists
 func (c *Config) Has(key string) bool {
 	section, subKey := splitKey(key)
-	if globalKeys[key] {
+	if isGlobal(key) {
 		sectionData, exists := c.global[section]
 		if !exists {
 			return false
@@ -78,10 +145,20 @@ func (c *Config) Has(key string) bool {
 }
 
 // Get retrieves a configuration value. Returns empty string if not found.
+// Encrypted secret values are decrypted, unlocking the config if needed (see
+// Unlock to handle failures, which return an empty string).
 func (c *Config) Get(key string) string {
 	section, subKey := splitKey(key)
-	if globalKeys[key] {
-		return c.global[section][subKey]
+	if isGlobal(key) {
+		value := c.global[section][subKey]
+		if isSecret(key) && strings.HasPrefix(value, encryptedPrefix) {
+			encryptionKey, err := c.key()
+			if err != nil {
+				return ""
+			}
+			value, _ = open(encryptionKey, key, value)
+		}
+		return value
 	}
 	return c.project[section][subKey]
 }
@@ -89,7 +166,16 @@ func (c *Config) Get(key string) string {
 // Set stores a configuration value and persists it to the appropriate location
 func (c *Config) Set(key, value string) error {
 	section, subKey := splitKey(key)
-	if globalKeys[key] {
+	if isGlobal(key) {
+		if isSecret(key) && c.Encryption() != "" {
+			encryptionKey, err := c.key()
+			if err != nil {
+				return err
+			}
+			if value, err = seal(encryptionKey, key, value); err != nil {
+				return err
+			}
+		}
 		if _, exists := c.global[section]; !exists {
 			c.global[section] = make(map[string]string)
 		}
@@ -106,7 +192,7 @@ func (c *Config) Set(key, value string) error {
 // Delete removes a configuration value
 func (c *Config) Delete(key string) error {
 	section, subKey := splitKey(key)
-	if globalKeys[key] {
+	if isGlobal(key) {
 		if sectionData, exists := c.global[section]; exists {
 			delete(sectionData, subKey)
 		}
@@ -141,11 +227,108 @@ func (c *Config) GetAllKeys() []string {
 
 // IsGlobalKey checks if a key is stored in global config
 func (c *Config) IsGlobalKey(key string) bool {
-	return globalKeys[key]
+	return isGlobal(key)
+}
+
+// IsSecretKey checks if a key holds a secret (e.g. credentials)
+func (c *Config) IsSecretKey(key string) bool {
+	return isSecret(key)
+}
+
+// Export returns a copy of all configuration values (global and project) grouped
+// by section. Secret values are only included when includeSecrets is true.
+func (c *Config) Export(includeSecrets bool) map[string]map[string]string {
+	result := make(map[string]map[string]string)
+	for _, scope := range []map[string]map[string]string{c.global, c.project} {
+		for section, sectionData := range scope {
+			if section == "encryption" {
+				continue // Only meaningful with this file's encrypted values
+			}
+			for subKey, value := range sectionData {
+				if isSecret(section + "." + subKey) {
+					if !includeSecrets {
+						continue
+					}
+					value = c.Get(section + "." + subKey)
+				}
+				if _, exists := result[section]; !exists {
+					result[section] = make(map[string]string)
+				}
+				result[section][subKey] = value
+			}
+		}
+	}
+	return result
+}
+
+// Entries returns the keys set in the global and project config files, with
+// their positions, sorted by file and line.
+func (c *Config) Entries() []Entry {
+	var entries []Entry
+	for _, scope := range []struct {
+		path string
+		data map[string]map[string]string
+	}{{c.globalPath, c.global}, {c.projectPath, c.project}} {
+		var scoped []Entry
+		for section, sectionData := range scope.data {
+			for subKey, value := range sectionData {
+				key := section + "." + subKey
+				scoped = append(scoped, Entry{Key: key, Value: value, Path: scope.path, Line: c.lines[scope.path][key]})
+			}
+		}
+		sort.Slice(scoped, func(i, j int) bool {
+			if scoped[i].Line != scoped[j].Line {
+				return scoped[i].Line < scoped[j].Line
+			}
+			return scoped[i].Key < scoped[j].Key
+		})
+		entries = append(entries, scoped...)
+	}
+	return entries
+}
+
+// Dir returns the global configuration directory. Other global state (caches,
+// logs) is kept alongside the global config file.
+func (c *Config) Dir() string {
+	return filepath.Dir(c.globalPath)
+}
+
+// Source returns the path of the file a key is stored in (or would be stored
+// in, if it isn't set yet).
+func (c *Config) Source(key string) string {
+	if isGlobal(key) {
+		return c.globalPath
+	}
+	return c.projectPath
+}
+
+// Keys returns the sub-keys set in a section, e.g. the account labels for the
+// "accounts" section.
+func (c *Config) Keys(section string) []string {
+	data := c.project[section]
+	if globalSections[section] {
+		data = c.global[section]
+	}
+	keys := make([]string, 0, len(data))
+	for subKey := range data {
+		keys = append(keys, subKey)
+	}
+	sort.Strings(keys)
+	return keys
 }
 
 // MARK: Internal helper functions
 
+func isGlobal(key string) bool {
+	section, _ := splitKey(key)
+	return globalKeys[key] || globalSections[section]
+}
+
+func isSecret(key string) bool {
+	section, _ := splitKey(key)
+	return secretKeys[key] || secretSections[section]
+}
+
 func splitKey(key string) (section, subKey string) {
 	parts := strings.SplitN(key, ".", 2)
 	if len(parts) != 2 {
@@ -167,7 +350,77 @@ func (c *Config) load(path string, data map[string]map[string]string) error {
 	if err != nil {
 		return err
 	}
-	return json.Unmarshal(content, &data)
+
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch err := json.Unmarshal(content, &data); {
+	case errors.As(err, &syntaxErr):
+		return &FileError{Path: path, Line: lineAt(content, syntaxErr.Offset), Err: err}
+	case errors.As(err, &typeErr):
+		problem := fmt.Errorf("expected a string value, got %s", typeErr.Value)
+		if !strings.Contains(typeErr.Field, ".") {
+			problem = fmt.Errorf("expected an object of settings, got %s", typeErr.Value)
+		}
+		return &FileError{Path: path, Line: lineAt(content, typeErr.Offset), Key: typeErr.Field, Err: problem}
+	case err != nil:
+		return &FileError{Path: path, Err: err}
+	}
+
+	c.setLines(path, content)
+	return nil
+}
+
+// setLines records the line of each key of a config file.
+func (c *Config) setLines(path string, content []byte) {
+	if c.lines == nil {
+		c.lines = make(map[string]map[string]int)
+	}
+	c.lines[path] = keyLines(content)
+}
+
+// keyLines returns the line of each key ("section.key") of a config file.
+func keyLines(content []byte) map[string]int {
+	lines := make(map[string]int)
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	var section string
+	depth := 0
+	expectKey := false
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return lines
+		}
+		switch token := token.(type) {
+		case json.Delim:
+			switch token {
+			case '{':
+				depth++
+				expectKey = true
+			case '}':
+				depth--
+				expectKey = depth > 0
+			}
+		case string:
+			if !expectKey {
+				expectKey = true
+				continue
+			}
+			if depth == 1 {
+				section = token
+			} else if depth == 2 {
+				lines[section+"."+token] = lineAt(content, decoder.InputOffset())
+			}
+			expectKey = false
+		default:
+			expectKey = true
+		}
+	}
+}
+
+// lineAt returns the (1-based) line of a byte offset.
+func lineAt(content []byte, offset int64) int {
+	offset = min(max(offset, 0), int64(len(content)))
+	return bytes.Count(content[:offset], []byte("\n")) + 1
 }
 
 func (c *Config) saveGlobal() error {
@@ -184,44 +437,128 @@ func (c *Config) save(path string, data map[string]map[string]string) error {
 		return fmt.Errorf("failed to marshal config: %w", err)
 	}
 
+	// The global config holds the session keys, so only the user can read it
+	dirMode, fileMode := os.FileMode(0o755), os.FileMode(0o644)
+	if path == c.globalPath {
+		dirMode, fileMode = 0o700, 0o600
+	}
+
 	dir := filepath.Dir(path)
-	if err := os.MkdirAll(dir, 0o755); err != nil {
+	if err := os.MkdirAll(dir, dirMode); err != nil {
 		return fmt.Errorf("failed to create config directory: %w", err)
 	}
 
-	if err := os.WriteFile(path, content, 0o644); err != nil {
+	if err := os.WriteFile(path, content, fileMode); err != nil {
 		return fmt.Errorf("failed to write config: %w", err)
 	}
+	// WriteFile keeps the permissions of existing files
+	if path == c.globalPath {
+		if err := restrictPermissions(path); err != nil {
+			return fmt.Errorf("failed to restrict config permissions: %w", err)
+		}
+	}
+	c.setLines(path, content)
 
 	return nil
 }
 
+// InsecureFile returns the path of the global config file if other users can
+// read it (and with it the session keys), e.g. because it was written by an
+// older version with 0644 permissions. Saving the config fixes it.
+func InsecureFile() (string, bool) {
+	if runtime.GOOS == "windows" {
+		return "", false
+	}
+	dir, err := getGlobalConfigPath()
+	if err != nil {
+		return "", false
+	}
+	path := filepath.Join(dir, "config.json")
+	info, err := os.Stat(path)
+	if err != nil || info.Mode().Perm()&0o077 == 0 {
+		return "", false
+	}
+	return path, true
+}
+
+// restrictPermissions removes the group and world permissions of a file,
+// keeping the user's (as narrowed by their umask).
+func restrictPermissions(path string) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if perm := info.Mode().Perm(); perm&0o077 != 0 {
+		return os.Chmod(path, perm&^0o077)
+	}
+	return nil
+}
+
+// PortableDir is the directory next to the sandworm binary which, if it
+// exists, holds the global config instead of the user's config directory
+// (portable mode, e.g. for a toolbox on a USB stick).
+const PortableDir = "sandworm-config"
+
+// DirEnv overrides the global config directory (set by --config-dir), taking
+// precedence over portable mode.
+const DirEnv = "SANDWORM_CONFIG_DIR"
+
+// getGlobalConfigPath returns the global config directory: DirEnv if set, the
+// portable directory if it exists, or the platform's user config directory.
 func getGlobalConfigPath() (string, error) {
-	var configDir string
+	if dir := os.Getenv(DirEnv); dir != "" {
+		return filepath.Abs(wsl.Normalize(dir))
+	}
+	if dir, ok := portableDir(); ok {
+		return dir, nil
+	}
 
+	var configDir string
 	switch runtime.GOOS {
-	case "darwin", "linux", "freebsd", "openbsd", "netbsd":
-		// Check XDG_CONFIG_HOME first
+	case "windows":
+		configDir = os.Getenv("APPDATA")
+		if configDir == "" {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return "", fmt.Errorf("APPDATA environment variable not set (use --config-dir or %s)", DirEnv)
+			}
+			configDir = filepath.Join(home, "AppData", "Roaming")
+		}
+
+	default:
+		// darwin, linux, the BSDs and anything else unix-like: check
+		// XDG_CONFIG_HOME first
 		if xdgHome := os.Getenv("XDG_CONFIG_HOME"); xdgHome != "" {
 			configDir = xdgHome
 		} else {
 			// Fall back to ~/.config
 			home, err := os.UserHomeDir()
 			if err != nil {
-				return "", fmt.Errorf("failed to get home directory: %w", err)
+				return "", fmt.Errorf("failed to get home directory (use --config-dir or %s): %w", DirEnv, err)
 			}
 			configDir = filepath.Join(home, ".config")
 		}
-
-	case "windows":
-		configDir = os.Getenv("APPDATA")
-		if configDir == "" {
-			return "", fmt.Errorf("APPDATA environment variable not set")
-		}
-
-	default:
-		return "", fmt.Errorf("unsupported operating system: %s", runtime.GOOS)
 	}
 
 	return filepath.Join(configDir, "sandworm"), nil
 }
+
+// portableDir returns the portable config directory, if it exists next to
+// the (symlink-resolved) sandworm binary.
+func portableDir() (string, bool) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", false
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+	dir := filepath.Join(filepath.Dir(exe), PortableDir)
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return "", false
+	}
+	return dir, true
+}
@@ -2,8 +2,13 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -159,3 +164,228 @@ func TestConfig(t *testing.T) {
 		}
 	})
 }
+
+func TestExport(t *testing.T) {
+	cfg := &Config{
+		global: map[string]map[string]string{
+			"claude": {"session_key": "secret"},
+		},
+		project: map[string]map[string]string{
+			"claude":    {"project_id": "project"},
+			"processor": {"follow_symlinks": "true"},
+		},
+	}
+
+	data := cfg.Export(false)
+	if _, exists := data["claude"]["session_key"]; exists {
+		t.Error("Expected session key to be excluded from export")
+	}
+	if data["claude"]["project_id"] != "project" {
+		t.Errorf("Expected project_id 'project', got '%s'", data["claude"]["project_id"])
+	}
+	if data["processor"]["follow_symlinks"] != "true" {
+		t.Errorf("Expected follow_symlinks 'true', got '%s'", data["processor"]["follow_symlinks"])
+	}
+
+	data = cfg.Export(true)
+	if data["claude"]["session_key"] != "secret" {
+		t.Errorf("Expected session key 'secret' when including secrets, got '%s'", data["claude"]["session_key"])
+	}
+}
+
+func TestGlobalSections(t *testing.T) {
+	cfg := &Config{
+		globalPath:  filepath.Join(t.TempDir(), "config.json"),
+		projectPath: filepath.Join(t.TempDir(), ".sandworm"),
+		global:      make(map[string]map[string]string),
+		project:     make(map[string]map[string]string),
+	}
+
+	if err := cfg.Set("accounts.work", "key-1"); err != nil {
+		t.Fatalf("Failed to set account: %v", err)
+	}
+	if err := cfg.Set("accounts.personal", "key-2"); err != nil {
+		t.Fatalf("Failed to set account: %v", err)
+	}
+
+	if !cfg.IsGlobalKey("accounts.work") {
+		t.Error("Expected accounts section to be global")
+	}
+	if !cfg.IsSecretKey("accounts.work") {
+		t.Error("Expected accounts section to be secret")
+	}
+	if cfg.global["accounts"]["work"] != "key-1" {
+		t.Error("Expected account to be stored in global config")
+	}
+	if got := cfg.Keys("accounts"); len(got) != 2 || got[0] != "personal" || got[1] != "work" {
+		t.Errorf("Expected sorted keys [personal work], got %v", got)
+	}
+
+	// Commands in a project file (e.g. of a cloned repository) are never run
+	cfg.project["converters"] = map[string]string{"pdf": "*.pdf: pdftotext {} -"}
+	if got := cfg.Keys("converters"); len(got) != 0 {
+		t.Errorf("Expected project converters to be ignored, got %v", got)
+	}
+	if cfg.Get("converters.pdf") != "" {
+		t.Error("Expected project converters to be ignored")
+	}
+}
+
+func TestFileErrors(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	tests := map[string]struct {
+		content string
+		want    string
+	}{
+		"syntax error": {
+			content: "{\n  \"processor\": {\n    \"symbol_index\": \"true\",\n  }\n}\n",
+			want:    ".sandworm:4: invalid character '}' looking for beginning of object key string",
+		},
+		"unquoted value": {
+			content: "{\n  \"processor\": {\n    \"symbol_index\": true\n  }\n}\n",
+			want:    ".sandworm:3: processor.symbol_index: expected a string value, got bool",
+		},
+		"section not an object": {
+			content: "{\n  \"processor\": [\"symbol_index\"]\n}\n",
+			want:    ".sandworm:2: processor: expected an object of settings, got array",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, ".sandworm"), []byte(tt.content), 0o644); err != nil {
+				t.Fatalf("Failed to write config: %v", err)
+			}
+			_, err := New(dir)
+			var fileErr *FileError
+			if !errors.As(err, &fileErr) {
+				t.Fatalf("Expected a FileError, got %v", err)
+			}
+			if got := strings.TrimPrefix(err.Error(), dir+string(filepath.Separator)); got != tt.want {
+				t.Errorf("Unexpected error:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEntries(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	dir := t.TempDir()
+	content := "{\n  \"processor\": {\n    \"symbol_index\": \"true\",\n    \"max_files\": \"10\"\n  },\n  \"claude\": {\"project_id\": \"p-1\"}\n}\n"
+	if err := os.WriteFile(filepath.Join(dir, ".sandworm"), []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	cfg, err := New(dir)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	entries := func() []string {
+		var got []string
+		for _, e := range cfg.Entries() {
+			got = append(got, fmt.Sprintf("%s:%d %s=%s", filepath.Base(e.Path), e.Line, e.Key, e.Value))
+		}
+		return got
+	}
+	want := []string{
+		".sandworm:3 processor.symbol_index=true",
+		".sandworm:4 processor.max_files=10",
+		".sandworm:6 claude.project_id=p-1",
+	}
+	if got := entries(); !slices.Equal(got, want) {
+		t.Errorf("Unexpected entries:\n%q\nwant:\n%q", got, want)
+	}
+
+	// Saving rewrites the file (sorted), moving the keys
+	if err := cfg.Set("processor.linguist", "false"); err != nil {
+		t.Fatalf("Failed to set value: %v", err)
+	}
+	want = []string{
+		".sandworm:3 claude.project_id=p-1",
+		".sandworm:6 processor.linguist=false",
+		".sandworm:7 processor.max_files=10",
+		".sandworm:8 processor.symbol_index=true",
+	}
+	if got := entries(); !slices.Equal(got, want) {
+		t.Errorf("Unexpected entries:\n%q\nwant:\n%q", got, want)
+	}
+}
+
+func TestGlobalConfigPath(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv(DirEnv, "")
+
+	want := filepath.Join(os.Getenv("XDG_CONFIG_HOME"), "sandworm")
+	if got, err := getGlobalConfigPath(); err != nil || got != want {
+		t.Errorf("Expected the user config directory %s, got %s (%v)", want, got, err)
+	}
+
+	// Portable mode: a config directory next to the binary
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatalf("Failed to get executable: %v", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+	portable := filepath.Join(filepath.Dir(exe), PortableDir)
+	if err := os.Mkdir(portable, 0o755); err != nil {
+		t.Skipf("Unable to create a directory next to the test binary: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(portable) })
+	if got, err := getGlobalConfigPath(); err != nil || got != portable {
+		t.Errorf("Expected the portable directory %s, got %s (%v)", portable, got, err)
+	}
+
+	// The override takes precedence over portable mode
+	override := t.TempDir()
+	t.Setenv(DirEnv, override)
+	cfg, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create config: %v", err)
+	}
+	if err := cfg.Set("claude.session_key", "key"); err != nil {
+		t.Fatalf("Failed to set value: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(override, "config.json")); err != nil {
+		t.Errorf("Expected the global config in the override directory: %v", err)
+	}
+}
+
+func TestSecretPermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("File permissions aren't enforced on Windows")
+	}
+	t.Setenv(DirEnv, t.TempDir())
+
+	// Files written by older versions are readable by everyone
+	path := filepath.Join(os.Getenv(DirEnv), "config.json")
+	if err := os.WriteFile(path, []byte("{}"), 0o644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	if err := os.Chmod(path, 0o644); err != nil {
+		t.Fatalf("Failed to change permissions: %v", err)
+	}
+	if got, insecure := InsecureFile(); !insecure || got != path {
+		t.Errorf("Expected %s to be reported as insecure, got %q", path, got)
+	}
+
+	cfg, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create config: %v", err)
+	}
+	if err := cfg.Set("claude.session_key", "secret"); err != nil {
+		t.Fatalf("Failed to set session key: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Failed to stat config: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("Expected 0600 permissions, got %o", perm)
+	}
+	if _, insecure := InsecureFile(); insecure {
+		t.Error("Expected the saved config not to be reported as insecure")
+	}
+}
@@ -0,0 +1,160 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// journalSize is the number of changes the journal keeps, the oldest being
+// dropped first.
+const journalSize = 20
+
+// Change is a change of a config value, recorded in the journal so that it
+// can be undone (see Undo).
+type Change struct {
+	Time     time.Time `json:"time"`
+	Key      string    `json:"key"`
+	Path     string    `json:"path"`               // Absolute path of the config file the key is stored in
+	Previous *string   `json:"previous,omitempty"` // Value before the change; nil if the key wasn't set
+	Value    *string   `json:"value,omitempty"`    // Value after the change; nil if the key was unset
+}
+
+// SetUndoable is Set, recording the change in the journal for Undo. It's
+// meant for changes made by hand (e.g. 'config set'). Secrets aren't
+// recorded, as the journal would keep them in the clear.
+func (c *Config) SetUndoable(key, value string) error {
+	change, err := c.change(key)
+	if err != nil {
+		return err
+	}
+	if err := c.Set(key, value); err != nil {
+		return err
+	}
+	if isSecret(key) || (change.Previous != nil && *change.Previous == value) {
+		return nil
+	}
+	change.Value = &value
+	return c.record(change)
+}
+
+// DeleteUndoable is Delete, recording the change in the journal for Undo
+// (see SetUndoable).
+func (c *Config) DeleteUndoable(key string) error {
+	change, err := c.change(key)
+	if err != nil {
+		return err
+	}
+	if err := c.Delete(key); err != nil {
+		return err
+	}
+	if isSecret(key) || change.Previous == nil {
+		return nil
+	}
+	return c.record(change)
+}
+
+// Undo reverts the last change recorded in the journal for the global config
+// or this project's config, and removes it from the journal. It returns nil
+// if there is no change to undo.
+func (c *Config) Undo() (*Change, error) {
+	changes, err := c.readJournal()
+	if err != nil {
+		return nil, err
+	}
+	paths := make([]string, 0, 2)
+	for _, path := range []string{c.globalPath, c.projectPath} {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve config path: %w", err)
+		}
+		paths = append(paths, abs)
+	}
+
+	for i := len(changes) - 1; i >= 0; i-- {
+		change := changes[i]
+		if !slices.Contains(paths, change.Path) {
+			continue
+		}
+		if change.Previous == nil {
+			err = c.Delete(change.Key)
+		} else {
+			err = c.Set(change.Key, *change.Previous)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &change, c.writeJournal(slices.Delete(changes, i, i+1))
+	}
+	return nil, nil
+}
+
+// MARK: Journal helpers
+
+// journalPath returns the path of the journal, kept with the global config
+// as it records the changes of every project.
+func (c *Config) journalPath() string {
+	return filepath.Join(c.Dir(), "journal.json")
+}
+
+// change returns the change of a key about to be set or deleted, with its
+// current value.
+func (c *Config) change(key string) (Change, error) {
+	path, err := filepath.Abs(c.Source(key))
+	if err != nil {
+		return Change{}, fmt.Errorf("failed to resolve config path: %w", err)
+	}
+	change := Change{Time: time.Now(), Key: key, Path: path}
+	if c.Has(key) && !isSecret(key) {
+		previous := c.Get(key)
+		change.Previous = &previous
+	}
+	return change, nil
+}
+
+// record appends a change to the journal.
+func (c *Config) record(change Change) error {
+	changes, err := c.readJournal()
+	if err != nil {
+		return err
+	}
+	changes = append(changes, change)
+	if len(changes) > journalSize {
+		changes = changes[len(changes)-journalSize:]
+	}
+	return c.writeJournal(changes)
+}
+
+func (c *Config) readJournal() ([]Change, error) {
+	data, err := os.ReadFile(c.journalPath())
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config journal: %w", err)
+	}
+	var changes []Change
+	if err := json.Unmarshal(data, &changes); err != nil {
+		return nil, fmt.Errorf("failed to parse config journal: %w", err)
+	}
+	return changes, nil
+}
+
+func (c *Config) writeJournal(changes []Change) error {
+	data, err := json.MarshalIndent(changes, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal config journal: %w", err)
+	}
+	if err := os.MkdirAll(c.Dir(), 0o700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(c.journalPath(), data, 0o600); err != nil {
+		return fmt.Errorf("failed to write config journal: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package config
+
+import "testing"
+
+func TestUndo(t *testing.T) {
+	t.Setenv(DirEnv, t.TempDir())
+	project := t.TempDir()
+	cfg, err := New(project)
+	if err != nil {
+		t.Fatalf("Failed to create config: %v", err)
+	}
+
+	steps := []func() error{
+		func() error { return cfg.SetUndoable("claude.project_id", "p-1") },
+		func() error { return cfg.SetUndoable("claude.project_id", "p-2") },
+		func() error { return cfg.SetUndoable("claude.project_id", "p-2") }, // Unchanged, not recorded
+		func() error { return cfg.DeleteUndoable("claude.project_id") },
+		func() error { return cfg.DeleteUndoable("claude.organization_id") },    // Not set, not recorded
+		func() error { return cfg.SetUndoable("claude.session_key", "secret") }, // Secret, not recorded
+	}
+	for _, step := range steps {
+		if err := step(); err != nil {
+			t.Fatalf("Failed to change config: %v", err)
+		}
+	}
+
+	// Changes of other projects are left alone
+	other, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create config: %v", err)
+	}
+	if err := other.SetUndoable("claude.project_id", "other"); err != nil {
+		t.Fatalf("Failed to change config: %v", err)
+	}
+
+	for _, want := range []string{"p-2", "p-1", ""} {
+		change, err := cfg.Undo()
+		if err != nil || change == nil {
+			t.Fatalf("Expected a change to undo, got %v, %v", change, err)
+		}
+		if got := cfg.Get("claude.project_id"); got != want {
+			t.Errorf("Expected claude.project_id = %q after undo, got %q", want, got)
+		}
+	}
+	if change, err := cfg.Undo(); change != nil || err != nil {
+		t.Errorf("Expected nothing left to undo, got %+v, %v", change, err)
+	}
+
+	// Undone changes are persisted
+	reloaded, err := New(project)
+	if err != nil {
+		t.Fatalf("Failed to reload config: %v", err)
+	}
+	if reloaded.Has("claude.project_id") || reloaded.Get("claude.session_key") != "secret" {
+		t.Errorf("Unexpected config after undo: %v", reloaded.Export(true))
+	}
+	if change, err := other.Undo(); err != nil || change == nil || change.Path != other.projectPath {
+		t.Errorf("Expected the other project's change to be undoable, got %+v, %v", change, err)
+	}
+}
+
+func TestJournalSize(t *testing.T) {
+	t.Setenv(DirEnv, t.TempDir())
+	cfg, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create config: %v", err)
+	}
+	for i := range journalSize + 5 {
+		if err := cfg.SetUndoable("claude.document_id", string(rune('a'+i))); err != nil {
+			t.Fatalf("Failed to change config: %v", err)
+		}
+	}
+	changes, err := cfg.readJournal()
+	if err != nil || len(changes) != journalSize {
+		t.Errorf("Expected %d changes in the journal, got %d (%v)", journalSize, len(changes), err)
+	}
+}
@@ -0,0 +1,269 @@
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/holonoms/sandworm/internal/keychain"
+)
+
+// Methods protecting the secret values of the global config at rest.
+const (
+	EncryptPassphrase = "passphrase" // Key derived from a passphrase
+	EncryptKeychain   = "keychain"   // Random key stored in the OS keychain
+)
+
+// EncryptionMethods lists the supported encryption methods.
+var EncryptionMethods = []string{EncryptPassphrase, EncryptKeychain}
+
+// PassphraseEnv holds the passphrase of an encrypted config, for
+// non-interactive use (e.g. the daemon).
+const PassphraseEnv = "SANDWORM_PASSPHRASE"
+
+// Passphrase asks for the passphrase of an encrypted config, twice when
+// confirm is set (for a new passphrase). It's set by the CLI; without it,
+// the passphrase must be set in PassphraseEnv.
+var Passphrase func(confirm bool) (string, error)
+
+// Keys of the encryption settings, stored in the global config.
+const (
+	encryptionMethod = "encryption.method"
+	encryptionSalt   = "encryption.salt"
+	encryptionCheck  = "encryption.check" // A known value, to verify the key
+)
+
+const (
+	encryptedPrefix  = "enc:"
+	checkValue       = "sandworm"
+	saltSize         = 16
+	keySize          = 32 // AES-256
+	pbkdf2Iterations = 600_000
+)
+
+// unlockedKeys caches the keys unlocked by this process, by check value, so
+// that the passphrase is only asked once.
+var unlockedKeys = make(map[string][]byte)
+
+// Encryption returns the method protecting the secret values of the global
+// config, or "" if they're stored in the clear.
+func (c *Config) Encryption() string {
+	return c.Get(encryptionMethod)
+}
+
+// Unlock derives (or fetches from the keychain) the key of an encrypted
+// config, asking for the passphrase if needed, so that its secret values can
+// be read and set. It does nothing for unencrypted configs.
+func (c *Config) Unlock() error {
+	_, err := c.key()
+	return err
+}
+
+// Encrypt encrypts the secret values of the global config, and those set
+// later, with a key derived from a passphrase or stored in the OS keychain.
+func (c *Config) Encrypt(method string) error {
+	if c.Encryption() != "" {
+		return fmt.Errorf("the config is already encrypted (with a %s)", c.Encryption())
+	}
+
+	var key, salt []byte
+	switch method {
+	case EncryptPassphrase:
+		passphrase, err := readPassphrase(true)
+		if err != nil {
+			return err
+		}
+		if passphrase == "" {
+			return errors.New("the passphrase can't be empty")
+		}
+		salt = make([]byte, saltSize)
+		_, _ = rand.Read(salt)
+		if key, err = deriveKey(passphrase, salt); err != nil {
+			return err
+		}
+	case EncryptKeychain:
+		key = make([]byte, keySize)
+		_, _ = rand.Read(key)
+		if err := keychain.Set(c.globalPath, base64.StdEncoding.EncodeToString(key)); err != nil {
+			return fmt.Errorf("failed to store the key in the keychain: %w", err)
+		}
+	default:
+		return fmt.Errorf("invalid encryption method '%s' (must be one of %s)", method, strings.Join(EncryptionMethods, ", "))
+	}
+
+	for section, sectionData := range c.global {
+		for subKey, value := range sectionData {
+			if !isSecret(section+"."+subKey) || strings.HasPrefix(value, encryptedPrefix) {
+				continue
+			}
+			sealed, err := seal(key, section+"."+subKey, value)
+			if err != nil {
+				return err
+			}
+			sectionData[subKey] = sealed
+		}
+	}
+	check, err := seal(key, encryptionCheck, checkValue)
+	if err != nil {
+		return err
+	}
+	c.global["encryption"] = map[string]string{"method": method, "check": check}
+	if salt != nil {
+		c.global["encryption"]["salt"] = base64.StdEncoding.EncodeToString(salt)
+	}
+	unlockedKeys[check] = key
+	return c.saveGlobal()
+}
+
+// Decrypt stores the secret values of an encrypted global config in the
+// clear again.
+func (c *Config) Decrypt() error {
+	method := c.Encryption()
+	if method == "" {
+		return errors.New("the config isn't encrypted")
+	}
+	key, err := c.key()
+	if err != nil {
+		return err
+	}
+
+	for section, sectionData := range c.global {
+		for subKey, value := range sectionData {
+			if !isSecret(section+"."+subKey) || !strings.HasPrefix(value, encryptedPrefix) {
+				continue
+			}
+			plain, err := open(key, section+"."+subKey, value)
+			if err != nil {
+				return fmt.Errorf("failed to decrypt %s.%s: %w", section, subKey, err)
+			}
+			sectionData[subKey] = plain
+		}
+	}
+	delete(c.global, "encryption")
+	if err := c.saveGlobal(); err != nil {
+		return err
+	}
+
+	if method == EncryptKeychain {
+		if err := keychain.Delete(c.globalPath); err != nil {
+			return fmt.Errorf("failed to remove the key from the keychain: %w", err)
+		}
+	}
+	return nil
+}
+
+// MARK: Helpers
+
+// key returns the key of an encrypted config (nil if it isn't encrypted),
+// unlocking it if needed.
+func (c *Config) key() ([]byte, error) {
+	method := c.Encryption()
+	if method == "" {
+		return nil, nil
+	}
+	check := c.Get(encryptionCheck)
+	if key, ok := unlockedKeys[check]; ok {
+		return key, nil
+	}
+
+	var key []byte
+	switch method {
+	case EncryptPassphrase:
+		salt, err := base64.StdEncoding.DecodeString(c.Get(encryptionSalt))
+		if err != nil || len(salt) == 0 {
+			return nil, fmt.Errorf("invalid %s in %s", encryptionSalt, c.globalPath)
+		}
+		passphrase, err := readPassphrase(false)
+		if err != nil {
+			return nil, err
+		}
+		if key, err = deriveKey(passphrase, salt); err != nil {
+			return nil, err
+		}
+	case EncryptKeychain:
+		secret, err := keychain.Get(c.globalPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the config key from the keychain: %w", err)
+		}
+		if key, err = base64.StdEncoding.DecodeString(secret); err != nil {
+			return nil, fmt.Errorf("invalid config key in the keychain: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("invalid %s '%s' in %s", encryptionMethod, method, c.globalPath)
+	}
+
+	if value, err := open(key, encryptionCheck, check); err != nil || value != checkValue {
+		if method == EncryptPassphrase {
+			return nil, errors.New("incorrect passphrase for the encrypted config")
+		}
+		return nil, errors.New("the keychain key doesn't match the encrypted config")
+	}
+	unlockedKeys[check] = key
+	return key, nil
+}
+
+// readPassphrase returns the passphrase from PassphraseEnv, or asks for it.
+func readPassphrase(confirm bool) (string, error) {
+	if passphrase := os.Getenv(PassphraseEnv); passphrase != "" {
+		return passphrase, nil
+	}
+	if Passphrase == nil {
+		return "", fmt.Errorf("the config is encrypted: set %s to its passphrase", PassphraseEnv)
+	}
+	return Passphrase(confirm)
+}
+
+// deriveKey derives an AES-256 key from a passphrase.
+func deriveKey(passphrase string, salt []byte) ([]byte, error) {
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, pbkdf2Iterations, keySize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	return key, nil
+}
+
+// seal encrypts the value of a key with AES-GCM. The key name is
+// authenticated too, so that encrypted values can't be swapped between keys.
+func seal(key []byte, name, value string) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	_, _ = rand.Read(nonce)
+	sealed := gcm.Seal(nonce, nonce, []byte(value), []byte(name))
+	return encryptedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// open decrypts a value encrypted by seal.
+func open(key []byte, name, value string) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, encryptedPrefix))
+	if err != nil || len(data) < gcm.NonceSize() {
+		return "", errors.New("malformed encrypted value")
+	}
+	plain, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], []byte(name))
+	if err != nil {
+		return "", errors.New("unable to decrypt value")
+	}
+	return string(plain), nil
+}
+
+// newGCM returns an AES-GCM cipher for a key.
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,95 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEncryption(t *testing.T) {
+	t.Setenv(DirEnv, t.TempDir())
+	t.Setenv(PassphraseEnv, "correct horse")
+	t.Cleanup(func() { clear(unlockedKeys) })
+
+	cfg, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create config: %v", err)
+	}
+	if err := cfg.Set("claude.session_key", "sk-secret"); err != nil {
+		t.Fatalf("Failed to set session key: %v", err)
+	}
+	if err := cfg.Set("claude.default_account", "work"); err != nil {
+		t.Fatalf("Failed to set default account: %v", err)
+	}
+	if err := cfg.Encrypt(EncryptPassphrase); err != nil {
+		t.Fatalf("Failed to encrypt config: %v", err)
+	}
+	// Secrets set later are encrypted too
+	if err := cfg.Set("accounts.work", "sk-work"); err != nil {
+		t.Fatalf("Failed to set account: %v", err)
+	}
+
+	path := filepath.Join(os.Getenv(DirEnv), "config.json")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	if strings.Contains(string(data), "sk-") {
+		t.Errorf("Expected secrets to be encrypted, got:\n%s", data)
+	}
+	if !strings.Contains(string(data), `"default_account": "work"`) {
+		t.Errorf("Expected other values in the clear, got:\n%s", data)
+	}
+
+	// A new process asks for the passphrase again
+	clear(unlockedKeys)
+	cfg, err = New("")
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if got := cfg.Get("claude.session_key"); got != "sk-secret" {
+		t.Errorf("Expected the decrypted session key, got %q", got)
+	}
+	if got := cfg.Export(true)["accounts"]["work"]; got != "sk-work" {
+		t.Errorf("Expected the decrypted account in exports, got %q", got)
+	}
+	if _, ok := cfg.Export(true)["encryption"]; ok {
+		t.Error("Expected the encryption settings not to be exported")
+	}
+
+	clear(unlockedKeys)
+	t.Setenv(PassphraseEnv, "wrong")
+	if err := cfg.Unlock(); err == nil || !strings.Contains(err.Error(), "incorrect passphrase") {
+		t.Errorf("Expected an incorrect passphrase error, got %v", err)
+	}
+	if got := cfg.Get("claude.session_key"); got != "" {
+		t.Errorf("Expected no session key without the passphrase, got %q", got)
+	}
+
+	t.Setenv(PassphraseEnv, "correct horse")
+	if err := cfg.Decrypt(); err != nil {
+		t.Fatalf("Failed to decrypt config: %v", err)
+	}
+	data, err = os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	if !strings.Contains(string(data), `"session_key": "sk-secret"`) || strings.Contains(string(data), "encryption") {
+		t.Errorf("Expected secrets in the clear, got:\n%s", data)
+	}
+}
+
+func TestSealBindsKeyName(t *testing.T) {
+	key := make([]byte, keySize)
+	sealed, err := seal(key, "accounts.work", "sk-work")
+	if err != nil {
+		t.Fatalf("Failed to seal value: %v", err)
+	}
+	if got, err := open(key, "accounts.work", sealed); err != nil || got != "sk-work" {
+		t.Errorf("Unexpected value: %q, %v", got, err)
+	}
+	if _, err := open(key, "claude.session_key", sealed); err == nil {
+		t.Error("Expected values to be bound to their key")
+	}
+}
@@ -0,0 +1,398 @@
+package convert
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// How API specs (OpenAPI/Swagger, GraphQL schemas) are handled
+const (
+	APISpecSummary = "summary" // Condense specs into an endpoint/type summary (default)
+	APISpecFull    = "full"    // Include specs as is
+)
+
+// APISpecModes lists the valid API spec modes.
+var APISpecModes = []string{APISpecSummary, APISpecFull}
+
+// openAPIRE matches the top-level openapi/swagger key of an OpenAPI spec, in
+// YAML or JSON.
+var openAPIRE = regexp.MustCompile(`(?m)^[\s{]*["']?(openapi|swagger)["']?\s*:`)
+
+// httpMethods are the operations of an OpenAPI path item, in display order.
+var httpMethods = []string{"get", "post", "put", "patch", "delete", "head", "options", "trace"}
+
+func init() {
+	Register(func(opts Options) Converter { return apiSpecConverter{mode: opts.APISpecs} })
+}
+
+// apiSpecConverter condenses OpenAPI specs and GraphQL schemas, which
+// routinely exceed a megabyte, into a compact summary of their endpoints and
+// types.
+type apiSpecConverter struct {
+	mode string
+}
+
+func (apiSpecConverter) Name() string { return "apispec" }
+
+// Match accepts GraphQL schemas, and YAML/JSON files declaring an OpenAPI
+// (or Swagger) version.
+func (c apiSpecConverter) Match(relPath string, head []byte) bool {
+	if c.mode == APISpecFull {
+		return false
+	}
+	switch strings.ToLower(path.Ext(relPath)) {
+	case ".graphql", ".graphqls", ".gql":
+		return true
+	case ".yaml", ".yml", ".json":
+		return head != nil && openAPIRE.Match(head)
+	}
+	return false
+}
+
+// Convert returns the summary of a spec. Small specs, and specs that can't be
+// parsed, are returned unchanged.
+func (c apiSpecConverter) Convert(_ context.Context, f File) ([]byte, error) {
+	if len(f.Content) <= condenseMinSize {
+		return f.Content, nil
+	}
+	switch strings.ToLower(path.Ext(f.Path)) {
+	case ".graphql", ".graphqls", ".gql":
+		return condenseGraphQL(f.Content), nil
+	}
+
+	var spec map[string]any
+	if err := yaml.Unmarshal(f.Content, &spec); err != nil || spec == nil {
+		return f.Content, nil
+	}
+	return condenseOpenAPI(spec), nil
+}
+
+// MARK: OpenAPI
+
+// condenseOpenAPI summarizes an OpenAPI 3 or Swagger 2 spec: one line per
+// operation and per schema.
+func condenseOpenAPI(spec map[string]any) []byte {
+	var b bytes.Buffer
+
+	version := str(spec["openapi"])
+	kind := "OpenAPI"
+	if version == "" {
+		version, kind = str(spec["swagger"]), "Swagger"
+	}
+	info := mapOf(spec["info"])
+	fmt.Fprintf(&b, "%s %s: %s", kind, version, str(info["title"]))
+	if v := str(info["version"]); v != "" {
+		fmt.Fprintf(&b, " (version %s)", v)
+	}
+	b.WriteString("\n")
+
+	var servers []string
+	for _, server := range listOf(spec["servers"]) {
+		if u := str(mapOf(server)["url"]); u != "" {
+			servers = append(servers, u)
+		}
+	}
+	if host := str(spec["host"]); host != "" {
+		servers = append(servers, host+str(spec["basePath"]))
+	}
+	if len(servers) > 0 {
+		fmt.Fprintf(&b, "Servers: %s\n", strings.Join(servers, ", "))
+	}
+
+	// Operations, sorted by path
+	paths := mapOf(spec["paths"])
+	var operations []string
+	for _, p := range sortedKeys(paths) {
+		item := mapOf(paths[p])
+		shared := listOf(item["parameters"])
+		for _, method := range httpMethods {
+			op := mapOf(item[method])
+			if op == nil {
+				continue
+			}
+			operations = append(operations, operationLine(method, p, op, shared))
+		}
+	}
+
+	// Schemas of OpenAPI 3 (components) or Swagger 2 (definitions)
+	schemas := mapOf(mapOf(spec["components"])["schemas"])
+	if schemas == nil {
+		schemas = mapOf(spec["definitions"])
+	}
+
+	fmt.Fprintf(&b, "[condensed by sandworm: %d operations, %d schemas; set processor.api_specs to full for the whole spec]\n", len(operations), len(schemas))
+	if len(operations) > 0 {
+		b.WriteString("\nENDPOINTS:\n")
+		for _, line := range operations {
+			b.WriteString(line + "\n")
+		}
+	}
+	if len(schemas) > 0 {
+		b.WriteString("\nSCHEMAS:\n")
+		for _, name := range sortedKeys(schemas) {
+			b.WriteString(schemaLine(name, mapOf(schemas[name])) + "\n")
+		}
+	}
+	return b.Bytes()
+}
+
+// operationLine summarizes an operation, e.g.
+// "POST /users - Create a user (body: User) -> 201 User".
+func operationLine(method, p string, op map[string]any, shared []any) string {
+	line := fmt.Sprintf("%-7s %s", strings.ToUpper(method), p)
+	if summary := str(op["summary"]); summary != "" {
+		line += " - " + oneLine(summary)
+	} else if id := str(op["operationId"]); id != "" {
+		line += " - " + id
+	}
+	if op["deprecated"] == true {
+		line += " [deprecated]"
+	}
+
+	var params []string
+	var body string
+	for _, param := range append(slices.Clone(shared), listOf(op["parameters"])...) {
+		param := mapOf(param)
+		if ref := str(param["$ref"]); ref != "" {
+			params = append(params, refName(ref))
+			continue
+		}
+		name, in := str(param["name"]), str(param["in"])
+		if in == "body" { // Swagger 2
+			body = typeName(mapOf(param["schema"]))
+			continue
+		}
+		if param["required"] != true {
+			name += "?"
+		}
+		params = append(params, name)
+	}
+	if requestBody := mapOf(op["requestBody"]); requestBody != nil {
+		if ref := str(requestBody["$ref"]); ref != "" {
+			body = refName(ref)
+		} else {
+			body = contentType(mapOf(requestBody["content"]))
+		}
+	}
+
+	var details []string
+	if len(params) > 0 {
+		details = append(details, "params: "+strings.Join(params, ", "))
+	}
+	if body != "" {
+		details = append(details, "body: "+body)
+	}
+	if len(details) > 0 {
+		line += " (" + strings.Join(details, "; ") + ")"
+	}
+
+	// The first successful response
+	responses := mapOf(op["responses"])
+	for _, code := range sortedKeys(responses) {
+		if !strings.HasPrefix(code, "2") {
+			continue
+		}
+		response := mapOf(responses[code])
+		result := code
+		if t := typeName(mapOf(response["schema"])); t != "" { // Swagger 2
+			result += " " + t
+		} else if t := contentType(mapOf(response["content"])); t != "" {
+			result += " " + t
+		} else if ref := str(response["$ref"]); ref != "" {
+			result += " " + refName(ref)
+		}
+		line += " -> " + result
+		break
+	}
+	return line
+}
+
+// schemaLine summarizes a schema, e.g. "User {id: string, email?: string}".
+func schemaLine(name string, schema map[string]any) string {
+	if enum := listOf(schema["enum"]); len(enum) > 0 {
+		return fmt.Sprintf("%s enum(%s)", name, joinValues(enum))
+	}
+	props := mapOf(schema["properties"])
+	if len(props) == 0 {
+		if t := typeName(schema); t != "" {
+			return name + " = " + t
+		}
+		return name
+	}
+
+	required := make(map[string]bool)
+	for _, r := range listOf(schema["required"]) {
+		required[str(r)] = true
+	}
+	var fields []string
+	for _, prop := range sortedKeys(props) {
+		field := prop
+		if !required[prop] {
+			field += "?"
+		}
+		fields = append(fields, field+": "+typeName(mapOf(props[prop])))
+	}
+	return fmt.Sprintf("%s {%s}", name, strings.Join(fields, ", "))
+}
+
+// typeName names the type of a schema: the referenced schema's name, an
+// array ([]T), a combination (A | B, A & B) or a primitive type.
+func typeName(schema map[string]any) string {
+	if schema == nil {
+		return ""
+	}
+	if ref := str(schema["$ref"]); ref != "" {
+		return refName(ref)
+	}
+	for _, combination := range []struct{ key, sep string }{{"oneOf", " | "}, {"anyOf", " | "}, {"allOf", " & "}} {
+		if variants := listOf(schema[combination.key]); len(variants) > 0 {
+			names := make([]string, len(variants))
+			for i, v := range variants {
+				names[i] = typeName(mapOf(v))
+			}
+			return strings.Join(names, combination.sep)
+		}
+	}
+
+	t := str(schema["type"])
+	if types := listOf(schema["type"]); len(types) > 0 { // OpenAPI 3.1 type lists
+		t = joinValues(types)
+	}
+	switch {
+	case t == "array":
+		return "[]" + typeName(mapOf(schema["items"]))
+	case len(listOf(schema["enum"])) > 0:
+		return "enum(" + joinValues(listOf(schema["enum"])) + ")"
+	case t == "" && schema["properties"] != nil:
+		return "object"
+	}
+	if format := str(schema["format"]); format != "" {
+		t += "(" + format + ")"
+	}
+	return t
+}
+
+// contentType returns the type of the first media type of a content map,
+// JSON preferred.
+func contentType(content map[string]any) string {
+	if content == nil {
+		return ""
+	}
+	media := mapOf(content["application/json"])
+	if keys := sortedKeys(content); media == nil && len(keys) > 0 {
+		media = mapOf(content[keys[0]])
+	}
+	return typeName(mapOf(media["schema"]))
+}
+
+// refName returns the name of a referenced schema ("#/components/schemas/User"
+// -> "User").
+func refName(ref string) string {
+	return ref[strings.LastIndex(ref, "/")+1:]
+}
+
+// MARK: GraphQL
+
+var (
+	graphQLBlockStringRE = regexp.MustCompile(`(?s)""".*?"""`)
+	graphQLCommaRE       = regexp.MustCompile(`,(\s*,)+`)
+	graphQLSpaceRE       = regexp.MustCompile(`([{(]), |, ([})])`)
+)
+
+// condenseGraphQL condenses a GraphQL schema: descriptions and comments are
+// removed, and each definition is collapsed onto a single line.
+func condenseGraphQL(content []byte) []byte {
+	text := graphQLBlockStringRE.ReplaceAllString(string(content), "")
+
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		// Single-line descriptions
+		if line == "" || (len(line) >= 2 && strings.HasPrefix(line, `"`) && strings.HasSuffix(line, `"`)) {
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	var b strings.Builder
+	var definitions int
+	depth := 0
+	for _, line := range lines {
+		if depth == 0 {
+			if b.Len() > 0 {
+				b.WriteString("\n")
+			}
+			definitions++
+		} else {
+			b.WriteString(", ")
+		}
+		b.WriteString(line)
+		depth += strings.Count(line, "{") + strings.Count(line, "(") - strings.Count(line, "}") - strings.Count(line, ")")
+	}
+	condensed := graphQLCommaRE.ReplaceAllString(b.String(), ",")
+	condensed = graphQLSpaceRE.ReplaceAllString(condensed, "$1 $2")
+
+	header := fmt.Sprintf("[condensed by sandworm: %d definitions, descriptions and comments removed; set processor.api_specs to full for the whole schema]\n", definitions)
+	return []byte(header + condensed + "\n")
+}
+
+// MARK: Helpers
+
+// mapOf returns a value as a map, or nil.
+func mapOf(v any) map[string]any {
+	m, _ := v.(map[string]any)
+	return m
+}
+
+// listOf returns a value as a list, or nil.
+func listOf(v any) []any {
+	l, _ := v.([]any)
+	return l
+}
+
+// str returns a scalar value as a string, or "".
+func str(v any) string {
+	switch v := v.(type) {
+	case nil, map[string]any, []any:
+		return ""
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// joinValues joins scalar values with commas.
+func joinValues(values []any) string {
+	s := make([]string, len(values))
+	for i, v := range values {
+		s[i] = str(v)
+	}
+	return strings.Join(s, ", ")
+}
+
+// oneLine collapses whitespace (including newlines) into single spaces.
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// sortedKeys returns the keys of a map, sorted.
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
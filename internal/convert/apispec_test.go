@@ -0,0 +1,165 @@
+package convert
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+const openAPISpec = `openapi: 3.1.0
+info:
+  title: Shop API
+  version: "2.0"
+servers:
+  - url: https://api.example.com
+paths:
+  /users/{id}:
+    parameters:
+      - name: id
+        in: path
+        required: true
+    get:
+      summary: Get a user
+      responses:
+        "200":
+          content:
+            application/json:
+              schema:
+                $ref: "#/components/schemas/User"
+        "404":
+          description: Not found
+    delete:
+      operationId: deleteUser
+      deprecated: true
+      responses:
+        "204":
+          description: Deleted
+  /users:
+    post:
+      summary: |
+        Create a
+        user
+      parameters:
+        - name: dryRun
+          in: query
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: "#/components/schemas/User"
+      responses:
+        "201":
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  $ref: "#/components/schemas/User"
+components:
+  schemas:
+    User:
+      type: object
+      required: [id]
+      properties:
+        id:
+          type: string
+          format: uuid
+        role:
+          $ref: "#/components/schemas/Role"
+        tags:
+          type: array
+          items:
+            type: string
+    Role:
+      type: string
+      enum: [admin, member]
+    Owner:
+      oneOf:
+        - $ref: "#/components/schemas/User"
+        - type: string
+`
+
+func TestAPISpecConverterOpenAPI(t *testing.T) {
+	c := apiSpecConverter{mode: APISpecSummary}
+	if !c.Match("api/openapi.yaml", []byte(openAPISpec[:100])) {
+		t.Fatal("Expected the OpenAPI spec to match")
+	}
+	if c.Match("config.yaml", []byte("name: app\n")) || c.Match("api/openapi.yaml", nil) {
+		t.Error("Expected other YAML files not to match")
+	}
+	if !c.Match("swagger.json", []byte(`{"swagger": "2.0", "info": {}}`)) {
+		t.Error("Expected the JSON Swagger spec to match")
+	}
+	if (apiSpecConverter{mode: APISpecFull}).Match("api/openapi.yaml", []byte(openAPISpec)) {
+		t.Error("Expected no match in full mode")
+	}
+
+	got, err := c.Convert(context.Background(), File{Path: "api/openapi.yaml", Content: []byte(openAPISpec)})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := `OpenAPI 3.1.0: Shop API (version 2.0)
+Servers: https://api.example.com
+[condensed by sandworm: 3 operations, 3 schemas; set processor.api_specs to full for the whole spec]
+
+ENDPOINTS:
+POST    /users - Create a user (params: dryRun?; body: User) -> 201 []User
+GET     /users/{id} - Get a user (params: id) -> 200 User
+DELETE  /users/{id} - deleteUser [deprecated] (params: id) -> 204
+
+SCHEMAS:
+Owner = User | string
+Role enum(admin, member)
+User {id: string(uuid), role?: Role, tags?: []string}
+`
+	if string(got) != want {
+		t.Errorf("Unexpected summary:\n%s\nwant:\n%s", got, want)
+	}
+
+	// Unparsable specs are kept as is
+	broken := "openapi: 3.0.0\npaths: [\n"
+	if got, _ := c.Convert(context.Background(), File{Path: "openapi.yaml", Content: []byte(broken)}); string(got) != broken {
+		t.Errorf("Expected the broken spec unchanged, got %q", got)
+	}
+}
+
+func TestAPISpecConverterGraphQL(t *testing.T) {
+	c := apiSpecConverter{mode: APISpecSummary}
+	schema := `"""
+A user of the shop
+` + strings.Repeat("Users place orders and write posts.\n", 30) + `"""
+type User implements Node {
+  "The user's ID"
+  id: ID!
+  # Internal note
+  name: String
+  posts(
+    first: Int = 10,
+    after: String
+  ): [Post!]!
+}
+
+enum Role {
+  ADMIN
+  MEMBER
+}
+
+scalar DateTime
+`
+	got, err := c.Convert(context.Background(), File{Path: "schema.graphql", Content: []byte(schema)})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got, _ := c.Convert(context.Background(), File{Path: "small.graphql", Content: []byte("scalar DateTime\n")}); string(got) != "scalar DateTime\n" {
+		t.Errorf("Expected the small schema unchanged, got %q", got)
+	}
+
+	want := `[condensed by sandworm: 3 definitions, descriptions and comments removed; set processor.api_specs to full for the whole schema]
+type User implements Node { id: ID!, name: String, posts( first: Int = 10, after: String ): [Post!]! }
+enum Role { ADMIN, MEMBER }
+scalar DateTime
+`
+	if string(got) != want {
+		t.Errorf("Unexpected summary:\n%s\nwant:\n%s", got, want)
+	}
+}
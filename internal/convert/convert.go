@@ -0,0 +1,136 @@
+// Package convert renders files that aren't plain text (databases, data
+// files, binary descriptors...) as text for the generated file. Converters
+// are either built in or external commands configured by the user.
+package convert
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// File is a file to convert.
+type File struct {
+	Path    string // Relative, slash-separated path
+	AbsPath string // Path to read the file from
+	Content []byte
+}
+
+// Converter renders files of some format as text.
+type Converter interface {
+	// Name identifies the converter (shown in file headers).
+	Name() string
+	// Match reports whether the converter handles a file, given its path and
+	// the first bytes of its content.
+	Match(relPath string, head []byte) bool
+	// Convert returns the text representation of a file (or its content, if
+	// it's fine as is). External converters are killed once ctx is done.
+	Convert(ctx context.Context, f File) ([]byte, error)
+}
+
+// Dependent is implemented by converters whose output depends on other files
+// than the one converted, e.g. minified files rendered from their source map.
+type Dependent interface {
+	// Dependencies returns the paths of the other files the conversion of a
+	// file reads, whether they exist or not.
+	Dependencies(f File) []string
+}
+
+// Options configures the built-in converters.
+type Options struct {
+	CSVSampleRows     int    // Data rows kept in CSV/TSV files; 0 keeps them all
+	ImagePlaceholders bool   // Describe images in one line instead of skipping them
+	Minified          string // How to handle minified JS/CSS (see MinifiedModes); defaults to sources
+	APISpecs          string // How to handle OpenAPI/GraphQL specs (see APISpecModes); defaults to summary
+	Infra             string // How to handle Terraform/Kubernetes files (see InfraModes); defaults to full
+}
+
+// condenseMinSize is the size up to which condensing converters (minified
+// files, API specs) keep files as is: they're cheap enough whole.
+const condenseMinSize = 1024
+
+// builtins create the built-in converters (see Register).
+var builtins []func(Options) Converter
+
+// Register adds a built-in converter. It's meant to be called from init.
+func Register(newConverter func(Options) Converter) {
+	builtins = append(builtins, newConverter)
+}
+
+// Builtins returns the built-in converters.
+func Builtins(opts Options) []Converter {
+	converters := make([]Converter, len(builtins))
+	for i, newConverter := range builtins {
+		converters[i] = newConverter(opts)
+	}
+	return converters
+}
+
+// Registry holds the converters in use. The first matching converter wins, so
+// converters registered first take precedence.
+type Registry struct {
+	converters []Converter
+}
+
+// NewRegistry creates a registry with the given converters.
+func NewRegistry(converters ...Converter) *Registry {
+	return &Registry{converters: converters}
+}
+
+// Register adds a converter with lower precedence than the existing ones.
+func (r *Registry) Register(c Converter) {
+	r.converters = append(r.converters, c)
+}
+
+// Converters returns the registered converters, by precedence.
+func (r *Registry) Converters() []Converter {
+	if r == nil {
+		return nil
+	}
+	return append([]Converter(nil), r.converters...)
+}
+
+// Fingerprint describes the registered converters with their settings (the
+// options of the built-in ones, the commands of external ones), which shape
+// the files they render. Converters are plain values holding their settings.
+func (r *Registry) Fingerprint() string {
+	if r == nil {
+		return ""
+	}
+	var b strings.Builder
+	for _, c := range r.converters {
+		fmt.Fprintf(&b, "%T%+v\n", c, c)
+	}
+	return b.String()
+}
+
+// Find returns the converter for a file, or nil if there's none. head holds
+// the first bytes of the file (may be nil when only checking the path).
+func (r *Registry) Find(relPath string, head []byte) Converter {
+	if r == nil {
+		return nil
+	}
+	for _, c := range r.converters {
+		if c.Match(relPath, head) {
+			return c
+		}
+	}
+	return nil
+}
+
+// MatchPattern matches a file against a converter pattern: a MIME type (e.g.
+// "application/pdf", sniffed from head) or a glob on the file name (e.g.
+// "*.parquet").
+func MatchPattern(pattern, relPath string, head []byte) bool {
+	if strings.Contains(pattern, "/") && !strings.ContainsAny(pattern, "*?[") {
+		if head == nil {
+			return false
+		}
+		mime, _, _ := strings.Cut(http.DetectContentType(head), ";")
+		return mime == pattern
+	}
+	ok, _ := path.Match(pattern, path.Base(relPath))
+	return ok
+}
@@ -0,0 +1,122 @@
+package convert
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestMatchPattern(t *testing.T) {
+	pdf := []byte("%PDF-1.7\n")
+	tests := []struct {
+		pattern string
+		path    string
+		head    []byte
+		want    bool
+	}{
+		{"*.parquet", "data/events.parquet", nil, true},
+		{"*.parquet", "data/events.csv", nil, false},
+		{"schema.pb", "proto/schema.pb", nil, true},
+		{"application/pdf", "docs/spec.bin", pdf, true},
+		{"application/pdf", "docs/spec.pdf", nil, false},
+		{"application/pdf", "docs/notes.txt", []byte("hello"), false},
+	}
+	for _, tt := range tests {
+		if got := MatchPattern(tt.pattern, tt.path, tt.head); got != tt.want {
+			t.Errorf("MatchPattern(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestParseExec(t *testing.T) {
+	e, err := ParseExec("data", "*.parquet, *.avro: tool schema {}")
+	if err != nil {
+		t.Fatalf("Failed to parse converter: %v", err)
+	}
+	if !e.Match("a.avro", nil) || e.Match("a.csv", nil) {
+		t.Errorf("Unexpected patterns: %v", e.patterns)
+	}
+	if e.command != "tool schema {}" {
+		t.Errorf("Unexpected command: %q", e.command)
+	}
+
+	for _, definition := range []string{"*.parquet", "*.parquet:", ": tool {}"} {
+		if _, err := ParseExec("bad", definition); err == nil {
+			t.Errorf("Expected an error for %q", definition)
+		}
+	}
+}
+
+func TestExecConvert(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Converter commands use POSIX tools")
+	}
+
+	file := filepath.Join(t.TempDir(), "it's.dat")
+	if err := os.WriteFile(file, []byte("binary"), 0o644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	f := File{Path: "it's.dat", AbsPath: file, Content: []byte("binary")}
+
+	t.Run("stdin", func(t *testing.T) {
+		e, _ := ParseExec("upper", "*.dat: tr a-z A-Z")
+		out, err := e.Convert(context.Background(), f)
+		if err != nil || string(out) != "BINARY" {
+			t.Errorf("Expected BINARY, got %q (%v)", out, err)
+		}
+	})
+
+	t.Run("file placeholder", func(t *testing.T) {
+		e, _ := ParseExec("size", "*.dat: wc -c < {}")
+		out, err := e.Convert(context.Background(), f)
+		if err != nil || string(out) != "6\n" {
+			t.Errorf("Expected the file size, got %q (%v)", out, err)
+		}
+	})
+
+	t.Run("file placeholder without a file on disk", func(t *testing.T) {
+		e, _ := ParseExec("size", "*.dat: wc -c < {}")
+		if _, err := e.Convert(context.Background(), File{Path: "it's.dat", Content: []byte("binary")}); err == nil {
+			t.Error("Expected an error")
+		}
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		e, _ := ParseExec("fail", "*.dat: echo broken >&2; exit 1")
+		if _, err := e.Convert(context.Background(), f); err == nil {
+			t.Error("Expected an error")
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		e, _ := ParseExec("slow", "*.dat: sleep 10")
+		if _, err := e.Convert(ctx, f); err == nil {
+			t.Error("Expected the command to be killed")
+		}
+	})
+}
+
+func TestRegistry(t *testing.T) {
+	first, _ := ParseExec("first", "*.dat: cat")
+	second, _ := ParseExec("second", "*.dat, *.bin: cat")
+	r := NewRegistry(first)
+	r.Register(second)
+
+	if c := r.Find("a.dat", nil); c == nil || c.Name() != "first" {
+		t.Errorf("Expected the first registered converter to win, got %v", c)
+	}
+	if c := r.Find("a.bin", nil); c == nil || c.Name() != "second" {
+		t.Errorf("Expected the second converter, got %v", c)
+	}
+	if c := r.Find("a.txt", nil); c != nil {
+		t.Errorf("Expected no converter, got %s", c.Name())
+	}
+	var nilRegistry *Registry
+	if nilRegistry.Find("a.dat", nil) != nil {
+		t.Error("Expected no converter from a nil registry")
+	}
+}
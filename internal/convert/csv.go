@@ -0,0 +1,88 @@
+package convert
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// DefaultCSVSampleRows is the number of data rows kept from CSV/TSV files.
+const DefaultCSVSampleRows = 20
+
+func init() {
+	Register(func(opts Options) Converter { return csvConverter{rows: opts.CSVSampleRows} })
+}
+
+// csvConverter samples CSV/TSV files: the header and the first rows are
+// kept, with a note of the total number of rows.
+type csvConverter struct {
+	rows int // Data rows to keep; 0 keeps them all
+}
+
+func (csvConverter) Name() string { return "csv" }
+
+// Match accepts .csv and .tsv files.
+func (c csvConverter) Match(relPath string, _ []byte) bool {
+	if c.rows <= 0 {
+		return false
+	}
+	ext := strings.ToLower(path.Ext(relPath))
+	return ext == ".csv" || ext == ".tsv"
+}
+
+// Convert keeps the header and the first rows, as is. Files with few rows are
+// returned unchanged.
+func (c csvConverter) Convert(_ context.Context, f File) ([]byte, error) {
+	r := csv.NewReader(bytes.NewReader(f.Content))
+	if strings.EqualFold(path.Ext(f.Path), ".tsv") {
+		r.Comma = '\t'
+	}
+	r.FieldsPerRecord = -1
+	r.ReuseRecord = true
+
+	// Records can span lines (quoted newlines), so the cut is made at the
+	// reader's offset rather than by counting lines.
+	var cut int64
+	total := -1 // The header isn't a data row
+	for {
+		_, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return c.sampleLines(f.Content), nil
+		}
+		total++
+		if total == c.rows {
+			cut = r.InputOffset()
+		}
+	}
+	if total <= c.rows {
+		return f.Content, nil
+	}
+
+	return c.sample(f.Content[:cut], total), nil
+}
+
+// MARK: Helpers
+
+// sampleLines samples malformed files line by line.
+func (c csvConverter) sampleLines(content []byte) []byte {
+	lines := bytes.SplitAfter(bytes.TrimRight(content, "\r\n"), []byte("\n"))
+	total := len(lines) - 1
+	if total <= c.rows {
+		return content
+	}
+	return c.sample(bytes.Join(lines[:c.rows+1], nil), total)
+}
+
+// sample appends a note of the omitted rows to the kept ones.
+func (c csvConverter) sample(kept []byte, total int) []byte {
+	kept = bytes.TrimRight(kept, "\r\n")
+	note := fmt.Sprintf("\n[... %d more rows omitted: %d of %d data rows shown]\n", total-c.rows, c.rows, total)
+	return append(kept[:len(kept):len(kept)], note...)
+}
@@ -0,0 +1,76 @@
+package convert
+
+import (
+	"context"
+	"testing"
+)
+
+func TestCSVConverter(t *testing.T) {
+	c := csvConverter{rows: 2}
+
+	tests := []struct {
+		name    string
+		path    string
+		content string
+		want    string
+	}{
+		{
+			name:    "samples rows",
+			path:    "data/users.csv",
+			content: "id,name\n1,Ann\n2,Bob\n3,Cid\n4,Dee\n",
+			want:    "id,name\n1,Ann\n2,Bob\n[... 2 more rows omitted: 2 of 4 data rows shown]\n",
+		},
+		{
+			name:    "keeps quoted newlines",
+			path:    "notes.csv",
+			content: "id,note\n1,\"multi\nline\"\n2,b\n3,c\n",
+			want:    "id,note\n1,\"multi\nline\"\n2,b\n[... 1 more rows omitted: 2 of 3 data rows shown]\n",
+		},
+		{
+			name:    "tab-separated",
+			path:    "data.TSV",
+			content: "a\tb\r\n1\t2\r\n3\t4\r\n5\t6\r\n",
+			want:    "a\tb\r\n1\t2\r\n3\t4\n[... 1 more rows omitted: 2 of 3 data rows shown]\n",
+		},
+		{
+			name:    "small files unchanged",
+			path:    "small.csv",
+			content: "id,name\n1,Ann\n2,Bob\n",
+			want:    "id,name\n1,Ann\n2,Bob\n",
+		},
+		{
+			name:    "malformed files sampled by line",
+			path:    "bad.csv",
+			content: "a,b\n1,\"x\n2,y\n3,z\n",
+			want:    "a,b\n1,\"x\n2,y\n[... 1 more rows omitted: 2 of 3 data rows shown]\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !c.Match(tt.path, nil) {
+				t.Fatalf("Expected %s to match", tt.path)
+			}
+			got, err := c.Convert(context.Background(), File{Path: tt.path, Content: []byte(tt.content)})
+			if err != nil {
+				t.Fatalf("Failed to convert: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		if (csvConverter{}).Match("data.csv", nil) || c.Match("data.json", nil) {
+			t.Error("Expected no match")
+		}
+	})
+
+	t.Run("builtin", func(t *testing.T) {
+		for _, b := range Builtins(Options{CSVSampleRows: 5}) {
+			if csv, ok := b.(csvConverter); ok && csv.rows != 5 {
+				t.Errorf("Expected the configured sample size, got %d", csv.rows)
+			}
+		}
+	})
+}
@@ -0,0 +1,109 @@
+package convert
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// filePlaceholder is replaced with the (quoted) path of the file in external
+// converter commands. Without it, the content is passed on stdin.
+const filePlaceholder = "{}"
+
+// Exec is an external converter: a shell command printing the text
+// representation of a file.
+type Exec struct {
+	name     string
+	patterns []string
+	command  string
+}
+
+// ParseExec parses an external converter definition of the form
+// "<patterns>: <command>", where patterns is a comma-separated list of file
+// name globs or MIME types, e.g. "*.parquet: parquet-tools schema {}".
+func ParseExec(name, definition string) (*Exec, error) {
+	patterns, command, ok := strings.Cut(definition, ":")
+	command = strings.TrimSpace(command)
+	if !ok || command == "" {
+		return nil, fmt.Errorf("invalid converter %q (expected '<patterns>: <command>')", name)
+	}
+
+	e := &Exec{name: name, command: command}
+	for _, pattern := range strings.Split(patterns, ",") {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			e.patterns = append(e.patterns, pattern)
+		}
+	}
+	if len(e.patterns) == 0 {
+		return nil, fmt.Errorf("invalid converter %q: no file patterns", name)
+	}
+	return e, nil
+}
+
+// Name returns the converter name.
+func (e *Exec) Name() string { return e.name }
+
+// Match reports whether a file matches any of the converter's patterns.
+func (e *Exec) Match(relPath string, head []byte) bool {
+	for _, pattern := range e.patterns {
+		if MatchPattern(pattern, relPath, head) {
+			return true
+		}
+	}
+	return false
+}
+
+// Convert runs the command, returning its output. The command is killed once
+// ctx is done.
+func (e *Exec) Convert(ctx context.Context, f File) ([]byte, error) {
+	command := e.command
+	var stdin []byte
+	if strings.Contains(command, filePlaceholder) {
+		if f.AbsPath == "" {
+			return nil, errors.New("converter " + e.name + " needs the file on disk")
+		}
+		command = strings.ReplaceAll(command, filePlaceholder, shellQuote(f.AbsPath))
+	} else {
+		stdin = f.Content
+	}
+
+	cmd := shellCommand(ctx, command)
+	cmd.Stdin = bytes.NewReader(stdin)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			return nil, fmt.Errorf("converter %s failed: %w", e.name, err)
+		}
+		return nil, fmt.Errorf("converter %s failed: %w: %s", e.name, err, msg)
+	}
+	if len(out) == 0 {
+		return nil, errors.New("converter " + e.name + " produced no output")
+	}
+	return out, nil
+}
+
+// MARK: Helpers
+
+// shellCommand returns a command running a shell command line, killed once
+// ctx is done.
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
+
+// shellQuote quotes a path for the shell running converter commands.
+func shellQuote(s string) string {
+	if runtime.GOOS == "windows" {
+		return `"` + s + `"`
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,170 @@
+package convert
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"image"
+	_ "image/gif"  // Register the GIF decoder for image.DecodeConfig
+	_ "image/jpeg" // Register the JPEG decoder for image.DecodeConfig
+	_ "image/png"  // Register the PNG decoder for image.DecodeConfig
+	"path"
+	"strings"
+
+	"github.com/holonoms/sandworm/internal/util"
+)
+
+// imageExtensions are the image files described by placeholders.
+var imageExtensions = []string{".png", ".jpg", ".jpeg", ".gif", ".bmp", ".ico", ".webp"}
+
+func init() {
+	Register(func(opts Options) Converter { return imageConverter{enabled: opts.ImagePlaceholders} })
+}
+
+// imageConverter replaces images with a one-line description, e.g.
+// "[logo.png: 512x512 PNG, 14.2 KB]", so the assets are known to exist.
+type imageConverter struct {
+	enabled bool
+}
+
+func (imageConverter) Name() string { return "image" }
+
+// Match accepts image files when placeholders are enabled.
+func (c imageConverter) Match(relPath string, _ []byte) bool {
+	if !c.enabled {
+		return false
+	}
+	ext := strings.ToLower(path.Ext(relPath))
+	for _, e := range imageExtensions {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+// Convert describes the image: dimensions, format, size and EXIF description
+// when known.
+func (imageConverter) Convert(_ context.Context, f File) ([]byte, error) {
+	details := []string{strings.ToUpper(strings.TrimPrefix(strings.ToLower(path.Ext(f.Path)), "."))}
+	if width, height, format, ok := imageDimensions(f.Content); ok {
+		details[0] = fmt.Sprintf("%dx%d %s", width, height, format)
+	}
+	details = append(details, util.FormatSize(int64(len(f.Content))))
+	if description := exifDescription(f.Content); description != "" {
+		details = append(details, fmt.Sprintf("%q", description))
+	}
+	return []byte(fmt.Sprintf("[%s: %s]\n", path.Base(f.Path), strings.Join(details, ", "))), nil
+}
+
+// MARK: Helpers
+
+// imageDimensions returns the size and format of an image, decoding only its
+// header.
+func imageDimensions(data []byte) (width, height int, format string, ok bool) {
+	if config, format, err := image.DecodeConfig(bytes.NewReader(data)); err == nil {
+		return config.Width, config.Height, strings.ToUpper(format), true
+	}
+
+	switch {
+	case len(data) >= 26 && bytes.HasPrefix(data, []byte("BM")):
+		width = int(int32(binary.LittleEndian.Uint32(data[18:])))
+		height = int(int32(binary.LittleEndian.Uint32(data[22:])))
+		return width, max(height, -height), "BMP", true
+	case len(data) >= 8 && bytes.HasPrefix(data, []byte{0, 0, 1, 0}):
+		// Icons hold several images; describe the first one (0 means 256)
+		width, height = int(data[6]), int(data[7])
+		if width == 0 {
+			width = 256
+		}
+		if height == 0 {
+			height = 256
+		}
+		return width, height, "ICO", true
+	case len(data) >= 30 && string(data[:4]) == "RIFF" && string(data[8:12]) == "WEBP":
+		switch string(data[12:16]) {
+		case "VP8 ":
+			width = int(binary.LittleEndian.Uint16(data[26:]) & 0x3fff)
+			height = int(binary.LittleEndian.Uint16(data[28:]) & 0x3fff)
+		case "VP8L":
+			bits := binary.LittleEndian.Uint32(data[21:])
+			width, height = int(bits&0x3fff)+1, int(bits>>14&0x3fff)+1
+		case "VP8X":
+			width = int(uint32(data[24])|uint32(data[25])<<8|uint32(data[26])<<16) + 1
+			height = int(uint32(data[27])|uint32(data[28])<<8|uint32(data[29])<<16) + 1
+		default:
+			return 0, 0, "", false
+		}
+		return width, height, "WEBP", true
+	}
+	return 0, 0, "", false
+}
+
+// exifDescription returns the EXIF ImageDescription of a JPEG image, if any.
+func exifDescription(data []byte) string {
+	if !bytes.HasPrefix(data, []byte{0xff, 0xd8}) {
+		return ""
+	}
+
+	// Walk the segments up to the image data, looking for the EXIF one (APP1)
+	for pos := 2; pos+4 <= len(data) && data[pos] == 0xff; {
+		marker := data[pos+1]
+		// The size includes its own 2 bytes, and must fit in the data
+		size := int(binary.BigEndian.Uint16(data[pos+2:]))
+		if marker == 0xda || size < 2 || pos+2+size > len(data) {
+			return ""
+		}
+		segment := data[pos+4 : pos+2+size]
+		if marker == 0xe1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return tiffDescription(segment[6:])
+		}
+		pos += 2 + size
+	}
+	return ""
+}
+
+// tiffDescription returns the ImageDescription tag (0x010e) of the first IFD
+// of TIFF data.
+func tiffDescription(tiff []byte) string {
+	if len(tiff) < 8 {
+		return ""
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return ""
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd+2 > len(tiff) {
+		return ""
+	}
+	entries := int(order.Uint16(tiff[ifd:]))
+	for i := range entries {
+		entry := ifd + 2 + 12*i
+		if entry+12 > len(tiff) {
+			return ""
+		}
+		if order.Uint16(tiff[entry:]) != 0x010e {
+			continue
+		}
+		count := int(order.Uint32(tiff[entry+4:]))
+		value := tiff[entry+8 : entry+12]
+		if count > 4 {
+			offset := int(order.Uint32(value))
+			if offset < 0 || offset+count > len(tiff) {
+				return ""
+			}
+			value = tiff[offset : offset+count]
+		} else {
+			value = value[:count]
+		}
+		return strings.TrimSpace(strings.TrimRight(string(value), "\x00"))
+	}
+	return ""
+}
@@ -0,0 +1,92 @@
+package convert
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/holonoms/sandworm/internal/util"
+)
+
+func TestImageConverter(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 64, 32))
+	var pngData, jpegData bytes.Buffer
+	if err := png.Encode(&pngData, img); err != nil {
+		t.Fatalf("Failed to encode PNG: %v", err)
+	}
+	if err := jpeg.Encode(&jpegData, img, nil); err != nil {
+		t.Fatalf("Failed to encode JPEG: %v", err)
+	}
+
+	// A JPEG with an EXIF ImageDescription, in a big-endian TIFF structure
+	description := "App icon\x00"
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08")
+	tiff = binary.BigEndian.AppendUint16(tiff, 1)
+	tiff = binary.BigEndian.AppendUint16(tiff, 0x010e)
+	tiff = binary.BigEndian.AppendUint16(tiff, 2)
+	tiff = binary.BigEndian.AppendUint32(tiff, uint32(len(description)))
+	tiff = binary.BigEndian.AppendUint32(tiff, 26)
+	tiff = append(binary.BigEndian.AppendUint32(tiff, 0), description...)
+	app1 := append([]byte{0xff, 0xe1, 0, 0}, "Exif\x00\x00"...)
+	app1 = append(app1, tiff...)
+	binary.BigEndian.PutUint16(app1[2:], uint16(len(app1)-2))
+	exifData := append(append([]byte{0xff, 0xd8}, app1...), jpegData.Bytes()[2:]...)
+
+	bmp := make([]byte, 54)
+	copy(bmp, "BM")
+	binary.LittleEndian.PutUint32(bmp[18:], 16)
+	binary.LittleEndian.PutUint32(bmp[22:], uint32(0xffffffff-7)) // -8: top-down
+
+	webp := []byte("RIFF\x00\x00\x00\x00WEBPVP8L\x00\x00\x00\x00\x2f")
+	webp = binary.LittleEndian.AppendUint32(webp, 99|(49<<14)) // 100x50
+	webp = append(webp, make([]byte, 8)...)
+
+	tests := []struct {
+		path    string
+		content []byte
+		want    string
+	}{
+		{"assets/logo.png", pngData.Bytes(), "[logo.png: 64x32 PNG, " + sizeOf(pngData.Bytes()) + "]\n"},
+		{"photo.JPG", exifData, "[photo.JPG: 64x32 JPEG, " + sizeOf(exifData) + ", \"App icon\"]\n"},
+		{"icon.bmp", bmp, "[icon.bmp: 16x8 BMP, " + sizeOf(bmp) + "]\n"},
+		{"hero.webp", webp, "[hero.webp: 100x50 WEBP, " + sizeOf(webp) + "]\n"},
+		{"broken.gif", []byte("nope"), "[broken.gif: GIF, 4.0 B]\n"},
+	}
+	c := imageConverter{enabled: true}
+	for _, tt := range tests {
+		if !c.Match(tt.path, nil) {
+			t.Errorf("Expected %s to match", tt.path)
+			continue
+		}
+		got, err := c.Convert(context.Background(), File{Path: tt.path, Content: tt.content})
+		if err != nil || string(got) != tt.want {
+			t.Errorf("Expected %q, got %q (%v)", tt.want, got, err)
+		}
+	}
+
+	if (imageConverter{}).Match("logo.png", nil) || c.Match("logo.svg", nil) {
+		t.Error("Expected no match when disabled or for other files")
+	}
+}
+
+func TestExifDescriptionTruncated(t *testing.T) {
+	for _, data := range [][]byte{
+		{0xff, 0xd8, 0xff, 0xe1, 0x00, 0x00},             // Zero-length segment
+		{0xff, 0xd8, 0xff, 0xe1, 0x00, 0x01},             // Shorter than its size field
+		{0xff, 0xd8, 0xff, 0xe1, 0x00, 0x10, 'E', 'x'},   // Running past the data
+		{0xff, 0xd8, 0xff, 0xe0, 0x00, 0x02, 0xff, 0xe1}, // Truncated after an empty segment
+	} {
+		if got := exifDescription(data); got != "" {
+			t.Errorf("Expected no description for % x, got %q", data, got)
+		}
+	}
+}
+
+// sizeOf formats a size as placeholders do.
+func sizeOf(data []byte) string {
+	return util.FormatSize(int64(len(data)))
+}
@@ -0,0 +1,419 @@
+package convert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// How infrastructure files (Terraform state/plans, Kubernetes manifests) are
+// handled
+const (
+	InfraFull    = "full"    // Include them as is (default)
+	InfraSummary = "summary" // Summarize their resources, names and key fields
+)
+
+// InfraModes lists the valid infrastructure modes.
+var InfraModes = []string{InfraFull, InfraSummary}
+
+var (
+	// kubernetesRE matches the top-level apiVersion key of a Kubernetes manifest.
+	kubernetesRE = regexp.MustCompile(`(?m)^apiVersion:\s*\S`)
+	// terraformRE matches the terraform_version key of a Terraform state or
+	// plan (as rendered by terraform show -json).
+	terraformRE = regexp.MustCompile(`"terraform_version"\s*:`)
+)
+
+// terraformKeyFields are the attributes shown for each resource of a state:
+// identifiers and the few settings telling resources apart. Anything else
+// (including secrets the state may hold) is left out.
+var terraformKeyFields = []string{
+	"id", "name", "arn", "region", "location", "availability_zone", "zone",
+	"instance_type", "machine_type", "instance_class", "ami", "image",
+	"cidr_block", "address_space", "engine", "engine_version", "bucket",
+	"domain_name", "runtime", "handler",
+}
+
+// podSpecPaths locate the pod spec of the workload kinds.
+var podSpecPaths = map[string][]string{
+	"Pod":         {"spec"},
+	"Deployment":  {"spec", "template", "spec"},
+	"StatefulSet": {"spec", "template", "spec"},
+	"DaemonSet":   {"spec", "template", "spec"},
+	"ReplicaSet":  {"spec", "template", "spec"},
+	"Job":         {"spec", "template", "spec"},
+	"CronJob":     {"spec", "jobTemplate", "spec", "template", "spec"},
+}
+
+func init() {
+	Register(func(opts Options) Converter { return infraConverter{mode: opts.Infra} })
+}
+
+// infraConverter summarizes Terraform states and plans, and Kubernetes
+// manifests, for loading infrastructure repositories without their (often
+// huge, and secret-laden) full content.
+type infraConverter struct {
+	mode string
+}
+
+func (infraConverter) Name() string { return "infra" }
+
+// Match accepts Terraform state files, Terraform JSON (states and plans
+// rendered by terraform show -json) and Kubernetes YAML manifests.
+func (c infraConverter) Match(relPath string, head []byte) bool {
+	if c.mode != InfraSummary {
+		return false
+	}
+	name := strings.ToLower(path.Base(relPath))
+	if strings.HasSuffix(name, ".tfstate") || strings.HasSuffix(name, ".tfstate.backup") {
+		return true
+	}
+	switch path.Ext(name) {
+	case ".json":
+		return head != nil && terraformRE.Match(head)
+	case ".yaml", ".yml":
+		return head != nil && kubernetesRE.Match(head)
+	}
+	return false
+}
+
+// Convert returns the summary of an infrastructure file. Files that can't be
+// parsed (e.g. templated manifests) are returned unchanged.
+func (c infraConverter) Convert(_ context.Context, f File) ([]byte, error) {
+	switch path.Ext(strings.ToLower(f.Path)) {
+	case ".yaml", ".yml":
+		if summary := summarizeManifests(f.Content); summary != nil {
+			return summary, nil
+		}
+		return f.Content, nil
+	}
+
+	var doc map[string]any
+	if err := json.Unmarshal(f.Content, &doc); err != nil || doc == nil {
+		return f.Content, nil
+	}
+	switch {
+	case doc["resource_changes"] != nil || doc["planned_values"] != nil:
+		return summarizePlan(doc), nil
+	case doc["resources"] != nil || doc["terraform_version"] != nil:
+		return summarizeState(doc), nil
+	}
+	return f.Content, nil
+}
+
+// MARK: Terraform
+
+// summarizeState summarizes a Terraform state: one line per resource
+// instance with its key fields, and the outputs.
+func summarizeState(state map[string]any) []byte {
+	var lines []string
+	for _, resource := range listOf(state["resources"]) {
+		resource := mapOf(resource)
+		address := str(resource["type"]) + "." + str(resource["name"])
+		if str(resource["mode"]) == "data" {
+			address = "data." + address
+		}
+		if module := str(resource["module"]); module != "" {
+			address = module + "." + address
+		}
+		for _, instance := range listOf(resource["instances"]) {
+			instance := mapOf(instance)
+			line := address + indexKey(instance["index_key"])
+			attributes := mapOf(instance["attributes"])
+			for _, field := range terraformKeyFields {
+				if v := str(attributes[field]); v != "" {
+					line += fmt.Sprintf(" %s=%s", field, v)
+				}
+			}
+			lines = append(lines, line)
+		}
+	}
+
+	outputs := mapOf(state["outputs"])
+	var outputLines []string
+	for _, name := range sortedKeys(outputs) {
+		output := mapOf(outputs[name])
+		value := str(output["value"])
+		switch {
+		case output["sensitive"] == true:
+			value = "(sensitive)"
+		case value == "":
+			value = "(" + str(output["type"]) + ")"
+			if value == "()" {
+				value = "(complex)"
+			}
+		}
+		outputLines = append(outputLines, name+" = "+value)
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "Terraform state (version %s, Terraform %s): %d resource instances\n", str(state["version"]), str(state["terraform_version"]), len(lines))
+	b.WriteString("[condensed by sandworm: attributes other than key fields omitted; set processor.infra to full for the whole file]\n")
+	writeSection(&b, "RESOURCES", lines)
+	writeSection(&b, "OUTPUTS", outputLines)
+	return b.Bytes()
+}
+
+// summarizePlan summarizes a Terraform plan: one line per changed resource
+// with its action and changed attributes.
+func summarizePlan(plan map[string]any) []byte {
+	counts := make(map[string]int)
+	var lines []string
+	for _, rc := range listOf(plan["resource_changes"]) {
+		rc := mapOf(rc)
+		change := mapOf(rc["change"])
+		action := planAction(listOf(change["actions"]))
+		if action == "no-op" || action == "read" {
+			continue
+		}
+		counts[action]++
+		line := fmt.Sprintf("%-7s %s", action, str(rc["address"]))
+		if action == "update" || action == "replace" {
+			if changed := changedAttributes(mapOf(change["before"]), mapOf(change["after"]), mapOf(change["after_unknown"])); len(changed) > 0 {
+				line += " (" + strings.Join(changed, ", ") + ")"
+			}
+		}
+		lines = append(lines, line)
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "Terraform plan (Terraform %s): %d to create, %d to update, %d to replace, %d to delete\n",
+		str(plan["terraform_version"]), counts["create"], counts["update"], counts["replace"], counts["delete"])
+	b.WriteString("[condensed by sandworm: attribute values omitted; set processor.infra to full for the whole file]\n")
+	writeSection(&b, "CHANGES", lines)
+	return b.Bytes()
+}
+
+// planAction names the action of a resource change ("delete" then "create",
+// in either order, is a replacement).
+func planAction(actions []any) string {
+	if len(actions) == 2 {
+		return "replace"
+	}
+	if len(actions) == 1 {
+		return str(actions[0])
+	}
+	return "no-op"
+}
+
+// changedAttributes lists the top-level attributes an update changes, or
+// that are only known after applying it.
+func changedAttributes(before, after, unknown map[string]any) []string {
+	keys := make(map[string]any)
+	for _, m := range []map[string]any{before, after, unknown} {
+		for key := range m {
+			keys[key] = nil
+		}
+	}
+	var changed []string
+	for _, key := range sortedKeys(keys) {
+		if !reflect.DeepEqual(before[key], after[key]) || unknown[key] == true {
+			changed = append(changed, key)
+		}
+	}
+	return changed
+}
+
+// indexKey formats the index of a resource instance created with count
+// ([0]) or for_each (["key"]).
+func indexKey(key any) string {
+	switch key := key.(type) {
+	case nil:
+		return ""
+	case string:
+		return fmt.Sprintf("[%q]", key)
+	default:
+		return "[" + str(key) + "]"
+	}
+}
+
+// MARK: Kubernetes
+
+// summarizeManifests summarizes the Kubernetes resources of a (multi-document)
+// YAML file, one line per resource. It returns nil if the file can't be
+// parsed or holds no resources.
+func summarizeManifests(content []byte) []byte {
+	var lines []string
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	for {
+		var doc map[string]any
+		err := decoder.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil
+		}
+		if strings.HasSuffix(str(doc["kind"]), "List") && doc["items"] != nil {
+			for _, item := range listOf(doc["items"]) {
+				lines = append(lines, manifestLine(mapOf(item)))
+			}
+		} else if doc["kind"] != nil {
+			lines = append(lines, manifestLine(doc))
+		}
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "Kubernetes manifests: %d resources\n", len(lines))
+	b.WriteString("[condensed by sandworm: fields other than key fields omitted; set processor.infra to full for the whole file]\n\n")
+	for _, line := range lines {
+		b.WriteString(line + "\n")
+	}
+	return b.Bytes()
+}
+
+// manifestLine summarizes a Kubernetes resource, e.g.
+// "Deployment prod/web: replicas=3; containers: web=nginx:1.25 (ports 80)".
+func manifestLine(doc map[string]any) string {
+	kind := str(doc["kind"])
+	metadata := mapOf(doc["metadata"])
+	name := str(metadata["name"])
+	if ns := str(metadata["namespace"]); ns != "" {
+		name = ns + "/" + name
+	}
+	line := strings.TrimSpace(kind + " " + name)
+
+	spec := mapOf(doc["spec"])
+	var details []string
+	switch kind {
+	case "Service":
+		if t := str(spec["type"]); t != "" {
+			details = append(details, "type="+t)
+		}
+		var ports []string
+		for _, port := range listOf(spec["ports"]) {
+			ports = append(ports, servicePort(mapOf(port)))
+		}
+		if len(ports) > 0 {
+			details = append(details, "ports "+strings.Join(ports, ", "))
+		}
+		if selector := labels(mapOf(spec["selector"])); selector != "" {
+			details = append(details, "selector "+selector)
+		}
+	case "ConfigMap", "Secret":
+		if t := str(doc["type"]); t != "" {
+			details = append(details, "type="+t)
+		}
+		keys := append(sortedKeys(mapOf(doc["data"])), sortedKeys(mapOf(doc["stringData"]))...)
+		if len(keys) > 0 {
+			details = append(details, "keys: "+strings.Join(keys, ", "))
+		}
+	case "Ingress":
+		var rules []string
+		for _, rule := range listOf(spec["rules"]) {
+			rule := mapOf(rule)
+			host := str(rule["host"])
+			if host == "" {
+				host = "*"
+			}
+			for _, p := range listOf(mapOf(rule["http"])["paths"]) {
+				p := mapOf(p)
+				backend := mapOf(mapOf(p["backend"])["service"])
+				port := mapOf(backend["port"])
+				target := str(backend["name"]) + ":" + str(port["number"]) + str(port["name"])
+				rules = append(rules, host+str(p["path"])+" -> "+target)
+			}
+		}
+		if len(rules) > 0 {
+			details = append(details, "rules: "+strings.Join(rules, ", "))
+		}
+	}
+
+	if specPath, ok := podSpecPaths[kind]; ok {
+		if r := str(spec["replicas"]); r != "" {
+			details = append(details, "replicas="+r)
+		}
+		if s := str(spec["schedule"]); s != "" {
+			details = append(details, "schedule="+s)
+		}
+		podSpec := doc
+		for _, key := range specPath {
+			podSpec = mapOf(podSpec[key])
+		}
+		var containers []string
+		for _, key := range []string{"initContainers", "containers"} {
+			for _, container := range listOf(podSpec[key]) {
+				containers = append(containers, containerSummary(mapOf(container), key == "initContainers"))
+			}
+		}
+		if len(containers) > 0 {
+			details = append(details, "containers: "+strings.Join(containers, ", "))
+		}
+	}
+
+	if len(details) > 0 {
+		line += ": " + strings.Join(details, "; ")
+	}
+	return line
+}
+
+// containerSummary summarizes a container, e.g. "web=nginx:1.25 (ports 80)".
+func containerSummary(container map[string]any, init bool) string {
+	s := str(container["name"]) + "=" + str(container["image"])
+	var ports []string
+	for _, port := range listOf(container["ports"]) {
+		port := mapOf(port)
+		p := str(port["containerPort"])
+		if proto := str(port["protocol"]); proto != "" && proto != "TCP" {
+			p += "/" + proto
+		}
+		ports = append(ports, p)
+	}
+	var notes []string
+	if init {
+		notes = append(notes, "init")
+	}
+	if len(ports) > 0 {
+		notes = append(notes, "ports "+strings.Join(ports, ", "))
+	}
+	if len(notes) > 0 {
+		s += " (" + strings.Join(notes, "; ") + ")"
+	}
+	return s
+}
+
+// servicePort formats a service port, e.g. "80->8080" or "53/UDP".
+func servicePort(port map[string]any) string {
+	s := str(port["port"])
+	if target := str(port["targetPort"]); target != "" && target != s {
+		s += "->" + target
+	}
+	if proto := str(port["protocol"]); proto != "" && proto != "TCP" {
+		s += "/" + proto
+	}
+	return s
+}
+
+// labels formats a label map, e.g. "app=web,tier=frontend".
+func labels(m map[string]any) string {
+	pairs := make([]string, 0, len(m))
+	for _, k := range sortedKeys(m) {
+		pairs = append(pairs, k+"="+str(m[k]))
+	}
+	return strings.Join(pairs, ",")
+}
+
+// MARK: Helpers
+
+// writeSection writes a titled list of lines, if there are any.
+func writeSection(b *bytes.Buffer, title string, lines []string) {
+	if len(lines) == 0 {
+		return
+	}
+	b.WriteString("\n" + title + ":\n")
+	for _, line := range lines {
+		b.WriteString(line + "\n")
+	}
+}
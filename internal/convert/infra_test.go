@@ -0,0 +1,167 @@
+package convert
+
+import (
+	"context"
+	"testing"
+)
+
+const terraformState = `{
+  "version": 4,
+  "terraform_version": "1.5.7",
+  "resources": [
+    {
+      "mode": "managed",
+      "type": "aws_instance",
+      "name": "web",
+      "instances": [
+        {"index_key": 0, "attributes": {"id": "i-1", "instance_type": "t3.micro", "user_data": "secret"}},
+        {"index_key": 1, "attributes": {"id": "i-2", "instance_type": "t3.micro"}}
+      ]
+    },
+    {
+      "module": "module.db",
+      "mode": "data",
+      "type": "aws_db_instance",
+      "name": "main",
+      "instances": [{"index_key": "eu", "attributes": {"id": "db-1", "password": "hunter2"}}]
+    }
+  ],
+  "outputs": {
+    "db_password": {"value": "hunter2", "type": "string", "sensitive": true},
+    "web_ip": {"value": "10.0.0.1", "type": "string"}
+  }
+}`
+
+const terraformPlan = `{
+  "format_version": "1.2",
+  "terraform_version": "1.5.7",
+  "resource_changes": [
+    {"address": "aws_instance.web", "change": {"actions": ["create"], "after": {"ami": "ami-1"}}},
+    {"address": "aws_s3_bucket.logs", "change": {"actions": ["update"],
+      "before": {"bucket": "logs", "tags": {"env": "dev"}},
+      "after": {"bucket": "logs", "tags": {"env": "prod"}},
+      "after_unknown": {"arn": true}}},
+    {"address": "aws_db_instance.main", "change": {"actions": ["delete", "create"]}},
+    {"address": "aws_iam_role.ci", "change": {"actions": ["no-op"]}}
+  ]
+}`
+
+const kubernetesManifests = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: prod
+spec:
+  replicas: 3
+  template:
+    spec:
+      initContainers:
+        - name: migrate
+          image: app:1.2
+      containers:
+        - name: web
+          image: nginx:1.25
+          ports:
+            - containerPort: 80
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  selector:
+    app: web
+  ports:
+    - port: 80
+      targetPort: 8080
+    - port: 53
+      protocol: UDP
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: creds
+type: Opaque
+data:
+  password: aHVudGVyMg==
+`
+
+func TestInfraConverterTerraform(t *testing.T) {
+	c := infraConverter{mode: InfraSummary}
+	if !c.Match("infra/terraform.tfstate", nil) || !c.Match("plan.json", []byte(terraformPlan[:60])) {
+		t.Fatal("Expected the Terraform files to match")
+	}
+	if c.Match("package.json", []byte(`{"name": "app"}`)) {
+		t.Error("Expected other JSON files not to match")
+	}
+	if (infraConverter{mode: InfraFull}).Match("terraform.tfstate", nil) {
+		t.Error("Expected no match in full mode")
+	}
+
+	got, err := c.Convert(context.Background(), File{Path: "terraform.tfstate", Content: []byte(terraformState)})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := `Terraform state (version 4, Terraform 1.5.7): 3 resource instances
+[condensed by sandworm: attributes other than key fields omitted; set processor.infra to full for the whole file]
+
+RESOURCES:
+aws_instance.web[0] id=i-1 instance_type=t3.micro
+aws_instance.web[1] id=i-2 instance_type=t3.micro
+module.db.data.aws_db_instance.main["eu"] id=db-1
+
+OUTPUTS:
+db_password = (sensitive)
+web_ip = 10.0.0.1
+`
+	if string(got) != want {
+		t.Errorf("Unexpected state summary:\n%s\nwant:\n%s", got, want)
+	}
+
+	got, err = c.Convert(context.Background(), File{Path: "plan.json", Content: []byte(terraformPlan)})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want = `Terraform plan (Terraform 1.5.7): 1 to create, 1 to update, 1 to replace, 0 to delete
+[condensed by sandworm: attribute values omitted; set processor.infra to full for the whole file]
+
+CHANGES:
+create  aws_instance.web
+update  aws_s3_bucket.logs (arn, tags)
+replace aws_db_instance.main
+`
+	if string(got) != want {
+		t.Errorf("Unexpected plan summary:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestInfraConverterKubernetes(t *testing.T) {
+	c := infraConverter{mode: InfraSummary}
+	if !c.Match("k8s/web.yaml", []byte(kubernetesManifests[:40])) {
+		t.Fatal("Expected the manifests to match")
+	}
+	if c.Match("config.yaml", []byte("name: app\n")) {
+		t.Error("Expected other YAML files not to match")
+	}
+
+	got, err := c.Convert(context.Background(), File{Path: "k8s/web.yaml", Content: []byte(kubernetesManifests)})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := `Kubernetes manifests: 3 resources
+[condensed by sandworm: fields other than key fields omitted; set processor.infra to full for the whole file]
+
+Deployment prod/web: replicas=3; containers: migrate=app:1.2 (init), web=nginx:1.25 (ports 80)
+Service web: ports 80->8080, 53/UDP; selector app=web
+Secret creds: type=Opaque; keys: password
+`
+	if string(got) != want {
+		t.Errorf("Unexpected summary:\n%s\nwant:\n%s", got, want)
+	}
+
+	// Templated manifests can't be parsed, and are kept as is
+	templated := "apiVersion: v1\nkind: ConfigMap\ndata:\n  {{- toYaml .Values | nindent 2 }}\n"
+	if got, _ := c.Convert(context.Background(), File{Path: "templates/cm.yaml", Content: []byte(templated)}); string(got) != templated {
+		t.Errorf("Expected the template unchanged, got %q", got)
+	}
+}
@@ -0,0 +1,159 @@
+package convert
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/holonoms/sandworm/internal/wsl"
+)
+
+// Database is a database whose schema is bundled, configured by DSN.
+type Database struct {
+	Name string
+	DSN  string
+}
+
+// ParseDatabases parses comma-separated name=DSN pairs, e.g.
+// "app=postgres://localhost/app,cache=sqlite:data/cache.db". Environment
+// variables in DSNs are expanded, so credentials don't have to be stored in
+// config: databases must only be read from the global config, never from a
+// project's files.
+func ParseDatabases(value string) ([]Database, error) {
+	var databases []Database
+	for _, pair := range strings.Split(value, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		name, dsn, ok := strings.Cut(pair, "=")
+		name, dsn = strings.TrimSpace(name), strings.TrimSpace(dsn)
+		if !ok || name == "" || dsn == "" {
+			return nil, fmt.Errorf("invalid database %q (expected name=DSN)", pair)
+		}
+		// DSNs given entirely by an environment variable are checked on use
+		if _, err := schemaDumper(dsn); err != nil && !strings.HasPrefix(dsn, "$") {
+			return nil, fmt.Errorf("invalid database %q: %w", name, err)
+		}
+		databases = append(databases, Database{Name: name, DSN: dsn})
+	}
+	return databases, nil
+}
+
+// ValidateDatabases checks a list of databases (see ParseDatabases).
+func ValidateDatabases(value string) error {
+	_, err := ParseDatabases(value)
+	return err
+}
+
+// Unset reports whether the DSN is only made of environment variables that
+// aren't set, e.g. $DATABASE_URL outside of the projects defining it.
+func (d Database) Unset() bool {
+	return strings.TrimSpace(os.ExpandEnv(d.DSN)) == ""
+}
+
+// Schema dumps the schema of a database: sqlite: DSNs (files within dir) are
+// read natively, postgres:// ones with pg_dump and mysql:// ones with
+// mysqldump, killed once ctx is done.
+func (d Database) Schema(ctx context.Context, dir string) ([]byte, error) {
+	dumper, err := schemaDumper(os.ExpandEnv(d.DSN))
+	if err != nil {
+		return nil, err
+	}
+
+	if dumper.file != "" {
+		// Opened through a root so that symlinks can't lead out of dir either
+		root, err := os.OpenRoot(dir)
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = root.Close() }()
+		f, err := root.Open(dumper.file)
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = f.Close() }()
+		data, err := io.ReadAll(f)
+		if err != nil {
+			return nil, err
+		}
+		return SQLiteSchema(data)
+	}
+
+	if _, err := exec.LookPath(dumper.tool); err != nil {
+		return nil, fmt.Errorf("dumping %s requires %s", d.Name, dumper.tool)
+	}
+	cmd := exec.CommandContext(ctx, dumper.tool, dumper.args...)
+	cmd.Env = append(os.Environ(), dumper.env...)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("%s failed: %w: %s", dumper.tool, err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+// MARK: Helpers
+
+// dumper describes how to dump the schema of a DSN: reading a SQLite file, or
+// running a tool.
+type dumper struct {
+	file string
+	tool string
+	args []string
+	env  []string // Extra environment, to keep passwords out of the arguments
+}
+
+// schemaDumper returns the dumper for a DSN.
+func schemaDumper(dsn string) (dumper, error) {
+	scheme, rest, ok := strings.Cut(dsn, ":")
+	if !ok {
+		return dumper{}, fmt.Errorf("missing scheme in DSN %q (expected sqlite:, postgres:// or mysql://)", dsn)
+	}
+
+	switch scheme {
+	case "sqlite", "sqlite3":
+		name := strings.TrimPrefix(rest, "//")
+		if name == "" {
+			return dumper{}, errors.New("missing sqlite database file")
+		}
+		// Files may be given in the form of the other side of WSL, as the
+		// project directory is shared by both
+		file := filepath.FromSlash(wsl.Normalize(name))
+		if !filepath.IsLocal(file) {
+			return dumper{}, fmt.Errorf("sqlite database %q must be a relative path within the project", name)
+		}
+		return dumper{file: file}, nil
+	case "postgres", "postgresql":
+		return dumper{tool: "pg_dump", args: []string{"--schema-only", "--no-owner", "--no-privileges", dsn}}, nil
+	case "mysql":
+		u, err := url.Parse(dsn)
+		if err != nil {
+			return dumper{}, err
+		}
+		db := strings.TrimPrefix(u.Path, "/")
+		if db == "" {
+			return dumper{}, errors.New("missing database name in mysql DSN")
+		}
+		d := dumper{tool: "mysqldump", args: []string{"--no-data", "--skip-comments", "--host", u.Hostname()}}
+		if port := u.Port(); port != "" {
+			d.args = append(d.args, "--port", port)
+		}
+		if user := u.User.Username(); user != "" {
+			d.args = append(d.args, "--user", user)
+		}
+		if password, ok := u.User.Password(); ok {
+			d.env = append(d.env, "MYSQL_PWD="+password)
+		}
+		d.args = append(d.args, db)
+		return d, nil
+	default:
+		return dumper{}, fmt.Errorf("unsupported database scheme %q (expected sqlite:, postgres:// or mysql://)", scheme)
+	}
+}
@@ -0,0 +1,219 @@
+package convert
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/holonoms/sandworm/internal/util"
+)
+
+// How minified files are handled
+const (
+	MinifiedKeep    = "keep"    // Include minified files as is
+	MinifiedSources = "sources" // Replace them with the sources of their source map, if any (default)
+	MinifiedSummary = "summary" // Like sources, with a one-line placeholder when there's no map
+)
+
+// MinifiedModes lists the valid minified file modes.
+var MinifiedModes = []string{MinifiedKeep, MinifiedSources, MinifiedSummary}
+
+// minifiedLineLength is the average line length above which a file is
+// considered minified.
+const minifiedLineLength = 250
+
+// sourceMappingRE matches source map references (//# sourceMappingURL=... in
+// JS, /*# sourceMappingURL=... */ in CSS).
+var sourceMappingRE = regexp.MustCompile(`[#@]\s*sourceMappingURL=([^\s*]+)`)
+
+func init() {
+	Register(func(opts Options) Converter { return minifiedConverter{mode: opts.Minified} })
+}
+
+// minifiedConverter replaces minified JS/CSS with the original sources
+// embedded in their source map, and source maps with a summary.
+type minifiedConverter struct {
+	mode string
+}
+
+func (minifiedConverter) Name() string { return "sourcemap" }
+
+// Match accepts JS/CSS files and source maps.
+func (c minifiedConverter) Match(relPath string, _ []byte) bool {
+	if c.mode == MinifiedKeep {
+		return false
+	}
+	switch strings.ToLower(path.Ext(relPath)) {
+	case ".js", ".mjs", ".cjs", ".css", ".map":
+		return true
+	}
+	return false
+}
+
+// Convert returns the original sources of minified files, and a summary of
+// source maps. Other files are returned as is.
+func (c minifiedConverter) Convert(_ context.Context, f File) ([]byte, error) {
+	if strings.EqualFold(path.Ext(f.Path), ".map") {
+		m, err := parseSourceMap(f.Content)
+		if err != nil {
+			return f.Content, nil // Not a source map
+		}
+		return []byte(fmt.Sprintf("[source map of %d sources: %s]\n", len(m.Sources), strings.Join(m.Sources, ", "))), nil
+	}
+	if !isMinified(f.Path, f.Content) {
+		return f.Content, nil
+	}
+
+	mapName, m := findSourceMap(f)
+	switch {
+	case m != nil && len(m.SourcesContent) > 0:
+		return m.originalSources(mapName), nil
+	case m != nil:
+		return []byte(fmt.Sprintf("[minified file, %s; sources (not embedded in %s): %s]\n",
+			util.FormatSize(int64(len(f.Content))), mapName, strings.Join(m.Sources, ", "))), nil
+	case c.mode == MinifiedSummary:
+		return []byte(fmt.Sprintf("[minified file, %s, no source map]\n", util.FormatSize(int64(len(f.Content))))), nil
+	default:
+		return f.Content, nil
+	}
+}
+
+// Dependencies returns the source map files of minified files, which their
+// conversion reads.
+func (c minifiedConverter) Dependencies(f File) []string {
+	if strings.EqualFold(path.Ext(f.Path), ".map") || !isMinified(f.Path, f.Content) {
+		return nil
+	}
+	var deps []string
+	for _, candidate := range sourceMapFiles(f) {
+		deps = append(deps, filepath.Join(filepath.Dir(f.AbsPath), filepath.FromSlash(candidate)))
+	}
+	return deps
+}
+
+// MARK: Helpers
+
+// sourceMap holds the fields of a source map (v3) used here.
+type sourceMap struct {
+	SourceRoot     string    `json:"sourceRoot"`
+	Sources        []string  `json:"sources"`
+	SourcesContent []*string `json:"sourcesContent"`
+}
+
+func parseSourceMap(data []byte) (*sourceMap, error) {
+	var m sourceMap
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	if len(m.Sources) == 0 {
+		return nil, errors.New("no sources in source map")
+	}
+	return &m, nil
+}
+
+// originalSources renders the embedded sources, skipping third-party ones.
+func (m *sourceMap) originalSources(mapName string) []byte {
+	var b strings.Builder
+	thirdParty := 0
+	for i, source := range m.Sources {
+		if i >= len(m.SourcesContent) || m.SourcesContent[i] == nil {
+			continue
+		}
+		name := sourceName(m.SourceRoot, source)
+		if strings.Contains(name, "node_modules/") || strings.HasPrefix(name, "webpack/") {
+			thirdParty++
+			continue
+		}
+		fmt.Fprintf(&b, "\n--- %s ---\n%s\n", name, strings.TrimRight(*m.SourcesContent[i], "\n"))
+	}
+
+	header := fmt.Sprintf("[minified file; original sources from %s", mapName)
+	if thirdParty > 0 {
+		header += fmt.Sprintf(", %d third-party sources omitted", thirdParty)
+	}
+	return []byte(header + "]\n" + b.String())
+}
+
+// sourceName cleans up a source path: bundler schemes (webpack://) and
+// leading relative components are dropped.
+func sourceName(root, source string) string {
+	if _, rest, ok := strings.Cut(source, "://"); ok {
+		source = strings.TrimLeft(rest, "/")
+	} else if root != "" {
+		source = path.Join(root, source)
+	}
+	source = path.Clean(source)
+	for strings.HasPrefix(source, "../") {
+		source = strings.TrimPrefix(source, "../")
+	}
+	return strings.TrimPrefix(source, "./")
+}
+
+// findSourceMap returns the source map of a file: referenced by a
+// sourceMappingURL comment (inline or a relative file), or a sibling .map
+// file. It returns a nil map if there's none.
+func findSourceMap(f File) (string, *sourceMap) {
+	if match := sourceMappingRE.FindSubmatch(lastLine(f.Content)); match != nil {
+		if data, ok := strings.CutPrefix(string(match[1]), "data:"); ok {
+			if _, encoded, ok := strings.Cut(data, ";base64,"); ok {
+				if decoded, err := base64.StdEncoding.DecodeString(encoded); err == nil {
+					if m, err := parseSourceMap(decoded); err == nil {
+						return "inline source map", m
+					}
+				}
+			}
+		}
+	}
+
+	for _, candidate := range sourceMapFiles(f) {
+		data, err := os.ReadFile(filepath.Join(filepath.Dir(f.AbsPath), filepath.FromSlash(candidate)))
+		if err != nil {
+			continue
+		}
+		if m, err := parseSourceMap(data); err == nil {
+			return path.Base(candidate), m
+		}
+	}
+	return "", nil
+}
+
+// sourceMapFiles returns the files that may hold the source map of a file,
+// relative to its directory: the one its sourceMappingURL comment references,
+// then the sibling .map file.
+func sourceMapFiles(f File) []string {
+	var candidates []string
+	if match := sourceMappingRE.FindSubmatch(lastLine(f.Content)); match != nil {
+		if url := string(match[1]); !strings.HasPrefix(url, "data:") && !strings.Contains(url, "://") {
+			candidates = append(candidates, url)
+		}
+	}
+	return append(candidates, path.Base(f.Path)+".map")
+}
+
+// isMinified reports whether a file is minified, by name (.min.js) or by
+// average line length.
+func isMinified(relPath string, content []byte) bool {
+	base := strings.ToLower(path.Base(relPath))
+	if strings.Contains(base, ".min.") {
+		return true
+	}
+	lines := bytes.Count(bytes.TrimRight(content, "\n"), []byte("\n")) + 1
+	return len(content) > condenseMinSize && len(content)/lines > minifiedLineLength
+}
+
+// lastLine returns the last line of a file, where source map references are.
+func lastLine(content []byte) []byte {
+	content = bytes.TrimRight(content, " \r\n")
+	if i := bytes.LastIndexByte(content, '\n'); i >= 0 {
+		return content[i+1:]
+	}
+	return content
+}
@@ -0,0 +1,94 @@
+package convert
+
+import (
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMinifiedConverter(t *testing.T) {
+	dir := t.TempDir()
+	minified := "var a=1;" + strings.Repeat("function f(){return a}", 100) + "\n//# sourceMappingURL=app.min.js.map\n"
+	sourceMap := `{"version":3,"sources":["webpack:///./src/app.ts","webpack:///./node_modules/lib/index.js"],` +
+		`"sourcesContent":["export const a = 1;\n","module.exports = {};\n"],"mappings":""}`
+	write := func(name, content string) File {
+		t.Helper()
+		file := filepath.Join(dir, name)
+		if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+		return File{Path: "dist/" + name, AbsPath: file, Content: []byte(content)}
+	}
+	c := minifiedConverter{mode: MinifiedSources}
+
+	t.Run("original sources", func(t *testing.T) {
+		write("app.min.js.map", sourceMap)
+		got, err := c.Convert(context.Background(), write("app.min.js", minified))
+		want := "[minified file; original sources from app.min.js.map, 1 third-party sources omitted]\n\n--- src/app.ts ---\nexport const a = 1;\n"
+		if err != nil || string(got) != want {
+			t.Errorf("Expected %q, got %q (%v)", want, got, err)
+		}
+	})
+
+	t.Run("inline source map", func(t *testing.T) {
+		inline := strings.Repeat("a();", 400) + "\n//# sourceMappingURL=data:application/json;base64," +
+			base64.StdEncoding.EncodeToString([]byte(sourceMap))
+		got, _ := c.Convert(context.Background(), write("inline.js", inline))
+		if !strings.HasPrefix(string(got), "[minified file; original sources from inline source map") {
+			t.Errorf("Expected the inline map's sources, got %q", got)
+		}
+	})
+
+	t.Run("sources not embedded", func(t *testing.T) {
+		write("styles.min.css.map", `{"version":3,"sources":["../src/styles.scss"],"mappings":""}`)
+		got, _ := c.Convert(context.Background(), write("styles.min.css", "a{color:red}"))
+		if !strings.Contains(string(got), "sources (not embedded in styles.min.css.map): ../src/styles.scss]") {
+			t.Errorf("Expected the list of sources, got %q", got)
+		}
+	})
+
+	t.Run("without source map", func(t *testing.T) {
+		f := write("vendor.min.js", minified[:200])
+		if got, _ := c.Convert(context.Background(), f); string(got) != minified[:200] {
+			t.Errorf("Expected the file as is, got %q", got)
+		}
+		got, _ := minifiedConverter{mode: MinifiedSummary}.Convert(context.Background(), f)
+		if string(got) != "[minified file, 200.0 B, no source map]\n" {
+			t.Errorf("Expected a placeholder, got %q", got)
+		}
+	})
+
+	t.Run("source maps and regular files", func(t *testing.T) {
+		got, _ := c.Convert(context.Background(), write("app.min.js.map", sourceMap))
+		if !strings.HasPrefix(string(got), "[source map of 2 sources: webpack:///./src/app.ts") {
+			t.Errorf("Expected a summary of the source map, got %q", got)
+		}
+		regular := "function f() {\n  return 1;\n}\n"
+		if got, _ := c.Convert(context.Background(), write("app.js", regular)); string(got) != regular {
+			t.Errorf("Expected regular files as is, got %q", got)
+		}
+	})
+
+	if (minifiedConverter{mode: MinifiedKeep}).Match("app.min.js", nil) || c.Match("app.ts", nil) {
+		t.Error("Expected no match")
+	}
+}
+
+func TestSourceName(t *testing.T) {
+	tests := map[string]string{
+		"webpack:///./src/app.ts": "src/app.ts",
+		"../src/styles.scss":      "src/styles.scss",
+		"./lib/a.js":              "lib/a.js",
+	}
+	for source, want := range tests {
+		if got := sourceName("", source); got != want {
+			t.Errorf("sourceName(%q) = %q, want %q", source, got, want)
+		}
+	}
+	if got := sourceName("src", "app.ts"); got != "src/app.ts" {
+		t.Errorf("Expected the source root to apply, got %q", got)
+	}
+}
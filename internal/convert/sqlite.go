@@ -0,0 +1,353 @@
+package convert
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"path"
+	"strings"
+	"unicode/utf16"
+)
+
+// sqliteMagic starts every SQLite 3 database file.
+const sqliteMagic = "SQLite format 3\x00"
+
+// sqliteExtensions are the file extensions of SQLite databases.
+var sqliteExtensions = []string{".sqlite", ".sqlite3", ".db", ".db3"}
+
+// sqliteMaxDepth bounds b-tree traversals, so corrupted files can't loop.
+const sqliteMaxDepth = 32
+
+func init() {
+	Register(func(Options) Converter { return sqliteConverter{} })
+}
+
+// sqliteConverter renders SQLite databases as their schema (the CREATE
+// statements), with the row count of each table.
+type sqliteConverter struct{}
+
+func (sqliteConverter) Name() string { return "sqlite" }
+
+// Match accepts files with a SQLite extension, and checks the header when
+// the content is known (.db files aren't always SQLite).
+func (sqliteConverter) Match(relPath string, head []byte) bool {
+	if head != nil {
+		return bytes.HasPrefix(head, []byte(sqliteMagic))
+	}
+	ext := strings.ToLower(path.Ext(relPath))
+	for _, e := range sqliteExtensions {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+// Convert dumps the schema. Unreadable databases are noted rather than failing
+// the whole bundle.
+func (sqliteConverter) Convert(_ context.Context, f File) ([]byte, error) {
+	schema, err := SQLiteSchema(f.Content)
+	if err != nil {
+		return []byte(fmt.Sprintf("-- SQLite database (unable to read schema: %v)\n", err)), nil
+	}
+	return schema, nil
+}
+
+// SQLiteSchema returns the schema of a SQLite database: the statements
+// creating its tables, indexes, views and triggers, as in sqlite3's .schema.
+func SQLiteSchema(data []byte) ([]byte, error) {
+	db, err := newSQLiteDB(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows [][]any
+	if err := db.walkTable(1, func(payload []byte) error {
+		row, err := db.record(payload)
+		if err == nil {
+			rows = append(rows, row)
+		}
+		return err
+	}); err != nil {
+		return nil, fmt.Errorf("failed to read sqlite_master: %w", err)
+	}
+
+	var b strings.Builder
+	b.WriteString("-- SQLite database schema\n")
+	for _, row := range rows {
+		// sqlite_master columns: type, name, tbl_name, rootpage, sql
+		if len(row) < 5 {
+			continue
+		}
+		kind, _ := row[0].(string)
+		name, _ := row[1].(string)
+		sql, _ := row[4].(string)
+		if sql == "" || strings.HasPrefix(name, "sqlite_") {
+			continue
+		}
+
+		b.WriteString("\n" + strings.TrimSuffix(strings.TrimSpace(sql), ";") + ";\n")
+		if rootPage, ok := row[3].(int64); ok && kind == "table" && rootPage > 0 {
+			if count, err := db.countRows(uint32(rootPage)); err == nil {
+				fmt.Fprintf(&b, "-- %d rows\n", count)
+			}
+		}
+	}
+	return []byte(b.String()), nil
+}
+
+// MARK: Helpers
+
+// sqliteDB reads the b-trees of a SQLite database file.
+type sqliteDB struct {
+	data     []byte
+	pageSize int
+	usable   int // Page size minus the reserved bytes at the end of each page
+	utf16    binary.ByteOrder
+}
+
+func newSQLiteDB(data []byte) (*sqliteDB, error) {
+	if len(data) < 100 || !bytes.HasPrefix(data, []byte(sqliteMagic)) {
+		return nil, errors.New("not a SQLite 3 database")
+	}
+
+	pageSize := int(binary.BigEndian.Uint16(data[16:18]))
+	if pageSize == 1 {
+		pageSize = 65536
+	}
+	if pageSize < 512 || pageSize&(pageSize-1) != 0 {
+		return nil, fmt.Errorf("invalid page size %d", pageSize)
+	}
+
+	db := &sqliteDB{data: data, pageSize: pageSize, usable: pageSize - int(data[20])}
+	switch binary.BigEndian.Uint32(data[56:60]) {
+	case 2:
+		db.utf16 = binary.LittleEndian
+	case 3:
+		db.utf16 = binary.BigEndian
+	}
+	return db, nil
+}
+
+// page returns a page (numbered from 1) and the offset of its b-tree header,
+// which follows the file header on the first page.
+func (db *sqliteDB) page(n uint32) ([]byte, int, error) {
+	start := int(n-1) * db.pageSize
+	if n == 0 || start+db.pageSize > len(db.data) {
+		return nil, 0, fmt.Errorf("page %d out of range", n)
+	}
+	offset := 0
+	if n == 1 {
+		offset = 100
+	}
+	return db.data[start : start+db.pageSize], offset, nil
+}
+
+// walkTable calls fn with the payload of each row of the table b-tree rooted
+// at a page.
+func (db *sqliteDB) walkTable(n uint32, fn func(payload []byte) error) error {
+	return db.walkCells(n, 0, func(page []byte, cell int) error {
+		size, k := readVarint(page[cell:])
+		_, k2 := readVarint(page[cell+k:]) // rowid
+		payload, err := db.payload(page, cell+k+k2, int(size))
+		if err != nil {
+			return err
+		}
+		return fn(payload)
+	})
+}
+
+// countRows returns the number of rows of the table rooted at a page.
+func (db *sqliteDB) countRows(n uint32) (int, error) {
+	count := 0
+	err := db.walkCells(n, 0, func(_ []byte, _ int) error {
+		count++
+		return nil
+	})
+	return count, err
+}
+
+// walkCells calls fn with the offset of each leaf cell (row) of a table
+// b-tree, in rowid order.
+func (db *sqliteDB) walkCells(n uint32, depth int, fn func(page []byte, cell int) error) error {
+	if depth > sqliteMaxDepth {
+		return errors.New("b-tree too deep")
+	}
+	page, offset, err := db.page(n)
+	if err != nil {
+		return err
+	}
+
+	kind := page[offset]
+	if kind != 0x05 && kind != 0x0d {
+		return fmt.Errorf("page %d is not a table b-tree page", n)
+	}
+	leaf := kind == 0x0d
+	cells := int(binary.BigEndian.Uint16(page[offset+3:]))
+	pointers := offset + 8
+	if !leaf {
+		pointers = offset + 12
+	}
+	if pointers+2*cells > len(page) {
+		return fmt.Errorf("page %d is corrupted", n)
+	}
+
+	for i := range cells {
+		cell := int(binary.BigEndian.Uint16(page[pointers+2*i:]))
+		if cell+4 > len(page) {
+			return fmt.Errorf("page %d is corrupted", n)
+		}
+		if !leaf {
+			if err := db.walkCells(binary.BigEndian.Uint32(page[cell:]), depth+1, fn); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := fn(page, cell); err != nil {
+			return err
+		}
+	}
+	if !leaf {
+		return db.walkCells(binary.BigEndian.Uint32(page[offset+8:]), depth+1, fn)
+	}
+	return nil
+}
+
+// payload returns a cell's payload of a given size starting at an offset of
+// a table leaf page, following overflow pages.
+func (db *sqliteDB) payload(page []byte, offset, size int) ([]byte, error) {
+	maxLocal := db.usable - 35
+	local := size
+	if size > maxLocal {
+		minLocal := (db.usable-12)*32/255 - 23
+		local = minLocal + (size-minLocal)%(db.usable-4)
+		if local > maxLocal {
+			local = minLocal
+		}
+	}
+	if offset+local > len(page) {
+		return nil, errors.New("cell out of page bounds")
+	}
+	payload := append([]byte(nil), page[offset:offset+local]...)
+	if local == size {
+		return payload, nil
+	}
+
+	if offset+local+4 > len(page) {
+		return nil, errors.New("cell out of page bounds")
+	}
+	next := binary.BigEndian.Uint32(page[offset+local:])
+	for len(payload) < size {
+		overflow, _, err := db.page(next)
+		if err != nil {
+			return nil, err
+		}
+		chunk := overflow[4:db.usable]
+		if remaining := size - len(payload); len(chunk) > remaining {
+			chunk = chunk[:remaining]
+		}
+		payload = append(payload, chunk...)
+		next = binary.BigEndian.Uint32(overflow)
+	}
+	return payload, nil
+}
+
+// record decodes a record's values: nil, int64, float64, string or []byte.
+func (db *sqliteDB) record(payload []byte) ([]any, error) {
+	headerSize, n := readVarint(payload)
+	if int(headerSize) > len(payload) || n == 0 {
+		return nil, errors.New("invalid record")
+	}
+
+	var values []any
+	body := int(headerSize)
+	for pos := n; pos < int(headerSize); {
+		serialType, k := readVarint(payload[pos:])
+		if k == 0 {
+			return nil, errors.New("invalid record")
+		}
+		pos += k
+
+		size := serialTypeSize(serialType)
+		if body+size > len(payload) {
+			return nil, errors.New("invalid record")
+		}
+		value := payload[body : body+size]
+		body += size
+
+		switch {
+		case serialType == 0:
+			values = append(values, nil)
+		case serialType == 7:
+			values = append(values, math.Float64frombits(binary.BigEndian.Uint64(value)))
+		case serialType == 8, serialType == 9:
+			values = append(values, int64(serialType-8))
+		case serialType < 7:
+			values = append(values, readInt(value))
+		case serialType >= 13 && serialType%2 == 1:
+			values = append(values, db.text(value))
+		default:
+			values = append(values, value)
+		}
+	}
+	return values, nil
+}
+
+// text decodes a text value in the database encoding.
+func (db *sqliteDB) text(value []byte) string {
+	if db.utf16 == nil {
+		return string(value)
+	}
+	units := make([]uint16, len(value)/2)
+	for i := range units {
+		units[i] = db.utf16.Uint16(value[2*i:])
+	}
+	return string(utf16.Decode(units))
+}
+
+// serialTypeSize returns the size in bytes of a value of a serial type.
+func serialTypeSize(serialType uint64) int {
+	switch {
+	case serialType <= 4:
+		return []int{0, 1, 2, 3, 4}[serialType]
+	case serialType == 5:
+		return 6
+	case serialType == 6, serialType == 7:
+		return 8
+	case serialType < 12:
+		return 0
+	default:
+		return int((serialType - 12) / 2)
+	}
+}
+
+// readInt decodes a big-endian two's complement integer of 1 to 8 bytes.
+func readInt(b []byte) int64 {
+	var v int64
+	if len(b) > 0 && b[0]&0x80 != 0 {
+		v = -1
+	}
+	for _, c := range b {
+		v = v<<8 | int64(c)
+	}
+	return v
+}
+
+// readVarint decodes a SQLite varint, returning it and its length (0 if b is
+// too short).
+func readVarint(b []byte) (uint64, int) {
+	var v uint64
+	for i := 0; i < 9 && i < len(b); i++ {
+		if i == 8 {
+			return v<<8 | uint64(b[i]), 9
+		}
+		v = v<<7 | uint64(b[i]&0x7f)
+		if b[i]&0x80 == 0 {
+			return v, i + 1
+		}
+	}
+	return 0, 0
+}
@@ -0,0 +1,144 @@
+package convert
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// createSQLite creates a database with the sqlite3 CLI, skipping the test if
+// it's not installed.
+func createSQLite(t *testing.T, sql string) string {
+	t.Helper()
+	if _, err := exec.LookPath("sqlite3"); err != nil {
+		t.Skip("sqlite3 not installed")
+	}
+	file := filepath.Join(t.TempDir(), "app.db")
+	if out, err := exec.Command("sqlite3", file, sql).CombinedOutput(); err != nil {
+		t.Fatalf("Failed to create database: %v: %s", err, out)
+	}
+	return file
+}
+
+func TestSQLiteSchema(t *testing.T) {
+	// A small page size spreads rows over interior pages, and the wide table's
+	// statement over overflow pages.
+	columns := make([]string, 300)
+	for i := range columns {
+		columns[i] = fmt.Sprintf("col%d TEXT", i)
+	}
+	wide := "CREATE TABLE wide(" + strings.Join(columns, ", ") + ")"
+	file := createSQLite(t, `PRAGMA page_size=512;
+CREATE TABLE users(id INTEGER PRIMARY KEY AUTOINCREMENT, email TEXT NOT NULL);
+CREATE INDEX users_email ON users(email);
+CREATE VIEW emails AS SELECT email FROM users;
+`+wide+`;
+WITH RECURSIVE n(x) AS (SELECT 1 UNION ALL SELECT x+1 FROM n WHERE x<2000)
+INSERT INTO users(email) SELECT 'user'||x||'@example.com' FROM n;`)
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("Failed to read database: %v", err)
+	}
+	schema, err := SQLiteSchema(data)
+	if err != nil {
+		t.Fatalf("Failed to read schema: %v", err)
+	}
+
+	for _, want := range []string{
+		"CREATE TABLE users(id INTEGER PRIMARY KEY AUTOINCREMENT, email TEXT NOT NULL);\n-- 2000 rows\n",
+		"CREATE INDEX users_email ON users(email);\n",
+		"CREATE VIEW emails AS SELECT email FROM users;\n",
+		wide + ";\n-- 0 rows\n",
+	} {
+		if !strings.Contains(string(schema), want) {
+			t.Errorf("Expected %q in schema, got:\n%s", want, schema)
+		}
+	}
+	if strings.Contains(string(schema), "sqlite_sequence") {
+		t.Errorf("Expected internal tables to be skipped, got:\n%s", schema)
+	}
+
+	t.Run("invalid database", func(t *testing.T) {
+		if _, err := SQLiteSchema([]byte("not a database")); err == nil {
+			t.Error("Expected an error")
+		}
+		out, err := sqliteConverter{}.Convert(context.Background(), File{Path: "broken.db", Content: []byte(sqliteMagic + "garbage")})
+		if err != nil || !strings.Contains(string(out), "unable to read schema") {
+			t.Errorf("Expected a note for unreadable databases, got %q (%v)", out, err)
+		}
+	})
+
+	t.Run("match", func(t *testing.T) {
+		c := sqliteConverter{}
+		if !c.Match("data/app.sqlite3", nil) || c.Match("app.csv", nil) {
+			t.Error("Expected files to match by extension")
+		}
+		if !c.Match("app.db", data[:512]) || c.Match("app.db", []byte("Berkeley DB")) {
+			t.Error("Expected content to be checked")
+		}
+	})
+}
+
+func TestParseDatabases(t *testing.T) {
+	databases, err := ParseDatabases("app = postgres://localhost/app, cache=sqlite:cache.db,env=$DATABASE_URL")
+	if err != nil {
+		t.Fatalf("Failed to parse databases: %v", err)
+	}
+	want := []Database{
+		{Name: "app", DSN: "postgres://localhost/app"},
+		{Name: "cache", DSN: "sqlite:cache.db"},
+		{Name: "env", DSN: "$DATABASE_URL"},
+	}
+	if fmt.Sprint(databases) != fmt.Sprint(want) {
+		t.Errorf("Expected %v, got %v", want, databases)
+	}
+
+	for _, value := range []string{"app", "app=localhost/app", "app=redis://localhost", "app=mysql://localhost", "app=sqlite:/etc/app.db", "app=sqlite:../app.db"} {
+		if err := ValidateDatabases(value); err == nil {
+			t.Errorf("Expected an error for %q", value)
+		}
+	}
+
+	t.Run("mysql", func(t *testing.T) {
+		d, err := schemaDumper("mysql://root:secret@db:3307/shop")
+		if err != nil {
+			t.Fatalf("Failed to parse DSN: %v", err)
+		}
+		args := strings.Join(d.args, " ")
+		if d.tool != "mysqldump" || args != "--no-data --skip-comments --host db --port 3307 --user root shop" {
+			t.Errorf("Unexpected command: %s %s", d.tool, args)
+		}
+		if len(d.env) != 1 || d.env[0] != "MYSQL_PWD=secret" {
+			t.Errorf("Expected the password in the environment, got %v", d.env)
+		}
+	})
+
+	t.Run("sqlite", func(t *testing.T) {
+		file := createSQLite(t, "CREATE TABLE t(x);")
+		schema, err := Database{Name: "t", DSN: "sqlite:app.db"}.Schema(context.Background(), filepath.Dir(file))
+		if err != nil || !strings.Contains(string(schema), "CREATE TABLE t(x);") {
+			t.Errorf("Expected the schema, got %q (%v)", schema, err)
+		}
+
+		// Files out of the project aren't read, even through a variable or symlink
+		dir := t.TempDir()
+		if err := os.Symlink(file, filepath.Join(dir, "link.db")); err != nil {
+			t.Skip("Symlinks not supported")
+		}
+		t.Setenv("SANDWORM_TEST_DB", "sqlite:"+file)
+		for _, dsn := range []string{"$SANDWORM_TEST_DB", "sqlite:link.db"} {
+			if _, err := (Database{Name: "t", DSN: dsn}).Schema(context.Background(), dir); err == nil {
+				t.Errorf("Expected %s to be refused", dsn)
+			}
+		}
+	})
+
+	if !(Database{DSN: "$SANDWORM_TEST_UNSET"}).Unset() || (Database{DSN: "sqlite:app.db"}).Unset() {
+		t.Error("Expected only DSNs of unset variables to be unset")
+	}
+}
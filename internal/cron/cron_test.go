@@ -0,0 +1,80 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	valid := []string{
+		"* * * * *",
+		"0 * * * *",
+		"*/15 9-17 * * mon-fri",
+		"0,30 0 1,15 jan,jul *",
+		"5/10 * * * 7",
+		"@hourly",
+		"@Daily",
+		"0 0 */28 feb mon",
+	}
+	for _, expr := range valid {
+		if _, err := Parse(expr); err != nil {
+			t.Errorf("Parse(%q) failed: %v", expr, err)
+		}
+	}
+
+	invalid := []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"@sometimes",
+		"a * * * *",
+		"0 0 30 feb *",
+		"0 0 31 apr,jun *",
+	}
+	for _, expr := range invalid {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("Expected Parse(%q) to fail", expr)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	// Friday 2025-07-18 10:17:30 UTC
+	base := time.Date(2025, 7, 18, 10, 17, 30, 0, time.UTC)
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2025, 7, 18, 10, 18, 0, 0, time.UTC)},
+		{"0 * * * *", time.Date(2025, 7, 18, 11, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2025, 7, 18, 10, 30, 0, 0, time.UTC)},
+		{"30 9 * * mon-fri", time.Date(2025, 7, 21, 9, 30, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2025, 7, 20, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC)},
+		{"@yearly", time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)},
+		// Either day field matches when both are restricted
+		{"0 12 25 * sat", time.Date(2025, 7, 19, 12, 0, 0, 0, time.UTC)},
+		// Unless one of them starts with "*"
+		{"0 12 */2 * sat", time.Date(2025, 7, 19, 12, 0, 0, 0, time.UTC)},
+		{"0 12 */5 * fri", time.Date(2025, 8, 1, 12, 0, 0, 0, time.UTC)},
+		{"0 0 29 feb *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			s, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			if got := s.Next(base); !got.Equal(tt.want) {
+				t.Errorf("Next() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+// Package daemon runs a per-project background process (the watcher) and
+// lets other sandworm invocations query or stop it through a unix socket.
+package daemon
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNotRunning is returned when no daemon is running for a project.
+var ErrNotRunning = errors.New("daemon not running")
+
+// Commands understood by the status socket
+const (
+	CommandStatus = "status"
+	CommandStop   = "stop"
+)
+
+// Paths are the runtime files of a project's daemon.
+type Paths struct {
+	PID    string // Process ID of the daemon
+	Socket string // Unix socket serving status and stop commands
+	Log    string // Daemon output
+}
+
+// PathsFor returns the runtime file paths of the daemon for a project
+// directory, stored under baseDir. Projects are identified by a hash of
+// their absolute path, which keeps socket paths short.
+func PathsFor(baseDir, projectDir string) (Paths, error) {
+	abs, err := filepath.Abs(projectDir)
+	if err != nil {
+		return Paths{}, fmt.Errorf("failed to resolve project directory: %w", err)
+	}
+	sum := sha256.Sum256([]byte(abs))
+	base := filepath.Join(baseDir, "daemons", hex.EncodeToString(sum[:8]))
+	return Paths{PID: base + ".pid", Socket: base + ".sock", Log: base + ".log"}, nil
+}
+
+// Status describes a running daemon.
+type Status struct {
+	PID       int       `json:"pid"`
+	Directory string    `json:"directory"`
+	StartedAt time.Time `json:"started_at"`
+	LastPush  time.Time `json:"last_push,omitempty"`
+	Pushes    int       `json:"pushes"`
+	LastError string    `json:"last_error,omitempty"`
+}
+
+// response is the reply to a socket command.
+type response struct {
+	Status *Status `json:"status,omitempty"`
+	Error  string  `json:"error,omitempty"`
+}
+
+// Start launches the current executable with args as a detached background
+// process, writing its output to the log file and its PID to the pidfile.
+func Start(paths Paths, args []string) (int, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return 0, fmt.Errorf("failed to locate executable: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(paths.PID), 0o700); err != nil {
+		return 0, fmt.Errorf("failed to create daemon directory: %w", err)
+	}
+
+	logFile, err := os.OpenFile(paths.Log, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open log file: %w", err)
+	}
+	defer func() { _ = logFile.Close() }()
+
+	cmd := exec.Command(exe, args...)
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	detach(cmd)
+	if err := cmd.Start(); err != nil {
+		return 0, fmt.Errorf("failed to start daemon: %w", err)
+	}
+	pid := cmd.Process.Pid
+	// Reap the process if it exits while we're still around, so that Alive
+	// doesn't report a zombie as running.
+	go func() { _ = cmd.Wait() }()
+
+	if err := os.WriteFile(paths.PID, []byte(strconv.Itoa(pid)), 0o600); err != nil {
+		return pid, fmt.Errorf("failed to write pidfile: %w", err)
+	}
+	return pid, nil
+}
+
+// Serve answers status and stop commands on the socket until ctx is
+// cancelled. The stop function is called when a stop command is received.
+func Serve(ctx context.Context, socketPath string, status func() Status, stop func()) error {
+	// A leftover socket from a crashed daemon would make Listen fail
+	_ = os.Remove(socketPath)
+	if err := os.MkdirAll(filepath.Dir(socketPath), 0o700); err != nil {
+		return fmt.Errorf("failed to create daemon directory: %w", err)
+	}
+
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", socketPath, err)
+	}
+	go func() {
+		<-ctx.Done()
+		_ = listener.Close()
+	}()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("failed to accept connection: %w", err)
+		}
+		go handle(conn, status, stop)
+	}
+}
+
+func handle(conn net.Conn, status func() Status, stop func()) {
+	defer func() { _ = conn.Close() }()
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return
+	}
+
+	var resp response
+	switch strings.TrimSpace(line) {
+	case CommandStatus:
+		s := status()
+		resp.Status = &s
+	case CommandStop:
+		s := status()
+		resp.Status = &s
+		defer stop()
+	default:
+		resp.Error = fmt.Sprintf("unknown command %q", strings.TrimSpace(line))
+	}
+	_ = json.NewEncoder(conn).Encode(resp)
+}
+
+// Send sends a command to the daemon listening on the socket, returning its
+// status. It returns ErrNotRunning if nothing is listening.
+func Send(socketPath, command string) (*Status, error) {
+	conn, err := net.DialTimeout("unix", socketPath, 2*time.Second)
+	if err != nil {
+		return nil, ErrNotRunning
+	}
+	defer func() { _ = conn.Close() }()
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	if _, err := fmt.Fprintln(conn, command); err != nil {
+		return nil, fmt.Errorf("failed to send command: %w", err)
+	}
+	var resp response
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("failed to read daemon response: %w", err)
+	}
+	if resp.Error != "" {
+		return nil, errors.New(resp.Error)
+	}
+	return resp.Status, nil
+}
+
+// Cleanup removes the runtime files of a stopped daemon.
+func Cleanup(paths Paths) {
+	_ = os.Remove(paths.Socket)
+	_ = os.Remove(paths.PID)
+}
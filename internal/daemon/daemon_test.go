@@ -0,0 +1,77 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPathsFor(t *testing.T) {
+	a, err := PathsFor("/base", "/projects/a")
+	if err != nil {
+		t.Fatalf("PathsFor failed: %v", err)
+	}
+	b, err := PathsFor("/base", "/projects/b")
+	if err != nil {
+		t.Fatalf("PathsFor failed: %v", err)
+	}
+	if a.Socket == b.Socket {
+		t.Error("Expected distinct paths for distinct projects")
+	}
+	if !strings.HasPrefix(a.PID, filepath.Join("/base", "daemons")) || !strings.HasSuffix(a.Socket, ".sock") {
+		t.Errorf("Unexpected paths: %+v", a)
+	}
+}
+
+func TestServeAndSend(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "d.sock")
+
+	if _, err := Send(socket, CommandStatus); !errors.Is(err, ErrNotRunning) {
+		t.Fatalf("Expected ErrNotRunning without a daemon, got: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	started := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- Serve(ctx, socket, func() Status {
+			return Status{PID: 42, Directory: "/project", StartedAt: started, Pushes: 3}
+		}, cancel)
+	}()
+
+	// Wait for the socket to come up
+	var status *Status
+	var err error
+	for i := 0; i < 100; i++ {
+		if status, err = Send(socket, CommandStatus); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if status.PID != 42 || status.Pushes != 3 || status.Directory != "/project" {
+		t.Errorf("Unexpected status: %+v", status)
+	}
+
+	if _, err := Send(socket, "bogus"); err == nil {
+		t.Error("Expected error for unknown command")
+	}
+
+	if _, err := Send(socket, CommandStop); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Serve returned error: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for the server to stop")
+	}
+}
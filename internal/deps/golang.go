@@ -0,0 +1,177 @@
+// Package deps resolves dependency information between the files of a project,
+// so bundles can be scoped to what a given target actually needs.
+package deps
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// goModuleFiles are module-level files always included alongside packages.
+var goModuleFiles = []string{"go.mod", "go.sum", "go.work", "go.work.sum"}
+
+// GoPackageFiles returns the files (slash-separated, relative to root) making
+// up the Go package matching pattern. If withDeps is set, the files of all the
+// local packages it transitively imports are included too; packages from other
+// modules (standard library, third-party) are never included.
+//
+// This requires the go toolchain, which is used to load the packages.
+func GoPackageFiles(root, pattern string, withDeps bool) ([]string, error) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve root directory: %w", err)
+	}
+
+	mode := packages.NeedName | packages.NeedFiles | packages.NeedEmbedFiles | packages.NeedModule
+	if withDeps {
+		mode |= packages.NeedImports | packages.NeedDeps
+	}
+	pkgs, err := packages.Load(&packages.Config{Mode: mode, Dir: absRoot}, pattern)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load go package %s: %w", pattern, err)
+	}
+	if len(pkgs) == 0 {
+		return nil, fmt.Errorf("no go package matches %s", pattern)
+	}
+
+	files := map[string]bool{}
+	add := func(path string) {
+		rel, err := filepath.Rel(absRoot, path)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return
+		}
+		files[filepath.ToSlash(rel)] = true
+	}
+
+	var loadErr error
+	packages.Visit(pkgs, func(pkg *packages.Package) bool {
+		if loadErr == nil && len(pkg.Errors) > 0 {
+			loadErr = fmt.Errorf("failed to load go package %s: %s", pkg.PkgPath, pkg.Errors[0].Msg)
+		}
+		if pkg.Module == nil || !pkg.Module.Main {
+			return false
+		}
+		for _, list := range [][]string{pkg.GoFiles, pkg.OtherFiles, pkg.EmbedFiles, pkg.IgnoredFiles} {
+			for _, f := range list {
+				add(f)
+			}
+		}
+		if pkg.Module.Dir != "" {
+			for _, name := range goModuleFiles {
+				if modFile := filepath.Join(pkg.Module.Dir, name); fileExists(modFile) {
+					add(modFile)
+				}
+			}
+		}
+		return withDeps
+	}, nil)
+	if loadErr != nil {
+		return nil, loadErr
+	}
+
+	result := make([]string, 0, len(files))
+	for f := range files {
+		result = append(result, f)
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("go package %s has no files within %s", pattern, root)
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// goModule is a Go module found in the project.
+type goModule struct {
+	dir  string // Slash-separated directory relative to the root ("." for the root)
+	path string // Module path, from the go.mod module directive
+}
+
+// goAdapter resolves imports between the Go packages of the project. Each
+// package directory is a node of the graph.
+type goAdapter struct {
+	modules []goModule // Sorted by descending path length, for longest match
+}
+
+func newGoAdapter(fsys fs.FS, files []string) *goAdapter {
+	a := &goAdapter{}
+	seen := map[string]bool{}
+	for _, f := range files {
+		if !strings.HasSuffix(f, ".go") {
+			continue
+		}
+		// Find the module of each package by looking for the closest go.mod
+		for dir := path.Dir(f); !seen[dir]; dir = path.Dir(dir) {
+			seen[dir] = true
+			data, err := fs.ReadFile(fsys, path.Join(dir, "go.mod"))
+			if err == nil {
+				if modPath := goModulePath(data); modPath != "" {
+					a.modules = append(a.modules, goModule{dir: dir, path: modPath})
+				}
+				break
+			}
+			if dir == "." {
+				break
+			}
+		}
+	}
+	sort.Slice(a.modules, func(i, j int) bool { return len(a.modules[i].path) > len(a.modules[j].path) })
+	return a
+}
+
+func (a *goAdapter) match(relPath string) bool {
+	return strings.HasSuffix(relPath, ".go")
+}
+
+func (a *goAdapter) imports(relPath string, content []byte) (string, []string) {
+	node := path.Dir(relPath)
+	file, err := parser.ParseFile(token.NewFileSet(), relPath, content, parser.ImportsOnly)
+	if err != nil {
+		return node, nil
+	}
+
+	var deps []string
+	for _, imp := range file.Imports {
+		importPath, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			continue
+		}
+		for _, mod := range a.modules {
+			if importPath == mod.path {
+				deps = append(deps, mod.dir)
+				break
+			}
+			if rest, ok := strings.CutPrefix(importPath, mod.path+"/"); ok {
+				deps = append(deps, path.Join(mod.dir, rest))
+				break
+			}
+		}
+	}
+	return node, deps
+}
+
+// MARK: Helpers
+
+// goModulePath extracts the module path from go.mod contents.
+func goModulePath(data []byte) string {
+	for _, line := range strings.Split(string(data), "\n") {
+		if fields := strings.Fields(line); len(fields) == 2 && fields[0] == "module" {
+			return strings.Trim(fields[1], `"`)
+		}
+	}
+	return ""
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
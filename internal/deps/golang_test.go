@@ -0,0 +1,59 @@
+package deps
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestGoPackageFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"go.mod":               "module example.com/app\n\ngo 1.22\n",
+		"cmd/foo/main.go":      "package main\n\nimport _ \"example.com/app/internal/a\"\n\nfunc main() {}\n",
+		"cmd/bar/main.go":      "package main\n\nfunc main() {}\n",
+		"internal/a/a.go":      "package a\n\nimport (\n\t_ \"fmt\"\n\t_ \"example.com/app/internal/b\"\n)\n",
+		"internal/b/b.go":      "package b\n",
+		"internal/b/b_test.go": "package b\n",
+		"internal/c/c.go":      "package c\n",
+		"README.md":            "readme",
+	}
+	for path, content := range files {
+		fullPath := filepath.Join(tmpDir, path)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0o755); err != nil {
+			t.Fatalf("Failed to create directories for %s: %v", path, err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to create file %s: %v", path, err)
+		}
+	}
+
+	t.Run("package only", func(t *testing.T) {
+		got, err := GoPackageFiles(tmpDir, "./cmd/foo", false)
+		if err != nil {
+			t.Fatalf("GoPackageFiles failed: %v", err)
+		}
+		want := []string{"cmd/foo/main.go", "go.mod"}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Expected %v, got %v", want, got)
+		}
+	})
+
+	t.Run("with deps", func(t *testing.T) {
+		got, err := GoPackageFiles(tmpDir, "./cmd/foo", true)
+		if err != nil {
+			t.Fatalf("GoPackageFiles failed: %v", err)
+		}
+		want := []string{"cmd/foo/main.go", "go.mod", "internal/a/a.go", "internal/b/b.go"}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Expected %v, got %v", want, got)
+		}
+	})
+
+	t.Run("missing package", func(t *testing.T) {
+		if _, err := GoPackageFiles(tmpDir, "./cmd/missing", true); err == nil {
+			t.Error("Expected error for missing package")
+		}
+	})
+}
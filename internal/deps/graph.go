@@ -0,0 +1,87 @@
+package deps
+
+import (
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+)
+
+// Graph maps a node of the project (a Go package directory, a TS/JS file) to
+// the nodes it imports. Only intra-project imports are recorded.
+type Graph map[string][]string
+
+// adapter extracts intra-project imports for one language.
+type adapter interface {
+	// match reports whether the adapter handles the file.
+	match(relPath string) bool
+	// imports returns the graph node of the file and the nodes it imports.
+	imports(relPath string, content []byte) (node string, deps []string)
+}
+
+// BuildGraph builds the import graph of the given files (slash-separated,
+// relative to the root of fsys, which holds go.mod files). The read function
+// loads a file's contents.
+func BuildGraph(fsys fs.FS, files []string, read func(relPath string) ([]byte, error)) (Graph, error) {
+	adapters := []adapter{
+		newGoAdapter(fsys, files),
+		newScriptAdapter(files),
+	}
+
+	edges := map[string]map[string]bool{}
+	nodes := map[string]bool{}
+	for _, file := range files {
+		for _, a := range adapters {
+			if !a.match(file) {
+				continue
+			}
+			content, err := read(file)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s: %w", file, err)
+			}
+			node, deps := a.imports(file, content)
+			nodes[node] = true
+			for _, dep := range deps {
+				if dep == node {
+					continue
+				}
+				if edges[node] == nil {
+					edges[node] = map[string]bool{}
+				}
+				edges[node][dep] = true
+			}
+			break
+		}
+	}
+
+	// Only keep edges to nodes that are part of the bundle
+	graph := Graph{}
+	for node, deps := range edges {
+		for dep := range deps {
+			if nodes[dep] {
+				graph[node] = append(graph[node], dep)
+			}
+		}
+		sort.Strings(graph[node])
+		if len(graph[node]) == 0 {
+			delete(graph, node)
+		}
+	}
+
+	return graph, nil
+}
+
+// String renders the graph as one "node -> dep, dep" line per importing node.
+func (g Graph) String() string {
+	nodes := make([]string, 0, len(g))
+	for node := range g {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+
+	var b strings.Builder
+	for _, node := range nodes {
+		fmt.Fprintf(&b, "%s -> %s\n", node, strings.Join(g[node], ", "))
+	}
+	return b.String()
+}
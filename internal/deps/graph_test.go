@@ -0,0 +1,58 @@
+package deps
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestBuildGraph(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"go.mod":               "module example.com/app\n\ngo 1.22\n",
+		"cmd/app/main.go":      "package main\n\nimport (\n\t\"fmt\"\n\t\"example.com/app/internal/store\"\n\t\"github.com/other/lib\"\n)\n",
+		"internal/store/db.go": "package store\n\nimport \"example.com/app/internal/model\"\n",
+		"internal/model/m.go":  "package model\n",
+		"web/src/app.ts":       "import { a } from './lib/a.js';\nimport React from 'react';\nconst b = require(\"./lib\");\n",
+		"web/src/lib/a.ts":     "export * from '../util';\n",
+		"web/src/lib/index.ts": "export const x = 1;\n",
+		"web/src/util.tsx":     "export const u = 1;\n",
+	}
+	var relPaths []string
+	for path, content := range files {
+		fullPath := filepath.Join(tmpDir, path)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0o755); err != nil {
+			t.Fatalf("Failed to create directories for %s: %v", path, err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to create file %s: %v", path, err)
+		}
+		relPaths = append(relPaths, path)
+	}
+
+	graph, err := BuildGraph(os.DirFS(tmpDir), relPaths, func(relPath string) ([]byte, error) {
+		return []byte(files[relPath]), nil
+	})
+	if err != nil {
+		t.Fatalf("BuildGraph failed: %v", err)
+	}
+
+	want := Graph{
+		"cmd/app":          {"internal/store"},
+		"internal/store":   {"internal/model"},
+		"web/src/app.ts":   {"web/src/lib/a.ts", "web/src/lib/index.ts"},
+		"web/src/lib/a.ts": {"web/src/util.tsx"},
+	}
+	if !reflect.DeepEqual(graph, want) {
+		t.Errorf("Expected graph %v, got %v", want, graph)
+	}
+
+	expected := "cmd/app -> internal/store\n" +
+		"internal/store -> internal/model\n" +
+		"web/src/app.ts -> web/src/lib/a.ts, web/src/lib/index.ts\n" +
+		"web/src/lib/a.ts -> web/src/util.tsx\n"
+	if got := graph.String(); got != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, got)
+	}
+}
@@ -0,0 +1,86 @@
+package deps
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+// scriptExtensions are the TypeScript/JavaScript files handled by the script
+// adapter, in module resolution order.
+var scriptExtensions = []string{".ts", ".tsx", ".mts", ".cts", ".js", ".jsx", ".mjs", ".cjs"}
+
+// scriptImportRe matches static imports, re-exports, dynamic imports and
+// require calls, capturing the module specifier.
+var scriptImportRe = regexp.MustCompile(`(?m)(?:\bfrom\s*|^\s*import\s*|\bimport\s*\(\s*|\brequire\s*\(\s*)["']([^"'\n]+)["']`)
+
+// scriptAdapter resolves relative imports between TS/JS files. Each file is a
+// node of the graph; bare specifiers (packages) are ignored.
+type scriptAdapter struct {
+	files map[string]bool
+}
+
+func newScriptAdapter(files []string) *scriptAdapter {
+	a := &scriptAdapter{files: make(map[string]bool, len(files))}
+	for _, f := range files {
+		a.files[f] = true
+	}
+	return a
+}
+
+func (a *scriptAdapter) match(relPath string) bool {
+	return !strings.HasSuffix(relPath, ".d.ts") && hasScriptExtension(relPath)
+}
+
+func (a *scriptAdapter) imports(relPath string, content []byte) (string, []string) {
+	var deps []string
+	for _, m := range scriptImportRe.FindAllSubmatch(content, -1) {
+		spec := string(m[1])
+		if !strings.HasPrefix(spec, "./") && !strings.HasPrefix(spec, "../") {
+			continue
+		}
+		if target := a.resolve(path.Join(path.Dir(relPath), spec)); target != "" {
+			deps = append(deps, target)
+		}
+	}
+	return relPath, deps
+}
+
+// resolve finds the file a relative specifier refers to, following the usual
+// bundler rules: exact file, added extension, then directory index.
+func (a *scriptAdapter) resolve(target string) string {
+	if a.files[target] {
+		return target
+	}
+	// ESM TypeScript imports reference the compiled ".js" file
+	if base, ok := strings.CutSuffix(target, ".js"); ok {
+		for _, ext := range []string{".ts", ".tsx"} {
+			if a.files[base+ext] {
+				return base + ext
+			}
+		}
+	}
+	for _, ext := range scriptExtensions {
+		if a.files[target+ext] {
+			return target + ext
+		}
+	}
+	for _, ext := range scriptExtensions {
+		if index := target + "/index" + ext; a.files[index] {
+			return index
+		}
+	}
+	return ""
+}
+
+// MARK: Helpers
+
+func hasScriptExtension(relPath string) bool {
+	ext := path.Ext(relPath)
+	for _, e := range scriptExtensions {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,125 @@
+// Package encrypt encrypts and decrypts generated files with age or GPG, using
+// their command-line tools.
+package encrypt
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Encryption tools
+const (
+	ToolAge = "age"
+	ToolGPG = "gpg"
+)
+
+// Tools lists the supported encryption tools.
+var Tools = []string{ToolAge, ToolGPG}
+
+// Spec selects the tool and recipient to encrypt for.
+type Spec struct {
+	Tool      string
+	Recipient string // age recipient (public key or recipients file) or GPG key ID/email
+}
+
+// Parse parses an encryption spec of the form "<tool>:<recipient>", e.g.
+// "age:age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p" or
+// "gpg:alice@example.com".
+func Parse(spec string) (Spec, error) {
+	tool, recipient, ok := strings.Cut(spec, ":")
+	if !ok || recipient == "" {
+		return Spec{}, fmt.Errorf("invalid encryption %q (expected <tool>:<recipient>, e.g. age:age1...)", spec)
+	}
+	if tool != ToolAge && tool != ToolGPG {
+		return Spec{}, fmt.Errorf("invalid encryption tool %q (must be one of %s)", tool, strings.Join(Tools, ", "))
+	}
+	return Spec{Tool: tool, Recipient: recipient}, nil
+}
+
+// Extension returns the file extension for files encrypted with the spec.
+func (s Spec) Extension() string {
+	return "." + s.Tool
+}
+
+// EncryptFile encrypts the file at in into out.
+func EncryptFile(spec Spec, in, out string) error {
+	name, args := encryptCommand(spec, in, out)
+	return run(name, args)
+}
+
+// DecryptFile decrypts the file at in into out, detecting the tool from its
+// contents. identity is the age identity file (ignored for GPG, which uses
+// its keyring).
+func DecryptFile(in, out, identity string) error {
+	tool, err := detect(in)
+	if err != nil {
+		return err
+	}
+	if tool == ToolAge && identity == "" {
+		return errors.New("decrypting age files requires an identity file (--identity)")
+	}
+	name, args := decryptCommand(tool, in, out, identity)
+	return run(name, args)
+}
+
+// MARK: Helpers
+
+// encryptCommand returns the command encrypting in into out.
+func encryptCommand(spec Spec, in, out string) (string, []string) {
+	if spec.Tool == ToolAge {
+		flag := "-r"
+		if _, err := os.Stat(spec.Recipient); err == nil {
+			flag = "-R" // Recipients file
+		}
+		return "age", []string{"--encrypt", flag, spec.Recipient, "-o", out, in}
+	}
+	return "gpg", []string{"--batch", "--yes", "--encrypt", "--recipient", spec.Recipient, "--output", out, in}
+}
+
+// decryptCommand returns the command decrypting in into out.
+func decryptCommand(tool, in, out, identity string) (string, []string) {
+	if tool == ToolAge {
+		return "age", []string{"--decrypt", "-i", identity, "-o", out, in}
+	}
+	return "gpg", []string{"--batch", "--yes", "--decrypt", "--output", out, in}
+}
+
+// detect returns the tool that encrypted a file, from its header: age files
+// start with a version line (or an armor header); anything else is assumed
+// to be OpenPGP.
+func detect(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	header := make([]byte, 64)
+	n, err := io.ReadFull(f, header)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return "", fmt.Errorf("failed to read file: %w", err)
+	}
+	header = header[:n]
+	if bytes.HasPrefix(header, []byte("age-encryption.org/")) || bytes.HasPrefix(header, []byte("-----BEGIN AGE ENCRYPTED FILE-----")) {
+		return ToolAge, nil
+	}
+	return ToolGPG, nil
+}
+
+// run runs an encryption tool, reporting its output on failure.
+func run(name string, args []string) error {
+	if _, err := exec.LookPath(name); err != nil {
+		return fmt.Errorf("%s not found in PATH: install it to encrypt/decrypt files", name)
+	}
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = os.Stdin // For passphrase/pinentry prompts
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to run %s: %w: %s", name, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package encrypt
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	spec, err := Parse("gpg:alice@example.com")
+	if err != nil {
+		t.Fatalf("Failed to parse spec: %v", err)
+	}
+	if spec.Tool != ToolGPG || spec.Recipient != "alice@example.com" || spec.Extension() != ".gpg" {
+		t.Errorf("Unexpected spec: %+v", spec)
+	}
+
+	for _, invalid := range []string{"age", "age:", "pgp:alice@example.com"} {
+		if _, err := Parse(invalid); err == nil {
+			t.Errorf("Expected an error for %q", invalid)
+		}
+	}
+}
+
+func TestCommands(t *testing.T) {
+	name, args := encryptCommand(Spec{Tool: ToolAge, Recipient: "age1xyz"}, "in.txt", "in.txt.age")
+	if got := name + " " + strings.Join(args, " "); got != "age --encrypt -r age1xyz -o in.txt.age in.txt" {
+		t.Errorf("Unexpected age command: %s", got)
+	}
+
+	name, args = decryptCommand(ToolGPG, "in.txt.gpg", "in.txt", "")
+	if got := name + " " + strings.Join(args, " "); got != "gpg --batch --yes --decrypt --output in.txt in.txt.gpg" {
+		t.Errorf("Unexpected gpg command: %s", got)
+	}
+}
+
+func TestDetect(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"binary.age":  "age-encryption.org/v1\n-> X25519 ...",
+		"armored.age": "-----BEGIN AGE ENCRYPTED FILE-----\n...",
+		"file.gpg":    "\x85\x02\x0c\x03",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	for name, want := range map[string]string{"binary.age": ToolAge, "armored.age": ToolAge, "file.gpg": ToolGPG} {
+		got, err := detect(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("Failed to detect %s: %v", name, err)
+		}
+		if got != want {
+			t.Errorf("Expected %s for %s, got %s", want, name, got)
+		}
+	}
+}
+
+func TestGPGRoundTrip(t *testing.T) {
+	if _, err := exec.LookPath("gpg"); err != nil {
+		t.Skip("gpg not installed")
+	}
+	home := t.TempDir()
+	t.Setenv("GNUPGHOME", home)
+	gen := exec.Command("gpg", "--batch", "--passphrase", "", "--quick-gen-key", "sandworm-test@example.com", "default", "default", "never")
+	if out, err := gen.CombinedOutput(); err != nil {
+		t.Skipf("Unable to generate a test key: %v: %s", err, out)
+	}
+	t.Cleanup(func() { _ = exec.Command("gpgconf", "--kill", "gpg-agent").Run() })
+
+	dir := t.TempDir()
+	plain := filepath.Join(dir, "sandworm.txt")
+	if err := os.WriteFile(plain, []byte("secret project"), 0o644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	spec := Spec{Tool: ToolGPG, Recipient: "sandworm-test@example.com"}
+	if err := EncryptFile(spec, plain, plain+spec.Extension()); err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+	decrypted := filepath.Join(dir, "decrypted.txt")
+	if err := DecryptFile(plain+spec.Extension(), decrypted, ""); err != nil {
+		t.Fatalf("Failed to decrypt: %v", err)
+	}
+	data, err := os.ReadFile(decrypted)
+	if err != nil {
+		t.Fatalf("Failed to read decrypted file: %v", err)
+	}
+	if string(data) != "secret project" {
+		t.Errorf("Expected decrypted content to match, got %q", data)
+	}
+}
@@ -14,11 +14,29 @@ import (
 // Node (for directories) or nil (for files).
 type Node map[string]any
 
+// Charset defines the connectors used to draw the tree.
+type Charset struct {
+	Branch string // Connector for entries followed by siblings
+	Last   string // Connector for the last entry in a directory
+	Pipe   string // Prefix continuing a parent that has more siblings
+	Blank  string // Prefix below a parent that was the last entry
+}
+
+var (
+	// UnicodeCharset draws the tree with box-drawing characters (default).
+	UnicodeCharset = Charset{Branch: "├── ", Last: "└── ", Pipe: "│   ", Blank: "    "}
+
+	// ASCIICharset draws the tree with plain ASCII characters, for terminals
+	// and logs that mangle box-drawing characters.
+	ASCIICharset = Charset{Branch: "|-- ", Last: "`-- ", Pipe: "|   ", Blank: "    "}
+)
+
 // FileTree creates ASCII tree representations of directory structures.
 // It maintains an internal map-based representation of the directory
 // hierarchy that can be rendered into a string format.
 type FileTree struct {
-	root Node
+	root    Node
+	charset Charset
 }
 
 // New creates a new FileTree instance and processes the provided paths
@@ -26,7 +44,8 @@ type FileTree struct {
 // and added to the tree while maintaining the hierarchical relationships.
 func New(paths []string) *FileTree {
 	tree := &FileTree{
-		root: make(Node),
+		root:    make(Node),
+		charset: UnicodeCharset,
 	}
 	for _, path := range paths {
 		// Split path into components, automatically filtering empty parts
@@ -42,6 +61,11 @@ func New(paths []string) *FileTree {
 	return tree
 }
 
+// SetCharset changes the characters used to draw the tree.
+func (t *FileTree) SetCharset(charset Charset) {
+	t.charset = charset
+}
+
 // String renders the file tree into a string representation using ASCII characters
 // for the tree structure. It includes an optional custom root name and uses standard
 // tree drawing characters (├──, └──, │) to show the hierarchy.
@@ -95,9 +119,9 @@ func (t *FileTree) buildTree(node Node, prefix string, result *[]string) {
 
 	for i, name := range entries {
 		isLast := i == len(entries)-1
-		connector := "├── "
+		connector := t.charset.Branch
 		if isLast {
-			connector = "└── "
+			connector = t.charset.Last
 		}
 
 		// Add directory indicator for non-files
@@ -111,9 +135,9 @@ func (t *FileTree) buildTree(node Node, prefix string, result *[]string) {
 		if i < len(dirs) {
 			newPrefix := prefix
 			if isLast {
-				newPrefix += "    "
+				newPrefix += t.charset.Blank
 			} else {
-				newPrefix += "│   "
+				newPrefix += t.charset.Pipe
 			}
 			t.buildTree(node[name].(Node), newPrefix, result)
 		}
@@ -120,4 +120,28 @@ func TestFileTree(t *testing.T) {
 			t.Errorf("Expected:\n%s\n\nGot:\n%s", expected, result)
 		}
 	})
+
+	t.Run("ascii charset", func(t *testing.T) {
+		paths := []string{
+			"dir1/file1.txt",
+			"dir2/subdir/file2.txt",
+			"file3.txt",
+		}
+		tree := New(paths)
+		tree.SetCharset(ASCIICharset)
+		result := tree.String("")
+		expected := strings.Join([]string{
+			"/",
+			"|-- dir1/",
+			"|   `-- file1.txt",
+			"|-- dir2/",
+			"|   `-- subdir/",
+			"|       `-- file2.txt",
+			"`-- file3.txt",
+		}, "\n")
+
+		if result != expected {
+			t.Errorf("Expected:\n%s\n\nGot:\n%s", expected, result)
+		}
+	})
 }
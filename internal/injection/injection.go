@@ -0,0 +1,66 @@
+// Package injection flags instruction-like content that could hijack an LLM
+// reading the bundle (prompt injection), such as "ignore previous
+// instructions" planted in third-party files. It's a heuristic meant to
+// surface files for human review, not a guarantee.
+package injection
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+
+	"github.com/holonoms/sandworm/internal/sanitize"
+)
+
+// maxSnippet is the maximum length of the line excerpt of a finding.
+const maxSnippet = 100
+
+// Finding is a suspicious line.
+type Finding struct {
+	Line    int    // 1-based line number
+	Rule    string // Name of the matching rule
+	Snippet string // The line, with invisible characters escaped
+}
+
+// rule flags lines matching a pattern.
+type rule struct {
+	name    string
+	pattern *regexp.Regexp
+}
+
+// rules are checked on each line, in order; a line is reported once.
+var rules = []rule{
+	{"ignore-instructions", regexp.MustCompile(`(?i)\b(ignore|disregard|forget|override)\b[^.\n]{0,40}\b(previous|prior|above|earlier|all|any|your)\b[^.\n]{0,20}\b(instructions?|prompts?|rules|directives|guidelines)\b`)},
+	{"role-override", regexp.MustCompile(`(?i)\b(you are now|from now on,? you|pretend (to be|you are)|new instructions?:)`)},
+	{"prompt-leak", regexp.MustCompile(`(?i)\b(reveal|print|output|repeat|show)\b[^.\n]{0,30}\b(system prompt|your instructions)\b`)},
+	{"chat-markup", regexp.MustCompile(`<\|im_(start|end)\|>|<\|endoftext\|>|\[/?INST\]|<</?SYS>>|^\s*(Human|Assistant):\s`)},
+	{"concealment", regexp.MustCompile(`(?i)\b(do not|don't|never)\s+(tell|inform|mention|reveal|alert)\b[^.\n]{0,20}\b(user|human|developer)s?\b`)},
+	{"exfiltration", regexp.MustCompile(`(?i)\b(send|post|upload|exfiltrate)\b[^\n]{0,60}\b(api.?keys?|credentials|secrets|passwords)\b[^\n]{0,60}https?://`)},
+	{"hidden-unicode", regexp.MustCompile(`[\x{E0000}-\x{E007F}\x{202A}-\x{202E}\x{2066}-\x{2069}]`)},
+}
+
+// Scan returns the suspicious lines of a file's content.
+func Scan(content []byte) []Finding {
+	var findings []Finding
+	for i, line := range bytes.Split(content, []byte("\n")) {
+		for _, r := range rules {
+			if r.pattern.Match(line) {
+				findings = append(findings, Finding{Line: i + 1, Rule: r.name, Snippet: snippet(line)})
+				break
+			}
+		}
+	}
+	return findings
+}
+
+// MARK: Helpers
+
+// snippet returns a printable excerpt of a line.
+func snippet(line []byte) string {
+	escaped, _ := sanitize.Sanitize(bytes.TrimSpace(line), sanitize.Escape)
+	s := string(escaped)
+	if len(s) > maxSnippet {
+		s = strings.ToValidUTF8(s[:maxSnippet], "") + "..."
+	}
+	return s
+}
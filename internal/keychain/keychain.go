@@ -0,0 +1,95 @@
+// Package keychain stores secrets in the OS keychain, using its command-line
+// tools: security on macOS and secret-tool (libsecret) elsewhere.
+package keychain
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// service is the keychain service sandworm's secrets are stored under.
+const service = "sandworm"
+
+// ErrUnsupported is returned when no supported keychain tool is available.
+var ErrUnsupported = errors.New("no supported keychain (requires security on macOS or secret-tool on Linux and the BSDs)")
+
+// Set stores a secret for an account, replacing any previous one.
+func Set(account, secret string) error {
+	name, args, stdin := setCommand(account, secret)
+	_, err := run(name, args, stdin)
+	return err
+}
+
+// Get returns the secret stored for an account.
+func Get(account string) (string, error) {
+	name, args := getCommand(account)
+	out, err := run(name, args, "")
+	if err != nil {
+		return "", err
+	}
+	secret := strings.TrimRight(out, "\n")
+	if secret == "" {
+		return "", fmt.Errorf("no secret stored for %s", account)
+	}
+	return secret, nil
+}
+
+// Delete removes the secret stored for an account.
+func Delete(account string) error {
+	name, args := deleteCommand(account)
+	_, err := run(name, args, "")
+	return err
+}
+
+// MARK: Helpers
+
+// setCommand returns the command storing a secret, and its input. The secret
+// is passed on stdin rather than as an argument, where other users could see
+// it in the process list.
+func setCommand(account, secret string) (string, []string, string) {
+	if runtime.GOOS == "darwin" {
+		// In interactive mode, security reads its commands from stdin
+		command := fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n", service, strconv.Quote(account), strconv.Quote(secret))
+		return "security", []string{"-i"}, command
+	}
+	return "secret-tool", []string{"store", "--label=sandworm " + account, "service", service, "account", account}, secret
+}
+
+// getCommand returns the command printing a secret.
+func getCommand(account string) (string, []string) {
+	if runtime.GOOS == "darwin" {
+		return "security", []string{"find-generic-password", "-s", service, "-a", account, "-w"}
+	}
+	return "secret-tool", []string{"lookup", "service", service, "account", account}
+}
+
+// deleteCommand returns the command removing a secret.
+func deleteCommand(account string) (string, []string) {
+	if runtime.GOOS == "darwin" {
+		return "security", []string{"delete-generic-password", "-s", service, "-a", account}
+	}
+	return "secret-tool", []string{"clear", "service", service, "account", account}
+}
+
+// run runs a keychain tool, reporting its output on failure.
+func run(name string, args []string, stdin string) (string, error) {
+	if runtime.GOOS == "windows" {
+		return "", ErrUnsupported
+	}
+	if _, err := exec.LookPath(name); err != nil {
+		return "", ErrUnsupported
+	}
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = strings.NewReader(stdin)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to run %s: %w: %s", name, err, strings.TrimSpace(stderr.String()))
+	}
+	return string(out), nil
+}
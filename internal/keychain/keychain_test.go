@@ -0,0 +1,24 @@
+package keychain
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSetCommandKeepsSecretOutOfArgs(t *testing.T) {
+	name, args, stdin := setCommand("/home/me/.config/sandworm/config.json", "s3cr3t")
+	if name == "" || stdin == "" {
+		t.Fatalf("Unexpected command: %s %v", name, args)
+	}
+	for _, arg := range args {
+		if arg == "s3cr3t" {
+			t.Errorf("Expected the secret on stdin, got it in the arguments: %v", args)
+		}
+	}
+	if get, _ := getCommand("x"); get != name {
+		t.Errorf("Expected the same tool to store and read secrets, got %s and %s", name, get)
+	}
+	if _, args := deleteCommand("x"); !slices.Contains(args, service) {
+		t.Errorf("Expected the delete command to target the sandworm service: %v", args)
+	}
+}
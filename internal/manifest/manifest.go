@@ -0,0 +1,144 @@
+// Package manifest reads and writes the checksum manifest embedded at the end
+// of generated files: the SHA-256 of each file's content, in the format of
+// sha256sum.
+package manifest
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Heading introduces the manifest section in generated files.
+const Heading = "CHECKSUM MANIFEST (SHA-256):"
+
+// Manifest maps relative (slash-separated) file paths to SHA-256 hashes.
+type Manifest map[string]string
+
+// lineRE matches a manifest entry: "<hash>  <path>", as written by sha256sum.
+var lineRE = regexp.MustCompile(`^([0-9a-f]{64})  (.+)$`)
+
+// Hash returns the hex SHA-256 of content.
+func Hash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// Write writes the manifest section, sorted by path.
+func (m Manifest) Write(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n\n%s\n%s\n\n", Heading, strings.Repeat("=", len(Heading)))
+	for _, path := range m.paths() {
+		fmt.Fprintf(&b, "%s  %s\n", m[path], path)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Read extracts the manifest from a generated file. The last manifest section
+// is used, as file contents may contain the heading too.
+func Read(r io.Reader) (Manifest, error) {
+	var m Manifest
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == Heading {
+			m = Manifest{}
+			continue
+		}
+		if m == nil {
+			continue
+		}
+		if match := lineRE.FindStringSubmatch(line); match != nil {
+			m[match[2]] = match[1]
+		} else if strings.TrimSpace(line) != "" && strings.Trim(line, "=") != "" {
+			// Not a manifest after all (e.g. the heading quoted in a file)
+			m = nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	if m == nil {
+		return nil, fmt.Errorf("no checksum manifest found (generate with --manifest)")
+	}
+	return m, nil
+}
+
+// ReadFile extracts the manifest from a generated file on disk.
+func ReadFile(path string) (Manifest, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	return Read(f)
+}
+
+// Changes lists the differences between two manifests.
+type Changes struct {
+	Added    []string
+	Removed  []string
+	Modified []string
+}
+
+// Empty reports whether there are no differences.
+func (c Changes) Empty() bool {
+	return len(c.Added) == 0 && len(c.Removed) == 0 && len(c.Modified) == 0
+}
+
+// Diff returns the files added, removed and modified from old to updated.
+func Diff(old, updated Manifest) Changes {
+	var c Changes
+	for _, path := range updated.paths() {
+		hash, ok := old[path]
+		switch {
+		case !ok:
+			c.Added = append(c.Added, path)
+		case hash != updated[path]:
+			c.Modified = append(c.Modified, path)
+		}
+	}
+	for _, path := range old.paths() {
+		if _, ok := updated[path]; !ok {
+			c.Removed = append(c.Removed, path)
+		}
+	}
+	return c
+}
+
+// Verify compares the manifest against the files in dir, returning the
+// modified and missing files.
+func (m Manifest) Verify(dir string) (modified, missing []string) {
+	for _, path := range m.paths() {
+		content, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(path)))
+		if err != nil {
+			missing = append(missing, path)
+			continue
+		}
+		if Hash(content) != m[path] {
+			modified = append(modified, path)
+		}
+	}
+	return modified, missing
+}
+
+// MARK: Helpers
+
+// paths returns the manifest's paths, sorted.
+func (m Manifest) paths() []string {
+	paths := make([]string, 0, len(m))
+	for path := range m {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
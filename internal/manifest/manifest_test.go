@@ -0,0 +1,69 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWriteRead(t *testing.T) {
+	m := Manifest{
+		"main.go":    Hash([]byte("package main\n")),
+		"lib/lib.go": Hash([]byte("package lib\n")),
+	}
+
+	var b strings.Builder
+	b.WriteString("FILE CONTENTS:\n\n// A file quoting the heading:\n" + Heading + "\nnot a manifest\n")
+	if err := m.Write(&b); err != nil {
+		t.Fatalf("Failed to write manifest: %v", err)
+	}
+	if !strings.Contains(b.String(), m["lib/lib.go"]+"  lib/lib.go\n"+m["main.go"]+"  main.go\n") {
+		t.Errorf("Expected sorted sha256sum-style entries, got:\n%s", b.String())
+	}
+
+	got, err := Read(strings.NewReader(b.String()))
+	if err != nil {
+		t.Fatalf("Failed to read manifest: %v", err)
+	}
+	if !reflect.DeepEqual(got, m) {
+		t.Errorf("Expected %v, got %v", m, got)
+	}
+
+	t.Run("missing manifest", func(t *testing.T) {
+		if _, err := Read(strings.NewReader("PROJECT STRUCTURE:\n")); err == nil {
+			t.Error("Expected an error without a manifest")
+		}
+	})
+}
+
+func TestDiff(t *testing.T) {
+	old := Manifest{"a": Hash([]byte("a")), "b": Hash([]byte("b")), "c": Hash([]byte("c"))}
+	updated := Manifest{"a": Hash([]byte("a")), "b": Hash([]byte("b2")), "d": Hash([]byte("d"))}
+
+	got := Diff(old, updated)
+	want := Changes{Added: []string{"d"}, Removed: []string{"c"}, Modified: []string{"b"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+	if !Diff(old, old).Empty() {
+		t.Error("Expected no changes between identical manifests")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("changed"), 0o644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "b.txt"), []byte("b"), 0o644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	m := Manifest{"a.txt": Hash([]byte("a")), "b.txt": Hash([]byte("b")), "c.txt": Hash([]byte("c"))}
+	modified, missing := m.Verify(dir)
+	if !reflect.DeepEqual(modified, []string{"a.txt"}) || !reflect.DeepEqual(missing, []string{"c.txt"}) {
+		t.Errorf("Unexpected result: modified %v, missing %v", modified, missing)
+	}
+}
@@ -0,0 +1,218 @@
+// Package manpage generates man pages from a cobra command tree: one page
+// per command in section 1, and one per additional help topic (commands
+// without a Run function nor subcommands) in section 7.
+package manpage
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// Sections of the generated pages
+const (
+	CommandSection = 1
+	TopicSection   = 7
+)
+
+// Generate writes the man pages of root and its descendants to dir, named
+// after their command path (e.g. sandworm-config-set.1). date is the one
+// shown in the page footers.
+func Generate(root *cobra.Command, dir string, date time.Time) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	return generate(root, dir, date)
+}
+
+func generate(cmd *cobra.Command, dir string, date time.Time) error {
+	for _, child := range cmd.Commands() {
+		if !child.IsAvailableCommand() && !child.IsAdditionalHelpTopicCommand() {
+			continue
+		}
+		if err := generate(child, dir, date); err != nil {
+			return err
+		}
+	}
+
+	path := filepath.Join(dir, Name(cmd))
+	if err := os.WriteFile(path, []byte(Render(cmd, date)), 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// Name returns the file name of a command's page.
+func Name(cmd *cobra.Command) string {
+	return fmt.Sprintf("%s.%d", pageName(cmd), section(cmd))
+}
+
+// Render returns the man page of a command, in roff.
+func Render(cmd *cobra.Command, date time.Time) string {
+	root := cmd.Root()
+	var b strings.Builder
+	fmt.Fprintf(&b, ".TH %q %q %q %q %q\n", strings.ToUpper(pageName(cmd)), fmt.Sprint(section(cmd)),
+		date.Format("Jan 2006"), strings.TrimSpace(root.Name()+" "+root.Version), titleCase(root.Name())+" Manual")
+
+	b.WriteString(".SH NAME\n")
+	fmt.Fprintf(&b, "%s \\- %s\n", escape(pageName(cmd)), escape(cmd.Short))
+
+	if !cmd.IsAdditionalHelpTopicCommand() {
+		b.WriteString(".SH SYNOPSIS\n")
+		if cmd.Runnable() {
+			fmt.Fprintf(&b, "\\fB%s\\fP %s\n", escape(cmd.CommandPath()), escape(strings.TrimPrefix(cmd.UseLine(), cmd.CommandPath()+" ")))
+		}
+		if cmd.HasAvailableSubCommands() {
+			if cmd.Runnable() {
+				b.WriteString(".br\n")
+			}
+			fmt.Fprintf(&b, "\\fB%s\\fP \\fIcommand\\fP [flags]\n", escape(cmd.CommandPath()))
+		}
+	}
+
+	b.WriteString(".SH DESCRIPTION\n")
+	writeText(&b, cmp.Or(cmd.Long, cmd.Short))
+
+	if cmd.HasAvailableSubCommands() {
+		b.WriteString(".SH COMMANDS\n")
+		for _, child := range cmd.Commands() {
+			if child.IsAvailableCommand() {
+				fmt.Fprintf(&b, ".TP\n\\fB%s\\fP\n%s\n", escape(child.Name()), escape(child.Short))
+			}
+		}
+	}
+	if !cmd.IsAdditionalHelpTopicCommand() {
+		writeFlags(&b, "OPTIONS", cmd.NonInheritedFlags())
+		writeFlags(&b, "GLOBAL OPTIONS", cmd.InheritedFlags())
+	}
+
+	if cmd.Example != "" {
+		b.WriteString(".SH EXAMPLES\n")
+		writeText(&b, cmd.Example)
+	}
+
+	var seeAlso []string
+	if cmd.HasParent() {
+		seeAlso = append(seeAlso, reference(cmd.Parent()))
+	}
+	for _, child := range cmd.Commands() {
+		if child.IsAvailableCommand() || (!cmd.HasParent() && child.IsAdditionalHelpTopicCommand()) {
+			seeAlso = append(seeAlso, reference(child))
+		}
+	}
+	if len(seeAlso) > 0 {
+		b.WriteString(".SH SEE ALSO\n")
+		b.WriteString(strings.Join(seeAlso, ", ") + "\n")
+	}
+	return b.String()
+}
+
+// MARK: Helpers
+
+// pageName returns the name of a command's page, e.g. sandworm-config-set.
+func pageName(cmd *cobra.Command) string {
+	return strings.ReplaceAll(cmd.CommandPath(), " ", "-")
+}
+
+func section(cmd *cobra.Command) int {
+	if cmd.IsAdditionalHelpTopicCommand() {
+		return TopicSection
+	}
+	return CommandSection
+}
+
+// reference returns a reference to a command's page, e.g. sandworm-push(1).
+func reference(cmd *cobra.Command) string {
+	return fmt.Sprintf("\\fB%s\\fP(%d)", escape(pageName(cmd)), section(cmd))
+}
+
+// writeFlags writes a section listing flags, if there are visible ones.
+func writeFlags(b *strings.Builder, title string, flags *pflag.FlagSet) {
+	if !flags.HasAvailableFlags() {
+		return
+	}
+	fmt.Fprintf(b, ".SH %s\n", title)
+	flags.VisitAll(func(f *pflag.Flag) {
+		if f.Hidden {
+			return
+		}
+		b.WriteString(".TP\n")
+		if f.Shorthand != "" && f.ShorthandDeprecated == "" {
+			fmt.Fprintf(b, "\\fB\\-%s\\fP, ", escape(f.Shorthand))
+		}
+		fmt.Fprintf(b, "\\fB\\-\\-%s\\fP", escape(f.Name))
+		name, usage := pflag.UnquoteUsage(f)
+		if name != "" {
+			fmt.Fprintf(b, " \\fI%s\\fP", escape(name))
+		}
+		b.WriteString("\n")
+		switch f.DefValue {
+		case "", "false", "0", "[]":
+		default:
+			usage += fmt.Sprintf(" (default: %s)", f.DefValue)
+		}
+		b.WriteString(escape(usage) + "\n")
+	})
+}
+
+// writeText writes plain text as roff. Paragraphs are filled, while indented
+// lines (examples, lists, tables) are kept as they are.
+func writeText(b *strings.Builder, text string) {
+	var block, paragraph bool
+	for _, line := range strings.Split(strings.Trim(text, "\n"), "\n") {
+		line = strings.TrimRight(line, " \t")
+		switch {
+		case line == "":
+			if block {
+				b.WriteString(".fi\n")
+				block = false
+			}
+			if paragraph {
+				b.WriteString(".PP\n")
+				paragraph = false
+			}
+			continue
+		case line[0] == ' ' || line[0] == '\t':
+			if !block {
+				b.WriteString(".nf\n")
+				block = true
+			}
+		case block:
+			b.WriteString(".fi\n")
+			block = false
+		}
+		b.WriteString(escapeLine(line) + "\n")
+		paragraph = true
+	}
+	if block {
+		b.WriteString(".fi\n")
+	}
+}
+
+// escape escapes text for roff.
+func escape(s string) string {
+	return strings.NewReplacer(`\`, `\e`, "-", `\-`).Replace(s)
+}
+
+// escapeLine escapes a line of text for roff, including the characters that
+// start a request at the beginning of a line.
+func escapeLine(s string) string {
+	s = escape(s)
+	if strings.HasPrefix(s, ".") || strings.HasPrefix(s, "'") {
+		s = `\&` + s
+	}
+	return s
+}
+
+func titleCase(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
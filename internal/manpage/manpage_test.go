@@ -0,0 +1,78 @@
+package manpage
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func TestGenerate(t *testing.T) {
+	root := &cobra.Command{Use: "tool [directory]", Version: "1.2.3", Run: func(*cobra.Command, []string) {}}
+	root.PersistentFlags().StringP("output", "o", "", "Output file")
+	push := &cobra.Command{
+		Use:     "push",
+		Short:   "Push the bundle",
+		Long:    "Push the bundle.\n\n.dotfiles are included:\n\n  tool push --strict",
+		Example: `  tool push -o out.txt`,
+		Run:     func(*cobra.Command, []string) {},
+	}
+	push.Flags().Int("limit", 10, "Maximum `count` of files")
+	hidden := &cobra.Command{Use: "secret", Hidden: true, Run: func(*cobra.Command, []string) {}}
+	topic := &cobra.Command{Use: "syntax", Short: "Pattern syntax", Long: "Patterns are globs."}
+	root.AddCommand(push, hidden, topic)
+
+	dir := t.TempDir()
+	date := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	if err := Generate(root, dir, date); err != nil {
+		t.Fatalf("Failed to generate: %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	if strings.Join(names, " ") != "tool-push.1 tool-syntax.7 tool.1" {
+		t.Fatalf("Unexpected pages: %v", names)
+	}
+
+	page := readPage(t, filepath.Join(dir, "tool-push.1"))
+	for _, want := range []string{
+		`.TH "TOOL-PUSH" "1" "Jan 2026" "tool 1.2.3" "Tool Manual"`,
+		"tool\\-push \\- Push the bundle",
+		"\\fBtool push\\fP [flags]",
+		"\\&.dotfiles are included:",
+		".nf\n  tool push \\-\\-strict\n.fi",
+		"\\fB\\-\\-limit\\fP \\fIcount\\fP\nMaximum count of files (default: 10)",
+		".SH GLOBAL OPTIONS\n.TP\n\\fB\\-o\\fP, \\fB\\-\\-output\\fP \\fIstring\\fP",
+		".SH SEE ALSO\n\\fBtool\\fP(1)",
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("Expected the push page to contain %q, got:\n%s", want, page)
+		}
+	}
+
+	page = readPage(t, filepath.Join(dir, "tool.1"))
+	if !strings.Contains(page, "\\fBtool\\-push\\fP(1), \\fBtool\\-syntax\\fP(7)") || strings.Contains(page, "secret") {
+		t.Errorf("Expected the root page to reference the push and topic pages only, got:\n%s", page)
+	}
+	if page := readPage(t, filepath.Join(dir, "tool-syntax.7")); strings.Contains(page, "OPTIONS") || strings.Contains(page, "SYNOPSIS") {
+		t.Errorf("Expected the topic page to only have a description, got:\n%s", page)
+	}
+}
+
+func readPage(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
@@ -0,0 +1,46 @@
+package notify
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCommand(t *testing.T) {
+	t.Run("darwin", func(t *testing.T) {
+		name, args, err := command("darwin", "sandworm", `Pushed "app" \ ok`)
+		if err != nil {
+			t.Fatalf("command failed: %v", err)
+		}
+		want := []string{"-e", `display notification "Pushed \"app\" \\ ok" with title "sandworm"`}
+		if name != "osascript" || !reflect.DeepEqual(args, want) {
+			t.Errorf("Unexpected command: %s %q", name, args)
+		}
+	})
+
+	t.Run("linux", func(t *testing.T) {
+		name, args, err := command("linux", "sandworm", "Pushed")
+		if err != nil {
+			t.Fatalf("command failed: %v", err)
+		}
+		if name != "notify-send" || args[len(args)-2] != "sandworm" || args[len(args)-1] != "Pushed" {
+			t.Errorf("Unexpected command: %s %q", name, args)
+		}
+	})
+
+	t.Run("windows", func(t *testing.T) {
+		name, args, err := command("windows", "sandworm", "it's done")
+		if err != nil {
+			t.Fatalf("command failed: %v", err)
+		}
+		if name != "powershell" || !strings.Contains(args[len(args)-1], "'it''s done'") {
+			t.Errorf("Unexpected command: %s %q", name, args)
+		}
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		if _, _, err := command("plan9", "sandworm", "Pushed"); err == nil {
+			t.Error("Expected error for unsupported platform")
+		}
+	})
+}
@@ -0,0 +1,299 @@
+// Package picker implements a minimal terminal UI to hand-select files from
+// a tree with checkboxes.
+package picker
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/holonoms/sandworm/internal/style"
+	"golang.org/x/term"
+)
+
+// ErrCancelled is returned when the user quits the picker without confirming.
+var ErrCancelled = errors.New("selection cancelled")
+
+// node is a file or directory of the tree.
+type node struct {
+	name     string
+	path     string // Slash-separated path relative to the root
+	dir      bool
+	parent   *node
+	children []*node
+	checked  bool // Files only
+	expanded bool // Directories only
+}
+
+// Picker holds the state of the file tree and the cursor.
+type Picker struct {
+	root   *node
+	cursor int // Index in the visible lines
+	offset int // First visible line when scrolled
+}
+
+// New creates a picker for the given files (slash-separated, relative
+// paths). Files in selected start checked.
+func New(paths []string, selected map[string]bool) *Picker {
+	root := &node{dir: true, expanded: true}
+	dirs := map[string]*node{"": root}
+
+	sorted := append([]string(nil), paths...)
+	sort.Strings(sorted)
+	for _, p := range sorted {
+		parts := strings.Split(p, "/")
+		parent := root
+		for i, name := range parts[:len(parts)-1] {
+			dirPath := strings.Join(parts[:i+1], "/")
+			dir, ok := dirs[dirPath]
+			if !ok {
+				dir = &node{name: name, path: dirPath, dir: true, parent: parent}
+				parent.children = append(parent.children, dir)
+				dirs[dirPath] = dir
+			}
+			parent = dir
+		}
+		parent.children = append(parent.children, &node{
+			name: parts[len(parts)-1], path: p, parent: parent, checked: selected[p],
+		})
+	}
+	sortTree(root)
+
+	return &Picker{root: root}
+}
+
+// Selected returns the checked files, sorted.
+func (p *Picker) Selected() []string {
+	var selected []string
+	walk(p.root, func(n *node) {
+		if !n.dir && n.checked {
+			selected = append(selected, n.path)
+		}
+	})
+	sort.Strings(selected)
+	return selected
+}
+
+// HandleKey applies a key press. It reports whether the selection was
+// confirmed, and returns ErrCancelled if the user quit.
+func (p *Picker) HandleKey(key string) (bool, error) {
+	lines := p.visible()
+	switch key {
+	case "\r", "\n":
+		return true, nil
+	case "q", "\x03", "\x1b":
+		return false, ErrCancelled
+	}
+	if len(lines) == 0 {
+		return false, nil
+	}
+
+	switch key {
+	case "\x1b[A", "k":
+		if p.cursor > 0 {
+			p.cursor--
+		}
+	case "\x1b[B", "j":
+		if p.cursor < len(lines)-1 {
+			p.cursor++
+		}
+	case "\x1b[C", "l":
+		if n := lines[p.cursor]; n.dir {
+			n.expanded = true
+		}
+	case "\x1b[D", "h":
+		n := lines[p.cursor]
+		if n.dir && n.expanded {
+			n.expanded = false
+		} else if n.parent != nil && n.parent != p.root {
+			// Jump to the parent directory and collapse it
+			n.parent.expanded = false
+			p.cursor = indexOf(p.visible(), n.parent)
+		}
+	case " ":
+		toggle(lines[p.cursor])
+	case "a":
+		toggle(p.root)
+	}
+	return false, nil
+}
+
+// Render draws the visible part of the tree within the given number of lines.
+func (p *Picker) Render(w io.Writer, height int) {
+	lines := p.visible()
+
+	// Keep the cursor on screen, leaving room for the header and footer
+	rows := height - 3
+	if rows < 1 {
+		rows = 1
+	}
+	if p.cursor < p.offset {
+		p.offset = p.cursor
+	}
+	if p.cursor >= p.offset+rows {
+		p.offset = p.cursor - rows + 1
+	}
+
+	var b strings.Builder
+	b.WriteString("\x1b[H\x1b[2J")
+	b.WriteString(style.Header("Select files") + style.Dim("  (space: toggle, a: all, arrows: move/expand, enter: done, q: cancel)") + "\r\n\r\n")
+	for i := p.offset; i < len(lines) && i < p.offset+rows; i++ {
+		n := lines[i]
+		line := strings.Repeat("  ", depth(n)-1) + checkbox(n) + " " + label(n)
+		if i == p.cursor {
+			line = style.Info("> ") + line
+		} else {
+			line = "  " + line
+		}
+		b.WriteString(line + "\r\n")
+	}
+	fmt.Fprintf(&b, "%s", style.Dim(fmt.Sprintf("%d files selected", len(p.Selected()))))
+	_, _ = io.WriteString(w, b.String())
+}
+
+// Run shows the picker on the terminal and returns the selected files.
+func Run(paths []string, selected map[string]bool) ([]string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return nil, errors.New("an interactive terminal is required")
+	}
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up terminal: %w", err)
+	}
+	// Use the alternate screen and hide the cursor while picking
+	fmt.Print("\x1b[?1049h\x1b[?25l")
+	defer func() {
+		fmt.Print("\x1b[?25h\x1b[?1049l")
+		_ = term.Restore(fd, state)
+	}()
+
+	p := New(paths, selected)
+	buf := make([]byte, 16)
+	for {
+		_, height, err := term.GetSize(fd)
+		if err != nil {
+			height = 24
+		}
+		p.Render(os.Stdout, height)
+
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read input: %w", err)
+		}
+		done, err := p.HandleKey(string(buf[:n]))
+		if err != nil {
+			return nil, err
+		}
+		if done {
+			return p.Selected(), nil
+		}
+	}
+}
+
+// MARK: Helpers
+
+// visible returns the nodes shown, in order: the children of expanded
+// directories.
+func (p *Picker) visible() []*node {
+	var lines []*node
+	var add func(n *node)
+	add = func(n *node) {
+		for _, child := range n.children {
+			lines = append(lines, child)
+			if child.dir && child.expanded {
+				add(child)
+			}
+		}
+	}
+	add(p.root)
+	return lines
+}
+
+// toggle checks all files under n, or unchecks them if they all are.
+func toggle(n *node) {
+	checked, total := count(n)
+	value := checked < total
+	walk(n, func(f *node) {
+		if !f.dir {
+			f.checked = value
+		}
+	})
+}
+
+// count returns the number of checked files and total files under n.
+func count(n *node) (checked, total int) {
+	walk(n, func(f *node) {
+		if !f.dir {
+			total++
+			if f.checked {
+				checked++
+			}
+		}
+	})
+	return checked, total
+}
+
+func walk(n *node, fn func(*node)) {
+	fn(n)
+	for _, child := range n.children {
+		walk(child, fn)
+	}
+}
+
+func checkbox(n *node) string {
+	checked, total := count(n)
+	switch {
+	case checked == 0:
+		return "[ ]"
+	case checked == total:
+		return "[x]"
+	default:
+		return "[-]"
+	}
+}
+
+func label(n *node) string {
+	if !n.dir {
+		return n.name
+	}
+	marker := style.Symbol("▸", "+")
+	if n.expanded {
+		marker = style.Symbol("▾", "-")
+	}
+	return marker + " " + n.name + "/"
+}
+
+func depth(n *node) int {
+	d := 0
+	for ; n.parent != nil; n = n.parent {
+		d++
+	}
+	return d
+}
+
+func indexOf(lines []*node, n *node) int {
+	for i, line := range lines {
+		if line == n {
+			return i
+		}
+	}
+	return 0
+}
+
+// sortTree orders directories before files, then by name.
+func sortTree(n *node) {
+	sort.SliceStable(n.children, func(i, j int) bool {
+		a, b := n.children[i], n.children[j]
+		if a.dir != b.dir {
+			return a.dir
+		}
+		return a.name < b.name
+	})
+	for _, child := range n.children {
+		sortTree(child)
+	}
+}
@@ -0,0 +1,84 @@
+package picker
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPicker(t *testing.T) {
+	paths := []string{"main.go", "internal/a/a.go", "internal/b.go", "README.md"}
+	p := New(paths, map[string]bool{"main.go": true})
+
+	if got := p.Selected(); !reflect.DeepEqual(got, []string{"main.go"}) {
+		t.Errorf("Expected initial selection [main.go], got %v", got)
+	}
+
+	// Directories come first and start collapsed
+	var names []string
+	for _, n := range p.visible() {
+		names = append(names, n.name)
+	}
+	if want := []string{"internal", "README.md", "main.go"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("Expected visible %v, got %v", want, names)
+	}
+
+	keys := func(keys ...string) {
+		for _, key := range keys {
+			if _, err := p.HandleKey(key); err != nil {
+				t.Fatalf("HandleKey(%q) failed: %v", key, err)
+			}
+		}
+	}
+
+	t.Run("toggle directory", func(t *testing.T) {
+		keys(" ")
+		want := []string{"internal/a/a.go", "internal/b.go", "main.go"}
+		if got := p.Selected(); !reflect.DeepEqual(got, want) {
+			t.Errorf("Expected %v, got %v", want, got)
+		}
+	})
+
+	t.Run("expand and toggle file", func(t *testing.T) {
+		// internal/ > a/ > b.go
+		keys("\x1b[C", "\x1b[B", "\x1b[B", " ")
+		want := []string{"internal/a/a.go", "main.go"}
+		if got := p.Selected(); !reflect.DeepEqual(got, want) {
+			t.Errorf("Expected %v, got %v", want, got)
+		}
+
+		var b strings.Builder
+		p.Render(&b, 20)
+		if !strings.Contains(b.String(), "[-]") || !strings.Contains(b.String(), "2 files selected") {
+			t.Errorf("Unexpected render:\n%s", b.String())
+		}
+	})
+
+	t.Run("collapse to parent", func(t *testing.T) {
+		keys("\x1b[D")
+		if p.visible()[p.cursor].path != "internal" {
+			t.Errorf("Expected cursor on internal/, got %s", p.visible()[p.cursor].path)
+		}
+	})
+
+	t.Run("toggle all", func(t *testing.T) {
+		keys("a")
+		if got := p.Selected(); len(got) != len(paths) {
+			t.Errorf("Expected all files selected, got %v", got)
+		}
+		keys("a")
+		if got := p.Selected(); len(got) != 0 {
+			t.Errorf("Expected no files selected, got %v", got)
+		}
+	})
+
+	t.Run("confirm and cancel", func(t *testing.T) {
+		if done, err := p.HandleKey("\r"); !done || err != nil {
+			t.Errorf("Expected enter to confirm, got %v, %v", done, err)
+		}
+		if _, err := p.HandleKey("q"); !errors.Is(err, ErrCancelled) {
+			t.Errorf("Expected ErrCancelled, got %v", err)
+		}
+	})
+}
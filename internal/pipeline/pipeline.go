@@ -0,0 +1,373 @@
+// Package pipeline reads sandworm.yaml, which declares the bundles of a
+// repository as named pipelines: their inputs, transforms (generation
+// flags), output and backend (the Claude project they're pushed to).
+package pipeline
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/holonoms/sandworm/internal/processor"
+	"github.com/holonoms/sandworm/internal/util"
+	"gopkg.in/yaml.v3"
+)
+
+// File is the name of the pipelines file, at the repository root.
+const File = "sandworm.yaml"
+
+// Commands a pipeline can run.
+const (
+	Generate = "generate" // Write the bundle (default)
+	Push     = "push"     // Write the bundle and push it to the backend project
+)
+
+// reservedFlags are set with a pipeline's input, output and backend rather
+// than as transforms.
+var reservedFlags = []string{
+	"output", "o", "keep", "k", "org", "project", "include", "exclude",
+	"from", "target", "workspace", "package", "preset",
+}
+
+// forbiddenFlags can't be set from a pipelines file, which comes with the
+// repository: skipping confirmations and switching the config directory are
+// up to whoever runs sandworm.
+var forbiddenFlags = []string{"yes", "y", "config-dir"}
+
+// Pipeline is a bundle to generate (and push), as declared in the pipelines
+// file.
+type Pipeline struct {
+	Line        int            `yaml:"-"` // Line of the pipeline in the file
+	Name        string         `yaml:"name"`
+	Description string         `yaml:"description"`
+	Command     string         `yaml:"command"`    // generate (default) or push
+	Input       Input          `yaml:"input"`      // The files to bundle
+	Transforms  map[string]any `yaml:"transforms"` // Generation flags, e.g. header-style: short
+	Output      Output         `yaml:"output"`
+	Backend     Backend        `yaml:"backend"` // Push only
+}
+
+// Input selects the files of a pipeline.
+type Input struct {
+	Directory string   `yaml:"directory"` // Relative to the pipelines file; defaults to its directory
+	From      string   `yaml:"from"`      // Archive to read files from instead
+	Include   []string `yaml:"include"`
+	Exclude   []string `yaml:"exclude"`
+	Target    string   `yaml:"target"`
+	Workspace string   `yaml:"workspace"`
+	Package   string   `yaml:"package"`
+}
+
+// Output is where a pipeline writes its bundle.
+type Output struct {
+	File string `yaml:"file"` // Relative to the pipelines file
+	Keep bool   `yaml:"keep"` // Keep the file after pushing
+}
+
+// Backend is the Claude project a pipeline pushes to; the configured one if
+// empty.
+type Backend struct {
+	Organization string `yaml:"organization"`
+	Project      string `yaml:"project"`
+}
+
+// Error is a problem in a pipelines file.
+type Error struct {
+	Line int // 0 if unknown
+	Err  error
+}
+
+func (e *Error) Error() string {
+	if e.Line == 0 {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// yamlLineRE matches the line number yaml.v3 prefixes its errors with.
+var yamlLineRE = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+
+// config is the content of the pipelines file.
+type config struct {
+	Pipelines []Pipeline `yaml:"pipelines"`
+}
+
+// Read reads and validates a pipelines file. Problems are reported with
+// their position, as "file:line: problem".
+func Read(path string) ([]Pipeline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", filepath.Base(path), err)
+	}
+	pipelines, err := Parse(data)
+	var parseErr *Error
+	if errors.As(err, &parseErr) && parseErr.Line > 0 {
+		return nil, fmt.Errorf("%s:%d: %w", path, parseErr.Line, parseErr.Err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return pipelines, nil
+}
+
+// Parse parses and validates the content of a pipelines file. Unknown fields
+// are rejected (with the closest known one), so that typos don't silently
+// change a bundle.
+func Parse(data []byte) ([]Pipeline, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, yamlError(err)
+	}
+	if len(root.Content) == 0 {
+		return nil, errors.New("no pipelines defined")
+	}
+	doc := root.Content[0]
+	if err := checkFields(doc, reflect.TypeFor[config]()); err != nil {
+		return nil, err
+	}
+	var cfg config
+	if err := doc.Decode(&cfg); err != nil {
+		return nil, yamlError(err)
+	}
+	if len(cfg.Pipelines) == 0 {
+		return nil, &Error{Line: doc.Line, Err: errors.New("no pipelines defined")}
+	}
+	if items := pipelineNodes(doc); len(items) == len(cfg.Pipelines) {
+		for i, item := range items {
+			cfg.Pipelines[i].Line = item.Line
+		}
+	}
+
+	seen := make(map[string]bool)
+	for i, p := range cfg.Pipelines {
+		if p.Name == "" || strings.ContainsAny(p.Name, " \t") {
+			return nil, &Error{Line: p.Line, Err: fmt.Errorf("pipeline %d: expected a name without spaces", i+1)}
+		}
+		if seen[p.Name] {
+			return nil, &Error{Line: p.Line, Err: fmt.Errorf("duplicate pipeline '%s'", p.Name)}
+		}
+		seen[p.Name] = true
+		if err := p.validate(); err != nil {
+			return nil, &Error{Line: p.Line, Err: fmt.Errorf("pipeline '%s': %w", p.Name, err)}
+		}
+	}
+	return cfg.Pipelines, nil
+}
+
+// Find returns the pipelines with the given names, in the given order, or
+// all of them if no names are given.
+func Find(pipelines []Pipeline, names []string) ([]Pipeline, error) {
+	if len(names) == 0 {
+		return pipelines, nil
+	}
+	found := make([]Pipeline, 0, len(names))
+	for _, name := range names {
+		i := slices.IndexFunc(pipelines, func(p Pipeline) bool { return p.Name == name })
+		if i < 0 {
+			return nil, fmt.Errorf("unknown pipeline '%s'", name)
+		}
+		found = append(found, pipelines[i])
+	}
+	return found, nil
+}
+
+// Args returns the sandworm command line running a pipeline (without the
+// program name). Paths are resolved relative to dir, the directory of the
+// pipelines file.
+func (p Pipeline) Args(dir string) []string {
+	command := p.Command
+	if command == "" {
+		command = Generate
+	}
+	args := []string{command}
+
+	if p.Input.From != "" {
+		args = append(args, "--from="+resolve(dir, p.Input.From))
+	}
+	for _, pattern := range p.Input.Include {
+		args = append(args, "--include="+pattern)
+	}
+	for _, pattern := range p.Input.Exclude {
+		args = append(args, "--exclude="+pattern)
+	}
+	if p.Input.Target != "" {
+		args = append(args, "--target="+p.Input.Target)
+	}
+	if p.Input.Workspace != "" {
+		args = append(args, "--workspace="+p.Input.Workspace)
+	}
+	if p.Input.Package != "" {
+		args = append(args, "--package="+p.Input.Package)
+	}
+
+	flags := make([]string, 0, len(p.Transforms))
+	for flag := range p.Transforms {
+		flags = append(flags, flag)
+	}
+	sort.Strings(flags)
+	for _, flag := range flags {
+		switch value := p.Transforms[flag].(type) {
+		case []any:
+			for _, v := range value {
+				args = append(args, fmt.Sprintf("--%s=%v", flag, v))
+			}
+		case bool:
+			if value {
+				args = append(args, "--"+flag)
+			} else {
+				args = append(args, "--"+flag+"=false")
+			}
+		default:
+			args = append(args, fmt.Sprintf("--%s=%v", flag, value))
+		}
+	}
+
+	if p.Output.File != "" {
+		args = append(args, "--output="+p.OutputPath(dir))
+	}
+	if p.Output.Keep {
+		args = append(args, "--keep")
+	}
+	if p.Backend.Organization != "" {
+		args = append(args, "--org="+p.Backend.Organization)
+	}
+	if p.Backend.Project != "" {
+		args = append(args, "--project="+p.Backend.Project)
+	}
+
+	return append(args, resolve(dir, p.Input.Directory))
+}
+
+// OutputPath returns the path of a pipeline's output file, resolved relative
+// to dir, or "" for the command's default.
+func (p Pipeline) OutputPath(dir string) string {
+	if p.Output.File == "" {
+		return ""
+	}
+	return resolve(dir, p.Output.File)
+}
+
+// MARK: Helpers
+
+// validate checks the fields of a pipeline.
+func (p Pipeline) validate() error {
+	if p.Command != "" && p.Command != Generate && p.Command != Push {
+		return fmt.Errorf("invalid command '%s' (expected %s or %s)", p.Command, Generate, Push)
+	}
+	if p.Command != Push {
+		if p.Backend != (Backend{}) {
+			return errors.New("a backend requires 'command: push'")
+		}
+		if p.Output.Keep {
+			return errors.New("output.keep requires 'command: push'")
+		}
+	}
+	if err := processor.ValidatePatterns(append(slices.Clone(p.Input.Include), p.Input.Exclude...)); err != nil {
+		return err
+	}
+	for flag, value := range p.Transforms {
+		if strings.HasPrefix(flag, "-") {
+			return fmt.Errorf("transform '%s': expected a flag name without dashes", flag)
+		}
+		if slices.Contains(reservedFlags, flag) {
+			return fmt.Errorf("transform '%s' is set with the input, output or backend of the pipeline", flag)
+		}
+		if slices.Contains(forbiddenFlags, flag) {
+			return fmt.Errorf("transform '%s' can't be set in %s", flag, File)
+		}
+		switch value := value.(type) {
+		case nil, map[string]any:
+			return fmt.Errorf("transform '%s': expected a value or a list of values", flag)
+		case []any:
+			for _, v := range value {
+				switch v.(type) {
+				case nil, map[string]any, []any:
+					return fmt.Errorf("transform '%s': expected a list of values", flag)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// checkFields rejects the keys of a node that aren't fields of t, suggesting
+// the closest one, recursively.
+func checkFields(node *yaml.Node, t reflect.Type) error {
+	switch {
+	case t.Kind() == reflect.Slice && node.Kind == yaml.SequenceNode:
+		for _, item := range node.Content {
+			if err := checkFields(item, t.Elem()); err != nil {
+				return err
+			}
+		}
+	case t.Kind() == reflect.Struct && node.Kind == yaml.MappingNode:
+		fields := make(map[string]reflect.Type)
+		var names []string
+		for i := range t.NumField() {
+			name, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+			if name != "" && name != "-" {
+				fields[name] = t.Field(i).Type
+				names = append(names, name)
+			}
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			fieldType, ok := fields[key.Value]
+			if !ok {
+				problem := fmt.Sprintf("unknown field '%s'", key.Value)
+				if closest := util.Closest(key.Value, names); closest != "" {
+					problem += fmt.Sprintf(" (did you mean '%s'?)", closest)
+				} else {
+					problem += fmt.Sprintf(" (expected one of: %s)", strings.Join(names, ", "))
+				}
+				return &Error{Line: key.Line, Err: errors.New(problem)}
+			}
+			if err := checkFields(value, fieldType); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// pipelineNodes returns the nodes of the pipelines of a document.
+func pipelineNodes(doc *yaml.Node) []*yaml.Node {
+	for i := 0; i+1 < len(doc.Content); i += 2 {
+		if doc.Content[i].Value == "pipelines" && doc.Content[i+1].Kind == yaml.SequenceNode {
+			return doc.Content[i+1].Content
+		}
+	}
+	return nil
+}
+
+// yamlError converts a yaml.v3 error to an Error with its line, e.g. a type
+// mismatch ("line 5: cannot unmarshal !!seq into string").
+func yamlError(err error) error {
+	message := err.Error()
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) && len(typeErr.Errors) > 0 {
+		message = typeErr.Errors[0]
+	}
+	if m := yamlLineRE.FindStringSubmatch(message); m != nil {
+		line, _ := strconv.Atoi(m[1])
+		return &Error{Line: line, Err: errors.New(m[2])}
+	}
+	return &Error{Err: errors.New(strings.TrimPrefix(message, "yaml: "))}
+}
+
+// resolve resolves a path relative to dir; empty paths resolve to dir.
+func resolve(dir, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
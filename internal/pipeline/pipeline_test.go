@@ -0,0 +1,142 @@
+package pipeline
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const pipelinesFile = `pipelines:
+  - name: api
+    description: API code
+    command: push
+    input:
+      directory: services/api
+      include: ["!vendor/keep"]
+      exclude: ["*_test.go", "testdata/"]
+    transforms:
+      symbol-index: true
+      line-numbers: false
+      header-style: short
+      image-path: [/app, /etc]
+    output:
+      file: bundles/api.txt
+      keep: true
+    backend:
+      organization: Acme
+      project: API
+  - name: docs
+`
+
+func TestParse(t *testing.T) {
+	pipelines, err := Parse([]byte(pipelinesFile))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(pipelines) != 2 || pipelines[0].Name != "api" || pipelines[1].Name != "docs" {
+		t.Fatalf("Unexpected pipelines: %+v", pipelines)
+	}
+
+	dir := filepath.Join("repo", "root")
+	got := pipelines[0].Args(dir)
+	want := []string{
+		"push",
+		"--include=!vendor/keep",
+		"--exclude=*_test.go",
+		"--exclude=testdata/",
+		"--header-style=short",
+		"--image-path=/app",
+		"--image-path=/etc",
+		"--line-numbers=false",
+		"--symbol-index",
+		"--output=" + filepath.Join(dir, "bundles", "api.txt"),
+		"--keep",
+		"--org=Acme",
+		"--project=API",
+		filepath.Join(dir, "services", "api"),
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Unexpected args:\n%q\nwant:\n%q", got, want)
+	}
+	if got := pipelines[1].Args(dir); !slices.Equal(got, []string{"generate", dir}) {
+		t.Errorf("Unexpected default args: %q", got)
+	}
+
+	found, err := Find(pipelines, []string{"docs", "api"})
+	if err != nil || len(found) != 2 || found[0].Name != "docs" {
+		t.Errorf("Unexpected pipelines found: %+v, %v", found, err)
+	}
+	if _, err := Find(pipelines, []string{"web"}); err == nil {
+		t.Error("Expected an error for an unknown pipeline")
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := map[string]struct {
+		data string
+		want string
+	}{
+		"empty":           {"", "no pipelines defined"},
+		"syntax error":    {"pipelines:\n  - name: a\n    command: \"push\n", "line 3: found unexpected end of stream"},
+		"unknown field":   {"pipelines:\n  - name: a\n    outputs: {}\n", "line 3: unknown field 'outputs' (did you mean 'output'?)"},
+		"unknown nested":  {"pipelines:\n  - name: a\n    input:\n      dir: src\n", "line 4: unknown field 'dir' (expected one of: directory, from"},
+		"wrong type":      {"pipelines:\n  - name: a\n    input:\n      exclude: vendor/\n", "line 4: cannot unmarshal !!str `vendor/` into []string"},
+		"missing name":    {"pipelines:\n  - command: push\n", "line 2: pipeline 1: expected a name"},
+		"duplicate name":  {"pipelines:\n  - name: a\n  - name: a\n", "line 3: duplicate pipeline 'a'"},
+		"invalid command": {"pipelines:\n  - name: a\n    command: purge\n", "invalid command 'purge'"},
+		"bad glob": {
+			"pipelines:\n  - name: a\n    input:\n      exclude: [\"*.[ch\"]\n",
+			`line 2: pipeline 'a': invalid pattern "*.[ch": unterminated bracket expression`,
+		},
+		"generate backend": {
+			"pipelines:\n  - name: a\n    backend:\n      project: P\n",
+			"a backend requires 'command: push'",
+		},
+		"reserved transform": {
+			"pipelines:\n  - name: a\n    transforms:\n      output: x.txt\n",
+			"transform 'output' is set with the input, output or backend",
+		},
+		"confirmation transform": {
+			"pipelines:\n  - name: a\n    transforms:\n      yes: true\n",
+			"transform 'yes' can't be set in sandworm.yaml",
+		},
+		"short confirmation transform": {
+			"pipelines:\n  - name: a\n    transforms:\n      y: true\n",
+			"transform 'y' can't be set in sandworm.yaml",
+		},
+		"config-dir transform": {
+			"pipelines:\n  - name: a\n    transforms:\n      config-dir: /tmp/x\n",
+			"transform 'config-dir' can't be set in sandworm.yaml",
+		},
+		"dashed transform": {
+			"pipelines:\n  - name: a\n    transforms:\n      --plain: true\n",
+			"expected a flag name without dashes",
+		},
+		"nested transform": {
+			"pipelines:\n  - name: a\n    transforms:\n      sanitize: {mode: strip}\n",
+			"expected a value or a list of values",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected an error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestReadReportsPosition(t *testing.T) {
+	path := filepath.Join(t.TempDir(), File)
+	if err := os.WriteFile(path, []byte("pipelines:\n  - name: a\n    transfroms: {}\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	_, err := Read(path)
+	want := path + ":3: unknown field 'transfroms' (did you mean 'transforms'?)"
+	if err == nil || err.Error() != want {
+		t.Errorf("Unexpected error:\n%v\nwant:\n%s", err, want)
+	}
+}
@@ -0,0 +1,151 @@
+// Package policy reads the safety policies that restrict what sandworm may
+// push and where, so that organizations can roll it out with guard rails: a
+// system-wide policy installed by administrators, an extra one set through
+// the environment, and one committed to the repository. All apply when
+// present.
+package policy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/holonoms/sandworm/internal/util"
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the name of the repository policy, at the project root.
+const FileName = ".sandworm-policy.yaml"
+
+// Env is the path of an extra policy, applying on top of the system one.
+const Env = "SANDWORM_POLICY"
+
+// Policy restricts pushes. Empty fields don't restrict anything.
+type Policy struct {
+	Path string `yaml:"-"` // File the policy was read from
+
+	Organizations     []string `yaml:"organizations"`       // Organizations (IDs or names) pushes may target
+	Projects          []string `yaml:"projects"`            // Projects (IDs or names) pushes may target
+	RequireSecretScan bool     `yaml:"require_secret_scan"` // Mask secrets on push, whatever the config
+	MaxBundleSize     string   `yaml:"max_bundle_size"`     // Size of the largest bundle that may be pushed, e.g. "5MB"
+
+	maxBundleSize int64
+}
+
+// ViolationError is returned when a push would break a policy.
+type ViolationError struct {
+	Path   string // Policy file
+	Reason string
+}
+
+func (e *ViolationError) Error() string {
+	return fmt.Sprintf("%s (policy %s)", e.Reason, e.Path)
+}
+
+// SystemPath returns the path of the system policy: policy.yaml in
+// /etc/sandworm, /Library/Application Support/sandworm (macOS) or
+// %ProgramData%\sandworm (Windows).
+func SystemPath() string {
+	switch runtime.GOOS {
+	case "darwin":
+		return "/Library/Application Support/sandworm/policy.yaml"
+	case "windows":
+		dir := os.Getenv("ProgramData")
+		if dir == "" {
+			dir = `C:\ProgramData`
+		}
+		return filepath.Join(dir, "sandworm", "policy.yaml")
+	}
+	return "/etc/sandworm/policy.yaml"
+}
+
+// systemPath returns the path of the system policy, replaced in tests.
+var systemPath = SystemPath
+
+// Load reads the system policy, the policy set through Env and the policy of
+// the repository in dir, those of them that exist. The system policy always
+// applies: Env can only add restrictions. A policy set through Env must
+// exist.
+func Load(dir string) ([]*Policy, error) {
+	var policies []*Policy
+	for _, source := range []struct {
+		path     string
+		required bool
+	}{
+		{systemPath(), false},
+		{os.Getenv(Env), true},
+		{filepath.Join(dir, FileName), false},
+	} {
+		if source.path == "" {
+			continue
+		}
+		p, err := Read(source.path)
+		if errors.Is(err, fs.ErrNotExist) && !source.required {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, p)
+	}
+	return policies, nil
+}
+
+// Read reads a policy file. Unknown keys are errors, so that a typo can't
+// silently weaken a policy.
+func Read(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy: %w", err)
+	}
+	p := &Policy{Path: path}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(p); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid policy %s: %w", path, err)
+	}
+	if p.MaxBundleSize != "" {
+		if p.maxBundleSize, err = util.ParseSize(p.MaxBundleSize); err != nil {
+			return nil, fmt.Errorf("invalid policy %s: max_bundle_size: %w", path, err)
+		}
+	}
+	return p, nil
+}
+
+// CheckTarget returns a ViolationError if the policy doesn't allow pushing to
+// a project, given the IDs and names of the project and its organization.
+func (p *Policy) CheckTarget(orgID, orgName, projectID, projectName string) error {
+	if len(p.Organizations) > 0 && !matches(p.Organizations, orgID, orgName) {
+		return &ViolationError{p.Path, fmt.Sprintf("pushing to organization '%s' isn't allowed", orgName)}
+	}
+	if len(p.Projects) > 0 && !matches(p.Projects, projectID, projectName) {
+		return &ViolationError{p.Path, fmt.Sprintf("pushing to project '%s' isn't allowed", projectName)}
+	}
+	return nil
+}
+
+// CheckSize returns a ViolationError if a bundle of the given size is larger
+// than the policy allows.
+func (p *Policy) CheckSize(size int64) error {
+	if p.maxBundleSize > 0 && size > p.maxBundleSize {
+		return &ViolationError{p.Path, fmt.Sprintf("bundle is %s, over the %s allowed", util.FormatSize(size), util.FormatSize(p.maxBundleSize))}
+	}
+	return nil
+}
+
+// MARK: Helpers
+
+// matches reports whether an allowlist contains an ID, or a name (compared
+// case-insensitively).
+func matches(allowed []string, id, name string) bool {
+	return slices.ContainsFunc(allowed, func(entry string) bool {
+		return entry == id || (name != "" && strings.EqualFold(entry, name))
+	})
+}
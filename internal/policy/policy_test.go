@@ -0,0 +1,89 @@
+package policy
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	installed := filepath.Join(t.TempDir(), "installed.yaml")
+	systemPath = func() string { return installed }
+	t.Cleanup(func() { systemPath = SystemPath })
+	system := filepath.Join(t.TempDir(), "policy.yaml")
+	t.Setenv(Env, system)
+
+	if _, err := Load(dir); err == nil {
+		t.Error("Expected an error for a missing policy set through " + Env)
+	}
+
+	writePolicy(t, system, "organizations: [org-1]\nmax_bundle_size: 1KB\n")
+	writePolicy(t, filepath.Join(dir, FileName), "projects: [Backend]\nrequire_secret_scan: true\n")
+	policies, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Failed to load: %v", err)
+	}
+	if len(policies) != 2 || policies[0].Path != system || !policies[1].RequireSecretScan {
+		t.Fatalf("Expected the system and repository policies, got %+v", policies)
+	}
+
+	// The installed policy still applies with one set through Env
+	writePolicy(t, installed, "projects: [Frontend]\n")
+	if policies, err := Load(dir); err != nil || len(policies) != 3 || policies[0].Path != installed {
+		t.Fatalf("Expected the installed, extra and repository policies, got %+v (%v)", policies, err)
+	}
+	writePolicy(t, installed, "")
+
+	writePolicy(t, system, "max_bundle_sise: 1KB\n")
+	if _, err := Load(dir); err == nil || !strings.Contains(err.Error(), "max_bundle_sise") {
+		t.Errorf("Expected an error for an unknown key, got %v", err)
+	}
+	writePolicy(t, system, "max_bundle_size: lots\n")
+	if _, err := Load(dir); err == nil {
+		t.Error("Expected an error for an invalid size")
+	}
+}
+
+func TestCheck(t *testing.T) {
+	p := &Policy{Path: "policy.yaml", Organizations: []string{"org-1"}, Projects: []string{"backend", "p-2"}, maxBundleSize: 1024}
+
+	tests := []struct {
+		name                                   string
+		orgID, orgName, projectID, projectName string
+		allowed                                bool
+	}{
+		{"allowed by name", "org-1", "Acme", "p-1", "Backend", true},
+		{"allowed by ID", "org-1", "Acme", "p-2", "Frontend", true},
+		{"other organization", "org-2", "Personal", "p-1", "Backend", false},
+		{"other project", "org-1", "Acme", "p-3", "Sandbox", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := p.CheckTarget(tt.orgID, tt.orgName, tt.projectID, tt.projectName)
+			var violation *ViolationError
+			if tt.allowed != (err == nil) || (err != nil && !errors.As(err, &violation)) {
+				t.Errorf("Expected allowed = %v, got %v", tt.allowed, err)
+			}
+		})
+	}
+
+	if err := p.CheckSize(1024); err != nil {
+		t.Errorf("Expected a bundle at the limit to be allowed, got %v", err)
+	}
+	if err := p.CheckSize(1025); err == nil || !strings.Contains(err.Error(), "policy.yaml") {
+		t.Errorf("Expected a violation naming the policy, got %v", err)
+	}
+	if err := (&Policy{}).CheckTarget("org", "Org", "p", "P"); err != nil {
+		t.Errorf("Expected an empty policy to allow anything, got %v", err)
+	}
+}
+
+func writePolicy(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
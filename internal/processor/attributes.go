@@ -0,0 +1,69 @@
+package processor
+
+import (
+	"bytes"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/format/gitattributes"
+)
+
+// linguistAttributes are the .gitattributes attributes that, when set on a path,
+// exclude it from the output. These mirror how GitHub hides generated and
+// vendored code in diffs and language statistics.
+var linguistAttributes = []string{"linguist-generated", "linguist-vendored"}
+
+// newAttributesMatcher builds a matcher from .gitattributes files of fsys,
+// given as slash-separated paths. Files are ordered from shallowest to
+// deepest, so nested files take priority.
+func newAttributesMatcher(fsys fs.FS, files []string) (gitattributes.Matcher, error) {
+	relPaths := append([]string(nil), files...)
+	sort.Slice(relPaths, func(i, j int) bool {
+		di, dj := strings.Count(relPaths[i], "/"), strings.Count(relPaths[j], "/")
+		if di != dj {
+			return di < dj
+		}
+		return relPaths[i] < relPaths[j]
+	})
+
+	var stack []gitattributes.MatchAttribute
+	for _, relPath := range relPaths {
+		data, err := fs.ReadFile(fsys, relPath)
+		if err != nil {
+			// Unreadable attribute files are ignored, like unreadable files in the walk
+			continue
+		}
+
+		var domain []string
+		if dir := path.Dir(relPath); dir != "." {
+			domain = strings.Split(dir, "/")
+		}
+		attrs, err := gitattributes.ReadAttributes(bytes.NewReader(data), domain, relPath == ".gitattributes")
+		if err != nil {
+			return nil, err
+		}
+		stack = append(stack, attrs...)
+	}
+
+	return gitattributes.NewMatcher(stack), nil
+}
+
+// isLinguistExcluded reports whether a path is marked as generated or vendored.
+func isLinguistExcluded(m gitattributes.Matcher, relPath string) bool {
+	parts := strings.Split(relPath, "/")
+	for _, name := range linguistAttributes {
+		// Query one attribute at a time: the matcher stops at the first
+		// (highest priority) match once all requested attributes are found.
+		results, _ := m.Match(parts, []string{name})
+		attr, ok := results[name]
+		if !ok {
+			continue
+		}
+		if attr.IsSet() || (attr.IsValueSet() && attr.Value() != "false") {
+			return true
+		}
+	}
+	return false
+}
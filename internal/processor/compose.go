@@ -0,0 +1,312 @@
+package processor
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/holonoms/sandworm/internal/filetree"
+	"github.com/holonoms/sandworm/internal/manifest"
+)
+
+// Composition is a bundle composed from generated bundles (see Compose).
+type Composition struct {
+	Content    []byte
+	Offsets    []int64  // Offsets of the file sections in Content (see FileOffsets)
+	Files      int      // Files with contents
+	Duplicates []string // Files found in several bundles, kept from the first
+}
+
+// bundle holds the sections of a generated file.
+type bundle struct {
+	ascii    bool     // The structure is drawn with ASCII characters
+	paths    []string // Files listed in the structure
+	symbols  []string // Lines of the symbol index
+	graph    []string // Lines of the dependency graph
+	files    []bundleFile
+	remote   string // Contents of the remote sources section
+	schemas  string // Contents of the database schemas section
+	skipped  string // Contents of the skipped files section
+	manifest manifest.Manifest
+}
+
+// bundleFile is the section of a file in a bundle: its header and contents.
+type bundleFile struct {
+	path string
+	text string
+}
+
+// Compose merges bundles generated by Process (e.g. a project and its
+// vendored docs, generated once) into a single one, as if generated
+// together: a single structure listing the files of all of them, merged
+// symbol indexes, dependency graphs and checksum manifests, and the file
+// contents of each bundle in order. A file found in several bundles is kept
+// from the first one.
+//
+// File headers are recognized in any of the built-in styles, or with
+// headerTemplate, a custom template.
+func Compose(bundles [][]byte, headerTemplate string) (*Composition, error) {
+	headers := headerPatterns(headerTemplate)
+
+	var merged bundle
+	seen := map[string]bool{}
+	result := &Composition{}
+	for i, content := range bundles {
+		b, err := parseBundle(string(content), headers)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse bundle %d: %w", i+1, err)
+		}
+		if i == 0 {
+			merged.ascii = b.ascii
+		}
+		merged.paths = append(merged.paths, b.paths...)
+		merged.symbols = appendNew(merged.symbols, b.symbols...)
+		merged.graph = appendNew(merged.graph, b.graph...)
+		for _, file := range b.files {
+			if seen[file.path] {
+				result.Duplicates = append(result.Duplicates, file.path)
+				continue
+			}
+			seen[file.path] = true
+			merged.files = append(merged.files, file)
+		}
+		merged.remote += b.remote
+		merged.schemas += b.schemas
+		merged.skipped += b.skipped
+		for filePath, hash := range b.manifest {
+			if merged.manifest == nil {
+				merged.manifest = manifest.Manifest{}
+			}
+			if _, ok := merged.manifest[filePath]; !ok {
+				merged.manifest[filePath] = hash
+			}
+		}
+	}
+	sort.Strings(merged.graph)
+
+	var out bytes.Buffer
+	tree := filetree.New(merged.paths)
+	if merged.ascii {
+		tree.SetCharset(filetree.ASCIICharset)
+	}
+	out.WriteString(heading(structureTitle) + "\n\n" + tree.String(""))
+	if len(merged.symbols) > 0 {
+		out.WriteString("\n\n" + heading(symbolIndexTitle) + "\n\n" + strings.Join(merged.symbols, "\n"))
+	}
+	if len(merged.graph) > 0 {
+		out.WriteString("\n\n" + heading(dependencyGraphTitle) + "\n\n" + strings.Join(merged.graph, "\n"))
+	}
+	out.WriteString("\n\n" + heading(contentsTitle) + "\n\n")
+	for _, file := range merged.files {
+		result.Offsets = append(result.Offsets, int64(out.Len()))
+		out.WriteString(file.text)
+	}
+	result.Files = len(merged.files)
+	if merged.remote != "" {
+		out.WriteString("\n\n" + heading(remoteSourcesTitle) + "\n\n" + merged.remote)
+	}
+	if merged.schemas != "" {
+		out.WriteString("\n\n" + heading(databaseSchemasTitle) + "\n" + merged.schemas)
+	}
+	if merged.skipped != "" {
+		out.WriteString("\n\n" + heading(skippedFilesTitle) + "\n\n" + merged.skipped)
+	}
+	if merged.manifest != nil {
+		if err := merged.manifest.Write(&out); err != nil {
+			return nil, err
+		}
+	}
+	result.Content = out.Bytes()
+	return result, nil
+}
+
+// parseBundle splits a generated file into its sections.
+func parseBundle(content string, headers []*regexp.Regexp) (*bundle, error) {
+	rest, ok := strings.CutPrefix(content, heading(structureTitle)+"\n\n")
+	if !ok {
+		return nil, errors.New("not a file generated by sandworm (no project structure)")
+	}
+	preamble, rest, ok := strings.Cut(rest, "\n\n"+heading(contentsTitle)+"\n\n")
+	if !ok {
+		return nil, errors.New("no file contents section")
+	}
+
+	// Sections before the file contents can't be confused with file contents
+	b := &bundle{}
+	preamble, graph, _ := strings.Cut(preamble, "\n\n"+heading(dependencyGraphTitle)+"\n\n")
+	preamble, symbols, _ := strings.Cut(preamble, "\n\n"+heading(symbolIndexTitle)+"\n\n")
+	b.paths, b.ascii = parseTree(preamble)
+	b.symbols = nonEmptyLines(symbols)
+	b.graph = nonEmptyLines(graph)
+
+	// Sections after it are found from the end, as file contents may contain
+	// their headings too (see manifest.Read)
+	if i := strings.LastIndex(rest, "\n\n"+manifest.Heading+"\n"); i >= 0 {
+		m, err := manifest.Read(strings.NewReader(rest[i:]))
+		if err != nil {
+			return nil, err
+		}
+		b.manifest, rest = m, rest[:i]
+	}
+	if i := strings.LastIndex(rest, "\n\n"+heading(skippedFilesTitle)+"\n\n"); i >= 0 {
+		b.skipped, rest = rest[i+len("\n\n"+heading(skippedFilesTitle)+"\n\n"):], rest[:i]
+	}
+	if i := strings.LastIndex(rest, "\n\n"+heading(databaseSchemasTitle)+"\n"); i >= 0 {
+		b.schemas, rest = rest[i+len("\n\n"+heading(databaseSchemasTitle)+"\n"):], rest[:i]
+	}
+	if i := strings.LastIndex(rest, "\n\n"+heading(remoteSourcesTitle)+"\n\n"); i >= 0 {
+		b.remote, rest = rest[i+len("\n\n"+heading(remoteSourcesTitle)+"\n\n"):], rest[:i]
+	}
+	b.files = parseFiles(rest, b.paths, headers)
+	if len(b.files) == 0 && strings.TrimSpace(rest) != "" {
+		return nil, errors.New("no recognized file headers (generated with another processor.file_header?)")
+	}
+	return b, nil
+}
+
+// parseTree extracts the file paths from a structure drawn by filetree, and
+// whether it's drawn with ASCII characters.
+func parseTree(tree string) ([]string, bool) {
+	charsets := []filetree.Charset{filetree.UnicodeCharset, filetree.ASCIICharset}
+	var paths, dirs []string
+	ascii := false
+	for _, line := range strings.Split(tree, "\n")[1:] { // The first line is the root
+		depth := 0
+		for {
+			prefix := ""
+			for _, charset := range charsets {
+				for _, indent := range []string{charset.Pipe, charset.Blank} {
+					if strings.HasPrefix(line, indent) {
+						prefix = indent
+					}
+				}
+			}
+			if prefix == "" {
+				break
+			}
+			line = line[len(prefix):]
+			depth++
+		}
+
+		var name string
+		for i, charset := range charsets {
+			if rest, ok := strings.CutPrefix(line, charset.Branch); ok {
+				name, ascii = rest, i == 1
+			} else if rest, ok := strings.CutPrefix(line, charset.Last); ok {
+				name, ascii = rest, i == 1
+			}
+		}
+		if name == "" || depth > len(dirs) {
+			continue
+		}
+		dirs = dirs[:depth]
+		if dir, ok := strings.CutSuffix(name, "/"); ok {
+			dirs = append(dirs, dir)
+			continue
+		}
+		paths = append(paths, path.Join(append(slices.Clone(dirs), name)...))
+	}
+	return paths, ascii
+}
+
+// parseFiles splits the file contents section into the sections of each
+// file. Only headers naming a file of the structure are considered, so that
+// header-like lines within contents aren't mistaken for headers.
+func parseFiles(contents string, paths []string, headers []*regexp.Regexp) []bundleFile {
+	known := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		known[p] = true
+	}
+
+	type match struct {
+		start int
+		path  string
+	}
+	var matches []match
+	for _, header := range headers {
+		for _, m := range header.FindAllStringSubmatchIndex(contents, -1) {
+			filePath := contents[m[2]:m[3]]
+			if !known[filePath] {
+				// Metadata follows the path in parentheses, unless placed by the template
+				i := strings.LastIndex(filePath, " (")
+				if i < 0 || !strings.HasSuffix(filePath, ")") || !known[filePath[:i]] {
+					continue
+				}
+				filePath = filePath[:i]
+			}
+			matches = append(matches, match{start: m[0], path: filePath})
+		}
+		if len(matches) > 0 {
+			break // Bundles use a single header style
+		}
+	}
+
+	files := make([]bundleFile, len(matches))
+	for i, m := range matches {
+		end := len(contents)
+		if i+1 < len(matches) {
+			end = matches[i+1].start
+		}
+		files[i] = bundleFile{path: m.path, text: contents[m.start:end]}
+	}
+	return files
+}
+
+// headerPatterns returns the patterns matching file headers, capturing their
+// path: headers of the custom template, if any, then of the built-in styles.
+func headerPatterns(custom string) []*regexp.Regexp {
+	templates := []string{headerTemplates[HeaderFull], headerTemplates[HeaderShort], headerTemplates[HeaderMinimal]}
+	if custom != "" && !slices.Contains(templates, custom) {
+		templates = append([]string{custom}, templates...)
+	}
+
+	var patterns []*regexp.Regexp
+	for _, template := range templates {
+		template = strings.ReplaceAll(template, `\n`, "\n")
+		var pattern strings.Builder
+		pattern.WriteString("(?m)^")
+		for i, part := range strings.Split(template, headerPathPlaceholder) {
+			if i > 0 {
+				pattern.WriteString("(.+?)")
+			}
+			metaParts := strings.Split(part, headerMetaPlaceholder)
+			for j, metaPart := range metaParts {
+				if j > 0 {
+					pattern.WriteString(".*?")
+				}
+				pattern.WriteString(regexp.QuoteMeta(metaPart))
+			}
+		}
+		pattern.WriteString("\n")
+		if re, err := regexp.Compile(pattern.String()); err == nil && re.NumSubexp() > 0 {
+			patterns = append(patterns, re)
+		}
+	}
+	return patterns
+}
+
+// nonEmptyLines returns the non-empty lines of a section.
+func nonEmptyLines(section string) []string {
+	var lines []string
+	for _, line := range strings.Split(section, "\n") {
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// appendNew appends the lines that aren't in lines yet.
+func appendNew(lines []string, added ...string) []string {
+	for _, line := range added {
+		if !slices.Contains(lines, line) {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
@@ -0,0 +1,91 @@
+package processor
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// generateBundle generates the bundle of a project made of the given files.
+func generateBundle(t *testing.T, files map[string]string, opts SandwormOptions) []byte {
+	t.Helper()
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, files)
+	outputFile := filepath.Join(t.TempDir(), "out.txt")
+	p, err := NewWithOptions(tmpDir, outputFile, "", opts)
+	if err != nil {
+		t.Fatalf("Failed to create processor: %v", err)
+	}
+	if _, err := p.Process(); err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	content, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	return content
+}
+
+func TestCompose(t *testing.T) {
+	project := map[string]string{
+		"main.go":         "package main\n\nfunc Main() {}\n",
+		"pkg/util.go":     "package pkg\n\n// A header-like line naming another file:\n// === FILE: docs/intro.md ===\n",
+		"docs/shared.txt": "shared\n",
+	}
+	vendored := map[string]string{
+		"docs/intro.md":   "# Intro\n",
+		"docs/shared.txt": "other copy\n",
+	}
+	opts := SandwormOptions{SymbolIndex: true, ChecksumManifest: true}
+
+	t.Run("single bundle round trip", func(t *testing.T) {
+		content := generateBundle(t, project, opts)
+		composition, err := Compose([][]byte{content}, "")
+		if err != nil {
+			t.Fatalf("Compose failed: %v", err)
+		}
+		if string(composition.Content) != string(content) {
+			t.Errorf("Expected the bundle unchanged, got:\n%s", composition.Content)
+		}
+		if composition.Files != 3 {
+			t.Errorf("Expected 3 files, got %d", composition.Files)
+		}
+	})
+
+	t.Run("mixed header styles", func(t *testing.T) {
+		first := generateBundle(t, project, SandwormOptions{FileHeader: headerTemplates[HeaderShort], ChecksumManifest: true})
+		second := generateBundle(t, vendored, SandwormOptions{FileHeader: headerTemplates[HeaderMinimal], ChecksumManifest: true})
+		composition, err := Compose([][]byte{first, second}, "")
+		if err != nil {
+			t.Fatalf("Compose failed: %v", err)
+		}
+		content := string(composition.Content)
+
+		if !reflect.DeepEqual(composition.Duplicates, []string{"docs/shared.txt"}) {
+			t.Errorf("Expected docs/shared.txt to be a duplicate, got %v", composition.Duplicates)
+		}
+		if strings.Contains(content, "other copy") {
+			t.Error("Expected the duplicate to be kept from the first bundle")
+		}
+		if strings.Count(content, heading(structureTitle)) != 1 || !strings.Contains(content, "│   ├── intro.md") {
+			t.Errorf("Expected a single structure listing all files, got:\n%s", content)
+		}
+		if composition.Files != 4 || len(composition.Offsets) != 4 {
+			t.Fatalf("Expected 4 files, got %d (offsets %v)", composition.Files, composition.Offsets)
+		}
+		if got := content[composition.Offsets[3]:]; !strings.HasPrefix(got, ">>> docs/intro.md\n# Intro\n") {
+			t.Errorf("Expected the last offset at docs/intro.md, got %q", got[:min(len(got), 30)])
+		}
+		if !strings.Contains(content, "  docs/intro.md\n") || !strings.Contains(content, "  main.go\n") {
+			t.Errorf("Expected a merged checksum manifest, got:\n%s", content)
+		}
+	})
+
+	t.Run("not a bundle", func(t *testing.T) {
+		if _, err := Compose([][]byte{[]byte("hello")}, ""); err == nil {
+			t.Error("Expected an error for a file not generated by sandworm")
+		}
+	})
+}
@@ -0,0 +1,71 @@
+package processor
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/holonoms/sandworm/internal/convert"
+)
+
+// convertHeadSize is the number of bytes used to sniff a file's MIME type.
+const convertHeadSize = 512
+
+// convertContent renders a file's content as text with the matching
+// converter, if any, returning the converter's name (empty if the content was
+// kept as is).
+func (p *Processor) convertContent(ctx context.Context, file FileInfo, content []byte) ([]byte, string, error) {
+	head := content
+	if len(head) > convertHeadSize {
+		head = head[:convertHeadSize]
+	}
+	c := p.converters.Find(file.RelativePath, head)
+	if c == nil {
+		return content, "", nil
+	}
+
+	converted, err := c.Convert(ctx, convert.File{
+		Path:    file.RelativePath,
+		AbsPath: file.AbsolutePath,
+		Content: content,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	if bytes.Equal(converted, content) {
+		return content, "", nil
+	}
+	return converted, c.Name(), nil
+}
+
+// conversionDependencies returns the other files the conversion of a file
+// reads, if any (see convert.Dependent).
+func (p *Processor) conversionDependencies(file FileInfo, content []byte) []string {
+	head := content
+	if len(head) > convertHeadSize {
+		head = head[:convertHeadSize]
+	}
+	c, ok := p.converters.Find(file.RelativePath, head).(convert.Dependent)
+	if !ok {
+		return nil
+	}
+	return c.Dependencies(convert.File{Path: file.RelativePath, AbsPath: file.AbsolutePath, Content: content})
+}
+
+// writeDatabaseSchemas writes the schema of each configured database.
+func (p *Processor) writeDatabaseSchemas(ctx context.Context, w *bufio.Writer) error {
+	if _, err := w.WriteString("\n\n" + heading(databaseSchemasTitle) + "\n"); err != nil {
+		return err
+	}
+	for _, db := range p.databases {
+		schema, err := db.Schema(ctx, p.rootDir)
+		if err != nil {
+			return fmt.Errorf("failed to dump schema of %s: %w", db.Name, err)
+		}
+		if _, err := fmt.Fprintf(w, "\n-- Database: %s\n%s", db.Name, schema); err != nil {
+			return err
+		}
+	}
+	return nil
+}
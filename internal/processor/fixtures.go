@@ -0,0 +1,68 @@
+package processor
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// DefaultFixtureMaxLines is the line count above which test fixtures are
+// trimmed to a sample.
+const DefaultFixtureMaxLines = 2000
+
+// fixtureSampleLines is the number of lines kept from trimmed fixtures.
+const fixtureSampleLines = 100
+
+// fixtureDirs hold test fixtures and golden files, at any depth.
+var fixtureDirs = map[string]bool{
+	"testdata":      true,
+	"fixtures":      true,
+	"__fixtures__":  true,
+	"__snapshots__": true,
+	"golden":        true,
+}
+
+// fixtureExts are the extensions of snapshot and golden files, wherever
+// they are.
+var fixtureExts = map[string]bool{
+	".snap":   true,
+	".golden": true,
+}
+
+// isFixture reports whether a (slash-separated, relative) path is a test
+// fixture or golden file.
+func isFixture(relPath string) bool {
+	if fixtureExts[strings.ToLower(path.Ext(relPath))] {
+		return true
+	}
+	dirs := strings.Split(path.Dir(relPath), "/")
+	for _, dir := range dirs {
+		if fixtureDirs[dir] {
+			return true
+		}
+	}
+	return false
+}
+
+// trimFixture keeps the first lines of a fixture longer than maxLines,
+// followed by a note of what was left out. It returns the content unchanged
+// (and 0) if it's short enough.
+func trimFixture(content []byte, maxLines int) ([]byte, int) {
+	lines := bytes.Count(content, []byte("\n"))
+	if len(content) > 0 && content[len(content)-1] != '\n' {
+		lines++
+	}
+	if maxLines <= 0 || lines <= maxLines {
+		return content, 0
+	}
+
+	sample := min(fixtureSampleLines, maxLines)
+	cut := 0
+	for range sample {
+		cut += bytes.IndexByte(content[cut:], '\n') + 1
+	}
+	trimmed := bytes.Clone(content[:cut])
+	trimmed = fmt.Appendf(trimmed, "[... %d more lines omitted by sandworm; set processor.fixture_max_lines to 0 to include whole fixtures]\n", lines-sample)
+	return trimmed, lines
+}
@@ -0,0 +1,60 @@
+package processor
+
+import (
+	"fmt"
+	"strings"
+)
+
+// File header styles
+const (
+	HeaderFull    = "full"    // 80-char separators around "FILE: <path>" (default)
+	HeaderShort   = "short"   // "=== FILE: <path> ===" on a single line
+	HeaderMinimal = "minimal" // ">>> <path>", the cheapest in tokens
+)
+
+// HeaderStyles lists the valid file header styles.
+var HeaderStyles = []string{HeaderFull, HeaderShort, HeaderMinimal}
+
+// Header template placeholders
+const (
+	headerPathPlaceholder = "{path}" // The file path
+	headerMetaPlaceholder = "{meta}" // The file metadata, if enabled
+)
+
+// headerTemplates maps header styles to their templates.
+var headerTemplates = map[string]string{
+	HeaderFull:    separator + "\nFILE: {path}\n" + separator,
+	HeaderShort:   "=== FILE: {path} ===",
+	HeaderMinimal: ">>> {path}",
+}
+
+// HeaderTemplate returns the template of a header style.
+func HeaderTemplate(style string) (string, error) {
+	template, ok := headerTemplates[style]
+	if !ok {
+		return "", fmt.Errorf("invalid header style %q (must be one of %s)", style, strings.Join(HeaderStyles, ", "))
+	}
+	return template, nil
+}
+
+// ValidateHeaderTemplate checks that a custom header template references the
+// file path.
+func ValidateHeaderTemplate(template string) error {
+	if !strings.Contains(template, headerPathPlaceholder) {
+		return fmt.Errorf("header template must contain %s", headerPathPlaceholder)
+	}
+	return nil
+}
+
+// formatHeader renders a header template for a file. Escaped newlines ("\n")
+// in the template are expanded so multi-line templates fit in config values.
+// Metadata, if any, replaces {meta}, or follows the path in parentheses when
+// the template doesn't place it.
+func formatHeader(template, relPath, meta string) string {
+	template = strings.ReplaceAll(template, `\n`, "\n")
+	if meta != "" && !strings.Contains(template, headerMetaPlaceholder) {
+		relPath += " (" + meta + ")"
+	}
+	template = strings.ReplaceAll(template, headerMetaPlaceholder, meta)
+	return strings.ReplaceAll(template, headerPathPlaceholder, relPath)
+}
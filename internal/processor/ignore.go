@@ -0,0 +1,335 @@
+package processor
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ignorePattern is a single gitignore pattern, matched with git's semantics.
+type ignorePattern struct {
+	negate  bool
+	dirOnly bool           // Trailing slash: only matches directories
+	name    bool           // No slash: matched against the last path component only
+	re      *regexp.Regexp // Matches a (slash-separated) path relative to the ignore file's directory
+}
+
+// ignoreMatcher matches paths against gitignore patterns like git does:
+//
+//   - The last matching pattern wins, with patterns of nested ignore files
+//     taking precedence over those of their parent directories, and
+//     overrides (e.g. --exclude/--include) over all of them.
+//   - A path is excluded when any of its parent directories is, since git
+//     doesn't descend into excluded directories: files within them can't be
+//     re-included by a negated pattern.
+//
+// It implements go-git's gitignore.Matcher interface.
+type ignoreMatcher struct {
+	patterns  []ignorePattern            // Root patterns, in increasing priority
+	overrides []ignorePattern            // Patterns taking precedence over all ignore files, in increasing priority
+	fsys      fs.FS                      // Files of the root, with nested ignore files
+	nested    string                     // Name of nested ignore files (e.g. .gitignore); none if empty
+	loaded    map[string][]ignorePattern // Nested patterns, by (slash-separated, relative) directory
+	dirs      map[string]bool            // Exclusion of directories, by (slash-separated, relative) path
+}
+
+// newIgnoreMatcher creates a matcher of root patterns (in increasing
+// priority), also reading the patterns of nested ignore files with a name in
+// each directory of fsys, if name isn't empty.
+func newIgnoreMatcher(patterns []ignorePattern, fsys fs.FS, name string) *ignoreMatcher {
+	return &ignoreMatcher{
+		patterns: patterns,
+		fsys:     fsys,
+		nested:   name,
+		loaded:   make(map[string][]ignorePattern),
+		dirs:     make(map[string]bool),
+	}
+}
+
+// Match reports whether a path (split in components) is excluded.
+func (m *ignoreMatcher) Match(parts []string, isDir bool) bool {
+	for i := 1; i < len(parts); i++ {
+		dir := strings.Join(parts[:i], "/")
+		excluded, ok := m.dirs[dir]
+		if !ok {
+			excluded = m.matchPath(parts[:i], true)
+			m.dirs[dir] = excluded
+		}
+		if excluded {
+			return true
+		}
+	}
+	return m.matchPath(parts, isDir)
+}
+
+// matchPath matches a path against the patterns, ignoring its parents'
+// exclusion.
+func (m *ignoreMatcher) matchPath(parts []string, isDir bool) bool {
+	if result, ok := matchPatterns(m.overrides, parts, isDir); ok {
+		return result
+	}
+	// Nested ignore files, from the deepest directory containing the path
+	if m.nested != "" {
+		for i := len(parts) - 1; i > 0; i-- {
+			dir := strings.Join(parts[:i], "/")
+			if result, ok := matchPatterns(m.nestedPatterns(dir), parts[i:], isDir); ok {
+				return result
+			}
+		}
+	}
+	result, _ := matchPatterns(m.patterns, parts, isDir)
+	return result
+}
+
+// nestedPatterns returns the patterns of the ignore file of a directory.
+func (m *ignoreMatcher) nestedPatterns(dir string) []ignorePattern {
+	patterns, ok := m.loaded[dir]
+	if !ok {
+		data, err := fs.ReadFile(m.fsys, path.Join(dir, m.nested))
+		if err == nil {
+			patterns = parseIgnorePatterns(string(data))
+		}
+		m.loaded[dir] = patterns
+	}
+	return patterns
+}
+
+// matchPatterns returns whether the last pattern matching a path excludes it,
+// and whether any pattern matched.
+func matchPatterns(patterns []ignorePattern, parts []string, isDir bool) (bool, bool) {
+	for i := len(patterns) - 1; i >= 0; i-- {
+		if patterns[i].match(parts, isDir) {
+			return !patterns[i].negate, true
+		}
+	}
+	return false, false
+}
+
+// match reports whether the pattern matches a path (relative to the
+// pattern's ignore file).
+func (p ignorePattern) match(parts []string, isDir bool) bool {
+	if p.dirOnly && !isDir {
+		return false
+	}
+	if p.name {
+		return p.re.MatchString(parts[len(parts)-1])
+	}
+	return p.re.MatchString(strings.Join(parts, "/"))
+}
+
+// parseIgnorePatterns parses the patterns of an ignore file, skipping blank
+// lines and comments.
+func parseIgnorePatterns(data string) []ignorePattern {
+	var patterns []ignorePattern
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		if pattern, ok := parseIgnorePattern(scanner.Text()); ok {
+			patterns = append(patterns, pattern)
+		}
+	}
+	return patterns
+}
+
+// parseIgnorePattern parses a gitignore pattern. It returns false for blank
+// lines and comments.
+func parseIgnorePattern(line string) (ignorePattern, bool) {
+	line = strings.TrimSuffix(line, "\r")
+	// Trailing spaces are ignored unless escaped
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, `\ `) {
+		line = line[:len(line)-1]
+	}
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignorePattern{}, false
+	}
+
+	var p ignorePattern
+	if strings.HasPrefix(line, "!") {
+		p.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		p.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return ignorePattern{}, false
+	}
+
+	// Patterns without a slash (but a trailing one) match names at any depth;
+	// others are relative to the ignore file's directory.
+	p.name = !strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+	re, err := regexp.Compile("^" + globRegexp(line) + "$")
+	if err != nil {
+		// Invalid bracket expressions (e.g. [z-a]) are taken literally
+		re = regexp.MustCompile("^" + regexp.QuoteMeta(line) + "$")
+	}
+	p.re = re
+	return p, true
+}
+
+// globRegexp converts a gitignore glob to a regular expression: * and ?
+// don't match slashes, while ** matches any number of directories when it's
+// a whole path component.
+func globRegexp(glob string) string {
+	var b strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "**") && (i == 0 || glob[i-1] == '/'):
+			rest := glob[i+2:]
+			switch {
+			case rest == "":
+				b.WriteString(".*") // Everything within (or everything, alone)
+				i++
+			case strings.HasPrefix(rest, "/"):
+				b.WriteString("(?:.*/)?") // Zero or more directories
+				i += 2
+			default:
+				b.WriteString("[^/]*") // Not a whole component: like *
+				i++
+			}
+		case c == '*':
+			for i+1 < len(glob) && glob[i+1] == '*' {
+				i++
+			}
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '[':
+			if class, n := globClass(glob[i:]); n > 0 {
+				b.WriteString(class)
+				i += n - 1
+			} else {
+				b.WriteString(`\[`)
+			}
+		case c == '\\' && i+1 < len(glob):
+			i++
+			b.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return b.String()
+}
+
+// globClass converts a bracket expression at the start of a glob, e.g.
+// "[!a-z]", returning it and its length (0 if it isn't terminated).
+func globClass(glob string) (string, int) {
+	var b strings.Builder
+	b.WriteString("[")
+	i := 1
+	if i < len(glob) && (glob[i] == '!' || glob[i] == '^') {
+		b.WriteString("^/") // Negated classes don't match slashes either
+		i++
+	}
+	for start := i; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case c == ']' && i > start:
+			b.WriteString("]")
+			return b.String(), i + 1
+		case c == '\\' && i+1 < len(glob):
+			i++
+			b.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		case c == '[' || c == ']' || c == '^' || c == '\\':
+			b.WriteString(`\` + string(c))
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", 0
+}
+
+// ValidatePatterns checks gitignore-style patterns for bracket expressions
+// that are unterminated or invalid (e.g. "[z-a]"): they are taken literally
+// when matching, so such patterns silently match nothing.
+func ValidatePatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		glob := strings.TrimPrefix(strings.TrimPrefix(pattern, "!"), "/")
+		for i := 0; i < len(glob); i++ {
+			switch glob[i] {
+			case '\\':
+				i++
+			case '[':
+				class, n := globClass(glob[i:])
+				if n == 0 {
+					return fmt.Errorf("invalid pattern %q: unterminated bracket expression", pattern)
+				}
+				if _, err := regexp.Compile(class); err != nil {
+					return fmt.Errorf("invalid pattern %q: invalid bracket expression %s", pattern, glob[i:i+n])
+				}
+				i += n - 1
+			}
+		}
+	}
+	return nil
+}
+
+// generatedPatterns match files sandworm itself leaves in projects, such as
+// the temporary output of an interrupted push, which are never included.
+var generatedPatterns = []string{
+	".sandworm-*.txt",
+}
+
+// outputPattern returns a pattern matching exactly an output file (relative
+// to the working directory), or false if it isn't within rootDir. With parts,
+// it also matches the parts the output file is split in, e.g. out-1.txt for
+// out.txt.
+func outputPattern(rootDir, outputFile string, parts bool) (ignorePattern, bool) {
+	if outputFile == "" {
+		return ignorePattern{}, false
+	}
+	root, err := resolvePath(rootDir)
+	if err != nil {
+		return ignorePattern{}, false
+	}
+	dir, err := resolvePath(filepath.Dir(outputFile))
+	if err != nil {
+		return ignorePattern{}, false
+	}
+	rel, err := filepath.Rel(root, filepath.Join(dir, filepath.Base(outputFile)))
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return ignorePattern{}, false
+	}
+	rel = filepath.ToSlash(rel)
+	expr := regexp.QuoteMeta(rel)
+	if parts {
+		ext := path.Ext(rel)
+		expr = regexp.QuoteMeta(strings.TrimSuffix(rel, ext)) + "(-[0-9]+)?" + regexp.QuoteMeta(ext)
+	}
+	return ignorePattern{re: regexp.MustCompile("^" + expr + "$")}, true
+}
+
+// resolvePath returns the absolute path of a file, with symbolic links
+// resolved when it exists.
+func resolvePath(name string) (string, error) {
+	abs, err := filepath.Abs(name)
+	if err != nil {
+		return "", err
+	}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		return resolved, nil
+	}
+	return abs, nil
+}
+
+// isStandardIgnoreFile reports whether an ignore file is one sandworm looks
+// for in each directory (as opposed to a custom --ignore file).
+func isStandardIgnoreFile(name string) bool {
+	base := path.Base(filepath.ToSlash(name))
+	return base == ".gitignore" || base == ".sandwormignore"
+}
+
+// sameDir reports whether two paths are the same directory.
+func sameDir(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	return errA == nil && errB == nil && absA == absB
+}
@@ -0,0 +1,244 @@
+package processor
+
+import (
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// ignoreTree is the project the conformance cases run against.
+var ignoreTree = []string{
+	"README.md",
+	"notes.txt",
+	"build/keep.txt",
+	"build/out.bin",
+	"docs/a.md",
+	"docs/deep/b.md",
+	"docs/deep/c.txt",
+	"logs/.gitkeep",
+	"logs/today.log",
+	"src/main.go",
+	"src/gen/keep.go",
+	"src/gen/types.go",
+	"vendor/lib/x.go",
+	"vendor/mylib/y.go",
+	"a b.txt",
+	"#hash.txt",
+	"!bang.txt",
+}
+
+// ignoreCases are gitignore conformance cases: the ignore files to create
+// (by path) and the files of ignoreTree expected to be ignored.
+var ignoreCases = []struct {
+	name    string
+	files   map[string]string
+	ignored []string
+}{
+	{
+		name:    "negation re-includes a file",
+		files:   map[string]string{".gitignore": "*.txt\n!notes.txt\n"},
+		ignored: []string{"build/keep.txt", "docs/deep/c.txt", "a b.txt", "#hash.txt", "!bang.txt"},
+	},
+	{
+		name:    "excluded directory can't be re-entered",
+		files:   map[string]string{".gitignore": "build/\n!build/keep.txt\n"},
+		ignored: []string{"build/keep.txt", "build/out.bin"},
+	},
+	{
+		name:    "directory contents can be re-included",
+		files:   map[string]string{".gitignore": "build/*\n!build/keep.txt\n"},
+		ignored: []string{"build/out.bin"},
+	},
+	{
+		name:    "ignore everything but go files",
+		files:   map[string]string{".gitignore": "*\n!*/\n!*.go\n"},
+		ignored: []string{"README.md", "notes.txt", "build/keep.txt", "build/out.bin", "docs/a.md", "docs/deep/b.md", "docs/deep/c.txt", "logs/.gitkeep", "logs/today.log", "a b.txt", "#hash.txt", "!bang.txt"},
+	},
+	{
+		name:    "trailing slash matches directories only",
+		files:   map[string]string{".gitignore": "notes.txt/\nlogs/\n"},
+		ignored: []string{"logs/.gitkeep", "logs/today.log"},
+	},
+	{
+		name:    "anchored and unanchored patterns",
+		files:   map[string]string{".gitignore": "/keep.txt\ngen\n"},
+		ignored: []string{"src/gen/keep.go", "src/gen/types.go"},
+	},
+	{
+		name:    "middle slash anchors the pattern",
+		files:   map[string]string{".gitignore": "deep/b.md\ndocs/*.md\n"},
+		ignored: []string{"docs/a.md"},
+	},
+	{
+		name:    "leading double star",
+		files:   map[string]string{".gitignore": "**/deep\n**/keep.go\n"},
+		ignored: []string{"docs/deep/b.md", "docs/deep/c.txt", "src/gen/keep.go"},
+	},
+	{
+		name:    "trailing double star",
+		files:   map[string]string{".gitignore": "docs/**\n!docs/**/*.md\n"},
+		ignored: []string{"docs/deep/b.md", "docs/deep/c.txt"},
+	},
+	{
+		name:    "middle double star",
+		files:   map[string]string{".gitignore": "src/**/*.go\n!src/**/main.go\n"},
+		ignored: []string{"src/gen/keep.go", "src/gen/types.go"},
+	},
+	{
+		name:    "keep files in ignored directory contents",
+		files:   map[string]string{".gitignore": "logs/*\n!logs/.gitkeep\n"},
+		ignored: []string{"logs/today.log"},
+	},
+	{
+		name:    "re-included directory",
+		files:   map[string]string{".gitignore": "vendor/*\n!vendor/mylib/\n"},
+		ignored: []string{"vendor/lib/x.go"},
+	},
+	{
+		name:    "character classes and wildcards",
+		files:   map[string]string{".gitignore": "[a-c]*.md\n!d?cs\nout.[!t]*\n"},
+		ignored: []string{"docs/a.md", "docs/deep/b.md", "build/out.bin"},
+	},
+	{
+		name:    "escapes and spaces",
+		files:   map[string]string{".gitignore": "\\#hash.txt\n\\!bang.txt\na\\ b.txt\nnotes.txt   \n"},
+		ignored: []string{"#hash.txt", "!bang.txt", "a b.txt", "notes.txt"},
+	},
+	{
+		name: "nested ignore files take precedence",
+		files: map[string]string{
+			".gitignore":         "*.go\n",
+			"src/gen/.gitignore": "!*.go\ntypes.go\n",
+			"docs/.gitignore":    "/a.md\ndeep/*.txt\n",
+		},
+		ignored: []string{"src/main.go", "src/gen/types.go", "vendor/lib/x.go", "vendor/mylib/y.go", "docs/a.md", "docs/deep/c.txt"},
+	},
+	{
+		name: "nested files can't re-include from excluded directories",
+		files: map[string]string{
+			".gitignore":     "src/\n",
+			"src/.gitignore": "!main.go\n",
+		},
+		ignored: []string{"src/main.go", "src/gen/keep.go", "src/gen/types.go"},
+	},
+}
+
+func TestIgnoreMatcher(t *testing.T) {
+	for _, tt := range ignoreCases {
+		t.Run(tt.name, func(t *testing.T) {
+			root := writeIgnoreCase(t, tt.files)
+			if got := ignoredFiles(root); !slices.Equal(got, sorted(tt.ignored)) {
+				t.Errorf("Expected ignored files %q, got %q", sorted(tt.ignored), got)
+			}
+		})
+	}
+}
+
+// TestIgnoreMatcherGit checks the conformance cases against git itself.
+func TestIgnoreMatcherGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+
+	for _, tt := range ignoreCases {
+		t.Run(tt.name, func(t *testing.T) {
+			root := writeIgnoreCase(t, tt.files)
+			if out, err := exec.Command("git", "-C", root, "init", "-q").CombinedOutput(); err != nil {
+				t.Fatalf("git init failed: %v: %s", err, out)
+			}
+			out, err := exec.Command("git", "-C", root, "ls-files", "--others", "--exclude-standard", "-z").Output()
+			if err != nil {
+				t.Fatalf("git ls-files failed: %v", err)
+			}
+			included := strings.Split(strings.TrimRight(string(out), "\x00"), "\x00")
+
+			var ignored []string
+			for _, file := range ignoreTree {
+				if !slices.Contains(included, file) {
+					ignored = append(ignored, file)
+				}
+			}
+			if got := ignoredFiles(root); !slices.Equal(got, sorted(ignored)) {
+				t.Errorf("Expected git's ignored files %q, got %q", sorted(ignored), got)
+			}
+		})
+	}
+}
+
+func TestGlobRegexp(t *testing.T) {
+	tests := []struct {
+		glob     string
+		expected string
+	}{
+		{glob: "*.go", expected: `[^/]*\.go`},
+		{glob: "a?c", expected: `a[^/]c`},
+		{glob: "**/foo", expected: `(?:.*/)?foo`},
+		{glob: "foo/**", expected: `foo/.*`},
+		{glob: "a/**/b", expected: `a/(?:.*/)?b`},
+		{glob: "a**b", expected: `a[^/]*b`},
+		{glob: "[!a-c]x", expected: `[^/a-c]x`},
+		{glob: "[abc", expected: `\[abc`},
+		{glob: `\*`, expected: `\*`},
+	}
+
+	for _, tt := range tests {
+		if got := globRegexp(tt.glob); got != tt.expected {
+			t.Errorf("globRegexp(%q) = %q, expected %q", tt.glob, got, tt.expected)
+		}
+	}
+}
+
+// writeIgnoreCase creates ignoreTree and the ignore files of a case.
+func writeIgnoreCase(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	files = maps.Clone(files)
+	for _, name := range ignoreTree {
+		files[name] = name
+	}
+	writeFiles(t, root, files)
+	return root
+}
+
+// ignoredFiles returns the (sorted) files of ignoreTree ignored under root,
+// with the root .gitignore and those of nested directories.
+func ignoredFiles(root string) []string {
+	data, _ := os.ReadFile(filepath.Join(root, ".gitignore"))
+	m := newIgnoreMatcher(parseIgnorePatterns(string(data)), os.DirFS(root), ".gitignore")
+
+	var ignored []string
+	for _, file := range ignoreTree {
+		if m.Match(strings.Split(file, "/"), false) {
+			ignored = append(ignored, file)
+		}
+	}
+	return sorted(ignored)
+}
+
+func sorted(s []string) []string {
+	s = slices.Clone(s)
+	slices.Sort(s)
+	return s
+}
+
+func TestValidatePatterns(t *testing.T) {
+	valid := []string{"*.go", "!vendor/keep/", "/build", "src/**/*.[ch]", `\[literal`, "[!a-z]*"}
+	if err := ValidatePatterns(valid); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	for pattern, want := range map[string]string{
+		"*.[ch":    "unterminated bracket expression",
+		"!a/[z-a]": "invalid bracket expression [z-a]",
+	} {
+		err := ValidatePatterns([]string{"*.go", pattern})
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("ValidatePatterns(%q) = %v, want an error containing %q", pattern, err, want)
+		}
+	}
+}
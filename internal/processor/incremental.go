@@ -0,0 +1,271 @@
+package processor
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/holonoms/sandworm/internal/injection"
+	"github.com/holonoms/sandworm/internal/manifest"
+	"github.com/holonoms/sandworm/internal/scrub"
+)
+
+// Files of the incremental cache (see SandwormOptions.IncrementalDir).
+const (
+	incrementalBundle = "bundle.txt"
+	incrementalIndex  = "index.json"
+)
+
+// sectionIndex locates the section of each file in a cached output, with
+// what's needed to reuse it: the state of the file it was rendered from, and
+// what processing it reported.
+type sectionIndex struct {
+	Options string                    `json:"options"` // Fingerprint of the options shaping file sections
+	Started time.Time                 `json:"started"` // Files modified since may have changed while being read
+	Bundle  string                    `json:"bundle"`  // SHA-256 of the cached output
+	Files   map[string]indexedSection `json:"files"`
+}
+
+// indexedSection is the section of a file in a cached output.
+type indexedSection struct {
+	Size      int64                `json:"size"`
+	ModTime   int64                `json:"mtime"`            // In nanoseconds
+	Commit    string               `json:"commit,omitempty"` // Last commit, when shown in headers
+	Offset    int64                `json:"offset"`
+	Length    int64                `json:"length"`
+	Checksum  string               `json:"checksum"`
+	Scrubbed  scrub.Counts         `json:"scrubbed,omitempty"`
+	Sanitized int                  `json:"sanitized,omitempty"`
+	Findings  []injection.Finding  `json:"findings,omitempty"`
+	Deps      map[string]fileState `json:"deps,omitempty"` // Other files its conversion read (see convert.Dependent)
+}
+
+// fileState is the size and modification time of a file, to tell whether it
+// changed. Missing files have a size of -1.
+type fileState struct {
+	Size    int64 `json:"size"`
+	ModTime int64 `json:"mtime"` // In nanoseconds
+}
+
+// statFile returns the state of a file.
+func statFile(name string) fileState {
+	info, err := os.Stat(name)
+	if err != nil {
+		return fileState{Size: -1}
+	}
+	return fileState{Size: info.Size(), ModTime: info.ModTime().UnixNano()}
+}
+
+// incremental splices the sections of unchanged files from the last output,
+// rather than reading and processing them again. Files are unchanged when
+// their size and modification time (and last commit, if shown) match, like
+// git's index does.
+type incremental struct {
+	dir     string
+	options string
+	last    *sectionIndex // Index of bundle; nil if there's no usable one
+	bundle  []byte
+	next    *sectionIndex // Index of the output being written
+	spliced int
+}
+
+// newIncremental returns the incremental state of a processor caching its
+// output in dir, given the fingerprint of its options.
+func newIncremental(dir string, options SandwormOptions) *incremental {
+	fingerprint, _ := json.Marshal(struct {
+		LineNumbers, Metadata, NormalizeEOL, TrimWhitespace bool
+		Header, Sanitize                                    string
+		ScrubPII, ScrubSecrets, InjectionScan               bool
+		ScrubPattern                                        string
+		ScrubPaths, InjectionPaths                          []string
+		Converters                                          string
+		FixtureMaxLines                                     int
+	}{
+		options.PrintLineNumbers, options.FileMetadata, options.NormalizeEOL, options.TrimWhitespace,
+		options.FileHeader, options.Sanitize,
+		options.ScrubPII, options.ScrubSecrets, options.InjectionScan,
+		options.ScrubPattern,
+		options.ScrubPaths, options.InjectionPaths,
+		options.Converters.Fingerprint(),
+		options.FixtureMaxLines,
+	})
+	sum := sha256.Sum256(fingerprint)
+	return &incremental{dir: dir, options: hex.EncodeToString(sum[:])}
+}
+
+// Spliced returns the number of unchanged files whose sections were reused
+// from the last output by the last Process call (see
+// SandwormOptions.IncrementalDir).
+func (p *Processor) Spliced() int {
+	if p.incremental == nil {
+		return 0
+	}
+	return p.incremental.spliced
+}
+
+// MARK: Helpers
+
+// reportMarks are the lengths of the processing reports before a file is
+// processed, to find what it reported.
+type reportMarks struct {
+	scrubbed, sanitized, flagged int
+}
+
+func (p *Processor) reportMarks() reportMarks {
+	return reportMarks{len(p.scrubReport), len(p.sanitizeReport), len(p.injectionReport)}
+}
+
+// spliceSection writes the section of an unchanged file from the last
+// output, restoring its checksum and reports. It returns false if the file
+// changed (or there's no last output).
+func (p *Processor) spliceSection(w *bufio.Writer, relPath string, info os.FileInfo, commit string, checksums manifest.Manifest) bool {
+	inc := p.incremental
+	section, ok := inc.reuse(relPath, info, commit)
+	if !ok {
+		return false
+	}
+
+	start := p.written.n + int64(w.Buffered())
+	if _, err := w.Write(inc.bundle[section.Offset : section.Offset+section.Length]); err != nil {
+		return false
+	}
+	p.fileOffsets = append(p.fileOffsets, start)
+	checksums[relPath] = section.Checksum
+	if len(section.Scrubbed) > 0 {
+		p.scrubReport = append(p.scrubReport, ScrubResult{Path: relPath, Counts: section.Scrubbed})
+	}
+	if section.Sanitized > 0 {
+		p.sanitizeReport = append(p.sanitizeReport, SanitizeResult{Path: relPath, Count: section.Sanitized})
+	}
+	if len(section.Findings) > 0 {
+		p.injectionReport = append(p.injectionReport, InjectionResult{Path: relPath, Findings: section.Findings})
+	}
+
+	section.Offset = start
+	inc.next.Files[relPath] = section
+	inc.spliced++
+	return true
+}
+
+// recordSection indexes the section of a file just written, from its last
+// file offset to the current position, with the other files its conversion
+// read.
+func (p *Processor) recordSection(w *bufio.Writer, relPath string, info os.FileInfo, commit, checksum string, deps []string, marks reportMarks) {
+	start := p.fileOffsets[len(p.fileOffsets)-1]
+	section := indexedSection{
+		Size:     info.Size(),
+		ModTime:  info.ModTime().UnixNano(),
+		Commit:   commit,
+		Offset:   start,
+		Length:   p.written.n + int64(w.Buffered()) - start,
+		Checksum: checksum,
+	}
+	for _, dep := range deps {
+		if section.Deps == nil {
+			section.Deps = map[string]fileState{}
+		}
+		section.Deps[dep] = statFile(dep)
+	}
+	if len(p.scrubReport) > marks.scrubbed {
+		section.Scrubbed = p.scrubReport[marks.scrubbed].Counts
+	}
+	if len(p.sanitizeReport) > marks.sanitized {
+		section.Sanitized = p.sanitizeReport[marks.sanitized].Count
+	}
+	if len(p.injectionReport) > marks.flagged {
+		section.Findings = p.injectionReport[marks.flagged].Findings
+	}
+	p.incremental.next.Files[relPath] = section
+}
+
+// load reads the last output and its index, if they match the options.
+func (inc *incremental) load() {
+	inc.last, inc.bundle, inc.spliced = nil, nil, 0
+	inc.next = &sectionIndex{Options: inc.options, Started: time.Now(), Files: map[string]indexedSection{}}
+
+	data, err := os.ReadFile(filepath.Join(inc.dir, incrementalIndex))
+	if err != nil {
+		return
+	}
+	var index sectionIndex
+	if json.Unmarshal(data, &index) != nil || index.Options != inc.options {
+		return
+	}
+	bundle, err := os.ReadFile(filepath.Join(inc.dir, incrementalBundle))
+	if err != nil {
+		return
+	}
+	if sum := sha256.Sum256(bundle); hex.EncodeToString(sum[:]) != index.Bundle {
+		return
+	}
+	inc.last, inc.bundle = &index, bundle
+}
+
+// reuse returns the cached section of a file, if it's unchanged.
+func (inc *incremental) reuse(relPath string, info os.FileInfo, commit string) (indexedSection, bool) {
+	if inc.last == nil || info == nil {
+		return indexedSection{}, false
+	}
+	section, ok := inc.last.Files[relPath]
+	if !ok || section.Size != info.Size() || section.ModTime != info.ModTime().UnixNano() || section.Commit != commit {
+		return indexedSection{}, false
+	}
+	// Files modified as the last output was generated may have been read
+	// before their last change
+	if !info.ModTime().Before(inc.last.Started) {
+		return indexedSection{}, false
+	}
+	if section.Offset < 0 || section.Offset+section.Length > int64(len(inc.bundle)) {
+		return indexedSection{}, false
+	}
+	for dep, state := range section.Deps {
+		if current := statFile(dep); current != state || current.ModTime >= inc.last.Started.UnixNano() {
+			return indexedSection{}, false
+		}
+	}
+	return section, true
+}
+
+// save caches the output and its index, for the next Process call. The
+// cache is an optimization: failures only disable it.
+func (inc *incremental) save(outputFile string) {
+	bundle, err := os.ReadFile(outputFile)
+	if err != nil {
+		return
+	}
+	sum := sha256.Sum256(bundle)
+	inc.next.Bundle = hex.EncodeToString(sum[:])
+	index, err := json.Marshal(inc.next)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(inc.dir, 0o700); err != nil {
+		return
+	}
+	if writeAtomic(filepath.Join(inc.dir, incrementalBundle), bundle) == nil {
+		_ = writeAtomic(filepath.Join(inc.dir, incrementalIndex), index)
+	}
+	inc.bundle = nil // Only needed while writing
+}
+
+// writeAtomic writes a file through a temporary file renamed into place, so
+// that concurrent runs never read a partial file.
+func writeAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }() // No-op once renamed
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
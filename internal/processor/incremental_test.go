@@ -0,0 +1,119 @@
+package processor
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/holonoms/sandworm/internal/convert"
+)
+
+func TestIncremental(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, map[string]string{"a.txt": "alpha\n", "b.txt": "beta\n"})
+	cacheDir := filepath.Join(t.TempDir(), "cache")
+	outputFile := filepath.Join(t.TempDir(), "out.txt")
+
+	process := func(opts SandwormOptions) (string, int) {
+		t.Helper()
+		opts.IncrementalDir = cacheDir
+		p, err := NewWithOptions(tmpDir, outputFile, "", opts)
+		if err != nil {
+			t.Fatalf("Failed to create processor: %v", err)
+		}
+		if _, err := p.Process(); err != nil {
+			t.Fatalf("Process failed: %v", err)
+		}
+		content, err := os.ReadFile(outputFile)
+		if err != nil {
+			t.Fatalf("Failed to read output: %v", err)
+		}
+		return string(content), p.Spliced()
+	}
+
+	first, spliced := process(SandwormOptions{ChecksumManifest: true})
+	if spliced != 0 {
+		t.Errorf("Expected nothing to reuse on the first run, got %d", spliced)
+	}
+
+	second, spliced := process(SandwormOptions{ChecksumManifest: true})
+	if spliced != 2 {
+		t.Errorf("Expected both files to be reused, got %d", spliced)
+	}
+	if second != first {
+		t.Errorf("Expected the same output, got:\n%s\nwant:\n%s", second, first)
+	}
+
+	if err := os.WriteFile(filepath.Join(tmpDir, "b.txt"), []byte("beta, changed\n"), 0o644); err != nil {
+		t.Fatalf("Failed to update file: %v", err)
+	}
+	third, spliced := process(SandwormOptions{ChecksumManifest: true})
+	if spliced != 1 {
+		t.Errorf("Expected only the unchanged file to be reused, got %d", spliced)
+	}
+	if !strings.Contains(third, "beta, changed\n") || !strings.Contains(third, "alpha\n") {
+		t.Errorf("Expected the updated contents, got:\n%s", third)
+	}
+
+	// Options shaping file sections invalidate the cache
+	if _, spliced := process(SandwormOptions{ChecksumManifest: true, PrintLineNumbers: true}); spliced != 0 {
+		t.Errorf("Expected nothing to be reused with other options, got %d", spliced)
+	}
+}
+
+func TestIncrementalConverters(t *testing.T) {
+	tmpDir := t.TempDir()
+	sourceMap := func(source string) string {
+		return `{"version":3,"sources":["app.js"],"sourcesContent":[` + strconv.Quote(source) + `]}`
+	}
+	writeFiles(t, tmpDir, map[string]string{
+		"app.min.js":     "let a=1\n//# sourceMappingURL=app.min.js.map\n",
+		"app.min.js.map": sourceMap("let answer = 1\n"),
+		"data.csv":       "id\n1\n2\n3\n",
+	})
+	cacheDir := filepath.Join(t.TempDir(), "cache")
+	outputFile := filepath.Join(t.TempDir(), "out.txt")
+
+	process := func(opts convert.Options) (string, int) {
+		t.Helper()
+		p, err := NewWithOptions(tmpDir, outputFile, "", SandwormOptions{
+			IncrementalDir: cacheDir,
+			Converters:     convert.NewRegistry(convert.Builtins(opts)...),
+		})
+		if err != nil {
+			t.Fatalf("Failed to create processor: %v", err)
+		}
+		if _, err := p.Process(); err != nil {
+			t.Fatalf("Process failed: %v", err)
+		}
+		content, err := os.ReadFile(outputFile)
+		if err != nil {
+			t.Fatalf("Failed to read output: %v", err)
+		}
+		return string(content), p.Spliced()
+	}
+
+	process(convert.Options{CSVSampleRows: 1})
+	if _, spliced := process(convert.Options{CSVSampleRows: 1}); spliced != 3 {
+		t.Errorf("Expected every file to be reused, got %d", spliced)
+	}
+
+	// Converter options invalidate the cache
+	if _, spliced := process(convert.Options{CSVSampleRows: 2}); spliced != 0 {
+		t.Errorf("Expected nothing to be reused with other converter options, got %d", spliced)
+	}
+
+	// So do changes to the source map a minified file was rendered from
+	if err := os.WriteFile(filepath.Join(tmpDir, "app.min.js.map"), []byte(sourceMap("let answer = 42\n")), 0o644); err != nil {
+		t.Fatalf("Failed to update file: %v", err)
+	}
+	output, spliced := process(convert.Options{CSVSampleRows: 2})
+	if spliced != 1 {
+		t.Errorf("Expected only data.csv to be reused, got %d", spliced)
+	}
+	if !strings.Contains(output, "let answer = 42") {
+		t.Errorf("Expected the sources of the updated source map, got:\n%s", output)
+	}
+}
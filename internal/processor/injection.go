@@ -0,0 +1,36 @@
+package processor
+
+import (
+	"strings"
+
+	"github.com/holonoms/sandworm/internal/injection"
+)
+
+// InjectionResult records the suspicious lines of a file.
+type InjectionResult struct {
+	Path     string // Relative path of the file
+	Findings []injection.Finding
+}
+
+// InjectionReport returns the files with suspicious, instruction-like content
+// found during the last Process call.
+func (p *Processor) InjectionReport() []InjectionResult {
+	return p.injectionReport
+}
+
+// MARK: Helpers
+
+// scanInjection scans a file's content for prompt injection if scanning
+// applies to it, recording the findings.
+func (p *Processor) scanInjection(relPath string, content []byte) {
+	if !p.injectionScan {
+		return
+	}
+	if p.injectionPaths != nil && !p.injectionPaths.Match(strings.Split(relPath, "/"), false) {
+		return
+	}
+
+	if findings := injection.Scan(content); len(findings) > 0 {
+		p.injectionReport = append(p.injectionReport, InjectionResult{Path: relPath, Findings: findings})
+	}
+}
@@ -0,0 +1,131 @@
+package processor
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// licenseHeaderLines is how many lines at the start of a file are checked
+// against the license policy. License and copyright notices live in the
+// header; scanning whole files would flag code merely mentioning a license.
+const licenseHeaderLines = 30
+
+// LicenseExclusion records a file excluded by the license policy.
+type LicenseExclusion struct {
+	Path string // Relative path of the file
+	Line int    // Line number of the matching header line
+	Text string // The matching header line
+}
+
+// CompileLicensePattern compiles a license policy pattern: a regular
+// expression matched case-insensitively against each header line.
+func CompileLicensePattern(pattern string) (*regexp.Regexp, error) {
+	re, err := regexp.Compile("(?i)" + pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid license pattern: %w", err)
+	}
+	return re, nil
+}
+
+// ValidateLicensePattern checks that a license policy pattern compiles.
+func ValidateLicensePattern(pattern string) error {
+	if pattern == "" {
+		return nil
+	}
+	_, err := CompileLicensePattern(pattern)
+	return err
+}
+
+// LicenseExclusions returns the files excluded by the license policy during
+// the last Process (or Files) call.
+func (p *Processor) LicenseExclusions() []LicenseExclusion {
+	return p.licenseExclusions
+}
+
+// WriteLicenseReport writes a compliance report of the last Process call:
+// the policy, the excluded files with the matching header lines, and the
+// number of included files.
+func (p *Processor) WriteLicenseReport(w io.Writer) error {
+	pattern := "(none)"
+	if p.licenseExclude != nil {
+		pattern = strings.TrimPrefix(p.licenseExclude.String(), "(?i)")
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "LICENSE COMPLIANCE REPORT\n")
+	fmt.Fprintf(&b, "Generated: %s\n", time.Now().Format(time.RFC3339))
+	fmt.Fprintf(&b, "Directory: %s\n", p.rootDir)
+	fmt.Fprintf(&b, "Policy:    exclude files whose first %d lines match /%s/ (case-insensitive)\n\n", licenseHeaderLines, pattern)
+
+	fmt.Fprintf(&b, "Excluded files (%d):\n", len(p.licenseExclusions))
+	for _, e := range p.licenseExclusions {
+		fmt.Fprintf(&b, "  %s (line %d: %s)\n", e.Path, e.Line, e.Text)
+	}
+	fmt.Fprintf(&b, "\nIncluded files: %d\n", p.includedCount)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// MARK: Helpers
+
+// applyLicensePolicy drops files whose header matches the license policy,
+// recording them as exclusions.
+func (p *Processor) applyLicensePolicy(files []FileInfo) []FileInfo {
+	p.licenseExclusions = nil
+	kept := files[:0]
+	for _, file := range files {
+		if !file.TreeOnly && p.licenseExclude != nil {
+			match, err := readRecorded(p, file.RelativePath, func() (licenseMatch, error) {
+				return matchLicenseHeader(p.fsys, file.RelativePath, p.licenseExclude), nil
+			})
+			// Files whose header stalled are skipped when writing contents
+			if err == nil && match.ok {
+				p.licenseExclusions = append(p.licenseExclusions, LicenseExclusion{
+					Path: file.RelativePath, Line: match.line, Text: match.text,
+				})
+				continue
+			}
+		}
+		kept = append(kept, file)
+	}
+
+	p.includedCount = 0
+	for _, file := range kept {
+		if !file.TreeOnly {
+			p.includedCount++
+		}
+	}
+	return kept
+}
+
+// licenseMatch is the first header line of a file matching the license
+// policy, if ok.
+type licenseMatch struct {
+	line int
+	text string
+	ok   bool
+}
+
+// matchLicenseHeader returns the first header line of a file matching re.
+func matchLicenseHeader(fsys fs.FS, name string, re *regexp.Regexp) licenseMatch {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return licenseMatch{}
+	}
+	defer func() { _ = f.Close() }()
+
+	scanner := bufio.NewScanner(f)
+	for line := 1; line <= licenseHeaderLines && scanner.Scan(); line++ {
+		if re.Match(scanner.Bytes()) {
+			return licenseMatch{line, string(bytes.TrimSpace(scanner.Bytes())), true}
+		}
+	}
+	return licenseMatch{}
+}
@@ -0,0 +1,83 @@
+package processor
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"os/exec"
+	"strings"
+
+	"github.com/holonoms/sandworm/internal/util"
+)
+
+// gitCommit identifies the last commit touching a file.
+type gitCommit struct {
+	Hash string // Abbreviated hash
+	Date string // Commit date (YYYY-MM-DD)
+}
+
+// fileMetadata describes a file in its header.
+func fileMetadata(info fs.FileInfo, content []byte, commit *gitCommit) string {
+	lines := bytes.Count(content, []byte("\n"))
+	if len(content) > 0 && content[len(content)-1] != '\n' {
+		lines++
+	}
+
+	parts := []string{
+		util.FormatSize(info.Size()),
+		fmt.Sprintf("%d lines", lines),
+		"modified " + info.ModTime().Format("2006-01-02 15:04"),
+	}
+	if commit != nil {
+		parts = append(parts, fmt.Sprintf("last commit %s %s", commit.Hash, commit.Date))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// gitLastCommits returns the last commit touching each of the given files
+// (slash-separated, relative to the root). It walks the history once,
+// stopping as soon as every file has been seen. Files without history (or
+// the root not being in a git repository, or on disk) are left out.
+func (p *Processor) gitLastCommits(ctx context.Context, paths []string) map[string]gitCommit {
+	commits := make(map[string]gitCommit, len(paths))
+	if p.rootDir == "" {
+		return commits
+	}
+	pending := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		pending[path] = true
+	}
+
+	cmd := exec.CommandContext(ctx, "git", "-C", p.rootDir, "log", "--relative", "--name-only",
+		"--format=%x00%h %cs", "--", ".")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return commits
+	}
+	if err := cmd.Start(); err != nil {
+		return commits
+	}
+	defer func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	}()
+
+	var current gitCommit
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() && len(pending) > 0 {
+		line := scanner.Text()
+		if header, ok := strings.CutPrefix(line, "\x00"); ok {
+			hash, date, _ := strings.Cut(header, " ")
+			current = gitCommit{Hash: hash, Date: date}
+			continue
+		}
+		if pending[line] {
+			commits[line] = current
+			delete(pending, line)
+		}
+	}
+
+	return commits
+}
@@ -0,0 +1,29 @@
+package processor
+
+import "bytes"
+
+// normalizeWhitespace converts CRLF line endings to LF (eol) and trims
+// trailing spaces and tabs from each line (trim), so that the same files
+// produce the same output regardless of the OS they were checked out on.
+func normalizeWhitespace(content []byte, eol, trim bool) []byte {
+	if eol {
+		content = bytes.ReplaceAll(content, []byte("\r\n"), []byte("\n"))
+	}
+	if !trim {
+		return content
+	}
+
+	lines := bytes.SplitAfter(content, []byte("\n"))
+	out := make([]byte, 0, len(content))
+	for _, line := range lines {
+		body, newline := line, []byte(nil)
+		if bytes.HasSuffix(body, []byte("\r\n")) {
+			body, newline = body[:len(body)-2], body[len(body)-2:]
+		} else if bytes.HasSuffix(body, []byte("\n")) {
+			body, newline = body[:len(body)-1], body[len(body)-1:]
+		}
+		out = append(out, bytes.TrimRight(body, " \t")...)
+		out = append(out, newline...)
+	}
+	return out
+}
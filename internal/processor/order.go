@@ -0,0 +1,229 @@
+package processor
+
+import (
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/holonoms/sandworm/internal/deps"
+)
+
+// File ordering strategies
+const (
+	OrderPath    = "path"    // Files in path order (default)
+	OrderRelated = "related" // Files clustered by directory and imports, companions together
+)
+
+// OrderModes lists the valid file ordering strategies.
+var OrderModes = []string{OrderPath, OrderRelated}
+
+// Ranks of the files of a companion group (e.g. foo.h, foo.c, foo_test.c),
+// in the order they're written.
+const (
+	rankHeader = iota
+	rankSource
+	rankTest
+)
+
+// headerExtensions and sourceExtensions pair C-family headers with sources.
+var (
+	headerExtensions = map[string]bool{".h": true, ".hh": true, ".hpp": true, ".hxx": true}
+	sourceExtensions = map[string]bool{".c": true, ".cc": true, ".cpp": true, ".cxx": true, ".m": true, ".mm": true}
+)
+
+// relatedOrder orders files so that related ones are next to each other:
+//   - files are clustered by directory, and directories importing each other
+//     (see deps.BuildGraph) are adjacent, imported ones first;
+//   - tests follow the file they test, and headers precede their source
+//     (see companionOf), even when they're in another directory.
+//
+// Files are otherwise kept in their original (path) order.
+func (p *Processor) relatedOrder(files []FileInfo) []FileInfo {
+	// Attach companions (headers, tests) to their source file
+	anchors := map[string][]int{}
+	for i, file := range files {
+		if key, rank, ok := companionOf(file.RelativePath); ok && rank == rankSource {
+			anchors[key] = append(anchors[key], i)
+		}
+	}
+	anchorOf := make([]int, len(files))
+	companions := map[int][]int{}
+	for i, file := range files {
+		anchorOf[i] = i
+		key, rank, ok := companionOf(file.RelativePath)
+		if !ok || rank == rankSource {
+			continue
+		}
+		if anchor := companionAnchor(files, anchors[key], key, path.Dir(file.RelativePath)); anchor >= 0 {
+			anchorOf[i] = anchor
+			companions[anchor] = append(companions[anchor], i)
+		}
+	}
+
+	// Cluster the remaining files by directory, in order of appearance
+	var dirs []string
+	dirFiles := map[string][]int{}
+	for i, file := range files {
+		if anchorOf[i] != i {
+			continue
+		}
+		dir := path.Dir(file.RelativePath)
+		if _, ok := dirFiles[dir]; !ok {
+			dirs = append(dirs, dir)
+		}
+		dirFiles[dir] = append(dirFiles[dir], i)
+	}
+
+	// Write each directory after the directories it imports
+	var ordered []FileInfo
+	visited := map[string]bool{}
+	imports := p.directoryImports(files)
+	appearance := make(map[string]int, len(dirs))
+	for i, dir := range dirs {
+		appearance[dir] = i
+	}
+	var visit func(dir string)
+	visit = func(dir string) {
+		if visited[dir] {
+			return
+		}
+		visited[dir] = true
+		dirImports := imports[dir]
+		sort.Slice(dirImports, func(i, j int) bool { return appearance[dirImports[i]] < appearance[dirImports[j]] })
+		for _, dep := range dirImports {
+			visit(dep)
+		}
+		for _, i := range dirFiles[dir] {
+			group := append([]int{i}, companions[i]...)
+			sort.SliceStable(group, func(a, b int) bool {
+				_, rankA, _ := companionOf(files[group[a]].RelativePath)
+				_, rankB, _ := companionOf(files[group[b]].RelativePath)
+				return rankA < rankB
+			})
+			for _, j := range group {
+				ordered = append(ordered, files[j])
+			}
+		}
+	}
+	for _, dir := range dirs {
+		visit(dir)
+	}
+	return ordered
+}
+
+// companionAnchor returns the source file a header or test belongs with: the
+// one in the same directory, or else the only one in the project (so that
+// e.g. tests/test_utils.py isn't attached to one of several utils.py). Go
+// tests are always in the directory of their package. It returns -1 if
+// there's none.
+func companionAnchor(files []FileInfo, candidates []int, key, dir string) int {
+	for _, i := range candidates {
+		if path.Dir(files[i].RelativePath) == dir {
+			return i
+		}
+	}
+	if len(candidates) == 1 && !strings.HasPrefix(key, "go:") {
+		return candidates[0]
+	}
+	return -1
+}
+
+// directoryImports returns the directories each directory imports from,
+// derived from the import graph of the files.
+func (p *Processor) directoryImports(files []FileInfo) map[string][]string {
+	included := make(map[string]bool, len(files))
+	var paths []string
+	for _, file := range files {
+		if file.TreeOnly {
+			continue
+		}
+		included[file.RelativePath] = true
+		paths = append(paths, file.RelativePath)
+	}
+	graph, err := deps.BuildGraph(timeoutFS{p.fsys, p}, paths, func(relPath string) ([]byte, error) {
+		if content, err := p.readFile(relPath); err == nil {
+			return content, nil
+		}
+		return nil, nil // Reported when writing contents
+	})
+	if err != nil {
+		return nil
+	}
+
+	// Nodes are Go package directories or script files
+	dirOf := func(node string) string {
+		if included[node] {
+			return path.Dir(node)
+		}
+		return node
+	}
+	imports := map[string][]string{}
+	seen := map[[2]string]bool{}
+	for node, nodeDeps := range graph {
+		from := dirOf(node)
+		for _, dep := range nodeDeps {
+			to := dirOf(dep)
+			if to == from || seen[[2]string{from, to}] {
+				continue
+			}
+			seen[[2]string{from, to}] = true
+			imports[from] = append(imports[from], to)
+		}
+	}
+	return imports
+}
+
+// companionOf returns the key grouping a file with its companions, and its
+// rank in the group: a header (foo.h), a source (foo.c, foo.go, foo.ts) or a
+// test (foo_test.go, foo.test.ts, test_foo.py, foo_spec.rb, FooTest.java).
+// It returns false for files of other kinds.
+func companionOf(relPath string) (string, int, bool) {
+	name := path.Base(relPath)
+	ext := path.Ext(name)
+	stem := strings.TrimSuffix(name, ext)
+	if stem == "" {
+		return "", 0, false
+	}
+
+	var family string
+	var prefixes, suffixes []string
+	switch {
+	case headerExtensions[ext]:
+		return "c:" + stem, rankHeader, true
+	case sourceExtensions[ext]:
+		family, suffixes = "c", []string{"_test", "_tests", "Test", "Tests"}
+	case ext == ".go":
+		family, suffixes = "go", []string{"_test"}
+	case hasScriptExtension(name):
+		family, suffixes = "script", []string{".test", ".spec"}
+	case ext == ".py":
+		family, prefixes, suffixes = "py", []string{"test_"}, []string{"_test"}
+	case ext == ".rb":
+		family, suffixes = "rb", []string{"_spec", "_test"}
+	case ext == ".java" || ext == ".kt" || ext == ".scala":
+		family, suffixes = "jvm", []string{"Tests", "Test", "Spec"}
+	default:
+		return "", 0, false
+	}
+
+	for _, prefix := range prefixes {
+		if base, ok := strings.CutPrefix(stem, prefix); ok && base != "" {
+			return family + ":" + base, rankTest, true
+		}
+	}
+	for _, suffix := range suffixes {
+		if base, ok := strings.CutSuffix(stem, suffix); ok && base != "" {
+			return family + ":" + base, rankTest, true
+		}
+	}
+	return family + ":" + stem, rankSource, true
+}
+
+// hasScriptExtension reports whether a file is TypeScript/JavaScript.
+func hasScriptExtension(name string) bool {
+	switch path.Ext(name) {
+	case ".ts", ".tsx", ".mts", ".cts", ".js", ".jsx", ".mjs", ".cjs":
+		return true
+	}
+	return false
+}
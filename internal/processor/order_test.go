@@ -0,0 +1,73 @@
+package processor
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestRelatedOrder(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"go.mod":          "module example.com/m\n",
+		"README.md":       "# m\n",
+		"a/a.go":          "package a\n\nimport \"example.com/m/z\"\n\nvar _ = z.Z\n",
+		"a/a_test.go":     "package a\n",
+		"z/z.go":          "package z\n\nconst Z = 1\n",
+		"include/util.h":  "int util(void);\n",
+		"src/util.c":      "int util(void) { return 1; }\n",
+		"x.py":            "X = 1\n",
+		"tests/test_x.py": "from x import X\n",
+	}
+	writeFiles(t, tmpDir, files)
+
+	p, err := NewWithOptions(tmpDir, filepath.Join(t.TempDir(), "out.txt"), "", SandwormOptions{Order: OrderRelated})
+	if err != nil {
+		t.Fatalf("Failed to create processor: %v", err)
+	}
+	collected, err := p.Files(context.Background())
+	if err != nil {
+		t.Fatalf("Files failed: %v", err)
+	}
+
+	var got []string
+	for _, file := range p.relatedOrder(collected) {
+		got = append(got, file.RelativePath)
+	}
+	want := []string{
+		"README.md", "go.mod", "x.py", "tests/test_x.py", // Unique source: the test follows it
+		"z/z.go", "a/a.go", "a/a_test.go", // Imported package first, test after its file
+		"include/util.h", "src/util.c", // Header before its source
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("relatedOrder() =\n%v\nwant\n%v", got, want)
+	}
+}
+
+func TestCompanionOf(t *testing.T) {
+	tests := []struct {
+		path string
+		key  string
+		rank int
+		ok   bool
+	}{
+		{"pkg/foo.go", "go:foo", rankSource, true},
+		{"pkg/foo_test.go", "go:foo", rankTest, true},
+		{"src/button.tsx", "script:button", rankSource, true},
+		{"src/button.test.tsx", "script:button", rankTest, true},
+		{"src/button.spec.js", "script:button", rankTest, true},
+		{"tests/test_api.py", "py:api", rankTest, true},
+		{"lib/user_spec.rb", "rb:user", rankTest, true},
+		{"src/UserServiceTest.java", "jvm:UserService", rankTest, true},
+		{"include/foo.hpp", "c:foo", rankHeader, true},
+		{"src/foo.cpp", "c:foo", rankSource, true},
+		{"README.md", "", 0, false},
+	}
+	for _, tt := range tests {
+		key, rank, ok := companionOf(tt.path)
+		if key != tt.key || rank != tt.rank || ok != tt.ok {
+			t.Errorf("companionOf(%q) = %q, %d, %v; want %q, %d, %v", tt.path, key, rank, ok, tt.key, tt.rank, tt.ok)
+		}
+	}
+}
@@ -6,54 +6,82 @@ package processor
 
 import (
 	"bufio"
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"math"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+	"github.com/holonoms/sandworm/internal/convert"
+	"github.com/holonoms/sandworm/internal/deps"
 	"github.com/holonoms/sandworm/internal/filetree"
-	"github.com/karrick/godirwalk"
+	"github.com/holonoms/sandworm/internal/manifest"
+	"github.com/holonoms/sandworm/internal/sanitize"
+	"github.com/holonoms/sandworm/internal/scrub"
+	"github.com/holonoms/sandworm/internal/source"
+	"github.com/holonoms/sandworm/internal/symbols"
 )
 
 const separator = "================================================================================"
 
-// extraIgnores defines patterns for files that should typically be ignored
-const extraIgnores = `
-# === Non-binary files that are typically committed but irrelevant
-# === for LLMs assistance (e.g. logs, package lock files, etc.)
+// Titles of the sections of generated files
+const (
+	structureTitle       = "PROJECT STRUCTURE:"
+	symbolIndexTitle     = "SYMBOL INDEX:"
+	dependencyGraphTitle = "DEPENDENCY GRAPH:"
+	contentsTitle        = "FILE CONTENTS:"
+	remoteSourcesTitle   = "REMOTE SOURCES:"
+	databaseSchemasTitle = "DATABASE SCHEMAS:"
+	skippedFilesTitle    = "SKIPPED FILES:"
+)
+
+// heading returns the heading of a section: its title, underlined.
+func heading(title string) string {
+	return title + "\n" + strings.Repeat("=", len(title))
+}
+
+// Default traversal limits, guarding against running at $HOME or / by mistake
+const (
+	DefaultMaxDepth = 25
+	DefaultMaxFiles = 10000
+)
+
+// DefaultReadTimeout is how long a file read may stall (e.g. on a hung network
+// mount) before the file is skipped.
+const DefaultReadTimeout = 10 * time.Second
+
+// ErrLimitExceeded is returned when the walk exceeds MaxDepth or MaxFiles.
+var ErrLimitExceeded = errors.New("traversal limit exceeded")
+
+// sandwormIgnores are sandworm's own files, always ignored along with the
+// built-in ignore categories.
+const sandwormIgnores = `
 .sandworm
 .sandwormignore
+.sandwormsources
 .sandworm*.txt
-.git*
-CHANGELOG*
-*LICENSE*
-*.lock
-*-lock.json
-*-lock.yaml
-go.sum
-*.log
-
-# === Binary files
-# Image files
-*.png
-*.jpg
-*.jpeg
-*.gif
-*.bmp
-*.ico
-*.webp
-
-# Document files
-*.pdf
-*.doc
-*.docx
-*.xls
-*.xlsx
-*.ppt
-*.pptx
+sandworm-*.pprof
+sandworm.trace
+`
 
+// ignoreCategories define patterns for files that should typically be
+// ignored, as named categories which can be turned on or off individually.
+// Binary files come first, then non-binary files that are typically committed
+// but irrelevant for LLMs assistance (e.g. logs, package lock files, etc.)
+var ignoreCategories = []struct {
+	name     string
+	patterns string
+}{
+	{"binaries", `
 # Archive files
 *.zip
 *.tar
@@ -67,7 +95,29 @@ go.sum
 *.so
 *.dylib
 
-# Media files
+# Generic binary files
+*.bin
+`},
+	{"docs-binary", `
+*.pdf
+*.doc
+*.docx
+*.xls
+*.xlsx
+*.ppt
+*.pptx
+`},
+	{"media", `
+# Image files
+*.png
+*.jpg
+*.jpeg
+*.gif
+*.bmp
+*.ico
+*.webp
+
+# Audio and video files
 *.mp3
 *.mp4
 *.avi
@@ -79,27 +129,170 @@ go.sum
 *.otf
 *.woff
 *.woff2
+`},
+	{"locks", `
+*.lock
+*-lock.json
+*-lock.yaml
+go.sum
+`},
+	{"logs", `
+*.log
+`},
+	{"meta", `
+CHANGELOG*
+*LICENSE*
+`},
+	{"vcs", `
+.git*
+`},
+}
 
-# Generic binary files
-*.bin
-`
+// IgnoreCategories are the names of the built-in ignore categories.
+var IgnoreCategories = func() []string {
+	names := make([]string, len(ignoreCategories))
+	for i, category := range ignoreCategories {
+		names[i] = category.name
+	}
+	return names
+}()
+
+// IgnoreCategoryPatterns returns the patterns of a built-in ignore category,
+// without comments, or nil if there's no such category.
+func IgnoreCategoryPatterns(name string) []string {
+	for _, category := range ignoreCategories {
+		if category.name != name {
+			continue
+		}
+		var patterns []string
+		for _, line := range strings.Split(category.patterns, "\n") {
+			if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+				patterns = append(patterns, line)
+			}
+		}
+		return patterns
+	}
+	return nil
+}
+
+// ParseIgnoreCategories parses a comma-separated selection of built-in ignore
+// categories: "all" (or empty), "none", names to only enable these, or names
+// prefixed with "-" to disable them (from all categories, unless names are
+// given too). It returns the enabled categories.
+func ParseIgnoreCategories(spec string) (map[string]bool, error) {
+	enabled := make(map[string]bool)
+	var plain bool
+	var disabled []string
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		name := strings.TrimPrefix(item, "-")
+		switch {
+		case item == "" || item == "all":
+			for _, category := range IgnoreCategories {
+				enabled[category] = true
+			}
+			plain = true
+		case item == "none":
+			plain = true
+		case !slices.Contains(IgnoreCategories, name):
+			return nil, fmt.Errorf("invalid ignore category: %s (expected one of %s, all or none)",
+				name, strings.Join(IgnoreCategories, ", "))
+		case name != item:
+			disabled = append(disabled, name)
+		default:
+			enabled[name] = true
+			plain = true
+		}
+	}
+	if !plain {
+		for _, category := range IgnoreCategories {
+			enabled[category] = true
+		}
+	}
+	for _, name := range disabled {
+		delete(enabled, name)
+	}
+	return enabled, nil
+}
+
+// ValidateIgnoreCategories validates a selection of built-in ignore
+// categories (see ParseIgnoreCategories).
+func ValidateIgnoreCategories(spec string) error {
+	_, err := ParseIgnoreCategories(spec)
+	return err
+}
 
 // FileInfo represents a file to be included in the output
 type FileInfo struct {
-	RelativePath string // The path to display in the output (relative to root)
-	AbsolutePath   string // The actual path to read the file from (resolved symlinks)
+	RelativePath string // The path to display in the output, and to read the file from (relative to root)
+	AbsolutePath string // The path of the file on disk; empty if the project isn't on disk (see New)
+	TreeOnly     bool   // Only list the file in the structure, without its contents
+}
+
+// Timings breaks down the duration of a Process call.
+type Timings struct {
+	Walk  time.Duration // Collecting the files to include
+	Read  time.Duration // Reading and processing file contents
+	Write time.Duration // Writing the output: sections and file contents
 }
 
 // Processor handles the concatenation of project files into a single document
 // All options are set via SandwormOptions, which is constructed from CLI flags and config.
 // Symlink and line number logic are fully configurable via CLI flags or config file.
 type Processor struct {
-	rootDir              string
-	outputFile           string
-	ignoreFile           string
-	matcher              gitignore.Matcher
-	followSymlinks bool
+	fsys             fs.FS  // Project files, by slash-separated relative path
+	rootDir          string // Directory of fsys on disk, for git and external tools; empty if not on disk
+	outputFile       string
+	matcher          gitignore.Matcher
+	userMatcher      gitignore.Matcher // matcher without the built-in patterns, for files with a converter
+	followSymlinks   bool
 	printLineNumbers bool
+	asciiTree        bool
+	linguist         bool
+	submoduleMode    string
+	submodules       []string
+	includeDirs      []string
+	maxDepth         int // 0 for no limit
+	maxFiles         int // 0 for no limit
+	includeFiles     map[string]bool
+	includeFileDirs  map[string]bool // Ancestor directories of includeFiles
+	selectedFiles    bool            // includeFiles were hand-picked: ignore rules don't apply
+	dependencyGraph  bool
+	symbolIndex      bool
+	fileHeader       string
+	fileMetadata     bool
+	licenseExclude   *regexp.Regexp
+	scrubber         *scrub.Scrubber   // If set, PII is masked in file contents
+	scrubPaths       gitignore.Matcher // Files to scrub; all if nil
+	secrets          *scrub.Scrubber   // If set, credentials are masked in all file contents
+	checksumManifest bool
+	normalizeEOL     bool
+	trimWhitespace   bool
+	sanitize         string // Sanitization mode for control/invisible characters (see sanitize.Modes)
+	injectionScan    bool
+	injectionPaths   gitignore.Matcher // Files to scan for prompt injection; all if nil
+	converters       *convert.Registry
+	fixtureMaxLines  int
+	databases        []convert.Database
+	urlSources       []source.URL
+	urlFetcher       *source.URLFetcher
+
+	licenseExclusions []LicenseExclusion // Files excluded by licenseExclude in the last walk
+	includedCount     int                // Files with contents in the last walk
+	scrubReport       []ScrubResult      // Files in which PII was masked in the last Process
+	sanitizeReport    []SanitizeResult   // Files with sanitized characters in the last Process
+	injectionReport   []InjectionResult  // Files with suspicious content in the last Process
+	skippedFiles      []SkippedFile      // Files that couldn't be read in the last walk or Process
+	readTimeout       time.Duration      // Of each file or directory read; 0 for no limit
+	stalled           map[string]bool    // Files whose read timed out, never read again
+	walkConcurrency   int                // Directories read at once while walking
+	statBatch         int                // Files stat'ed at once
+	staleURLs         []string           // Remote sources included from an outdated copy in the last Process
+	order             string             // Order of the file contents (see OrderModes)
+	incremental       *incremental       // Reuses unchanged file sections of the last output; nil if off
+	timings           Timings            // Of the last Process
+	fileOffsets       []int64            // Offsets of the file sections in the output of the last Process
+	written           *offsetWriter      // Output of the current Process, for fileOffsets
 }
 
 // SandwormOptions holds the options for the Processor
@@ -108,78 +301,263 @@ type Processor struct {
 type SandwormOptions struct {
 	PrintLineNumbers bool
 	FollowSymlinks   bool
+	ASCIITree        bool               // Draw the project structure with ASCII characters only
+	Linguist         bool               // Exclude files marked linguist-generated/vendored in .gitattributes
+	Submodules       string             // How to handle git submodules (see SubmoduleModes); defaults to full
+	Order            string             // Order of the file contents (see OrderModes); defaults to path
+	DefaultIgnores   string             // Built-in ignore categories to enable (see ParseIgnoreCategories); all if empty
+	GlobalIgnoreFile string             // User-level ignore file merged into every project's patterns (like git's core.excludesFile), if it exists
+	ExcludePatterns  []string           // Ad-hoc gitignore-style patterns to exclude, taking precedence over ignore files
+	IncludePatterns  []string           // Ad-hoc gitignore-style patterns to re-include, taking precedence over all others
+	IncludeDirs      []string           // If set, only files within these (slash-separated, relative) directories are included
+	MaxDepth         int                // Fail when a (non-ignored) directory is nested deeper than this; 0 for no limit
+	MaxFiles         int                // Fail when more files than this are included; 0 for no limit
+	IncludeFiles     []string           // If set, only these (slash-separated, relative) files are included
+	SelectedFiles    []string           // If set, exactly these files are included, regardless of ignore rules
+	DependencyGraph  bool               // Add a section summarizing the imports between project files
+	SymbolIndex      bool               // Add a section listing the exported symbols of each file
+	FileHeader       string             // Template for file headers, with a {path} placeholder; defaults to the full style
+	FileMetadata     bool               // Add size, line count, modification time and last git commit to file headers
+	LicenseExclude   string             // Exclude files whose header lines match this (case-insensitive) regular expression
+	ReportFiles      []string           // Other files written by the run (e.g. a license report), never included; empty ones are skipped
+	NormalizeEOL     bool               // Convert CRLF line endings to LF in file contents
+	TrimWhitespace   bool               // Trim trailing whitespace from each line of file contents
+	Sanitize         string             // Strip or escape control and invisible characters (see sanitize.Modes); off by default
+	ScrubPII         bool               // Mask emails, phone numbers and ScrubPattern matches in file contents
+	ScrubPattern     string             // Regular expression of extra values to mask (e.g. names)
+	ScrubPaths       []string           // If set, only scrub files matching these gitignore-style patterns
+	ScrubSecrets     bool               // Mask credentials (private keys, service tokens) in all file contents
+	InjectionScan    bool               // Flag instruction-like content that could hijack the model (prompt injection)
+	InjectionPaths   []string           // If set, only scan files matching these gitignore-style patterns
+	ChecksumManifest bool               // Add a section with the SHA-256 of each file's content
+	Converters       *convert.Registry  // Render matching files (e.g. databases) as text
+	FixtureMaxLines  int                // Keep only a sample of test fixtures/golden files longer than this; 0 keeps them whole
+	Databases        []convert.Database // Add a section with the schema of these databases
+	URLSources       []source.URL       // Add a section with the contents of these remote sources
+	URLFetcher       *source.URLFetcher // Fetches (and caches) URLSources; required if any
+	IncrementalDir   string             // Caches the output, to reuse the sections of unchanged files next time (see Spliced); off if empty
+	ReadTimeout      time.Duration      // Skip files and directories whose read stalls longer than this (e.g. on hung network mounts); 0 for no limit
+	WalkConcurrency  int                // Directories read at once while walking; 0 or 1 for a serial walk
+	StatBatch        int                // Files stat'ed at once (symbolic link targets, unchanged file checks, metadata); 0 or 1 for one at a time
+}
+
+// New creates a Processor of the project in fsys (e.g. an fstest.MapFS, or an
+// archive), whose output is written with WriteTo. Features relying on files
+// being on disk (git metadata, database schemas and converters running a
+// command on the file) need NewWithOptions.
+func New(fsys fs.FS, opts SandwormOptions) (*Processor, error) {
+	return newProcessor(fsys, "", "", "", opts)
+}
+
+// NewFromFS creates a Processor of the project in fsys, like New, whose
+// output is written to outputFile by Process.
+func NewFromFS(fsys fs.FS, outputFile, ignoreFile string, opts SandwormOptions) (*Processor, error) {
+	return newProcessor(fsys, "", outputFile, ignoreFile, opts)
 }
 
-// NewWithOptions creates a new Processor instance with all options
+// NewWithOptions creates a Processor of the project in rootDir, whose output
+// is written to outputFile by Process. Unless ignoreFile is set, the
+// project's .sandwormignore or .gitignore files apply.
 func NewWithOptions(rootDir, outputFile, ignoreFile string, opts SandwormOptions) (*Processor, error) {
 	rootDir = filepath.Clean(rootDir)
+	return newProcessor(os.DirFS(rootDir), rootDir, outputFile, ignoreFile, opts)
+}
 
+func newProcessor(fsys fs.FS, rootDir, outputFile, ignoreFile string, opts SandwormOptions) (*Processor, error) {
 	p := &Processor{
+		fsys:             fsys,
 		rootDir:          rootDir,
 		outputFile:       outputFile,
-		ignoreFile:       ignoreFile,
 		printLineNumbers: opts.PrintLineNumbers,
 		followSymlinks:   opts.FollowSymlinks,
+		asciiTree:        opts.ASCIITree,
+		linguist:         opts.Linguist,
+		submoduleMode:    opts.Submodules,
+		order:            opts.Order,
+		includeDirs:      opts.IncludeDirs,
+		maxDepth:         opts.MaxDepth,
+		maxFiles:         opts.MaxFiles,
+		dependencyGraph:  opts.DependencyGraph,
+		symbolIndex:      opts.SymbolIndex,
+		fileHeader:       opts.FileHeader,
+		fileMetadata:     opts.FileMetadata,
+		checksumManifest: opts.ChecksumManifest,
+		normalizeEOL:     opts.NormalizeEOL,
+		trimWhitespace:   opts.TrimWhitespace,
+		sanitize:         opts.Sanitize,
+		injectionScan:    opts.InjectionScan,
+		injectionPaths:   newPathMatcher(opts.InjectionPaths),
+		converters:       opts.Converters,
+		fixtureMaxLines:  opts.FixtureMaxLines,
+		databases:        opts.Databases,
+		urlSources:       opts.URLSources,
+		urlFetcher:       opts.URLFetcher,
+		readTimeout:      opts.ReadTimeout,
+		stalled:          map[string]bool{},
+		walkConcurrency:  opts.WalkConcurrency,
+		statBatch:        opts.StatBatch,
+	}
+	if opts.IncrementalDir != "" {
+		p.incremental = newIncremental(opts.IncrementalDir, opts)
+	}
+	if p.fileHeader == "" {
+		p.fileHeader = headerTemplates[HeaderFull]
+	} else if err := ValidateHeaderTemplate(p.fileHeader); err != nil {
+		return nil, err
+	}
+	if opts.LicenseExclude != "" {
+		re, err := CompileLicensePattern(opts.LicenseExclude)
+		if err != nil {
+			return nil, err
+		}
+		p.licenseExclude = re
+	}
+	if err := sanitize.Validate(p.sanitize); err != nil {
+		return nil, err
+	}
+	if opts.ScrubPII {
+		scrubber, err := scrub.New(opts.ScrubPattern)
+		if err != nil {
+			return nil, err
+		}
+		p.scrubber = scrubber
+		p.scrubPaths = newPathMatcher(opts.ScrubPaths)
+	}
+	if opts.ScrubSecrets {
+		p.secrets = scrub.NewSecrets()
+	}
+	includeFiles := opts.IncludeFiles
+	if len(opts.SelectedFiles) > 0 {
+		includeFiles = opts.SelectedFiles
+		p.selectedFiles = true
+	}
+	if len(includeFiles) > 0 {
+		p.includeFiles = make(map[string]bool, len(includeFiles))
+		p.includeFileDirs = make(map[string]bool)
+		for _, f := range includeFiles {
+			p.includeFiles[f] = true
+			for dir := path.Dir(f); dir != "."; dir = path.Dir(dir) {
+				p.includeFileDirs[dir] = true
+			}
+		}
 	}
 
-	// Initialize patterns with EXTRA_IGNORES
-	patterns := []gitignore.Pattern{}
+	switch p.submoduleMode {
+	case "":
+		p.submoduleMode = SubmodulesFull
+	case SubmodulesFull, SubmodulesTree, SubmodulesSkip:
+	default:
+		return nil, fmt.Errorf("invalid submodules mode: %s (expected one of %s)",
+			p.submoduleMode, strings.Join(SubmoduleModes, ", "))
+	}
+	if p.submoduleMode != SubmodulesFull {
+		p.submodules = readSubmodulePaths(timeoutFS{fsys, p})
+	}
+
+	switch p.order {
+	case "":
+		p.order = OrderPath
+	case OrderPath, OrderRelated:
+	default:
+		return nil, fmt.Errorf("invalid file order: %s (expected one of %s)",
+			p.order, strings.Join(OrderModes, ", "))
+	}
 
-	// Add patterns from extraIgnores when no specific ignore file is provided
-	// or when using standard ignore files
-	addExtraIgnores := ignoreFile == "" ||
-		filepath.Base(ignoreFile) == ".gitignore" ||
-		filepath.Base(ignoreFile) == ".sandwormignore"
+	// Initialize patterns with the built-in ignore categories
+	patterns := []ignorePattern{}
+	var userPatterns []ignorePattern
 
-	if addExtraIgnores {
-		scanner := bufio.NewScanner(strings.NewReader(extraIgnores))
-		for scanner.Scan() {
-			line := scanner.Text()
-			if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
-				continue
+	// Add the built-in patterns when no specific ignore file is provided or
+	// when using standard ignore files
+	categories, err := ParseIgnoreCategories(opts.DefaultIgnores)
+	if err != nil {
+		return nil, err
+	}
+	if ignoreFile == "" || isStandardIgnoreFile(ignoreFile) {
+		patterns = append(patterns, parseIgnorePatterns(sandwormIgnores)...)
+		for _, category := range ignoreCategories {
+			if categories[category.name] {
+				patterns = append(patterns, parseIgnorePatterns(category.patterns)...)
 			}
-			pattern := gitignore.ParsePattern(line, []string{})
-			patterns = append(patterns, pattern)
 		}
 	}
 
+	// Add the user's global patterns, which project ignore files can override
+	// (later patterns take precedence)
+	if opts.GlobalIgnoreFile != "" {
+		data, err := os.ReadFile(opts.GlobalIgnoreFile)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to read global ignore file: %w", err)
+		}
+		globalPatterns := parseIgnorePatterns(string(data))
+		patterns = append(patterns, globalPatterns...)
+		userPatterns = append(userPatterns, globalPatterns...)
+	}
+
 	// If no specific ignore file is provided, look for .sandwormignore first,
-	// then fall back to .gitignore
+	// then fall back to .gitignore. Files of the same name in subdirectories
+	// apply too, as in git.
+	nestedName := ""
+	var data []byte
 	if ignoreFile == "" {
-		sandwormIgnore := filepath.Join(rootDir, ".sandwormignore")
-		if _, err := os.Stat(sandwormIgnore); err == nil {
-			p.ignoreFile = sandwormIgnore
-		} else {
-			gitIgnore := filepath.Join(rootDir, ".gitignore")
-			if _, err := os.Stat(gitIgnore); err == nil {
-				p.ignoreFile = gitIgnore
-			}
+		nestedName = ".gitignore"
+		if _, err := fs.Stat(timeoutFS{fsys, p}, ".sandwormignore"); err == nil {
+			nestedName = ".sandwormignore"
+		}
+		if data, err = fs.ReadFile(timeoutFS{fsys, p}, nestedName); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("failed to read ignore file: %w", err)
+		}
+	} else {
+		if isStandardIgnoreFile(ignoreFile) && sameDir(filepath.Dir(ignoreFile), rootDir) {
+			nestedName = filepath.Base(ignoreFile)
+		}
+		if data, err = os.ReadFile(ignoreFile); err != nil {
+			return nil, fmt.Errorf("failed to read ignore file: %w", err)
 		}
 	}
 
 	// Add patterns from the ignore file if it exists
-	if p.ignoreFile != "" {
-		data, err := os.ReadFile(p.ignoreFile)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read ignore file: %w", err)
-		}
+	if len(data) > 0 {
+		filePatterns := parseIgnorePatterns(string(data))
+		patterns = append(patterns, filePatterns...)
+		userPatterns = append(userPatterns, filePatterns...)
+	}
 
-		scanner := bufio.NewScanner(strings.NewReader(string(data)))
-		for scanner.Scan() {
-			line := scanner.Text()
-			if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
-				continue
-			}
-			pattern := gitignore.ParsePattern(line, []string{})
-			patterns = append(patterns, pattern)
+	// Ad-hoc patterns take precedence over every ignore file, nested ones
+	// included, with includes last so that they win over excludes
+	var overrides []ignorePattern
+	for _, line := range opts.ExcludePatterns {
+		if pattern, ok := parseIgnorePattern(line); ok {
+			overrides = append(overrides, pattern)
+		}
+	}
+	for _, line := range opts.IncludePatterns {
+		if pattern, ok := parseIgnorePattern(line); ok {
+			pattern.negate = true
+			overrides = append(overrides, pattern)
 		}
 	}
 
-	// Always ignore the output file
-	pattern := gitignore.ParsePattern(p.outputFile, []string{})
-	patterns = append(patterns, pattern)
+	// Always ignore the output file (and its parts), the reports and
+	// sandworm's own leftovers
+	for _, line := range generatedPatterns {
+		pattern, _ := parseIgnorePattern(line)
+		overrides = append(overrides, pattern)
+	}
+	if pattern, ok := outputPattern(rootDir, p.outputFile, true); ok {
+		overrides = append(overrides, pattern)
+	}
+	for _, file := range opts.ReportFiles {
+		if pattern, ok := outputPattern(rootDir, file, false); ok {
+			overrides = append(overrides, pattern)
+		}
+	}
 
-	p.matcher = gitignore.NewMatcher(patterns)
+	matcher := newIgnoreMatcher(patterns, timeoutFS{fsys, p}, nestedName)
+	matcher.overrides = overrides
+	userMatcher := newIgnoreMatcher(userPatterns, timeoutFS{fsys, p}, nestedName)
+	userMatcher.overrides = overrides
+	p.matcher, p.userMatcher = matcher, userMatcher
 	return p, nil
 }
 
@@ -188,109 +566,384 @@ func (p *Processor) SetFollowSymlinks(follow bool) {
 	p.followSymlinks = follow
 }
 
-// Process concatenates all project files into a single document
+// Process concatenates all project files into the output file, returning its
+// size.
 func (p *Processor) Process() (int64, error) {
-	files, err := p.collectFiles()
-	if err != nil {
-		return 0, fmt.Errorf("failed to collect files: %w", err)
+	return p.ProcessContext(context.Background())
+}
+
+// ProcessContext is Process, stopping between files once ctx is done (with
+// its cause as error), without leaving an output file behind.
+func (p *Processor) ProcessContext(ctx context.Context) (int64, error) {
+	if p.outputFile == "" {
+		return 0, errors.New("no output file (use WriteTo)")
 	}
 
-	out, err := os.Create(p.outputFile)
+	// Write to a temporary file renamed into place once complete, so that
+	// an interrupted run never leaves a truncated output file behind. Its
+	// name matches generatedPatterns, should it be left over anyway.
+	out, err := os.CreateTemp(filepath.Dir(p.outputFile), ".sandworm-*.txt")
 	if err != nil {
 		return 0, fmt.Errorf("failed to create output file: %w", err)
 	}
-	defer func() { _ = out.Close() }()
+	defer func() {
+		_ = out.Close()
+		_ = os.Remove(out.Name()) // No-op once renamed
+	}()
+
+	// A partial output is still written, listing the files left out
+	size, err := p.writeTo(ctx, out)
+	var partial *PartialError
+	if err != nil && !errors.As(err, &partial) {
+		return 0, err
+	}
+	if err := commitOutput(out, p.outputFile); err != nil {
+		return 0, err
+	}
+	if p.incremental != nil {
+		p.incremental.save(p.outputFile)
+	}
+	if partial != nil {
+		return size, partial
+	}
+	return size, nil
+}
+
+// WriteTo concatenates all project files into a single document written to
+// w, returning the number of bytes written. Files that can't be read don't
+// abort it: they're listed in the output, and reported with a *PartialError
+// once the output is complete.
+func (p *Processor) WriteTo(out io.Writer) (int64, error) {
+	return p.writeTo(context.Background(), out)
+}
+
+// writeTo is WriteTo, stopping between files once ctx is done.
+func (p *Processor) writeTo(ctx context.Context, out io.Writer) (int64, error) {
+	start := time.Now()
+	files, err := p.collectFiles(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to collect files: %w", err)
+	}
+	p.timings = Timings{Walk: time.Since(start)}
+	defer func() { p.timings.Write = time.Since(start) - p.timings.Walk - p.timings.Read }()
 
-	w := bufio.NewWriter(out)
+	p.written = &offsetWriter{w: out}
+	defer func() { p.written = nil }()
+	if p.incremental != nil {
+		p.incremental.load()
+	}
+	w := bufio.NewWriter(p.written)
 
 	// Write project structure
 	if err := p.writeStructure(w, files); err != nil {
 		return 0, fmt.Errorf("failed to write structure: %w", err)
 	}
 
-	// Write file contents
-	if err := p.writeContents(w, files); err != nil {
+	// Write the symbol index
+	if p.symbolIndex {
+		if err := p.writeSymbolIndex(w, files); err != nil {
+			return 0, fmt.Errorf("failed to write symbol index: %w", err)
+		}
+	}
+
+	// Write the import graph
+	if p.dependencyGraph {
+		if err := p.writeDependencyGraph(w, files); err != nil {
+			return 0, fmt.Errorf("failed to write dependency graph: %w", err)
+		}
+	}
+
+	// Write file contents, related files together if requested (the
+	// structure and indexes stay in path order)
+	contents := files
+	if p.order == OrderRelated {
+		contents = p.relatedOrder(files)
+	}
+	checksums := manifest.Manifest{}
+	if err := p.writeContents(ctx, w, contents, checksums); err != nil {
 		return 0, fmt.Errorf("failed to write contents: %w", err)
 	}
 
+	// Write the contents of remote sources
+	if len(p.urlSources) > 0 {
+		if err := p.writeURLSources(w); err != nil {
+			return 0, fmt.Errorf("failed to write remote sources: %w", err)
+		}
+	}
+
+	// Write the schemas of configured databases
+	if len(p.databases) > 0 {
+		if err := p.writeDatabaseSchemas(ctx, w); err != nil {
+			return 0, fmt.Errorf("failed to write database schemas: %w", err)
+		}
+	}
+
+	// List the files that couldn't be included
+	if len(p.skippedFiles) > 0 {
+		if err := p.writeSkippedFiles(w); err != nil {
+			return 0, fmt.Errorf("failed to write skipped files: %w", err)
+		}
+	}
+
+	// Write the checksum manifest
+	if p.checksumManifest {
+		if err := checksums.Write(w); err != nil {
+			return 0, fmt.Errorf("failed to write checksum manifest: %w", err)
+		}
+	}
+
 	if err := w.Flush(); err != nil {
 		return 0, fmt.Errorf("failed to flush writer: %w", err)
 	}
+	if len(p.skippedFiles) > 0 {
+		return p.written.n, &PartialError{Skipped: p.skippedFiles}
+	}
+	return p.written.n, nil
+}
 
-	// Get final file size
-	info, err := out.Stat()
-	if err != nil {
-		return 0, fmt.Errorf("failed to get file stats: %w", err)
+// commitOutput syncs a complete temporary output file to disk and renames it
+// to the output file, keeping the permissions of the file it replaces.
+func commitOutput(tmp *os.File, outputFile string) error {
+	mode := os.FileMode(0o644)
+	if info, err := os.Stat(outputFile); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := tmp.Chmod(mode); err != nil {
+		return fmt.Errorf("failed to set output file permissions: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		return fmt.Errorf("failed to sync output file: %w", err)
 	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close output file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), outputFile); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+	return nil
+}
 
-	return info.Size(), nil
+// canceled returns the cause of the cancellation of ctx, if it's done.
+func canceled(ctx context.Context) error {
+	if ctx.Err() == nil {
+		return nil
+	}
+	return context.Cause(ctx)
+}
+
+// Ignored reports whether a file (slash-separated, relative path) is excluded
+// by the ignore rules.
+func (p *Processor) Ignored(relPath string) bool {
+	return p.matcher != nil && p.matcher.Match(strings.Split(relPath, "/"), false)
+}
+
+// Files returns the files that would be included in the output, honoring
+// ignore rules and all other options. The walk stops once ctx is done.
+func (p *Processor) Files(ctx context.Context) ([]FileInfo, error) {
+	return p.collectFiles(ctx)
+}
+
+// Timings returns the breakdown of the duration of the last Process call.
+func (p *Processor) Timings() Timings {
+	return p.timings
+}
+
+// IncludedCount returns the number of files whose contents were included by
+// the last Process (or Files) call.
+func (p *Processor) IncludedCount() int {
+	return p.includedCount
 }
 
 // collectFiles walks the directory tree and returns a list of files to include
-func (p *Processor) collectFiles() ([]FileInfo, error) {
+func (p *Processor) collectFiles(ctx context.Context) ([]FileInfo, error) {
 	var files []FileInfo
+	var attributeFiles []string
+	p.skippedFiles = nil
 
-	callback := func(osPathname string, de *godirwalk.Dirent) error {
-		// Skip directories (but not symbolic links to files)
-		if de.IsDir() && !de.IsSymlink() {
+	// Entries are visited in lexical order, directories before their
+	// contents. The subdirectories of a directory are read ahead, in
+	// parallel, while visiting it (see SandwormOptions.WalkConcurrency).
+	slots := make(chan struct{}, max(p.walkConcurrency, 1))
+	var walk func(dir string, listing *dirListing) error
+	walk = func(dir string, listing *dirListing) error {
+		<-listing.done
+		if err := canceled(ctx); err != nil {
+			return err
+		}
+		if listing.err != nil {
+			// Skip directories that can't be read, listing them with the
+			// skipped files
+			p.skipFile(dir+"/", listing.err)
 			return nil
 		}
+		entries := listing.entries
 
-		// For symbolic links, check what they point to
-		if de.IsSymlink() {
-			isDir, err := de.IsDirOrSymlinkToDir()
-			if err != nil {
-				// Can't determine target, skip it
-				return nil
-			}
-			if isDir {
-				// It's a symbolic link to a directory, skip it from the file list
-				// (godirwalk will still traverse into it if FollowSymbolicLinks is true)
-				return nil
+		// For symbolic links, check what they point to, all at once
+		var links []string
+		for _, entry := range entries {
+			if entry.Type()&fs.ModeSymlink != 0 {
+				links = append(links, path.Join(dir, entry.Name()))
 			}
 		}
-
-		// Get relative path and normalize separators for cross-platform consistency
-		relPath, err := filepath.Rel(p.rootDir, osPathname)
-		if err != nil {
-			return fmt.Errorf("failed to get relative path: %w", err)
+		targets := map[string]statResult{}
+		for i, result := range p.statAll(links) {
+			targets[links[i]] = result
 		}
 
-		// Normalize to forward slashes for consistent processing
-		normalizedPath := filepath.ToSlash(relPath)
-		if p.matcher != nil && p.matcher.Match(strings.Split(normalizedPath, "/"), false) {
-			return nil
+		subdirs := map[string]*dirListing{}
+		for _, entry := range entries {
+			relPath := path.Join(dir, entry.Name())
+			if target, ok := targets[relPath]; ok {
+				if target.err == nil && target.info.IsDir() && p.followSymlinks {
+					subdirs[relPath] = p.listDir(relPath, slots)
+				}
+			} else if entry.IsDir() {
+				if descend, err := p.descend(relPath); err == nil && descend {
+					subdirs[relPath] = p.listDir(relPath, slots)
+				}
+			}
 		}
 
-		// Store both the display path and actual path
-		files = append(files, FileInfo{
-			RelativePath: normalizedPath,
-			AbsolutePath: osPathname,
-		})
+		for _, entry := range entries {
+			relPath := path.Join(dir, entry.Name())
+
+			if target, ok := targets[relPath]; ok {
+				if errors.Is(target.err, errReadTimeout) {
+					p.skipFile(relPath, target.err)
+					continue
+				}
+				if target.err != nil {
+					// Can't determine target (e.g. a cycle), skip it
+					continue
+				}
+				if target.info.IsDir() {
+					// Symbolic links to directories are only traversed
+					// when following them
+					if listing := subdirs[relPath]; listing != nil {
+						if err := walk(relPath, listing); err != nil {
+							return err
+						}
+					}
+					continue
+				}
+			} else if entry.IsDir() {
+				if listing := subdirs[relPath]; listing != nil {
+					if err := walk(relPath, listing); err != nil {
+						return err
+					}
+				} else if _, err := p.descend(relPath); err != nil {
+					return err
+				}
+				continue
+			}
+
+			// Keep track of .gitattributes files (even though they're ignored)
+			if p.linguist && entry.Name() == ".gitattributes" {
+				attributeFiles = append(attributeFiles, relPath)
+			}
+
+			if p.matcher != nil && !p.selectedFiles && p.matcher.Match(strings.Split(relPath, "/"), false) {
+				// Files with a converter (e.g. databases) are only skipped when
+				// the user's ignore rules say so
+				if p.converters.Find(relPath, nil) == nil || p.userMatcher.Match(strings.Split(relPath, "/"), false) {
+					continue
+				}
+			}
+			if !p.inScope(relPath, false) {
+				continue
+			}
+
+			if p.maxFiles > 0 && len(files) >= p.maxFiles {
+				return fmt.Errorf("%w: found more than %d files", ErrLimitExceeded, p.maxFiles)
+			}
+
+			files = append(files, FileInfo{
+				RelativePath: relPath,
+				AbsolutePath: p.absPath(relPath),
+				TreeOnly:     p.submoduleMode == SubmodulesTree && p.submoduleOf(relPath) != "",
+			})
+		}
 		return nil
 	}
+	if err := walk(".", p.listDir(".", slots)); err != nil {
+		return nil, err
+	}
 
-	errorCallback := func(_ string, _ error) godirwalk.ErrorAction {
-		// Skip files/directories that can't be accessed
-		return godirwalk.SkipNode
+	// Drop files marked as generated/vendored via .gitattributes
+	if len(attributeFiles) > 0 && !p.selectedFiles {
+		m, err := newAttributesMatcher(timeoutFS{p.fsys, p}, attributeFiles)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read .gitattributes: %w", err)
+		}
+		kept := files[:0]
+		for _, file := range files {
+			if !isLinguistExcluded(m, file.RelativePath) {
+				kept = append(kept, file)
+			}
+		}
+		files = kept
 	}
 
-	err := godirwalk.Walk(p.rootDir, &godirwalk.Options{
-		FollowSymbolicLinks: p.followSymlinks,
-		Callback:            callback,
-		ErrorCallback:       errorCallback,
-	})
+	return p.applyLicensePolicy(files), nil
+}
 
-	if err != nil {
-		return nil, err
+// descend reports whether the walk descends into a directory, failing if
+// it's nested deeper than allowed.
+func (p *Processor) descend(relPath string) (bool, error) {
+	// Never descend into git's internal directory. In worktrees and
+	// submodules .git is a file instead, which is ignored like any other
+	// .git* file.
+	if path.Base(relPath) == ".git" {
+		return false, nil
+	}
+	if p.submoduleMode == SubmodulesSkip && p.submoduleOf(relPath) != "" {
+		return false, nil
+	}
+	if !p.inScope(relPath, true) {
+		return false, nil
 	}
+	if depth := strings.Count(relPath, "/") + 1; p.maxDepth > 0 && depth > p.maxDepth &&
+		(p.matcher == nil || !p.matcher.Match(strings.Split(relPath, "/"), true)) {
+		return false, fmt.Errorf("%w: %s is nested deeper than %d directories", ErrLimitExceeded, relPath, p.maxDepth)
+	}
+	return true, nil
+}
 
-	return files, nil
+// absPath returns the path on disk of a file, if the project is on disk.
+func (p *Processor) absPath(relPath string) string {
+	if p.rootDir == "" {
+		return ""
+	}
+	return filepath.Join(p.rootDir, filepath.FromSlash(relPath))
+}
+
+// inScope reports whether a path is within the directories or files the output
+// is scoped to (see SandwormOptions.IncludeDirs and IncludeFiles). Directories
+// leading to a scoped path are in scope so that the walk can reach it.
+func (p *Processor) inScope(relPath string, isDir bool) bool {
+	if p.includeFiles != nil {
+		if isDir {
+			return p.includeFileDirs[relPath]
+		}
+		return p.includeFiles[relPath]
+	}
+	if len(p.includeDirs) == 0 {
+		return true
+	}
+	for _, dir := range p.includeDirs {
+		if dir == "." || dir == "" || relPath == dir || strings.HasPrefix(relPath, dir+"/") {
+			return true
+		}
+		if isDir && strings.HasPrefix(dir, relPath+"/") {
+			return true
+		}
+	}
+	return false
 }
 
 // writeStructure writes the directory tree structure to the output.
 func (p *Processor) writeStructure(w *bufio.Writer, files []FileInfo) error {
-	_, err := w.WriteString("PROJECT STRUCTURE:\n==================\n\n")
+	_, err := w.WriteString(heading(structureTitle) + "\n\n")
 	if err != nil {
 		return err
 	}
@@ -301,28 +954,180 @@ func (p *Processor) writeStructure(w *bufio.Writer, files []FileInfo) error {
 		paths[i] = file.RelativePath
 	}
 
-	tree := filetree.Build(paths, "")
-	_, err = w.WriteString(tree)
+	tree := filetree.New(paths)
+	if p.asciiTree {
+		tree.SetCharset(filetree.ASCIICharset)
+	}
+	_, err = w.WriteString(tree.String(""))
+	return err
+}
+
+// writeSymbolIndex writes the exported symbols of each file, so that the model
+// can navigate by symbol without scanning the whole text.
+func (p *Processor) writeSymbolIndex(w *bufio.Writer, files []FileInfo) error {
+	var lines []string
+	for _, file := range files {
+		if file.TreeOnly || !symbols.Supported(file.RelativePath) {
+			continue
+		}
+		content, err := p.readFile(file.RelativePath)
+		if err != nil {
+			continue // Reported when writing contents
+		}
+		if names := symbols.Extract(file.RelativePath, content); len(names) > 0 {
+			lines = append(lines, fmt.Sprintf("%s: %s", file.RelativePath, strings.Join(names, ", ")))
+		}
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+
+	if _, err := w.WriteString("\n\n" + heading(symbolIndexTitle) + "\n\n"); err != nil {
+		return err
+	}
+	_, err := w.WriteString(strings.Join(lines, "\n"))
+	return err
+}
+
+// writeDependencyGraph writes a summary of the intra-project imports, which
+// conveys the architecture of the project better than the raw contents.
+func (p *Processor) writeDependencyGraph(w *bufio.Writer, files []FileInfo) error {
+	var paths []string
+	for _, file := range files {
+		if !file.TreeOnly {
+			paths = append(paths, file.RelativePath)
+		}
+	}
+
+	graph, err := deps.BuildGraph(timeoutFS{p.fsys, p}, paths, func(relPath string) ([]byte, error) {
+		if content, err := p.readFile(relPath); err == nil {
+			return content, nil
+		}
+		return nil, nil // Reported when writing contents
+	})
 	if err != nil {
 		return err
 	}
+	if len(graph) == 0 {
+		return nil
+	}
 
-	_, err = w.WriteString("\n\nFILE CONTENTS:\n==============\n\n")
+	if _, err := w.WriteString("\n\n" + heading(dependencyGraphTitle) + "\n\n"); err != nil {
+		return err
+	}
+	_, err = w.WriteString(strings.TrimSuffix(graph.String(), "\n"))
 	return err
 }
 
-// writeContents writes the contents of each file to the output.
-func (p *Processor) writeContents(w *bufio.Writer, files []FileInfo) error {
+// writeContents writes the contents of each file to the output, recording the
+// SHA-256 of the written content in checksums.
+func (p *Processor) writeContents(ctx context.Context, w *bufio.Writer, files []FileInfo, checksums manifest.Manifest) error {
+	if _, err := w.WriteString("\n\n" + heading(contentsTitle) + "\n\n"); err != nil {
+		return err
+	}
+
+	var commits map[string]gitCommit
+	if p.fileMetadata {
+		paths := make([]string, len(files))
+		for i, file := range files {
+			paths[i] = file.RelativePath
+		}
+		commits = p.gitLastCommits(ctx, paths)
+	}
+	p.scrubReport = nil
+	p.sanitizeReport = nil
+	p.injectionReport = nil
+	p.fileOffsets = nil
+
+	// Stat the files ahead, in batches, to check whether they're unchanged
+	// and describe them
+	infos := map[string]fs.FileInfo{}
+	if p.fileMetadata || (p.incremental != nil && p.written != nil) {
+		var names []string
+		for _, file := range files {
+			if !file.TreeOnly {
+				names = append(names, file.RelativePath)
+			}
+		}
+		for i, result := range p.statAll(names) {
+			infos[names[i]] = result.info
+		}
+	}
+
 	for _, file := range files {
-		// Write file header using the relative path for display
-		if _, err := fmt.Fprintf(w, "%s\nFILE: %s\n%s\n", separator, file.RelativePath, separator); err != nil {
+		if err := canceled(ctx); err != nil {
 			return err
 		}
+		if file.TreeOnly {
+			continue
+		}
+
+		// Reuse the section of unchanged files from the last output
+		info := infos[file.RelativePath]
+		var marks reportMarks
+		if p.incremental != nil && p.written != nil {
+			if p.spliceSection(w, file.RelativePath, info, commits[file.RelativePath].Hash, checksums) {
+				continue
+			}
+			marks = p.reportMarks()
+		}
 
-		// Read file contents from the actual path (handles symlinks automatically)
-		content, err := os.ReadFile(file.AbsolutePath)
+		// Read file contents from the actual path (handles symlinks automatically).
+		// Files deleted or rewritten since the walk are skipped, not fatal.
+		readStart := time.Now()
+		content, err := p.readFile(file.RelativePath)
 		if err != nil {
-			return fmt.Errorf("failed to read file %s: %w", file.RelativePath, err)
+			p.skipFile(file.RelativePath, err)
+			continue
+		}
+		// The manifest is verified against the files on disk (see
+		// manifest.Verify), so it hashes them as read, not as included
+		checksums[file.RelativePath] = manifest.Hash(content)
+		var deps []string
+		if p.incremental != nil && p.written != nil {
+			deps = p.conversionDependencies(file, content)
+		}
+		content, converter, err := p.convertContent(ctx, file, content)
+		if err != nil {
+			p.skipFile(file.RelativePath, fmt.Errorf("conversion failed: %w", err))
+			continue
+		}
+		var fixtureLines int
+		if isFixture(file.RelativePath) {
+			content, fixtureLines = trimFixture(content, p.fixtureMaxLines)
+		}
+		p.scanInjection(file.RelativePath, content) // Before sanitizing, which hides some attempts
+		content = normalizeWhitespace(content, p.normalizeEOL, p.trimWhitespace)
+		content = p.sanitizeContent(file.RelativePath, content)
+		content = p.scrubContent(file.RelativePath, content)
+
+		var meta string
+		if p.fileMetadata {
+			var commit *gitCommit
+			if c, ok := commits[file.RelativePath]; ok {
+				commit = &c
+			}
+			// Metadata is left out of the header if the file couldn't be stat'ed
+			if info != nil {
+				meta = fileMetadata(info, content, commit)
+			}
+		}
+		if converter != "" {
+			meta = strings.TrimPrefix(meta+", converted by "+converter, ", ")
+		}
+		if fixtureLines > 0 {
+			meta = strings.TrimPrefix(meta+fmt.Sprintf(", fixture trimmed from %d lines", fixtureLines), ", ")
+		}
+
+		p.timings.Read += time.Since(readStart)
+
+		if p.written != nil {
+			p.fileOffsets = append(p.fileOffsets, p.written.n+int64(w.Buffered()))
+		}
+
+		// Write file header using the relative path for display
+		if _, err := fmt.Fprintf(w, "%s\n", formatHeader(p.fileHeader, file.RelativePath, meta)); err != nil {
+			return err
 		}
 
 		// Write file contents with optional line numbers
@@ -339,6 +1144,9 @@ func (p *Processor) writeContents(w *bufio.Writer, files []FileInfo) error {
 		if _, err := w.WriteString("\n"); err != nil {
 			return err
 		}
+		if p.incremental != nil && p.written != nil && info != nil {
+			p.recordSection(w, file.RelativePath, info, commits[file.RelativePath].Hash, checksums[file.RelativePath], deps, marks)
+		}
 	}
 
 	return nil
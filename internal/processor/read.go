@@ -0,0 +1,180 @@
+package processor
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+	"time"
+)
+
+// readRetryDelay is how long to wait before reading a file again when it
+// vanished or changed while being read (e.g. rewritten by a build).
+const readRetryDelay = 100 * time.Millisecond
+
+// errFileChanged is returned when a file changes while it's being read.
+var errFileChanged = errors.New("file changed while being read")
+
+// errReadTimeout is returned when reading a file (or directory) stalls for
+// longer than the read timeout, e.g. on a hung network mount.
+var errReadTimeout = errors.New("read timed out")
+
+// SkippedFile records a file listed in the structure whose contents couldn't
+// be read, typically because it was deleted or rewritten during the walk.
+type SkippedFile struct {
+	Path   string // Relative path of the file
+	Reason string
+}
+
+// PartialError is returned when files were skipped by an otherwise complete
+// Process call: the output was written without them, listing them in its
+// skipped files section.
+type PartialError struct {
+	Skipped []SkippedFile // Files, and directories with a trailing slash
+}
+
+func (e *PartialError) Error() string {
+	paths := make([]string, len(e.Skipped))
+	for i, file := range e.Skipped {
+		paths[i] = file.Path
+	}
+	return fmt.Sprintf("skipped %d files that couldn't be read: %s", len(e.Skipped), strings.Join(paths, ", "))
+}
+
+// MARK: Helpers
+
+// readFile reads a project file, retrying once if it can't be read or
+// changes while being read. Files whose read stalled aren't read again.
+func (p *Processor) readFile(name string) ([]byte, error) {
+	if p.stalled[name] {
+		return nil, p.stallError()
+	}
+	read := func() ([]byte, error) { return readStable(p.fsys, name) }
+	content, err := withTimeout(p.readTimeout, read)
+	if err == nil {
+		return content, nil
+	}
+	if errors.Is(err, errReadTimeout) {
+		p.stalled[name] = true
+		return nil, err
+	}
+	time.Sleep(readRetryDelay)
+	content, err = withTimeout(p.readTimeout, read)
+	if errors.Is(err, errReadTimeout) {
+		p.stalled[name] = true
+	}
+	return content, err
+}
+
+// timeoutFS is the project's fsys for the files read outside of readFile
+// (ignore, attribute and module files), giving up on ReadFile and Stat calls
+// after the read timeout. Stalled files are recorded, and not read again.
+type timeoutFS struct {
+	fs.FS
+	p *Processor
+}
+
+func (f timeoutFS) ReadFile(name string) ([]byte, error) {
+	return readRecorded(f.p, name, func() ([]byte, error) { return fs.ReadFile(f.FS, name) })
+}
+
+func (f timeoutFS) Stat(name string) (fs.FileInfo, error) {
+	return readRecorded(f.p, name, func() (fs.FileInfo, error) { return fs.Stat(f.FS, name) })
+}
+
+// readRecorded calls read with the read timeout of p, unless name already
+// stalled, recording name if it stalls.
+func readRecorded[T any](p *Processor, name string, read func() (T, error)) (T, error) {
+	if p.stalled[name] {
+		var zero T
+		return zero, p.stallError()
+	}
+	value, err := withTimeout(p.readTimeout, read)
+	if errors.Is(err, errReadTimeout) {
+		p.stalled[name] = true
+	}
+	return value, err
+}
+
+// stallError is the error of reads that stalled.
+func (p *Processor) stallError() error {
+	return fmt.Errorf("%w after %s", errReadTimeout, p.readTimeout)
+}
+
+// withTimeout calls read, giving up after timeout if it's positive. Stalled
+// reads (e.g. a hung open() on a network mount) can't be interrupted: they're
+// left to finish in the background.
+func withTimeout[T any](timeout time.Duration, read func() (T, error)) (T, error) {
+	if timeout <= 0 {
+		return read()
+	}
+	type result struct {
+		value T
+		err   error
+	}
+	done := make(chan result, 1) // Never blocks a read finishing too late
+	go func() {
+		value, err := read()
+		done <- result{value, err}
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case r := <-done:
+		return r.value, r.err
+	case <-timer.C:
+		var zero T
+		return zero, fmt.Errorf("%w after %s", errReadTimeout, timeout)
+	}
+}
+
+// readStable reads a file, failing if its size or modification time changed
+// during the read.
+func readStable(fsys fs.FS, name string) ([]byte, error) {
+	before, err := fs.Stat(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	content, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	after, err := fs.Stat(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	if after.Size() != before.Size() || !after.ModTime().Equal(before.ModTime()) {
+		return nil, errFileChanged
+	}
+	return content, nil
+}
+
+// skipFile records a file whose contents couldn't be read.
+func (p *Processor) skipFile(relPath string, err error) {
+	reason := err.Error()
+	// The path is already known
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		reason = strings.Replace(reason, pathErr.Error(), pathErr.Err.Error(), 1)
+	}
+	if errors.Is(err, fs.ErrNotExist) {
+		reason = "deleted"
+	}
+	p.skippedFiles = append(p.skippedFiles, SkippedFile{Path: relPath, Reason: reason})
+}
+
+// writeSkippedFiles lists the files that couldn't be included, so that the
+// model knows what's missing from the output.
+func (p *Processor) writeSkippedFiles(w *bufio.Writer) error {
+	if _, err := w.WriteString("\n\n" + heading(skippedFilesTitle) + "\n\n"); err != nil {
+		return err
+	}
+	for _, file := range p.skippedFiles {
+		if _, err := fmt.Fprintf(w, "%s (%s)\n", file.Path, file.Reason); err != nil {
+			return err
+		}
+	}
+	return nil
+}
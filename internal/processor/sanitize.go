@@ -0,0 +1,27 @@
+package processor
+
+import "github.com/holonoms/sandworm/internal/sanitize"
+
+// SanitizeResult records the characters sanitized in a file.
+type SanitizeResult struct {
+	Path  string // Relative path of the file
+	Count int    // Characters (or escape sequences) stripped or escaped
+}
+
+// SanitizeReport returns the files in which characters were sanitized during
+// the last Process call.
+func (p *Processor) SanitizeReport() []SanitizeResult {
+	return p.sanitizeReport
+}
+
+// MARK: Helpers
+
+// sanitizeContent strips or escapes control and invisible characters in a
+// file's content, recording how many.
+func (p *Processor) sanitizeContent(relPath string, content []byte) []byte {
+	content, count := sanitize.Sanitize(content, p.sanitize)
+	if count > 0 {
+		p.sanitizeReport = append(p.sanitizeReport, SanitizeResult{Path: relPath, Count: count})
+	}
+	return content
+}
@@ -0,0 +1,69 @@
+package processor
+
+import (
+	"maps"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+	"github.com/holonoms/sandworm/internal/scrub"
+)
+
+// ScrubResult records the PII and secrets masked in a file.
+type ScrubResult struct {
+	Path   string       // Relative path of the file
+	Counts scrub.Counts // Replacements per category
+}
+
+// ScrubReport returns the files in which PII or secrets were masked during the
+// last Process call.
+func (p *Processor) ScrubReport() []ScrubResult {
+	return p.scrubReport
+}
+
+// PathMatcher returns a function reporting whether a (slash-separated,
+// relative) path matches gitignore-style patterns, or nil if there are none.
+func PathMatcher(patterns []string) func(relPath string) bool {
+	m := newPathMatcher(patterns)
+	if m == nil {
+		return nil
+	}
+	return func(relPath string) bool {
+		return m.Match(strings.Split(relPath, "/"), false)
+	}
+}
+
+// MARK: Helpers
+
+// newPathMatcher matches the files an option applies to, given gitignore-style
+// patterns. It returns nil (every file) when there are none.
+func newPathMatcher(patterns []string) gitignore.Matcher {
+	if len(patterns) == 0 {
+		return nil
+	}
+	var parsed []ignorePattern
+	for _, pattern := range patterns {
+		if p, ok := parseIgnorePattern(pattern); ok {
+			parsed = append(parsed, p)
+		}
+	}
+	return newIgnoreMatcher(parsed, nil, "")
+}
+
+// scrubContent masks secrets in a file's content, and PII if scrubbing applies
+// to it, recording the replacements.
+func (p *Processor) scrubContent(relPath string, content []byte) []byte {
+	counts := scrub.Counts{}
+	if p.secrets != nil {
+		content, counts = p.secrets.Scrub(content)
+	}
+	if p.scrubber != nil && (p.scrubPaths == nil || p.scrubPaths.Match(strings.Split(relPath, "/"), false)) {
+		var pii scrub.Counts
+		content, pii = p.scrubber.Scrub(content)
+		maps.Copy(counts, pii)
+	}
+
+	if counts.Total() > 0 {
+		p.scrubReport = append(p.scrubReport, ScrubResult{Path: relPath, Counts: counts})
+	}
+	return content
+}
@@ -0,0 +1,92 @@
+package processor
+
+import (
+	"io"
+	"sort"
+)
+
+// offsetWriter counts the bytes written through it, to record where each
+// file starts in the output (see FileOffsets).
+type offsetWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (o *offsetWriter) Write(b []byte) (int, error) {
+	n, err := o.w.Write(b)
+	o.n += int64(n)
+	return n, err
+}
+
+// FileOffsets returns the offsets in the output at which the section of each
+// file starts (its header), in the last Process call.
+func (p *Processor) FileOffsets() []int64 {
+	return p.fileOffsets
+}
+
+// Split splits a bundle into parts of at most budget tokens, cutting only at
+// file boundaries (offsets, see FileOffsets). Parts are balanced: there are
+// as few as the budget allows, with the largest as small as possible, so
+// that no part is disproportionately large. count estimates the tokens of a
+// piece of the bundle.
+//
+// The first part holds the preamble (project structure, indexes) and the last
+// the trailing sections (remote sources, manifest). A file larger than the
+// budget makes a part of its own.
+func Split(content []byte, offsets []int64, budget int, count func([]byte) int) [][]byte {
+	// Segments are files, the preamble going with the first one
+	starts := []int{0}
+	for _, offset := range offsets[min(1, len(offsets)):] {
+		if offset > int64(starts[len(starts)-1]) && offset < int64(len(content)) {
+			starts = append(starts, int(offset))
+		}
+	}
+	sizes := make([]int, len(starts))
+	for i, start := range starts {
+		end := len(content)
+		if i+1 < len(starts) {
+			end = starts[i+1]
+		}
+		sizes[i] = count(content[start:end])
+	}
+
+	// Fewest parts within the budget, then the lowest part size that still
+	// fits in as many parts
+	parts := packSegments(sizes, budget, budget)
+	if len(parts) > 1 {
+		limit := sort.Search(budget, func(limit int) bool {
+			return len(packSegments(sizes, budget, limit+1)) <= len(parts)
+		}) + 1
+		parts = packSegments(sizes, budget, limit)
+	}
+
+	chunks := make([][]byte, len(parts))
+	for i, first := range parts {
+		end := len(content)
+		if i+1 < len(parts) {
+			end = starts[parts[i+1]]
+		}
+		chunks[i] = content[starts[first]:end]
+	}
+	return chunks
+}
+
+// packSegments groups consecutive segments of the given sizes into parts of
+// at most limit, returning the first segment of each part. Segments over the
+// budget make parts of their own.
+func packSegments(sizes []int, budget, limit int) []int {
+	var parts []int
+	total := 0
+	for i, size := range sizes {
+		switch {
+		case len(parts) == 0:
+		case size > budget, total > budget, total+size > limit:
+		default:
+			total += size
+			continue
+		}
+		parts = append(parts, i)
+		total = size
+	}
+	return parts
+}
@@ -0,0 +1,77 @@
+package processor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	count := func(b []byte) int { return len(b) }
+	// A 10-byte preamble, then files of 40, 50, 10 and 10 bytes
+	content := []byte(strings.Repeat("p", 10) + strings.Repeat("a", 40) + strings.Repeat("b", 50) + strings.Repeat("c", 10) + strings.Repeat("d", 10))
+	offsets := []int64{10, 50, 100, 110}
+
+	tests := []struct {
+		name   string
+		budget int
+		want   []string // First byte of each part
+	}{
+		{name: "fits", budget: 200, want: []string{"p"}},
+		// Greedy packing would make parts of 100 and 20 bytes
+		{name: "balanced", budget: 100, want: []string{"p", "b"}},
+		{name: "oversized files", budget: 40, want: []string{"p", "b", "c"}},
+		{name: "one file per part", budget: 10, want: []string{"p", "b", "c", "d"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parts := Split(content, offsets, tt.budget, count)
+			var got []string
+			total := 0
+			for _, part := range parts {
+				got = append(got, string(part[0]))
+				total += len(part)
+			}
+			if strings.Join(got, "") != strings.Join(tt.want, "") {
+				t.Errorf("Expected parts starting with %v, got %v", tt.want, got)
+			}
+			if total != len(content) {
+				t.Errorf("Expected parts to cover the bundle, got %d of %d bytes", total, len(content))
+			}
+		})
+	}
+}
+
+func TestProcessorFileOffsets(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte("content of "+name+"\n"), 0o644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+	}
+
+	outputFile := filepath.Join(t.TempDir(), "out.txt")
+	header, _ := HeaderTemplate(HeaderMinimal)
+	p, err := NewWithOptions(tmpDir, outputFile, "", SandwormOptions{FileHeader: header})
+	if err != nil {
+		t.Fatalf("Failed to create processor: %v", err)
+	}
+	if _, err := p.Process(); err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	content, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+
+	offsets := p.FileOffsets()
+	if len(offsets) != 2 {
+		t.Fatalf("Expected 2 file offsets, got %v", offsets)
+	}
+	for i, name := range []string{"a.txt", "b.txt"} {
+		if got := string(content[offsets[i]:]); !strings.HasPrefix(got, ">>> "+name+"\n") {
+			t.Errorf("Expected offset %d at the header of %s, got %q", offsets[i], name, got[:min(len(got), 20)])
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package processor
+
+import (
+	"bufio"
+	"bytes"
+	"io/fs"
+	"path/filepath"
+	"strings"
+)
+
+// Submodule handling modes
+const (
+	SubmodulesFull = "full" // Include submodule files like any other file (default)
+	SubmodulesTree = "tree" // List submodule files in the structure, without contents
+	SubmodulesSkip = "skip" // Leave submodules out entirely
+)
+
+// SubmoduleModes lists the valid submodule handling modes.
+var SubmoduleModes = []string{SubmodulesFull, SubmodulesTree, SubmodulesSkip}
+
+// readSubmodulePaths returns the (slash-separated) paths of the submodules
+// declared in the .gitmodules file at the root of fsys, if any.
+func readSubmodulePaths(fsys fs.FS) []string {
+	data, err := fs.ReadFile(fsys, ".gitmodules")
+	if err != nil {
+		return nil
+	}
+
+	var paths []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(strings.TrimSpace(scanner.Text()), "=")
+		if !ok || strings.TrimSpace(key) != "path" {
+			continue
+		}
+		path := strings.Trim(strings.TrimSpace(value), `"/`)
+		if path != "" {
+			paths = append(paths, filepath.ToSlash(path))
+		}
+	}
+	return paths
+}
+
+// submoduleOf returns the submodule containing relPath, or "" if none does.
+func (p *Processor) submoduleOf(relPath string) string {
+	for _, sub := range p.submodules {
+		if relPath == sub || strings.HasPrefix(relPath, sub+"/") {
+			return sub
+		}
+	}
+	return ""
+}
@@ -0,0 +1,61 @@
+package processor
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+// StaleURLs returns the remote sources included from an outdated cached
+// copy in the last Process call, because fetching them failed.
+func (p *Processor) StaleURLs() []string {
+	return p.staleURLs
+}
+
+// writeURLSources writes the contents of the remote sources, after the
+// project files. Sources that can't be fetched (and were never cached) are
+// reported as skipped.
+func (p *Processor) writeURLSources(w *bufio.Writer) error {
+	if _, err := w.WriteString("\n\n" + heading(remoteSourcesTitle) + "\n\n"); err != nil {
+		return err
+	}
+	p.staleURLs = nil
+
+	for _, u := range p.urlSources {
+		fetched, err := p.urlFetcher.Fetch(u)
+		if err != nil {
+			p.skipFile(u.URL, err)
+			continue
+		}
+		if bytes.IndexByte(fetched.Content, 0) >= 0 {
+			p.skipFile(u.URL, errors.New("binary content"))
+			continue
+		}
+
+		meta := "fetched " + fetched.FetchedAt.Local().Format("2006-01-02 15:04")
+		if fetched.Stale {
+			meta += ", stale: refresh failed"
+			p.staleURLs = append(p.staleURLs, u.URL)
+		}
+		p.scanInjection(u.URL, fetched.Content)
+		content := normalizeWhitespace(fetched.Content, p.normalizeEOL, p.trimWhitespace)
+		content = p.sanitizeContent(u.URL, content)
+		content = p.scrubContent(u.URL, content)
+
+		if _, err := fmt.Fprintf(w, "%s\n", formatHeader(p.fileHeader, u.URL, meta)); err != nil {
+			return err
+		}
+		if p.printLineNumbers {
+			if err := p.writeContentWithLineNumbers(w, content); err != nil {
+				return err
+			}
+		} else if _, err := w.Write(content); err != nil {
+			return err
+		}
+		if _, err := w.WriteString("\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
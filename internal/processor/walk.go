@@ -0,0 +1,76 @@
+package processor
+
+import (
+	"io/fs"
+	"sync"
+)
+
+// Default walk parallelism: directories read and files stat'ed at once. Local
+// disks barely notice, but on high-latency filesystems (network shares, WSL
+// /mnt paths) a serial walk spends most of its time waiting on round trips.
+const (
+	DefaultWalkConcurrency = 8
+	DefaultStatBatch       = 32
+)
+
+// dirListing is the listing of a directory, read ahead of the walk reaching it.
+type dirListing struct {
+	done    chan struct{}
+	entries []fs.DirEntry
+	err     error
+}
+
+// listDir starts reading a directory, taking one of slots while reading it.
+// With a serial walk, the directory is read right away.
+func (p *Processor) listDir(dir string, slots chan struct{}) *dirListing {
+	l := &dirListing{done: make(chan struct{})}
+	read := func() {
+		defer close(l.done)
+		l.entries, l.err = withTimeout(p.readTimeout, func() ([]fs.DirEntry, error) { return fs.ReadDir(p.fsys, dir) })
+	}
+	if p.walkConcurrency <= 1 {
+		read()
+		return l
+	}
+	go func() {
+		slots <- struct{}{}
+		defer func() { <-slots }()
+		read()
+	}()
+	return l
+}
+
+// statResult is the outcome of stat'ing a file.
+type statResult struct {
+	info fs.FileInfo
+	err  error
+}
+
+// statAll stats files of the project, up to statBatch at once, returning the
+// results in the same order.
+func (p *Processor) statAll(names []string) []statResult {
+	results := make([]statResult, len(names))
+	stat := func(i int) {
+		results[i].info, results[i].err = withTimeout(p.readTimeout, func() (fs.FileInfo, error) { return fs.Stat(p.fsys, names[i]) })
+	}
+	if p.statBatch <= 1 {
+		for i := range names {
+			stat(i)
+		}
+		return results
+	}
+
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, p.statBatch)
+	for i := range names {
+		slots <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			stat(i)
+		}()
+	}
+	wg.Wait()
+	return results
+}
@@ -0,0 +1,121 @@
+// Package profile writes pprof profiles and execution traces of a run, and
+// times its phases, to diagnose performance issues in large projects.
+package profile
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+	"strings"
+	"time"
+)
+
+// Profile kinds
+const (
+	CPU   = "cpu"   // CPU profile (pprof)
+	Mem   = "mem"   // Heap profile at the end of the run (pprof)
+	Trace = "trace" // Execution trace (go tool trace)
+)
+
+// Modes lists the supported profile kinds.
+var Modes = []string{CPU, Mem, Trace}
+
+// FileName returns the name of the file a profile is written to.
+func FileName(mode string) string {
+	if mode == Trace {
+		return "sandworm.trace"
+	}
+	return "sandworm-" + mode + ".pprof"
+}
+
+// Start starts profiling, writing to path. The returned function stops it and
+// completes the file.
+func Start(mode, path string) (func() error, error) {
+	switch mode {
+	case CPU, Mem, Trace:
+	default:
+		return nil, fmt.Errorf("invalid profile '%s' (must be one of %s)", mode, strings.Join(Modes, ", "))
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create profile: %w", err)
+	}
+	switch mode {
+	case CPU:
+		if err := pprof.StartCPUProfile(f); err != nil {
+			_ = f.Close()
+			return nil, fmt.Errorf("failed to start CPU profile: %w", err)
+		}
+	case Trace:
+		if err := trace.Start(f); err != nil {
+			_ = f.Close()
+			return nil, fmt.Errorf("failed to start trace: %w", err)
+		}
+	}
+
+	return func() error {
+		var err error
+		switch mode {
+		case CPU:
+			pprof.StopCPUProfile()
+		case Mem:
+			runtime.GC() // Up-to-date statistics
+			err = pprof.WriteHeapProfile(f)
+		case Trace:
+			trace.Stop()
+		}
+		return errors.Join(err, f.Close())
+	}, nil
+}
+
+// Timings records the duration of the phases of a run.
+type Timings struct {
+	start  time.Time
+	phases []phase
+}
+
+type phase struct {
+	name     string
+	duration time.Duration
+}
+
+// NewTimings starts timing a run.
+func NewTimings() *Timings {
+	return &Timings{start: time.Now()}
+}
+
+// Add adds time to a phase, in order of first appearance.
+func (t *Timings) Add(name string, d time.Duration) {
+	for i := range t.phases {
+		if t.phases[i].name == name {
+			t.phases[i].duration += d
+			return
+		}
+	}
+	t.phases = append(t.phases, phase{name, d})
+}
+
+// String renders the phases with their share of the total run time, the
+// rest of which is reported as "other".
+func (t *Timings) String() string {
+	total := time.Since(t.start)
+	phases := t.phases
+	var timed time.Duration
+	for _, p := range phases {
+		timed += p.duration
+	}
+	if other := total - timed; other > 0 {
+		phases = append(phases[:len(phases):len(phases)], phase{"other", other})
+	}
+
+	var b strings.Builder
+	for _, p := range phases {
+		fmt.Fprintf(&b, "  %-8s %10s %5.1f%%\n", p.name, p.duration.Round(time.Microsecond), 100*float64(p.duration)/float64(total))
+	}
+	fmt.Fprintf(&b, "  %-8s %10s", "total", total.Round(time.Microsecond))
+	return b.String()
+}
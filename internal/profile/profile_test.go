@@ -0,0 +1,51 @@
+package profile
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStart(t *testing.T) {
+	for _, mode := range Modes {
+		t.Run(mode, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), FileName(mode))
+			stop, err := Start(mode, path)
+			if err != nil {
+				t.Fatalf("Start failed: %v", err)
+			}
+			if err := stop(); err != nil {
+				t.Fatalf("stop failed: %v", err)
+			}
+			if info, err := os.Stat(path); err != nil || info.Size() == 0 {
+				t.Errorf("Expected a profile in %s, got %v", path, err)
+			}
+		})
+	}
+
+	if _, err := Start("heap", filepath.Join(t.TempDir(), "x")); err == nil {
+		t.Error("Expected an error for an unknown profile")
+	}
+}
+
+func TestTimings(t *testing.T) {
+	timings := &Timings{start: time.Now().Add(-time.Second)}
+	timings.Add("walk", 100*time.Millisecond)
+	timings.Add("read", 200*time.Millisecond)
+	timings.Add("walk", 100*time.Millisecond)
+
+	lines := strings.Split(timings.String(), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("Expected walk, read, other and total, got:\n%s", timings.String())
+	}
+	for i, want := range []string{"walk", "read", "other", "total"} {
+		if fields := strings.Fields(lines[i]); fields[0] != want {
+			t.Errorf("Expected line %d to be %s, got %q", i, want, lines[i])
+		}
+	}
+	if !strings.Contains(lines[0], "200ms") || !strings.Contains(lines[0], "20.0%") {
+		t.Errorf("Expected walk to add up to 200ms (20%%), got %q", lines[0])
+	}
+}
@@ -0,0 +1,52 @@
+package report
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRecord(t *testing.T) {
+	path := LogPath(t.TempDir())
+	for i := range logSize + 5 {
+		args := []string{"push", "--project", "my project"}
+		if i == logSize+4 {
+			args = []string{"config", "set", "accounts.work", "sk-ant-REDACTED"}
+		}
+		if err := Record(path, args, 3, errors.New("API request failed:\n403")); err != nil {
+			t.Fatalf("Failed to record: %v", err)
+		}
+	}
+
+	lines, err := Tail(path, logSize*2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != logSize {
+		t.Errorf("Expected the log to keep %d lines, got %d", logSize, len(lines))
+	}
+	if !strings.HasSuffix(lines[0], ` exit=3 sandworm push --project "my project": API request failed: 403`) {
+		t.Errorf("Unexpected log line: %q", lines[0])
+	}
+	if last := lines[len(lines)-1]; strings.Contains(last, "abcdefghij") {
+		t.Errorf("Expected the session key to be masked, got %q", last)
+	}
+
+	if lines, err := Tail(filepath.Join(t.TempDir(), "missing.log"), 10); lines != nil || err != nil {
+		t.Errorf("Expected no lines for a missing log, got %v, %v", lines, err)
+	}
+}
+
+func TestAnonymize(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip("No home directory")
+	}
+	input := "alice@example.com pushed " + filepath.Join(home, "src", "app") + " to 0f8fad5b-d9cb-469f-a165-70867728950e from postgres://app:hunter2@db/app"
+	want := "[EMAIL] pushed " + filepath.Join("~", "src", "app") + " to [ID] from postgres://[CREDENTIALS]@db/app"
+	if got := Anonymize(input); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,155 @@
+// Package sanitize removes or escapes characters that can corrupt the
+// formatting of a bundle or hide content from human reviewers: ANSI escape
+// sequences, control characters, zero-width and bidirectional formatting
+// characters, and Unicode tags (which can smuggle invisible ASCII text).
+package sanitize
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Sanitization modes
+const (
+	Off    = "off"    // Leave contents as is (default)
+	Strip  = "strip"  // Remove the characters
+	Escape = "escape" // Replace them with a visible escape, e.g. \u200b
+)
+
+// Modes lists the valid sanitization modes.
+var Modes = []string{Off, Strip, Escape}
+
+// Validate checks a sanitization mode.
+func Validate(mode string) error {
+	switch mode {
+	case "", Off, Strip, Escape:
+		return nil
+	}
+	return fmt.Errorf("invalid sanitize mode %q (must be one of %s)", mode, strings.Join(Modes, ", "))
+}
+
+// Sanitize strips or escapes unsafe characters in content, returning the new
+// content and the number of characters (or escape sequences) handled.
+func Sanitize(content []byte, mode string) ([]byte, int) {
+	if mode != Strip && mode != Escape {
+		return content, 0
+	}
+
+	var out []byte // Allocated on the first unsafe character
+	count := 0
+	for i := 0; i < len(content); {
+		r, size := utf8.DecodeRune(content[i:])
+		if r == utf8.RuneError && size <= 1 {
+			// Invalid UTF-8 is left alone (e.g. Latin-1 files)
+			if out != nil {
+				out = append(out, content[i])
+			}
+			i++
+			continue
+		}
+
+		n := size
+		if r == 0x1b && mode == Strip {
+			n = ansiSequenceLength(content[i:])
+		}
+		if !unsafe(r) {
+			if out != nil {
+				out = append(out, content[i:i+n]...)
+			}
+			i += n
+			continue
+		}
+
+		if out == nil {
+			out = append(make([]byte, 0, len(content)), content[:i]...)
+		}
+		if mode == Escape {
+			out = append(out, escape(r)...)
+		}
+		count++
+		i += n
+	}
+	if out == nil {
+		return content, 0
+	}
+	return out, count
+}
+
+// MARK: Helpers
+
+// unsafe reports whether a character is stripped or escaped. Tabs, line feeds
+// and carriage returns are kept.
+func unsafe(r rune) bool {
+	switch {
+	case r == '\t' || r == '\n' || r == '\r':
+		return false
+	case r < 0x20 || r == 0x7f: // C0 controls, DEL
+		return true
+	case r >= 0x80 && r <= 0x9f: // C1 controls
+		return true
+	case r == 0xad: // Soft hyphen
+		return true
+	case r >= 0x200b && r <= 0x200f: // Zero-width characters, LRM/RLM
+		return true
+	case r >= 0x202a && r <= 0x202e: // Bidirectional embeddings/overrides
+		return true
+	case r >= 0x2060 && r <= 0x2069: // Word joiner, invisible operators, isolates
+		return true
+	case r == 0xfeff: // Zero-width no-break space (BOM)
+		return true
+	case r >= 0xe0000 && r <= 0xe007f: // Tags
+		return true
+	}
+	return false
+}
+
+// escape returns the visible escape of a character.
+func escape(r rune) string {
+	switch {
+	case r <= 0xff:
+		return fmt.Sprintf(`\x%02x`, r)
+	case r <= 0xffff:
+		return fmt.Sprintf(`\u%04x`, r)
+	default:
+		return fmt.Sprintf(`\U%08x`, r)
+	}
+}
+
+// ansiSequenceLength returns the length of the ANSI escape sequence starting
+// at b (which starts with ESC): CSI sequences (ESC [ ... final byte), OSC
+// sequences (ESC ] ... BEL or ESC \), or ESC followed by a single character.
+func ansiSequenceLength(b []byte) int {
+	if len(b) < 2 {
+		return 1
+	}
+	switch b[1] {
+	case '[':
+		for i := 2; i < len(b); i++ {
+			if b[i] >= 0x40 && b[i] <= 0x7e {
+				return i + 1
+			}
+			if b[i] < 0x20 || b[i] > 0x3f {
+				return i // Malformed: stop before the offending byte
+			}
+		}
+		return len(b)
+	case ']':
+		for i := 2; i < len(b); i++ {
+			if b[i] == 0x07 {
+				return i + 1
+			}
+			if b[i] == 0x1b && i+1 < len(b) && b[i+1] == '\\' {
+				return i + 2
+			}
+			if b[i] == '\n' {
+				return i
+			}
+		}
+		return len(b)
+	}
+	if b[1] >= 0x20 && b[1] <= 0x7e {
+		return 2
+	}
+	return 1
+}
@@ -0,0 +1,38 @@
+package sanitize
+
+import "testing"
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		mode    string
+		want    string
+		count   int
+	}{
+		{"off", "a\x1b[31mb", Off, "a\x1b[31mb", 0},
+		{"clean", "func main() {\n\treturn\r\n}", Strip, "func main() {\n\treturn\r\n}", 0},
+		{"ansi colors", "\x1b[1;31mERROR\x1b[0m done", Strip, "ERROR done", 2},
+		{"osc hyperlink", "\x1b]8;;https://x.io\x07link\x1b]8;;\x1b\\", Strip, "link", 2},
+		{"control bytes", "a\x00b\x08c\x7fd", Strip, "abcd", 3},
+		{"zero-width", "ig\u200bnore\u2060 previous", Strip, "ignore previous", 2},
+		{"bidi override", "access = \u202euser\u2066", Strip, "access = user", 2},
+		{"tags", "hi\U000E0069\U000E0067", Strip, "hi", 2},
+		{"bom", "\ufeffpackage main", Strip, "package main", 1},
+		{"escape", "a\x1b[31mb\u200bc\U000E0041", Escape, `a\x1b[31mb\u200bc\U000e0041`, 3},
+		{"unicode kept", "héllo wörld 日本 👋", Strip, "héllo wörld 日本 👋", 0},
+		{"invalid utf-8 kept", "caf\xe9\x00", Strip, "caf\xe9", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, count := Sanitize([]byte(tt.content), tt.mode)
+			if string(got) != tt.want || count != tt.count {
+				t.Errorf("Expected %q (%d), got %q (%d)", tt.want, tt.count, got, count)
+			}
+		})
+	}
+
+	if err := Validate("remove"); err == nil {
+		t.Error("Expected an error for an invalid mode")
+	}
+}
@@ -0,0 +1,117 @@
+// Package scrub masks personally identifiable information (emails, phone
+// numbers and custom patterns such as names) and credentials in file contents.
+package scrub
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Categories of scrubbed values
+const (
+	Email  = "email"
+	Phone  = "phone"
+	Custom = "custom"
+	Secret = "secret"
+)
+
+// rule masks the matches of a pattern with a placeholder.
+type rule struct {
+	category    string
+	pattern     *regexp.Regexp
+	replacement string
+}
+
+// builtinRules are always applied. Phone numbers require separators or a
+// leading "+" so that plain numbers (IDs, timestamps) are left alone.
+var builtinRules = []rule{
+	{Email, regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}`), "[EMAIL]"},
+	{Phone, regexp.MustCompile(`\+\d{1,3}(?:[ .-]?\(?\d{1,4}\)?){2,5}\d`), "[PHONE]"},
+	{Phone, regexp.MustCompile(`\(?\b\d{3}\)?[ .-]\d{3}[ .-]\d{4}\b`), "[PHONE]"},
+}
+
+// secretRules match credentials in formats distinctive enough to be masked
+// without false positives: private keys and the tokens of common services.
+// Generic assignments (password = "...") are left alone.
+var secretRules = []rule{
+	{Secret, regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`), "[PRIVATE KEY]"},
+	{Secret, regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`), "[AWS KEY]"},
+	{Secret, regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`), "[GITHUB TOKEN]"},
+	{Secret, regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`), "[SLACK TOKEN]"},
+	{Secret, regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`), "[GOOGLE KEY]"},
+	{Secret, regexp.MustCompile(`\b[rs]k_live_[0-9A-Za-z]{16,}`), "[STRIPE KEY]"},
+	{Secret, regexp.MustCompile(`\bsk-[A-Za-z0-9_-]{20,}`), "[API KEY]"}, // Anthropic, OpenAI
+}
+
+// Counts maps categories to the number of replaced values.
+type Counts map[string]int
+
+// Total returns the number of replaced values across categories.
+func (c Counts) Total() int {
+	total := 0
+	for _, n := range c {
+		total += n
+	}
+	return total
+}
+
+// String summarizes counts, e.g. "3 email, 1 phone".
+func (c Counts) String() string {
+	categories := make([]string, 0, len(c))
+	for category := range c {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+
+	parts := make([]string, len(categories))
+	for i, category := range categories {
+		parts[i] = fmt.Sprintf("%d %s", c[category], category)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Scrubber masks PII or secrets in contents.
+type Scrubber struct {
+	rules []rule
+}
+
+// New creates a scrubber applying the builtin rules plus a custom pattern (a
+// regular expression, e.g. "Jane Doe|John Smith"), if not empty.
+func New(custom string) (*Scrubber, error) {
+	rules := append([]rule(nil), builtinRules...)
+	if custom != "" {
+		re, err := regexp.Compile(custom)
+		if err != nil {
+			return nil, fmt.Errorf("invalid scrub pattern: %w", err)
+		}
+		rules = append(rules, rule{Custom, re, "[REDACTED]"})
+	}
+	return &Scrubber{rules: rules}, nil
+}
+
+// NewSecrets creates a scrubber masking credentials (private keys, cloud and
+// service tokens).
+func NewSecrets() *Scrubber {
+	return &Scrubber{rules: secretRules}
+}
+
+// ValidatePattern checks that a custom pattern compiles.
+func ValidatePattern(pattern string) error {
+	_, err := New(pattern)
+	return err
+}
+
+// Scrub returns content with its matches masked, and the number of replacements per
+// category.
+func (s *Scrubber) Scrub(content []byte) ([]byte, Counts) {
+	counts := Counts{}
+	for _, r := range s.rules {
+		content = r.pattern.ReplaceAllFunc(content, func([]byte) []byte {
+			counts[r.category]++
+			return []byte(r.replacement)
+		})
+	}
+	return content, counts
+}
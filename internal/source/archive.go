@@ -0,0 +1,321 @@
+package source
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"time"
+)
+
+// archiveLimits bounds what an archive may expand to, guarding against
+// archive bombs.
+type archiveLimits struct {
+	size    int64 // Total uncompressed size of the files
+	entries int   // Number of entries, directories included
+	ratio   int64 // Total uncompressed size over the archive's size
+}
+
+// defaultArchiveLimits are the limits of Archive. Tar files are held in
+// memory, hence the size limit.
+var defaultArchiveLimits = archiveLimits{size: 1 << 30, entries: 100_000, ratio: 100}
+
+// ratioFloor is the uncompressed size under which the compression ratio isn't
+// checked: small archives of repetitive files compress very well.
+const ratioFloor = 10 << 20
+
+// ArchiveFS is the file system of a zip or tar archive. Zip files are read
+// from the archive as needed, tar files are read upfront. Only regular files
+// and directories are included; links and special files are left out.
+type ArchiveFS struct {
+	entries map[string]*archiveEntry // By slash-separated path, "." for the root
+	closer  io.Closer                // The archive file, if still read from
+}
+
+// Archive opens a zip or tar (optionally gzip/bzip2-compressed) archive as a
+// file system. The format is detected from the contents, not the file name.
+func Archive(archivePath string) (*ArchiveFS, error) {
+	return openArchive(archivePath, defaultArchiveLimits)
+}
+
+// Close releases the archive.
+func (a *ArchiveFS) Close() error {
+	if a.closer == nil {
+		return nil
+	}
+	return a.closer.Close()
+}
+
+// Open opens a file or directory of the archive.
+func (a *ArchiveFS) Open(name string) (fs.File, error) {
+	e, err := a.entry("open", name)
+	if err != nil {
+		return nil, err
+	}
+	if e.IsDir() {
+		return &archiveDir{archiveEntry: e}, nil
+	}
+	if e.zf == nil {
+		return &archiveFile{archiveEntry: e, r: io.NopCloser(bytes.NewReader(e.data))}, nil
+	}
+	r, err := e.zf.Open()
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	return &archiveFile{archiveEntry: e, r: r}, nil
+}
+
+// ReadDir returns the entries of a directory of the archive, sorted by name.
+func (a *ArchiveFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	e, err := a.entry("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	if !e.IsDir() {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: errors.New("not a directory")}
+	}
+	return slices.Clone(e.children), nil
+}
+
+// MARK: Helpers
+
+// archiveEntry is a file or directory of an archive.
+type archiveEntry struct {
+	name     string
+	mode     fs.FileMode
+	size     int64
+	modTime  time.Time
+	data     []byte        // Contents of tar files
+	zf       *zip.File     // Zip files
+	children []fs.DirEntry // Entries of directories, sorted by name
+}
+
+func (e *archiveEntry) Name() string               { return e.name }
+func (e *archiveEntry) Size() int64                { return e.size }
+func (e *archiveEntry) Mode() fs.FileMode          { return e.mode }
+func (e *archiveEntry) ModTime() time.Time         { return e.modTime }
+func (e *archiveEntry) IsDir() bool                { return e.mode.IsDir() }
+func (e *archiveEntry) Sys() any                   { return nil }
+func (e *archiveEntry) Type() fs.FileMode          { return e.mode.Type() }
+func (e *archiveEntry) Info() (fs.FileInfo, error) { return e, nil }
+
+// archiveFile is an open file of an archive.
+type archiveFile struct {
+	*archiveEntry
+	r io.ReadCloser
+}
+
+func (f *archiveFile) Stat() (fs.FileInfo, error) { return f.archiveEntry, nil }
+func (f *archiveFile) Read(b []byte) (int, error) { return f.r.Read(b) }
+func (f *archiveFile) Close() error               { return f.r.Close() }
+
+// archiveDir is an open directory of an archive.
+type archiveDir struct {
+	*archiveEntry
+	offset int
+}
+
+func (d *archiveDir) Stat() (fs.FileInfo, error) { return d.archiveEntry, nil }
+func (d *archiveDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.name, Err: errors.New("is a directory")}
+}
+func (d *archiveDir) Close() error { return nil }
+
+func (d *archiveDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	rest := d.children[d.offset:]
+	if n > 0 {
+		if len(rest) == 0 {
+			return nil, io.EOF
+		}
+		rest = rest[:min(n, len(rest))]
+	}
+	d.offset += len(rest)
+	return slices.Clone(rest), nil
+}
+
+// entry returns the entry of a path.
+func (a *ArchiveFS) entry(op, name string) (*archiveEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	e, ok := a.entries[name]
+	if !ok {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+	return e, nil
+}
+
+// add adds an entry of the archive by its (cleaned) path, creating its parent
+// directories. Entries clashing with existing ones are left out.
+func (a *ArchiveFS) add(name string, e *archiveEntry) {
+	if _, ok := a.entries[name]; ok {
+		return
+	}
+	parent := a.dir(path.Dir(name))
+	if parent == nil {
+		return
+	}
+	e.name = path.Base(name)
+	a.entries[name] = e
+	parent.children = append(parent.children, e)
+}
+
+// dir returns the directory of a path, creating it (and its parents) if
+// needed, or nil if a file is in the way.
+func (a *ArchiveFS) dir(name string) *archiveEntry {
+	if e, ok := a.entries[name]; ok {
+		if !e.IsDir() {
+			return nil
+		}
+		return e
+	}
+	parent := a.dir(path.Dir(name))
+	if parent == nil {
+		return nil
+	}
+	e := &archiveEntry{name: path.Base(name), mode: fs.ModeDir | 0o755}
+	a.entries[name] = e
+	parent.children = append(parent.children, e)
+	return e
+}
+
+// sortChildren sorts the entries of each directory by name.
+func (a *ArchiveFS) sortChildren() {
+	for _, e := range a.entries {
+		slices.SortFunc(e.children, func(x, y fs.DirEntry) int { return strings.Compare(x.Name(), y.Name()) })
+	}
+}
+
+// openArchive opens an archive as a file system, within limits.
+func openArchive(archivePath string, limits archiveLimits) (*ArchiveFS, error) {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+
+	a := &ArchiveFS{entries: map[string]*archiveEntry{
+		".": {name: ".", mode: fs.ModeDir | 0o755},
+	}}
+	header := make([]byte, 4)
+	n, _ := io.ReadFull(f, header)
+	if bytes.HasPrefix(header[:n], []byte("PK\x03\x04")) {
+		if err := a.readZip(f, info.Size(), limits); err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+	} else {
+		// Tar archives are read in full
+		defer func() { _ = f.Close() }()
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return nil, fmt.Errorf("failed to read archive: %w", err)
+		}
+		if err := a.readTar(f, info.Size(), limits); err != nil {
+			return nil, err
+		}
+	}
+	a.sortChildren()
+	return a, nil
+}
+
+// readZip indexes the entries of a zip archive, which are read as needed.
+// Their declared sizes are checked against the limits: the zip reader fails
+// on entries expanding to more.
+func (a *ArchiveFS) readZip(f *os.File, size int64, limits archiveLimits) error {
+	zr, err := zip.NewReader(f, size)
+	if err != nil {
+		return fmt.Errorf("failed to read zip archive: %w", err)
+	}
+	if len(zr.File) > limits.entries {
+		return fmt.Errorf("archive has more than %d entries", limits.entries)
+	}
+
+	var total int64
+	for _, zf := range zr.File {
+		name, ok := entryName(zf.Name)
+		if !ok {
+			continue
+		}
+		switch {
+		case zf.FileInfo().IsDir():
+			a.dir(name)
+		case zf.Mode().IsRegular():
+			if zf.UncompressedSize64 > uint64(limits.size) {
+				return fmt.Errorf("archive expands to more than %d bytes", limits.size)
+			}
+			total += int64(zf.UncompressedSize64)
+			if err := checkExpansion(total, size, limits); err != nil {
+				return err
+			}
+			a.add(name, &archiveEntry{mode: 0o644, size: int64(zf.UncompressedSize64), modTime: zf.Modified, zf: zf})
+		}
+	}
+	a.closer = f
+	return nil
+}
+
+// readTar reads the files of a tar archive (optionally gzip/bzip2-compressed)
+// into memory.
+func (a *ArchiveFS) readTar(r io.Reader, size int64, limits archiveLimits) error {
+	tr, err := tarReader(r)
+	if err != nil {
+		return err
+	}
+
+	var total int64
+	for count := 1; ; count++ {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read tar archive: %w", err)
+		}
+		if count > limits.entries {
+			return fmt.Errorf("archive has more than %d entries", limits.entries)
+		}
+
+		name, ok := entryName(hdr.Name)
+		if !ok {
+			continue
+		}
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			a.dir(name)
+		case tar.TypeReg:
+			// Headers can lie about sizes: read one byte more than allowed
+			data, err := io.ReadAll(io.LimitReader(tr, limits.size-total+1))
+			if err != nil {
+				return fmt.Errorf("failed to read tar archive: %w", err)
+			}
+			total += int64(len(data))
+			if err := checkExpansion(total, size, limits); err != nil {
+				return err
+			}
+			a.add(name, &archiveEntry{mode: 0o644, size: int64(len(data)), modTime: hdr.ModTime, data: data})
+		}
+	}
+}
+
+// checkExpansion checks the uncompressed size of an archive so far against
+// the limits.
+func checkExpansion(total, size int64, limits archiveLimits) error {
+	if total > limits.size {
+		return fmt.Errorf("archive expands to more than %d bytes", limits.size)
+	}
+	if total > ratioFloor && total > size*limits.ratio {
+		return fmt.Errorf("archive expands more than %d times its size", limits.ratio)
+	}
+	return nil
+}
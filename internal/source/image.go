@@ -0,0 +1,132 @@
+package source
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// containerTools are the CLIs that can export image filesystems, in order of
+// preference. Podman is CLI-compatible with docker for what's needed here.
+var containerTools = []string{"docker", "podman"}
+
+// Image extracts paths (absolute, e.g. "/app") of an OCI image's filesystem,
+// pulling the image if needed. Without paths, the image's working directory
+// is used. With a single path, Dir is that path's directory.
+func Image(ref string, paths []string) (*Extracted, error) {
+	tool, err := containerTool()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(paths) == 0 {
+		workDir, err := output(tool, "image", "inspect", "--format", "{{.Config.WorkingDir}}", ref)
+		if err != nil {
+			// The image may not have been pulled yet
+			if _, pullErr := output(tool, "pull", ref); pullErr != nil {
+				return nil, pullErr
+			}
+			if workDir, err = output(tool, "image", "inspect", "--format", "{{.Config.WorkingDir}}", ref); err != nil {
+				return nil, err
+			}
+		}
+		if workDir == "" || workDir == "/" {
+			return nil, errors.New("the image has no working directory, select paths to bundle (e.g. /app)")
+		}
+		paths = []string{workDir}
+	}
+
+	// Export the filesystem of a (never started) container
+	id, err := output(tool, "create", ref)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = exec.Command(tool, "rm", id).Run() }()
+
+	e, err := newExtracted()
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.Command(tool, "export", id)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		_ = e.Close()
+		return nil, fmt.Errorf("failed to export image: %w", err)
+	}
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	if err := cmd.Start(); err != nil {
+		_ = e.Close()
+		return nil, fmt.Errorf("failed to export image: %w", err)
+	}
+	extractErr := ExtractTar(stdout, e.Dir, imagePathFilter(paths))
+	if extractErr != nil {
+		_ = cmd.Process.Kill()
+	} else {
+		_, _ = io.Copy(io.Discard, stdout) // Trailing padding
+	}
+	if err := cmd.Wait(); err != nil && extractErr == nil {
+		_ = e.Close()
+		return nil, fmt.Errorf("failed to export image: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	if extractErr != nil {
+		_ = e.Close()
+		return nil, extractErr
+	}
+
+	if len(paths) == 1 {
+		dir := filepath.Join(e.Dir, filepath.FromSlash(strings.TrimPrefix(path.Clean("/"+paths[0]), "/")))
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			_ = e.Close()
+			return nil, fmt.Errorf("directory %s not found in the image", paths[0])
+		}
+		e.Dir = dir
+	}
+	return e, nil
+}
+
+// MARK: Helpers
+
+// containerTool returns the first available container CLI.
+func containerTool() (string, error) {
+	for _, tool := range containerTools {
+		if _, err := exec.LookPath(tool); err == nil {
+			return tool, nil
+		}
+	}
+	return "", fmt.Errorf("reading images requires %s", strings.Join(containerTools, " or "))
+}
+
+// imagePathFilter accepts entries (relative to the image root) within paths.
+func imagePathFilter(paths []string) func(string) bool {
+	prefixes := make([]string, len(paths))
+	for i, p := range paths {
+		prefixes[i] = strings.TrimPrefix(path.Clean("/"+p), "/")
+	}
+	return func(name string) bool {
+		for _, prefix := range prefixes {
+			if prefix == "" || name == prefix || strings.HasPrefix(name, prefix+"/") {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// output runs a command, returning its trimmed output.
+func output(name string, args ...string) (string, error) {
+	out, err := exec.Command(name, args...).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return "", fmt.Errorf("%s %s failed: %s", name, args[0], strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", fmt.Errorf("%s %s failed: %w", name, args[0], err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
@@ -0,0 +1,171 @@
+package source
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+// remoteConfigFiles are fetched before the other files, so that the ignore
+// rules can be applied before transferring anything else.
+var remoteConfigFiles = []string{".sandwormignore", ".gitignore", ".gitattributes", ".gitmodules"}
+
+// remoteTools are the commands run on remote hosts, besides a POSIX shell.
+var remoteTools = []string{"find", "tar"}
+
+// remoteRE matches scp-like remote targets: [user@]host:/path.
+var remoteRE = regexp.MustCompile(`^((?:[^@/:\s]+@)?[^@/:\s]+):(.*)$`)
+
+// IsRemote reports whether a directory argument is a remote target of the
+// form [user@]host:/path. Existing local paths and Windows drive letters are
+// never remote.
+func IsRemote(arg string) bool {
+	if _, err := os.Stat(arg); err == nil {
+		return false
+	}
+	match := remoteRE.FindStringSubmatch(arg)
+	return match != nil && len(match[1]) > 1
+}
+
+// Remote copies a remote directory ([user@]host:/path) over ssh, using tar on
+// both ends: the host must run a POSIX shell, with find and tar (checked
+// before anything else). The remote configuration files (ignore rules, attributes) are
+// fetched first into the directory, then filter selects the files (relative,
+// slash-separated paths) to transfer.
+func Remote(target string, filter func(dir string, files []string) []string) (*Extracted, error) {
+	match := remoteRE.FindStringSubmatch(target)
+	if match == nil {
+		return nil, fmt.Errorf("invalid remote target %q (expected [user@]host:/path)", target)
+	}
+	host, dir := match[1], match[2]
+	if dir == "" {
+		dir = "."
+	}
+	if _, err := exec.LookPath("ssh"); err != nil {
+		return nil, errors.New("reading remote directories requires ssh")
+	}
+	if err := checkRemote(host); err != nil {
+		return nil, err
+	}
+
+	// List the remote files
+	listing, err := remoteOutput(host, fmt.Sprintf("cd %s && find . -type f ! -path './.git/*'", shellQuote(dir)))
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, line := range strings.Split(string(listing), "\n") {
+		if name, ok := entryName(strings.TrimPrefix(line, "./")); ok && line != "" {
+			files = append(files, name)
+		}
+	}
+
+	e, err := newExtracted()
+	if err != nil {
+		return nil, err
+	}
+
+	var config []string
+	for _, name := range remoteConfigFiles {
+		for _, file := range files {
+			if file == name {
+				config = append(config, name)
+			}
+		}
+	}
+	if err := e.fetch(host, dir, config); err != nil {
+		_ = e.Close()
+		return nil, err
+	}
+
+	if err := e.fetch(host, dir, filter(e.Dir, files)); err != nil {
+		_ = e.Close()
+		return nil, err
+	}
+	return e, nil
+}
+
+// MARK: Helpers
+
+// checkRemote checks that a host runs a POSIX shell with remoteTools, so that
+// hosts that don't fail with a clear error rather than halfway through.
+func checkRemote(host string) error {
+	requirement := "reading remote directories requires a POSIX shell with " + strings.Join(remoteTools, " and ")
+	probe := fmt.Sprintf(`echo sandworm; for tool in %s; do command -v "$tool" >/dev/null 2>&1 || echo "$tool"; done`, strings.Join(remoteTools, " "))
+	cmd := remoteCommand(host, probe)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	// ssh exits with 255 when it can't connect, and with the status of the
+	// command otherwise, which other shells likely fail to run
+	var exitErr *exec.ExitError
+	if err != nil && (!errors.As(err, &exitErr) || exitErr.ExitCode() == 255) {
+		return fmt.Errorf("ssh %s failed: %w: %s", host, err, strings.TrimSpace(stderr.String()))
+	}
+
+	lines := strings.Fields(string(out))
+	if err != nil || len(lines) == 0 || lines[0] != "sandworm" {
+		return fmt.Errorf("%s doesn't run a POSIX shell (%s)", host, requirement)
+	}
+	if missing := lines[1:]; len(missing) > 0 {
+		return fmt.Errorf("%s lacks %s (%s)", host, strings.Join(missing, " and "), requirement)
+	}
+	return nil
+}
+
+// fetch transfers remote files (relative to dir) into the extracted directory.
+func (e *Extracted) fetch(host, dir string, files []string) error {
+	if len(files) == 0 {
+		return nil
+	}
+
+	cmd := remoteCommand(host, fmt.Sprintf("cd %s && tar -cf - -T -", shellQuote(dir)))
+	cmd.Stdin = strings.NewReader(strings.Join(files, "\n") + "\n")
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to transfer files: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to transfer files: %w", err)
+	}
+
+	extractErr := ExtractTar(stdout, e.Dir, nil)
+	if extractErr != nil {
+		_ = cmd.Process.Kill()
+	} else {
+		_, _ = io.Copy(io.Discard, stdout) // Trailing padding
+	}
+	if err := cmd.Wait(); err != nil && extractErr == nil {
+		return fmt.Errorf("ssh %s failed: %w: %s", host, err, strings.TrimSpace(stderr.String()))
+	}
+	return extractErr
+}
+
+// remoteCommand returns the command running a shell command on a host. Batch
+// mode makes ssh fail instead of prompting for passwords mid-transfer.
+func remoteCommand(host, command string) *exec.Cmd {
+	return exec.Command("ssh", "-o", "BatchMode=yes", host, command)
+}
+
+// remoteOutput runs a shell command on a host over ssh, returning its output.
+func remoteOutput(host, command string) ([]byte, error) {
+	cmd := remoteCommand(host, command)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("ssh %s failed: %w: %s", host, err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+// shellQuote quotes a string for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,120 @@
+// Package source materializes inputs other than local directories (archives,
+// container images, remote directories) as file systems or temporary
+// directories that can be processed like any project.
+package source
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Extracted is a temporary directory holding the files of an input.
+type Extracted struct {
+	Dir string // Root of the input's files
+
+	tmp string // Temporary directory to remove (Dir or one of its parents)
+}
+
+// Close removes the temporary directory.
+func (e *Extracted) Close() error {
+	return os.RemoveAll(e.tmp)
+}
+
+// ExtractTar extracts a tar stream (optionally gzip/bzip2-compressed) into
+// dir. If keep is set, only entries it accepts (by slash-separated path,
+// relative to the archive root) are extracted. Only regular files and
+// directories are extracted; links and special files are skipped.
+func ExtractTar(r io.Reader, dir string, keep func(string) bool) error {
+	tr, err := tarReader(r)
+	if err != nil {
+		return err
+	}
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read tar archive: %w", err)
+		}
+
+		name, ok := entryName(hdr.Name)
+		if !ok || (keep != nil && !keep(name)) {
+			continue
+		}
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(filepath.Join(dir, name), 0o755); err != nil {
+				return fmt.Errorf("failed to create directory: %w", err)
+			}
+		case tar.TypeReg:
+			if err := writeFile(filepath.Join(dir, name), tr); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// MARK: Helpers
+
+// newExtracted creates an empty temporary directory.
+func newExtracted() (*Extracted, error) {
+	dir, err := os.MkdirTemp("", "sandworm-source-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	return &Extracted{Dir: dir, tmp: dir}, nil
+}
+
+// tarReader reads a tar stream, decompressing it if it's gzip or bzip2
+// compressed.
+func tarReader(r io.Reader) (*tar.Reader, error) {
+	br := bufio.NewReader(r)
+	magic, _ := br.Peek(3)
+	switch {
+	case bytes.HasPrefix(magic, []byte{0x1f, 0x8b}):
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read gzip stream: %w", err)
+		}
+		return tar.NewReader(gz), nil
+	case bytes.HasPrefix(magic, []byte("BZh")):
+		return tar.NewReader(bzip2.NewReader(br)), nil
+	}
+	return tar.NewReader(br), nil
+}
+
+// entryName cleans an archive entry name into a relative, slash-separated
+// path, rejecting entries that would escape the extraction directory.
+func entryName(name string) (string, bool) {
+	name = path.Clean(strings.TrimLeft(strings.ReplaceAll(name, `\`, "/"), "/"))
+	if name == "." || name == ".." || strings.HasPrefix(name, "../") {
+		return "", false
+	}
+	return name, true
+}
+
+// writeFile writes a file, creating its parent directories.
+func writeFile(target string, r io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	out, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	if _, err := io.Copy(out, r); err != nil {
+		_ = out.Close()
+		return fmt.Errorf("failed to extract file: %w", err)
+	}
+	return out.Close()
+}
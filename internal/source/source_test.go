@@ -0,0 +1,327 @@
+package source
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestArchive(t *testing.T) {
+	files := map[string]string{
+		"proj/main.go":     "package main",
+		"proj/lib/lib.go":  "package lib",
+		"../escape.txt":    "outside",
+		"/abs/path.txt":    "absolute is made relative",
+		"proj/../ok.txt":   "cleaned",
+		"proj/lib/":        "",
+		"proj/empty/dir/":  "",
+		"proj/lib/data.md": "# data",
+	}
+	want := map[string]string{
+		"proj/main.go":     "package main",
+		"proj/lib/lib.go":  "package lib",
+		"abs/path.txt":     "absolute is made relative",
+		"ok.txt":           "cleaned",
+		"proj/lib/data.md": "# data",
+	}
+
+	tests := []struct {
+		name  string
+		build func(t *testing.T) []byte
+	}{
+		{"zip", func(t *testing.T) []byte {
+			var buf bytes.Buffer
+			zw := zip.NewWriter(&buf)
+			for name, content := range files {
+				w, err := zw.Create(name)
+				if err != nil {
+					t.Fatalf("Failed to create zip entry: %v", err)
+				}
+				_, _ = w.Write([]byte(content))
+			}
+			_ = zw.Close()
+			return buf.Bytes()
+		}},
+		{"tar.gz", func(t *testing.T) []byte {
+			var buf bytes.Buffer
+			gz := gzip.NewWriter(&buf)
+			tw := tar.NewWriter(gz)
+			for name, content := range files {
+				hdr := &tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}
+				if name[len(name)-1] == '/' {
+					hdr = &tar.Header{Name: name, Mode: 0o755, Typeflag: tar.TypeDir}
+				}
+				if err := tw.WriteHeader(hdr); err != nil {
+					t.Fatalf("Failed to write tar header: %v", err)
+				}
+				_, _ = tw.Write([]byte(content))
+			}
+			_ = tw.WriteHeader(&tar.Header{Name: "proj/link", Linkname: "/etc/passwd", Typeflag: tar.TypeSymlink})
+			_ = tw.Close()
+			_ = gz.Close()
+			return buf.Bytes()
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			archive := filepath.Join(t.TempDir(), "archive")
+			if err := os.WriteFile(archive, tt.build(t), 0o644); err != nil {
+				t.Fatalf("Failed to write archive: %v", err)
+			}
+
+			a, err := Archive(archive)
+			if err != nil {
+				t.Fatalf("Failed to open archive: %v", err)
+			}
+			defer func() { _ = a.Close() }()
+
+			var got []string
+			err = fs.WalkDir(a, ".", func(path string, d fs.DirEntry, err error) error {
+				if err == nil && !d.IsDir() {
+					got = append(got, path)
+				}
+				return err
+			})
+			if err != nil {
+				t.Fatalf("Failed to walk archive: %v", err)
+			}
+			if len(got) != len(want) {
+				t.Errorf("Expected files %v, got %v", want, got)
+			}
+			for name, content := range want {
+				data, err := fs.ReadFile(a, name)
+				if err != nil || string(data) != content {
+					t.Errorf("Expected %s to contain %q, got %q (%v)", name, content, data, err)
+				}
+			}
+			if err := fstest.TestFS(a, "proj/main.go", "proj/lib/data.md", "proj/empty/dir"); err != nil {
+				t.Errorf("Invalid file system: %v", err)
+			}
+		})
+	}
+
+	t.Run("close removes the directory", func(t *testing.T) {
+		e, err := newExtracted()
+		if err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		_ = e.Close()
+		if _, err := os.Stat(e.Dir); !os.IsNotExist(err) {
+			t.Error("Expected directory to be removed")
+		}
+	})
+}
+
+func TestArchiveLimits(t *testing.T) {
+	write := func(t *testing.T, build func(w io.Writer)) string {
+		var buf bytes.Buffer
+		build(&buf)
+		archive := filepath.Join(t.TempDir(), "archive")
+		if err := os.WriteFile(archive, buf.Bytes(), 0o644); err != nil {
+			t.Fatalf("Failed to write archive: %v", err)
+		}
+		return archive
+	}
+	zipOf := func(files map[string][]byte) func(w io.Writer) {
+		return func(w io.Writer) {
+			zw := zip.NewWriter(w)
+			for name, content := range files {
+				fw, _ := zw.Create(name)
+				_, _ = fw.Write(content)
+			}
+			_ = zw.Close()
+		}
+	}
+	tarGzOf := func(files map[string][]byte) func(w io.Writer) {
+		return func(w io.Writer) {
+			gz := gzip.NewWriter(w)
+			tw := tar.NewWriter(gz)
+			for name, content := range files {
+				_ = tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg})
+				_, _ = tw.Write(content)
+			}
+			_ = tw.Close()
+			_ = gz.Close()
+		}
+	}
+
+	small := map[string][]byte{"a.txt": []byte("0123456789a"), "b.txt": nil, "c.txt": nil}
+	bomb := map[string][]byte{"zeros.txt": make([]byte, ratioFloor+1)}
+	tests := []struct {
+		name   string
+		build  func(w io.Writer)
+		limits archiveLimits
+		want   string
+	}{
+		{"zip size", zipOf(small), archiveLimits{size: 10, entries: 10, ratio: 100}, "expands to more than 10 bytes"},
+		{"tar size", tarGzOf(small), archiveLimits{size: 10, entries: 10, ratio: 100}, "expands to more than 10 bytes"},
+		{"zip entries", zipOf(small), archiveLimits{size: 100, entries: 2, ratio: 100}, "more than 2 entries"},
+		{"tar entries", tarGzOf(small), archiveLimits{size: 100, entries: 2, ratio: 100}, "more than 2 entries"},
+		{"zip ratio", zipOf(bomb), defaultArchiveLimits, "expands more than 100 times its size"},
+		{"tar ratio", tarGzOf(bomb), defaultArchiveLimits, "expands more than 100 times its size"},
+		{"within limits", zipOf(small), archiveLimits{size: 100, entries: 10, ratio: 100}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := openArchive(write(t, tt.build), tt.limits)
+			if tt.want == "" {
+				if err != nil {
+					t.Fatalf("Expected the archive to open, got %v", err)
+				}
+				_ = a.Close()
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected an error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestImagePathFilter(t *testing.T) {
+	keep := imagePathFilter([]string{"/app", "etc/nginx/"})
+	for name, want := range map[string]bool{
+		"app":             true,
+		"app/main.go":     true,
+		"application/x":   false,
+		"etc/nginx/a.cnf": true,
+		"etc/passwd":      false,
+	} {
+		if got := keep(name); got != want {
+			t.Errorf("Expected keep(%q) = %v, got %v", name, want, got)
+		}
+	}
+
+	if !imagePathFilter([]string{"/"})("usr/bin/env") {
+		t.Error("Expected / to keep everything")
+	}
+}
+
+func TestIsRemote(t *testing.T) {
+	for arg, want := range map[string]bool{
+		"user@host:/srv/app": true,
+		"host:app":           true,
+		"host:":              true,
+		"C:\\src":            false,
+		"./dir":              false,
+		"src/a:b":            false,
+	} {
+		if got := IsRemote(arg); got != want {
+			t.Errorf("Expected IsRemote(%q) = %v, got %v", arg, want, got)
+		}
+	}
+}
+
+func TestRemote(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Requires a POSIX shell")
+	}
+
+	// A fake ssh running commands locally
+	bin := t.TempDir()
+	script := "#!/bin/sh\nwhile [ \"$1\" = -o ]; do shift 2; done\nshift\nexec sh -c \"$1\"\n"
+	if err := os.WriteFile(filepath.Join(bin, "ssh"), []byte(script), 0o755); err != nil {
+		t.Fatalf("Failed to write fake ssh: %v", err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	remote := t.TempDir()
+	for name, content := range map[string]string{
+		".gitignore":        "*.log\n",
+		"main.go":           "package main",
+		"debug.log":         "ignored",
+		"lib/it's.go":       "package lib",
+		".git/config":       "never listed",
+		"node_modules/x.js": "filtered",
+	} {
+		path := filepath.Join(remote, filepath.FromSlash(name))
+		_ = os.MkdirAll(filepath.Dir(path), 0o755)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	var listed []string
+	e, err := Remote("user@host:"+remote, func(dir string, files []string) []string {
+		listed = files
+		// The ignore rules are available before transferring the files
+		if _, err := os.Stat(filepath.Join(dir, ".gitignore")); err != nil {
+			t.Errorf("Expected .gitignore to be fetched first: %v", err)
+		}
+		var kept []string
+		for _, f := range files {
+			if !strings.HasSuffix(f, ".log") && !strings.HasPrefix(f, "node_modules/") {
+				kept = append(kept, f)
+			}
+		}
+		return kept
+	})
+	if err != nil {
+		t.Fatalf("Failed to copy remote directory: %v", err)
+	}
+	defer func() { _ = e.Close() }()
+
+	if len(listed) != 5 {
+		t.Errorf("Expected 5 listed files (without .git), got %v", listed)
+	}
+	for name, want := range map[string]bool{"main.go": true, "lib/it's.go": true, "debug.log": false, "node_modules/x.js": false} {
+		_, err := os.Stat(filepath.Join(e.Dir, filepath.FromSlash(name)))
+		if got := err == nil; got != want {
+			t.Errorf("Expected %s transferred = %v", name, want)
+		}
+	}
+}
+
+func TestRemoteRequirements(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Requires a POSIX shell")
+	}
+	find, err := exec.LookPath("find")
+	if err != nil {
+		t.Skip("Requires find")
+	}
+
+	// A remote host with find, but without tar
+	tools := t.TempDir()
+	if err := os.Symlink(find, filepath.Join(tools, "find")); err != nil {
+		t.Fatalf("Failed to link find: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		script string
+		want   string
+	}{
+		{"missing tool", "#!/bin/sh\nwhile [ \"$1\" = -o ]; do shift 2; done\nshift\nPATH=" + tools + " exec /bin/sh -c \"$1\"\n", "host lacks tar"},
+		{"other shell", "#!/bin/sh\nwhile [ \"$1\" = -o ]; do shift 2; done\nshift\necho \"$1\"\n", "host doesn't run a POSIX shell"},
+		{"connection failure", "#!/bin/sh\necho 'Connection refused' >&2\nexit 255\n", "Connection refused"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bin := t.TempDir()
+			if err := os.WriteFile(filepath.Join(bin, "ssh"), []byte(tt.script), 0o755); err != nil {
+				t.Fatalf("Failed to write fake ssh: %v", err)
+			}
+			t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+			_, err := Remote("host:/srv/app", func(string, []string) []string {
+				t.Error("Expected no files to be listed")
+				return nil
+			})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected an error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,302 @@
+package source
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// SourcesFile lists the URLs of a project's remote sources (e.g. OpenAPI
+// specs or published docs), one per line with an optional maximum age.
+const SourcesFile = ".sandwormsources"
+
+// DefaultMaxAge is how long fetched URLs are used without fetching them
+// again, unless a source or the sources.max_age setting says otherwise.
+const DefaultMaxAge = 24 * time.Hour
+
+// maxURLSize bounds the content of a URL, like a (large) project file.
+const maxURLSize = 10 << 20
+
+// URL is a remote source included in the bundle.
+type URL struct {
+	URL    string
+	MaxAge time.Duration // How long a fetched copy is fresh; -1 for the fetcher's default
+}
+
+// Fetched is the content of a remote source.
+type Fetched struct {
+	URL       string
+	Content   []byte
+	FetchedAt time.Time // When the content was last fetched (or revalidated)
+	Stale     bool      // Fetching failed, so this is an outdated cached copy
+}
+
+// URLFetcher fetches remote sources, caching them in a directory.
+type URLFetcher struct {
+	Dir     string        // Cache directory
+	MaxAge  time.Duration // Default maximum age of cached copies
+	Refresh bool          // Fetch (or revalidate) all sources regardless of their age
+	Client  *http.Client  // Defaults to a client with a 30s timeout, refusing private addresses
+
+	// AllowPrivate lets the default client fetch from private, loopback and
+	// link-local addresses (e.g. internal docs). Sources files come with
+	// projects, so this is off by default: a cloned repository can't make
+	// sandworm read internal services into a bundle.
+	AllowPrivate bool
+}
+
+// cachedURL is the cache entry of a fetched URL.
+type cachedURL struct {
+	URL          string    `json:"url"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	FetchedAt    time.Time `json:"fetched_at"`
+	Content      []byte    `json:"content"`
+}
+
+// ReadURLs reads the sources file of a directory. A missing file has no
+// sources.
+func ReadURLs(dir string) ([]URL, error) {
+	data, err := os.ReadFile(filepath.Join(dir, SourcesFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", SourcesFile, err)
+	}
+	urls, err := ParseURLs(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", SourcesFile, err)
+	}
+	return urls, nil
+}
+
+// ParseURLs parses a sources file: one http(s) URL per line, optionally
+// followed by a maximum age (e.g. "1h", "7d", or "0" to always fetch it).
+// Blank lines and lines starting with # are skipped.
+func ParseURLs(data string) ([]URL, error) {
+	var urls []URL
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if len(fields) > 2 {
+			return nil, fmt.Errorf("line %d: expected a URL and an optional maximum age", line)
+		}
+		if u, err := url.Parse(fields[0]); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("line %d: invalid URL %q (expected http or https)", line, fields[0])
+		}
+		source := URL{URL: fields[0], MaxAge: -1}
+		if len(fields) == 2 {
+			maxAge, err := ParseMaxAge(fields[1])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			source.MaxAge = maxAge
+		}
+		urls = append(urls, source)
+	}
+	return urls, nil
+}
+
+// ParseMaxAge parses a maximum age: a Go duration (e.g. "90m", "12h"), a
+// number of days (e.g. "7d"), or "0".
+func ParseMaxAge(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		var n int
+		if _, err := fmt.Sscanf(days, "%d", &n); err == nil && n >= 0 && fmt.Sprint(n) == days {
+			return time.Duration(n) * 24 * time.Hour, nil
+		}
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid maximum age %q (expected e.g. 12h or 7d)", value)
+	}
+	return d, nil
+}
+
+// ValidateMaxAge checks a maximum age (see ParseMaxAge).
+func ValidateMaxAge(value string) error {
+	_, err := ParseMaxAge(value)
+	return err
+}
+
+// Fetch returns the content of a source: the cached copy while it's fresh,
+// otherwise the fetched one (revalidated with conditional requests). When
+// fetching fails, an outdated cached copy is returned as stale rather than
+// failing.
+func (f *URLFetcher) Fetch(source URL) (*Fetched, error) {
+	maxAge := source.MaxAge
+	if maxAge < 0 {
+		maxAge = f.MaxAge
+	}
+
+	cached := f.cached(source.URL)
+	if cached != nil && !f.Refresh && time.Since(cached.FetchedAt) < maxAge {
+		return &Fetched{URL: source.URL, Content: cached.Content, FetchedAt: cached.FetchedAt}, nil
+	}
+
+	fetched, err := f.fetch(source.URL, cached)
+	if err != nil {
+		if cached != nil {
+			return &Fetched{URL: source.URL, Content: cached.Content, FetchedAt: cached.FetchedAt, Stale: true}, nil
+		}
+		return nil, err
+	}
+	// Best effort: the content was fetched, it'll just be fetched again.
+	_ = f.store(fetched)
+	return &Fetched{URL: source.URL, Content: fetched.Content, FetchedAt: fetched.FetchedAt}, nil
+}
+
+// MARK: Helpers
+
+// fetch downloads a URL, or revalidates its cached copy.
+func (f *URLFetcher) fetch(rawURL string, cached *cachedURL) (*cachedURL, error) {
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if cached != nil {
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+
+	client := f.Client
+	if client == nil {
+		client = newClient(f.AllowPrivate)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	now := time.Now()
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		revalidated := *cached
+		revalidated.FetchedAt = now
+		return &revalidated, nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("request failed: %s", resp.Status)
+	}
+
+	content, err := io.ReadAll(io.LimitReader(resp.Body, maxURLSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if len(content) > maxURLSize {
+		return nil, errors.New("content exceeds 10MB")
+	}
+	return &cachedURL{
+		URL:          rawURL,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		FetchedAt:    now,
+		Content:      content,
+	}, nil
+}
+
+// newClient returns the client fetching sources. Unless allowPrivate is set,
+// it refuses to connect to private addresses, checked once host names are
+// resolved so that no DNS answer can lead to them. Proxies set in the
+// environment are trusted: they're dialed whatever their address.
+func newClient(allowPrivate bool) *http.Client {
+	var proxies sync.Map // Addresses of the proxies in use
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = func(req *http.Request) (*url.URL, error) {
+		proxy, err := http.ProxyFromEnvironment(req)
+		if proxy != nil {
+			port := proxy.Port()
+			if port == "" {
+				port = map[string]string{"https": "443", "socks5": "1080"}[proxy.Scheme]
+				if port == "" {
+					port = "80"
+				}
+			}
+			proxies.Store(net.JoinHostPort(proxy.Hostname(), port), true)
+		}
+		return proxy, err
+	}
+	transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		dialer := net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+		if _, proxy := proxies.Load(addr); !allowPrivate && !proxy {
+			dialer.Control = refusePrivate
+		}
+		return dialer.DialContext(ctx, network, addr)
+	}
+	return &http.Client{Timeout: 30 * time.Second, Transport: transport}
+}
+
+// refusePrivate fails connections to private, loopback, link-local and
+// unspecified addresses. It's a net.Dialer Control function, called with
+// resolved addresses.
+func refusePrivate(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	if ip = ip.Unmap(); ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified() {
+		return fmt.Errorf("refusing to fetch from private address %s (see sources.allow_private)", ip)
+	}
+	return nil
+}
+
+// path returns the cache file of a URL.
+func (f *URLFetcher) path(rawURL string) string {
+	sum := sha256.Sum256([]byte(rawURL))
+	return filepath.Join(f.Dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// cached returns the cache entry of a URL, or nil.
+func (f *URLFetcher) cached(rawURL string) *cachedURL {
+	data, err := os.ReadFile(f.path(rawURL))
+	if err != nil {
+		return nil
+	}
+	var entry cachedURL
+	if json.Unmarshal(data, &entry) != nil || entry.URL != rawURL {
+		return nil
+	}
+	return &entry
+}
+
+// store caches a fetched URL.
+func (f *URLFetcher) store(entry *cachedURL) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache entry: %w", err)
+	}
+	if err := os.MkdirAll(f.Dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	if err := os.WriteFile(f.path(entry.URL), data, 0o600); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	return nil
+}
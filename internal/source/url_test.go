@@ -0,0 +1,101 @@
+package source
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseURLs(t *testing.T) {
+	urls, err := ParseURLs(`
+# API contract
+https://example.com/openapi.yaml
+http://example.com/schema.json 7d
+  https://example.com/docs.md 0
+`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []URL{
+		{URL: "https://example.com/openapi.yaml", MaxAge: -1},
+		{URL: "http://example.com/schema.json", MaxAge: 7 * 24 * time.Hour},
+		{URL: "https://example.com/docs.md", MaxAge: 0},
+	}
+	if len(urls) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, urls)
+	}
+	for i := range expected {
+		if urls[i] != expected[i] {
+			t.Errorf("Expected %v, got %v", expected[i], urls[i])
+		}
+	}
+
+	for _, invalid := range []string{"ftp://example.com/x", "example.com/x", "https://example.com/x 1w", "https://example.com/x 1h extra"} {
+		if _, err := ParseURLs(invalid); err == nil {
+			t.Errorf("Expected an error for %q", invalid)
+		}
+	}
+}
+
+func TestURLFetcher(t *testing.T) {
+	requests, revalidations := 0, 0
+	fail := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if fail {
+			http.Error(w, "down", http.StatusServiceUnavailable)
+			return
+		}
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			revalidations++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte("openapi: 3.1.0\n"))
+	}))
+	defer server.Close()
+
+	// Private addresses (the test server's) are refused by default
+	source := URL{URL: server.URL + "/openapi.yaml", MaxAge: -1}
+	if _, err := (&URLFetcher{Dir: t.TempDir()}).Fetch(source); err == nil || !strings.Contains(err.Error(), "private address") {
+		t.Errorf("Expected a private address to be refused, got %v", err)
+	}
+	if requests != 0 {
+		t.Errorf("Expected no request to reach the server, got %d", requests)
+	}
+
+	f := &URLFetcher{Dir: t.TempDir(), MaxAge: time.Hour, AllowPrivate: true}
+
+	fetched, err := f.Fetch(source)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if string(fetched.Content) != "openapi: 3.1.0\n" || fetched.Stale {
+		t.Errorf("Unexpected fetch: %+v", fetched)
+	}
+
+	// Fresh copies are used as is
+	if _, err := f.Fetch(source); err != nil || requests != 1 {
+		t.Errorf("Expected the cached copy (requests: %d, error: %v)", requests, err)
+	}
+
+	// Outdated copies are revalidated
+	source.MaxAge = 0
+	if fetched, err = f.Fetch(source); err != nil || revalidations != 1 || string(fetched.Content) != "openapi: 3.1.0\n" {
+		t.Errorf("Expected a revalidated copy (revalidations: %d, error: %v)", revalidations, err)
+	}
+
+	// When fetching fails, the outdated copy is used
+	fail = true
+	if fetched, err = f.Fetch(source); err != nil || !fetched.Stale || string(fetched.Content) != "openapi: 3.1.0\n" {
+		t.Errorf("Expected a stale copy, got %+v (error: %v)", fetched, err)
+	}
+
+	// Without a copy, it fails
+	if _, err := f.Fetch(URL{URL: server.URL + "/other", MaxAge: -1}); err == nil {
+		t.Error("Expected an error without a cached copy")
+	}
+}
@@ -0,0 +1,122 @@
+// Package stats keeps a local log of generated bundles (size, file count and
+// generation time) per project directory, to follow how they grow over time.
+// Nothing is ever sent anywhere.
+package stats
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Actions recorded in the log
+const (
+	ActionGenerate = "generate"
+	ActionPush     = "push"
+)
+
+// sparkBars are the bars of sparklines, lowest first.
+var (
+	sparkBars      = []rune("▁▂▃▄▅▆▇█")
+	sparkBarsASCII = []rune("_.-:=+*#")
+)
+
+// Entry is a single stats record.
+type Entry struct {
+	Time      time.Time `json:"time"`
+	Action    string    `json:"action"`
+	Directory string    `json:"directory"`        // Absolute project directory
+	Size      int64     `json:"size"`             // Size of the generated bundle
+	Files     int       `json:"files"`            // Files with contents in the bundle
+	Tokens    int       `json:"tokens,omitempty"` // Estimated tokens of the bundle
+	Duration  int64     `json:"duration_ms"`      // Generation time, in milliseconds
+}
+
+// Path returns the stats log path in a (global config) directory.
+func Path(dir string) string {
+	return filepath.Join(dir, "stats.log")
+}
+
+// Append adds an entry to the log at path, filling in the time if unset.
+func Append(path string, e Entry) error {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to encode stats entry: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create stats directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open stats log: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write stats log: %w", err)
+	}
+	return f.Close()
+}
+
+// Read returns the entries of the log at path for a project directory (all
+// if empty), oldest first. A missing log has no entries; unreadable lines are
+// skipped, as stats are informational only.
+func Read(path, directory string) ([]Entry, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open stats log: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue
+		}
+		if directory == "" || e.Directory == directory {
+			entries = append(entries, e)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read stats log: %w", err)
+	}
+	return entries, nil
+}
+
+// Sparkline draws values as a line of bars scaled between their minimum and
+// maximum, e.g. "▁▂▂▄█".
+func Sparkline(values []int64, ascii bool) string {
+	bars := sparkBars
+	if ascii {
+		bars = sparkBarsASCII
+	}
+	if len(values) == 0 {
+		return ""
+	}
+
+	lo, hi := values[0], values[0]
+	for _, v := range values {
+		lo, hi = min(lo, v), max(hi, v)
+	}
+	line := make([]rune, len(values))
+	for i, v := range values {
+		level := 0
+		if hi > lo {
+			level = int((v - lo) * int64(len(bars)-1) / (hi - lo))
+		}
+		line[i] = bars[level]
+	}
+	return string(line)
+}
@@ -0,0 +1,59 @@
+package stats
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestAppendRead(t *testing.T) {
+	path := Path(filepath.Join(t.TempDir(), "sandworm"))
+
+	entries, err := Read(path, "")
+	if err != nil || len(entries) != 0 {
+		t.Fatalf("Expected no entries for a missing log, got %v (%v)", entries, err)
+	}
+
+	for _, e := range []Entry{
+		{Action: ActionGenerate, Directory: "/a", Size: 10, Files: 1},
+		{Action: ActionPush, Directory: "/b", Size: 20, Files: 2},
+		{Action: ActionPush, Directory: "/a", Size: 30, Files: 3, Duration: 5},
+	} {
+		if err := Append(path, e); err != nil {
+			t.Fatalf("Failed to append: %v", err)
+		}
+	}
+
+	entries, err = Read(path, "/a")
+	if err != nil {
+		t.Fatalf("Failed to read: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Size != 10 || entries[1].Duration != 5 {
+		t.Errorf("Unexpected entries for /a: %+v", entries)
+	}
+	if entries[0].Time.IsZero() {
+		t.Error("Expected the time to be filled in")
+	}
+
+	if entries, _ := Read(path, ""); len(entries) != 3 {
+		t.Errorf("Expected 3 entries in total, got %d", len(entries))
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		values   []int64
+		ascii    bool
+		expected string
+	}{
+		{values: nil, expected: ""},
+		{values: []int64{5, 5}, expected: "▁▁"},
+		{values: []int64{0, 7, 14}, expected: "▁▄█"},
+		{values: []int64{0, 7, 14}, ascii: true, expected: "_:#"},
+	}
+
+	for _, tt := range tests {
+		if got := Sparkline(tt.values, tt.ascii); got != tt.expected {
+			t.Errorf("Sparkline(%v, %v) = %q, expected %q", tt.values, tt.ascii, got, tt.expected)
+		}
+	}
+}
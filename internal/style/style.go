@@ -0,0 +1,106 @@
+// Package style provides minimal terminal styling (colors, bold) for CLI output.
+// Styling is automatically disabled when NO_COLOR is set, when TERM is "dumb",
+// or when stdout isn't a terminal, and can be disabled explicitly (--no-color).
+package style
+
+import (
+	"os"
+)
+
+// ANSI escape sequences
+const (
+	reset  = "\033[0m"
+	bold   = "\033[1m"
+	dim    = "\033[2m"
+	red    = "\033[31m"
+	green  = "\033[32m"
+	yellow = "\033[33m"
+	cyan   = "\033[36m"
+)
+
+var (
+	enabled = detect(os.Getenv, isTerminal(os.Stdout))
+	plain   = false
+)
+
+// SetEnabled forces styling on or off.
+func SetEnabled(on bool) {
+	enabled = on
+}
+
+// SetPlain enables plain output: no colors and ASCII-only symbols, for
+// terminals and logs that mangle unicode.
+func SetPlain(on bool) {
+	plain = on
+	if on {
+		enabled = false
+	}
+}
+
+// Plain reports whether plain (ASCII-only) output is requested.
+func Plain() bool {
+	return plain
+}
+
+// Symbol returns the fancy (unicode/emoji) symbol, or its ASCII alternative in
+// plain mode.
+func Symbol(fancy, ascii string) string {
+	if plain {
+		return ascii
+	}
+	return fancy
+}
+
+// Enabled reports whether styling is currently applied.
+func Enabled() bool {
+	return enabled
+}
+
+// Header styles section headers and prominent labels.
+func Header(s string) string { return apply(bold, s) }
+
+// Success styles messages reporting a completed operation.
+func Success(s string) string { return apply(green, s) }
+
+// Warning styles messages that need the user's attention.
+func Warning(s string) string { return apply(yellow, s) }
+
+// Error styles error messages.
+func Error(s string) string { return apply(red, s) }
+
+// Info styles highlighted values (names, paths).
+func Info(s string) string { return apply(cyan, s) }
+
+// Dim styles secondary information such as progress lines.
+func Dim(s string) string { return apply(dim, s) }
+
+// Added styles lines added in a diff.
+func Added(s string) string { return apply(green, s) }
+
+// Removed styles lines removed in a diff.
+func Removed(s string) string { return apply(red, s) }
+
+func apply(code, s string) string {
+	if !enabled || s == "" {
+		return s
+	}
+	return code + s + reset
+}
+
+// detect determines whether styling should be enabled by default. See
+// https://no-color.org for the NO_COLOR convention.
+func detect(getenv func(string) string, tty bool) bool {
+	if getenv("NO_COLOR") != "" || getenv("TERM") == "dumb" {
+		return false
+	}
+	return tty
+}
+
+// isTerminal reports whether f is attached to a terminal (character device).
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,62 @@
+package style
+
+import "testing"
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		tty      bool
+		expected bool
+	}{
+		{name: "terminal", env: nil, tty: true, expected: true},
+		{name: "not a terminal", env: nil, tty: false, expected: false},
+		{name: "NO_COLOR", env: map[string]string{"NO_COLOR": "1"}, tty: true, expected: false},
+		{name: "dumb terminal", env: map[string]string{"TERM": "dumb"}, tty: true, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getenv := func(key string) string { return tt.env[key] }
+			if got := detect(getenv, tt.tty); got != tt.expected {
+				t.Errorf("detect() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	defer SetEnabled(Enabled())
+
+	SetEnabled(false)
+	if got := Success("done"); got != "done" {
+		t.Errorf("Expected unstyled output when disabled, got %q", got)
+	}
+
+	SetEnabled(true)
+	if got := Success("done"); got != green+"done"+reset {
+		t.Errorf("Expected styled output when enabled, got %q", got)
+	}
+	if got := Success(""); got != "" {
+		t.Errorf("Expected empty strings to stay empty, got %q", got)
+	}
+}
+
+func TestPlain(t *testing.T) {
+	defer SetEnabled(Enabled())
+	defer SetPlain(Plain())
+
+	SetEnabled(true)
+	SetPlain(true)
+	if Enabled() {
+		t.Error("Expected plain mode to disable colors")
+	}
+	if got := Symbol("✓", "ok"); got != "ok" {
+		t.Errorf("Expected ASCII symbol in plain mode, got %q", got)
+	}
+
+	SetPlain(false)
+	if got := Symbol("✓", "ok"); got != "✓" {
+		t.Errorf("Expected fancy symbol, got %q", got)
+	}
+}
@@ -0,0 +1,246 @@
+// Package suggest analyzes the files of a project and proposes ignore
+// patterns for content that is rarely useful to an LLM: vendored
+// dependencies, generated code, binaries and unusually large files.
+package suggest
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+)
+
+// File is a file considered for suggestions.
+type File struct {
+	Path    string // Slash-separated path relative to the project root
+	AbsPath string // Path to read the file from
+	Size    int64
+}
+
+// Suggestion is a proposed ignore pattern.
+type Suggestion struct {
+	Pattern string // Ignore pattern, in .gitignore syntax
+	Reason  string
+	Files   int   // Number of files matched
+	Size    int64 // Total size of the matched files
+}
+
+// Options tunes the analysis.
+type Options struct {
+	LargeFileSize int64 // Files at least this big are suggested individually
+	MaxLargeFiles int   // How many large files to suggest at most
+}
+
+// vendoredDirs are directory names that hold dependencies or build output.
+var vendoredDirs = map[string]bool{
+	"vendor":           true,
+	"node_modules":     true,
+	"bower_components": true,
+	"third_party":      true,
+	"dist":             true,
+	"build":            true,
+	"out":              true,
+	"target":           true,
+	"coverage":         true,
+	".next":            true,
+	".nuxt":            true,
+	"__pycache__":      true,
+	".venv":            true,
+	"venv":             true,
+	"Pods":             true,
+}
+
+// generatedSuffixes are file name suffixes of generated code.
+var generatedSuffixes = []string{
+	".pb.go", "_pb2.py", "_pb2_grpc.py", ".pb.ts", "_generated.go", ".gen.go",
+	".g.dart", ".freezed.dart", ".min.js", ".min.css", ".js.map", ".css.map",
+}
+
+// generatedMarkers are found near the top of generated files.
+var generatedMarkers = [][]byte{
+	[]byte("Code generated"),
+	[]byte("DO NOT EDIT"),
+	[]byte("@generated"),
+	[]byte("autogenerated"),
+	[]byte("auto-generated"),
+}
+
+// sniffSize is how much of each file is read to detect binary and generated
+// content.
+const sniffSize = 8000
+
+// Binary files are suggested by extension (e.g. "*.png") only when there are
+// at least minExtensionFiles of them, or minExtensionSize bytes; otherwise
+// by path, so that one stray binary doesn't rule out a whole file type.
+const (
+	minExtensionFiles = 3
+	minExtensionSize  = 1 << 20
+)
+
+// Analyze proposes ignore patterns for the given files. Each file is covered
+// by at most one suggestion: vendored directories first, then generated
+// code, binary files and large files.
+func Analyze(files []File, opts Options) []Suggestion {
+	var suggestions []Suggestion
+	remaining := files
+
+	// Vendored directories (outermost match only)
+	dirs := map[string]*Suggestion{}
+	remaining = filter(remaining, func(f File) bool {
+		dir := vendoredDir(f.Path)
+		if dir == "" {
+			return true
+		}
+		s, ok := dirs[dir]
+		if !ok {
+			s = &Suggestion{Pattern: "/" + dir + "/", Reason: "vendored or build output directory"}
+			dirs[dir] = s
+		}
+		s.Files++
+		s.Size += f.Size
+		return false
+	})
+	suggestions = append(suggestions, sorted(dirs)...)
+
+	// Generated code and binary files, by name suffix or extension when
+	// possible, otherwise by path
+	generated := map[string]*Suggestion{}
+	binary := map[string]*Suggestion{}
+	byExt := map[string][]File{}
+	remaining = filter(remaining, func(f File) bool {
+		if suffix := generatedSuffix(f.Path); suffix != "" {
+			add(generated, "*"+suffix, "generated code", f)
+			return false
+		}
+		head := sniff(f.AbsPath)
+		switch {
+		case bytes.IndexByte(head, 0) >= 0:
+			ext := path.Ext(f.Path)
+			byExt[ext] = append(byExt[ext], f)
+			return false
+		case isGenerated(head):
+			add(generated, "/"+f.Path, "generated code", f)
+			return false
+		}
+		return true
+	})
+	for ext, group := range byExt {
+		var size int64
+		for _, f := range group {
+			size += f.Size
+		}
+		byExtension := ext != "" && (len(group) >= minExtensionFiles || size >= minExtensionSize)
+		for _, f := range group {
+			if byExtension {
+				add(binary, "*"+ext, "binary file", f)
+			} else {
+				add(binary, "/"+f.Path, "binary file", f)
+			}
+		}
+	}
+	suggestions = append(suggestions, sorted(generated)...)
+	suggestions = append(suggestions, sorted(binary)...)
+
+	// Largest remaining files
+	if opts.LargeFileSize > 0 {
+		var large []File
+		for _, f := range remaining {
+			if f.Size >= opts.LargeFileSize {
+				large = append(large, f)
+			}
+		}
+		sort.Slice(large, func(i, j int) bool { return large[i].Size > large[j].Size })
+		if opts.MaxLargeFiles > 0 && len(large) > opts.MaxLargeFiles {
+			large = large[:opts.MaxLargeFiles]
+		}
+		for _, f := range large {
+			suggestions = append(suggestions, Suggestion{Pattern: "/" + f.Path, Reason: "large file", Files: 1, Size: f.Size})
+		}
+	}
+
+	return suggestions
+}
+
+// MARK: Helpers
+
+// vendoredDir returns the outermost vendored directory containing the path,
+// or "" if there is none.
+func vendoredDir(p string) string {
+	parts := strings.Split(p, "/")
+	for i, part := range parts[:len(parts)-1] {
+		if vendoredDirs[part] {
+			return strings.Join(parts[:i+1], "/")
+		}
+	}
+	return ""
+}
+
+func generatedSuffix(p string) string {
+	for _, suffix := range generatedSuffixes {
+		if strings.HasSuffix(p, suffix) {
+			return suffix
+		}
+	}
+	return ""
+}
+
+func isGenerated(head []byte) bool {
+	// Markers only count near the top of the file
+	if lines := bytes.SplitN(head, []byte("\n"), 6); len(lines) > 5 {
+		head = bytes.Join(lines[:5], []byte("\n"))
+	}
+	for _, marker := range generatedMarkers {
+		if bytes.Contains(head, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// sniff reads the beginning of a file.
+func sniff(p string) []byte {
+	f, err := os.Open(p)
+	if err != nil {
+		return nil
+	}
+	defer func() { _ = f.Close() }()
+	head, _ := io.ReadAll(io.LimitReader(f, sniffSize))
+	return head
+}
+
+func filter(files []File, keep func(File) bool) []File {
+	var kept []File
+	for _, f := range files {
+		if keep(f) {
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
+
+func add(group map[string]*Suggestion, pattern, reason string, f File) {
+	s, ok := group[pattern]
+	if !ok {
+		s = &Suggestion{Pattern: pattern, Reason: reason}
+		group[pattern] = s
+	}
+	s.Files++
+	s.Size += f.Size
+}
+
+// sorted returns the suggestions of a group, biggest first.
+func sorted(group map[string]*Suggestion) []Suggestion {
+	list := make([]Suggestion, 0, len(group))
+	for _, s := range group {
+		list = append(list, *s)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Size != list[j].Size {
+			return list[i].Size > list[j].Size
+		}
+		return list[i].Pattern < list[j].Pattern
+	})
+	return list
+}
@@ -0,0 +1,63 @@
+package suggest
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAnalyze(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"main.go":                     "package main",
+		"vendor/lib/lib.go":           "package lib",
+		"web/node_modules/x/index.js": "module.exports = 1",
+		"api/api.pb.go":               "package api",
+		"models/models.go":            "// Code generated by sqlc. DO NOT EDIT.\npackage models",
+		"assets/logo.dat":             "\x00\x01\x02",
+		"assets/icon.dat":             "\x00\x01",
+		"assets/font.dat":             "\x00",
+		"tools/helper.bin":            "\x00\x01\x02\x03",
+		"data/big.json":               strings.Repeat("x", 2000),
+		"data/small.json":             "{}",
+	}
+
+	var input []File
+	for path, content := range files {
+		fullPath := filepath.Join(tmpDir, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0o755); err != nil {
+			t.Fatalf("Failed to create directories for %s: %v", path, err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to create file %s: %v", path, err)
+		}
+		input = append(input, File{Path: path, AbsPath: fullPath, Size: int64(len(content))})
+	}
+
+	suggestions := Analyze(input, Options{LargeFileSize: 1000, MaxLargeFiles: 5})
+
+	var patterns []string
+	for _, s := range suggestions {
+		patterns = append(patterns, s.Pattern)
+	}
+	want := []string{
+		"/web/node_modules/",
+		"/vendor/",
+		"/models/models.go",
+		"*.pb.go",
+		"*.dat",
+		"/tools/helper.bin",
+		"/data/big.json",
+	}
+	if !reflect.DeepEqual(patterns, want) {
+		t.Errorf("Expected patterns %v, got %v", want, patterns)
+	}
+
+	for _, s := range suggestions {
+		if s.Pattern == "*.dat" && (s.Files != 3 || s.Size != 6) {
+			t.Errorf("Expected *.dat to cover 3 files (6 B), got %d files (%d B)", s.Files, s.Size)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package symbols
+
+import (
+	"regexp"
+	"strings"
+)
+
+// pythonDefRe matches class and function definitions, capturing indentation.
+var pythonDefRe = regexp.MustCompile(`(?m)^([ \t]*)(?:async\s+)?(def|class)\s+([A-Za-z_]\w*)`)
+
+// pythonSymbols lists the public top-level classes and functions of a Python
+// file, along with the public methods of top-level classes.
+func pythonSymbols(content []byte) []string {
+	var symbols []string
+	class, methodIndent := "", ""
+	for _, m := range pythonDefRe.FindAllSubmatch(content, -1) {
+		indent, kind, name := string(m[1]), string(m[2]), string(m[3])
+		if indent == "" {
+			class, methodIndent = "", ""
+			if kind == "class" {
+				class = name
+			}
+			if !strings.HasPrefix(name, "_") {
+				symbols = append(symbols, name)
+			}
+			continue
+		}
+		if class == "" {
+			continue
+		}
+		// The first definition in a class sets the indentation of its
+		// methods; deeper definitions are nested and skipped.
+		if methodIndent == "" {
+			methodIndent = indent
+		}
+		if kind == "def" && indent == methodIndent &&
+			!strings.HasPrefix(class, "_") && !strings.HasPrefix(name, "_") {
+			symbols = append(symbols, class+"."+name)
+		}
+	}
+	return symbols
+}
@@ -0,0 +1,15 @@
+package symbols
+
+import "regexp"
+
+// scriptExportRe matches exported declarations in TypeScript/JavaScript.
+var scriptExportRe = regexp.MustCompile(`(?m)^\s*export\s+(?:default\s+)?(?:declare\s+)?(?:abstract\s+)?(?:async\s+)?(?:function\*?|class|interface|type|enum|const|let|var|namespace)\s+([A-Za-z_$][\w$]*)`)
+
+// scriptSymbols lists the exported declarations of a TS/JS file.
+func scriptSymbols(content []byte) []string {
+	var symbols []string
+	for _, m := range scriptExportRe.FindAllSubmatch(content, -1) {
+		symbols = append(symbols, string(m[1]))
+	}
+	return symbols
+}
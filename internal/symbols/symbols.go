@@ -0,0 +1,43 @@
+// Package symbols extracts a compact index of the symbols (functions, types,
+// classes) declared by source files, using lightweight per-language parsers.
+package symbols
+
+import (
+	"path"
+	"strings"
+)
+
+// extractor returns the exported symbols declared in a file's contents.
+type extractor func(content []byte) []string
+
+// extractors maps file extensions to the extractor of their language.
+var extractors = map[string]extractor{
+	".go":  goSymbols,
+	".ts":  scriptSymbols,
+	".tsx": scriptSymbols,
+	".mts": scriptSymbols,
+	".cts": scriptSymbols,
+	".js":  scriptSymbols,
+	".jsx": scriptSymbols,
+	".mjs": scriptSymbols,
+	".cjs": scriptSymbols,
+	".py":  pythonSymbols,
+}
+
+// Supported reports whether symbols can be extracted from the file.
+func Supported(relPath string) bool {
+	_, ok := extractors[strings.ToLower(path.Ext(relPath))]
+	return ok
+}
+
+// Extract returns the exported symbols declared in the file, in declaration
+// order. Methods are qualified with their receiver or class (e.g.
+// "Server.Start"). It returns nil for unsupported languages or files that
+// can't be parsed.
+func Extract(relPath string, content []byte) []string {
+	extract, ok := extractors[strings.ToLower(path.Ext(relPath))]
+	if !ok {
+		return nil
+	}
+	return extract(content)
+}
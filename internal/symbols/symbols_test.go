@@ -0,0 +1,89 @@
+package symbols
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtract(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		content string
+		want    []string
+	}{
+		{
+			name: "go",
+			path: "server.go",
+			content: `package server
+
+type Server struct{}
+type handler struct{}
+
+const Version = "1"
+
+var ErrClosed, errOther = 1, 2
+
+func New() *Server { return nil }
+func helper() {}
+func (s *Server) Start() {}
+func (s *Server) stop() {}
+func (h handler) Serve() {}
+func (l List[T]) Len() int { return 0 }
+`,
+			want: []string{"Server", "Version", "ErrClosed", "New", "Server.Start", "List.Len"},
+		},
+		{
+			name: "typescript",
+			path: "src/api.ts",
+			content: `import x from './x';
+export interface Options {}
+export type ID = string;
+export default class Client {}
+export async function fetchAll() {}
+export const LIMIT = 10;
+function internal() {}
+`,
+			want: []string{"Options", "ID", "Client", "fetchAll", "LIMIT"},
+		},
+		{
+			name: "python",
+			path: "app.py",
+			content: `class Service:
+    def start(self):
+        def inner():
+            pass
+    def _private(self):
+        pass
+
+def main():
+    pass
+
+def _helper():
+    pass
+`,
+			want: []string{"Service", "Service.start", "main"},
+		},
+		{
+			name:    "unsupported",
+			path:    "README.md",
+			content: "# Title",
+			want:    nil,
+		},
+		{
+			name:    "invalid go",
+			path:    "broken.go",
+			content: "package",
+			want:    nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Extract(tt.path, []byte(tt.content))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Extract(%s) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
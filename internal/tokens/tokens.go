@@ -0,0 +1,196 @@
+// Package tokens estimates the number of tokens of a text, with estimators
+// trading accuracy for speed: a character-count heuristic, a heuristic
+// weighing words, numbers and punctuation, and Anthropic's token counting
+// endpoint.
+package tokens
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Estimators
+const (
+	Fast  = "fast"  // Characters / 4, instant
+	Words = "words" // Weighs words, numbers and punctuation, locally
+	API   = "api"   // Anthropic's token counting endpoint (requires APIKeyEnv)
+)
+
+// Estimators lists the supported estimators.
+var Estimators = []string{Fast, Words, API}
+
+// APIKeyEnv holds the Anthropic API key used by the API estimator.
+const APIKeyEnv = "ANTHROPIC_API_KEY"
+
+// DefaultModel is the model whose tokenizer the API estimator counts with.
+const DefaultModel = "claude-sonnet-4-20250514"
+
+// Counter counts the tokens of a text.
+type Counter interface {
+	Name() string
+	Count(text []byte) (int, error)
+}
+
+// New returns the counter of an estimator.
+func New(estimator string) (Counter, error) {
+	switch estimator {
+	case Fast, "":
+		return fastCounter{}, nil
+	case Words:
+		return wordsCounter{}, nil
+	case API:
+		key := os.Getenv(APIKeyEnv)
+		if key == "" {
+			return nil, fmt.Errorf("the %s estimator requires an Anthropic API key in %s", API, APIKeyEnv)
+		}
+		return &apiCounter{url: countURL, key: key, model: DefaultModel, client: &http.Client{Timeout: 60 * time.Second}}, nil
+	default:
+		return nil, fmt.Errorf("invalid token estimator '%s' (must be one of %s)", estimator, strings.Join(Estimators, ", "))
+	}
+}
+
+// Format formats a token count compactly, e.g. "~12.3k tokens".
+func Format(count int) string {
+	switch {
+	case count >= 1_000_000:
+		return fmt.Sprintf("~%.1fM tokens", float64(count)/1_000_000)
+	case count >= 1_000:
+		return fmt.Sprintf("~%.1fk tokens", float64(count)/1_000)
+	default:
+		return fmt.Sprintf("~%d tokens", count)
+	}
+}
+
+// MARK: Fast
+
+// fastCounter assumes 4 characters per token, the usual average for English
+// text and code.
+type fastCounter struct{}
+
+func (fastCounter) Name() string { return Fast }
+
+func (fastCounter) Count(text []byte) (int, error) {
+	return (utf8.RuneCount(text) + 3) / 4, nil
+}
+
+// MARK: Words
+
+// pieceRE splits text roughly the way BPE tokenizers pre-tokenize it: contractions,
+// words with their leading space, numbers of up to 3 digits, runs of
+// punctuation and runs of whitespace.
+var pieceRE = regexp.MustCompile(`'(?:s|t|re|ve|m|ll|d)| ?\pL+| ?\pN{1,3}| ?[^\s\pL\pN]+|\s+`)
+
+// wordsCounter is a heuristic: it weighs the pieces of pieceRE the way BPE
+// tokenizers tend to split them, without a tokenizer's vocabulary. Common
+// words count as a single token, while long words (e.g. identifiers),
+// punctuation and non-Latin scripts take several. It's usually closer than
+// fastCounter on code, but remains an estimate.
+type wordsCounter struct{}
+
+func (wordsCounter) Name() string { return Words }
+
+func (wordsCounter) Count(text []byte) (int, error) {
+	count := 0
+	for _, piece := range pieceRE.FindAll(text, -1) {
+		count += pieceTokens(piece)
+	}
+	return count, nil
+}
+
+// pieceTokens estimates the tokens of a pre-tokenized piece.
+func pieceTokens(piece []byte) int {
+	trimmed := bytes.TrimPrefix(piece, []byte(" "))
+	if len(trimmed) == 0 {
+		return 1
+	}
+	r, _ := utf8.DecodeRune(trimmed)
+	switch {
+	case unicode.IsSpace(r):
+		// Indentation and blank lines merge into a few tokens
+		return (len(piece) + 7) / 8
+	case unicode.IsLetter(r):
+		if runes := utf8.RuneCount(trimmed); runes != len(trimmed) {
+			return runes // Non-Latin scripts: about a token per character
+		}
+		return (len(trimmed) + 4) / 5
+	case unicode.IsNumber(r):
+		return 1
+	case bytes.Count(trimmed, trimmed[:1]) == len(trimmed):
+		// Runs of a single character (e.g. separators) have long tokens
+		return (len(trimmed) + 15) / 16
+	default:
+		return (utf8.RuneCount(trimmed) + 1) / 2
+	}
+}
+
+// MARK: API
+
+// countURL is Anthropic's token counting endpoint.
+const countURL = "https://api.anthropic.com/v1/messages/count_tokens"
+
+// apiCounter counts tokens with Anthropic's token counting endpoint, which
+// is exact for the model but sends the text to the API.
+type apiCounter struct {
+	url    string
+	key    string
+	model  string
+	client *http.Client
+}
+
+func (c *apiCounter) Name() string { return API }
+
+func (c *apiCounter) Count(text []byte) (int, error) {
+	body, err := json.Marshal(map[string]any{
+		"model":    c.model,
+		"messages": []map[string]string{{"role": "user", "content": string(text)}},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	req, err := http.NewRequest(http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Api-Key", c.key)
+	req.Header.Set("Anthropic-Version", "2023-06-01")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count tokens: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Error.Message != "" {
+			return 0, fmt.Errorf("failed to count tokens: %s (HTTP %d)", apiErr.Error.Message, resp.StatusCode)
+		}
+		return 0, fmt.Errorf("failed to count tokens: HTTP %d", resp.StatusCode)
+	}
+
+	var result struct {
+		InputTokens *int `json:"input_tokens"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil || result.InputTokens == nil {
+		return 0, errors.New("failed to count tokens: unexpected response")
+	}
+	return *result.InputTokens, nil
+}
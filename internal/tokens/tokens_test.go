@@ -0,0 +1,88 @@
+package tokens
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFast(t *testing.T) {
+	counter, err := New(Fast)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got, _ := counter.Count([]byte("12345678")); got != 2 {
+		t.Errorf("Expected 2 tokens, got %d", got)
+	}
+	if got, _ := counter.Count([]byte("héllo")); got != 2 {
+		t.Errorf("Expected characters rather than bytes to be counted, got %d", got)
+	}
+}
+
+func TestWords(t *testing.T) {
+	tests := map[string]struct {
+		text string
+		want int
+	}{
+		"words":       {"the quick brown fox", 4},
+		"identifier":  {"getGlobalConfigPath", 4},
+		"punctuation": {"if (x) {", 5},
+		"numbers":     {"1234567", 3},
+		"indentation": {"\n\t\t", 1},
+		"separator":   {strings.Repeat("=", 80), 5},
+		"non-latin":   {"日本語", 3},
+	}
+	counter, _ := New(Words)
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got, _ := counter.Count([]byte(tt.text)); got != tt.want {
+				t.Errorf("Expected %d tokens for %q, got %d", tt.want, tt.text, got)
+			}
+		})
+	}
+}
+
+func TestAPI(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Api-Key") != "test-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"type":"error","error":{"type":"authentication_error","message":"invalid x-api-key"}}`))
+			return
+		}
+		var body struct {
+			Messages []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		_ = json.NewEncoder(w).Encode(map[string]int{"input_tokens": len(strings.Fields(body.Messages[0].Content))})
+	}))
+	defer server.Close()
+
+	counter := &apiCounter{url: server.URL, key: "test-key", model: DefaultModel, client: server.Client()}
+	if got, err := counter.Count([]byte("one two three")); err != nil || got != 3 {
+		t.Errorf("Expected 3 tokens, got %d (%v)", got, err)
+	}
+	counter.key = "wrong"
+	if _, err := counter.Count([]byte("one")); err == nil || !strings.Contains(err.Error(), "invalid x-api-key (HTTP 401)") {
+		t.Errorf("Expected the API error, got %v", err)
+	}
+
+	t.Setenv(APIKeyEnv, "")
+	if _, err := New(API); err == nil || !strings.Contains(err.Error(), APIKeyEnv) {
+		t.Errorf("Expected an error without an API key, got %v", err)
+	}
+	if _, err := New("exact"); err == nil {
+		t.Error("Expected an error for an unknown estimator")
+	}
+}
+
+func TestFormat(t *testing.T) {
+	for count, want := range map[int]string{42: "~42 tokens", 12_345: "~12.3k tokens", 2_500_000: "~2.5M tokens"} {
+		if got := Format(count); got != want {
+			t.Errorf("Format(%d) = %q, want %q", count, got, want)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package util
+
+// Closest returns the candidate closest to s by edit distance, if it's close
+// enough to be a likely typo of it (e.g. "print_line_number" for
+// "print_line_numbers"); otherwise "". Ties go to the first candidate.
+func Closest(s string, candidates []string) string {
+	maxDistance := min(max(len(s)/3, 1), 3)
+	best, bestDistance := "", maxDistance+1
+	for _, candidate := range candidates {
+		if d := editDistance(s, candidate); d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between two strings (in
+// bytes, which is fine for keys and field names).
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
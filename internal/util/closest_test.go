@@ -0,0 +1,20 @@
+package util
+
+import "testing"
+
+func TestClosest(t *testing.T) {
+	candidates := []string{"output", "input", "transforms", "backend"}
+	tests := map[string]string{
+		"outptu":     "output",
+		"transfroms": "transforms",
+		"inpt":       "input",
+		"backends":   "backend",
+		"directory":  "", // Too far from any candidate
+		"x":          "",
+	}
+	for s, want := range tests {
+		if got := Closest(s, candidates); got != want {
+			t.Errorf("Closest(%q) = %q, want %q", s, got, want)
+		}
+	}
+}
@@ -5,6 +5,8 @@ package util
 import (
 	"fmt"
 	"math"
+	"strconv"
+	"strings"
 )
 
 // FormatSize converts a byte count into a human-readable string using the most appropriate
@@ -41,3 +43,24 @@ func FormatSize(bytes int64) string {
 	// Format with one decimal place, followed by the unit
 	return fmt.Sprintf("%.1f %s", value, units[exp])
 }
+
+// ParseSize parses a human-readable size, the inverse of FormatSize: a number
+// of bytes with an optional (case-insensitive) B, KB, MB, GB or TB unit, e.g.
+// "500", "1.5 MB" or "10mb". Units are powers of 1024.
+func ParseSize(s string) (int64, error) {
+	value := strings.ToUpper(strings.TrimSpace(s))
+	multiplier := float64(1)
+	for i, unit := range []string{"TB", "GB", "MB", "KB", "B"} {
+		if number, ok := strings.CutSuffix(value, unit); ok {
+			value = strings.TrimSpace(number)
+			multiplier = math.Pow(1024, float64(4-i))
+			break
+		}
+	}
+
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q (expected e.g. 500KB or 10MB)", s)
+	}
+	return int64(n * multiplier), nil
+}
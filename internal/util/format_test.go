@@ -64,3 +64,32 @@ func TestFormatSize(t *testing.T) {
 		})
 	}
 }
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+		wantErr  bool
+	}{
+		{input: "500", expected: 500},
+		{input: "500B", expected: 500},
+		{input: "1.5 KB", expected: 1536},
+		{input: "10mb", expected: 10 * 1024 * 1024},
+		{input: "1GB", expected: 1024 * 1024 * 1024},
+		{input: "", wantErr: true},
+		{input: "MB", wantErr: true},
+		{input: "-1KB", wantErr: true},
+		{input: "10 parsecs", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		result, err := ParseSize(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseSize(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if result != tt.expected {
+			t.Errorf("ParseSize(%q) = %d, want %d", tt.input, result, tt.expected)
+		}
+	}
+}
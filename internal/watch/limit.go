@@ -0,0 +1,47 @@
+package watch
+
+import "time"
+
+// Limit caps the number of events (e.g. pushes) within a sliding window, so
+// that noisy projects don't turn into a stream of API calls.
+type Limit struct {
+	Max    int           // Events allowed per window; 0 for no limit
+	Window time.Duration // e.g. an hour
+
+	events []time.Time // Within the last window, oldest first
+}
+
+// Allow reports whether an event may happen at now, recording it if so.
+func (l *Limit) Allow(now time.Time) bool {
+	if l == nil || l.Max <= 0 {
+		return true
+	}
+	l.expire(now)
+	if len(l.events) >= l.Max {
+		return false
+	}
+	l.events = append(l.events, now)
+	return true
+}
+
+// Next returns when the next event will be allowed, which is now if it
+// already is.
+func (l *Limit) Next(now time.Time) time.Time {
+	if l == nil || l.Max <= 0 {
+		return now
+	}
+	l.expire(now)
+	if len(l.events) < l.Max {
+		return now
+	}
+	return l.events[0].Add(l.Window)
+}
+
+// expire drops the events that left the window.
+func (l *Limit) expire(now time.Time) {
+	i := 0
+	for i < len(l.events) && !l.events[i].Add(l.Window).After(now) {
+		i++
+	}
+	l.events = l.events[i:]
+}
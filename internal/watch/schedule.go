@@ -0,0 +1,25 @@
+package watch
+
+import (
+	"context"
+	"time"
+)
+
+// Schedule calls fn at each activation time returned by next, until ctx is
+// cancelled or next returns the zero time.
+func Schedule(ctx context.Context, next func(time.Time) time.Time, fn func()) {
+	for {
+		at := next(time.Now())
+		if at.IsZero() {
+			return
+		}
+		timer := time.NewTimer(time.Until(at))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			fn()
+		}
+	}
+}
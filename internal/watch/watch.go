@@ -0,0 +1,95 @@
+// Package watch polls a set of files for changes. Polling (rather than OS
+// file events) keeps it portable and independent of how many files are
+// watched; the scanned set is provided by the caller, so ignore rules apply.
+package watch
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// Snapshot maps file paths to a fingerprint of their state (e.g. modification
+// time and size).
+type Snapshot map[string]string
+
+// Diff returns the paths added, removed or modified between two snapshots,
+// sorted.
+func Diff(before, after Snapshot) []string {
+	var changed []string
+	for path, state := range after {
+		if prev, ok := before[path]; !ok || prev != state {
+			changed = append(changed, path)
+		}
+	}
+	for path := range before {
+		if _, ok := after[path]; !ok {
+			changed = append(changed, path)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// Watcher polls files for changes, reporting them once they settle.
+type Watcher struct {
+	Scan     func() (Snapshot, error) // Returns the current state of the watched files
+	Interval time.Duration            // Polling interval
+	Debounce time.Duration            // Quiet period after the last change before reporting it
+	Limit    *Limit                   // Caps the number of reports, if set
+	OnLimit  func(next time.Time)     // Called when changes are held back by Limit, with when they'll be reported
+}
+
+// Run polls until ctx is cancelled, calling onChange with the changed paths
+// once no further change happened for the debounce period. Changes held back
+// by the limit accumulate until it allows a report. Scan errors after the
+// initial scan are treated as transient and skipped.
+func (w *Watcher) Run(ctx context.Context, onChange func(changed []string)) error {
+	last, err := w.Scan()
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+
+	pending := map[string]bool{}
+	var lastChange time.Time
+	limited := false
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case now := <-ticker.C:
+			snapshot, err := w.Scan()
+			if err != nil {
+				continue
+			}
+			if changed := Diff(last, snapshot); len(changed) > 0 {
+				for _, path := range changed {
+					pending[path] = true
+				}
+				last = snapshot
+				lastChange = now
+				continue
+			}
+			if len(pending) > 0 && now.Sub(lastChange) >= w.Debounce {
+				if !w.Limit.Allow(now) {
+					if !limited && w.OnLimit != nil {
+						w.OnLimit(w.Limit.Next(now))
+					}
+					limited = true
+					continue
+				}
+				limited = false
+				changed := make([]string, 0, len(pending))
+				for path := range pending {
+					changed = append(changed, path)
+				}
+				sort.Strings(changed)
+				pending = map[string]bool{}
+				onChange(changed)
+			}
+		}
+	}
+}
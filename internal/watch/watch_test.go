@@ -0,0 +1,124 @@
+package watch
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDiff(t *testing.T) {
+	before := Snapshot{"a.go": "1", "b.go": "1", "c.go": "1"}
+	after := Snapshot{"a.go": "1", "b.go": "2", "d.go": "1"}
+
+	want := []string{"b.go", "c.go", "d.go"}
+	if got := Diff(before, after); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	if got := Diff(before, before); len(got) != 0 {
+		t.Errorf("Expected no changes, got %v", got)
+	}
+}
+
+func TestWatcherRun(t *testing.T) {
+	var mu sync.Mutex
+	state := Snapshot{"a.go": "1"}
+	scan := func() (Snapshot, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		snapshot := Snapshot{}
+		for k, v := range state {
+			snapshot[k] = v
+		}
+		return snapshot, nil
+	}
+
+	w := &Watcher{Scan: scan, Interval: 5 * time.Millisecond, Debounce: 20 * time.Millisecond}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reports := make(chan []string, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- w.Run(ctx, func(changed []string) { reports <- changed })
+	}()
+
+	// Two changes in quick succession are reported together
+	time.Sleep(10 * time.Millisecond)
+	mu.Lock()
+	state["a.go"] = "2"
+	mu.Unlock()
+	time.Sleep(7 * time.Millisecond)
+	mu.Lock()
+	state["b.go"] = "1"
+	mu.Unlock()
+
+	select {
+	case changed := <-reports:
+		if want := []string{"a.go", "b.go"}; !reflect.DeepEqual(changed, want) {
+			t.Errorf("Expected %v, got %v", want, changed)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for changes")
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Run returned error: %v", err)
+	}
+	if len(reports) != 0 {
+		t.Errorf("Expected a single report, got %d more", len(reports))
+	}
+}
+
+func TestSchedule(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	calls := 0
+	next := func(now time.Time) time.Time {
+		if calls == 3 {
+			return time.Time{}
+		}
+		return now.Add(time.Millisecond)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		Schedule(ctx, next, func() { calls++ })
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for the schedule to end")
+	}
+	if calls != 3 {
+		t.Errorf("Expected 3 calls, got %d", calls)
+	}
+}
+
+func TestLimit(t *testing.T) {
+	start := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	l := &Limit{Max: 2, Window: time.Hour}
+
+	if !l.Allow(start) || !l.Allow(start.Add(10*time.Minute)) {
+		t.Fatal("Expected the first two events to be allowed")
+	}
+	if l.Allow(start.Add(20 * time.Minute)) {
+		t.Error("Expected a third event within the hour to be refused")
+	}
+	if next := l.Next(start.Add(20 * time.Minute)); !next.Equal(start.Add(time.Hour)) {
+		t.Errorf("Expected the next event to be allowed at %s, got %s", start.Add(time.Hour), next)
+	}
+	if !l.Allow(start.Add(time.Hour)) {
+		t.Error("Expected an event to be allowed once the first left the window")
+	}
+
+	var unlimited *Limit
+	if !unlimited.Allow(start) || !(&Limit{}).Allow(start) {
+		t.Error("Expected no limit to allow every event")
+	}
+}
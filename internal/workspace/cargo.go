@@ -0,0 +1,71 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/BurntSushi/toml"
+)
+
+// cargoManifest holds the fields of Cargo.toml relevant to workspaces.
+type cargoManifest struct {
+	Workspace *struct {
+		Members []string `toml:"members"`
+		Exclude []string `toml:"exclude"`
+	} `toml:"workspace"`
+	Package *struct {
+		Name string `toml:"name"`
+	} `toml:"package"`
+	Dependencies      map[string]any `toml:"dependencies"`
+	DevDependencies   map[string]any `toml:"dev-dependencies"`
+	BuildDependencies map[string]any `toml:"build-dependencies"`
+}
+
+// detectCargo detects Cargo workspaces (a root Cargo.toml with [workspace]).
+func detectCargo(root string) (*Workspace, error) {
+	var manifest cargoManifest
+	if _, err := toml.DecodeFile(filepath.Join(root, "Cargo.toml"), &manifest); err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if manifest.Workspace == nil {
+		return nil, nil
+	}
+
+	globs := manifest.Workspace.Members
+	for _, exclude := range manifest.Workspace.Exclude {
+		globs = append(globs, "!"+exclude)
+	}
+	dirs, err := expandGlobs(root, globs, "Cargo.toml")
+	if err != nil {
+		return nil, err
+	}
+
+	ws := &Workspace{Kind: "cargo"}
+	deps := map[string][]string{}
+	local := map[string]bool{}
+	for _, dir := range dirs {
+		var member cargoManifest
+		if _, err := toml.DecodeFile(filepath.Join(root, filepath.FromSlash(dir), "Cargo.toml"), &member); err != nil {
+			continue
+		}
+		if member.Package == nil || member.Package.Name == "" {
+			continue
+		}
+		name := member.Package.Name
+		local[name] = true
+		for _, group := range []map[string]any{member.Dependencies, member.DevDependencies, member.BuildDependencies} {
+			for dep := range group {
+				deps[name] = append(deps[name], dep)
+			}
+		}
+		ws.Packages = append(ws.Packages, Package{Name: name, Path: dir})
+	}
+
+	for i := range ws.Packages {
+		ws.Packages[i].Deps = localDeps(deps[ws.Packages[i].Name], local)
+	}
+	return ws, nil
+}
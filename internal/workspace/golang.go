@@ -0,0 +1,86 @@
+package workspace
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// detectGoWork detects Go workspaces (go.work). Packages are the modules listed
+// in `use` directives; dependencies come from each module's go.mod requires.
+func detectGoWork(root string) (*Workspace, error) {
+	data, err := os.ReadFile(filepath.Join(root, "go.work"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ws := &Workspace{Kind: "go.work"}
+	requires := map[string][]string{}
+	local := map[string]bool{}
+	for _, dir := range parseGoDirective(data, "use") {
+		dir = strings.TrimSuffix(strings.TrimPrefix(filepath.ToSlash(dir), "./"), "/")
+		if dir == "" {
+			dir = "."
+		}
+		mod, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(dir), "go.mod"))
+		if err != nil {
+			continue
+		}
+		modules := parseGoDirective(mod, "module")
+		if len(modules) == 0 {
+			continue
+		}
+		name := modules[0]
+		local[name] = true
+		requires[name] = parseGoDirective(mod, "require")
+		ws.Packages = append(ws.Packages, Package{Name: name, Path: dir})
+	}
+
+	for i := range ws.Packages {
+		ws.Packages[i].Deps = localDeps(requires[ws.Packages[i].Name], local)
+	}
+	return ws, nil
+}
+
+// parseGoDirective returns the first argument of every occurrence of a
+// directive in a go.mod/go.work file, handling both the single-line form
+// (`use ./a`) and the block form (`use ( ./a ./b )`).
+func parseGoDirective(data []byte, directive string) []string {
+	var values []string
+	inBlock := false
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		if inBlock {
+			if fields[0] == ")" {
+				inBlock = false
+				continue
+			}
+			values = append(values, strings.Trim(fields[0], `"`))
+			continue
+		}
+
+		if fields[0] != directive || len(fields) < 2 {
+			continue
+		}
+		if fields[1] == "(" {
+			inBlock = true
+			continue
+		}
+		values = append(values, strings.Trim(fields[1], `"`))
+	}
+	return values
+}
@@ -0,0 +1,114 @@
+package workspace
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// packageJSON holds the fields of package.json relevant to workspaces.
+type packageJSON struct {
+	Name                 string            `json:"name"`
+	Workspaces           json.RawMessage   `json:"workspaces"`
+	Dependencies         map[string]string `json:"dependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+}
+
+// detectPnpm detects pnpm workspaces (pnpm-workspace.yaml).
+func detectPnpm(root string) (*Workspace, error) {
+	data, err := os.ReadFile(filepath.Join(root, "pnpm-workspace.yaml"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest struct {
+		Packages []string `yaml:"packages"`
+	}
+	if err := yaml.Unmarshal(data, &manifest); err != nil {
+		return nil, err
+	}
+	return nodeWorkspace(root, "pnpm", manifest.Packages)
+}
+
+// detectNpm detects npm/yarn workspaces declared in the root package.json.
+// This is also how Nx and Turborepo monorepos declare their packages.
+func detectNpm(root string) (*Workspace, error) {
+	data, err := os.ReadFile(filepath.Join(root, "package.json"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var pkg packageJSON
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil, err
+	}
+	if len(pkg.Workspaces) == 0 {
+		return nil, nil
+	}
+
+	// "workspaces" is either a list of globs or {"packages": [...]} (yarn)
+	var globs []string
+	if err := json.Unmarshal(pkg.Workspaces, &globs); err != nil {
+		var nested struct {
+			Packages []string `json:"packages"`
+		}
+		if err := json.Unmarshal(pkg.Workspaces, &nested); err != nil {
+			return nil, err
+		}
+		globs = nested.Packages
+	}
+
+	kind := "npm"
+	for _, marker := range []string{"nx.json", "turbo.json"} {
+		if _, err := os.Stat(filepath.Join(root, marker)); err == nil {
+			kind = marker[:len(marker)-len(".json")]
+		}
+	}
+	return nodeWorkspace(root, kind, globs)
+}
+
+// nodeWorkspace resolves package globs to packages with a package.json.
+func nodeWorkspace(root, kind string, globs []string) (*Workspace, error) {
+	dirs, err := expandGlobs(root, globs, "package.json")
+	if err != nil {
+		return nil, err
+	}
+
+	ws := &Workspace{Kind: kind}
+	deps := map[string][]string{}
+	local := map[string]bool{}
+	for _, dir := range dirs {
+		data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(dir), "package.json"))
+		if err != nil {
+			continue
+		}
+		var pkg packageJSON
+		if err := json.Unmarshal(data, &pkg); err != nil || pkg.Name == "" {
+			continue
+		}
+		local[pkg.Name] = true
+		for _, group := range []map[string]string{
+			pkg.Dependencies, pkg.DevDependencies, pkg.PeerDependencies, pkg.OptionalDependencies,
+		} {
+			for name := range group {
+				deps[pkg.Name] = append(deps[pkg.Name], name)
+			}
+		}
+		ws.Packages = append(ws.Packages, Package{Name: pkg.Name, Path: dir})
+	}
+
+	for i := range ws.Packages {
+		ws.Packages[i].Deps = localDeps(deps[ws.Packages[i].Name], local)
+	}
+	return ws, nil
+}
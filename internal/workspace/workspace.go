@@ -0,0 +1,154 @@
+// Package workspace detects monorepo workspace layouts (go.work, pnpm, npm/yarn
+// workspaces as used by Nx/Turbo, Cargo workspaces) and resolves the local
+// dependencies between workspace packages. This allows scoping a bundle to a
+// single package plus everything it depends on within the repository.
+package workspace
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Package is a single package (module, crate, npm package) in a workspace.
+type Package struct {
+	Name string   // Package name (module path, crate or npm package name)
+	Path string   // Slash-separated directory relative to the workspace root
+	Deps []string // Names of the workspace packages this one depends on
+}
+
+// Workspace is a detected monorepo workspace.
+type Workspace struct {
+	Kind     string // Manifest kind, e.g. "go.work", "pnpm", "npm", "cargo"
+	Packages []Package
+}
+
+// detector inspects a root directory and returns a workspace if its manifest
+// is present. It returns nil (and no error) when the manifest doesn't exist.
+type detector func(root string) (*Workspace, error)
+
+// detectors are tried in order; the first one finding a manifest wins.
+var detectors = []detector{
+	detectGoWork,
+	detectPnpm,
+	detectNpm,
+	detectCargo,
+}
+
+// Detect looks for a workspace manifest in root. It returns nil if root isn't
+// a workspace.
+func Detect(root string) (*Workspace, error) {
+	for _, detect := range detectors {
+		ws, err := detect(root)
+		if err != nil {
+			return nil, err
+		}
+		if ws != nil {
+			sort.Slice(ws.Packages, func(i, j int) bool { return ws.Packages[i].Name < ws.Packages[j].Name })
+			return ws, nil
+		}
+	}
+	return nil, nil
+}
+
+// Find returns the package with the given name, or whose path matches.
+func (w *Workspace) Find(query string) (*Package, error) {
+	query = strings.TrimSuffix(filepath.ToSlash(query), "/")
+	for i := range w.Packages {
+		pkg := &w.Packages[i]
+		if pkg.Name == query || pkg.Path == strings.TrimPrefix(query, "./") {
+			return pkg, nil
+		}
+	}
+	return nil, fmt.Errorf("workspace package '%s' not found", query)
+}
+
+// Closure returns the directories of a package and all the workspace packages
+// it (transitively) depends on, sorted.
+func (w *Workspace) Closure(name string) ([]string, error) {
+	root, err := w.Find(name)
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]*Package, len(w.Packages))
+	for i := range w.Packages {
+		byName[w.Packages[i].Name] = &w.Packages[i]
+	}
+
+	seen := map[string]bool{}
+	queue := []*Package{root}
+	var dirs []string
+	for len(queue) > 0 {
+		pkg := queue[0]
+		queue = queue[1:]
+		if seen[pkg.Name] {
+			continue
+		}
+		seen[pkg.Name] = true
+		dirs = append(dirs, pkg.Path)
+		for _, dep := range pkg.Deps {
+			if next, ok := byName[dep]; ok {
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	sort.Strings(dirs)
+	return dirs, nil
+}
+
+// MARK: Helpers
+
+// expandGlobs resolves workspace member globs (e.g. "packages/*") to the
+// matching directories containing the given manifest file. Negated globs
+// (prefixed with "!") remove matches.
+func expandGlobs(root string, globs []string, manifest string) ([]string, error) {
+	included := map[string]bool{}
+	for _, glob := range globs {
+		negate := strings.HasPrefix(glob, "!")
+		glob = strings.TrimPrefix(glob, "!")
+		glob = strings.TrimSuffix(strings.TrimPrefix(filepath.ToSlash(glob), "./"), "/")
+		// "**" isn't supported by filepath.Glob; treat it as a single level,
+		// which covers the common "packages/**" layout.
+		glob = strings.ReplaceAll(glob, "**", "*")
+
+		matches, err := filepath.Glob(filepath.Join(root, filepath.FromSlash(glob)))
+		if err != nil {
+			return nil, fmt.Errorf("invalid workspace glob %q: %w", glob, err)
+		}
+		for _, match := range matches {
+			if _, err := os.Stat(filepath.Join(match, manifest)); err != nil {
+				continue
+			}
+			rel, err := filepath.Rel(root, match)
+			if err != nil {
+				continue
+			}
+			included[filepath.ToSlash(rel)] = !negate
+		}
+	}
+
+	var dirs []string
+	for dir, ok := range included {
+		if ok {
+			dirs = append(dirs, dir)
+		}
+	}
+	sort.Strings(dirs)
+	return dirs, nil
+}
+
+// localDeps filters dependency names down to those that are workspace packages.
+func localDeps(names []string, local map[string]bool) []string {
+	var deps []string
+	for _, name := range names {
+		if local[name] {
+			deps = append(deps, name)
+		}
+	}
+	sort.Strings(deps)
+	return deps
+}
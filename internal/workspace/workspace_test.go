@@ -0,0 +1,128 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for path, content := range files {
+		fullPath := filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0o755); err != nil {
+			t.Fatalf("Failed to create directories for %s: %v", path, err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to create file %s: %v", path, err)
+		}
+	}
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		kind     string
+		target   string
+		expected []string // directories in the target's closure
+	}{
+		{
+			name: "go.work",
+			files: map[string]string{
+				"go.work":          "go 1.24\n\nuse (\n\t./cmd/app // the binary\n\t./lib\n\t./tools\n)\n",
+				"cmd/app/go.mod":   "module example.com/app\n\nrequire (\n\texample.com/lib v0.0.0\n\tgithub.com/spf13/cobra v1.9.1\n)\n",
+				"lib/go.mod":       "module example.com/lib\n",
+				"tools/go.mod":     "module example.com/tools\n",
+				"cmd/app/main.go":  "package main",
+				"lib/lib.go":       "package lib",
+				"tools/tools.go":   "package tools",
+				"unrelated/x.txt":  "x",
+				"cmd/app/sub/x.go": "package sub",
+			},
+			kind:     "go.work",
+			target:   "example.com/app",
+			expected: []string{"cmd/app", "lib"},
+		},
+		{
+			name: "pnpm",
+			files: map[string]string{
+				"pnpm-workspace.yaml":         "packages:\n  - 'apps/*'\n  - 'packages/*'\n",
+				"apps/web/package.json":       `{"name": "web", "dependencies": {"@acme/ui": "workspace:*", "react": "^18"}}`,
+				"packages/ui/package.json":    `{"name": "@acme/ui", "devDependencies": {"@acme/utils": "workspace:*"}}`,
+				"packages/utils/package.json": `{"name": "@acme/utils"}`,
+				"packages/other/package.json": `{"name": "@acme/other"}`,
+			},
+			kind:     "pnpm",
+			target:   "web",
+			expected: []string{"apps/web", "packages/ui", "packages/utils"},
+		},
+		{
+			name: "turbo (npm workspaces)",
+			files: map[string]string{
+				"package.json":           `{"name": "root", "workspaces": {"packages": ["apps/*", "libs/*"]}}`,
+				"turbo.json":             `{}`,
+				"apps/api/package.json":  `{"name": "api", "dependencies": {"core": "*"}}`,
+				"libs/core/package.json": `{"name": "core"}`,
+			},
+			kind:     "turbo",
+			target:   "apps/api",
+			expected: []string{"apps/api", "libs/core"},
+		},
+		{
+			name: "cargo",
+			files: map[string]string{
+				"Cargo.toml":               "[workspace]\nmembers = [\"crates/*\"]\nexclude = [\"crates/legacy\"]\n",
+				"crates/cli/Cargo.toml":    "[package]\nname = \"cli\"\n\n[dependencies]\ncore = { path = \"../core\" }\nserde = \"1\"\n",
+				"crates/core/Cargo.toml":   "[package]\nname = \"core\"\n",
+				"crates/legacy/Cargo.toml": "[package]\nname = \"legacy\"\n",
+			},
+			kind:     "cargo",
+			target:   "cli",
+			expected: []string{"crates/cli", "crates/core"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			writeFiles(t, root, tt.files)
+
+			ws, err := Detect(root)
+			if err != nil {
+				t.Fatalf("Detect failed: %v", err)
+			}
+			if ws == nil {
+				t.Fatal("Expected a workspace to be detected")
+			}
+			if ws.Kind != tt.kind {
+				t.Errorf("Expected kind %s, got %s", tt.kind, ws.Kind)
+			}
+
+			dirs, err := ws.Closure(tt.target)
+			if err != nil {
+				t.Fatalf("Closure failed: %v", err)
+			}
+			if strings.Join(dirs, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("Expected closure %v, got %v", tt.expected, dirs)
+			}
+		})
+	}
+}
+
+func TestDetectNoWorkspace(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"package.json": `{"name": "single"}`,
+		"go.mod":       "module example.com/single\n",
+	})
+
+	ws, err := Detect(root)
+	if err != nil {
+		t.Fatalf("Detect failed: %v", err)
+	}
+	if ws != nil {
+		t.Errorf("Expected no workspace, got %+v", ws)
+	}
+}
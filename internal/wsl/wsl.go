@@ -0,0 +1,118 @@
+// Package wsl detects the Windows Subsystem for Linux and translates paths
+// between its two sides (/mnt/c/Users/me and C:\Users\me), so that a project
+// accessed from both shares its config and produces the same bundles.
+package wsl
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// DefaultMountRoot is where WSL mounts Windows drives, unless automount.root
+// says otherwise in /etc/wsl.conf.
+const DefaultMountRoot = "/mnt/"
+
+// Detected reports whether sandworm runs under WSL.
+var Detected = sync.OnceValue(func() bool {
+	release, _ := os.ReadFile("/proc/sys/kernel/osrelease")
+	return detect(runtime.GOOS, string(release), os.Getenv("WSL_DISTRO_NAME"))
+})
+
+// MountRoot returns where Windows drives are mounted under WSL.
+var MountRoot = sync.OnceValue(func() string {
+	data, err := os.ReadFile("/etc/wsl.conf")
+	if err != nil {
+		return DefaultMountRoot
+	}
+	return parseMountRoot(data)
+})
+
+// Normalize translates a path written on the other side of WSL to this one:
+// under WSL, Windows paths become paths of the mounted drives and backslashes
+// become slashes; on Windows, paths of mounted drives become drive paths.
+// Other paths, and paths on other platforms, are returned unchanged.
+func Normalize(p string) string {
+	switch {
+	case runtime.GOOS == "windows":
+		if win, ok := ToWindows(p, DefaultMountRoot); ok {
+			return win
+		}
+	case Detected():
+		if linux, ok := ToLinux(p, MountRoot()); ok {
+			return linux
+		}
+		return strings.ReplaceAll(p, `\`, "/")
+	}
+	return p
+}
+
+// ToLinux translates a Windows path to its WSL form: a drive path
+// (C:\Users\me or C:/Users/me) to a path under mountRoot (/mnt/c/Users/me),
+// or a path of the WSL share (\\wsl$\Ubuntu\home\me, \\wsl.localhost\...)
+// to the Linux path (/home/me). It returns false for other paths.
+func ToLinux(p, mountRoot string) (string, bool) {
+	slashed := strings.ReplaceAll(p, `\`, "/")
+	if len(p) >= 2 && isLetter(p[0]) && p[1] == ':' && (len(p) == 2 || slashed[2] == '/') {
+		drive := strings.ToLower(p[:1])
+		return path.Clean(path.Join(mountRoot, drive, slashed[2:])), true
+	}
+	for _, share := range []string{"//wsl$/", "//wsl.localhost/"} {
+		if len(slashed) > len(share) && strings.EqualFold(slashed[:len(share)], share) {
+			// Past the share is the distribution, then the Linux path
+			_, rest, _ := strings.Cut(slashed[len(share):], "/")
+			return path.Clean("/" + rest), true
+		}
+	}
+	return "", false
+}
+
+// ToWindows translates the WSL path of a Windows drive (/mnt/c/Users/me, for
+// mountRoot /mnt/) to its Windows form (C:\Users\me). It returns false for
+// other paths.
+func ToWindows(p, mountRoot string) (string, bool) {
+	rest, ok := strings.CutPrefix(p, strings.TrimSuffix(mountRoot, "/")+"/")
+	if !ok || rest == "" || !isLetter(rest[0]) || (len(rest) > 1 && rest[1] != '/') {
+		return "", false
+	}
+	drive := strings.ToUpper(rest[:1]) + `:\`
+	rest = strings.Trim(path.Clean("/"+rest[1:]), "/")
+	return drive + strings.ReplaceAll(rest, "/", `\`), true
+}
+
+// MARK: Helpers
+
+// detect reports whether a platform is WSL, from the kernel release (e.g.
+// 5.15.153.1-microsoft-standard-WSL2) or the distribution WSL sets.
+func detect(goos, release, distro string) bool {
+	return goos == "linux" && (distro != "" || strings.Contains(strings.ToLower(release), "microsoft"))
+}
+
+// parseMountRoot returns automount.root of a wsl.conf file, or the default.
+func parseMountRoot(data []byte) string {
+	var section string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.ToLower(strings.TrimSpace(line[1 : len(line)-1]))
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || section != "automount" || strings.TrimSpace(key) != "root" {
+			continue
+		}
+		if value = strings.Trim(strings.TrimSpace(value), `"`); strings.HasPrefix(value, "/") {
+			return strings.TrimSuffix(value, "/") + "/"
+		}
+	}
+	return DefaultMountRoot
+}
+
+func isLetter(c byte) bool {
+	return ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
@@ -0,0 +1,96 @@
+package wsl
+
+import "testing"
+
+func TestToLinux(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+		ok   bool
+	}{
+		{`C:\Users\me\project`, "/mnt/c/Users/me/project", true},
+		{`d:/src/app/`, "/mnt/d/src/app", true},
+		{`C:`, "/mnt/c", true},
+		{`C:\`, "/mnt/c", true},
+		{`\\wsl$\Ubuntu\home\me\project`, "/home/me/project", true},
+		{`\\wsl.localhost\Ubuntu-22.04\home\me`, "/home/me", true},
+		{"/home/me/project", "", false},
+		{"relative/path", "", false},
+		{`C:relative`, "", false},
+	}
+	for _, tt := range tests {
+		got, ok := ToLinux(tt.path, DefaultMountRoot)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ToLinux(%q) = %q, %v; want %q, %v", tt.path, got, ok, tt.want, tt.ok)
+		}
+	}
+
+	if got, _ := ToLinux(`C:\Users`, "/win/"); got != "/win/c/Users" {
+		t.Errorf("Expected a custom mount root to be honored, got %q", got)
+	}
+}
+
+func TestToWindows(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+		ok   bool
+	}{
+		{"/mnt/c/Users/me/project", `C:\Users\me\project`, true},
+		{"/mnt/d", `D:\`, true},
+		{"/mnt/d/", `D:\`, true},
+		{"/mnt/c/src/../app", `C:\app`, true},
+		{"/mnt/wsl/shared", "", false},
+		{"/home/me", "", false},
+		{"/mnt/", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := ToWindows(tt.path, DefaultMountRoot)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ToWindows(%q) = %q, %v; want %q, %v", tt.path, got, ok, tt.want, tt.ok)
+		}
+	}
+
+	// Translations round-trip
+	for _, p := range []string{"/mnt/c/Users/me/project", "/mnt/e"} {
+		win, _ := ToWindows(p, DefaultMountRoot)
+		if back, _ := ToLinux(win, DefaultMountRoot); back != p {
+			t.Errorf("Expected %s to round-trip, got %s via %s", p, back, win)
+		}
+	}
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		goos, release, distro string
+		want                  bool
+	}{
+		{"linux", "5.15.153.1-microsoft-standard-WSL2", "", true},
+		{"linux", "4.4.0-19041-Microsoft", "", true},
+		{"linux", "6.8.0-45-generic", "Ubuntu", true},
+		{"linux", "6.8.0-45-generic", "", false},
+		{"darwin", "", "", false},
+	}
+	for _, tt := range tests {
+		if got := detect(tt.goos, tt.release, tt.distro); got != tt.want {
+			t.Errorf("detect(%q, %q, %q) = %v, want %v", tt.goos, tt.release, tt.distro, got, tt.want)
+		}
+	}
+}
+
+func TestParseMountRoot(t *testing.T) {
+	tests := []struct {
+		conf string
+		want string
+	}{
+		{"", DefaultMountRoot},
+		{"[automount]\nroot = /win\n", "/win/"},
+		{"[boot]\nsystemd=true\n\n[automount]\nenabled = true\nroot=\"/drives/\"\n", "/drives/"},
+		{"[network]\nroot = /elsewhere\n", DefaultMountRoot},
+	}
+	for _, tt := range tests {
+		if got := parseMountRoot([]byte(tt.conf)); got != tt.want {
+			t.Errorf("parseMountRoot(%q) = %q, want %q", tt.conf, got, tt.want)
+		}
+	}
+}